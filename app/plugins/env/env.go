@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"hr-system-go/app/plugins"
 
@@ -31,6 +32,22 @@ func (e Env) GetEnv(key string) string {
 	return e.defaultValues[key]
 }
 
+func (e Env) GetEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(e.GetEnv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (e Env) GetEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(e.GetEnv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func NewEnv() *Env {
 	env := &Env{
 		defaultValues: map[string]string{
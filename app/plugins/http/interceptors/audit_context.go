@@ -0,0 +1,35 @@
+package interceptors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"hr-system-go/app/plugins/mysql"
+
+	"github.com/gin-gonic/gin"
+)
+
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+// AuditContext tags request with ID and client IP, auth fills in actor once token is verified
+func AuditContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(REQUEST_ID_HEADER)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = newRequestID()
+		}
+
+		ctx.Set(mysql.AUDIT_CONTEXT_KEY, &mysql.AuditContext{
+			RequestID: requestID,
+			IP:        ctx.ClientIP(),
+		})
+		ctx.Header(REQUEST_ID_HEADER, requestID)
+		ctx.Next()
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -34,7 +34,7 @@ func NewRouter(env *env.Env, logger *logger.Logger) *gin.Engine {
 	gin.SetMode(ginMode)
 
 	r := gin.New()
-	r.Use(gin.Recovery(), interceptors.RequestLog(logger))
+	r.Use(gin.Recovery(), interceptors.AuditContext(), interceptors.RequestLog(logger))
 
 	return r
 }
@@ -0,0 +1,95 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"net/smtp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	plugins.Registry = append(plugins.Registry, NewMailer)
+}
+
+var ErrNoRecipient = errors.New("mail has no recipient")
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer sends plain text mails through SMTP, without host mails are only logged
+type Mailer struct {
+	logger   *logger.Logger
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+	// logBody shows mail content in log, tokens are sent by mail so it is for development only
+	logBody bool
+}
+
+func NewMailer(env *env.Env, logger *logger.Logger) *Mailer {
+	port := env.GetEnv("MAIL_SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := env.GetEnv("MAIL_FROM")
+	if from == "" {
+		from = "hr-system@localhost"
+	}
+
+	return &Mailer{
+		logger:   logger,
+		host:     env.GetEnv("MAIL_SMTP_HOST"),
+		port:     port,
+		username: env.GetEnv("MAIL_SMTP_USERNAME"),
+		password: env.GetEnv("MAIL_SMTP_PASSWORD"),
+		from:     from,
+		logBody:  env.GetEnv("ENVIRONMENT") == "development",
+	}
+}
+
+func (m *Mailer) Send(message Message) error {
+	if len(message.To) == 0 {
+		return ErrNoRecipient
+	}
+
+	if m.host == "" {
+		fields := []zap.Field{zap.Strings("to", message.To), zap.String("subject", message.Subject)}
+		if m.logBody {
+			fields = append(fields, zap.String("body", message.Body))
+		}
+		m.logger.Info("Mail is not sent, SMTP host is not configured", fields...)
+		return nil
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	addr := fmt.Sprintf("%s:%s", m.host, m.port)
+	if err := smtp.SendMail(addr, auth, m.from, message.To, m.compose(message)); err != nil {
+		m.logger.Error("Cannot Send Mail", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (m *Mailer) compose(message Message) []byte {
+	headers := []string{
+		"From: " + m.from,
+		"To: " + strings.Join(message.To, ", "),
+		"Subject: " + message.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body)
+}
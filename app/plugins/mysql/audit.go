@@ -0,0 +1,357 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	AUDIT_CONTEXT_KEY    = "auditContext"
+	AUDIT_ACTION_CREATE  = "create"
+	AUDIT_ACTION_UPDATE  = "update"
+	AUDIT_ACTION_DELETE  = "delete"
+	AUDIT_REDACTED_VALUE = "[REDACTED]"
+	// rows are soft deleted by moving them into this status
+	AUDIT_REMOVED_STATUS = "removed"
+	auditBeforeRowsKey   = "audit:before_rows"
+)
+
+var ErrAuditLogAppendOnly = errors.New("audit log is append only")
+
+// AuditContext describes who is making request, audit interceptor puts it into request context
+type AuditContext struct {
+	ActorID      *uint
+	OnBehalfOfID *uint
+	RequestID    string
+	IP           string
+}
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEntry is a change of a single row captured by audit callbacks
+type AuditEntry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Changes    map[string]AuditChange
+	Context    AuditContext
+}
+
+// AuditRecorder stores entries within the transaction which made the change
+type AuditRecorder func(db *gorm.DB, entries []AuditEntry) error
+
+type AuditConfig struct {
+	// LogTable is protected from updates and deletes
+	LogTable string
+	// Tables are audited on every create, update and delete
+	Tables []string
+	// IgnoredColumns never show up in recorded changes
+	IgnoredColumns []string
+	// RedactedColumns are recorded as changed without their values
+	RedactedColumns []string
+	Recorder        AuditRecorder
+}
+
+func AuditContextFrom(ctx context.Context) *AuditContext {
+	if ctx == nil {
+		return nil
+	}
+	auditContext, _ := ctx.Value(AUDIT_CONTEXT_KEY).(*AuditContext)
+	return auditContext
+}
+
+// EnableAudit hooks audit callbacks into store, it can be called before or after Connect
+func (s *MySqlStore) EnableAudit(config AuditConfig) error {
+	s.audit = &config
+	if s.db == nil {
+		return nil
+	}
+	return s.registerAuditCallbacks(s.db)
+}
+
+func (s *MySqlStore) registerAuditCallbacks(db *gorm.DB) error {
+	if s.auditRegistered {
+		return nil
+	}
+
+	// after callbacks run before commit, so change and its audit log share transaction
+	callbacks := []error{
+		db.Callback().Update().Before("*").Register("audit:append_only", s.auditAppendOnly),
+		db.Callback().Delete().Before("*").Register("audit:append_only", s.auditAppendOnly),
+		db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("audit:after_create", s.auditAfterCreate),
+		db.Callback().Update().After("gorm:setup_reflect_value").Before("gorm:update").Register("audit:before_update", s.auditBeforeChange),
+		db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("audit:after_update", s.auditAfterUpdate),
+		db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", s.auditBeforeChange),
+		db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("audit:after_delete", s.auditAfterDelete),
+	}
+	if err := errors.Join(callbacks...); err != nil {
+		return err
+	}
+	s.auditRegistered = true
+	return nil
+}
+
+func (s *MySqlStore) auditAppendOnly(db *gorm.DB) {
+	if s.audit != nil && db.Statement.Table == s.audit.LogTable {
+		db.AddError(ErrAuditLogAppendOnly)
+	}
+}
+
+func (s *MySqlStore) isAudited(db *gorm.DB) bool {
+	if s.audit == nil || db.Error != nil || db.DryRun || db.Statement.Schema == nil {
+		return false
+	}
+	for _, table := range s.audit.Tables {
+		if table == db.Statement.Table {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MySqlStore) auditAfterCreate(db *gorm.DB) {
+	// associations are upserted with ON CONFLICT DO NOTHING, existing rows are not created again
+	if !s.isAudited(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	condition := primaryKeyCondition(db.Statement)
+	if condition == nil {
+		return
+	}
+	rows, err := findAuditRows(db, []clause.Expression{condition})
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	entries := []AuditEntry{}
+	for _, row := range rows {
+		entries = append(entries, s.newAuditEntry(db, AUDIT_ACTION_CREATE, row, nil, row))
+	}
+	s.record(db, entries)
+}
+
+// rows are loaded before change with same conditions statement is going to use
+func (s *MySqlStore) auditBeforeChange(db *gorm.DB) {
+	if !s.isAudited(db) {
+		return
+	}
+	conditions := []clause.Expression{}
+	if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+		conditions = append(conditions, where)
+	}
+	if condition := primaryKeyCondition(db.Statement); condition != nil {
+		conditions = append(conditions, condition)
+	}
+	// global update or delete is refused by gorm anyway
+	if len(conditions) == 0 {
+		return
+	}
+
+	rows, err := findAuditRows(db, conditions)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(auditBeforeRowsKey, rows)
+}
+
+func (s *MySqlStore) auditAfterUpdate(db *gorm.DB) {
+	beforeRows := auditBeforeRows(db)
+	if !s.isAudited(db) || len(beforeRows) == 0 {
+		return
+	}
+
+	afterRows, err := findAuditRows(db, []clause.Expression{rowsCondition(db.Statement, beforeRows)})
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	afterByID := map[string]map[string]interface{}{}
+	for _, row := range afterRows {
+		afterByID[auditEntityID(db.Statement, row)] = row
+	}
+
+	entries := []AuditEntry{}
+	for _, before := range beforeRows {
+		after, ok := afterByID[auditEntityID(db.Statement, before)]
+		if !ok {
+			continue
+		}
+		action := AUDIT_ACTION_UPDATE
+		if after["status"] == AUDIT_REMOVED_STATUS && before["status"] != AUDIT_REMOVED_STATUS {
+			action = AUDIT_ACTION_DELETE
+		}
+		entry := s.newAuditEntry(db, action, before, before, after)
+		if len(entry.Changes) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	s.record(db, entries)
+}
+
+func (s *MySqlStore) auditAfterDelete(db *gorm.DB) {
+	beforeRows := auditBeforeRows(db)
+	if !s.isAudited(db) || len(beforeRows) == 0 {
+		return
+	}
+
+	entries := []AuditEntry{}
+	for _, before := range beforeRows {
+		entries = append(entries, s.newAuditEntry(db, AUDIT_ACTION_DELETE, before, before, nil))
+	}
+	s.record(db, entries)
+}
+
+func (s *MySqlStore) record(db *gorm.DB, entries []AuditEntry) {
+	if len(entries) == 0 || s.audit.Recorder == nil {
+		return
+	}
+	// recorder shares transaction so change is rolled back when it cannot be audited
+	if err := s.audit.Recorder(db.Session(&gorm.Session{NewDB: true}), entries); err != nil {
+		db.AddError(err)
+	}
+}
+
+func auditBeforeRows(db *gorm.DB) []map[string]interface{} {
+	rows, ok := db.InstanceGet(auditBeforeRowsKey)
+	if !ok {
+		return nil
+	}
+	beforeRows, _ := rows.([]map[string]interface{})
+	return beforeRows
+}
+
+func findAuditRows(db *gorm.DB, conditions []clause.Expression) ([]map[string]interface{}, error) {
+	stmt := db.Statement
+	rows := []map[string]interface{}{}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Table(stmt.Table).
+		Clauses(conditions...).
+		Find(&rows).Error
+	// text columns might be scanned as bytes, which would be encoded as base64
+	for _, row := range rows {
+		for column, value := range row {
+			if bytes, ok := value.([]byte); ok {
+				row[column] = string(bytes)
+			}
+		}
+	}
+	return rows, err
+}
+
+// primaryKeyCondition matches rows held by statement model, it is nil when primary keys are zero
+func primaryKeyCondition(stmt *gorm.Statement) clause.Expression {
+	if len(stmt.Schema.PrimaryFields) == 0 {
+		return nil
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array:
+	default:
+		return nil
+	}
+	_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+	if len(values) == 0 {
+		return nil
+	}
+	return clause.IN{Column: column, Values: values}
+}
+
+func rowsCondition(stmt *gorm.Statement, rows []map[string]interface{}) clause.Expression {
+	queryValues := [][]interface{}{}
+	for _, row := range rows {
+		values := []interface{}{}
+		for _, name := range stmt.Schema.PrimaryFieldDBNames {
+			values = append(values, row[name])
+		}
+		queryValues = append(queryValues, values)
+	}
+	column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+	return clause.IN{Column: column, Values: values}
+}
+
+// auditEntityID joins primary key values, composite keys of join tables look like "1:2"
+func auditEntityID(stmt *gorm.Statement, row map[string]interface{}) string {
+	ids := []string{}
+	for _, name := range stmt.Schema.PrimaryFieldDBNames {
+		ids = append(ids, fmt.Sprint(row[name]))
+	}
+	return strings.Join(ids, ":")
+}
+
+func (s *MySqlStore) newAuditEntry(db *gorm.DB, action string, row map[string]interface{}, before map[string]interface{}, after map[string]interface{}) AuditEntry {
+	entry := AuditEntry{
+		Action:     action,
+		EntityType: db.Statement.Table,
+		EntityID:   auditEntityID(db.Statement, row),
+		Changes:    s.auditChanges(before, after),
+	}
+	if auditContext := AuditContextFrom(db.Statement.Context); auditContext != nil {
+		entry.Context = *auditContext
+	}
+	return entry
+}
+
+func (s *MySqlStore) auditChanges(before map[string]interface{}, after map[string]interface{}) map[string]AuditChange {
+	columns := map[string]bool{}
+	for column := range before {
+		columns[column] = true
+	}
+	for column := range after {
+		columns[column] = true
+	}
+
+	changes := map[string]AuditChange{}
+	for column := range columns {
+		if containsColumn(s.audit.IgnoredColumns, column) {
+			continue
+		}
+		beforeValue, afterValue := before[column], after[column]
+		if sameAuditValue(beforeValue, afterValue) {
+			continue
+		}
+		if containsColumn(s.audit.RedactedColumns, column) {
+			beforeValue, afterValue = redactAuditValue(beforeValue), redactAuditValue(afterValue)
+		}
+		changes[column] = AuditChange{Before: beforeValue, After: afterValue}
+	}
+	return changes
+}
+
+func sameAuditValue(before interface{}, after interface{}) bool {
+	beforeJSON, beforeErr := json.Marshal(before)
+	afterJSON, afterErr := json.Marshal(after)
+	if beforeErr != nil || afterErr != nil {
+		return reflect.DeepEqual(before, after)
+	}
+	return string(beforeJSON) == string(afterJSON)
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return AUDIT_REDACTED_VALUE
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, name := range columns {
+		if name == column {
+			return true
+		}
+	}
+	return false
+}
@@ -28,6 +28,9 @@ type MySqlStore struct {
 	env    *env.Env
 	logger *logger.Logger
 	db     *gorm.DB
+	// audit is set by EnableAudit, callbacks are registered once per connection
+	audit           *AuditConfig
+	auditRegistered bool
 }
 
 type Migration struct {
@@ -84,6 +87,12 @@ func (s *MySqlStore) Connect(username string, password string, database string,
 	}
 
 	s.db = db
+	if s.audit != nil {
+		if err := s.registerAuditCallbacks(db); err != nil {
+			s.logger.Error("Failed to register audit callbacks", zap.Error(err))
+			panic(err)
+		}
+	}
 	s.logger.Info("Connected to MySQL database")
 }
 
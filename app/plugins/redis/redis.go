@@ -136,8 +136,20 @@ func (s *RedisStore) Set(key string, value interface{}, expiration time.Duration
 	return s.rdb.Set(s.ctx, key, jsonValue, expiration).Err()
 }
 
-func (s *RedisStore) Delete(redisKey string) error {
-	res, err := s.rdb.Del(s.ctx, redisKey).Result()
+// Take reads JSON value and deletes the key atomically, so value can be used only once
+func (s *RedisStore) Take(redisKey string, pointerData interface{}) error {
+	data, err := s.rdb.GetDel(s.ctx, redisKey).Bytes()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, pointerData)
+}
+
+func (s *RedisStore) Delete(redisKeys ...string) error {
+	if len(redisKeys) == 0 {
+		return nil
+	}
+	res, err := s.rdb.Del(s.ctx, redisKeys...).Result()
 	if err != nil {
 		s.logger.Error("Failed to Delete redis key", zap.Error(err))
 		return err
@@ -146,6 +158,40 @@ func (s *RedisStore) Delete(redisKey string) error {
 	return nil
 }
 
+// DeletePattern removes keys matching glob pattern, SCAN is used to avoid blocking redis like KEYS does
+func (s *RedisStore) DeletePattern(pattern string) error {
+	iter := s.rdb.Scan(s.ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(s.ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		s.logger.Error("Failed to Scan redis keys", zap.Error(err))
+		return err
+	}
+	return s.Delete(keys...)
+}
+
+func (s *RedisStore) Publish(channel string, message interface{}) error {
+	jsonValue, err := json.Marshal(message)
+	if err != nil {
+		s.logger.Error("Failed to Marshal message", zap.Error(err))
+		return err
+	}
+	return s.rdb.Publish(s.ctx, channel, jsonValue).Err()
+}
+
+// Subscribe calls handler with raw payload of each message until returned close func is called
+func (s *RedisStore) Subscribe(channel string, handler func(payload []byte)) func() error {
+	pubsub := s.rdb.Subscribe(s.ctx, channel)
+	go func() {
+		for msg := range pubsub.Channel() {
+			handler([]byte(msg.Payload))
+		}
+	}()
+	return pubsub.Close
+}
+
 func (s *RedisStore) ClearAll() error {
 	return s.rdb.FlushAll(s.ctx).Err()
 }
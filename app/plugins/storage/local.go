@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalDriver keeps objects as files under root, written to temporary file first so readers never see half of one
+type LocalDriver struct {
+	root string
+}
+
+func NewLocalDriver(root string) *LocalDriver {
+	return &LocalDriver{root: root}
+}
+
+func (d *LocalDriver) Put(ctx context.Context, key string, content io.Reader) error {
+	name := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), name)
+}
+
+func (d *LocalDriver) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (d *LocalDriver) Delete(ctx context.Context, key string) error {
+	err := os.Remove(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (d *LocalDriver) path(key string) string {
+	return filepath.Join(d.root, filepath.FromSlash(key))
+}
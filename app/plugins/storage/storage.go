@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"io"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	plugins.Registry = append(plugins.Registry, NewStorage)
+}
+
+const (
+	STORAGE_DRIVER_LOCAL = "local"
+	STORAGE_DRIVER_S3    = "s3"
+)
+
+var (
+	ErrObjectNotFound = errors.New("stored object not found")
+	ErrObjectKey      = errors.New("object key must be a relative path without . or .. segments")
+)
+
+// Driver keeps objects by key, keys are slash separated relative paths
+type Driver interface {
+	Put(ctx context.Context, key string, content io.Reader) error
+	// Open returns ErrObjectNotFound for unknown keys
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete of unknown key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Storage keeps uploaded files in the driver chosen by STORAGE_DRIVER, local disk or S3 compatible bucket
+type Storage struct {
+	logger *logger.Logger
+	driver Driver
+}
+
+func NewStorage(env *env.Env, logger *logger.Logger) *Storage {
+	driver := env.GetEnv("STORAGE_DRIVER")
+	switch driver {
+	case "", STORAGE_DRIVER_LOCAL:
+		root := env.GetEnv("STORAGE_LOCAL_PATH")
+		if root == "" {
+			root = "storage"
+		}
+		return NewStorageWithDriver(logger, NewLocalDriver(root))
+	case STORAGE_DRIVER_S3:
+		region := env.GetEnv("STORAGE_S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return NewStorageWithDriver(logger, NewS3Driver(S3Config{
+			Endpoint:  env.GetEnv("STORAGE_S3_ENDPOINT"),
+			Region:    region,
+			Bucket:    env.GetEnv("STORAGE_S3_BUCKET"),
+			AccessKey: env.GetEnv("STORAGE_S3_ACCESS_KEY"),
+			SecretKey: env.GetEnv("STORAGE_S3_SECRET_KEY"),
+			PathStyle: env.GetEnvBool("STORAGE_S3_PATH_STYLE", false),
+		}))
+	default:
+		logger.Fatal("Unknown storage driver", zap.String("driver", driver))
+		return nil
+	}
+}
+
+func NewStorageWithDriver(logger *logger.Logger, driver Driver) *Storage {
+	return &Storage{
+		logger: logger,
+		driver: driver,
+	}
+}
+
+func (s *Storage) Put(ctx context.Context, key string, content io.Reader) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	if err := s.driver.Put(ctx, key, content); err != nil {
+		return fmt.Errorf("cannot store %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	return s.driver.Open(ctx, key)
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	return s.driver.Delete(ctx, key)
+}
+
+// DeleteQuietly is for cleaning up after failures and replaced files, error is only logged
+func (s *Storage) DeleteQuietly(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.Delete(ctx, key); err != nil {
+			s.logger.Warn("Cannot delete stored object", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// validKey keeps keys inside storage root whatever the driver does with them
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) || path.Clean(key) != key {
+		return ErrObjectKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return ErrObjectKey
+		}
+	}
+	return nil
+}
@@ -8,8 +8,11 @@ import (
 	"hr-system-go/app/plugins/mysql"
 	"hr-system-go/app/plugins/redis"
 	"hr-system-go/internal/attendance"
+	"hr-system-go/internal/audit"
 	"hr-system-go/internal/auth"
+	"hr-system-go/internal/customfield"
 	"hr-system-go/internal/department"
+	"hr-system-go/internal/policy"
 	"hr-system-go/internal/session"
 	"hr-system-go/internal/user"
 
@@ -23,6 +26,9 @@ func main() {
 	app.AddModule(&attendance.AttendanceModule{})
 	app.AddModule(&session.SessionModule{})
 	app.AddModule(&department.DepartmentModule{})
+	app.AddModule(&audit.AuditModule{})
+	app.AddModule(&policy.PolicyModule{})
+	app.AddModule(&customfield.CustomFieldModule{})
 
 	app.Run(func(
 		env *env.Env,
@@ -33,6 +39,9 @@ func main() {
 		attendanceModule *attendance.AttendanceModule,
 		sessionModule *session.SessionModule,
 		departmentModule *department.DepartmentModule,
+		auditModule *audit.AuditModule,
+		policyModule *policy.PolicyModule,
+		customFieldModule *customfield.CustomFieldModule,
 		mysql *mysql.MySqlStore,
 		redis *redis.RedisStore,
 	) {
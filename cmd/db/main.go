@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
 	"hr-system-go/app/plugins/mysql"
 	"hr-system-go/database/migrations"
 	"hr-system-go/database/seeds"
+	auth_services "hr-system-go/internal/auth/services"
+	user_constants "hr-system-go/internal/user/constants"
+	user_services "hr-system-go/internal/user/services"
 	"os"
 	"strings"
 
@@ -88,6 +93,76 @@ func main() {
 			return
 		}
 		logger.Info(fmt.Sprintf("Run Seed %s completed successfully", filename))
+	case "keys:generate", "keys:rotate":
+		if len(os.Args) > 3 {
+			logger.Error(fmt.Sprintf("Usage: %s %s [RS256|EdDSA]", os.Args[0], os.Args[1]))
+			return
+		}
+		// algorithm argument overrides SIGNING_KEY_ALGORITHM for this run
+		if len(os.Args) == 3 {
+			os.Setenv("SIGNING_KEY_ALGORITHM", os.Args[2])
+		}
+		db := DBConnect(env, logger)
+		defer db.Close()
+		keyRing := auth_services.NewKeyRing(logger, env, db)
+		rotate := keyRing.EnsureActiveKey
+		if os.Args[1] == "keys:rotate" {
+			rotate = keyRing.Rotate
+		}
+		if err := rotate(); err != nil {
+			logger.Error("Failed to generate signing key", zap.Error(err))
+			return
+		}
+		logger.Info("Signing keys are ready", zap.String("Algorithm", keyRing.Algorithm()))
+	case "keys:list":
+		db := DBConnect(env, logger)
+		defer db.Close()
+		keys, err := auth_services.NewKeyRing(logger, env, db).Keys()
+		if err != nil {
+			logger.Error("Failed to list signing keys", zap.Error(err))
+			return
+		}
+		for _, key := range keys {
+			logger.Info(key.Kid, zap.String("Algorithm", key.Algorithm), zap.String("Status", key.Status), zap.Time("ActivatedAt", key.ActivatedAt))
+		}
+	case "employment:apply":
+		db := DBConnect(env, logger)
+		defer db.Close()
+		applied, err := user_services.ApplyDueEmploymentRecords(context.Background(), logger, db)
+		if err != nil {
+			logger.Error("Failed to apply employment records", zap.Error(err))
+			return
+		}
+		logger.Info("Applied employment records", zap.Int("Users", applied))
+	case "lifecycle:apply":
+		db := DBConnect(env, logger)
+		defer db.Close()
+		changed, err := user_services.ApplyDueLifecycle(context.Background(), logger, db)
+		if err != nil {
+			logger.Error("Failed to apply lifecycle changes", zap.Error(err))
+			return
+		}
+		logger.Info("Applied lifecycle changes", zap.Int("Users", changed))
+	case "contracts:remind":
+		db := DBConnect(env, logger)
+		defer db.Close()
+		days := env.GetEnvInt("CONTRACT_REMINDER_DAYS", user_constants.CONTRACT_DEFAULT_REMINDER_DAYS)
+		sent, err := user_services.SendContractReminders(context.Background(), logger, db, mailer.NewMailer(env, logger), days, env.GetEnv("CONTRACT_REMINDER_HR_EMAILS"))
+		if err != nil {
+			logger.Error("Failed to send contract reminders", zap.Error(err))
+			return
+		}
+		logger.Info("Sent contract reminders", zap.Int("Reminders", sent))
+	case "documents:remind":
+		db := DBConnect(env, logger)
+		defer db.Close()
+		days := env.GetEnvInt("DOCUMENT_EXPIRY_REMINDER_DAYS", user_constants.DOCUMENT_DEFAULT_EXPIRY_REMINDER_DAYS)
+		sent, err := user_services.SendDocumentExpiryReminders(context.Background(), logger, db, mailer.NewMailer(env, logger), days)
+		if err != nil {
+			logger.Error("Failed to send document expiry reminders", zap.Error(err))
+			return
+		}
+		logger.Info("Sent document expiry reminders", zap.Int("Reminders", sent))
 	default:
 		logger.Error(fmt.Sprintf("Unknown command: %s", os.Args[1]))
 	}
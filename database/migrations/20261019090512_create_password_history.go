@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_password_history",
+		Timestamp: "20261019090512",
+		Up:        Up_20261019090512,
+		Down:      Down_20261019090512,
+	})
+}
+
+func Up_20261019090512(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&models.User{}, "PasswordChangedAt") {
+		if err := migrator.AddColumn(&models.User{}, "PasswordChangedAt"); err != nil {
+			return err
+		}
+	}
+	// existing passwords stay NULL, they start to age once changed under the policy
+	return db.AutoMigrate(&models.PasswordHistory{})
+}
+
+func Down_20261019090512(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(&models.PasswordHistory{}); err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&models.User{}, "PasswordChangedAt")
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"hr-system-go/internal/auth/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "add_role_version",
+		Timestamp: "20261019113045",
+		Up:        Up_20261019113045,
+		Down:      Down_20261019113045,
+	})
+}
+
+func Up_20261019113045(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasColumn(&models.Role{}, "Version") {
+		return nil
+	}
+	return migrator.AddColumn(&models.Role{}, "Version")
+}
+
+func Down_20261019113045(db *gorm.DB) error {
+	return db.Migrator().DropColumn(&models.Role{}, "Version")
+}
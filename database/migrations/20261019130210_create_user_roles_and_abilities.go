@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"hr-system-go/internal/auth/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_user_roles_and_abilities",
+		Timestamp: "20261019130210",
+		Up:        Up_20261019130210,
+		Down:      Down_20261019130210,
+	})
+}
+
+func Up_20261019130210(db *gorm.DB) error {
+	return db.AutoMigrate(&models.UserRole{}, &models.UserAbility{})
+}
+
+func Down_20261019130210(db *gorm.DB) error {
+	return db.Migrator().DropTable(&models.UserRole{}, &models.UserAbility{})
+}
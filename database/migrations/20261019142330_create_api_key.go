@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	auth_models "hr-system-go/internal/auth/models"
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_api_key",
+		Timestamp: "20261019142330",
+		Up:        Up_20261019142330,
+		Down:      Down_20261019142330,
+	})
+}
+
+func Up_20261019142330(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&user_models.User{}, "Type") {
+		if err := migrator.AddColumn(&user_models.User{}, "Type"); err != nil {
+			return err
+		}
+	}
+	return db.AutoMigrate(&auth_models.ApiKey{})
+}
+
+func Down_20261019142330(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(&auth_models.ApiKey{}); err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&user_models.User{}, "Type")
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	auth_models "hr-system-go/internal/auth/models"
+	session_models "hr-system-go/internal/session/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_signing_key_and_oauth_client",
+		Timestamp: "20261019153050",
+		Up:        Up_20261019153050,
+		Down:      Down_20261019153050,
+	})
+}
+
+func Up_20261019153050(db *gorm.DB) error {
+	return db.AutoMigrate(&auth_models.SigningKey{}, &session_models.OAuthClient{})
+}
+
+func Down_20261019153050(db *gorm.DB) error {
+	return db.Migrator().DropTable(&auth_models.SigningKey{}, &session_models.OAuthClient{})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	session_models "hr-system-go/internal/session/models"
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_external_identity",
+		Timestamp: "20261019163015",
+		Up:        Up_20261019163015,
+		Down:      Down_20261019163015,
+	})
+}
+
+func Up_20261019163015(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&user_models.User{}, "PasswordLoginDisabled") {
+		if err := migrator.AddColumn(&user_models.User{}, "PasswordLoginDisabled"); err != nil {
+			return err
+		}
+	}
+	return db.AutoMigrate(&session_models.ExternalIdentity{})
+}
+
+func Down_20261019163015(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(&session_models.ExternalIdentity{}); err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&user_models.User{}, "PasswordLoginDisabled")
+}
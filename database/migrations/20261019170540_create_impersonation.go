@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	auth_models "hr-system-go/internal/auth/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_impersonation",
+		Timestamp: "20261019170540",
+		Up:        Up_20261019170540,
+		Down:      Down_20261019170540,
+	})
+}
+
+func Up_20261019170540(db *gorm.DB) error {
+	return db.AutoMigrate(&auth_models.Impersonation{})
+}
+
+func Down_20261019170540(db *gorm.DB) error {
+	return db.Migrator().DropTable(&auth_models.Impersonation{})
+}
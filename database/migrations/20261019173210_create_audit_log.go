@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	audit_models "hr-system-go/internal/audit/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_audit_log",
+		Timestamp: "20261019173210",
+		Up:        Up_20261019173210,
+		Down:      Down_20261019173210,
+	})
+}
+
+func Up_20261019173210(db *gorm.DB) error {
+	return db.AutoMigrate(&audit_models.AuditLog{})
+}
+
+func Down_20261019173210(db *gorm.DB) error {
+	return db.Migrator().DropTable(&audit_models.AuditLog{})
+}
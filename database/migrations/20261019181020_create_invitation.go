@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_invitation",
+		Timestamp: "20261019181020",
+		Up:        Up_20261019181020,
+		Down:      Down_20261019181020,
+	})
+}
+
+func Up_20261019181020(db *gorm.DB) error {
+	return db.AutoMigrate(&user_models.Invitation{})
+}
+
+func Down_20261019181020(db *gorm.DB) error {
+	return db.Migrator().DropTable(&user_models.Invitation{})
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "add_user_email_verification",
+		Timestamp: "20261019184030",
+		Up:        Up_20261019184030,
+		Down:      Down_20261019184030,
+	})
+}
+
+func Up_20261019184030(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, column := range []string{"EmailVerifiedAt", "PendingEmail"} {
+		if migrator.HasColumn(&user_models.User{}, column) {
+			continue
+		}
+		if err := migrator.AddColumn(&user_models.User{}, column); err != nil {
+			return err
+		}
+	}
+
+	// existing accounts were trusted before verification existed, requiring it must not lock them out
+	err := db.Model(&user_models.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at")).Error
+	if err != nil {
+		return err
+	}
+
+	return db.AutoMigrate(&user_models.EmailVerification{})
+}
+
+func Down_20261019184030(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(&user_models.EmailVerification{}); err != nil {
+		return err
+	}
+	for _, column := range []string{"EmailVerifiedAt", "PendingEmail"} {
+		if err := db.Migrator().DropColumn(&user_models.User{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
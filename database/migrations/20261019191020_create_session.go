@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	auth_models "hr-system-go/internal/auth/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_session",
+		Timestamp: "20261019191020",
+		Up:        Up_20261019191020,
+		Down:      Down_20261019191020,
+	})
+}
+
+func Up_20261019191020(db *gorm.DB) error {
+	return db.AutoMigrate(&auth_models.Session{})
+}
+
+func Down_20261019191020(db *gorm.DB) error {
+	return db.Migrator().DropTable(&auth_models.Session{})
+}
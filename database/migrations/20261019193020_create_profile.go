@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_profile",
+		Timestamp: "20261019193020",
+		Up:        Up_20261019193020,
+		Down:      Down_20261019193020,
+	})
+}
+
+// age is kept, deprecated, until HR has filled in date of birth of existing employees, a later migration drops it.
+// It becomes nullable since users are created without it
+func Up_20261019193020(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&user_models.User{}, "DateOfBirth") {
+		if err := migrator.AddColumn(&user_models.User{}, "DateOfBirth"); err != nil {
+			return err
+		}
+	}
+	if migrator.HasColumn(&user_models.User{}, "age") {
+		if err := db.Exec("ALTER TABLE user MODIFY COLUMN age bigint NULL").Error; err != nil {
+			return err
+		}
+	}
+
+	return db.AutoMigrate(&user_models.Profile{})
+}
+
+func Down_20261019193020(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(&user_models.Profile{}); err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&user_models.User{}, "DateOfBirth")
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_employment_record",
+		Timestamp: "20261019195510",
+		Up:        Up_20261019195510,
+		Down:      Down_20261019195510,
+	})
+}
+
+func Up_20261019195510(db *gorm.DB) error {
+	if err := db.AutoMigrate(&user_models.EmploymentRecord{}); err != nil {
+		return err
+	}
+
+	// history starts with current position of every employee, effective from their join date
+	return db.Exec(`INSERT INTO employment_record (user_id, effective_from, employment_type, fte, salary, department_id, applied_at, created_at, updated_at)
+		SELECT id, DATE(join_date), 'full_time', 1, salary, department_id, NOW(), NOW(), NOW() FROM user
+		WHERE status != 'removed' AND NOT EXISTS (SELECT 1 FROM employment_record WHERE employment_record.user_id = user.id)`).Error
+}
+
+func Down_20261019195510(db *gorm.DB) error {
+	return db.Migrator().DropTable(&user_models.EmploymentRecord{})
+}
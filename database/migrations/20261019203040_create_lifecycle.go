@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_lifecycle",
+		Timestamp: "20261019203040",
+		Up:        Up_20261019203040,
+		Down:      Down_20261019203040,
+	})
+}
+
+// existing employees are active, the ones hired for a later date wait in pre-hire
+func Up_20261019203040(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, column := range []string{"LifecycleState", "ProbationEndDate"} {
+		if migrator.HasColumn(&user_models.User{}, column) {
+			continue
+		}
+		if err := migrator.AddColumn(&user_models.User{}, column); err != nil {
+			return err
+		}
+	}
+	if err := db.Exec("UPDATE user SET lifecycle_state = 'pre_hire' WHERE DATE(join_date) > CURDATE()").Error; err != nil {
+		return err
+	}
+
+	return db.AutoMigrate(
+		&user_models.Termination{},
+		&user_models.ChecklistTemplate{},
+		&user_models.ChecklistTemplateTask{},
+		&user_models.Checklist{},
+		&user_models.ChecklistTask{},
+	)
+}
+
+func Down_20261019203040(db *gorm.DB) error {
+	err := db.Migrator().DropTable(
+		&user_models.ChecklistTask{},
+		&user_models.Checklist{},
+		&user_models.ChecklistTemplateTask{},
+		&user_models.ChecklistTemplate{},
+		&user_models.Termination{},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range []string{"ProbationEndDate", "LifecycleState"} {
+		if err := db.Migrator().DropColumn(&user_models.User{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_contract",
+		Timestamp: "20261019210010",
+		Up:        Up_20261019210010,
+		Down:      Down_20261019210010,
+	})
+}
+
+func Up_20261019210010(db *gorm.DB) error {
+	return db.AutoMigrate(&user_models.Contract{})
+}
+
+func Down_20261019210010(db *gorm.DB) error {
+	return db.Migrator().DropTable(&user_models.Contract{})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	attendance_models "hr-system-go/internal/attendance/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_clock_record_user_clock_in_index",
+		Timestamp: "20261019213020",
+		Up:        Up_20261019213020,
+		Down:      Down_20261019213020,
+	})
+}
+
+// cursor pages of clock records seek by user and clock in time
+func Up_20261019213020(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasIndex(&attendance_models.ClockRecord{}, "idx_user_clock_in") {
+		err := migrator.CreateIndex(&attendance_models.ClockRecord{}, "idx_user_clock_in")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Down_20261019213020(db *gorm.DB) error {
+	err := db.Migrator().DropIndex(&attendance_models.ClockRecord{}, "idx_user_clock_in")
+	return err
+}
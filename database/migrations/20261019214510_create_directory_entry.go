@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_directory_entry",
+		Timestamp: "20261019214510",
+		Up:        Up_20261019214510,
+		Down:      Down_20261019214510,
+	})
+}
+
+func Up_20261019214510(db *gorm.DB) error {
+	return db.AutoMigrate(&user_models.DirectoryEntry{})
+}
+
+func Down_20261019214510(db *gorm.DB) error {
+	return db.Migrator().DropTable(&user_models.DirectoryEntry{})
+}
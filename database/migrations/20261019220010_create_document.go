@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_document",
+		Timestamp: "20261019220010",
+		Up:        Up_20261019220010,
+		Down:      Down_20261019220010,
+	})
+}
+
+func Up_20261019220010(db *gorm.DB) error {
+	return db.AutoMigrate(&user_models.DocumentCategory{}, &user_models.Document{})
+}
+
+func Down_20261019220010(db *gorm.DB) error {
+	return db.Migrator().DropTable(&user_models.Document{}, &user_models.DocumentCategory{})
+}
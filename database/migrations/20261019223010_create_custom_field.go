@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	customfield_models "hr-system-go/internal/customfield/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_custom_field",
+		Timestamp: "20261019223010",
+		Up:        Up_20261019223010,
+		Down:      Down_20261019223010,
+	})
+}
+
+func Up_20261019223010(db *gorm.DB) error {
+	return db.AutoMigrate(&customfield_models.CustomField{}, &customfield_models.CustomFieldValue{})
+}
+
+func Down_20261019223010(db *gorm.DB) error {
+	return db.Migrator().DropTable(&customfield_models.CustomFieldValue{}, &customfield_models.CustomField{})
+}
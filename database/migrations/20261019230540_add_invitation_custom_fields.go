@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "add_invitation_custom_fields",
+		Timestamp: "20261019230540",
+		Up:        Up_20261019230540,
+		Down:      Down_20261019230540,
+	})
+}
+
+func Up_20261019230540(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasColumn(&user_models.Invitation{}, "CustomFields") {
+		return nil
+	}
+	return migrator.AddColumn(&user_models.Invitation{}, "CustomFields")
+}
+
+func Down_20261019230540(db *gorm.DB) error {
+	return db.Migrator().DropColumn(&user_models.Invitation{}, "CustomFields")
+}
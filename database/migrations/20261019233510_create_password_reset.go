@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Migrations = append(Migrations, MigrationPair{
+		Name:      "create_password_reset",
+		Timestamp: "20261019233510",
+		Up:        Up_20261019233510,
+		Down:      Down_20261019233510,
+	})
+}
+
+func Up_20261019233510(db *gorm.DB) error {
+	return db.AutoMigrate(&user_models.PasswordReset{})
+}
+
+func Down_20261019233510(db *gorm.DB) error {
+	return db.Migrator().DropTable(&user_models.PasswordReset{})
+}
@@ -2,6 +2,7 @@ package seeds
 
 import (
 	user_models "hr-system-go/internal/user/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,29 +17,29 @@ func init() {
 func Exec_20240710120332(db *gorm.DB) error {
 	users := []user_models.User{
 		{
-			Name:  "John Doe",
-			Email: "john.doe@example.com",
-			Age:   22,
+			Name:        "John Doe",
+			Email:       "john.doe@example.com",
+			DateOfBirth: birthDate(2004, time.March, 14),
 		},
 		{
-			Name:  "Jane Smith",
-			Email: "jane.smith@example.com",
-			Age:   30,
+			Name:        "Jane Smith",
+			Email:       "jane.smith@example.com",
+			DateOfBirth: birthDate(1996, time.July, 2),
 		},
 		{
-			Name:  "Bob Johnson",
-			Email: "bob.johnson@example.com",
-			Age:   35,
+			Name:        "Bob Johnson",
+			Email:       "bob.johnson@example.com",
+			DateOfBirth: birthDate(1991, time.January, 23),
 		},
 		{
-			Name:  "Alice Brown",
-			Email: "alice.brown@example.com",
-			Age:   24,
+			Name:        "Alice Brown",
+			Email:       "alice.brown@example.com",
+			DateOfBirth: birthDate(2002, time.October, 9),
 		},
 		{
-			Name:  "Charlie Wilson",
-			Email: "charlie.wilson@example.com",
-			Age:   35,
+			Name:        "Charlie Wilson",
+			Email:       "charlie.wilson@example.com",
+			DateOfBirth: birthDate(1990, time.November, 5),
 		},
 	}
 	for _, user := range users {
@@ -49,3 +50,8 @@ func Exec_20240710120332(db *gorm.DB) error {
 	}
 	return nil
 }
+
+func birthDate(year int, month time.Month, day int) *time.Time {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &date
+}
@@ -22,9 +22,9 @@ func init() {
 func Exec_20240711213135(db *gorm.DB) error {
 	// TODO: Implement the seed logic here
 	user := &models.User{
-		Name:  "HR Manager User",
-		Email: "hr-manager@gmail.com",
-		Age:   31,
+		Name:        "HR Manager User",
+		Email:       "hr-manager@gmail.com",
+		DateOfBirth: birthDate(1995, time.June, 18),
 	}
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("HrManager123"), bcrypt.DefaultCost)
 	user.PasswordEncrypt = string(hashedPassword)
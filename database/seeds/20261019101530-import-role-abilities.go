@@ -0,0 +1,32 @@
+package seeds
+
+import (
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Seeds = append(Seeds, Seed{
+		Name: "20261019101530-import-role-abilities",
+		Exec: Exec_20261019101530,
+	})
+}
+
+// role management abilities, HR Manager already has them through admin ability
+func Exec_20261019101530(db *gorm.DB) error {
+	abilityNames := []string{
+		constants.ABILITY_READ_ROLE,
+		constants.ABILITY_READ_WRITE_ROLE,
+		constants.ABILITY_DELETE_ROLE,
+	}
+
+	for _, name := range abilityNames {
+		var ability models.Ability
+		if err := db.Where("name = ?", name).FirstOrCreate(&ability, models.Ability{Name: name}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
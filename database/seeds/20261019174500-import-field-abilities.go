@@ -0,0 +1,44 @@
+package seeds
+
+import (
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Seeds = append(Seeds, Seed{
+		Name: "20261019174500-import-field-abilities",
+		Exec: Exec_20261019174500,
+	})
+}
+
+// sensitive user field abilities are granted to HR, HR Manager already has them through admin ability
+func Exec_20261019174500(db *gorm.DB) error {
+	abilityNames := []string{
+		constants.ABILITY_READ_SALARY,
+		constants.ABILITY_WRITE_SALARY,
+		constants.ABILITY_READ_PERSONAL_DATA,
+		constants.ABILITY_WRITE_EMPLOYMENT,
+	}
+
+	abilities := []models.Ability{}
+	for _, name := range abilityNames {
+		var ability models.Ability
+		if err := db.Where("name = ?", name).FirstOrCreate(&ability, models.Ability{Name: name}).Error; err != nil {
+			return err
+		}
+		abilities = append(abilities, ability)
+	}
+
+	var role models.Role
+	if err := db.Where("name = ?", constants.ROLE_HR).FirstOrCreate(&role, models.Role{Name: constants.ROLE_HR}).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&role).Association("Abilities").Append(abilities); err != nil {
+		return err
+	}
+	// permission cache is keyed on role version, HR users would keep their cached abilities
+	return models.BumpVersion(db, role.ID)
+}
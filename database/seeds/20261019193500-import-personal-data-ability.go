@@ -0,0 +1,32 @@
+package seeds
+
+import (
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Seeds = append(Seeds, Seed{
+		Name: "20261019193500-import-personal-data-ability",
+		Exec: Exec_20261019193500,
+	})
+}
+
+// HR maintains identity data and profiles of employees, HR Manager already can through admin ability
+func Exec_20261019193500(db *gorm.DB) error {
+	var ability models.Ability
+	if err := db.Where("name = ?", constants.ABILITY_WRITE_PERSONAL_DATA).FirstOrCreate(&ability, models.Ability{Name: constants.ABILITY_WRITE_PERSONAL_DATA}).Error; err != nil {
+		return err
+	}
+
+	var role models.Role
+	if err := db.Where("name = ?", constants.ROLE_HR).FirstOrCreate(&role, models.Role{Name: constants.ROLE_HR}).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&role).Association("Abilities").Append(&ability); err != nil {
+		return err
+	}
+	return models.BumpVersion(db, role.ID)
+}
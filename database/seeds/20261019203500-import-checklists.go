@@ -0,0 +1,72 @@
+package seeds
+
+import (
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/models"
+	user_constants "hr-system-go/internal/user/constants"
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Seeds = append(Seeds, Seed{
+		Name: "20261019203500-import-checklists",
+		Exec: Exec_20261019203500,
+	})
+}
+
+// IT works on equipment and accounts of new and leaving employees, default checklists are a starting point for HR
+func Exec_20261019203500(db *gorm.DB) error {
+	var ability models.Ability
+	if err := db.Where("name = ?", constants.ABILITY_COMPLETE_IT_TASKS).FirstOrCreate(&ability, models.Ability{Name: constants.ABILITY_COMPLETE_IT_TASKS}).Error; err != nil {
+		return err
+	}
+
+	var role models.Role
+	if err := db.Where("name = ?", constants.ROLE_IT).FirstOrCreate(&role, models.Role{Name: constants.ROLE_IT}).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&role).Association("Abilities").Append(&ability); err != nil {
+		return err
+	}
+	if err := models.BumpVersion(db, role.ID); err != nil {
+		return err
+	}
+
+	templates := []user_models.ChecklistTemplate{
+		{
+			Name: "Onboarding",
+			Kind: user_constants.CHECKLIST_KIND_ONBOARDING,
+			Tasks: []user_models.ChecklistTemplateTask{
+				{Position: 0, Title: "Collect signed contract and personal data", Assignee: user_constants.CHECKLIST_ASSIGNEE_HR, DueOffsetDays: -7},
+				{Position: 1, Title: "Prepare laptop and accounts", Assignee: user_constants.CHECKLIST_ASSIGNEE_IT, DueOffsetDays: -2},
+				{Position: 2, Title: "Plan first week and assign a buddy", Assignee: user_constants.CHECKLIST_ASSIGNEE_MANAGER, DueOffsetDays: -1},
+				{Position: 3, Title: "Hold probation review", Assignee: user_constants.CHECKLIST_ASSIGNEE_MANAGER, DueOffsetDays: 90},
+			},
+		},
+		{
+			Name: "Offboarding",
+			Kind: user_constants.CHECKLIST_KIND_OFFBOARDING,
+			Tasks: []user_models.ChecklistTemplateTask{
+				{Position: 0, Title: "Plan handover of responsibilities", Assignee: user_constants.CHECKLIST_ASSIGNEE_MANAGER, DueOffsetDays: -14},
+				{Position: 1, Title: "Hold exit interview", Assignee: user_constants.CHECKLIST_ASSIGNEE_HR, DueOffsetDays: -3},
+				{Position: 2, Title: "Collect equipment and revoke accounts", Assignee: user_constants.CHECKLIST_ASSIGNEE_IT, DueOffsetDays: 0},
+				{Position: 3, Title: "Issue final payslip and employment certificate", Assignee: user_constants.CHECKLIST_ASSIGNEE_HR, DueOffsetDays: 7},
+			},
+		},
+	}
+	for _, template := range templates {
+		var count int64
+		if err := db.Model(&user_models.ChecklistTemplate{}).Where("kind = ?", template.Kind).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := db.Create(&template).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package seeds
+
+import (
+	"hr-system-go/internal/auth/constants"
+	user_models "hr-system-go/internal/user/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Seeds = append(Seeds, Seed{
+		Name: "20261019220500-import-document-categories",
+		Exec: Exec_20261019220500,
+	})
+}
+
+// Default categories cover what HR keeps for everyone, admins can add more or change abilities later
+func Exec_20261019220500(db *gorm.DB) error {
+	categories := []user_models.DocumentCategory{
+		{
+			Name:         "Contracts",
+			Description:  "Signed employment contracts and amendments",
+			ReadAbility:  constants.ABILITY_READ_PERSONAL_DATA,
+			WriteAbility: constants.ABILITY_WRITE_EMPLOYMENT,
+		},
+		{
+			Name:           "Identity documents",
+			Description:    "Passports, visas and work permits",
+			ReadAbility:    constants.ABILITY_READ_PERSONAL_DATA,
+			WriteAbility:   constants.ABILITY_WRITE_PERSONAL_DATA,
+			ExpiryRequired: true,
+		},
+		{
+			Name:         "Certificates",
+			Description:  "Trainings, diplomas and certifications uploaded by employees",
+			ReadAbility:  constants.ABILITY_READ_PERSONAL_DATA,
+			WriteAbility: constants.ABILITY_WRITE_PERSONAL_DATA,
+			SelfService:  true,
+		},
+	}
+	for _, category := range categories {
+		if err := db.Where("name = ?", category.Name).FirstOrCreate(&category).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
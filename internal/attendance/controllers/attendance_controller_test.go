@@ -8,7 +8,8 @@ import (
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/internal/attendance/dtos"
 	"hr-system-go/internal/attendance/models"
-	"hr-system-go/internal/auth/constants"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
 	user_models "hr-system-go/internal/user/models"
 	mock_services "hr-system-go/mocks/services"
 	"hr-system-go/utils"
@@ -35,6 +36,7 @@ var (
 	mockLeaveService       *mock_services.MockLeaveService
 	mockClockRecordService *mock_services.MockClockRecordService
 	mockAuthService        *mock_services.MockAuthService
+	mockPolicy             *mock_services.MockPolicyService
 	router                 *gin.Engine
 	mockEnv                *env.Env
 	mockLogger             *logger.Logger
@@ -48,8 +50,9 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 		mockLeaveService = &mock_services.MockLeaveService{}
 		mockAuthService = &mock_services.MockAuthService{}
 		mockClockRecordService = &mock_services.MockClockRecordService{}
-		leaveController = NewLeaveController(mockLogger, mockLeaveService, mockAuthService)
-		clockRecordController = NewClockRecordController(mockLogger, mockClockRecordService, mockAuthService)
+		mockPolicy = &mock_services.MockPolicyService{}
+		leaveController = NewLeaveController(mockLogger, mockLeaveService, mockAuthService, mockPolicy)
+		clockRecordController = NewClockRecordController(mockLogger, mockClockRecordService, mockAuthService, mockPolicy)
 		router = gin.Default()
 		leaveController.RegisterRoutes(router)
 		clockRecordController.RegisterRoutes(router)
@@ -66,7 +69,7 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 				}
 				totalRows := int64(2)
 
-				mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_LEAVE).Return(true)
+				mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
 				mockLeaveService.On("FindLeavesByUserID", userID, mock.AnythingOfType("*utils.Pagination")).Return(leaves, totalRows, nil)
 
 				req, _ := http.NewRequest("GET", "/api/users/"+userId+"/leave", nil)
@@ -84,9 +87,8 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 			It("should return error when user is not authorized", func() {
 				userId := "1"
-				userID, _ := strconv.Atoi(userId)
 
-				mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_LEAVE).Return(false)
+				mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
 
 				req, _ := http.NewRequest("GET", "/api/users/"+userId+"/leave", nil)
 				w := httptest.NewRecorder()
@@ -105,9 +107,7 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 					leave := &models.Leave{UserID: uint(userID), StartDate: time.Now(), EndDate: time.Now().Add(24 * time.Hour)}
 
-					mockUser := &user_models.User{}
-					mockUser.ID = uint(userID)
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(mockUser)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
 					mockLeaveService.On("FindLeaveByID", leaveID).Return(leave, nil)
 
 					req, _ := http.NewRequest("GET", "/api/users/"+userId+"/leave/"+leaveId, nil)
@@ -123,13 +123,14 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 					Expect(response["leave"]).NotTo(BeNil())
 				})
 
-				It("should return error when leave doesn't belong to user", func() {
+				It("should return error when user is not authorized", func() {
 					userId := "1"
 					leaveId := "1"
+					leaveID, _ := strconv.Atoi(leaveId)
 
-					mockUser := &user_models.User{}
-					mockUser.ID = uint(2)
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(mockUser)
+					leave := &models.Leave{UserID: 1}
+					mockLeaveService.On("FindLeaveByID", leaveID).Return(leave, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
 
 					req, _ := http.NewRequest("GET", "/api/users/"+userId+"/leave/"+leaveId, nil)
 					w := httptest.NewRecorder()
@@ -138,6 +139,39 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 					Expect(w.Code).To(Equal(http.StatusForbidden))
 				})
+
+				It("should return not found when leave doesn't belong to user", func() {
+					userId := "1"
+					leaveId := "1"
+					leaveID, _ := strconv.Atoi(leaveId)
+
+					leave := &models.Leave{UserID: 2}
+					mockLeaveService.On("FindLeaveByID", leaveID).Return(leave, nil)
+
+					req, _ := http.NewRequest("GET", "/api/users/"+userId+"/leave/"+leaveId, nil)
+					w := httptest.NewRecorder()
+
+					router.ServeHTTP(w, req)
+
+					Expect(w.Code).To(Equal(http.StatusNotFound))
+					mockPolicy.AssertNotCalled(GinkgoT(), "Authorize", mock.Anything, mock.Anything, mock.Anything)
+				})
+
+				It("should authorize against the owner of the leave", func() {
+					leave := &models.Leave{UserID: 2}
+					leave.ID = 1
+					mockLeaveService.On("FindLeaveByID", 1).Return(leave, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.MatchedBy(func(resource policy_models.Resource) bool {
+						return resource.OwnerID == 2 && resource.ID == 1
+					}), policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+
+					req, _ := http.NewRequest("GET", "/api/users/2/leave/1", nil)
+					w := httptest.NewRecorder()
+
+					router.ServeHTTP(w, req)
+
+					Expect(w.Code).To(Equal(http.StatusOK))
+				})
 			})
 
 			Describe("createLeave", func() {
@@ -158,6 +192,7 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 					leave := &models.Leave{UserID: uint(userID), StartDate: st, EndDate: et, LeaveType: leaveType}
 					user := &user_models.User{}
 					user.ID = uint(userID)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: true})
 					mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
 					mockLeaveService.On("CreateLeaveByUser", mock.AnythingOfType("*models.User"), payload).Return(leave, nil)
 
@@ -178,7 +213,6 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 				It("should return error when creating leave for another user", func() {
 					userId := "1"
-					userID, _ := strconv.Atoi(userId)
 					sts := "2024-07-01T15:04:05+08:00"
 					ets := "2024-07-02T15:04:05+08:00"
 					leaveType := "annual"
@@ -187,9 +221,7 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 						EndDate:   &ets,
 						LeaveType: &leaveType,
 					}
-					user := &user_models.User{}
-					user.ID = uint(userID + 1)
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: false})
 
 					jsonPayload, _ := json.Marshal(payload)
 					req, _ := http.NewRequest("POST", "/api/users/"+userId+"/leave", bytes.NewBuffer(jsonPayload))
@@ -222,9 +254,8 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 					updatedLeave := &models.Leave{UserID: uint(userID), StartDate: st, EndDate: et, LeaveType: *payload.LeaveType}
 					updatedLeave.ID = uint(leaveID)
-					user := &user_models.User{}
-					user.ID = uint(userID)
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+					mockLeaveService.On("FindLeaveByID", leaveID).Return(&models.Leave{UserID: uint(userID)}, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
 					mockLeaveService.On("UpdateLeaveByID", leaveID, payload).Return(updatedLeave, nil)
 
 					jsonPayload, _ := json.Marshal(payload)
@@ -255,10 +286,9 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 						EndDate:   &ets,
 						LeaveType: &leaveType,
 					}
-					user := &user_models.User{}
-					user.ID = uint(userID + 1)
 
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+					mockLeaveService.On("FindLeaveByID", 1).Return(&models.Leave{UserID: uint(userID)}, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: false})
 
 					jsonPayload, _ := json.Marshal(payload)
 					req, _ := http.NewRequest("PUT", "/api/users/"+userId+"/leave/"+leaveId, bytes.NewBuffer(jsonPayload))
@@ -269,18 +299,56 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 					Expect(w.Code).To(Equal(http.StatusForbidden))
 				})
+
+				It("should check approve policy when status changes", func() {
+					userId := "1"
+					leaveId := "1"
+					userID, _ := strconv.Atoi(userId)
+					status := "approved"
+					payload := dtos.UpdateLeaveRequest{Status: &status}
+
+					mockLeaveService.On("FindLeaveByID", 1).Return(&models.Leave{UserID: uint(userID)}, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.MatchedBy(func(resource policy_models.Resource) bool {
+						return resource.OwnerID == uint(userID)
+					}), policy_constants.POLICY_ACTION_APPROVE).Return(policy_models.Decision{Allowed: false})
+
+					jsonPayload, _ := json.Marshal(payload)
+					req, _ := http.NewRequest("PUT", "/api/users/"+userId+"/leave/"+leaveId, bytes.NewBuffer(jsonPayload))
+					req.Header.Set("Content-Type", "application/json")
+					w := httptest.NewRecorder()
+
+					router.ServeHTTP(w, req)
+
+					Expect(w.Code).To(Equal(http.StatusForbidden))
+					mockPolicy.AssertNotCalled(GinkgoT(), "Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE)
+					mockLeaveService.AssertNotCalled(GinkgoT(), "UpdateLeaveByID", mock.Anything, mock.Anything)
+				})
+
+				It("should return not found when leave belongs to another user", func() {
+					status := "approved"
+					payload := dtos.UpdateLeaveRequest{Status: &status}
+
+					mockLeaveService.On("FindLeaveByID", 1).Return(&models.Leave{UserID: 2}, nil)
+
+					jsonPayload, _ := json.Marshal(payload)
+					req, _ := http.NewRequest("PUT", "/api/users/1/leave/1", bytes.NewBuffer(jsonPayload))
+					req.Header.Set("Content-Type", "application/json")
+					w := httptest.NewRecorder()
+
+					router.ServeHTTP(w, req)
+
+					Expect(w.Code).To(Equal(http.StatusNotFound))
+				})
 			})
 
 			Describe("deleteLeave", func() {
 				It("should delete a leave", func() {
 					userId := "1"
 					leaveId := "1"
-					userID, _ := strconv.Atoi(userId)
 					leaveID, _ := strconv.Atoi(leaveId)
 
-					user := &user_models.User{}
-					user.ID = uint(userID)
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+					mockLeaveService.On("FindLeaveByID", leaveID).Return(&models.Leave{UserID: 1}, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
 					mockLeaveService.On("DeleteLeaveByID", leaveID).Return(nil)
 
 					req, _ := http.NewRequest("DELETE", "/api/users/"+userId+"/leave/"+leaveId, nil)
@@ -291,14 +359,13 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 					Expect(w.Code).To(Equal(http.StatusNoContent))
 				})
 
-				It("should return error when deleting leave for another user", func() {
+				It("should return error when user is not authorized", func() {
 					userId := "1"
 					leaveId := "1"
-					userID, _ := strconv.Atoi(userId)
+					leaveID, _ := strconv.Atoi(leaveId)
 
-					user := &user_models.User{}
-					user.ID = uint(userID + 1)
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+					mockLeaveService.On("FindLeaveByID", leaveID).Return(&models.Leave{UserID: 1}, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: false})
 
 					req, _ := http.NewRequest("DELETE", "/api/users/"+userId+"/leave/"+leaveId, nil)
 					w := httptest.NewRecorder()
@@ -308,15 +375,30 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 					Expect(w.Code).To(Equal(http.StatusForbidden))
 				})
 
+				It("should not delete leave of another user through own url", func() {
+					userId := "1"
+					leaveId := "1"
+					leaveID, _ := strconv.Atoi(leaveId)
+
+					mockLeaveService.On("FindLeaveByID", leaveID).Return(&models.Leave{UserID: 2}, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
+
+					req, _ := http.NewRequest("DELETE", "/api/users/"+userId+"/leave/"+leaveId, nil)
+					w := httptest.NewRecorder()
+
+					router.ServeHTTP(w, req)
+
+					Expect(w.Code).To(Equal(http.StatusNotFound))
+					mockLeaveService.AssertNotCalled(GinkgoT(), "DeleteLeaveByID", mock.Anything)
+				})
+
 				It("should return error when leave deletion fails", func() {
 					userId := "1"
 					leaveId := "1"
-					userID, _ := strconv.Atoi(userId)
 					leaveID, _ := strconv.Atoi(leaveId)
 
-					user := &user_models.User{}
-					user.ID = uint(userID)
-					mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+					mockLeaveService.On("FindLeaveByID", leaveID).Return(&models.Leave{UserID: 1}, nil)
+					mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
 					mockLeaveService.On("DeleteLeaveByID", leaveID).Return(errors.New("deletion failed"))
 
 					req, _ := http.NewRequest("DELETE", "/api/users/"+userId+"/leave/"+leaveId, nil)
@@ -340,7 +422,7 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 				}
 				totalRows := int64(2)
 
-				mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_CLOCK_RECORD).Return(true)
+				mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
 				mockClockRecordService.On("FindClockRecordsByUserID", userID, mock.AnythingOfType("*utils.Pagination")).Return(records, totalRows, nil)
 
 				req, _ := http.NewRequest("GET", "/api/users/"+userId+"/clockRecord", nil)
@@ -358,9 +440,8 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 			It("should return error when user is not authorized", func() {
 				userId := "1"
-				userID, _ := strconv.Atoi(userId)
 
-				mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_CLOCK_RECORD).Return(false)
+				mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
 
 				req, _ := http.NewRequest("GET", "/api/users/"+userId+"/clockRecord", nil)
 				w := httptest.NewRecorder()
@@ -380,6 +461,7 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 				record := &models.ClockRecord{UserID: uint(userID)}
 				record.ID = uint(1)
 
+				mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: true})
 				mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
 				mockClockRecordService.On("ClockByUser", user).Return(record, nil)
 
@@ -398,11 +480,8 @@ var _ = Describe("LeavesController and ClockRecordController", func() {
 
 			It("should return error when creating clock record for another user", func() {
 				userId := "1"
-				userID, _ := strconv.Atoi(userId)
-				user := &user_models.User{}
-				user.ID = uint(userID + 1)
 
-				mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+				mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: false})
 
 				req, _ := http.NewRequest("POST", "/api/users/"+userId+"/clockRecord/clock", nil)
 				w := httptest.NewRecorder()
@@ -6,6 +6,9 @@ import (
 	"hr-system-go/internal/attendance/services"
 	"hr-system-go/internal/auth/constants"
 	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
 	"hr-system-go/utils"
 	"net/http"
 	"strconv"
@@ -15,16 +18,18 @@ import (
 )
 
 type ClockRecordController struct {
-	logger      *logger.Logger
-	service     services.ClockRecordServiceInterface
-	authService auth_service.AuthServiceInterface
+	logger        *logger.Logger
+	service       services.ClockRecordServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
 }
 
-func NewClockRecordController(logger *logger.Logger, service services.ClockRecordServiceInterface, authService auth_service.AuthServiceInterface) *ClockRecordController {
+func NewClockRecordController(logger *logger.Logger, service services.ClockRecordServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *ClockRecordController {
 	return &ClockRecordController{
-		logger:      logger,
-		service:     service,
-		authService: authService,
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
 	}
 }
 
@@ -46,14 +51,18 @@ func (c *ClockRecordController) listClockRecord(ctx *gin.Context) {
 		return
 	}
 
-	if !c.authService.AbleToAccessOtherUserData(ctx, userID, constants.ABILITY_ALL_GRANTS_CLOCK_RECORD) {
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_READ) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
 
 	pagination := utils.NewPagination(ctx)
 	records, totalRows, err := c.service.FindClockRecordsByUserID(userID, &pagination)
-	if err != nil {
+	switch {
+	case utils.IsQueryError(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
 		c.logger.Error("Failed to Find User", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
@@ -71,15 +80,13 @@ func (c *ClockRecordController) touchClockRecord(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
-	currentUser := c.authService.GetCurrentUser(ctx)
-	// only self can clock in/out
-	if userID != int(currentUser.ID) {
-		c.logger.Error("Cannot create Clock Record which not belong currentUser")
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_CREATE) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
+	currentUser := c.authService.GetCurrentUser(ctx)
 
-	record, err := c.service.ClockByUser(currentUser)
+	record, err := c.service.ClockByUser(ctx, currentUser)
 	if err != nil {
 		c.logger.Error("Cannot not touch ClockRecord", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
@@ -88,3 +95,8 @@ func (c *ClockRecordController) touchClockRecord(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"leave": dtos.NewClockRecordResponse(record)})
 }
+
+func (c *ClockRecordController) authorize(ctx *gin.Context, userID int, action string) bool {
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_CLOCK_RECORD, OwnerID: uint(userID)}
+	return c.policyService.Authorize(ctx, resource, action).Allowed
+}
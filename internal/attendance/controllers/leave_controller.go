@@ -3,9 +3,13 @@ package controllers
 import (
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/internal/attendance/dtos"
+	"hr-system-go/internal/attendance/models"
 	"hr-system-go/internal/attendance/services"
 	"hr-system-go/internal/auth/constants"
 	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
 	"hr-system-go/utils"
 	"net/http"
 	"strconv"
@@ -15,16 +19,18 @@ import (
 )
 
 type LeaveController struct {
-	logger      *logger.Logger
-	service     services.LeaveServiceInterface
-	authService auth_service.AuthServiceInterface
+	logger        *logger.Logger
+	service       services.LeaveServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
 }
 
-func NewLeaveController(logger *logger.Logger, service services.LeaveServiceInterface, authService auth_service.AuthServiceInterface) *LeaveController {
+func NewLeaveController(logger *logger.Logger, service services.LeaveServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *LeaveController {
 	return &LeaveController{
-		logger:      logger,
-		service:     service,
-		authService: authService,
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
 	}
 }
 
@@ -49,14 +55,18 @@ func (c *LeaveController) listLeaves(ctx *gin.Context) {
 		return
 	}
 
-	if !c.authService.AbleToAccessOtherUserData(ctx, userID, constants.ABILITY_ALL_GRANTS_LEAVE) {
+	if !c.authorize(ctx, policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_LEAVE, OwnerID: uint(userID)}, policy_constants.POLICY_ACTION_READ) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
 
 	pagination := utils.NewPagination(ctx)
 	leaves, totalRows, err := c.service.FindLeavesByUserID(userID, &pagination)
-	if err != nil {
+	switch {
+	case utils.IsQueryError(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
 		c.logger.Error("Failed to Find User", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
@@ -74,13 +84,6 @@ func (c *LeaveController) getLeave(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
-	currentUser := c.authService.GetCurrentUser(ctx)
-	// only self read one leave
-	if userID != int(currentUser.ID) {
-		c.logger.Error("Cannot create leave which not belong currentUser")
-		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
-		return
-	}
 
 	leaveId := ctx.Param("id")
 	leaveID, err := strconv.Atoi(leaveId)
@@ -91,9 +94,13 @@ func (c *LeaveController) getLeave(ctx *gin.Context) {
 	}
 
 	leave, err := c.service.FindLeaveByID(leaveID)
-	if err != nil {
+	if err != nil || int(leave.UserID) != userID {
 		c.logger.Error("Cannot not find leave", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, leaveResource(leave), policy_constants.POLICY_ACTION_READ) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
 
@@ -109,13 +116,11 @@ func (c *LeaveController) createLeave(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
-	currentUser := c.authService.GetCurrentUser(ctx)
-	// only self create leave
-	if userID != int(currentUser.ID) {
-		c.logger.Error("Cannot create leave which not belong currentUser")
+	if !c.authorize(ctx, policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_LEAVE, OwnerID: uint(userID)}, policy_constants.POLICY_ACTION_CREATE) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
+	currentUser := c.authService.GetCurrentUser(ctx)
 	var payload dtos.CreateLeaveRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		c.logger.Error("Cannot not parse create payload", zap.Error(err))
@@ -123,7 +128,7 @@ func (c *LeaveController) createLeave(ctx *gin.Context) {
 		return
 	}
 
-	leave, err := c.service.CreateLeaveByUser(currentUser, payload)
+	leave, err := c.service.CreateLeaveByUser(ctx, currentUser, payload)
 	if err != nil {
 		c.logger.Error("Cannot not create leave", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
@@ -142,13 +147,6 @@ func (c *LeaveController) updateLeave(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
-	currentUser := c.authService.GetCurrentUser(ctx)
-	// only self update leave
-	if userID != int(currentUser.ID) {
-		c.logger.Error("Cannot update leave which not belong currentUser")
-		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
-		return
-	}
 	var payload dtos.UpdateLeaveRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		c.logger.Error("Cannot not parse update payload", zap.Error(err))
@@ -164,7 +162,25 @@ func (c *LeaveController) updateLeave(ctx *gin.Context) {
 		return
 	}
 
-	leave, err := c.service.UpdateLeaveByID(leaveID, payload)
+	leave, err := c.service.FindLeaveByID(leaveID)
+	if err != nil || int(leave.UserID) != userID {
+		c.logger.Error("Cannot not find leave", zap.Error(err))
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	resource := leaveResource(leave)
+	// status is decided by approvers, other fields by owner
+	changesDetails := payload.StartDate != nil || payload.EndDate != nil || payload.LeaveType != nil
+	if changesDetails && !c.authorize(ctx, resource, policy_constants.POLICY_ACTION_UPDATE) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+	if payload.Status != nil && !c.authorize(ctx, resource, policy_constants.POLICY_ACTION_APPROVE) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	leave, err = c.service.UpdateLeaveByID(ctx, leaveID, payload)
 	if err != nil {
 		c.logger.Error("Cannot not update leave", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
@@ -183,13 +199,6 @@ func (c *LeaveController) deleteLeave(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
-	currentUser := c.authService.GetCurrentUser(ctx)
-	// only self delete leave
-	if userID != int(currentUser.ID) {
-		c.logger.Error("Cannot Delete leave which not belong currentUser")
-		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
-		return
-	}
 	leaveId := ctx.Param("id")
 	leaveID, err := strconv.Atoi(leaveId)
 	if err != nil {
@@ -197,7 +206,19 @@ func (c *LeaveController) deleteLeave(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
-	if err := c.service.DeleteLeaveByID(leaveID); err != nil {
+
+	leave, err := c.service.FindLeaveByID(leaveID)
+	if err != nil || int(leave.UserID) != userID {
+		c.logger.Error("Cannot not find leave", zap.Error(err))
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, leaveResource(leave), policy_constants.POLICY_ACTION_DELETE) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	if err := c.service.DeleteLeaveByID(ctx, leaveID); err != nil {
 		c.logger.Error("Cannot not delete leave", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
@@ -205,3 +226,18 @@ func (c *LeaveController) deleteLeave(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+func (c *LeaveController) authorize(ctx *gin.Context, resource policy_models.Resource, action string) bool {
+	return c.policyService.Authorize(ctx, resource, action).Allowed
+}
+
+// leaveResource is authorized against the stored owner, never the userId in the URL
+func leaveResource(leave *models.Leave) policy_models.Resource {
+	return policy_models.Resource{
+		Type:         policy_constants.POLICY_RESOURCE_LEAVE,
+		ID:           leave.ID,
+		OwnerID:      leave.UserID,
+		DepartmentID: leave.User.DepartmentID,
+		ManagerID:    leave.ManagerID,
+	}
+}
@@ -40,13 +40,8 @@ func NewClockRecordListResponse(ClockRecords []models.ClockRecord, totalRows int
 	}
 
 	return &ClockRecordListResponse{
-		Items: items,
-		Pagination: utils.PaginationResult{
-			Limit: pagination.Limit,
-			Page:  pagination.Page,
-			Total: totalRows,
-			Sort:  pagination.Sort,
-		},
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
 	}
 }
 
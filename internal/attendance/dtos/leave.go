@@ -40,13 +40,8 @@ func NewLeaveListResponse(leaves []models.Leave, totalRows int64, pagination uti
 	}
 
 	return &LeaveListResponse{
-		Items: items,
-		Pagination: utils.PaginationResult{
-			Limit: pagination.Limit,
-			Page:  pagination.Page,
-			Total: totalRows,
-			Sort:  pagination.Sort,
-		},
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
 	}
 }
 
@@ -8,8 +8,8 @@ import (
 
 type ClockRecord struct {
 	base_models.BaseModel
-	UserID   uint
+	UserID   uint             `gorm:"index:idx_user_clock_in"`
 	User     user_models.User `gorm:"foreignKey:UserID"`
-	ClockIn  time.Time        `gorm:"type:timestamp;default:current_timestamp()"`
+	ClockIn  time.Time        `gorm:"type:timestamp;default:current_timestamp();index:idx_user_clock_in"`
 	ClockOut *time.Time       `gorm:"default:null"`
 }
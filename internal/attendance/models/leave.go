@@ -16,6 +16,8 @@ type Leave struct {
 	EndDate   time.Time       `gorm:"type:timestamp;not null"`
 	LeaveType string          `gorm:"not null"`
 	Status    string          `gorm:"default:'pending'"`
+	// ManagerID is manager of User when leave was loaded, it decides who approves
+	ManagerID *uint `gorm:"-"`
 }
 
 func ValidLeaveScope(db *gorm.DB) *gorm.DB {
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
@@ -9,6 +10,7 @@ import (
 	user_models "hr-system-go/internal/user/models"
 	"hr-system-go/utils"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker/v3"
 	. "github.com/onsi/ginkgo/v2"
@@ -95,6 +97,49 @@ var _ = Describe("LeavesService and ClockRecordService", func() {
 				Expect(records).To(HaveLen(2))
 				Expect(totalCount).To(Equal(int64(2)))
 			})
+
+			It("should walk clock records with cursors", func() {
+				mockUser := &user_models.User{
+					Email: faker.Email(),
+				}
+				mockDB.DB().Create(mockUser)
+				clockIn := time.Date(2024, 7, 12, 9, 0, 0, 0, time.UTC)
+				mockClockRecord := []*models.ClockRecord{}
+				for day := 0; day < 5; day++ {
+					mockClockRecord = append(mockClockRecord, &models.ClockRecord{ClockIn: clockIn.AddDate(0, 0, day), User: *mockUser})
+				}
+				mockDB.DB().Create(&mockClockRecord)
+
+				pagination := utils.Pagination{Limit: 2, Sort: "clockIn desc", CursorMode: true}
+				first, _, err := clockRecordService.FindClockRecordsByUserID(int(mockUser.ID), &pagination)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(first).To(HaveLen(2))
+				Expect(first[0].ID).To(Equal(mockClockRecord[4].ID))
+				Expect(pagination.PrevCursor).To(BeEmpty())
+
+				pagination.Cursor = pagination.NextCursor
+				second, _, err := clockRecordService.FindClockRecordsByUserID(int(mockUser.ID), &pagination)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(second[0].ID).To(Equal(mockClockRecord[2].ID))
+				Expect(second[1].ID).To(Equal(mockClockRecord[1].ID))
+
+				pagination.Cursor = pagination.PrevCursor
+				back, _, err := clockRecordService.FindClockRecordsByUserID(int(mockUser.ID), &pagination)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(back[0].ID).To(Equal(first[0].ID))
+				Expect(back[1].ID).To(Equal(first[1].ID))
+				Expect(pagination.PrevCursor).To(BeEmpty())
+
+				pagination.Cursor = "not-a-cursor"
+				_, _, err = clockRecordService.FindClockRecordsByUserID(int(mockUser.ID), &pagination)
+				Expect(err).To(MatchError(utils.ErrCursor))
+			})
+
+			It("should refuse sort outside of sortable fields", func() {
+				pagination := utils.Pagination{Limit: 10, Sort: "user_id; DROP TABLE clock_record"}
+				_, _, err := clockRecordService.FindClockRecordsByUserID(1, &pagination)
+				Expect(err).To(MatchError(utils.ErrSortField))
+			})
 		})
 		Describe("ClockByUser", func() {
 			BeforeEach(func() {
@@ -107,7 +152,7 @@ var _ = Describe("LeavesService and ClockRecordService", func() {
 					}
 					mockDB.DB().Create(&clockUser)
 
-					record, err := clockRecordService.ClockByUser(clockUser)
+					record, err := clockRecordService.ClockByUser(context.Background(), clockUser)
 					Expect(err).ShouldNot(HaveOccurred())
 					Expect(record.UserID).To(Equal(clockUser.ID))
 					Expect(record.ClockIn).ToNot(BeZero())
@@ -128,7 +173,7 @@ var _ = Describe("LeavesService and ClockRecordService", func() {
 						ClockIn: ci,
 					}
 					mockDB.DB().Create(&existedRecord)
-					record, err := clockRecordService.ClockByUser(clockUser)
+					record, err := clockRecordService.ClockByUser(context.Background(), clockUser)
 
 					Expect(err).ShouldNot(HaveOccurred())
 					Expect(record.UserID).To(Equal(clockUser.ID))
@@ -197,7 +242,7 @@ var _ = Describe("LeavesService and ClockRecordService", func() {
 				}
 				mockDB.DB().Create(mockUser)
 
-				leave, err := leaveService.CreateLeaveByUser(mockUser, payload)
+				leave, err := leaveService.CreateLeaveByUser(context.Background(), mockUser, payload)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(leave.UserID).To(Equal(mockUser.ID))
 				Expect(leave.LeaveType).To(Equal(leaveType))
@@ -225,7 +270,7 @@ var _ = Describe("LeavesService and ClockRecordService", func() {
 				}
 				mockDB.DB().Create(mockLeave)
 
-				leave, err := leaveService.UpdateLeaveByID(int(mockLeave.ID), payload)
+				leave, err := leaveService.UpdateLeaveByID(context.Background(), int(mockLeave.ID), payload)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(leave.ID).To(Equal(mockLeave.ID))
 				Expect(leave.Status).To(Equal(newStatus))
@@ -248,7 +293,7 @@ var _ = Describe("LeavesService and ClockRecordService", func() {
 				}
 				mockDB.DB().Create(mockLeave)
 
-				err := leaveService.DeleteLeaveByID(int(mockLeave.ID))
+				err := leaveService.DeleteLeaveByID(context.Background(), int(mockLeave.ID))
 				Expect(err).ShouldNot(HaveOccurred())
 			})
 		})
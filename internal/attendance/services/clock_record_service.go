@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
@@ -15,7 +16,7 @@ import (
 
 type ClockRecordServiceInterface interface {
 	FindClockRecordsByUserID(userID int, pagination *utils.Pagination) ([]models.ClockRecord, int64, error)
-	ClockByUser(user *user_models.User) (*models.ClockRecord, error)
+	ClockByUser(ctx context.Context, user *user_models.User) (*models.ClockRecord, error)
 }
 
 type ClockRecordService struct {
@@ -30,31 +31,47 @@ func NewClockRecordService(logger *logger.Logger, db *mysql.MySqlStore) ClockRec
 	}
 }
 
+// clockRecordFilters are query parameters of clock record list
+var clockRecordFilters = []utils.Filter{
+	{Param: "clockInFrom", Column: "clock_in", Type: utils.FILTER_TYPE_DATE, Operator: utils.FILTER_OPERATOR_FROM},
+	{Param: "clockInTo", Column: "clock_in", Type: utils.FILTER_TYPE_DATE, Operator: utils.FILTER_OPERATOR_TO},
+}
+
+// clockRecordSortFields leave out clock out, open records have none
+var clockRecordSortFields = utils.SortFields{
+	"clockIn": "clock_in",
+}
+
 func (s *ClockRecordService) FindClockRecordsByUserID(userID int, pagination *utils.Pagination) ([]models.ClockRecord, int64, error) {
 	var records []models.ClockRecord
 	var totalCount int64 = 0
 
-	if err := s.db.Model(&models.ClockRecord{}).Count(&totalCount).Error; err != nil {
+	filter, err := pagination.FilterScope(clockRecordFilters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.db.Model(&models.ClockRecord{}).Scopes(filter).Where("user_id = ?", userID).Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := s.db.Model(&models.ClockRecord{}).Preload("User").Limit(pagination.Limit).Offset(pagination.Offset()).Order(pagination.Sort).Find(&records, "user_id = ?", userID).Error
+	err = pagination.FindPage(s.db.Model(&models.ClockRecord{}).Scopes(filter).Preload("User").Where("user_id = ?", userID), clockRecordSortFields, &records)
 	if err != nil {
 		return nil, 0, err
 	}
 	return records, totalCount, nil
 }
 
-func (s *ClockRecordService) ClockByUser(user *user_models.User) (*models.ClockRecord, error) {
+func (s *ClockRecordService) ClockByUser(ctx context.Context, user *user_models.User) (*models.ClockRecord, error) {
 	var existRecord *models.ClockRecord
-	recordBaseQuery := s.db.DB().Preload("User").Where(&models.ClockRecord{UserID: user.ID}).Where("clock_out is NULL")
+	recordBaseQuery := s.db.DB().WithContext(ctx).Preload("User").Where(&models.ClockRecord{UserID: user.ID}).Where("clock_out is NULL")
 	result := recordBaseQuery.First(&existRecord)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		newRecord := &models.ClockRecord{
 			User:    *user,
 			ClockIn: time.Now(),
 		}
-		if err := s.db.DB().Create(&newRecord).Error; err != nil {
+		if err := s.db.DB().WithContext(ctx).Create(&newRecord).Error; err != nil {
 			s.logger.Error("Clock In Failed", zap.Error(err))
 			return nil, err
 		}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
 	"hr-system-go/internal/attendance/dtos"
@@ -14,9 +15,9 @@ import (
 type LeaveServiceInterface interface {
 	FindLeavesByUserID(userID int, pagination *utils.Pagination) ([]models.Leave, int64, error)
 	FindLeaveByID(leaveID int) (*models.Leave, error)
-	CreateLeaveByUser(user *user_models.User, payload dtos.CreateLeaveRequest) (*models.Leave, error)
-	UpdateLeaveByID(leaveID int, payload dtos.UpdateLeaveRequest) (*models.Leave, error)
-	DeleteLeaveByID(leaveID int) error
+	CreateLeaveByUser(ctx context.Context, user *user_models.User, payload dtos.CreateLeaveRequest) (*models.Leave, error)
+	UpdateLeaveByID(ctx context.Context, leaveID int, payload dtos.UpdateLeaveRequest) (*models.Leave, error)
+	DeleteLeaveByID(ctx context.Context, leaveID int) error
 }
 
 type LeaveService struct {
@@ -31,22 +32,42 @@ func NewLeaveService(logger *logger.Logger, db *mysql.MySqlStore) LeaveServiceIn
 	}
 }
 
+// leaveFilters are query parameters of leave list, dates bound start of leave
+var leaveFilters = []utils.Filter{
+	{Param: "status", Column: "status", Type: utils.FILTER_TYPE_STRING},
+	{Param: "leaveType", Column: "leave_type", Type: utils.FILTER_TYPE_STRING},
+	{Param: "startDateFrom", Column: "start_date", Type: utils.FILTER_TYPE_DATE, Operator: utils.FILTER_OPERATOR_FROM},
+	{Param: "startDateTo", Column: "start_date", Type: utils.FILTER_TYPE_DATE, Operator: utils.FILTER_OPERATOR_TO},
+}
+
+var leaveSortFields = utils.SortFields{
+	"startDate": "start_date",
+	"endDate":   "end_date",
+	"leaveType": "leave_type",
+	"status":    "status",
+}
+
 func (s LeaveService) FindLeavesByUserID(userID int, pagination *utils.Pagination) ([]models.Leave, int64, error) {
 	var leaves []models.Leave
 	var totalCount int64 = 0
 
-	if err := models.ValidLeaveScope(s.db.DB()).Count(&totalCount).Error; err != nil {
+	filter, err := pagination.FilterScope(leaveFilters)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	err := models.ValidLeaveScope(s.db.DB()).Preload("User").Limit(pagination.Limit).Offset(pagination.Offset()).Order(pagination.Sort).Find(&leaves, "user_id = ?", userID).Error
+	if err := models.ValidLeaveScope(s.db.DB()).Scopes(filter).Where("user_id = ?", userID).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err = pagination.FindPage(models.ValidLeaveScope(s.db.DB()).Scopes(filter).Preload("User").Where("user_id = ?", userID), leaveSortFields, &leaves)
 	if err != nil {
 		return nil, 0, err
 	}
 	return leaves, totalCount, nil
 }
 
-func (s *LeaveService) CreateLeaveByUser(user *user_models.User, payload dtos.CreateLeaveRequest) (*models.Leave, error) {
+func (s *LeaveService) CreateLeaveByUser(ctx context.Context, user *user_models.User, payload dtos.CreateLeaveRequest) (*models.Leave, error) {
 	startDate, _ := utils.ParseDateTime(*payload.StartDate)
 	endDate, _ := utils.ParseDateTime(*payload.EndDate)
 	leave := &models.Leave{
@@ -56,7 +77,7 @@ func (s *LeaveService) CreateLeaveByUser(user *user_models.User, payload dtos.Cr
 		LeaveType: *payload.LeaveType,
 	}
 
-	if err := s.db.DB().Create(&leave).Error; err != nil {
+	if err := s.db.DB().WithContext(ctx).Create(&leave).Error; err != nil {
 		s.logger.Error("Create Leave Failed", zap.Error(err))
 		return nil, err
 	}
@@ -64,7 +85,7 @@ func (s *LeaveService) CreateLeaveByUser(user *user_models.User, payload dtos.Cr
 	return s.FindLeaveByID(int(leave.ID))
 }
 
-func (s *LeaveService) UpdateLeaveByID(leaveID int, payload dtos.UpdateLeaveRequest) (*models.Leave, error) {
+func (s *LeaveService) UpdateLeaveByID(ctx context.Context, leaveID int, payload dtos.UpdateLeaveRequest) (*models.Leave, error) {
 	leave := &models.Leave{}
 
 	if payload.LeaveType != nil {
@@ -85,7 +106,7 @@ func (s *LeaveService) UpdateLeaveByID(leaveID int, payload dtos.UpdateLeaveRequ
 	}
 
 	var updatedLeave *models.Leave
-	if err := models.ValidLeaveScope(s.db.DB()).First(&updatedLeave, leaveID).Updates(leave).Error; err != nil {
+	if err := models.ValidLeaveScope(s.db.DB().WithContext(ctx)).First(&updatedLeave, leaveID).Updates(leave).Error; err != nil {
 		s.logger.Error("Cannot Update Leave Data", zap.Error(err))
 		return nil, err
 	}
@@ -93,9 +114,9 @@ func (s *LeaveService) UpdateLeaveByID(leaveID int, payload dtos.UpdateLeaveRequ
 	return s.FindLeaveByID(leaveID)
 }
 
-func (s *LeaveService) DeleteLeaveByID(leaveID int) error {
+func (s *LeaveService) DeleteLeaveByID(ctx context.Context, leaveID int) error {
 	var leave *models.Leave
-	if err := models.ValidLeaveScope(s.db.DB()).First(&leave, leaveID).Update("status", "removed").Error; err != nil {
+	if err := models.ValidLeaveScope(s.db.DB().WithContext(ctx)).First(&leave, leaveID).Update("status", "removed").Error; err != nil {
 		s.logger.Error("Cannot Delete User", zap.Error(err))
 		return err
 	}
@@ -109,6 +130,12 @@ func (s *LeaveService) FindLeaveByID(leaveID int) (*models.Leave, error) {
 		s.logger.Error("Cannot Not Find Leave by ID", zap.Error(err))
 		return nil, err
 	}
+	managerID, err := user_models.AppliedManagerID(s.db.DB(), leave.UserID)
+	if err != nil {
+		s.logger.Error("Cannot Not Find Manager of Leave", zap.Error(err))
+		return nil, err
+	}
+	leave.ManagerID = managerID
 
 	return leave, nil
 }
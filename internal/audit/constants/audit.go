@@ -0,0 +1,35 @@
+package constants
+
+const (
+	AUDIT_LOG_TABLE = "audit_log"
+)
+
+// AUDITED_TABLES get an audit log entry for every created, updated and deleted row
+var AUDITED_TABLES = []string{
+	"user",
+	"department",
+	"leave",
+	"clock_record",
+	"role",
+	"role_abilities",
+	"user_role",
+	"user_ability",
+	"invitation",
+	"profile",
+	"employment_record",
+	"termination",
+	"checklist_template",
+	"checklist_task",
+	"contract",
+	"directory_entry",
+	"document_category",
+	"document",
+	"custom_field",
+	"custom_field_value",
+}
+
+// AUDIT_IGNORED_COLUMNS change as side effect of other changes
+var AUDIT_IGNORED_COLUMNS = []string{"updated_at", "version"}
+
+// AUDIT_REDACTED_COLUMNS are recorded as changed without their values
+var AUDIT_REDACTED_COLUMNS = []string{"password_encrypt", "token_hash", "national_id", "bank_account_number"}
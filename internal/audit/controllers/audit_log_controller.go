@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/audit/dtos"
+	"hr-system-go/internal/audit/services"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	"hr-system-go/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AuditLogController struct {
+	logger      *logger.Logger
+	service     services.AuditServiceInterface
+	authService auth_service.AuthServiceInterface
+}
+
+func NewAuditLogController(logger *logger.Logger, service services.AuditServiceInterface, authService auth_service.AuthServiceInterface) *AuditLogController {
+	return &AuditLogController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+// audit log is read only, there are no routes changing it
+func (c *AuditLogController) RegisterRoutes(r *gin.Engine) {
+	r.GET("/api/audit-logs", c.authService.AuthUserAbilityWrapper(c.listAuditLogs, constants.ABILITY_ADMIN))
+}
+
+func (c *AuditLogController) listAuditLogs(ctx *gin.Context) {
+	var filter dtos.FindAuditLogsRequest
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		c.logger.Error("Cannot not parse audit log filter", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid audit log filter"})
+		return
+	}
+
+	pagination := utils.NewPagination(ctx)
+	logs, totalRows, err := c.service.FindAuditLogs(filter, &pagination)
+	if errors.Is(err, services.ErrInvalidAuditAction) || utils.IsQueryError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to Find Audit Logs", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find Audit Logs Error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewAuditLogListResponse(logs, totalRows, pagination))
+}
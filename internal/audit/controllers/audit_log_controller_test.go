@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/audit/dtos"
+	"hr-system-go/internal/audit/models"
+	"hr-system-go/internal/audit/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditLogController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Audit Log Controller Suite")
+}
+
+var (
+	auditLogController *AuditLogController
+	mockAuditService   *mock_services.MockAuditService
+	mockAuthService    *mock_services.MockAuthService
+	router             *gin.Engine
+	mockLogger         *logger.Logger
+)
+
+var _ = Describe("AuditLogController", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockAuditService = &mock_services.MockAuditService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		auditLogController = NewAuditLogController(mockLogger, mockAuditService, mockAuthService)
+		router = gin.Default()
+		auditLogController.RegisterRoutes(router)
+	})
+
+	Describe("listAuditLogs", func() {
+		It("should return filtered audit logs with changes", func() {
+			actorID := uint(1)
+			logs := []models.AuditLog{
+				{ID: 3, ActorID: &actorID, Action: "update", EntityType: "user", EntityID: "12", Changes: `{"salary":{"before":1000,"after":1200}}`, RequestID: "req-1"},
+			}
+			filter := dtos.FindAuditLogsRequest{ActorID: &actorID, EntityType: "user", EntityID: "12"}
+			mockAuditService.On("FindAuditLogs", filter, mock.Anything).Return(logs, int64(1), nil)
+
+			req, _ := http.NewRequest("GET", "/api/audit-logs?actorId=1&entityType=user&entityId=12", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response struct {
+				Items []struct {
+					Id       uint
+					ActorId  *uint
+					Action   string
+					EntityId string
+					Changes  map[string]map[string]float64
+				}
+			}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(1))
+			Expect(*response.Items[0].ActorId).To(Equal(uint(1)))
+			Expect(response.Items[0].EntityId).To(Equal("12"))
+			Expect(response.Items[0].Changes["salary"]["after"]).To(Equal(float64(1200)))
+		})
+
+		It("should reject unknown action", func() {
+			filter := dtos.FindAuditLogsRequest{Action: "truncate"}
+			mockAuditService.On("FindAuditLogs", filter, mock.Anything).Return(nil, int64(0), services.ErrInvalidAuditAction)
+
+			req, _ := http.NewRequest("GET", "/api/audit-logs?action=truncate", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should reject malformed time range", func() {
+			req, _ := http.NewRequest("GET", "/api/audit-logs?from=yesterday", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			mockAuditService.AssertNotCalled(GinkgoT(), "FindAuditLogs", mock.Anything, mock.Anything)
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package dtos
+
+import (
+	"encoding/json"
+	"hr-system-go/internal/audit/models"
+	"hr-system-go/utils"
+	"time"
+)
+
+type AuditLogListResponse struct {
+	Items      []*AuditLogResponse
+	Pagination utils.PaginationResult
+}
+
+type AuditLogResponse struct {
+	Id           uint
+	ActorId      *uint
+	OnBehalfOfId *uint
+	Action       string
+	EntityType   string
+	EntityId     string
+	Changes      json.RawMessage
+	RequestId    string
+	IP           string
+	CreatedAt    time.Time
+}
+
+// FindAuditLogsRequest filters are combined, zero values are ignored
+type FindAuditLogsRequest struct {
+	ActorID    *uint      `form:"actorId"`
+	Action     string     `form:"action"`
+	EntityType string     `form:"entityType"`
+	EntityID   string     `form:"entityId"`
+	RequestID  string     `form:"requestId"`
+	From       *time.Time `form:"from"`
+	To         *time.Time `form:"to"`
+}
+
+func NewAuditLogListResponse(logs []models.AuditLog, totalRows int64, pagination utils.Pagination) *AuditLogListResponse {
+	items := []*AuditLogResponse{}
+	for _, log := range logs {
+		items = append(items, NewAuditLogResponse(&log))
+	}
+
+	return &AuditLogListResponse{
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
+	}
+}
+
+func NewAuditLogResponse(log *models.AuditLog) *AuditLogResponse {
+	return &AuditLogResponse{
+		Id:           log.ID,
+		ActorId:      log.ActorID,
+		OnBehalfOfId: log.OnBehalfOfID,
+		Action:       log.Action,
+		EntityType:   log.EntityType,
+		EntityId:     log.EntityID,
+		Changes:      json.RawMessage(log.Changes),
+		RequestId:    log.RequestID,
+		IP:           log.IP,
+		CreatedAt:    log.CreatedAt,
+	}
+}
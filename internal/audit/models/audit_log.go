@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog is a change of one row made by ActorID, OnBehalfOfID is set while actor impersonates
+// another user. Rows are append only, they are never updated or removed
+type AuditLog struct {
+	ID           uint  `gorm:"primarykey"`
+	ActorID      *uint `gorm:"index"`
+	OnBehalfOfID *uint
+	Action       string `gorm:"not null"`
+	EntityType   string `gorm:"not null;index:idx_entity"`
+	EntityID     string `gorm:"not null;index:idx_entity"`
+	// Changes holds before and after value of every changed column
+	Changes   string `gorm:"type:json;not null"`
+	RequestID string `gorm:"index"`
+	IP        string
+	CreatedAt time.Time `gorm:"type:timestamp;default:current_timestamp();index"`
+}
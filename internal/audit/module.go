@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"hr-system-go/app"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/audit/controllers"
+	"hr-system-go/internal/audit/services"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AuditModule struct {
+	app.AppModuleInterface
+}
+
+func (m *AuditModule) Controllers() []interface{} {
+	return []interface{}{
+		controllers.NewAuditLogController,
+		func(
+			r *gin.Engine,
+			c *controllers.AuditLogController,
+			service services.AuditServiceInterface,
+			logger *logger.Logger,
+		) *AuditModule {
+			c.RegisterRoutes(r)
+			// callbacks are registered on connect, so changes are audited from the first query
+			if err := service.EnableAudit(); err != nil {
+				logger.Fatal("Failed to enable audit log", zap.Error(err))
+			}
+			logger.Info("= Audit module init")
+			return m
+		},
+	}
+}
+
+func (m *AuditModule) Provide() []interface{} {
+	return []interface{}{
+		services.NewAuditService,
+	}
+}
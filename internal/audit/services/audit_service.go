@@ -0,0 +1,127 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/audit/constants"
+	"hr-system-go/internal/audit/dtos"
+	"hr-system-go/internal/audit/models"
+	"hr-system-go/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidAuditAction = errors.New("action must be create, update or delete")
+
+type AuditServiceInterface interface {
+	EnableAudit() error
+	Record(db *gorm.DB, entries []mysql.AuditEntry) error
+	FindAuditLogs(filter dtos.FindAuditLogsRequest, pagination *utils.Pagination) ([]models.AuditLog, int64, error)
+}
+
+type AuditService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+}
+
+func NewAuditService(logger *logger.Logger, db *mysql.MySqlStore) AuditServiceInterface {
+	return &AuditService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// EnableAudit makes every change of audited tables recorded, no matter which service makes it
+func (s *AuditService) EnableAudit() error {
+	return s.db.EnableAudit(mysql.AuditConfig{
+		LogTable:        constants.AUDIT_LOG_TABLE,
+		Tables:          constants.AUDITED_TABLES,
+		IgnoredColumns:  constants.AUDIT_IGNORED_COLUMNS,
+		RedactedColumns: constants.AUDIT_REDACTED_COLUMNS,
+		Recorder:        s.Record,
+	})
+}
+
+// Record is called by audit callbacks within transaction of the change
+func (s *AuditService) Record(db *gorm.DB, entries []mysql.AuditEntry) error {
+	logs := []models.AuditLog{}
+	for _, entry := range entries {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, models.AuditLog{
+			ActorID:      entry.Context.ActorID,
+			OnBehalfOfID: entry.Context.OnBehalfOfID,
+			Action:       entry.Action,
+			EntityType:   entry.EntityType,
+			EntityID:     entry.EntityID,
+			Changes:      string(changes),
+			RequestID:    entry.Context.RequestID,
+			IP:           entry.Context.IP,
+		})
+	}
+
+	if err := db.Create(&logs).Error; err != nil {
+		s.logger.Error("Cannot Record Audit Log", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+var auditLogSortFields = utils.SortFields{
+	"createdAt":  "created_at",
+	"action":     "action",
+	"entityType": "entity_type",
+}
+
+func (s *AuditService) FindAuditLogs(filter dtos.FindAuditLogsRequest, pagination *utils.Pagination) ([]models.AuditLog, int64, error) {
+	switch filter.Action {
+	case "", mysql.AUDIT_ACTION_CREATE, mysql.AUDIT_ACTION_UPDATE, mysql.AUDIT_ACTION_DELETE:
+	default:
+		return nil, 0, ErrInvalidAuditAction
+	}
+
+	var logs []models.AuditLog
+	var totalCount int64 = 0
+
+	if err := s.filterScope(filter).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := pagination.FindPage(s.filterScope(filter), auditLogSortFields, &logs)
+	if err != nil {
+		s.logger.Error("Cannot Find Audit Logs", zap.Error(err))
+		return nil, 0, err
+	}
+	return logs, totalCount, nil
+}
+
+func (s *AuditService) filterScope(filter dtos.FindAuditLogsRequest) *gorm.DB {
+	query := s.db.DB().Model(&models.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
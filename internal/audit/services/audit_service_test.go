@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/audit/dtos"
+	"hr-system-go/internal/audit/models"
+	department_dtos "hr-system-go/internal/department/dtos"
+	department_models "hr-system-go/internal/department/models"
+	department_services "hr-system-go/internal/department/services"
+	"hr-system-go/utils"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAuditService(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AuditService Suite")
+}
+
+var (
+	auditService      AuditServiceInterface
+	departmentService department_services.DepartmentServiceInterface
+	mockEnv           *env.Env
+	mockLogger        *logger.Logger
+	mockDB            *mysql.MySqlStore
+)
+
+var _ = BeforeSuite(func() {
+	mockEnv = env.NewEnv()
+	mockLogger = logger.NewLogger(mockEnv)
+	mockDB = mysql.NewMySqlStore(mockEnv, mockLogger)
+	auditService = NewAuditService(mockLogger, mockDB)
+	departmentService = department_services.NewDepartmentService(mockLogger, mockDB)
+	Expect(auditService.EnableAudit()).To(Succeed())
+
+	mockDB.Connect(
+		mockEnv.GetEnv("DB_USER"),
+		mockEnv.GetEnv("DB_PASSWORD"),
+		mockEnv.GetEnv("DB_DATABASE"),
+		mockEnv.GetEnv("DB_HOST"),
+		mockEnv.GetEnv("DB_PORT"),
+		mockEnv.GetEnv("DB_PARAMS"),
+	)
+
+	mockDB.DB().AutoMigrate(&department_models.Department{}, &models.AuditLog{})
+})
+
+var _ = AfterSuite(func() {
+	mockDB.DB().Migrator().DropTable(&department_models.Department{}, &models.AuditLog{})
+	mockDB.Close()
+})
+
+var _ = Describe("AuditService", func() {
+	actorID := uint(7)
+	requestContext := func() context.Context {
+		return context.WithValue(context.Background(), mysql.AUDIT_CONTEXT_KEY, &mysql.AuditContext{
+			ActorID:   &actorID,
+			RequestID: "req-42",
+			IP:        "10.0.0.1",
+		})
+	}
+	findLogs := func(action string) []models.AuditLog {
+		var logs []models.AuditLog
+		mockDB.DB().Where("entity_type = ? AND action = ?", "department", action).Order("id asc").Find(&logs)
+		return logs
+	}
+	changesOf := func(log models.AuditLog) map[string]mysql.AuditChange {
+		changes := map[string]mysql.AuditChange{}
+		Expect(json.Unmarshal([]byte(log.Changes), &changes)).To(Succeed())
+		return changes
+	}
+
+	BeforeEach(func() {
+		_ = mockDB.DB().Exec("truncate table department").Error
+		_ = mockDB.DB().Exec("truncate table audit_log").Error
+	})
+
+	Describe("audit callbacks", func() {
+		It("should record created row with actor and request", func() {
+			department, err := departmentService.CreateDepartment(requestContext(), department_dtos.CreateDepartmentRequest{Name: "Payroll"})
+			Expect(err).To(BeNil())
+
+			logs := findLogs("create")
+			Expect(logs).To(HaveLen(1))
+			Expect(logs[0].EntityID).To(Equal(strconv.FormatUint(uint64(department.ID), 10)))
+			Expect(*logs[0].ActorID).To(Equal(actorID))
+			Expect(logs[0].RequestID).To(Equal("req-42"))
+			Expect(logs[0].IP).To(Equal("10.0.0.1"))
+			Expect(changesOf(logs[0])["name"].After).To(Equal("Payroll"))
+		})
+
+		It("should record only changed columns of updated row", func() {
+			department := &department_models.Department{Name: "Payroll"}
+			mockDB.DB().Create(&department)
+			name := "Payroll & Benefits"
+
+			_, err := departmentService.UpdateDepartmentByID(requestContext(), int(department.ID), department_dtos.UpdateDepartmentRequest{Name: &name})
+			Expect(err).To(BeNil())
+
+			logs := findLogs("update")
+			Expect(logs).To(HaveLen(1))
+			changes := changesOf(logs[0])
+			Expect(changes).To(HaveLen(1))
+			Expect(changes["name"].Before).To(Equal("Payroll"))
+			Expect(changes["name"].After).To(Equal(name))
+		})
+
+		It("should record soft deleted row as delete", func() {
+			department := &department_models.Department{Name: "Payroll"}
+			mockDB.DB().Create(&department)
+
+			Expect(departmentService.DeleteDepartmentByID(requestContext(), int(department.ID))).To(Succeed())
+
+			logs := findLogs("delete")
+			Expect(logs).To(HaveLen(1))
+			Expect(changesOf(logs[0])["status"].After).To(Equal("removed"))
+		})
+
+		It("should record change made without request as system change", func() {
+			mockDB.DB().Create(&department_models.Department{Name: "Payroll"})
+
+			logs := findLogs("create")
+			Expect(logs).To(HaveLen(1))
+			Expect(logs[0].ActorID).To(BeNil())
+			Expect(logs[0].RequestID).To(BeEmpty())
+		})
+
+		It("should refuse updating or deleting audit log", func() {
+			mockDB.DB().Create(&department_models.Department{Name: "Payroll"})
+			var log models.AuditLog
+			mockDB.DB().First(&log)
+
+			err := mockDB.DB().Model(&log).Update("action", "create").Error
+			Expect(err).To(MatchError(mysql.ErrAuditLogAppendOnly))
+			err = mockDB.DB().Delete(&log).Error
+			Expect(err).To(MatchError(mysql.ErrAuditLogAppendOnly))
+		})
+	})
+
+	Describe("FindAuditLogs", func() {
+		It("should filter logs by entity and action", func() {
+			department, _ := departmentService.CreateDepartment(requestContext(), department_dtos.CreateDepartmentRequest{Name: "Payroll"})
+			Expect(departmentService.DeleteDepartmentByID(requestContext(), int(department.ID))).To(Succeed())
+			pagination := utils.Pagination{Page: 1, Limit: 10, Sort: "id asc"}
+
+			filter := dtos.FindAuditLogsRequest{
+				ActorID:    &actorID,
+				EntityType: "department",
+				EntityID:   strconv.FormatUint(uint64(department.ID), 10),
+				Action:     "delete",
+			}
+			logs, total, err := auditService.FindAuditLogs(filter, &pagination)
+
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(1)))
+			Expect(logs[0].Action).To(Equal("delete"))
+		})
+
+		It("should reject unknown action", func() {
+			pagination := utils.Pagination{Page: 1, Limit: 10, Sort: "id asc"}
+
+			_, _, err := auditService.FindAuditLogs(dtos.FindAuditLogsRequest{Action: "truncate"}, &pagination)
+
+			Expect(err).To(MatchError(ErrInvalidAuditAction))
+		})
+	})
+})
@@ -4,6 +4,7 @@ const (
 	ABILITY_READ_USER               = "read_user"
 	ABILITY_READ_WRITE_USER         = "read_write_user"
 	ABILITY_DELETE_USER             = "delete_user"
+	ABILITY_INVITE_USER             = "invite_user"
 	ABILITY_READ_LEAVE              = "read_leave"
 	ABILITY_READ_WRITE_LEAVE        = "read_write_leave"
 	ABILITY_DELETE_LEAVE            = "delete_leave"
@@ -13,10 +14,38 @@ const (
 	ABILITY_READ_DEPARTMENT         = "read_department"
 	ABILITY_READ_WRITE_DEPARTMENT   = "read_write_department"
 	ABILITY_DELETE_DEPARTMENT       = "delete_department"
+	ABILITY_READ_ROLE               = "read_role"
+	ABILITY_READ_WRITE_ROLE         = "read_write_role"
+	ABILITY_DELETE_ROLE             = "delete_role"
 	ABILITY_ADMIN                   = "admin"
 )
 
+// field level abilities, fields of own record are always readable
+const (
+	ABILITY_READ_SALARY        = "read_salary"
+	ABILITY_WRITE_SALARY       = "write_salary"
+	ABILITY_READ_PERSONAL_DATA = "read_personal_data"
+	ABILITY_WRITE_EMPLOYMENT   = "write_employment"
+	// ABILITY_WRITE_PERSONAL_DATA changes identity data and profile of other users
+	ABILITY_WRITE_PERSONAL_DATA = "write_personal_data"
+)
+
+// ABILITY_COMPLETE_IT_TASKS works on onboarding and offboarding tasks assigned to IT
+const ABILITY_COMPLETE_IT_TASKS = "complete_it_tasks"
+
 const ABILITY_ALL_GRANTS_USER = "all_users"
 const ABILITY_ALL_GRANTS_LEAVE = "all_leave"
 const ABILITY_ALL_GRANTS_CLOCK_RECORD = "all_clock_record"
 const ABILITY_ALL_GRANTS_DEPARTMENT = "all_department"
+
+const (
+	ABILITY_EFFECT_GRANT = "grant"
+	ABILITY_EFFECT_DENY  = "deny"
+)
+
+const (
+	PERMISSION_SOURCE_PRIMARY_ROLE = "primary_role"
+	PERMISSION_SOURCE_ROLE         = "role"
+	PERMISSION_SOURCE_GRANT        = "grant"
+	PERMISSION_SOURCE_DENY         = "deny"
+)
@@ -0,0 +1,4 @@
+package constants
+
+// API_KEY_TOKEN_PREFIX starts every API key, so it can be told from JWT in Authorization header
+const API_KEY_TOKEN_PREFIX = "hrk_"
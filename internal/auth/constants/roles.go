@@ -8,4 +8,13 @@ const (
 	ROLE_HR         = "HR"
 	ROLE_HR_MANAGER = "HR Manager"
 	ROLE_INTERN     = "Intern"
+	ROLE_IT         = "IT"
 )
+
+const (
+	ROLE_STATUS_ACTIVE   = "active"
+	ROLE_STATUS_INACTIVE = "inactive"
+)
+
+// ROLE_STATUSES can be set on update, removal goes through delete which also unassigns the role
+var ROLE_STATUSES = []string{ROLE_STATUS_ACTIVE, ROLE_STATUS_INACTIVE}
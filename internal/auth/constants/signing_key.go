@@ -0,0 +1,15 @@
+package constants
+
+const (
+	SIGNING_KEY_STATUS_ACTIVE   = "active"
+	SIGNING_KEY_STATUS_PREVIOUS = "previous"
+	SIGNING_KEY_STATUS_RETIRED  = "retired"
+)
+
+const (
+	SIGNING_ALGORITHM_RS256 = "RS256"
+	SIGNING_ALGORITHM_EDDSA = "EdDSA"
+)
+
+// API_TOKEN_AUDIENCE is aud of session tokens, ID tokens for OAuth clients carry client id instead
+const API_TOKEN_AUDIENCE = "hr-system-api"
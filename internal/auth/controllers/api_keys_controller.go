@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	"hr-system-go/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ApiKeysController struct {
+	logger        *logger.Logger
+	service       services.ApiKeyServiceInterface
+	authService   services.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewApiKeysController(logger *logger.Logger, service services.ApiKeyServiceInterface, authService services.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *ApiKeysController {
+	return &ApiKeysController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *ApiKeysController) RegisterRoutes(r *gin.Engine) {
+	serviceAccountRoutes := r.Group("/api/service-accounts")
+	{
+		serviceAccountRoutes.GET("", c.authService.AuthUserAbilityWrapper(c.listServiceAccounts, constants.ABILITY_ADMIN))
+		serviceAccountRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.CreateServiceAccount, constants.ABILITY_ADMIN))
+	}
+	apiKeyRoutes := r.Group("/api/users/:userId/api-keys")
+	{
+		apiKeyRoutes.GET("", c.authService.AuthTokenWrapper(c.listApiKeys))
+		apiKeyRoutes.POST("", c.authService.AuthTokenWrapper(c.CreateApiKey))
+		apiKeyRoutes.DELETE("/:keyId", c.authService.AuthTokenWrapper(c.RevokeApiKey))
+	}
+}
+
+func (c *ApiKeysController) listServiceAccounts(ctx *gin.Context) {
+	pagination := utils.NewPagination(ctx)
+	users, totalRows, err := c.service.FindServiceAccounts(&pagination)
+	if utils.IsQueryError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to Find Service Accounts", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find Service Accounts Error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewServiceAccountListResponse(users, totalRows, pagination))
+}
+
+func (c *ApiKeysController) CreateServiceAccount(ctx *gin.Context) {
+	var payload dtos.CreateServiceAccountRequest
+	errorMsg := "Failed to Create Service Account"
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil || payload.Name == "" {
+		c.logger.Error("Cannot not parse create payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	user, err := c.service.CreateServiceAccount(ctx, payload)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "role not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not create Service Account", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewServiceAccountResponse(user))
+}
+
+func (c *ApiKeysController) listApiKeys(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	errorMsg := "Failed to Find Api Keys"
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_READ).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+
+	apiKeys, err := c.service.FindApiKeys(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find Api Keys", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewApiKeyListResponse(apiKeys))
+}
+
+func (c *ApiKeysController) CreateApiKey(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	errorMsg := "Failed to Create Api Key"
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	// keys cannot be created with api key or while impersonating, reason tells which
+	if decision := c.authorize(ctx, userID, policy_constants.POLICY_ACTION_CREATE); !decision.Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": decision.Reason})
+		return
+	}
+
+	var payload dtos.CreateApiKeyRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil || payload.Name == "" {
+		c.logger.Error("Cannot not parse create payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	apiKey, key, err := c.service.CreateApiKey(userID, c.authService.GetCurrentUser(ctx).ID, payload)
+	if errors.Is(err, services.ErrApiKeyScope) || errors.Is(err, services.ErrApiKeyExpiry) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrApiKeyOwner) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not create Api Key", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewCreatedApiKeyResponse(apiKey, key))
+}
+
+func (c *ApiKeysController) RevokeApiKey(ctx *gin.Context) {
+	errorMsg := "Failed to Revoke Api Key"
+	userID, keyID, err := parseUserTargetIDs(ctx, "keyId")
+	if err != nil {
+		c.logger.Error("Cannot not parse User or Api Key ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_DELETE).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+
+	err = c.service.RevokeApiKey(userID, keyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not revoke Api Key", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (c *ApiKeysController) authorize(ctx *gin.Context, userID int, action string) policy_models.Decision {
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_API_KEY, OwnerID: uint(userID)}
+	return c.policyService.Authorize(ctx, resource, action)
+}
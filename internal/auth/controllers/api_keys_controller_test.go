@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
+	user_models "hr-system-go/internal/user/models"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("ApiKeysController", func() {
+	var mockApiKeyService *mock_services.MockApiKeyService
+	var mockPolicy *mock_services.MockPolicyService
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockApiKeyService = &mock_services.MockApiKeyService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		router = gin.Default()
+		NewApiKeysController(mockLogger, mockApiKeyService, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	Describe("CreateServiceAccount", func() {
+		It("should create service account", func() {
+			payload := dtos.CreateServiceAccountRequest{Name: "Payroll"}
+			user := &user_models.User{Name: payload.Name, Type: user_constants.USER_TYPE_SERVICE_ACCOUNT}
+			user.ID = 12
+			mockApiKeyService.On("CreateServiceAccount", payload).Return(user, nil)
+
+			req, _ := http.NewRequest("POST", "/api/service-accounts", bytes.NewBufferString(`{"name": "Payroll"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.ServiceAccountResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Id).To(Equal(uint(12)))
+		})
+	})
+
+	Describe("CreateApiKey", func() {
+		payload := dtos.CreateApiKeyRequest{Name: "badge sync", Scopes: []string{constants.ABILITY_READ_USER}}
+		currentUser := &user_models.User{}
+		currentUser.ID = 12
+
+		It("should return the key once", func() {
+			apiKey := &models.ApiKey{UserID: 12, Name: payload.Name, Prefix: "a1b2c3d4", Scopes: payload.Scopes}
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+			mockApiKeyService.On("CreateApiKey", 12, uint(12), payload).Return(apiKey, "hrk_a1b2c3d4_secret", nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/api-keys", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.CreatedApiKeyResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Key).To(Equal("hrk_a1b2c3d4_secret"))
+			Expect(response.Prefix).To(Equal("a1b2c3d4"))
+		})
+
+		It("should reject scopes user does not have", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+			mockApiKeyService.On("CreateApiKey", 12, uint(12), payload).Return(nil, "", services.ErrApiKeyScope)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/api-keys", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should refuse key of another human user", func() {
+			admin := &user_models.User{}
+			admin.ID = 1
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(admin)
+			mockApiKeyService.On("CreateApiKey", 12, uint(1), payload).Return(nil, "", services.ErrApiKeyOwner)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/api-keys", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring(services.ErrApiKeyOwner.Error()))
+		})
+
+		It("should not create key when authenticated with api key", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: false, Reason: "Api keys cannot create api keys"})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/api-keys", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring("Api keys cannot create api keys"))
+			mockApiKeyService.AssertNotCalled(GinkgoT(), "CreateApiKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should not create key while impersonating", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CREATE).Return(policy_models.Decision{Allowed: false, Reason: "Api keys cannot be created while impersonating"})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/api-keys", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockApiKeyService.AssertNotCalled(GinkgoT(), "CreateApiKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("RevokeApiKey", func() {
+		It("should return not found for unknown key", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
+			mockApiKeyService.On("RevokeApiKey", 12, 4).Return(gorm.ErrRecordNotFound)
+
+			req, _ := http.NewRequest("DELETE", "/api/users/12/api-keys/4", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
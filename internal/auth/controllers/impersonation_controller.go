@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/services"
+	"hr-system-go/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ImpersonationController struct {
+	logger      *logger.Logger
+	service     services.ImpersonationServiceInterface
+	authService services.AuthServiceInterface
+}
+
+func NewImpersonationController(logger *logger.Logger, service services.ImpersonationServiceInterface, authService services.AuthServiceInterface) *ImpersonationController {
+	return &ImpersonationController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+func (c *ImpersonationController) RegisterRoutes(r *gin.Engine) {
+	r.POST("/api/users/:userId/impersonation", c.authService.AuthUserAbilityWrapper(c.StartImpersonation, constants.ABILITY_ADMIN))
+	r.DELETE("/api/impersonation", c.authService.AuthTokenWrapper(c.StopImpersonation))
+	r.GET("/api/impersonations", c.authService.AuthUserAbilityWrapper(c.listImpersonations, constants.ABILITY_ADMIN))
+}
+
+func (c *ImpersonationController) StartImpersonation(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	errorMsg := "Failed to Start Impersonation"
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	// impersonation must be started by a person signed in as themselves
+	if c.authService.GetImpersonation(ctx) != nil || c.authService.GetCurrentApiKey(ctx) != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Impersonation must be started with your own session"})
+		return
+	}
+
+	var payload dtos.StartImpersonationRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse impersonation payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	impersonation, token, err := c.service.StartImpersonation(c.authService.GetCurrentUser(ctx), userID, payload)
+	if errors.Is(err, services.ErrImpersonationReason) || errors.Is(err, services.ErrImpersonateSelf) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrImpersonationNotAllowed) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not start impersonation", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewStartedImpersonationResponse(impersonation, token))
+}
+
+func (c *ImpersonationController) StopImpersonation(ctx *gin.Context) {
+	impersonation := c.authService.GetImpersonation(ctx)
+	if impersonation == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Not impersonating"})
+		return
+	}
+
+	if err := c.service.StopImpersonation(impersonation); err != nil {
+		c.logger.Error("Cannot not stop impersonation", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Stop Impersonation"})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (c *ImpersonationController) listImpersonations(ctx *gin.Context) {
+	pagination := utils.NewPagination(ctx)
+	impersonations, totalRows, err := c.service.FindImpersonations(&pagination)
+	if utils.IsQueryError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to Find Impersonations", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find Impersonations Error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewImpersonationListResponse(impersonations, totalRows, pagination))
+}
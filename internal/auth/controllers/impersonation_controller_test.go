@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/internal/auth/services"
+	user_models "hr-system-go/internal/user/models"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ImpersonationController", func() {
+	var mockImpersonationService *mock_services.MockImpersonationService
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockImpersonationService = &mock_services.MockImpersonationService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		router = gin.Default()
+		NewImpersonationController(mockLogger, mockImpersonationService, mockAuthService).RegisterRoutes(router)
+	})
+
+	Describe("StartImpersonation", func() {
+		payload := dtos.StartImpersonationRequest{Reason: "ticket #42, cannot see leave balance"}
+
+		It("should return token acting as user", func() {
+			admin := &user_models.User{Name: "Admin"}
+			admin.ID = 1
+			impersonation := &models.Impersonation{ActorID: 1, UserID: 12, Reason: payload.Reason, ExpiresAt: time.Now().Add(time.Hour)}
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentApiKey", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(admin)
+			mockImpersonationService.On("StartImpersonation", admin, 12, payload).Return(impersonation, "token123", nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/impersonation", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.StartedImpersonationResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Token).To(Equal("token123"))
+			Expect(response.ActorId).To(Equal(uint(1)))
+			Expect(response.Active).To(BeTrue())
+		})
+
+		It("should not start impersonation while impersonating", func() {
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(&models.Impersonation{})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/impersonation", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockImpersonationService.AssertNotCalled(GinkgoT(), "StartImpersonation", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should forbid impersonating administrators", func() {
+			admin := &user_models.User{Name: "Admin"}
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentApiKey", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(admin)
+			mockImpersonationService.On("StartImpersonation", admin, 12, payload).Return(nil, "", services.ErrImpersonationNotAllowed)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/users/12/impersonation", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Describe("StopImpersonation", func() {
+		It("should end current impersonation", func() {
+			impersonation := &models.Impersonation{ActorID: 1, UserID: 12}
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(impersonation)
+			mockImpersonationService.On("StopImpersonation", impersonation).Return(nil)
+
+			req, _ := http.NewRequest("DELETE", "/api/impersonation", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should reject request made with own session", func() {
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+
+			req, _ := http.NewRequest("DELETE", "/api/impersonation", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionCacheController struct {
+	logger      *logger.Logger
+	cache       *services.PermissionCache
+	authService services.AuthServiceInterface
+}
+
+func NewPermissionCacheController(logger *logger.Logger, cache *services.PermissionCache, authService services.AuthServiceInterface) *PermissionCacheController {
+	return &PermissionCacheController{
+		logger:      logger,
+		cache:       cache,
+		authService: authService,
+	}
+}
+
+func (c *PermissionCacheController) RegisterRoutes(r *gin.Engine) {
+	r.GET("/api/metrics/permission-cache", c.authService.AuthUserAbilityWrapper(c.GetStats, constants.ABILITY_ADMIN))
+}
+
+// stats are counted per API instance since process start
+func (c *PermissionCacheController) GetStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.cache.Stats())
+}
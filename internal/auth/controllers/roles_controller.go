@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/internal/auth/services"
+	"hr-system-go/utils"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RolesController struct {
+	logger      *logger.Logger
+	service     services.RoleServiceInterface
+	authService services.AuthServiceInterface
+}
+
+func NewRolesController(logger *logger.Logger, service services.RoleServiceInterface, authService services.AuthServiceInterface) *RolesController {
+	return &RolesController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+func (c *RolesController) RegisterRoutes(r *gin.Engine) {
+	roleRoutes := r.Group("/api/roles")
+	{
+		roleRoutes.GET("", c.authService.AuthUserAbilityWrapper(c.listRoles, constants.ABILITY_READ_ROLE))
+		roleRoutes.GET("/:id", c.authService.AuthUserAbilityWrapper(c.GetRole, constants.ABILITY_READ_ROLE))
+		roleRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.CreateRole, constants.ABILITY_READ_WRITE_ROLE))
+		roleRoutes.PUT("/:id", c.authService.AuthUserAbilityWrapper(c.UpdateRole, constants.ABILITY_READ_WRITE_ROLE))
+		roleRoutes.DELETE("/:id", c.authService.AuthUserAbilityWrapper(c.DeleteRole, constants.ABILITY_DELETE_ROLE))
+		roleRoutes.POST("/:id/abilities/:abilityId", c.authService.AuthUserAbilityWrapper(c.AddAbility, constants.ABILITY_READ_WRITE_ROLE))
+		roleRoutes.DELETE("/:id/abilities/:abilityId", c.authService.AuthUserAbilityWrapper(c.RemoveAbility, constants.ABILITY_READ_WRITE_ROLE))
+	}
+	r.GET("/api/abilities", c.authService.AuthUserAbilityWrapper(c.listAbilities, constants.ABILITY_READ_ROLE))
+	r.PUT("/api/users/:userId/role", c.authService.AuthUserAbilityWrapper(c.AssignUserRole, constants.ABILITY_READ_WRITE_ROLE))
+}
+
+func (c *RolesController) listRoles(ctx *gin.Context) {
+	pagination := utils.NewPagination(ctx)
+	roles, totalRows, err := c.service.FindRoles(&pagination)
+	if utils.IsQueryError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to Find Roles", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find Roles Error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewRoleListResponse(roles, totalRows, pagination))
+}
+
+func (c *RolesController) GetRole(ctx *gin.Context) {
+	roleId := ctx.Param("id")
+	roleID, err := strconv.Atoi(roleId)
+	errorMsg := "Failed to Get Role"
+	if err != nil {
+		c.logger.Error("Cannot not parse Role ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	role, err := c.service.FindRoleByID(roleID)
+	if err != nil {
+		c.logger.Error("Cannot not find Role", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewRoleResponse(role))
+}
+
+func (c *RolesController) CreateRole(ctx *gin.Context) {
+	var payload dtos.CreateRoleRequest
+	errorMsg := "Failed to Create Role"
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil || payload.Name == "" {
+		c.logger.Error("Cannot not parse create payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	abilities, err := findAbilities(c.service, payload.AbilityIDs...)
+	if err != nil {
+		c.logger.Error("Cannot not find Abilities", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+	if !authorizeGrant(ctx, c.authService, 0, abilities, errorMsg) {
+		return
+	}
+
+	role, err := c.service.CreateRole(ctx, payload)
+	if errors.Is(err, services.ErrAbilityNotFound) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not create Role", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewRoleResponse(role))
+}
+
+func (c *RolesController) UpdateRole(ctx *gin.Context) {
+	roleId := ctx.Param("id")
+	roleID, err := strconv.Atoi(roleId)
+	errorMsg := "Failed to Update Role"
+	if err != nil {
+		c.logger.Error("Cannot not parse Role ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.UpdateRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse update payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	role, err := c.service.UpdateRoleByID(ctx, roleID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not update Role", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewRoleResponse(role))
+}
+
+func (c *RolesController) DeleteRole(ctx *gin.Context) {
+	roleId := ctx.Param("id")
+	roleID, err := strconv.Atoi(roleId)
+	errorMsg := "Failed to Delete Role"
+	if err != nil {
+		c.logger.Error("Cannot not parse Role ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	if err := c.service.DeleteRoleByID(ctx, roleID); err != nil {
+		c.logger.Error("Cannot not delete Role", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (c *RolesController) AddAbility(ctx *gin.Context) {
+	errorMsg := "Failed to Add Ability"
+	roleID, abilityID, err := parseRoleAbilityIDs(ctx)
+	if err != nil {
+		c.logger.Error("Cannot not parse Role or Ability ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	abilities, err := findAbilities(c.service, uint(abilityID))
+	if err != nil {
+		c.logger.Error("Cannot not find Ability", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+	if !authorizeGrant(ctx, c.authService, 0, abilities, errorMsg) {
+		return
+	}
+
+	role, err := c.service.AddAbilityToRole(ctx, roleID, abilityID)
+	if err != nil {
+		c.logger.Error("Cannot not add Ability to Role", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewRoleResponse(role))
+}
+
+func (c *RolesController) RemoveAbility(ctx *gin.Context) {
+	errorMsg := "Failed to Remove Ability"
+	roleID, abilityID, err := parseRoleAbilityIDs(ctx)
+	if err != nil {
+		c.logger.Error("Cannot not parse Role or Ability ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	role, err := c.service.RemoveAbilityFromRole(ctx, roleID, abilityID)
+	if err != nil {
+		c.logger.Error("Cannot not remove Ability from Role", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewRoleResponse(role))
+}
+
+func (c *RolesController) listAbilities(ctx *gin.Context) {
+	abilities, err := c.service.FindAbilities()
+	if err != nil {
+		c.logger.Error("Failed to Find Abilities", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find Abilities Error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewAbilityListResponse(abilities))
+}
+
+func (c *RolesController) AssignUserRole(ctx *gin.Context) {
+	userId := ctx.Param("userId")
+	userID, err := strconv.Atoi(userId)
+	errorMsg := "Failed to Assign Role"
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	var payload dtos.AssignUserRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse assign payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	abilities := []models.Ability{}
+	if payload.RoleID != nil {
+		role, err := c.service.FindRoleByID(*payload.RoleID)
+		if err != nil {
+			c.logger.Error("Cannot not find Role", zap.Error(err))
+			respondRoleError(ctx, err, errorMsg)
+			return
+		}
+		abilities = role.Abilities
+	}
+	if !authorizeGrant(ctx, c.authService, userID, abilities, errorMsg) {
+		return
+	}
+
+	if err := c.service.AssignRoleToUser(ctx, userID, payload.RoleID); err != nil {
+		c.logger.Error("Cannot not assign Role to User", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// authorizeGrant responds with error itself unless current user holds every ability given away.
+// Users never change their own roles and abilities, userID is 0 when a role is changed instead of a user
+func authorizeGrant(ctx *gin.Context, authService services.AuthServiceInterface, userID int, abilities []models.Ability, errorMsg string) bool {
+	currentUser := authService.GetCurrentUser(ctx)
+	if currentUser == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return false
+	}
+	err := services.CheckGrant(authService.GetCurrentUserAbilities(ctx), abilities)
+	if userID != 0 && uint(userID) == currentUser.ID {
+		err = services.ErrOwnGrants
+	}
+	if err != nil {
+		respondRoleError(ctx, err, errorMsg)
+		return false
+	}
+	return true
+}
+
+// findAbilities looks up abilities to check before they are granted, unknown ID is ErrAbilityNotFound
+func findAbilities(service services.RoleServiceInterface, abilityIDs ...uint) ([]models.Ability, error) {
+	if len(abilityIDs) == 0 {
+		return []models.Ability{}, nil
+	}
+	all, err := service.FindAbilities()
+	if err != nil {
+		return nil, err
+	}
+	abilities := []models.Ability{}
+	for _, abilityID := range abilityIDs {
+		index := slices.IndexFunc(all, func(ability models.Ability) bool { return ability.ID == abilityID })
+		if index < 0 {
+			return nil, services.ErrAbilityNotFound
+		}
+		abilities = append(abilities, all[index])
+	}
+	return abilities, nil
+}
+
+func respondRoleError(ctx *gin.Context, err error, errorMsg string) {
+	switch {
+	case errors.Is(err, services.ErrGrantNotHeld), errors.Is(err, services.ErrOwnGrants):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLastAdmin):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAbilityNotFound),
+		errors.Is(err, services.ErrInvalidValidity),
+		errors.Is(err, services.ErrInvalidAbilityEffect),
+		errors.Is(err, services.ErrInvalidRoleStatus):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+	}
+}
+
+func parseRoleAbilityIDs(ctx *gin.Context) (int, int, error) {
+	roleID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	abilityID, err := strconv.Atoi(ctx.Param("abilityId"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return roleID, abilityID, nil
+}
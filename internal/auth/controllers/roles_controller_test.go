@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/internal/auth/services"
+	user_models "hr-system-go/internal/user/models"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRolesController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Roles Controller Suite")
+}
+
+var (
+	rolesController *RolesController
+	mockRoleService *mock_services.MockRoleService
+	mockAuthService *mock_services.MockAuthService
+	router          *gin.Engine
+	mockLogger      *logger.Logger
+)
+
+var _ = Describe("RolesController", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockRoleService = &mock_services.MockRoleService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		rolesController = NewRolesController(mockLogger, mockRoleService, mockAuthService)
+		router = gin.Default()
+		rolesController.RegisterRoutes(router)
+		currentUser := &user_models.User{}
+		currentUser.ID = 1
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+	})
+
+	Describe("listRoles", func() {
+		It("should return a list of roles with abilities", func() {
+			roles := []models.Role{
+				{Name: "RD", Abilities: []models.Ability{{Name: constants.ABILITY_READ_USER}}},
+				{Name: "HR"},
+			}
+			mockRoleService.On("FindRoles", mock.AnythingOfType("*utils.Pagination")).Return(roles, int64(2), nil)
+
+			req, _ := http.NewRequest("GET", "/api/roles", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.RoleListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(2))
+			Expect(response.Items[0].Abilities[0].Name).To(Equal(constants.ABILITY_READ_USER))
+		})
+	})
+
+	Describe("CreateRole", func() {
+		It("should create role with abilities", func() {
+			payload := dtos.CreateRoleRequest{Name: "Interim HR", AbilityIDs: []uint{1, 2}}
+			role := &models.Role{Name: payload.Name}
+			role.ID = 8
+			abilities := []models.Ability{{Name: constants.ABILITY_READ_USER}, {Name: constants.ABILITY_READ_ROLE}}
+			abilities[0].ID, abilities[1].ID = 1, 2
+			mockRoleService.On("FindAbilities").Return(abilities, nil)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE, constants.ABILITY_READ_USER, constants.ABILITY_READ_ROLE})
+			mockRoleService.On("CreateRole", payload).Return(role, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/roles", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.RoleResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Id).To(Equal(uint(8)))
+		})
+
+		It("should reject role without name", func() {
+			req, _ := http.NewRequest("POST", "/api/roles", bytes.NewBufferString(`{}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("DeleteRole", func() {
+		It("should delete a role", func() {
+			mockRoleService.On("DeleteRoleByID", 3).Return(nil)
+
+			req, _ := http.NewRequest("DELETE", "/api/roles/3", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should return conflict when deleting role of the last admin", func() {
+			mockRoleService.On("DeleteRoleByID", 6).Return(services.ErrLastAdmin)
+
+			req, _ := http.NewRequest("DELETE", "/api/roles/6", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("AddAbility", func() {
+		It("should refuse adding admin ability without being admin", func() {
+			admin := models.Ability{Name: constants.ABILITY_ADMIN}
+			admin.ID = 13
+			mockRoleService.On("FindAbilities").Return([]models.Ability{admin}, nil)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE})
+
+			req, _ := http.NewRequest("POST", "/api/roles/6/abilities/13", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockRoleService.AssertNotCalled(GinkgoT(), "AddAbilityToRole", mock.Anything, mock.Anything)
+		})
+
+		It("should add ability the caller holds", func() {
+			readUser := models.Ability{Name: constants.ABILITY_READ_USER}
+			readUser.ID = 4
+			mockRoleService.On("FindAbilities").Return([]models.Ability{readUser}, nil)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE, constants.ABILITY_READ_USER})
+			mockRoleService.On("AddAbilityToRole", 6, 4).Return(&models.Role{Abilities: []models.Ability{readUser}}, nil)
+
+			req, _ := http.NewRequest("POST", "/api/roles/6/abilities/4", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Describe("RemoveAbility", func() {
+		It("should return conflict when removing admin ability from the last admin", func() {
+			mockRoleService.On("RemoveAbilityFromRole", 6, 13).Return(nil, services.ErrLastAdmin)
+
+			req, _ := http.NewRequest("DELETE", "/api/roles/6/abilities/13", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("listAbilities", func() {
+		It("should return all abilities", func() {
+			abilities := []models.Ability{{Name: constants.ABILITY_ADMIN}, {Name: constants.ABILITY_READ_ROLE}}
+			mockRoleService.On("FindAbilities").Return(abilities, nil)
+
+			req, _ := http.NewRequest("GET", "/api/abilities", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.AbilityListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(2))
+		})
+	})
+
+	Describe("AssignUserRole", func() {
+		It("should assign role to user", func() {
+			roleID := 2
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE, constants.ABILITY_READ_USER})
+			mockRoleService.On("FindRoleByID", 2).Return(&models.Role{Abilities: []models.Ability{{Name: constants.ABILITY_READ_USER}}}, nil)
+			mockRoleService.On("AssignRoleToUser", 5, &roleID).Return(nil)
+
+			req, _ := http.NewRequest("PUT", "/api/users/5/role", bytes.NewBufferString(`{"roleId": 2}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should refuse assigning admin role to oneself", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE})
+			mockRoleService.On("FindRoleByID", 6).Return(&models.Role{Abilities: []models.Ability{{Name: constants.ABILITY_ADMIN}}}, nil)
+
+			req, _ := http.NewRequest("PUT", "/api/users/1/role", bytes.NewBufferString(`{"roleId": 6}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockRoleService.AssertNotCalled(GinkgoT(), "AssignRoleToUser", mock.Anything, mock.Anything)
+		})
+	})
+})
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SessionsController lists and terminates sign ins, /api/me routes act on current user
+type SessionsController struct {
+	logger        *logger.Logger
+	service       services.SessionServiceInterface
+	authService   services.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewSessionsController(logger *logger.Logger, service services.SessionServiceInterface, authService services.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *SessionsController {
+	return &SessionsController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *SessionsController) RegisterRoutes(r *gin.Engine) {
+	meRoutes := r.Group("/api/me/sessions")
+	{
+		meRoutes.GET("", c.authService.AuthTokenWrapper(c.ListSessions))
+		meRoutes.DELETE("/:sessionId", c.authService.AuthTokenWrapper(c.TerminateSession))
+	}
+	userRoutes := r.Group("/api/users/:userId/sessions")
+	{
+		userRoutes.GET("", c.authService.AuthTokenWrapper(c.ListSessions))
+		userRoutes.DELETE("", c.authService.AuthTokenWrapper(c.TerminateSessions))
+		userRoutes.DELETE("/:sessionId", c.authService.AuthTokenWrapper(c.TerminateSession))
+	}
+}
+
+func (c *SessionsController) ListSessions(ctx *gin.Context) {
+	errorMsg := "Failed to Find Sessions"
+	userID, err := c.sessionOwnerID(ctx)
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_READ).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+
+	sessions, err := c.service.FindSessions(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find Sessions", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewSessionListResponse(sessions, c.authService.GetCurrentSession(ctx)))
+}
+
+func (c *SessionsController) TerminateSession(ctx *gin.Context) {
+	errorMsg := "Failed to Terminate Session"
+	userID, err := c.sessionOwnerID(ctx)
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	sessionID, err := strconv.Atoi(ctx.Param("sessionId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Session ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if decision := c.authorize(ctx, userID, policy_constants.POLICY_ACTION_DELETE); !decision.Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": decision.Reason})
+		return
+	}
+
+	err = c.service.TerminateSession(ctx, userID, sessionID, c.authService.GetCurrentUser(ctx).ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Active session not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not terminate Session", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// TerminateSessions signs user out of every device
+func (c *SessionsController) TerminateSessions(ctx *gin.Context) {
+	errorMsg := "Failed to Terminate Sessions"
+	userID, err := c.sessionOwnerID(ctx)
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if decision := c.authorize(ctx, userID, policy_constants.POLICY_ACTION_DELETE); !decision.Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": decision.Reason})
+		return
+	}
+
+	terminated, err := c.service.TerminateSessions(ctx, userID, c.authService.GetCurrentUser(ctx).ID)
+	if err != nil {
+		c.logger.Error("Cannot not terminate Sessions", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"terminated": terminated})
+}
+
+func (c *SessionsController) sessionOwnerID(ctx *gin.Context) (int, error) {
+	if ctx.Param("userId") == "" {
+		return int(c.authService.GetCurrentUser(ctx).ID), nil
+	}
+	return strconv.Atoi(ctx.Param("userId"))
+}
+
+func (c *SessionsController) authorize(ctx *gin.Context, userID int, action string) policy_models.Decision {
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_SESSION, OwnerID: uint(userID)}
+	return c.policyService.Authorize(ctx, resource, action)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_models "hr-system-go/internal/user/models"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("SessionsController", func() {
+	var mockSessionService *mock_services.MockSessionService
+	var mockPolicy *mock_services.MockPolicyService
+	currentUser := &user_models.User{}
+	currentUser.ID = 7
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockSessionService = &mock_services.MockSessionService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+		router = gin.Default()
+		NewSessionsController(mockLogger, mockSessionService, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	Describe("ListSessions", func() {
+		It("should list sessions of current user and mark the current one", func() {
+			sessions := []models.Session{{UserID: 7, UserAgent: "Firefox"}, {UserID: 7, UserAgent: "Safari"}}
+			sessions[0].ID, sessions[1].ID = 3, 4
+			mockPolicy.On("Authorize", mock.Anything, policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_SESSION, OwnerID: 7}, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockSessionService.On("FindSessions", 7).Return(sessions, nil)
+			mockAuthService.On("GetCurrentSession", mock.Anything).Return(&sessions[1])
+
+			req, _ := http.NewRequest("GET", "/api/me/sessions", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.SessionListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(2))
+			Expect(response.Items[0].Current).To(BeFalse())
+			Expect(response.Items[1].Current).To(BeTrue())
+		})
+
+		It("should deny sessions of other user without permission", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
+
+			req, _ := http.NewRequest("GET", "/api/users/12/sessions", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockSessionService.AssertNotCalled(GinkgoT(), "FindSessions", mock.Anything)
+		})
+	})
+
+	Describe("TerminateSession", func() {
+		It("should terminate own session", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
+			mockSessionService.On("TerminateSession", 7, 3, uint(7)).Return(nil)
+
+			req, _ := http.NewRequest("DELETE", "/api/me/sessions/3", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should return not found for ended session", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
+			mockSessionService.On("TerminateSession", 12, 3, uint(7)).Return(gorm.ErrRecordNotFound)
+
+			req, _ := http.NewRequest("DELETE", "/api/users/12/sessions/3", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should not terminate session while impersonating", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: false, Reason: "Sessions cannot be terminated while impersonating"})
+
+			req, _ := http.NewRequest("DELETE", "/api/me/sessions/3", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockSessionService.AssertNotCalled(GinkgoT(), "TerminateSession", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("TerminateSessions", func() {
+		It("should sign user out everywhere", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
+			mockSessionService.On("TerminateSessions", 12, uint(7)).Return(int64(2), nil)
+
+			req, _ := http.NewRequest("DELETE", "/api/users/12/sessions", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response map[string]int
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["terminated"]).To(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type UserPermissionsController struct {
+	logger        *logger.Logger
+	service       services.RoleServiceInterface
+	authService   services.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewUserPermissionsController(logger *logger.Logger, service services.RoleServiceInterface, authService services.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *UserPermissionsController {
+	return &UserPermissionsController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *UserPermissionsController) RegisterRoutes(r *gin.Engine) {
+	userRoutes := r.Group("/api/users/:userId")
+	{
+		userRoutes.GET("/roles", c.authService.AuthUserAbilityWrapper(c.listUserRoles, constants.ABILITY_READ_ROLE))
+		userRoutes.PUT("/roles/:roleId", c.authService.AuthUserAbilityWrapper(c.AddUserRole, constants.ABILITY_READ_WRITE_ROLE))
+		userRoutes.DELETE("/roles/:roleId", c.authService.AuthUserAbilityWrapper(c.RemoveUserRole, constants.ABILITY_READ_WRITE_ROLE))
+		userRoutes.GET("/abilities", c.authService.AuthUserAbilityWrapper(c.listUserAbilities, constants.ABILITY_READ_ROLE))
+		userRoutes.PUT("/abilities/:abilityId", c.authService.AuthUserAbilityWrapper(c.SetUserAbility, constants.ABILITY_READ_WRITE_ROLE))
+		userRoutes.DELETE("/abilities/:abilityId", c.authService.AuthUserAbilityWrapper(c.RemoveUserAbility, constants.ABILITY_READ_WRITE_ROLE))
+		userRoutes.GET("/permissions", c.authService.AuthTokenWrapper(c.GetEffectivePermissions))
+	}
+}
+
+func (c *UserPermissionsController) listUserRoles(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	errorMsg := "Failed to Find User Roles"
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	userRoles, err := c.service.FindUserRoles(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find User Roles", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewUserRoleListResponse(userRoles))
+}
+
+func (c *UserPermissionsController) AddUserRole(ctx *gin.Context) {
+	errorMsg := "Failed to Add User Role"
+	userID, roleID, err := parseUserTargetIDs(ctx, "roleId")
+	if err != nil {
+		c.logger.Error("Cannot not parse User or Role ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	var payload dtos.AddUserRoleRequest
+	// body is optional, role without window never expires
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			c.logger.Error("Cannot not parse user role payload", zap.Error(err))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+			return
+		}
+	}
+	role, err := c.service.FindRoleByID(roleID)
+	if err != nil {
+		c.logger.Error("Cannot not find Role", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+	if !authorizeGrant(ctx, c.authService, userID, role.Abilities, errorMsg) {
+		return
+	}
+
+	userRole, err := c.service.AddRoleToUser(ctx, userID, roleID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not add Role to User", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewUserRoleResponse(userRole))
+}
+
+func (c *UserPermissionsController) RemoveUserRole(ctx *gin.Context) {
+	errorMsg := "Failed to Remove User Role"
+	userID, roleID, err := parseUserTargetIDs(ctx, "roleId")
+	if err != nil {
+		c.logger.Error("Cannot not parse User or Role ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !authorizeGrant(ctx, c.authService, userID, nil, errorMsg) {
+		return
+	}
+
+	if err := c.service.RemoveRoleFromUser(ctx, userID, roleID); err != nil {
+		c.logger.Error("Cannot not remove Role from User", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (c *UserPermissionsController) listUserAbilities(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	errorMsg := "Failed to Find User Abilities"
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	userAbilities, err := c.service.FindUserAbilities(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find User Abilities", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewUserAbilityListResponse(userAbilities))
+}
+
+func (c *UserPermissionsController) SetUserAbility(ctx *gin.Context) {
+	errorMsg := "Failed to Set User Ability"
+	userID, abilityID, err := parseUserTargetIDs(ctx, "abilityId")
+	if err != nil {
+		c.logger.Error("Cannot not parse User or Ability ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	var payload dtos.SetUserAbilityRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse user ability payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	// denying takes power away, only grants need the ability held
+	granted := []models.Ability{}
+	if payload.Effect != constants.ABILITY_EFFECT_DENY {
+		granted, err = findAbilities(c.service, uint(abilityID))
+		if err != nil {
+			c.logger.Error("Cannot not find Ability", zap.Error(err))
+			respondRoleError(ctx, err, errorMsg)
+			return
+		}
+	}
+	if !authorizeGrant(ctx, c.authService, userID, granted, errorMsg) {
+		return
+	}
+
+	userAbility, err := c.service.SetUserAbility(ctx, userID, abilityID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not set User Ability", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewUserAbilityResponse(userAbility))
+}
+
+func (c *UserPermissionsController) RemoveUserAbility(ctx *gin.Context) {
+	errorMsg := "Failed to Remove User Ability"
+	userID, abilityID, err := parseUserTargetIDs(ctx, "abilityId")
+	if err != nil {
+		c.logger.Error("Cannot not parse User or Ability ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !authorizeGrant(ctx, c.authService, userID, nil, errorMsg) {
+		return
+	}
+
+	if err := c.service.RemoveUserAbility(ctx, userID, abilityID); err != nil {
+		c.logger.Error("Cannot not remove User Ability", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// users can explain their own permissions, others need read_role
+func (c *UserPermissionsController) GetEffectivePermissions(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	errorMsg := "Failed to Get Effective Permissions"
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_USER_PERMISSIONS, OwnerID: uint(userID)}
+	if !c.policyService.Authorize(ctx, resource, policy_constants.POLICY_ACTION_READ).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+
+	abilities, err := c.service.FindEffectivePermissions(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find Effective Permissions", zap.Error(err))
+		respondRoleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewEffectivePermissionsResponse(uint(userID), abilities))
+}
+
+func parseUserTargetIDs(ctx *gin.Context, targetParam string) (int, int, error) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		return 0, 0, err
+	}
+	targetID, err := strconv.Atoi(ctx.Param(targetParam))
+	if err != nil {
+		return 0, 0, err
+	}
+	return userID, targetID, nil
+}
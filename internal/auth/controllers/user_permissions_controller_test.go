@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_models "hr-system-go/internal/user/models"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("UserPermissionsController", func() {
+	var mockPolicy *mock_services.MockPolicyService
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockRoleService = &mock_services.MockRoleService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		router = gin.Default()
+		NewUserPermissionsController(mockLogger, mockRoleService, mockAuthService, mockPolicy).RegisterRoutes(router)
+		currentUser := &user_models.User{}
+		currentUser.ID = 1
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+	})
+
+	Describe("AddUserRole", func() {
+		It("should add role without validity window", func() {
+			userRole := &models.UserRole{UserID: 5, RoleID: 2}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE, constants.ABILITY_READ_USER})
+			mockRoleService.On("FindRoleByID", 2).Return(&models.Role{Abilities: []models.Ability{{Name: constants.ABILITY_READ_USER}}}, nil)
+			mockRoleService.On("AddRoleToUser", 5, 2, dtos.AddUserRoleRequest{}).Return(userRole, nil)
+
+			req, _ := http.NewRequest("PUT", "/api/users/5/roles/2", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should reject invalid validity window", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ADMIN})
+			mockRoleService.On("FindRoleByID", 2).Return(&models.Role{}, nil)
+			mockRoleService.On("AddRoleToUser", 5, 2, mock.AnythingOfType("dtos.AddUserRoleRequest")).Return(nil, services.ErrInvalidValidity)
+
+			body := `{"validFrom": "2026-10-20T00:00:00Z", "validUntil": "2026-10-19T00:00:00Z"}`
+			req, _ := http.NewRequest("PUT", "/api/users/5/roles/2", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should refuse role with admin ability to non admin", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE})
+			mockRoleService.On("FindRoleByID", 6).Return(&models.Role{Abilities: []models.Ability{{Name: constants.ABILITY_ADMIN}}}, nil)
+
+			req, _ := http.NewRequest("PUT", "/api/users/5/roles/6", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockRoleService.AssertNotCalled(GinkgoT(), "AddRoleToUser", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should refuse adding role to oneself", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ADMIN})
+			mockRoleService.On("FindRoleByID", 2).Return(&models.Role{}, nil)
+
+			req, _ := http.NewRequest("PUT", "/api/users/1/roles/2", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockRoleService.AssertNotCalled(GinkgoT(), "AddRoleToUser", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("SetUserAbility", func() {
+		It("should deny ability to user", func() {
+			payload := dtos.SetUserAbilityRequest{Effect: constants.ABILITY_EFFECT_DENY}
+			userAbility := &models.UserAbility{UserID: 5, AbilityID: 3, Effect: payload.Effect}
+			mockRoleService.On("SetUserAbility", 5, 3, payload).Return(userAbility, nil)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE})
+
+			req, _ := http.NewRequest("PUT", "/api/users/5/abilities/3", bytes.NewBufferString(`{"effect": "deny"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.UserAbilityResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Effect).To(Equal(constants.ABILITY_EFFECT_DENY))
+		})
+
+		It("should refuse granting admin ability to oneself", func() {
+			admin := models.Ability{Name: constants.ABILITY_ADMIN}
+			admin.ID = 13
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_WRITE_ROLE})
+			mockRoleService.On("FindAbilities").Return([]models.Ability{admin}, nil)
+
+			for _, userID := range []string{"1", "5"} {
+				req, _ := http.NewRequest("PUT", "/api/users/"+userID+"/abilities/13", bytes.NewBufferString(`{"effect": "grant"}`))
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			}
+			mockRoleService.AssertNotCalled(GinkgoT(), "SetUserAbility", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("GetEffectivePermissions", func() {
+		It("should return abilities with their sources", func() {
+			roleID := uint(2)
+			roleName := "Interim HR"
+			abilities := []models.EffectiveAbility{{
+				Name:    constants.ABILITY_READ_USER,
+				Granted: true,
+				Sources: []models.PermissionSource{{Type: constants.PERMISSION_SOURCE_ROLE, RoleID: &roleID, RoleName: &roleName}},
+			}}
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockRoleService.On("FindEffectivePermissions", 5).Return(abilities, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/permissions", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.EffectivePermissionsResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(1))
+			Expect(*response.Items[0].Sources[0].RoleName).To(Equal(roleName))
+		})
+
+		It("should forbid other user's permissions without ability", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
+
+			req, _ := http.NewRequest("GET", "/api/users/6/permissions", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+})
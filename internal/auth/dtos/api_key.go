@@ -0,0 +1,111 @@
+package dtos
+
+import (
+	"hr-system-go/internal/auth/models"
+	user_models "hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"time"
+)
+
+type ApiKeyListResponse struct {
+	Items []*ApiKeyResponse
+}
+
+type ApiKeyResponse struct {
+	Id         uint
+	UserId     uint
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// CreatedApiKeyResponse is the only response containing the whole key
+type CreatedApiKeyResponse struct {
+	ApiKeyResponse
+	Key string
+}
+
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type ServiceAccountListResponse struct {
+	Items      []*ServiceAccountResponse
+	Pagination utils.PaginationResult
+}
+
+type ServiceAccountResponse struct {
+	Id       uint
+	Name     string
+	Email    string
+	Status   string
+	RoleId   *uint
+	RoleName *string
+}
+
+type CreateServiceAccountRequest struct {
+	Name   string `json:"name"`
+	RoleID *uint  `json:"roleId,omitempty"`
+}
+
+func NewApiKeyListResponse(apiKeys []models.ApiKey) *ApiKeyListResponse {
+	items := []*ApiKeyResponse{}
+	for _, apiKey := range apiKeys {
+		items = append(items, NewApiKeyResponse(&apiKey))
+	}
+
+	return &ApiKeyListResponse{Items: items}
+}
+
+func NewApiKeyResponse(apiKey *models.ApiKey) *ApiKeyResponse {
+	return &ApiKeyResponse{
+		Id:         apiKey.ID,
+		UserId:     apiKey.UserID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+func NewCreatedApiKeyResponse(apiKey *models.ApiKey, key string) *CreatedApiKeyResponse {
+	return &CreatedApiKeyResponse{
+		ApiKeyResponse: *NewApiKeyResponse(apiKey),
+		Key:            key,
+	}
+}
+
+func NewServiceAccountListResponse(users []user_models.User, totalRows int64, pagination utils.Pagination) *ServiceAccountListResponse {
+	items := []*ServiceAccountResponse{}
+	for _, user := range users {
+		items = append(items, NewServiceAccountResponse(&user))
+	}
+
+	return &ServiceAccountListResponse{
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
+	}
+}
+
+func NewServiceAccountResponse(user *user_models.User) *ServiceAccountResponse {
+	res := &ServiceAccountResponse{
+		Id:     user.ID,
+		Name:   user.Name,
+		Email:  user.Email,
+		Status: user.Status,
+		RoleId: user.RoleID,
+	}
+	if user.Role != nil {
+		res.RoleName = &user.Role.Name
+	}
+	return res
+}
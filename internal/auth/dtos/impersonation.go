@@ -0,0 +1,65 @@
+package dtos
+
+import (
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/utils"
+	"time"
+)
+
+type ImpersonationListResponse struct {
+	Items      []*ImpersonationResponse
+	Pagination utils.PaginationResult
+}
+
+type ImpersonationResponse struct {
+	Id        uint
+	ActorId   uint
+	UserId    uint
+	Reason    string
+	StartedAt time.Time
+	ExpiresAt time.Time
+	EndedAt   *time.Time
+	Active    bool
+}
+
+// StartedImpersonationResponse carries token acting as user, it is returned only once
+type StartedImpersonationResponse struct {
+	ImpersonationResponse
+	Token string
+}
+
+type StartImpersonationRequest struct {
+	Reason string `json:"reason"`
+}
+
+func NewImpersonationListResponse(impersonations []models.Impersonation, totalRows int64, pagination utils.Pagination) *ImpersonationListResponse {
+	items := []*ImpersonationResponse{}
+	for _, impersonation := range impersonations {
+		items = append(items, NewImpersonationResponse(&impersonation))
+	}
+
+	return &ImpersonationListResponse{
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
+	}
+}
+
+func NewImpersonationResponse(impersonation *models.Impersonation) *ImpersonationResponse {
+	return &ImpersonationResponse{
+		Id:        impersonation.ID,
+		ActorId:   impersonation.ActorID,
+		UserId:    impersonation.UserID,
+		Reason:    impersonation.Reason,
+		StartedAt: impersonation.StartedAt,
+		ExpiresAt: impersonation.ExpiresAt,
+		EndedAt:   impersonation.EndedAt,
+		Active:    impersonation.Active(),
+	}
+}
+
+func NewStartedImpersonationResponse(impersonation *models.Impersonation, token string) *StartedImpersonationResponse {
+	return &StartedImpersonationResponse{
+		ImpersonationResponse: *NewImpersonationResponse(impersonation),
+		Token:                 token,
+	}
+}
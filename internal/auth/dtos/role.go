@@ -0,0 +1,205 @@
+package dtos
+
+import (
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/utils"
+	"time"
+)
+
+type RoleListResponse struct {
+	Items      []*RoleResponse
+	Pagination utils.PaginationResult
+}
+
+type RoleResponse struct {
+	Id        uint
+	Name      string
+	Status    string
+	Abilities []*AbilityResponse
+}
+
+type AbilityListResponse struct {
+	Items []*AbilityResponse
+}
+
+type AbilityResponse struct {
+	Id           uint
+	Name         string
+	Status       string
+	Descriptions string
+}
+
+type CreateRoleRequest struct {
+	Name       string `json:"name"`
+	AbilityIDs []uint `json:"abilityIds,omitempty"`
+}
+
+type UpdateRoleRequest struct {
+	Name   *string `json:"name,omitempty"`
+	Status *string `json:"status,omitempty"`
+}
+
+type AssignUserRoleRequest struct {
+	// nil RoleID unassigns user's role
+	RoleID *int `json:"roleId"`
+}
+
+func NewRoleListResponse(roles []models.Role, totalRows int64, pagination utils.Pagination) *RoleListResponse {
+	items := []*RoleResponse{}
+	for _, role := range roles {
+		items = append(items, NewRoleResponse(&role))
+	}
+
+	return &RoleListResponse{
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
+	}
+}
+
+func NewRoleResponse(role *models.Role) *RoleResponse {
+	abilities := []*AbilityResponse{}
+	for _, ability := range role.Abilities {
+		abilities = append(abilities, NewAbilityResponse(&ability))
+	}
+
+	return &RoleResponse{
+		Id:        role.ID,
+		Name:      role.Name,
+		Status:    role.Status,
+		Abilities: abilities,
+	}
+}
+
+func NewAbilityListResponse(abilities []models.Ability) *AbilityListResponse {
+	items := []*AbilityResponse{}
+	for _, ability := range abilities {
+		items = append(items, NewAbilityResponse(&ability))
+	}
+
+	return &AbilityListResponse{Items: items}
+}
+
+func NewAbilityResponse(ability *models.Ability) *AbilityResponse {
+	return &AbilityResponse{
+		Id:           ability.ID,
+		Name:         ability.Name,
+		Status:       ability.Status,
+		Descriptions: ability.Descriptions,
+	}
+}
+
+type UserRoleListResponse struct {
+	Items []*UserRoleResponse
+}
+
+type UserRoleResponse struct {
+	Id         uint
+	UserId     uint
+	Role       *RoleResponse
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+}
+
+type UserAbilityListResponse struct {
+	Items []*UserAbilityResponse
+}
+
+type UserAbilityResponse struct {
+	Id      uint
+	UserId  uint
+	Ability *AbilityResponse
+	Effect  string
+}
+
+type AddUserRoleRequest struct {
+	ValidFrom  *time.Time `json:"validFrom"`
+	ValidUntil *time.Time `json:"validUntil"`
+}
+
+type SetUserAbilityRequest struct {
+	// Effect is either grant or deny
+	Effect string `json:"effect"`
+}
+
+func NewUserRoleListResponse(userRoles []models.UserRole) *UserRoleListResponse {
+	items := []*UserRoleResponse{}
+	for _, userRole := range userRoles {
+		items = append(items, NewUserRoleResponse(&userRole))
+	}
+
+	return &UserRoleListResponse{Items: items}
+}
+
+func NewUserRoleResponse(userRole *models.UserRole) *UserRoleResponse {
+	res := &UserRoleResponse{
+		Id:         userRole.ID,
+		UserId:     userRole.UserID,
+		ValidFrom:  userRole.ValidFrom,
+		ValidUntil: userRole.ValidUntil,
+	}
+	if userRole.Role != nil {
+		res.Role = NewRoleResponse(userRole.Role)
+	}
+	return res
+}
+
+func NewUserAbilityListResponse(userAbilities []models.UserAbility) *UserAbilityListResponse {
+	items := []*UserAbilityResponse{}
+	for _, userAbility := range userAbilities {
+		items = append(items, NewUserAbilityResponse(&userAbility))
+	}
+
+	return &UserAbilityListResponse{Items: items}
+}
+
+func NewUserAbilityResponse(userAbility *models.UserAbility) *UserAbilityResponse {
+	res := &UserAbilityResponse{
+		Id:     userAbility.ID,
+		UserId: userAbility.UserID,
+		Effect: userAbility.Effect,
+	}
+	if userAbility.Ability != nil {
+		res.Ability = NewAbilityResponse(userAbility.Ability)
+	}
+	return res
+}
+
+type EffectivePermissionsResponse struct {
+	UserId uint
+	Items  []*EffectiveAbilityResponse
+}
+
+type EffectiveAbilityResponse struct {
+	Name    string
+	Granted bool
+	Sources []*PermissionSourceResponse
+}
+
+type PermissionSourceResponse struct {
+	Type       string
+	RoleId     *uint      `json:",omitempty"`
+	RoleName   *string    `json:",omitempty"`
+	ValidUntil *time.Time `json:",omitempty"`
+}
+
+func NewEffectivePermissionsResponse(userID uint, abilities []models.EffectiveAbility) *EffectivePermissionsResponse {
+	items := []*EffectiveAbilityResponse{}
+	for _, ability := range abilities {
+		sources := []*PermissionSourceResponse{}
+		for _, source := range ability.Sources {
+			sources = append(sources, &PermissionSourceResponse{
+				Type:       source.Type,
+				RoleId:     source.RoleID,
+				RoleName:   source.RoleName,
+				ValidUntil: source.ValidUntil,
+			})
+		}
+		items = append(items, &EffectiveAbilityResponse{
+			Name:    ability.Name,
+			Granted: ability.Granted,
+			Sources: sources,
+		})
+	}
+
+	return &EffectivePermissionsResponse{UserId: userID, Items: items}
+}
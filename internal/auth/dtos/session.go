@@ -0,0 +1,42 @@
+package dtos
+
+import (
+	"hr-system-go/internal/auth/models"
+	"time"
+)
+
+type SessionListResponse struct {
+	Items []*SessionResponse
+}
+
+type SessionResponse struct {
+	Id         uint
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	// Current marks session of the token making the request
+	Current bool
+}
+
+func NewSessionListResponse(sessions []models.Session, current *models.Session) *SessionListResponse {
+	items := []*SessionResponse{}
+	for _, session := range sessions {
+		items = append(items, NewSessionResponse(&session, current))
+	}
+
+	return &SessionListResponse{Items: items}
+}
+
+func NewSessionResponse(session *models.Session, current *models.Session) *SessionResponse {
+	return &SessionResponse{
+		Id:         session.ID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+		Current:    current != nil && current.ID == session.ID,
+	}
+}
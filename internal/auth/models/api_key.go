@@ -0,0 +1,31 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApiKey belongs to user, only sha256 of secret is stored and Prefix is used for lookup
+type ApiKey struct {
+	base_model.BaseModel
+	UserID     uint       `gorm:"not null;index"`
+	Name       string     `gorm:"not null"`
+	Prefix     string     `gorm:"not null;uniqueIndex;size:16"`
+	SecretHash string     `gorm:"not null;size:64"`
+	Scopes     []string   `gorm:"serializer:json;type:text"`
+	ExpiresAt  *time.Time `gorm:"type:timestamp;default:null"`
+	LastUsedAt *time.Time `gorm:"type:timestamp;default:null"`
+	RevokedAt  *time.Time `gorm:"type:timestamp;default:null"`
+}
+
+func ActiveApiKeyScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&ApiKey{}).
+		Where("revoked_at IS NULL").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
+
+func (k *ApiKey) Expired() bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now())
+}
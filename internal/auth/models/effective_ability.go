@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// PermissionSource is where ability of user comes from, it is not stored
+type PermissionSource struct {
+	Type       string
+	RoleID     *uint
+	RoleName   *string
+	ValidUntil *time.Time
+}
+
+// EffectiveAbility explains why ability is granted or not, Granted is false only when ability is denied
+type EffectiveAbility struct {
+	Name    string
+	Granted bool
+	Sources []PermissionSource
+}
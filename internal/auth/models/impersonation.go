@@ -0,0 +1,30 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Impersonation is a support session in which actor acts as user,
+// rows are never removed so they serve as audit trail of start and stop
+type Impersonation struct {
+	base_model.BaseModel
+	ActorID   uint       `gorm:"not null;index"`
+	UserID    uint       `gorm:"not null;index"`
+	Reason    string     `gorm:"type:text"`
+	StartedAt time.Time  `gorm:"type:timestamp;default:current_timestamp()"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;default:current_timestamp()"`
+	EndedAt   *time.Time `gorm:"type:timestamp;default:null"`
+}
+
+func ActiveImpersonationScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&Impersonation{}).
+		Where("ended_at IS NULL").
+		Where("expires_at > ?", time.Now())
+}
+
+func (i *Impersonation) Active() bool {
+	return i.EndedAt == nil && i.ExpiresAt.After(time.Now())
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	base_model "hr-system-go/internal/base/models"
+
+	"gorm.io/gorm"
 )
 
 type Role struct {
@@ -9,6 +11,16 @@ type Role struct {
 	Name      string    `gorm:"not null"`
 	Status    string    `gorm:"default:'active'"`
 	Abilities []Ability `gorm:"many2many:role_abilities;"`
+	// Version is bumped on every change of role, permission cache keys depend on it
+	Version uint `gorm:"not null;default:1"`
+}
+
+func ValidScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&Role{}).Where("status != ?", "removed")
+}
+
+func BumpVersion(db *gorm.DB, roleID uint) error {
+	return db.Model(&Role{}).Where("id = ?", roleID).Update("version", gorm.Expr("version + 1")).Error
 }
 
 func (r *Role) GetAbilityNames() []string {
@@ -0,0 +1,28 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is a sign in of user, session tokens carry its ID in sid claim
+// and are rejected once it is terminated
+type Session struct {
+	base_model.BaseModel
+	UserID     uint      `gorm:"not null;index"`
+	UserAgent  string    `gorm:"type:text"`
+	IP         string    `gorm:"size:64"`
+	LastSeenAt time.Time `gorm:"type:timestamp;default:current_timestamp()"`
+	ExpiresAt  time.Time `gorm:"type:timestamp;default:current_timestamp()"`
+	// TerminatedByID is the user who signed session out, owner or admin
+	TerminatedByID *uint
+	TerminatedAt   *time.Time `gorm:"type:timestamp;default:null"`
+}
+
+func ActiveSessionScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&Session{}).
+		Where("terminated_at IS NULL").
+		Where("expires_at > ?", time.Now())
+}
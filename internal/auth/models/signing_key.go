@@ -0,0 +1,17 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+)
+
+// SigningKey signs JWT, only active key signs new tokens while previous key is kept
+// for verifying tokens issued before rotation
+type SigningKey struct {
+	base_model.BaseModel
+	Kid         string    `gorm:"not null;uniqueIndex;size:64"`
+	Algorithm   string    `gorm:"not null"`
+	PrivateKey  string    `gorm:"type:text;not null"`
+	Status      string    `gorm:"not null;index;default:'active'"`
+	ActivatedAt time.Time `gorm:"type:timestamp;default:current_timestamp()"`
+}
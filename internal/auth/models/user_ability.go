@@ -0,0 +1,14 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+)
+
+// UserAbility grants or denies single ability to user regardless of user's roles, deny always wins
+type UserAbility struct {
+	base_model.BaseModel
+	UserID    uint     `gorm:"not null;index:idx_user_ability,unique"`
+	AbilityID uint     `gorm:"not null;index:idx_user_ability,unique"`
+	Ability   *Ability `gorm:"foreignKey:AbilityID"`
+	Effect    string   `gorm:"not null"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRole is an additional role of user on top of user's primary role.
+// Nil ValidFrom/ValidUntil means the role is not limited on that side.
+type UserRole struct {
+	base_model.BaseModel
+	UserID     uint       `gorm:"not null;index:idx_user_role,unique"`
+	RoleID     uint       `gorm:"not null;index:idx_user_role,unique"`
+	Role       *Role      `gorm:"foreignKey:RoleID"`
+	ValidFrom  *time.Time `gorm:"type:timestamp;default:null"`
+	ValidUntil *time.Time `gorm:"type:timestamp;default:null"`
+}
+
+func ActiveUserRoleScope(db *gorm.DB, at time.Time) *gorm.DB {
+	return db.Model(&UserRole{}).
+		Where("valid_from IS NULL OR valid_from <= ?", at).
+		Where("valid_until IS NULL OR valid_until > ?", at)
+}
+
+func (r *UserRole) ActiveAt(at time.Time) bool {
+	if r.ValidFrom != nil && r.ValidFrom.After(at) {
+		return false
+	}
+	return r.ValidUntil == nil || r.ValidUntil.After(at)
+}
@@ -3,7 +3,10 @@ package auth
 import (
 	"hr-system-go/app"
 	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/controllers"
 	"hr-system-go/internal/auth/services"
+
+	"github.com/gin-gonic/gin"
 )
 
 type AuthModule struct {
@@ -12,9 +15,28 @@ type AuthModule struct {
 
 func (m *AuthModule) Controllers() []interface{} {
 	return []interface{}{
+		controllers.NewRolesController,
+		controllers.NewPermissionCacheController,
+		controllers.NewUserPermissionsController,
+		controllers.NewApiKeysController,
+		controllers.NewImpersonationController,
+		controllers.NewSessionsController,
 		func(
+			r *gin.Engine,
+			c *controllers.RolesController,
+			pcc *controllers.PermissionCacheController,
+			upc *controllers.UserPermissionsController,
+			akc *controllers.ApiKeysController,
+			ic *controllers.ImpersonationController,
+			sc *controllers.SessionsController,
 			logger *logger.Logger,
 		) *AuthModule {
+			c.RegisterRoutes(r)
+			pcc.RegisterRoutes(r)
+			upc.RegisterRoutes(r)
+			akc.RegisterRoutes(r)
+			ic.RegisterRoutes(r)
+			sc.RegisterRoutes(r)
 			logger.Info("= Auth module init")
 			return m
 		},
@@ -23,6 +45,12 @@ func (m *AuthModule) Controllers() []interface{} {
 
 func (m *AuthModule) Provide() []interface{} {
 	return []interface{}{
+		services.NewPermissionCache,
+		services.NewKeyRing,
 		services.NewAuthService,
+		services.NewRoleService,
+		services.NewApiKeyService,
+		services.NewImpersonationService,
+		services.NewSessionService,
 	}
 }
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	user_constants "hr-system-go/internal/user/constants"
+	user_models "hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidApiKey = errors.New("invalid api key")
+var ErrApiKeyScope = errors.New("scopes must be a non-empty subset of owner's abilities")
+var ErrApiKeyExpiry = errors.New("expiresAt must be in the future")
+var ErrApiKeyOwner = errors.New("api keys can only be created for own account or service accounts")
+
+// last used timestamp is written at most once per interval to avoid a write on every request
+var ApiKeyLastUsedInterval = 1 * time.Minute
+
+type ApiKeyServiceInterface interface {
+	CreateServiceAccount(ctx context.Context, payload dtos.CreateServiceAccountRequest) (*user_models.User, error)
+	FindServiceAccounts(pagination *utils.Pagination) ([]user_models.User, int64, error)
+	FindApiKeys(userID int) ([]models.ApiKey, error)
+	CreateApiKey(userID int, createdByID uint, payload dtos.CreateApiKeyRequest) (*models.ApiKey, string, error)
+	RevokeApiKey(userID int, keyID int) error
+}
+
+type ApiKeyService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	cache  *PermissionCache
+}
+
+func NewApiKeyService(logger *logger.Logger, db *mysql.MySqlStore, cache *PermissionCache) ApiKeyServiceInterface {
+	return &ApiKeyService{
+		logger: logger,
+		db:     db,
+		cache:  cache,
+	}
+}
+
+func (s *ApiKeyService) CreateServiceAccount(ctx context.Context, payload dtos.CreateServiceAccountRequest) (*user_models.User, error) {
+	suffix, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	user := &user_models.User{
+		Name:   payload.Name,
+		Email:  fmt.Sprintf("service-account-%s@service-account.invalid", suffix),
+		Type:   user_constants.USER_TYPE_SERVICE_ACCOUNT,
+		RoleID: payload.RoleID,
+	}
+	// nobody knows the password, service accounts can only use API keys
+	user.GenerateRandomPassword()
+
+	if payload.RoleID != nil {
+		var role *models.Role
+		if err := models.ValidScope(s.db.DB()).First(&role, *payload.RoleID).Error; err != nil {
+			return nil, err
+		}
+		user.Role = role
+	}
+
+	if err := s.db.DB().WithContext(ctx).Omit("Role").Create(&user).Error; err != nil {
+		s.logger.Error("Cannot Create Service Account", zap.Error(err))
+		return nil, err
+	}
+	return user, nil
+}
+
+var serviceAccountSortFields = utils.SortFields{
+	"name":      "name",
+	"createdAt": "created_at",
+}
+
+func (s *ApiKeyService) FindServiceAccounts(pagination *utils.Pagination) ([]user_models.User, int64, error) {
+	var users []user_models.User
+	var totalCount int64 = 0
+
+	query := func() *gorm.DB {
+		return user_models.ValidScope(s.db.DB()).Where("type = ?", user_constants.USER_TYPE_SERVICE_ACCOUNT)
+	}
+	if err := query().Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := pagination.FindPage(query().Preload("Role"), serviceAccountSortFields, &users)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, totalCount, nil
+}
+
+func (s *ApiKeyService) FindApiKeys(userID int) ([]models.ApiKey, error) {
+	var apiKeys []models.ApiKey
+	if err := s.db.DB().Where("user_id = ?", userID).Order("id desc").Find(&apiKeys).Error; err != nil {
+		s.logger.Error("Cannot Find Api Keys", zap.Error(err))
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+// CreateApiKey returns the key itself only once, afterwards only its prefix is known.
+// Keys of other humans are refused, calls with them would act as the user without the trail impersonation leaves
+func (s *ApiKeyService) CreateApiKey(userID int, createdByID uint, payload dtos.CreateApiKeyRequest) (*models.ApiKey, string, error) {
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		return nil, "", ErrApiKeyExpiry
+	}
+
+	var user *user_models.User
+	if err := user_models.ValidScope(s.db.DB()).First(&user, userID).Error; err != nil {
+		return nil, "", err
+	}
+	if user.ID != createdByID && user.Type != user_constants.USER_TYPE_SERVICE_ACCOUNT {
+		return nil, "", ErrApiKeyOwner
+	}
+	if err := s.validateScopes(user, payload.Scopes); err != nil {
+		return nil, "", err
+	}
+
+	prefix, err := randomHex(4)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := &models.ApiKey{
+		UserID:     user.ID,
+		Name:       payload.Name,
+		Prefix:     prefix,
+		SecretHash: hashApiKeySecret(secret),
+		Scopes:     payload.Scopes,
+		ExpiresAt:  payload.ExpiresAt,
+	}
+	if err := s.db.DB().Create(&apiKey).Error; err != nil {
+		s.logger.Error("Cannot Create Api Key", zap.Error(err))
+		return nil, "", err
+	}
+
+	return apiKey, constants.API_KEY_TOKEN_PREFIX + prefix + "_" + secret, nil
+}
+
+func (s *ApiKeyService) RevokeApiKey(userID int, keyID int) error {
+	result := s.db.DB().Model(&models.ApiKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		s.logger.Error("Cannot Revoke Api Key", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// admin may delegate any existing ability, others only what they currently have
+func (s *ApiKeyService) validateScopes(user *user_models.User, scopes []string) error {
+	if len(scopes) == 0 {
+		return ErrApiKeyScope
+	}
+
+	var existing int64
+	if err := s.db.DB().Model(&models.Ability{}).Where("name IN ?", scopes).Distinct("name").Count(&existing).Error; err != nil {
+		return err
+	}
+	if int(existing) != len(uniqueStrings(scopes)) {
+		return ErrApiKeyScope
+	}
+
+	abilities, err := s.cache.UserAbilities(user)
+	if err != nil {
+		return err
+	}
+	owned := map[string]bool{}
+	for _, ability := range abilities {
+		owned[ability] = true
+	}
+	if owned[constants.ABILITY_ADMIN] {
+		return nil
+	}
+	for _, scope := range scopes {
+		if !owned[scope] {
+			return ErrApiKeyScope
+		}
+	}
+	return nil
+}
+
+// findApiKeyOwner checks "hrk_<prefix>_<secret>" key and returns the key with its owner
+func findApiKeyOwner(db *gorm.DB, token string) (*models.ApiKey, *user_models.User, error) {
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(token, constants.API_KEY_TOKEN_PREFIX), "_")
+	if !found || prefix == "" || secret == "" {
+		return nil, nil, ErrInvalidApiKey
+	}
+
+	var apiKey *models.ApiKey
+	if err := models.ActiveApiKeyScope(db).Where("prefix = ?", prefix).First(&apiKey).Error; err != nil {
+		return nil, nil, ErrInvalidApiKey
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.SecretHash), []byte(hashApiKeySecret(secret))) != 1 {
+		return nil, nil, ErrInvalidApiKey
+	}
+
+	var user *user_models.User
+	if err := user_models.ValidScope(db).Preload("Role").First(&user, apiKey.UserID).Error; err != nil {
+		return nil, nil, ErrInvalidApiKey
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > ApiKeyLastUsedInterval {
+		db.Model(&models.ApiKey{}).Where("id = ?", apiKey.ID).Update("last_used_at", now)
+		apiKey.LastUsedAt = &now
+	}
+	return apiKey, user, nil
+}
+
+func hashApiKeySecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -1,47 +1,72 @@
 package services
 
 import (
-	"fmt"
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
-	"hr-system-go/app/plugins/redis"
 	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/models"
 	user_models "hr-system-go/internal/user/models"
 	"hr-system-go/utils"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 )
 
 type AuthServiceInterface interface {
 	AuthTokenWrapper(handler gin.HandlerFunc) gin.HandlerFunc
 	AuthUserAbilityWrapper(handler gin.HandlerFunc, ability string) gin.HandlerFunc
-	AbleToAccessOtherUserData(ctx *gin.Context, userID int, ability string) bool
 	GetCurrentUser(ctx *gin.Context) *user_models.User
-	GenerateToken(userID uint, username string) (string, error)
+	GetCurrentUserAbilities(ctx *gin.Context) []string
+	GetCurrentApiKey(ctx *gin.Context) *models.ApiKey
+	GetActor(ctx *gin.Context) *user_models.User
+	GetImpersonation(ctx *gin.Context) *models.Impersonation
+	GetCurrentSession(ctx *gin.Context) *models.Session
+	GenerateToken(ctx *gin.Context, userID uint, username string) (string, error)
 }
 
+var SessionTokenTTL = 24 * time.Hour
+
+// SessionLastSeenInterval limits how often requests refresh last seen time of their session
+var SessionLastSeenInterval = time.Minute
+
+// TokenClockSkew tolerates clock difference between instances when checking exp, nbf and iat
+var TokenClockSkew = 30 * time.Second
+
 type AuthService struct {
-	logger *logger.Logger
-	env    *env.Env
-	db     *mysql.MySqlStore
-	rdb    *redis.RedisStore
-	jwtKey []byte
+	logger  *logger.Logger
+	env     *env.Env
+	db      *mysql.MySqlStore
+	cache   *PermissionCache
+	keyRing *KeyRing
+	issuer  string
 }
 
-func NewAuthService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, rdb *redis.RedisStore) AuthServiceInterface {
+func NewAuthService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, cache *PermissionCache, keyRing *KeyRing) AuthServiceInterface {
 	return &AuthService{
-		logger: logger,
-		env:    env,
-		db:     db,
-		rdb:    rdb,
-		jwtKey: []byte(env.GetEnv("JWT_TOKEN_KEY")),
+		logger:  logger,
+		env:     env,
+		db:      db,
+		cache:   cache,
+		keyRing: keyRing,
+		issuer:  TokenIssuer(env),
 	}
 }
 
+// TokenIssuer is iss of every token signed by key ring, OIDC_ISSUER falls back to local address
+func TokenIssuer(env *env.Env) string {
+	issuer := env.GetEnv("OIDC_ISSUER")
+	if issuer == "" {
+		issuer = "http://localhost:" + env.GetEnv("PORT")
+	}
+	return strings.TrimSuffix(issuer, "/")
+}
+
 func (s AuthService) AuthTokenWrapper(handler gin.HandlerFunc) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		s.authTokenAndSetCurrentUser()(ctx)
@@ -67,50 +92,105 @@ func (s AuthService) AuthUserAbilityWrapper(handler gin.HandlerFunc, requireAbil
 	}
 }
 
-// if current user has full permissions or is an admin, then they can view anyone's records
-func (s AuthService) AbleToAccessOtherUserData(ctx *gin.Context, targetUserId int, allGrantAbility string) bool {
-	currentUser := getCurrentUser(ctx)
-	var abilitiesName []string
-	redisKey := fmt.Sprintf("cache:users/%v/abilitiesNames", targetUserId)
-	err := s.rdb.Get(redisKey, &abilitiesName)
-	if err != nil {
-		abilitiesName = currentUser.Role.GetAbilityNames()
-		_ = s.rdb.Set(redisKey, abilitiesName, 24*time.Hour)
+// GenerateToken starts a session of user on the requesting device, its token is valid until session ends
+func (s AuthService) GenerateToken(ctx *gin.Context, userID uint, username string) (string, error) {
+	now := time.Now()
+	session := &models.Session{
+		UserID:     userID,
+		UserAgent:  ctx.Request.UserAgent(),
+		IP:         ctx.ClientIP(),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(SessionTokenTTL),
 	}
-
-	for _, abilityName := range abilitiesName {
-		if abilityName == constants.ABILITY_ADMIN || abilityName == allGrantAbility {
-			return true
-		}
+	if err := s.db.DB().Create(&session).Error; err != nil {
+		s.logger.Error("Cannot record session", zap.Error(err))
+		return "", err
 	}
-	return currentUser.ID == uint(targetUserId)
+
+	claims := sessionClaims(s.issuer, userID, username, now, SessionTokenTTL)
+	claims["sid"] = int(session.ID)
+	return s.keyRing.Sign(claims)
 }
 
-func (s AuthService) GenerateToken(userID uint, username string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+func sessionClaims(issuer string, userID uint, username string, now time.Time, ttl time.Duration) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":      issuer,
+		"aud":      constants.API_TOKEN_AUDIENCE,
+		"sub":      strconv.Itoa(int(userID)),
+		"iat":      now.Unix(),
+		"nbf":      now.Unix(),
+		"exp":      now.Add(ttl).Unix(),
 		"userId":   int(userID),
 		"userName": username,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
-	})
-	return token.SignedString(s.jwtKey)
+	}
 }
 
 func (s AuthService) GetCurrentUser(ctx *gin.Context) *user_models.User {
 	return getCurrentUser(ctx)
 }
 
+// GetCurrentUserAbilities is empty when abilities cannot be resolved, so nothing extra is granted
+func (s AuthService) GetCurrentUserAbilities(ctx *gin.Context) []string {
+	abilities, err := s.currentUserAbilities(ctx)
+	if err != nil {
+		s.logger.Error("Cannot get current user's abilities", zap.Error(err))
+		return []string{}
+	}
+	return abilities
+}
+
+// GetActor is the person really making request, it differs from current user while impersonating
+func (s AuthService) GetActor(ctx *gin.Context) *user_models.User {
+	if actor, ok := ctx.Get("impersonator"); ok {
+		if user, ok := actor.(*user_models.User); ok {
+			return user
+		}
+	}
+	return getCurrentUser(ctx)
+}
+
+// GetImpersonation is nil unless request was made with impersonation token
+func (s AuthService) GetImpersonation(ctx *gin.Context) *models.Impersonation {
+	impersonation, ok := ctx.Get("currentImpersonation")
+	if !ok {
+		return nil
+	}
+	session, _ := impersonation.(*models.Impersonation)
+	return session
+}
+
+// GetCurrentSession is nil for api key and impersonation requests
+func (s AuthService) GetCurrentSession(ctx *gin.Context) *models.Session {
+	session, ok := ctx.Get("currentSession")
+	if !ok {
+		return nil
+	}
+	currentSession, _ := session.(*models.Session)
+	return currentSession
+}
+
+// GetCurrentApiKey is nil when request was authenticated with JWT
+func (s AuthService) GetCurrentApiKey(ctx *gin.Context) *models.ApiKey {
+	apiKey, ok := ctx.Get("currentApiKey")
+	if !ok {
+		return nil
+	}
+	key, _ := apiKey.(*models.ApiKey)
+	return key
+}
+
 func (s AuthService) authUserAbility(requiredAbility string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		currentUser := getCurrentUser(ctx)
-		if currentUser.Role == nil || len(currentUser.Role.Abilities) == 0 {
-			s.logger.Error("User does not have any ability")
+		abilities, err := s.currentUserAbilities(ctx)
+		if err != nil || len(abilities) == 0 {
+			s.logger.Error("User does not have any ability", zap.Error(err))
 			ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
 			ctx.Abort()
 			return
 		}
 		hasAbility := false
-		for _, ability := range currentUser.Role.Abilities {
-			if ability.Name == requiredAbility || ability.Name == constants.ABILITY_ADMIN {
+		for _, ability := range abilities {
+			if ability == requiredAbility || ability == constants.ABILITY_ADMIN {
 				hasAbility = true
 				break
 			}
@@ -136,7 +216,12 @@ func (s AuthService) authTokenAndSetCurrentUser() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := ValidateToken(tokenString, s.jwtKey)
+		if strings.HasPrefix(tokenString, constants.API_KEY_TOKEN_PREFIX) {
+			s.authApiKeyAndSetCurrentUser(ctx, tokenString)
+			return
+		}
+
+		claims, err := ValidateToken(tokenString, s.keyRing, s.issuer, constants.API_TOKEN_AUDIENCE)
 		if err != nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			ctx.Abort()
@@ -159,24 +244,188 @@ func (s AuthService) authTokenAndSetCurrentUser() gin.HandlerFunc {
 			return
 		}
 
+		// abilities come from permission cache
 		var user *user_models.User
-		if err := s.db.DB().Preload("Role.Abilities").First(&user, userID).Error; err != nil {
-			s.logger.Error("Cannot find user's Role and Ability")
+		if err := user_models.ValidScope(s.db.DB()).Preload("Role").First(&user, userID).Error; err != nil {
+			s.logger.Error("Cannot find user and Role")
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			ctx.Abort()
 			return
 		}
+		// terminated employees keep their records but lose access
+		if user.Terminated() {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Account is no longer active"})
+			ctx.Abort()
+			return
+		}
+
+		// tokens issued before sessions were tracked carry no sid, they are accepted until they expire
+		if sessionID, exist := claims["sid"]; exist {
+			session, err := s.findSession(sessionID, user.ID)
+			if err != nil {
+				s.logger.Error("Cannot find active session", zap.Error(err))
+				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Session has ended"})
+				ctx.Abort()
+				return
+			}
+			ctx.Set("currentSession", session)
+		}
+
+		// impersonation token is valid only while its session is active
+		actor := user
+		if impersonationID, exist := claims["impersonationId"]; exist {
+			impersonation, impersonator, err := s.findImpersonation(impersonationID, user.ID)
+			if err != nil {
+				s.logger.Error("Cannot find active impersonation", zap.Error(err))
+				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Impersonation has ended"})
+				ctx.Abort()
+				return
+			}
+			ctx.Set("currentImpersonation", impersonation)
+			ctx.Set("impersonator", impersonator)
+			actor = impersonator
+		}
 
+		setAuditActor(ctx, user, actor)
 		ctx.Set("currentUser", user)
 		ctx.Set("userName", claims["userName"])
 		ctx.Next()
 	}
 }
 
-func ValidateToken(tokenString string, jwtKey []byte) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+func (s AuthService) findImpersonation(impersonationID interface{}, userID uint) (*models.Impersonation, *user_models.User, error) {
+	id, err := utils.ParseInterfaceToInt(impersonationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var impersonation *models.Impersonation
+	if err := models.ActiveImpersonationScope(s.db.DB()).Where("user_id = ?", userID).First(&impersonation, id).Error; err != nil {
+		return nil, nil, err
+	}
+	var actor *user_models.User
+	if err := user_models.ValidScope(s.db.DB()).Preload("Role").First(&actor, impersonation.ActorID).Error; err != nil {
+		return nil, nil, err
+	}
+	return impersonation, actor, nil
+}
+
+func (s AuthService) findSession(sessionID interface{}, userID uint) (*models.Session, error) {
+	id, err := utils.ParseInterfaceToInt(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var session *models.Session
+	if err := models.ActiveSessionScope(s.db.DB()).Where("user_id = ?", userID).First(&session, id).Error; err != nil {
+		return nil, err
+	}
+
+	// last seen is refreshed at most once per interval, not on every request
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) > SessionLastSeenInterval {
+		if err := s.db.DB().Model(&models.Session{}).Where("id = ?", session.ID).UpdateColumn("last_seen_at", now).Error; err != nil {
+			s.logger.Error("Cannot update session last seen time", zap.Error(err))
+		}
+		session.LastSeenAt = now
+	}
+	return session, nil
+}
+
+// setAuditActor lets audit callbacks know who made the change, actor differs from user while impersonating
+func setAuditActor(ctx *gin.Context, user *user_models.User, actor *user_models.User) {
+	auditContext := mysql.AuditContextFrom(ctx)
+	if auditContext == nil {
+		return
+	}
+	auditContext.ActorID = &actor.ID
+	if actor.ID != user.ID {
+		auditContext.OnBehalfOfID = &user.ID
+	}
+}
+
+func (s AuthService) authApiKeyAndSetCurrentUser(ctx *gin.Context, token string) {
+	apiKey, user, err := findApiKeyOwner(s.db.DB(), token)
+	if err != nil {
+		s.logger.Error("Cannot authenticate Api Key", zap.Error(err))
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		ctx.Abort()
+		return
+	}
+	if user.Terminated() {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Account is no longer active"})
+		ctx.Abort()
+		return
+	}
+
+	setAuditActor(ctx, user, user)
+	ctx.Set("currentUser", user)
+	ctx.Set("currentApiKey", apiKey)
+	ctx.Set("userName", user.Name)
+	ctx.Next()
+}
+
+// abilities are resolved once per request, API key requests are limited to key's scopes
+func (s AuthService) currentUserAbilities(ctx *gin.Context) ([]string, error) {
+	if abilities, exist := ctx.Get("currentUserAbilities"); exist {
+		if abilityNames, ok := abilities.([]string); ok {
+			return abilityNames, nil
+		}
+	}
+
+	currentUser := getCurrentUser(ctx)
+	if currentUser == nil {
+		return []string{}, nil
+	}
+	abilities, err := s.cache.UserAbilities(currentUser)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey := s.GetCurrentApiKey(ctx); apiKey != nil {
+		abilities = scopeAbilities(abilities, apiKey.Scopes)
+	}
+	ctx.Set("currentUserAbilities", abilities)
+	return abilities, nil
+}
+
+// owner's admin ability lets key use any of its scopes
+func scopeAbilities(abilities []string, scopes []string) []string {
+	owned := map[string]bool{}
+	for _, ability := range abilities {
+		owned[ability] = true
+	}
+
+	scoped := []string{}
+	for _, scope := range scopes {
+		if owned[scope] || owned[constants.ABILITY_ADMIN] {
+			scoped = append(scoped, scope)
+		}
+	}
+	return scoped
+}
+
+// ValidateToken accepts only tokens signed by a key of key ring with the algorithm of that key,
+// issued by issuer for audience and already valid by exp, nbf and iat
+func ValidateToken(tokenString string, keyRing *KeyRing, issuer string, audience string) (jwt.MapClaims, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		publicKey, method, err := keyRing.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return publicKey, nil
+	}
+	token, err := jwt.Parse(tokenString, keyFunc,
+		jwt.WithValidMethods(SigningAlgorithms),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(TokenClockSkew),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -2,26 +2,30 @@ package services
 
 import (
 	"encoding/json"
-	"fmt"
 	"hr-system-go/app/plugins/env"
 	http_server "hr-system-go/app/plugins/http"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
 	"hr-system-go/app/plugins/redis"
 	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
 	auth_models "hr-system-go/internal/auth/models"
+	user_constants "hr-system-go/internal/user/constants"
 	user_models "hr-system-go/internal/user/models"
 	http "net/http"
 	"net/http/httptest"
+	"os"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/bxcodec/faker/v3"
+	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/fx/fxtest"
 )
 
 func TestAuthService(t *testing.T) {
@@ -35,15 +39,27 @@ var (
 	mockEnv     *env.Env
 	mockDB      *mysql.MySqlStore
 	mockRDS     *redis.RedisStore
+	mockCache   *PermissionCache
+	mockKeyRing *KeyRing
 )
 
+// signInContext is a request a token is issued for
+func signInContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/sessions/signin", nil)
+	c.Request.Header.Set("User-Agent", "test-agent")
+	return c
+}
+
 var _ = BeforeSuite(func() {
 	mockEnv = env.NewEnv()
 	mockLogger = logger.NewLogger(mockEnv)
 	http_server.NewRouter(mockEnv, mockLogger)
 	mockDB = mysql.NewMySqlStore(mockEnv, mockLogger)
 	mockRDS = redis.NewRedisStore(mockEnv, mockLogger)
-	authService = NewAuthService(mockLogger, mockEnv, mockDB, mockRDS)
+	mockCache = NewPermissionCache(mockLogger, mockDB, mockRDS, fxtest.NewLifecycle(GinkgoT()))
+	mockKeyRing = NewKeyRing(mockLogger, mockEnv, mockDB)
+	authService = NewAuthService(mockLogger, mockEnv, mockDB, mockCache, mockKeyRing)
 
 	mockDB.Connect(
 		mockEnv.GetEnv("DB_USER"),
@@ -61,48 +77,32 @@ var _ = BeforeSuite(func() {
 		redisDB,
 	)
 
-	mockDB.DB().AutoMigrate(&user_models.User{}, &auth_models.Role{}, &auth_models.Ability{})
+	mockDB.DB().AutoMigrate(&user_models.User{}, &auth_models.Role{}, &auth_models.Ability{}, &auth_models.UserRole{}, &auth_models.UserAbility{}, &auth_models.ApiKey{}, &auth_models.SigningKey{}, &auth_models.Impersonation{}, &auth_models.Session{})
 })
 
 var _ = AfterSuite(func() {
 	mockRDS.ClearAll()
-	mockDB.DB().Migrator().DropTable(&user_models.User{}, &auth_models.Role{}, &auth_models.Ability{})
+	mockDB.DB().Migrator().DropTable(&user_models.User{}, &auth_models.Role{}, &auth_models.Ability{}, &auth_models.UserRole{}, &auth_models.UserAbility{}, &auth_models.ApiKey{}, &auth_models.SigningKey{}, &auth_models.Impersonation{}, &auth_models.Session{})
 	mockDB.Close()
 })
 
 var _ = Describe("AuthService", func() {
 	Describe("GenerateToken", func() {
 		It("should generate a valid token", func() {
-			token, err := authService.GenerateToken(1, "testuser")
+			token, err := authService.GenerateToken(signInContext(), 1, "testuser")
 			Expect(err).To(BeNil())
 			Expect(token).NotTo(BeEmpty())
 		})
 	})
 
-	Describe("AbleToAccessOtherUserData", func() {
+	Describe("GetCurrentUserAbilities", func() {
 		var ctx *gin.Context
 		BeforeEach(func() {
 			ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
 		})
 
-		Context("when user has admin ability", func() {
-			It("should return true", func() {
-				user := &user_models.User{
-					Email: faker.Email(),
-					Role: &auth_models.Role{
-						Abilities: []auth_models.Ability{{Name: constants.ABILITY_ADMIN}},
-					},
-				}
-				mockDB.DB().Create(&user)
-				ctx.Set("currentUser", user)
-
-				result := authService.AbleToAccessOtherUserData(ctx, int(user.ID), "some_ability")
-				Expect(result).To(BeTrue())
-			})
-		})
-
-		Context("when user has the required ability", func() {
-			It("should return true", func() {
+		Context("when user has role abilities", func() {
+			It("should return abilities of role", func() {
 				user := &user_models.User{
 					Email: faker.Email(),
 					Role: &auth_models.Role{
@@ -112,63 +112,35 @@ var _ = Describe("AuthService", func() {
 				mockDB.DB().Create(&user)
 				ctx.Set("currentUser", user)
 
-				result := authService.AbleToAccessOtherUserData(ctx, int(user.ID), "required_ability")
-				Expect(result).To(BeTrue())
-			})
-		})
-
-		Context("when user is accessing their own data", func() {
-			It("should return true", func() {
-				user := &user_models.User{
-					Email: faker.Email(),
-					Role: &auth_models.Role{
-						Abilities: []auth_models.Ability{{Name: "some_ability"}},
-					},
-				}
-				mockDB.DB().Create(&user)
-				ctx.Set("currentUser", user)
-
-				result := authService.AbleToAccessOtherUserData(ctx, int(user.ID), "some_ability")
-				Expect(result).To(BeTrue())
+				Expect(authService.GetCurrentUserAbilities(ctx)).To(ContainElement("required_ability"))
 			})
 		})
 
-		Context("when user doesn't have permission", func() {
-			It("should return false", func() {
-				user := &user_models.User{
-					Email: faker.Email(),
-					Role: &auth_models.Role{
-						Abilities: []auth_models.Ability{{Name: "some_ability"}},
-					},
-				}
-				mockDB.DB().Create(&user)
-				ctx.Set("currentUser", user)
-
-				result := authService.AbleToAccessOtherUserData(ctx, 999, "required_ability")
-				Expect(result).To(BeFalse())
+		Context("when there is no current user", func() {
+			It("should return no abilities", func() {
+				Expect(authService.GetCurrentUserAbilities(ctx)).To(BeEmpty())
 			})
 		})
 
 		Context("When abilities are cached in Redis", func() {
-			It("should return true with cache abilities", func() {
+			It("should return cache abilities", func() {
 				user := &user_models.User{
 					Email: faker.Email(),
 					Role: &auth_models.Role{
-						Abilities: []auth_models.Ability{{Name: constants.ABILITY_ADMIN}},
+						Abilities: []auth_models.Ability{{Name: "some_ability"}},
 					},
 				}
 				mockDB.DB().Create(&user)
 				ctx.Set("currentUser", user)
-				redisKey := fmt.Sprintf("cache:users/%v/abilitiesNames", int(user.ID))
+				redisKey := PermissionCacheKey(user.ID, []auth_models.Role{{BaseModel: user.Role.BaseModel, Version: 1}})
 				mockRDS.Set(redisKey, []string{constants.ABILITY_ADMIN}, 24*time.Hour)
 
 				var abilities []string
 				Expect(mockRDS.Get(redisKey, &abilities)).To(BeNil())
-				result := authService.AbleToAccessOtherUserData(ctx, int(user.ID), "required_ability")
-				Expect(result).To(BeTrue())
+				Expect(authService.GetCurrentUserAbilities(ctx)).To(ConsistOf(constants.ABILITY_ADMIN))
 			})
 
-			It("should return true without cache abilities", func() {
+			It("should cache abilities when they are not cached", func() {
 				user := &user_models.User{
 					Email: faker.Email(),
 					Role: &auth_models.Role{
@@ -177,16 +149,92 @@ var _ = Describe("AuthService", func() {
 				}
 				mockDB.DB().Create(&user)
 				ctx.Set("currentUser", user)
-				redisKey := fmt.Sprintf("cache:users/%v/abilitiesNames", int(user.ID))
+				redisKey := PermissionCacheKey(user.ID, []auth_models.Role{{BaseModel: user.Role.BaseModel, Version: 1}})
 
 				var abilities []string
 				Expect(mockRDS.Get(redisKey, &abilities)).NotTo(BeNil())
-				result := authService.AbleToAccessOtherUserData(ctx, int(user.ID), "required_ability")
-				Expect(result).To(BeTrue())
+				Expect(authService.GetCurrentUserAbilities(ctx)).To(ConsistOf(constants.ABILITY_ADMIN))
+				Expect(mockRDS.Get(redisKey, &abilities)).To(BeNil())
+				Expect(abilities).To(ConsistOf(constants.ABILITY_ADMIN))
 			})
 		})
 	})
 
+	Describe("PermissionCache", func() {
+		It("should reload abilities after role version changes", func() {
+			role := &auth_models.Role{Abilities: []auth_models.Ability{{Name: "before_change"}}}
+			mockDB.DB().Create(&role)
+			user := &user_models.User{Email: faker.Email(), RoleID: &role.ID}
+			mockDB.DB().Create(&user)
+
+			abilities, err := mockCache.UserAbilities(user)
+			Expect(err).To(BeNil())
+			Expect(abilities).To(ConsistOf("before_change"))
+
+			mockDB.DB().Model(&role).Association("Abilities").Replace([]auth_models.Ability{{Name: "after_change"}})
+			Expect(auth_models.BumpVersion(mockDB.DB(), role.ID)).To(BeNil())
+			mockCache.InvalidateRole(role.ID)
+
+			abilities, err = mockCache.UserAbilities(user)
+			Expect(err).To(BeNil())
+			Expect(abilities).To(ConsistOf("after_change"))
+		})
+
+		It("should merge abilities of additional roles active now", func() {
+			primary := &auth_models.Role{Abilities: []auth_models.Ability{{Name: "primary_ability"}}}
+			mockDB.DB().Create(&primary)
+			acting := &auth_models.Role{Abilities: []auth_models.Ability{{Name: "acting_ability"}}}
+			mockDB.DB().Create(&acting)
+			ended := &auth_models.Role{Abilities: []auth_models.Ability{{Name: "ended_ability"}}}
+			mockDB.DB().Create(&ended)
+			user := &user_models.User{Email: faker.Email(), RoleID: &primary.ID}
+			mockDB.DB().Create(&user)
+			tomorrow := time.Now().Add(24 * time.Hour)
+			yesterday := time.Now().Add(-24 * time.Hour)
+			mockDB.DB().Create(&auth_models.UserRole{UserID: user.ID, RoleID: acting.ID, ValidUntil: &tomorrow})
+			mockDB.DB().Create(&auth_models.UserRole{UserID: user.ID, RoleID: ended.ID, ValidUntil: &yesterday})
+
+			abilities, err := mockCache.UserAbilities(user)
+			Expect(err).To(BeNil())
+			Expect(abilities).To(ConsistOf("primary_ability", "acting_ability"))
+		})
+
+		It("should apply user's grants and denies after invalidation", func() {
+			role := &auth_models.Role{Abilities: []auth_models.Ability{{Name: "denied_ability"}}}
+			mockDB.DB().Create(&role)
+			granted := &auth_models.Ability{Name: "granted_ability"}
+			mockDB.DB().Create(&granted)
+			user := &user_models.User{Email: faker.Email(), RoleID: &role.ID}
+			mockDB.DB().Create(&user)
+			abilities, _ := mockCache.UserAbilities(user)
+			Expect(abilities).To(ConsistOf("denied_ability"))
+
+			mockDB.DB().Create(&auth_models.UserAbility{UserID: user.ID, AbilityID: role.Abilities[0].ID, Effect: constants.ABILITY_EFFECT_DENY})
+			mockDB.DB().Create(&auth_models.UserAbility{UserID: user.ID, AbilityID: granted.ID, Effect: constants.ABILITY_EFFECT_GRANT})
+			mockCache.InvalidateUser(user.ID)
+
+			abilities, err := mockCache.UserAbilities(user)
+			Expect(err).To(BeNil())
+			Expect(abilities).To(ConsistOf("granted_ability"))
+		})
+
+		It("should count hits and misses", func() {
+			role := &auth_models.Role{Abilities: []auth_models.Ability{{Name: "counted"}}}
+			mockDB.DB().Create(&role)
+			user := &user_models.User{Email: faker.Email(), RoleID: &role.ID}
+			mockDB.DB().Create(&user)
+			before := mockCache.Stats()
+
+			mockCache.UserAbilities(user)
+			mockCache.UserAbilities(user)
+
+			after := mockCache.Stats()
+			Expect(after.Misses - before.Misses).To(Equal(int64(1)))
+			Expect(after.Hits - before.Hits).To(Equal(int64(1)))
+			Expect(after.HitRate).To(BeNumerically(">", 0))
+		})
+	})
+
 	Describe("AuthTokenWrapper", func() {
 		var (
 			w *httptest.ResponseRecorder
@@ -236,7 +284,7 @@ var _ = Describe("AuthService", func() {
 			}
 
 			mockDB.DB().Create(&user)
-			validToken, _ := authService.GenerateToken(user.ID, "testuser")
+			validToken, _ := authService.GenerateToken(signInContext(), user.ID, "testuser")
 
 			r, _ = http.NewRequest(http.MethodGet, "/", nil)
 			r.Header.Set("Authorization", validToken)
@@ -250,6 +298,220 @@ var _ = Describe("AuthService", func() {
 
 			Expect(handlerCalled).To(BeTrue())
 			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(authService.GetCurrentSession(c)).NotTo(BeNil())
+			Expect(authService.GetCurrentSession(c).UserAgent).To(Equal("test-agent"))
+		})
+
+		It("should reject token of terminated session", func() {
+			user := &user_models.User{Email: faker.Email()}
+			mockDB.DB().Create(&user)
+			token, _ := authService.GenerateToken(signInContext(), user.ID, "testuser")
+			now := time.Now()
+			mockDB.DB().Model(&auth_models.Session{}).Where("user_id = ?", user.ID).Update("terminated_at", &now)
+
+			r, _ = http.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Authorization", token)
+			c.Request = r
+
+			handlerCalled := false
+			handler := authService.AuthTokenWrapper(func(c *gin.Context) {
+				handlerCalled = true
+			})
+			handler(c)
+
+			Expect(handlerCalled).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject token of terminated user", func() {
+			user := &user_models.User{Email: faker.Email(), LifecycleState: user_constants.LIFECYCLE_STATE_TERMINATED}
+			mockDB.DB().Create(&user)
+			token, _ := authService.GenerateToken(signInContext(), user.ID, "testuser")
+
+			r, _ = http.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Authorization", token)
+			c.Request = r
+
+			handlerCalled := false
+			handler := authService.AuthTokenWrapper(func(c *gin.Context) {
+				handlerCalled = true
+			})
+			handler(c)
+
+			Expect(handlerCalled).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("KeyRing", func() {
+		It("should keep tokens of previous key valid after rotation", func() {
+			token, err := authService.GenerateToken(signInContext(), 1, "testuser")
+			Expect(err).To(BeNil())
+
+			Expect(mockKeyRing.Rotate()).To(BeNil())
+
+			_, err = ValidateToken(token, mockKeyRing, TokenIssuer(mockEnv), constants.API_TOKEN_AUDIENCE)
+			Expect(err).To(BeNil())
+			keySet, _ := mockKeyRing.JWKS()
+			Expect(keySet.Keys).To(HaveLen(2))
+		})
+
+		It("should reject tokens of retired key", func() {
+			token, _ := authService.GenerateToken(signInContext(), 1, "testuser")
+
+			Expect(mockKeyRing.Rotate()).To(BeNil())
+			Expect(mockKeyRing.Rotate()).To(BeNil())
+
+			_, err := ValidateToken(token, mockKeyRing, TokenIssuer(mockEnv), constants.API_TOKEN_AUDIENCE)
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should reject HS256 token signed with public key", func() {
+			keySet, _ := mockKeyRing.JWKS()
+			forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"userId": 1})
+			forged.Header["kid"] = keySet.Keys[0].Kid
+			tokenString, _ := forged.SignedString([]byte(keySet.Keys[0].N))
+
+			_, err := ValidateToken(tokenString, mockKeyRing, TokenIssuer(mockEnv), constants.API_TOKEN_AUDIENCE)
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should reject token issued for another audience or not valid yet", func() {
+			now := time.Now()
+			claims := jwt.MapClaims{
+				"iss":    TokenIssuer(mockEnv),
+				"aud":    "some-oauth-client",
+				"iat":    now.Unix(),
+				"exp":    now.Add(time.Hour).Unix(),
+				"userId": 1,
+			}
+			idToken, _ := mockKeyRing.Sign(claims)
+			_, err := ValidateToken(idToken, mockKeyRing, TokenIssuer(mockEnv), constants.API_TOKEN_AUDIENCE)
+			Expect(err).To(MatchError(jwt.ErrTokenInvalidAudience))
+
+			claims["aud"] = constants.API_TOKEN_AUDIENCE
+			claims["nbf"] = now.Add(time.Hour).Unix()
+			early, _ := mockKeyRing.Sign(claims)
+			_, err = ValidateToken(early, mockKeyRing, TokenIssuer(mockEnv), constants.API_TOKEN_AUDIENCE)
+			Expect(err).To(MatchError(jwt.ErrTokenNotValidYet))
+		})
+
+		It("should sign with EdDSA key and keep verifying RSA key after switching", func() {
+			rsaToken, _ := authService.GenerateToken(signInContext(), 1, "testuser")
+
+			os.Setenv("SIGNING_KEY_ALGORITHM", constants.SIGNING_ALGORITHM_EDDSA)
+			edKeyRing := NewKeyRing(mockLogger, mockEnv, mockDB)
+			os.Unsetenv("SIGNING_KEY_ALGORITHM")
+			Expect(edKeyRing.Rotate()).To(BeNil())
+			edAuthService := NewAuthService(mockLogger, mockEnv, mockDB, mockCache, edKeyRing)
+
+			edToken, err := edAuthService.GenerateToken(signInContext(), 1, "testuser")
+			Expect(err).To(BeNil())
+			parsed, _, _ := jwt.NewParser().ParseUnverified(edToken, jwt.MapClaims{})
+			Expect(parsed.Method.Alg()).To(Equal(constants.SIGNING_ALGORITHM_EDDSA))
+
+			// other instance learns about new key on first token signed by it
+			reloadInterval := KeyRingUnknownKidReloadInterval
+			KeyRingUnknownKidReloadInterval = 0
+			defer func() { KeyRingUnknownKidReloadInterval = reloadInterval }()
+			_, err = ValidateToken(edToken, mockKeyRing, TokenIssuer(mockEnv), constants.API_TOKEN_AUDIENCE)
+			Expect(err).To(BeNil())
+			_, err = ValidateToken(rsaToken, edKeyRing, TokenIssuer(mockEnv), constants.API_TOKEN_AUDIENCE)
+			Expect(err).To(BeNil())
+
+			keySet, _ := edKeyRing.JWKS()
+			Expect(keySet.Keys[0].Kty).To(Equal("OKP"))
+			Expect(keySet.Keys[0].Crv).To(Equal("Ed25519"))
+		})
+	})
+
+	Describe("Api key authentication", func() {
+		var (
+			w             *httptest.ResponseRecorder
+			c             *gin.Context
+			apiKeyService ApiKeyServiceInterface
+			owner         *user_models.User
+		)
+
+		BeforeEach(func() {
+			gin.SetMode(gin.TestMode)
+			w = httptest.NewRecorder()
+			c, _ = gin.CreateTestContext(w)
+			apiKeyService = NewApiKeyService(mockLogger, mockDB, mockCache)
+			owner = &user_models.User{
+				Email: faker.Email(),
+				Role: &auth_models.Role{
+					Abilities: []auth_models.Ability{{Name: "scoped_ability"}, {Name: "unscoped_ability"}},
+				},
+			}
+			mockDB.DB().Create(&owner)
+		})
+
+		requestWithKey := func(key string) {
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Authorization", key)
+			c.Request = r
+		}
+
+		It("should limit abilities to key's scopes", func() {
+			_, key, err := apiKeyService.CreateApiKey(int(owner.ID), owner.ID, dtos.CreateApiKeyRequest{Name: "sync", Scopes: []string{"scoped_ability"}})
+			Expect(err).To(BeNil())
+			requestWithKey(key)
+
+			scopedCalled := false
+			authService.AuthUserAbilityWrapper(func(c *gin.Context) { scopedCalled = true }, "scoped_ability")(c)
+			Expect(scopedCalled).To(BeTrue())
+			Expect(authService.GetCurrentApiKey(c)).NotTo(BeNil())
+
+			w = httptest.NewRecorder()
+			c, _ = gin.CreateTestContext(w)
+			requestWithKey(key)
+			unscopedCalled := false
+			authService.AuthUserAbilityWrapper(func(c *gin.Context) { unscopedCalled = true }, "unscoped_ability")(c)
+			Expect(unscopedCalled).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should refuse key of another human user", func() {
+			_, _, err := apiKeyService.CreateApiKey(int(owner.ID), owner.ID+1, dtos.CreateApiKeyRequest{Name: "sync", Scopes: []string{"scoped_ability"}})
+
+			Expect(err).To(MatchError(ErrApiKeyOwner))
+		})
+
+		It("should reject scopes owner does not have", func() {
+			_, _, err := apiKeyService.CreateApiKey(int(owner.ID), owner.ID, dtos.CreateApiKeyRequest{Name: "sync", Scopes: []string{constants.ABILITY_ADMIN}})
+
+			Expect(err).To(MatchError(ErrApiKeyScope))
+		})
+
+		It("should reject revoked key", func() {
+			apiKey, key, _ := apiKeyService.CreateApiKey(int(owner.ID), owner.ID, dtos.CreateApiKeyRequest{Name: "sync", Scopes: []string{"scoped_ability"}})
+			Expect(apiKeyService.RevokeApiKey(int(owner.ID), int(apiKey.ID))).To(BeNil())
+			requestWithKey(key)
+
+			authService.AuthTokenWrapper(func(c *gin.Context) {})(c)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject key with wrong secret", func() {
+			apiKey, _, _ := apiKeyService.CreateApiKey(int(owner.ID), owner.ID, dtos.CreateApiKeyRequest{Name: "sync", Scopes: []string{"scoped_ability"}})
+			requestWithKey(constants.API_KEY_TOKEN_PREFIX + apiKey.Prefix + "_guessed")
+
+			authService.AuthTokenWrapper(func(c *gin.Context) {})(c)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should record last used time", func() {
+			apiKey, key, _ := apiKeyService.CreateApiKey(int(owner.ID), owner.ID, dtos.CreateApiKeyRequest{Name: "sync", Scopes: []string{"scoped_ability"}})
+			requestWithKey(key)
+
+			authService.AuthTokenWrapper(func(c *gin.Context) {})(c)
+
+			var stored auth_models.ApiKey
+			mockDB.DB().First(&stored, apiKey.ID)
+			Expect(stored.LastUsedAt).NotTo(BeNil())
 		})
 	})
 })
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// resolveEffectivePermissions merges abilities of primary role and roles active at given time,
+// then applies user's grants and denies
+func resolveEffectivePermissions(db *gorm.DB, userID uint, primaryRoleID *uint, at time.Time) ([]models.EffectiveAbility, error) {
+	effective := map[string]*models.EffectiveAbility{}
+	addSource := func(name string, source models.PermissionSource) {
+		ability, exist := effective[name]
+		if !exist {
+			ability = &models.EffectiveAbility{Name: name}
+			effective[name] = ability
+		}
+		ability.Sources = append(ability.Sources, source)
+	}
+
+	if primaryRoleID != nil {
+		var role *models.Role
+		err := models.ValidScope(db).Preload("Abilities", "status = ?", "active").Where("id = ?", *primaryRoleID).Limit(1).Find(&role).Error
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.ID != 0 {
+			for _, name := range role.GetAbilityNames() {
+				addSource(name, models.PermissionSource{Type: constants.PERMISSION_SOURCE_PRIMARY_ROLE, RoleID: &role.ID, RoleName: &role.Name})
+			}
+		}
+	}
+
+	var userRoles []models.UserRole
+	err := models.ActiveUserRoleScope(db, at).
+		Where("user_id = ?", userID).
+		Preload("Role", "status != ?", "removed").
+		Preload("Role.Abilities", "status = ?", "active").
+		Find(&userRoles).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, userRole := range userRoles {
+		if userRole.Role == nil {
+			continue
+		}
+		for _, name := range userRole.Role.GetAbilityNames() {
+			source := models.PermissionSource{
+				Type:       constants.PERMISSION_SOURCE_ROLE,
+				RoleID:     &userRole.Role.ID,
+				RoleName:   &userRole.Role.Name,
+				ValidUntil: userRole.ValidUntil,
+			}
+			addSource(name, source)
+		}
+	}
+
+	var overrides []models.UserAbility
+	if err := db.Where("user_id = ?", userID).Preload("Ability").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		if override.Ability == nil {
+			continue
+		}
+		addSource(override.Ability.Name, models.PermissionSource{Type: override.Effect})
+	}
+
+	abilities := make([]models.EffectiveAbility, 0, len(effective))
+	for _, ability := range effective {
+		ability.Granted = isGranted(ability)
+		abilities = append(abilities, *ability)
+	}
+	sort.Slice(abilities, func(i, j int) bool {
+		return abilities[i].Name < abilities[j].Name
+	})
+	return abilities, nil
+}
+
+// deny wins over roles and grants
+func isGranted(ability *models.EffectiveAbility) bool {
+	for _, source := range ability.Sources {
+		if source.Type == constants.PERMISSION_SOURCE_DENY {
+			return false
+		}
+	}
+	return len(ability.Sources) > 0
+}
+
+func grantedAbilityNames(abilities []models.EffectiveAbility) []string {
+	names := []string{}
+	for _, ability := range abilities {
+		if ability.Granted {
+			names = append(names, ability.Name)
+		}
+	}
+	return names
+}
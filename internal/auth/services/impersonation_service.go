@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	user_models "hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// impersonation token is short lived, support has to start a new session after it ends
+var ImpersonationTTL = 1 * time.Hour
+
+var ErrImpersonationReason = errors.New("reason is required")
+var ErrImpersonateSelf = errors.New("cannot impersonate yourself")
+var ErrImpersonationNotAllowed = errors.New("administrators and service accounts cannot be impersonated")
+
+type ImpersonationServiceInterface interface {
+	StartImpersonation(actor *user_models.User, userID int, payload dtos.StartImpersonationRequest) (*models.Impersonation, string, error)
+	StopImpersonation(impersonation *models.Impersonation) error
+	FindImpersonations(pagination *utils.Pagination) ([]models.Impersonation, int64, error)
+}
+
+type ImpersonationService struct {
+	logger  *logger.Logger
+	db      *mysql.MySqlStore
+	cache   *PermissionCache
+	keyRing *KeyRing
+	issuer  string
+}
+
+func NewImpersonationService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, cache *PermissionCache, keyRing *KeyRing) ImpersonationServiceInterface {
+	return &ImpersonationService{
+		logger:  logger,
+		db:      db,
+		cache:   cache,
+		keyRing: keyRing,
+		issuer:  TokenIssuer(env),
+	}
+}
+
+// StartImpersonation records session and returns token acting as user with actor in act claim
+func (s *ImpersonationService) StartImpersonation(actor *user_models.User, userID int, payload dtos.StartImpersonationRequest) (*models.Impersonation, string, error) {
+	if strings.TrimSpace(payload.Reason) == "" {
+		return nil, "", ErrImpersonationReason
+	}
+	if actor.ID == uint(userID) {
+		return nil, "", ErrImpersonateSelf
+	}
+
+	var user *user_models.User
+	if err := user_models.ValidScope(s.db.DB()).Preload("Role").First(&user, userID).Error; err != nil {
+		return nil, "", err
+	}
+	// acting as an administrator would let support use privileges they were not granted
+	abilities, err := s.cache.UserAbilities(user)
+	if err != nil {
+		return nil, "", err
+	}
+	if user.IsServiceAccount() || slices.Contains(abilities, constants.ABILITY_ADMIN) {
+		return nil, "", ErrImpersonationNotAllowed
+	}
+
+	now := time.Now()
+	impersonation := &models.Impersonation{
+		ActorID:   actor.ID,
+		UserID:    user.ID,
+		Reason:    strings.TrimSpace(payload.Reason),
+		StartedAt: now,
+		ExpiresAt: now.Add(ImpersonationTTL),
+	}
+	if err := s.db.DB().Create(&impersonation).Error; err != nil {
+		s.logger.Error("Cannot record impersonation", zap.Error(err))
+		return nil, "", err
+	}
+
+	claims := sessionClaims(s.issuer, user.ID, user.Name, now, ImpersonationTTL)
+	claims["act"] = map[string]interface{}{"sub": strconv.Itoa(int(actor.ID))}
+	claims["impersonationId"] = int(impersonation.ID)
+	token, err := s.keyRing.Sign(claims)
+	if err != nil {
+		s.logger.Error("Cannot sign impersonation token", zap.Error(err))
+		return nil, "", err
+	}
+
+	s.logger.Info("Impersonation started", zap.Uint("actorId", actor.ID), zap.Uint("userId", user.ID), zap.Uint("impersonationId", impersonation.ID))
+	return impersonation, token, nil
+}
+
+// StopImpersonation ends session, its token is rejected from now on
+func (s *ImpersonationService) StopImpersonation(impersonation *models.Impersonation) error {
+	now := time.Now()
+	if err := s.db.DB().Model(&impersonation).Update("ended_at", now).Error; err != nil {
+		s.logger.Error("Cannot stop impersonation", zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Impersonation stopped", zap.Uint("actorId", impersonation.ActorID), zap.Uint("userId", impersonation.UserID), zap.Uint("impersonationId", impersonation.ID))
+	return nil
+}
+
+var impersonationSortFields = utils.SortFields{
+	"startedAt": "started_at",
+	"expiresAt": "expires_at",
+}
+
+func (s *ImpersonationService) FindImpersonations(pagination *utils.Pagination) ([]models.Impersonation, int64, error) {
+	var impersonations []models.Impersonation
+	var totalCount int64 = 0
+
+	if err := s.db.DB().Model(&models.Impersonation{}).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := pagination.FindPage(s.db.DB().Model(&models.Impersonation{}), impersonationSortFields, &impersonations)
+	if err != nil {
+		s.logger.Error("Cannot Find Impersonations", zap.Error(err))
+		return nil, 0, err
+	}
+	return impersonations, totalCount, nil
+}
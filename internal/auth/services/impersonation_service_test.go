@@ -0,0 +1,89 @@
+package services
+
+import (
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	auth_models "hr-system-go/internal/auth/models"
+	user_models "hr-system-go/internal/user/models"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ImpersonationService", func() {
+	var (
+		impersonationService ImpersonationServiceInterface
+		admin                *user_models.User
+		employee             *user_models.User
+	)
+	payload := dtos.StartImpersonationRequest{Reason: "ticket #42"}
+
+	BeforeEach(func() {
+		impersonationService = NewImpersonationService(mockLogger, mockEnv, mockDB, mockCache, mockKeyRing)
+		admin = &user_models.User{
+			Name:  "Admin",
+			Email: faker.Email(),
+			Role:  &auth_models.Role{Name: faker.Word(), Abilities: []auth_models.Ability{{Name: constants.ABILITY_ADMIN}}},
+		}
+		employee = &user_models.User{
+			Name:  "Employee",
+			Email: faker.Email(),
+			Role:  &auth_models.Role{Name: faker.Word(), Abilities: []auth_models.Ability{{Name: constants.ABILITY_READ_USER}}},
+		}
+		mockDB.DB().Create(&admin)
+		mockDB.DB().Create(&employee)
+	})
+
+	authenticate := func(token string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Authorization", token)
+		authService.AuthTokenWrapper(func(c *gin.Context) {})(c)
+		return c, w
+	}
+
+	It("should act as user and expose real actor", func() {
+		impersonation, token, err := impersonationService.StartImpersonation(admin, int(employee.ID), payload)
+		Expect(err).To(BeNil())
+		Expect(impersonation.Active()).To(BeTrue())
+
+		c, w := authenticate(token)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(authService.GetCurrentUser(c).ID).To(Equal(employee.ID))
+		Expect(authService.GetActor(c).ID).To(Equal(admin.ID))
+		Expect(authService.GetImpersonation(c).ID).To(Equal(impersonation.ID))
+	})
+
+	It("should reject token after impersonation is stopped", func() {
+		impersonation, token, _ := impersonationService.StartImpersonation(admin, int(employee.ID), payload)
+
+		Expect(impersonationService.StopImpersonation(impersonation)).To(BeNil())
+
+		_, w := authenticate(token)
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+
+		var stored auth_models.Impersonation
+		mockDB.DB().First(&stored, impersonation.ID)
+		Expect(stored.EndedAt).NotTo(BeNil())
+	})
+
+	It("should not impersonate administrators or self", func() {
+		_, _, err := impersonationService.StartImpersonation(employee, int(admin.ID), payload)
+		Expect(err).To(MatchError(ErrImpersonationNotAllowed))
+
+		_, _, err = impersonationService.StartImpersonation(admin, int(admin.ID), payload)
+		Expect(err).To(MatchError(ErrImpersonateSelf))
+	})
+
+	It("should require reason", func() {
+		_, _, err := impersonationService.StartImpersonation(admin, int(employee.ID), dtos.StartImpersonationRequest{Reason: " "})
+
+		Expect(err).To(MatchError(ErrImpersonationReason))
+	})
+})
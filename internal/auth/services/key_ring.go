@@ -0,0 +1,371 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/models"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSigningKeyNotFound = errors.New("signing key not found")
+var ErrUnsupportedSigningAlgorithm = errors.New("unsupported signing algorithm")
+
+// SigningAlgorithms are accepted by token parser, each key still verifies only its own algorithm
+var SigningAlgorithms = []string{constants.SIGNING_ALGORITHM_RS256, constants.SIGNING_ALGORITHM_EDDSA}
+
+// keys are re-read from DB after this interval, so rotation on one instance reaches the others
+var KeyRingReloadInterval = 1 * time.Minute
+
+// token with unknown kid triggers reload at most once per this interval
+var KeyRingUnknownKidReloadInterval = 5 * time.Second
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type ringKey struct {
+	kid         string
+	method      jwt.SigningMethod
+	privateKey  crypto.Signer
+	status      string
+	activatedAt time.Time
+}
+
+// KeyRing holds active and previous signing keys stored in DB.
+// Active key older than SIGNING_KEY_ROTATION_DAYS is rotated automatically, 0 disables it.
+// New keys use SIGNING_KEY_ALGORITHM, so changing it takes effect on next rotation.
+type KeyRing struct {
+	logger      *logger.Logger
+	db          *mysql.MySqlStore
+	rotationAge time.Duration
+	algorithm   string
+	mu          sync.Mutex
+	keys        []*ringKey
+	loadedAt    time.Time
+}
+
+func NewKeyRing(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore) *KeyRing {
+	algorithm := env.GetEnv("SIGNING_KEY_ALGORITHM")
+	if algorithm == "" {
+		algorithm = constants.SIGNING_ALGORITHM_RS256
+	}
+	return &KeyRing{
+		logger:      logger,
+		db:          db,
+		rotationAge: time.Duration(env.GetEnvInt("SIGNING_KEY_ROTATION_DAYS", 30)) * 24 * time.Hour,
+		algorithm:   algorithm,
+	}
+}
+
+// Algorithm is the algorithm of keys created by next rotation
+func (k *KeyRing) Algorithm() string {
+	return k.algorithm
+}
+
+// Sign signs claims with active key and sets kid header
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	key, err := k.activeKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.privateKey)
+}
+
+// VerificationKey returns public key for kid with the only algorithm allowed for it.
+// Unknown kid may be a key just rotated on another instance, so keys are reloaded before giving up.
+func (k *KeyRing) VerificationKey(kid string) (crypto.PublicKey, jwt.SigningMethod, error) {
+	for _, maxAge := range []time.Duration{KeyRingReloadInterval, KeyRingUnknownKidReloadInterval} {
+		keys, err := k.loadKeys(maxAge)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range keys {
+			if key.kid == kid {
+				return key.privateKey.Public(), key.method, nil
+			}
+		}
+	}
+	return nil, nil, ErrSigningKeyNotFound
+}
+
+func (k *KeyRing) JWKS() (JSONWebKeySet, error) {
+	keySet := JSONWebKeySet{Keys: []JSONWebKey{}}
+	keys, err := k.loadedKeys()
+	if err != nil {
+		return keySet, err
+	}
+
+	for _, key := range keys {
+		switch publicKey := key.privateKey.Public().(type) {
+		case *rsa.PublicKey:
+			keySet.Keys = append(keySet.Keys, JSONWebKey{
+				Kty: "RSA",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keySet.Keys = append(keySet.Keys, JSONWebKey{
+				Kty: "OKP",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+	return keySet, nil
+}
+
+// Keys lists signing keys with newest first, private keys are not included
+func (k *KeyRing) Keys() ([]models.SigningKey, error) {
+	var signingKeys []models.SigningKey
+	err := k.db.DB().
+		Select("id", "kid", "algorithm", "status", "activated_at", "created_at", "updated_at").
+		Order("activated_at desc").
+		Find(&signingKeys).Error
+	if err != nil {
+		k.logger.Error("Cannot list signing keys", zap.Error(err))
+		return nil, err
+	}
+	return signingKeys, nil
+}
+
+// Rotate creates new active key, current active key becomes previous and previous one is retired
+func (k *KeyRing) Rotate() error {
+	return k.rotate(true)
+}
+
+// EnsureActiveKey creates active key when there is none or the active one is due for rotation
+func (k *KeyRing) EnsureActiveKey() error {
+	return k.rotate(false)
+}
+
+func (k *KeyRing) rotate(force bool) error {
+	err := k.db.DB().Transaction(func(tx *gorm.DB) error {
+		// lock active key, so instances rotating at the same time create only one new key
+		var active []models.SigningKey
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ?", constants.SIGNING_KEY_STATUS_ACTIVE).
+			Find(&active).Error
+		if err != nil {
+			return err
+		}
+		if !force && len(active) > 0 && !k.needsRotation(active[0].ActivatedAt) {
+			return nil
+		}
+
+		err = tx.Model(&models.SigningKey{}).
+			Where("status = ?", constants.SIGNING_KEY_STATUS_PREVIOUS).
+			Update("status", constants.SIGNING_KEY_STATUS_RETIRED).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.SigningKey{}).
+			Where("status = ?", constants.SIGNING_KEY_STATUS_ACTIVE).
+			Update("status", constants.SIGNING_KEY_STATUS_PREVIOUS).Error
+		if err != nil {
+			return err
+		}
+
+		signingKey, err := newSigningKey(k.algorithm)
+		if err != nil {
+			return err
+		}
+		return tx.Create(signingKey).Error
+	})
+	if err != nil {
+		k.logger.Error("Cannot rotate signing key", zap.Error(err))
+		return err
+	}
+
+	k.mu.Lock()
+	k.loadedAt = time.Time{}
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KeyRing) needsRotation(activatedAt time.Time) bool {
+	return k.rotationAge > 0 && time.Since(activatedAt) > k.rotationAge
+}
+
+func (k *KeyRing) activeKey() (*ringKey, error) {
+	keys, err := k.loadedKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.status == constants.SIGNING_KEY_STATUS_ACTIVE {
+			return key, nil
+		}
+	}
+	return nil, ErrSigningKeyNotFound
+}
+
+func (k *KeyRing) loadedKeys() ([]*ringKey, error) {
+	return k.loadKeys(KeyRingReloadInterval)
+}
+
+func (k *KeyRing) loadKeys(maxAge time.Duration) ([]*ringKey, error) {
+	k.mu.Lock()
+	if time.Since(k.loadedAt) < maxAge {
+		defer k.mu.Unlock()
+		return k.keys, nil
+	}
+	k.mu.Unlock()
+
+	keys, err := k.readKeys()
+	if err != nil {
+		return nil, err
+	}
+	if !hasActiveKey(keys) || k.needsRotation(activeActivatedAt(keys)) {
+		if err := k.rotate(false); err != nil {
+			return nil, err
+		}
+		if keys, err = k.readKeys(); err != nil {
+			return nil, err
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	k.loadedAt = time.Now()
+	return k.keys, nil
+}
+
+func (k *KeyRing) readKeys() ([]*ringKey, error) {
+	var signingKeys []models.SigningKey
+	err := k.db.DB().
+		Where("status IN ?", []string{constants.SIGNING_KEY_STATUS_ACTIVE, constants.SIGNING_KEY_STATUS_PREVIOUS}).
+		Order("activated_at desc").
+		Find(&signingKeys).Error
+	if err != nil {
+		k.logger.Error("Cannot load signing keys", zap.Error(err))
+		return nil, err
+	}
+
+	keys := []*ringKey{}
+	for _, signingKey := range signingKeys {
+		key, err := parseSigningKey(signingKey)
+		if err != nil {
+			k.logger.Error("Cannot parse signing key", zap.String("kid", signingKey.Kid), zap.Error(err))
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func newSigningKey(algorithm string) (*models.SigningKey, error) {
+	var privateKey crypto.Signer
+	var err error
+	switch algorithm {
+	case constants.SIGNING_ALGORITHM_RS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case constants.SIGNING_ALGORITHM_EDDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigningAlgorithm, algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		Kid:         kid,
+		Algorithm:   algorithm,
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		Status:      constants.SIGNING_KEY_STATUS_ACTIVE,
+		ActivatedAt: time.Now(),
+	}, nil
+}
+
+func parseSigningKey(signingKey models.SigningKey) (*ringKey, error) {
+	block, _ := pem.Decode([]byte(signingKey.PrivateKey))
+	if block == nil {
+		return nil, errors.New("invalid private key pem")
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+	method := jwt.GetSigningMethod(signingKey.Algorithm)
+	if method == nil || !slices.Contains(SigningAlgorithms, method.Alg()) {
+		return nil, ErrUnsupportedSigningAlgorithm
+	}
+
+	return &ringKey{
+		kid:         signingKey.Kid,
+		method:      method,
+		privateKey:  signer,
+		status:      signingKey.Status,
+		activatedAt: signingKey.ActivatedAt,
+	}, nil
+}
+
+func hasActiveKey(keys []*ringKey) bool {
+	for _, key := range keys {
+		if key.status == constants.SIGNING_KEY_STATUS_ACTIVE {
+			return true
+		}
+	}
+	return false
+}
+
+func activeActivatedAt(keys []*ringKey) time.Time {
+	for _, key := range keys {
+		if key.status == constants.SIGNING_KEY_STATUS_ACTIVE {
+			return key.activatedAt
+		}
+	}
+	return time.Time{}
+}
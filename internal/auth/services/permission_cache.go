@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/app/plugins/redis"
+	"hr-system-go/internal/auth/models"
+	user_models "hr-system-go/internal/user/models"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const permissionInvalidateChannel = "auth:permissions:invalidate"
+
+var PermissionCacheTTL = 1 * time.Hour
+var PermissionLocalCacheTTL = 5 * time.Minute
+
+type PermissionCacheStats struct {
+	Hits    int64
+	Misses  int64
+	HitRate float64
+}
+
+type permissionInvalidateMessage struct {
+	UserID uint `json:"userId,omitempty"`
+	RoleID uint `json:"roleId,omitempty"`
+}
+
+type localPermissionEntry struct {
+	abilities []string
+	expiresAt time.Time
+}
+
+// PermissionCache keeps user's granted ability names in process memory and redis.
+// Keys contain versions of user's roles, so changing a role makes old entries unreachable on every instance,
+// pub/sub messages additionally purge local entries which are no longer valid.
+type PermissionCache struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	rdb    *redis.RedisStore
+	mu     sync.RWMutex
+	local  map[string]localPermissionEntry
+	hits   atomic.Int64
+	misses atomic.Int64
+	close  func() error
+}
+
+func NewPermissionCache(logger *logger.Logger, db *mysql.MySqlStore, rdb *redis.RedisStore, lc fx.Lifecycle) *PermissionCache {
+	cache := &PermissionCache{
+		logger: logger,
+		db:     db,
+		rdb:    rdb,
+		local:  map[string]localPermissionEntry{},
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			cache.close = rdb.Subscribe(permissionInvalidateChannel, cache.handleInvalidate)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if cache.close == nil {
+				return nil
+			}
+			return cache.close()
+		},
+	})
+	return cache
+}
+
+// PermissionCacheKey lists all active roles of user with their versions,
+// so role changes and ended validity windows lead to a new key
+func PermissionCacheKey(userID uint, roles []models.Role) string {
+	var key strings.Builder
+	key.WriteString(fmt.Sprintf("cache:permissions/users/%d/roles", userID))
+	for _, role := range roles {
+		key.WriteString(fmt.Sprintf("/%dv%d", role.ID, role.Version))
+	}
+	return key.String()
+}
+
+func (c *PermissionCache) UserAbilities(user *user_models.User) ([]string, error) {
+	now := time.Now()
+	roles, err := c.activeRoles(user, now)
+	if err != nil {
+		c.logger.Error("Cannot find user's active Roles", zap.Error(err))
+		return nil, err
+	}
+	key := PermissionCacheKey(user.ID, roles)
+
+	if abilities, ok := c.getLocal(key); ok {
+		c.hits.Add(1)
+		return abilities, nil
+	}
+
+	var abilities []string
+	if err := c.rdb.Get(key, &abilities); err == nil {
+		c.hits.Add(1)
+		c.setLocal(key, abilities)
+		return abilities, nil
+	}
+
+	c.misses.Add(1)
+	effective, err := resolveEffectivePermissions(c.db.DB(), user.ID, user.RoleID, now)
+	if err != nil {
+		c.logger.Error("Cannot find user's Role and Ability", zap.Error(err))
+		return nil, err
+	}
+	abilities = grantedAbilityNames(effective)
+
+	if err := c.rdb.Set(key, abilities, PermissionCacheTTL); err != nil {
+		c.logger.Error("Cannot cache user's abilities", zap.Error(err))
+	}
+	c.setLocal(key, abilities)
+	return abilities, nil
+}
+
+// activeRoles loads only ids and versions of primary role and additional roles active at given time
+func (c *PermissionCache) activeRoles(user *user_models.User, at time.Time) ([]models.Role, error) {
+	db := c.db.DB()
+	roleIDs := models.ActiveUserRoleScope(db, at).Where("user_id = ?", user.ID).Select("role_id")
+	condition := db.Where("id IN (?)", roleIDs)
+	if user.RoleID != nil {
+		condition = condition.Or("id = ?", *user.RoleID)
+	}
+
+	var roles []models.Role
+	err := models.ValidScope(db).Where(condition).Select("id", "version").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// InvalidateUser is needed when user's roles or ability grants and denies change
+func (c *PermissionCache) InvalidateUser(userID uint) {
+	if err := c.rdb.DeletePattern(fmt.Sprintf("cache:permissions/users/%d/*", userID)); err != nil {
+		c.logger.Error("Cannot delete user's permission cache", zap.Error(err))
+	}
+	c.publish(permissionInvalidateMessage{UserID: userID})
+}
+
+// InvalidateRole should be called after role version was bumped
+func (c *PermissionCache) InvalidateRole(roleID uint) {
+	c.publish(permissionInvalidateMessage{RoleID: roleID})
+}
+
+func (c *PermissionCache) Stats() PermissionCacheStats {
+	stats := PermissionCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *PermissionCache) publish(message permissionInvalidateMessage) {
+	// purge own entries right away instead of waiting for the message round trip
+	c.purgeLocal(message)
+	if err := c.rdb.Publish(permissionInvalidateChannel, message); err != nil {
+		c.logger.Error("Cannot publish permission invalidation", zap.Error(err))
+	}
+}
+
+func (c *PermissionCache) handleInvalidate(payload []byte) {
+	var message permissionInvalidateMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		c.logger.Error("Cannot parse permission invalidation", zap.Error(err))
+		return
+	}
+	c.purgeLocal(message)
+}
+
+func (c *PermissionCache) purgeLocal(message permissionInvalidateMessage) {
+	userPrefix := fmt.Sprintf("cache:permissions/users/%d/", message.UserID)
+	roleSegment := fmt.Sprintf("/%dv", message.RoleID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.local {
+		if (message.UserID != 0 && strings.HasPrefix(key, userPrefix)) ||
+			(message.RoleID != 0 && strings.Contains(key, roleSegment)) {
+			delete(c.local, key)
+		}
+	}
+}
+
+func (c *PermissionCache) getLocal(key string) ([]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, exist := c.local[key]
+	if !exist || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.abilities, true
+}
+
+func (c *PermissionCache) setLocal(key string, abilities []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.local[key] = localPermissionEntry{abilities: abilities, expiresAt: time.Now().Add(PermissionLocalCacheTTL)}
+}
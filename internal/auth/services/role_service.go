@@ -0,0 +1,469 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	user_constants "hr-system-go/internal/user/constants"
+	user_models "hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrLastAdmin = errors.New("cannot remove the last admin")
+var ErrAbilityNotFound = errors.New("ability not found")
+var ErrInvalidValidity = errors.New("validUntil must be after validFrom")
+var ErrInvalidAbilityEffect = errors.New("effect must be grant or deny")
+var ErrInvalidRoleStatus = errors.New("status must be active or inactive")
+var ErrGrantNotHeld = errors.New("cannot grant abilities you do not hold")
+var ErrOwnGrants = errors.New("cannot change own roles or abilities")
+
+// leavingLifecycleStates do not count as admins, they lose access by the termination date
+var leavingLifecycleStates = []string{user_constants.LIFECYCLE_STATE_ON_NOTICE, user_constants.LIFECYCLE_STATE_TERMINATED}
+
+type RoleServiceInterface interface {
+	FindRoles(pagination *utils.Pagination) ([]models.Role, int64, error)
+	FindRoleByID(roleID int) (*models.Role, error)
+	CreateRole(ctx context.Context, payload dtos.CreateRoleRequest) (*models.Role, error)
+	UpdateRoleByID(ctx context.Context, roleID int, payload dtos.UpdateRoleRequest) (*models.Role, error)
+	DeleteRoleByID(ctx context.Context, roleID int) error
+	FindAbilities() ([]models.Ability, error)
+	AddAbilityToRole(ctx context.Context, roleID int, abilityID int) (*models.Role, error)
+	RemoveAbilityFromRole(ctx context.Context, roleID int, abilityID int) (*models.Role, error)
+	AssignRoleToUser(ctx context.Context, userID int, roleID *int) error
+	FindUserRoles(userID int) ([]models.UserRole, error)
+	AddRoleToUser(ctx context.Context, userID int, roleID int, payload dtos.AddUserRoleRequest) (*models.UserRole, error)
+	RemoveRoleFromUser(ctx context.Context, userID int, roleID int) error
+	FindUserAbilities(userID int) ([]models.UserAbility, error)
+	SetUserAbility(ctx context.Context, userID int, abilityID int, payload dtos.SetUserAbilityRequest) (*models.UserAbility, error)
+	RemoveUserAbility(ctx context.Context, userID int, abilityID int) error
+	FindEffectivePermissions(userID int) ([]models.EffectiveAbility, error)
+}
+
+type RoleService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	cache  *PermissionCache
+}
+
+func NewRoleService(logger *logger.Logger, db *mysql.MySqlStore, cache *PermissionCache) RoleServiceInterface {
+	return &RoleService{
+		logger: logger,
+		db:     db,
+		cache:  cache,
+	}
+}
+
+var roleSortFields = utils.SortFields{
+	"name":      "name",
+	"createdAt": "created_at",
+}
+
+// CheckGrant refuses abilities the granting user does not hold, so only admins grant admin
+func CheckGrant(held []string, abilities []models.Ability) error {
+	if slices.Contains(held, constants.ABILITY_ADMIN) {
+		return nil
+	}
+	for _, ability := range abilities {
+		if !slices.Contains(held, ability.Name) {
+			return ErrGrantNotHeld
+		}
+	}
+	return nil
+}
+
+func (s *RoleService) FindRoles(pagination *utils.Pagination) ([]models.Role, int64, error) {
+	var roles []models.Role
+	var totalCount int64 = 0
+
+	if err := models.ValidScope(s.db.DB()).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := pagination.FindPage(models.ValidScope(s.db.DB()).Preload("Abilities"), roleSortFields, &roles)
+	if err != nil {
+		return nil, 0, err
+	}
+	return roles, totalCount, nil
+}
+
+func (s *RoleService) FindRoleByID(roleID int) (*models.Role, error) {
+	var role *models.Role
+	if err := models.ValidScope(s.db.DB()).Preload("Abilities").First(&role, roleID).Error; err != nil {
+		s.logger.Error("Cannot Not Find Role by ID", zap.Error(err))
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, payload dtos.CreateRoleRequest) (*models.Role, error) {
+	role := &models.Role{Name: payload.Name}
+	if len(payload.AbilityIDs) > 0 {
+		var abilities []models.Ability
+		if err := s.db.DB().Find(&abilities, payload.AbilityIDs).Error; err != nil {
+			return nil, err
+		}
+		if len(abilities) != len(payload.AbilityIDs) {
+			return nil, ErrAbilityNotFound
+		}
+		role.Abilities = abilities
+	}
+
+	if err := s.db.DB().WithContext(ctx).Create(&role).Error; err != nil {
+		s.logger.Error("Cannot Create Role", zap.Error(err))
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) UpdateRoleByID(ctx context.Context, roleID int, payload dtos.UpdateRoleRequest) (*models.Role, error) {
+	if payload.Status != nil && !slices.Contains(constants.ROLE_STATUSES, *payload.Status) {
+		return nil, ErrInvalidRoleStatus
+	}
+
+	err := s.changeRole(ctx, uint(roleID), func(tx *gorm.DB) error {
+		var role *models.Role
+		return models.ValidScope(tx).First(&role, roleID).Updates(payload).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Update Role Data", zap.Error(err))
+		return nil, err
+	}
+
+	return s.FindRoleByID(roleID)
+}
+
+func (s *RoleService) DeleteRoleByID(ctx context.Context, roleID int) error {
+	return s.changeRole(ctx, uint(roleID), func(tx *gorm.DB) error {
+		var role *models.Role
+		if err := models.ValidScope(tx).First(&role, roleID).Update("status", "removed").Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", roleID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user_models.User{}).Where("role_id = ?", roleID).Update("role_id", nil).Error
+	})
+}
+
+func (s *RoleService) FindAbilities() ([]models.Ability, error) {
+	var abilities []models.Ability
+	if err := s.db.DB().Order("name asc").Find(&abilities).Error; err != nil {
+		s.logger.Error("Cannot Find Abilities", zap.Error(err))
+		return nil, err
+	}
+	return abilities, nil
+}
+
+func (s *RoleService) AddAbilityToRole(ctx context.Context, roleID int, abilityID int) (*models.Role, error) {
+	err := s.changeRole(ctx, uint(roleID), func(tx *gorm.DB) error {
+		role, ability, err := findRoleAndAbility(tx, roleID, abilityID)
+		if err != nil {
+			return err
+		}
+		return tx.Model(role).Association("Abilities").Append(ability)
+	})
+	if err != nil {
+		s.logger.Error("Cannot Add Ability to Role", zap.Error(err))
+		return nil, err
+	}
+
+	return s.FindRoleByID(roleID)
+}
+
+func (s *RoleService) RemoveAbilityFromRole(ctx context.Context, roleID int, abilityID int) (*models.Role, error) {
+	err := s.changeRole(ctx, uint(roleID), func(tx *gorm.DB) error {
+		role, ability, err := findRoleAndAbility(tx, roleID, abilityID)
+		if err != nil {
+			return err
+		}
+		return tx.Model(role).Association("Abilities").Delete(ability)
+	})
+	if err != nil {
+		s.logger.Error("Cannot Remove Ability from Role", zap.Error(err))
+		return nil, err
+	}
+
+	return s.FindRoleByID(roleID)
+}
+
+func (s *RoleService) AssignRoleToUser(ctx context.Context, userID int, roleID *int) error {
+	err := s.guardLastAdmin(ctx, func(tx *gorm.DB) error {
+		if roleID != nil {
+			var role *models.Role
+			if err := models.ValidScope(tx).First(&role, *roleID).Error; err != nil {
+				return err
+			}
+		}
+
+		var user *user_models.User
+		return user_models.ValidScope(tx).First(&user, userID).Update("role_id", roleID).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Assign Role to User", zap.Error(err))
+		return err
+	}
+
+	s.cache.InvalidateUser(uint(userID))
+	return nil
+}
+
+// FindUserRoles returns additional roles of user including not yet started and ended ones
+func (s *RoleService) FindUserRoles(userID int) ([]models.UserRole, error) {
+	var userRoles []models.UserRole
+	err := s.db.DB().Where("user_id = ?", userID).Preload("Role").Preload("Role.Abilities").Order("id asc").Find(&userRoles).Error
+	if err != nil {
+		s.logger.Error("Cannot Find User Roles", zap.Error(err))
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+// AddRoleToUser adds role with optional validity window, window of already added role is replaced
+func (s *RoleService) AddRoleToUser(ctx context.Context, userID int, roleID int, payload dtos.AddUserRoleRequest) (*models.UserRole, error) {
+	if payload.ValidFrom != nil && payload.ValidUntil != nil && !payload.ValidUntil.After(*payload.ValidFrom) {
+		return nil, ErrInvalidValidity
+	}
+
+	var userRole models.UserRole
+	err := s.guardLastAdmin(ctx, func(tx *gorm.DB) error {
+		var user *user_models.User
+		if err := user_models.ValidScope(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+		var role *models.Role
+		if err := models.ValidScope(tx).First(&role, roleID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ? AND role_id = ?", userID, roleID).Limit(1).Find(&userRole).Error; err != nil {
+			return err
+		}
+		userRole.UserID = uint(userID)
+		userRole.RoleID = uint(roleID)
+		userRole.ValidFrom = payload.ValidFrom
+		userRole.ValidUntil = payload.ValidUntil
+		userRole.Role = role
+		// Select("*") so that validity window can be cleared with nil
+		return tx.Select("*").Save(&userRole).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Add Role to User", zap.Error(err))
+		return nil, err
+	}
+
+	s.cache.InvalidateUser(uint(userID))
+	return &userRole, nil
+}
+
+func (s *RoleService) RemoveRoleFromUser(ctx context.Context, userID int, roleID int) error {
+	err := s.guardLastAdmin(ctx, func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{})
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Remove Role from User", zap.Error(err))
+		return err
+	}
+
+	s.cache.InvalidateUser(uint(userID))
+	return nil
+}
+
+func (s *RoleService) FindUserAbilities(userID int) ([]models.UserAbility, error) {
+	var userAbilities []models.UserAbility
+	if err := s.db.DB().Where("user_id = ?", userID).Preload("Ability").Order("id asc").Find(&userAbilities).Error; err != nil {
+		s.logger.Error("Cannot Find User Abilities", zap.Error(err))
+		return nil, err
+	}
+	return userAbilities, nil
+}
+
+// SetUserAbility grants or denies ability to user, previous effect of same ability is replaced
+func (s *RoleService) SetUserAbility(ctx context.Context, userID int, abilityID int, payload dtos.SetUserAbilityRequest) (*models.UserAbility, error) {
+	if payload.Effect != constants.ABILITY_EFFECT_GRANT && payload.Effect != constants.ABILITY_EFFECT_DENY {
+		return nil, ErrInvalidAbilityEffect
+	}
+
+	var userAbility models.UserAbility
+	err := s.guardLastAdmin(ctx, func(tx *gorm.DB) error {
+		var user *user_models.User
+		if err := user_models.ValidScope(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+		var ability *models.Ability
+		if err := tx.First(&ability, abilityID).Error; err != nil {
+			return ErrAbilityNotFound
+		}
+
+		if err := tx.Where("user_id = ? AND ability_id = ?", userID, abilityID).Limit(1).Find(&userAbility).Error; err != nil {
+			return err
+		}
+		userAbility.UserID = uint(userID)
+		userAbility.AbilityID = uint(abilityID)
+		userAbility.Effect = payload.Effect
+		userAbility.Ability = ability
+		return tx.Save(&userAbility).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Set User Ability", zap.Error(err))
+		return nil, err
+	}
+
+	s.cache.InvalidateUser(uint(userID))
+	return &userAbility, nil
+}
+
+func (s *RoleService) RemoveUserAbility(ctx context.Context, userID int, abilityID int) error {
+	err := s.guardLastAdmin(ctx, func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND ability_id = ?", userID, abilityID).Delete(&models.UserAbility{})
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Remove User Ability", zap.Error(err))
+		return err
+	}
+
+	s.cache.InvalidateUser(uint(userID))
+	return nil
+}
+
+func (s *RoleService) FindEffectivePermissions(userID int) ([]models.EffectiveAbility, error) {
+	var user *user_models.User
+	if err := user_models.ValidScope(s.db.DB()).First(&user, userID).Error; err != nil {
+		s.logger.Error("Cannot Find User", zap.Error(err))
+		return nil, err
+	}
+
+	return resolveEffectivePermissions(s.db.DB(), user.ID, user.RoleID, time.Now())
+}
+
+// changeRole applies change on role and bumps role version, so cached abilities of role users are dropped
+func (s *RoleService) changeRole(ctx context.Context, roleID uint, change func(tx *gorm.DB) error) error {
+	err := s.guardLastAdmin(ctx, func(tx *gorm.DB) error {
+		if err := change(tx); err != nil {
+			return err
+		}
+		return models.BumpVersion(tx, roleID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.InvalidateRole(roleID)
+	return nil
+}
+
+// guardLastAdmin rollbacks change if it leaves system without any admin user
+func (s *RoleService) guardLastAdmin(ctx context.Context, change func(tx *gorm.DB) error) error {
+	return s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return GuardLastAdmin(tx, change)
+	})
+}
+
+// GuardLastAdmin applies change within tx and fails with ErrLastAdmin if it leaves system without
+// any admin user. User updates, removals and terminations go through it as well as role changes
+func GuardLastAdmin(tx *gorm.DB, change func(tx *gorm.DB) error) error {
+	adminsBefore, err := countAdminUsers(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	adminsAfter, err := countAdminUsers(tx)
+	if err != nil {
+		return err
+	}
+	if adminsBefore > 0 && adminsAfter == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// countAdminUsers counts users having admin ability through primary role, active additional role
+// or grant, and not having it denied. Users on notice count as gone already, so the last admin
+// cannot be terminated by a date in the future either
+func countAdminUsers(tx *gorm.DB) (int64, error) {
+	var primaryAdminIDs, roleAdminIDs, grantedAdminIDs, deniedAdminIDs []uint
+	// not using ValidScope since status column is ambiguous after joining role
+	err := tx.Model(&user_models.User{}).
+		Where("user.status != ? AND user.lifecycle_state NOT IN ?", "removed", leavingLifecycleStates).
+		Joins("JOIN role ON role.id = user.role_id AND role.status != ?", "removed").
+		Joins("JOIN role_abilities ON role_abilities.role_id = role.id").
+		Joins("JOIN ability ON ability.id = role_abilities.ability_id").
+		Where("ability.name = ?", constants.ABILITY_ADMIN).
+		Distinct().
+		Pluck("user.id", &primaryAdminIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = models.ActiveUserRoleScope(tx, time.Now()).
+		Joins("JOIN user ON user.id = user_role.user_id AND user.status != ? AND user.lifecycle_state NOT IN ?", "removed", leavingLifecycleStates).
+		Joins("JOIN role ON role.id = user_role.role_id AND role.status != ?", "removed").
+		Joins("JOIN role_abilities ON role_abilities.role_id = role.id").
+		Joins("JOIN ability ON ability.id = role_abilities.ability_id").
+		Where("ability.name = ?", constants.ABILITY_ADMIN).
+		Distinct().
+		Pluck("user_role.user_id", &roleAdminIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	userAdminAbility := func(effect string, userIDs *[]uint) error {
+		return tx.Model(&models.UserAbility{}).
+			Joins("JOIN user ON user.id = user_ability.user_id AND user.status != ? AND user.lifecycle_state NOT IN ?", "removed", leavingLifecycleStates).
+			Joins("JOIN ability ON ability.id = user_ability.ability_id").
+			Where("ability.name = ? AND user_ability.effect = ?", constants.ABILITY_ADMIN, effect).
+			Pluck("user_ability.user_id", userIDs).Error
+	}
+	if err := userAdminAbility(constants.ABILITY_EFFECT_GRANT, &grantedAdminIDs); err != nil {
+		return 0, err
+	}
+	if err := userAdminAbility(constants.ABILITY_EFFECT_DENY, &deniedAdminIDs); err != nil {
+		return 0, err
+	}
+
+	admins := map[uint]bool{}
+	for _, ids := range [][]uint{primaryAdminIDs, roleAdminIDs, grantedAdminIDs} {
+		for _, id := range ids {
+			admins[id] = true
+		}
+	}
+	for _, id := range deniedAdminIDs {
+		delete(admins, id)
+	}
+	return int64(len(admins)), nil
+}
+
+func findRoleAndAbility(tx *gorm.DB, roleID int, abilityID int) (*models.Role, *models.Ability, error) {
+	var role *models.Role
+	if err := models.ValidScope(tx).First(&role, roleID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var ability *models.Ability
+	if err := tx.First(&ability, abilityID).Error; err != nil {
+		return nil, nil, ErrAbilityNotFound
+	}
+	return role, ability, nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/auth/dtos"
+	auth_models "hr-system-go/internal/auth/models"
+	user_constants "hr-system-go/internal/user/constants"
+	user_models "hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("RoleService", func() {
+	var roleService RoleServiceInterface
+
+	BeforeEach(func() {
+		roleService = NewRoleService(mockLogger, mockDB, mockCache)
+	})
+
+	Describe("CreateRole", func() {
+		It("should create role with given abilities", func() {
+			ability := &auth_models.Ability{Name: faker.Word()}
+			mockDB.DB().Create(ability)
+
+			role, err := roleService.CreateRole(context.Background(), dtos.CreateRoleRequest{Name: "Interim HR", AbilityIDs: []uint{ability.ID}})
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(role.ID).NotTo(BeZero())
+			Expect(role.GetAbilityNames()).To(ConsistOf(ability.Name))
+		})
+
+		It("should reject unknown ability", func() {
+			_, err := roleService.CreateRole(context.Background(), dtos.CreateRoleRequest{Name: "Unknown", AbilityIDs: []uint{999999}})
+
+			Expect(err).To(MatchError(ErrAbilityNotFound))
+		})
+	})
+
+	Describe("UpdateRoleByID", func() {
+		It("should reject removed status, roles are removed by delete", func() {
+			role := &auth_models.Role{Name: "Status Role"}
+			mockDB.DB().Create(role)
+			status := "removed"
+
+			_, err := roleService.UpdateRoleByID(context.Background(), int(role.ID), dtos.UpdateRoleRequest{Status: &status})
+
+			Expect(err).To(MatchError(ErrInvalidRoleStatus))
+			found, err := roleService.FindRoleByID(int(role.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(found.Status).NotTo(Equal(status))
+		})
+	})
+
+	Describe("AddAbilityToRole", func() {
+		It("should add ability and bump role version for permission cache", func() {
+			role := &auth_models.Role{Name: "Cache Role"}
+			mockDB.DB().Create(role)
+			ability := &auth_models.Ability{Name: faker.Word()}
+			mockDB.DB().Create(ability)
+			user := &user_models.User{Email: faker.Email(), RoleID: &role.ID}
+			mockDB.DB().Create(user)
+			cached, _ := mockCache.UserAbilities(user)
+			Expect(cached).To(BeEmpty())
+
+			updatedRole, err := roleService.AddAbilityToRole(context.Background(), int(role.ID), int(ability.ID))
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(updatedRole.GetAbilityNames()).To(ContainElement(ability.Name))
+			user.Role = nil
+			cached, _ = mockCache.UserAbilities(user)
+			Expect(cached).To(ContainElement(ability.Name))
+		})
+	})
+
+	Describe("last admin protection", func() {
+		var adminRole *auth_models.Role
+		var adminUser *user_models.User
+
+		BeforeEach(func() {
+			mockDB.DB().Exec("UPDATE user SET role_id = NULL")
+			mockDB.DB().Exec("DELETE FROM user_role")
+			mockDB.DB().Exec("DELETE FROM user_ability")
+			var adminAbility auth_models.Ability
+			mockDB.DB().Where("name = ?", constants.ABILITY_ADMIN).FirstOrCreate(&adminAbility, auth_models.Ability{Name: constants.ABILITY_ADMIN})
+			adminRole = &auth_models.Role{Name: "Only Admin", Abilities: []auth_models.Ability{adminAbility}}
+			mockDB.DB().Create(adminRole)
+			adminUser = &user_models.User{Email: faker.Email(), RoleID: &adminRole.ID}
+			mockDB.DB().Create(adminUser)
+		})
+
+		It("should not delete role of the last admin", func() {
+			err := roleService.DeleteRoleByID(context.Background(), int(adminRole.ID))
+
+			Expect(err).To(MatchError(ErrLastAdmin))
+			var role auth_models.Role
+			mockDB.DB().First(&role, adminRole.ID)
+			Expect(role.Status).To(Equal("active"))
+		})
+
+		It("should not unassign role of the last admin", func() {
+			err := roleService.AssignRoleToUser(context.Background(), int(adminUser.ID), nil)
+
+			Expect(err).To(MatchError(ErrLastAdmin))
+		})
+
+		It("should not deny admin ability of the last admin", func() {
+			_, err := roleService.SetUserAbility(context.Background(), int(adminUser.ID), int(adminRole.Abilities[0].ID), dtos.SetUserAbilityRequest{Effect: constants.ABILITY_EFFECT_DENY})
+
+			Expect(err).To(MatchError(ErrLastAdmin))
+		})
+
+		It("should count admin from additional role", func() {
+			anotherAdmin := &user_models.User{Email: faker.Email()}
+			mockDB.DB().Create(anotherAdmin)
+			_, err := roleService.AddRoleToUser(context.Background(), int(anotherAdmin.ID), int(adminRole.ID), dtos.AddUserRoleRequest{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = roleService.AssignRoleToUser(context.Background(), int(adminUser.ID), nil)
+
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should not count admin who is terminated or on notice", func() {
+			for _, state := range []string{user_constants.LIFECYCLE_STATE_TERMINATED, user_constants.LIFECYCLE_STATE_ON_NOTICE} {
+				leavingAdmin := &user_models.User{Email: faker.Email(), RoleID: &adminRole.ID, LifecycleState: state}
+				mockDB.DB().Create(leavingAdmin)
+			}
+
+			err := roleService.AssignRoleToUser(context.Background(), int(adminUser.ID), nil)
+
+			Expect(err).To(MatchError(ErrLastAdmin))
+		})
+
+		It("should guard changes made outside of role service", func() {
+			err := mockDB.DB().Transaction(func(tx *gorm.DB) error {
+				return GuardLastAdmin(tx, func(tx *gorm.DB) error {
+					return tx.Model(&user_models.User{}).Where("id = ?", adminUser.ID).Update("status", "removed").Error
+				})
+			})
+
+			Expect(err).To(MatchError(ErrLastAdmin))
+			var user user_models.User
+			mockDB.DB().First(&user, adminUser.ID)
+			Expect(user.Status).NotTo(Equal("removed"))
+		})
+
+		It("should allow unassign when another admin exists", func() {
+			anotherAdmin := &user_models.User{Email: faker.Email(), RoleID: &adminRole.ID}
+			mockDB.DB().Create(anotherAdmin)
+
+			err := roleService.AssignRoleToUser(context.Background(), int(adminUser.ID), nil)
+
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Describe("AddRoleToUser", func() {
+		It("should reject validity window ending before it starts", func() {
+			from := time.Now()
+			until := from.Add(-time.Hour)
+
+			_, err := roleService.AddRoleToUser(context.Background(), 1, 1, dtos.AddUserRoleRequest{ValidFrom: &from, ValidUntil: &until})
+
+			Expect(err).To(MatchError(ErrInvalidValidity))
+		})
+
+		It("should replace validity window of already added role", func() {
+			role := &auth_models.Role{Name: "Acting Manager"}
+			mockDB.DB().Create(role)
+			user := &user_models.User{Email: faker.Email()}
+			mockDB.DB().Create(user)
+			until := time.Now().Add(24 * time.Hour)
+
+			roleService.AddRoleToUser(context.Background(), int(user.ID), int(role.ID), dtos.AddUserRoleRequest{ValidUntil: &until})
+			_, err := roleService.AddRoleToUser(context.Background(), int(user.ID), int(role.ID), dtos.AddUserRoleRequest{})
+
+			Expect(err).ShouldNot(HaveOccurred())
+			userRoles, _ := roleService.FindUserRoles(int(user.ID))
+			Expect(userRoles).To(HaveLen(1))
+			Expect(userRoles[0].ValidUntil).To(BeNil())
+		})
+	})
+
+	Describe("FindEffectivePermissions", func() {
+		It("should explain where each ability comes from", func() {
+			shared := auth_models.Ability{Name: "shared_ability"}
+			denied := auth_models.Ability{Name: "denied_ability"}
+			primary := &auth_models.Role{Name: "RD", Abilities: []auth_models.Ability{shared, denied}}
+			mockDB.DB().Create(primary)
+			interimHR := &auth_models.Role{Name: "Interim HR", Abilities: []auth_models.Ability{primary.Abilities[0]}}
+			mockDB.DB().Create(interimHR)
+			user := &user_models.User{Email: faker.Email(), RoleID: &primary.ID}
+			mockDB.DB().Create(user)
+			roleService.AddRoleToUser(context.Background(), int(user.ID), int(interimHR.ID), dtos.AddUserRoleRequest{})
+			roleService.SetUserAbility(context.Background(), int(user.ID), int(primary.Abilities[1].ID), dtos.SetUserAbilityRequest{Effect: constants.ABILITY_EFFECT_DENY})
+
+			abilities, err := roleService.FindEffectivePermissions(int(user.ID))
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(abilities).To(HaveLen(2))
+			Expect(abilities[0].Name).To(Equal("denied_ability"))
+			Expect(abilities[0].Granted).To(BeFalse())
+			Expect(abilities[1].Name).To(Equal("shared_ability"))
+			Expect(abilities[1].Granted).To(BeTrue())
+			Expect(abilities[1].Sources).To(HaveLen(2))
+			Expect(abilities[1].Sources[0].Type).To(Equal(constants.PERMISSION_SOURCE_PRIMARY_ROLE))
+			Expect(*abilities[1].Sources[1].RoleName).To(Equal("Interim HR"))
+		})
+	})
+})
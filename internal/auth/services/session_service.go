@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/auth/models"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SessionServiceInterface interface {
+	FindSessions(userID int) ([]models.Session, error)
+	TerminateSession(ctx context.Context, userID int, sessionID int, terminatedByID uint) error
+	TerminateSessions(ctx context.Context, userID int, terminatedByID uint) (int64, error)
+}
+
+type SessionService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+}
+
+func NewSessionService(logger *logger.Logger, db *mysql.MySqlStore) SessionServiceInterface {
+	return &SessionService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// FindSessions lists active sessions of user, most recently used first
+func (s *SessionService) FindSessions(userID int) ([]models.Session, error) {
+	var sessions []models.Session
+	err := models.ActiveSessionScope(s.db.DB()).
+		Where("user_id = ?", userID).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		s.logger.Error("Cannot Find Sessions", zap.Error(err))
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// TerminateSession signs out one device, its token is rejected from the next request
+func (s *SessionService) TerminateSession(ctx context.Context, userID int, sessionID int, terminatedByID uint) error {
+	result := models.ActiveSessionScope(s.db.DB().WithContext(ctx)).
+		Where("id = ? AND user_id = ?", sessionID, userID).
+		Updates(s.terminateUpdates(terminatedByID))
+	if result.Error != nil {
+		s.logger.Error("Cannot Terminate Session", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TerminateSessions signs user out everywhere and returns how many sessions ended
+func (s *SessionService) TerminateSessions(ctx context.Context, userID int, terminatedByID uint) (int64, error) {
+	result := models.ActiveSessionScope(s.db.DB().WithContext(ctx)).
+		Where("user_id = ?", userID).
+		Updates(s.terminateUpdates(terminatedByID))
+	if result.Error != nil {
+		s.logger.Error("Cannot Terminate Sessions", zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (s *SessionService) terminateUpdates(terminatedByID uint) map[string]interface{} {
+	return map[string]interface{}{"terminated_at": time.Now(), "terminated_by_id": terminatedByID}
+}
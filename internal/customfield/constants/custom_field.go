@@ -0,0 +1,31 @@
+package constants
+
+// entities custom fields are added to
+const (
+	CUSTOM_FIELD_ENTITY_USER       = "user"
+	CUSTOM_FIELD_ENTITY_DEPARTMENT = "department"
+)
+
+var CUSTOM_FIELD_ENTITIES = []string{CUSTOM_FIELD_ENTITY_USER, CUSTOM_FIELD_ENTITY_DEPARTMENT}
+
+const (
+	CUSTOM_FIELD_TYPE_TEXT    = "text"
+	CUSTOM_FIELD_TYPE_NUMBER  = "number"
+	CUSTOM_FIELD_TYPE_DATE    = "date"
+	CUSTOM_FIELD_TYPE_BOOLEAN = "boolean"
+	// CUSTOM_FIELD_TYPE_SELECT takes one of the options of the field
+	CUSTOM_FIELD_TYPE_SELECT = "select"
+)
+
+var CUSTOM_FIELD_TYPES = []string{CUSTOM_FIELD_TYPE_TEXT, CUSTOM_FIELD_TYPE_NUMBER, CUSTOM_FIELD_TYPE_DATE, CUSTOM_FIELD_TYPE_BOOLEAN, CUSTOM_FIELD_TYPE_SELECT}
+
+const (
+	// CUSTOM_FIELD_FILTER_PREFIX starts list filters on custom fields, like custom.costCenter=A1,B2.
+	// Number and date fields take ranges with From and To after the key, like custom.badgeNumberFrom=100
+	CUSTOM_FIELD_FILTER_PREFIX  = "custom."
+	CUSTOM_FIELD_FILTER_FROM    = "From"
+	CUSTOM_FIELD_FILTER_TO      = "To"
+	CUSTOM_FIELD_MAX_LABEL      = 100
+	CUSTOM_FIELD_MAX_TEXT_VALUE = 255
+	CUSTOM_FIELD_MAX_OPTIONS    = 100
+)
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	"hr-system-go/internal/customfield/dtos"
+	"hr-system-go/internal/customfield/services"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CustomFieldController lets admins define custom fields of users and departments, values are saved with the records
+type CustomFieldController struct {
+	logger      *logger.Logger
+	service     services.CustomFieldServiceInterface
+	authService auth_service.AuthServiceInterface
+}
+
+func NewCustomFieldController(logger *logger.Logger, service services.CustomFieldServiceInterface, authService auth_service.AuthServiceInterface) *CustomFieldController {
+	return &CustomFieldController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+func (c *CustomFieldController) RegisterRoutes(r *gin.Engine) {
+	customFieldRoutes := r.Group("/api/custom-fields")
+	{
+		customFieldRoutes.GET("", c.authService.AuthTokenWrapper(c.ListFields))
+		customFieldRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.CreateField, constants.ABILITY_ADMIN))
+		customFieldRoutes.PUT("/:fieldId", c.authService.AuthUserAbilityWrapper(c.UpdateField, constants.ABILITY_ADMIN))
+		customFieldRoutes.DELETE("/:fieldId", c.authService.AuthUserAbilityWrapper(c.DeleteField, constants.ABILITY_ADMIN))
+	}
+}
+
+// ListFields returns fields viewer can see of everyone, ?entity=user or department narrows them down
+func (c *CustomFieldController) ListFields(ctx *gin.Context) {
+	entity := ctx.Query("entity")
+	if entity != "" && !slices.Contains(customfield_constants.CUSTOM_FIELD_ENTITIES, entity) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrCustomFieldEntity.Error()})
+		return
+	}
+
+	fields, err := c.service.FindFields(entity)
+	if err != nil {
+		c.logger.Error("Cannot not find custom fields", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Find Custom Fields"})
+		return
+	}
+
+	access := dtos.ValueAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	ctx.JSON(http.StatusOK, dtos.NewCustomFieldListResponse(fields, access))
+}
+
+func (c *CustomFieldController) CreateField(ctx *gin.Context) {
+	errorMsg := "Failed to Create Custom Field"
+	var payload dtos.CreateCustomFieldRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse custom field payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	field, err := c.service.CreateField(ctx, payload)
+	if err != nil {
+		c.fieldError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewCustomFieldResponse(field))
+}
+
+func (c *CustomFieldController) UpdateField(ctx *gin.Context) {
+	errorMsg := "Failed to Update Custom Field"
+	fieldID, err := strconv.Atoi(ctx.Param("fieldId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Custom Field ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.UpdateCustomFieldRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse custom field payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	field, err := c.service.UpdateField(ctx, fieldID, payload)
+	if err != nil {
+		c.fieldError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewCustomFieldResponse(field))
+}
+
+// DeleteField removes values of the field as well
+func (c *CustomFieldController) DeleteField(ctx *gin.Context) {
+	errorMsg := "Failed to Delete Custom Field"
+	fieldID, err := strconv.Atoi(ctx.Param("fieldId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Custom Field ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	if err := c.service.DeleteField(ctx, fieldID); err != nil {
+		c.fieldError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Custom field deleted"})
+}
+
+func (c *CustomFieldController) fieldError(ctx *gin.Context, err error, errorMsg string) {
+	c.logger.Error("Cannot not save custom field", zap.Error(err))
+	switch {
+	case errors.Is(err, services.ErrCustomFieldEntity), errors.Is(err, services.ErrCustomFieldType),
+		errors.Is(err, services.ErrCustomFieldKey), errors.Is(err, services.ErrCustomFieldLabel),
+		errors.Is(err, services.ErrCustomFieldOptions), errors.Is(err, services.ErrCustomFieldPattern),
+		errors.Is(err, services.ErrCustomFieldRange), errors.Is(err, services.ErrCustomFieldAbility):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCustomFieldKeyTaken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+	}
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	auth_constants "hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/customfield/constants"
+	"hr-system-go/internal/customfield/dtos"
+	"hr-system-go/internal/customfield/models"
+	"hr-system-go/internal/customfield/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCustomFieldController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CustomField Controller Suite")
+}
+
+var (
+	mockCustomField *mock_services.MockCustomFieldService
+	mockAuthService *mock_services.MockAuthService
+	router          *gin.Engine
+)
+
+var _ = Describe("CustomFieldController", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockLogger := logger.NewLogger(env.NewEnv())
+		mockCustomField = &mock_services.MockCustomFieldService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		router = gin.Default()
+		NewCustomFieldController(mockLogger, mockCustomField, mockAuthService).RegisterRoutes(router)
+	})
+
+	Describe("ListFields", func() {
+		It("should leave out fields viewer cannot see", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockCustomField.On("FindFields", constants.CUSTOM_FIELD_ENTITY_USER).Return([]models.CustomField{
+				{Entity: constants.CUSTOM_FIELD_ENTITY_USER, Key: "tShirtSize", Type: constants.CUSTOM_FIELD_TYPE_SELECT},
+				{Entity: constants.CUSTOM_FIELD_ENTITY_USER, Key: "badgeNumber", Type: constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: auth_constants.ABILITY_READ_PERSONAL_DATA},
+			}, nil)
+
+			req, _ := http.NewRequest("GET", "/api/custom-fields?entity=user", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.CustomFieldListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(1))
+			Expect(response.Items[0].Key).To(Equal("tShirtSize"))
+			Expect(response.Items[0].FilterParams).To(Equal([]string{"custom.tShirtSize"}))
+		})
+
+		It("should return bad request for unknown entity", func() {
+			req, _ := http.NewRequest("GET", "/api/custom-fields?entity=leave", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("CreateField", func() {
+		It("should return conflict for taken key", func() {
+			payload := dtos.CreateCustomFieldRequest{Entity: constants.CUSTOM_FIELD_ENTITY_DEPARTMENT, Key: "costCenter", Type: constants.CUSTOM_FIELD_TYPE_TEXT}
+			payload.Label = "Cost center"
+			mockCustomField.On("CreateField", payload).Return(nil, services.ErrCustomFieldKeyTaken)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/custom-fields", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("should return bad request for select field without options", func() {
+			mockCustomField.On("CreateField", mock.Anything).Return(nil, services.ErrCustomFieldOptions)
+
+			req, _ := http.NewRequest("POST", "/api/custom-fields", bytes.NewBufferString(`{"entity":"user","key":"tShirtSize","type":"select","label":"T-shirt size"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
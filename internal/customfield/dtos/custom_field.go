@@ -0,0 +1,154 @@
+package dtos
+
+import (
+	auth_constants "hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/customfield/constants"
+	"hr-system-go/internal/customfield/models"
+	"hr-system-go/utils"
+	"strings"
+)
+
+type CustomFieldResponse struct {
+	Id       uint
+	Entity   string
+	Key      string
+	Label    string
+	Type     string
+	Required bool
+	Options  []string
+	Pattern  string
+	Min      *float64
+	Max      *float64
+	Ability  string
+	Position int
+	// FilterParams are query parameters of the list endpoint of entity
+	FilterParams []string
+}
+
+type CustomFieldListResponse struct {
+	Items []*CustomFieldResponse
+}
+
+// UpdateCustomFieldRequest replaces definition, values already saved are not checked again
+type UpdateCustomFieldRequest struct {
+	Label    string   `json:"label"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options"`
+	Pattern  string   `json:"pattern"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Ability  string   `json:"ability"`
+	Position int      `json:"position"`
+}
+
+// CreateCustomFieldRequest adds entity, key and type, they stay as created since saved values depend on them
+type CreateCustomFieldRequest struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	Type   string `json:"type"`
+	UpdateCustomFieldRequest
+}
+
+// ValueAccess decides which custom fields viewer sees and changes, Owner sees every field of own record
+type ValueAccess struct {
+	Abilities []string
+	Owner     bool
+}
+
+func (a ValueAccess) CanRead(field *models.CustomField) bool {
+	return a.Owner || a.hasAbility(field.Ability)
+}
+
+func (a ValueAccess) CanWrite(field *models.CustomField) bool {
+	return a.hasAbility(field.Ability)
+}
+
+// ForbiddenFilters lists filters on fields viewer cannot see of everyone, results would reveal the values
+func (a ValueAccess) ForbiddenFilters(fields []models.CustomField, pagination utils.Pagination) []string {
+	forbidden := []string{}
+	for i := range fields {
+		if a.hasAbility(fields[i].Ability) {
+			continue
+		}
+		for _, param := range fields[i].FilterParams() {
+			if pagination.HasFilter(param) {
+				forbidden = append(forbidden, param)
+			}
+		}
+	}
+	return forbidden
+}
+
+// ForbiddenValues lists keys set in payload viewer cannot change, unknown keys are refused on saving
+func (a ValueAccess) ForbiddenValues(fields []models.CustomField, values map[string]interface{}) []string {
+	forbidden := []string{}
+	for i := range fields {
+		if _, ok := values[fields[i].Key]; ok && !a.CanWrite(&fields[i]) {
+			forbidden = append(forbidden, fields[i].Key)
+		}
+	}
+	return forbidden
+}
+
+func (a ValueAccess) hasAbility(requiredAbility string) bool {
+	if requiredAbility == "" {
+		return true
+	}
+	for _, ability := range a.Abilities {
+		if ability == requiredAbility || ability == auth_constants.ABILITY_ADMIN {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFilters tells whether query filters on any custom field, definitions are looked up only then
+func HasFilters(pagination utils.Pagination) bool {
+	for param := range pagination.Filters {
+		if strings.HasPrefix(param, constants.CUSTOM_FIELD_FILTER_PREFIX) && pagination.HasFilter(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCustomFieldListResponse leaves out fields viewer cannot see of others
+func NewCustomFieldListResponse(fields []models.CustomField, access ValueAccess) *CustomFieldListResponse {
+	items := []*CustomFieldResponse{}
+	for i := range fields {
+		if access.hasAbility(fields[i].Ability) {
+			items = append(items, NewCustomFieldResponse(&fields[i]))
+		}
+	}
+	return &CustomFieldListResponse{Items: items}
+}
+
+func NewCustomFieldResponse(field *models.CustomField) *CustomFieldResponse {
+	return &CustomFieldResponse{
+		Id:           field.ID,
+		Entity:       field.Entity,
+		Key:          field.Key,
+		Label:        field.Label,
+		Type:         field.Type,
+		Required:     field.Required,
+		Options:      field.Options,
+		Pattern:      field.Pattern,
+		Min:          field.Min,
+		Max:          field.Max,
+		Ability:      field.Ability,
+		Position:     field.Position,
+		FilterParams: field.FilterParams(),
+	}
+}
+
+// NewCustomFieldValuesResponse maps key of each field viewer can see to its value, values need their Field preloaded
+func NewCustomFieldValuesResponse(values []models.CustomFieldValue, access ValueAccess) map[string]interface{} {
+	res := map[string]interface{}{}
+	for i := range values {
+		field := values[i].Field
+		if field != nil && access.CanRead(field) {
+			res[field.Key] = values[i].Value(field.Type)
+		}
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"hr-system-go/internal/customfield/constants"
+	"time"
+)
+
+// CustomField is attribute admins add to users or departments without schema changes, like T-shirt size or cost center
+type CustomField struct {
+	base_model.BaseModel
+	Entity   string `gorm:"not null;size:20;uniqueIndex:idx_custom_field_entity_key"`
+	Key      string `gorm:"not null;size:50;uniqueIndex:idx_custom_field_entity_key"`
+	Label    string `gorm:"not null;size:100"`
+	Type     string `gorm:"not null;size:20"`
+	Required bool   `gorm:"not null;default:false"`
+	// Options are values select field accepts
+	Options []string `gorm:"serializer:json;type:text"`
+	// Pattern is regular expression text values have to match
+	Pattern string
+	// Min and Max bound number values
+	Min *float64
+	Max *float64
+	// Ability is needed to see, filter and change values of other records, empty leaves field open to everyone
+	Ability  string
+	Position int `gorm:"not null;default:0"`
+}
+
+// CustomFieldValue is value of one field for one user or department, typed columns keep filters on numbers and dates right
+type CustomFieldValue struct {
+	base_model.BaseModel
+	FieldID    uint   `gorm:"not null;uniqueIndex:idx_custom_field_value_entity"`
+	EntityType string `gorm:"not null;size:20;uniqueIndex:idx_custom_field_value_entity"`
+	EntityID   uint   `gorm:"not null;uniqueIndex:idx_custom_field_value_entity;index"`
+	// TextValue keeps text, select and boolean values
+	TextValue   string     `gorm:"size:255;index"`
+	NumberValue *float64   `gorm:"index"`
+	DateValue   *time.Time `gorm:"type:date;default:null;index"`
+	// Relations
+	Field *CustomField `gorm:"foreignKey:FieldID"`
+}
+
+// ValueColumn is column of CustomFieldValue keeping values of the field
+func (f *CustomField) ValueColumn() string {
+	switch f.Type {
+	case constants.CUSTOM_FIELD_TYPE_NUMBER:
+		return "number_value"
+	case constants.CUSTOM_FIELD_TYPE_DATE:
+		return "date_value"
+	default:
+		return "text_value"
+	}
+}
+
+// FilterParams are query parameters of list endpoints filtering on the field
+func (f *CustomField) FilterParams() []string {
+	param := constants.CUSTOM_FIELD_FILTER_PREFIX + f.Key
+	if f.Type == constants.CUSTOM_FIELD_TYPE_NUMBER || f.Type == constants.CUSTOM_FIELD_TYPE_DATE {
+		return []string{param, param + constants.CUSTOM_FIELD_FILTER_FROM, param + constants.CUSTOM_FIELD_FILTER_TO}
+	}
+	return []string{param}
+}
+
+// Value is typed value for responses, dates like 2026-01-31
+func (v *CustomFieldValue) Value(fieldType string) interface{} {
+	switch fieldType {
+	case constants.CUSTOM_FIELD_TYPE_NUMBER:
+		if v.NumberValue == nil {
+			return nil
+		}
+		return *v.NumberValue
+	case constants.CUSTOM_FIELD_TYPE_DATE:
+		if v.DateValue == nil {
+			return nil
+		}
+		return v.DateValue.Format(time.DateOnly)
+	case constants.CUSTOM_FIELD_TYPE_BOOLEAN:
+		return v.TextValue == "true"
+	default:
+		return v.TextValue
+	}
+}
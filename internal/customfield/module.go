@@ -0,0 +1,35 @@
+package customfield
+
+import (
+	"hr-system-go/app"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/customfield/controllers"
+	"hr-system-go/internal/customfield/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomFieldModule struct {
+	app.AppModuleInterface
+}
+
+func (m *CustomFieldModule) Controllers() []interface{} {
+	return []interface{}{
+		controllers.NewCustomFieldController,
+		func(
+			r *gin.Engine,
+			c *controllers.CustomFieldController,
+			logger *logger.Logger,
+		) *CustomFieldModule {
+			c.RegisterRoutes(r)
+			logger.Info("= Custom field module init")
+			return m
+		},
+	}
+}
+
+func (m *CustomFieldModule) Provide() []interface{} {
+	return []interface{}{
+		services.NewCustomFieldService,
+	}
+}
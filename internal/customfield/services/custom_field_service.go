@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	auth_models "hr-system-go/internal/auth/models"
+	"hr-system-go/internal/customfield/constants"
+	"hr-system-go/internal/customfield/dtos"
+	"hr-system-go/internal/customfield/models"
+	"hr-system-go/utils"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCustomFieldEntity   = fmt.Errorf("entity must be one of %s", strings.Join(constants.CUSTOM_FIELD_ENTITIES, ", "))
+	ErrCustomFieldType     = fmt.Errorf("type must be one of %s", strings.Join(constants.CUSTOM_FIELD_TYPES, ", "))
+	ErrCustomFieldKey      = errors.New("key must start with a letter and have at most 50 letters and digits")
+	ErrCustomFieldKeyTaken = errors.New("custom field with this key already exists")
+	ErrCustomFieldLabel    = errors.New("label is required, at most 100 characters")
+	ErrCustomFieldOptions  = fmt.Errorf("select field needs 1 to %d distinct options", constants.CUSTOM_FIELD_MAX_OPTIONS)
+	ErrCustomFieldPattern  = errors.New("pattern is not a valid regular expression")
+	ErrCustomFieldRange    = errors.New("min cannot be greater than max")
+	ErrCustomFieldAbility  = errors.New("ability does not exist")
+	ErrCustomFieldValue    = errors.New("custom field value is invalid")
+)
+
+// CustomFieldError names custom field whose value was refused
+type CustomFieldError struct {
+	Key    string
+	Reason string
+}
+
+func (e *CustomFieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Key, e.Reason)
+}
+
+func (e *CustomFieldError) Unwrap() error {
+	return ErrCustomFieldValue
+}
+
+// customFieldKeyPattern keeps keys usable as json keys and in query parameters
+var customFieldKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]{0,49}$`)
+
+type CustomFieldServiceInterface interface {
+	FindFields(entity string) ([]models.CustomField, error)
+	CreateField(ctx context.Context, payload dtos.CreateCustomFieldRequest) (*models.CustomField, error)
+	UpdateField(ctx context.Context, fieldID int, payload dtos.UpdateCustomFieldRequest) (*models.CustomField, error)
+	DeleteField(ctx context.Context, fieldID int) error
+}
+
+type CustomFieldService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+}
+
+func NewCustomFieldService(logger *logger.Logger, db *mysql.MySqlStore) CustomFieldServiceInterface {
+	return &CustomFieldService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// FindFields returns fields of entity in form order, empty entity returns fields of every entity
+func (s *CustomFieldService) FindFields(entity string) ([]models.CustomField, error) {
+	fields, err := findFields(s.db.DB(), entity)
+	if err != nil {
+		s.logger.Error("Cannot Find Custom Fields", zap.Error(err))
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (s *CustomFieldService) CreateField(ctx context.Context, payload dtos.CreateCustomFieldRequest) (*models.CustomField, error) {
+	field := &models.CustomField{
+		Entity: payload.Entity,
+		Key:    strings.TrimSpace(payload.Key),
+		Type:   payload.Type,
+	}
+	if !slices.Contains(constants.CUSTOM_FIELD_ENTITIES, field.Entity) {
+		return nil, ErrCustomFieldEntity
+	}
+	if !slices.Contains(constants.CUSTOM_FIELD_TYPES, field.Type) {
+		return nil, ErrCustomFieldType
+	}
+	if !customFieldKeyPattern.MatchString(field.Key) {
+		return nil, ErrCustomFieldKey
+	}
+
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&models.CustomField{}).Where("entity = ? AND `key` = ?", field.Entity, field.Key).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrCustomFieldKeyTaken
+		}
+		if err := applyCustomField(tx, field, payload.UpdateCustomFieldRequest); err != nil {
+			return err
+		}
+		return tx.Create(&field).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Create Custom Field", zap.Error(err))
+		return nil, err
+	}
+	return field, nil
+}
+
+func (s *CustomFieldService) UpdateField(ctx context.Context, fieldID int, payload dtos.UpdateCustomFieldRequest) (*models.CustomField, error) {
+	var field *models.CustomField
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&field, fieldID).Error; err != nil {
+			return err
+		}
+		if err := applyCustomField(tx, field, payload); err != nil {
+			return err
+		}
+		return tx.Save(&field).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Update Custom Field", zap.Error(err))
+		return nil, err
+	}
+	return field, nil
+}
+
+// DeleteField removes field along with its values
+func (s *CustomFieldService) DeleteField(ctx context.Context, fieldID int) error {
+	return s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var field *models.CustomField
+		if err := tx.First(&field, fieldID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("field_id = ?", field.ID).Delete(&models.CustomFieldValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&field).Error
+	})
+}
+
+// CustomFieldFilters are list filters on every field of entity, see CUSTOM_FIELD_FILTER_PREFIX.
+// Definitions are looked up only when pagination filters on custom fields
+func CustomFieldFilters(db *gorm.DB, entity string, pagination utils.Pagination) ([]utils.Filter, error) {
+	if !dtos.HasFilters(pagination) {
+		return nil, nil
+	}
+	fields, err := findFields(db.Session(&gorm.Session{NewDB: true}), entity)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []utils.Filter{}
+	for _, field := range fields {
+		params := field.FilterParams()
+		filter := utils.Filter{Param: params[0], Type: utils.FILTER_TYPE_STRING}
+		switch field.Type {
+		case constants.CUSTOM_FIELD_TYPE_NUMBER:
+			filter.Type = utils.FILTER_TYPE_NUMBER
+		case constants.CUSTOM_FIELD_TYPE_DATE:
+			filter.Type = utils.FILTER_TYPE_DATE
+		case constants.CUSTOM_FIELD_TYPE_BOOLEAN:
+			filter.Values = []string{"true", "false"}
+		case constants.CUSTOM_FIELD_TYPE_SELECT:
+			filter.Values = field.Options
+		}
+		filters = append(filters, customFieldFilter(field, filter, "IN ?"))
+		if len(params) == 3 {
+			from, to := filter, filter
+			from.Param, from.Operator = params[1], utils.FILTER_OPERATOR_FROM
+			to.Param, to.Operator = params[2], utils.FILTER_OPERATOR_TO
+			filters = append(filters, customFieldFilter(field, from, ">= ?"), customFieldFilter(field, to, "<= ?"))
+		}
+	}
+	return filters, nil
+}
+
+// customFieldFilter matches records having value of field which meets condition
+func customFieldFilter(field models.CustomField, filter utils.Filter, condition string) utils.Filter {
+	filter.Scope = func(db *gorm.DB, values []interface{}) *gorm.DB {
+		var arg interface{} = values
+		if filter.Operator != "" {
+			arg = values[0]
+		}
+		matching := db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.CustomFieldValue{}).
+			Select("entity_id").
+			Where("field_id = ? AND entity_type = ?", field.ID, field.Entity).
+			Where(field.ValueColumn()+" "+condition, arg)
+		return db.Where("id IN (?)", matching)
+	}
+	return filter
+}
+
+// SaveCustomFieldValues checks values by key against fields of entity and saves them within tx, nil or empty value
+// clears the field. Required fields have to be given on create and cannot be cleared later
+func SaveCustomFieldValues(tx *gorm.DB, entity string, entityID uint, values map[string]interface{}, creating bool) error {
+	if len(values) == 0 && !creating {
+		return nil
+	}
+	fields, err := findFields(tx, entity)
+	if err != nil {
+		return err
+	}
+	parsed, err := parseCustomFieldValues(fields, values, creating)
+	if err != nil {
+		return err
+	}
+
+	for i := range fields {
+		field := &fields[i]
+		value, given := parsed[field.Key]
+		if !given {
+			continue
+		}
+		current := tx.Where("field_id = ? AND entity_type = ? AND entity_id = ?", field.ID, entity, entityID)
+		if value == nil {
+			if err := current.Delete(&models.CustomFieldValue{}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		var saved models.CustomFieldValue
+		if err := current.Session(&gorm.Session{}).FirstOrInit(&saved).Error; err != nil {
+			return err
+		}
+		saved.FieldID, saved.EntityType, saved.EntityID = field.ID, entity, entityID
+		saved.TextValue, saved.NumberValue, saved.DateValue = value.TextValue, value.NumberValue, value.DateValue
+		if err := tx.Omit("Field").Save(&saved).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindCustomFields are fields of entity for checking values before the record exists, see CheckCustomFieldValues
+func FindCustomFields(db *gorm.DB, entity string) ([]models.CustomField, error) {
+	return findFields(db.Session(&gorm.Session{NewDB: true}), entity)
+}
+
+// CheckCustomFieldValues checks values like SaveCustomFieldValues does without saving them
+func CheckCustomFieldValues(fields []models.CustomField, values map[string]interface{}, creating bool) error {
+	_, err := parseCustomFieldValues(fields, values, creating)
+	return err
+}
+
+// CustomFieldValueFromText turns spreadsheet cell into value SaveCustomFieldValues takes, empty cell clears the field
+func CustomFieldValueFromText(field *models.CustomField, text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	switch field.Type {
+	case constants.CUSTOM_FIELD_TYPE_NUMBER:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be a number"}
+		}
+		return number, nil
+	case constants.CUSTOM_FIELD_TYPE_BOOLEAN:
+		boolean, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be true or false"}
+		}
+		return boolean, nil
+	default:
+		return text, nil
+	}
+}
+
+// parseCustomFieldValues checks values by key against fields, nil value of a given key clears the field
+func parseCustomFieldValues(fields []models.CustomField, values map[string]interface{}, creating bool) (map[string]*models.CustomFieldValue, error) {
+	byKey := map[string]*models.CustomField{}
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+	for key := range values {
+		if byKey[key] == nil {
+			return nil, &CustomFieldError{Key: key, Reason: "is not a custom field"}
+		}
+	}
+
+	parsed := map[string]*models.CustomFieldValue{}
+	for i := range fields {
+		field := &fields[i]
+		raw, given := values[field.Key]
+		if !given {
+			if creating && field.Required {
+				return nil, &CustomFieldError{Key: field.Key, Reason: "is required"}
+			}
+			continue
+		}
+		value, err := parseCustomFieldValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil && field.Required {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "is required"}
+		}
+		parsed[field.Key] = value
+	}
+	return parsed, nil
+}
+
+// parseCustomFieldValue validates json value against field, nil means the value is cleared
+func parseCustomFieldValue(field *models.CustomField, raw interface{}) (*models.CustomFieldValue, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if text, ok := raw.(string); ok {
+		raw = strings.TrimSpace(text)
+		if raw == "" {
+			return nil, nil
+		}
+	}
+
+	value := &models.CustomFieldValue{}
+	switch field.Type {
+	case constants.CUSTOM_FIELD_TYPE_NUMBER:
+		number, ok := raw.(float64)
+		if !ok {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be a number"}
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be at least " + strconv.FormatFloat(*field.Min, 'f', -1, 64)}
+		}
+		if field.Max != nil && number > *field.Max {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be at most " + strconv.FormatFloat(*field.Max, 'f', -1, 64)}
+		}
+		value.NumberValue = &number
+	case constants.CUSTOM_FIELD_TYPE_DATE:
+		text, _ := raw.(string)
+		date, err := time.Parse(time.DateOnly, text)
+		if err != nil {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be a date like 2026-01-31"}
+		}
+		value.DateValue = &date
+	case constants.CUSTOM_FIELD_TYPE_BOOLEAN:
+		boolean, ok := raw.(bool)
+		if !ok {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be true or false"}
+		}
+		value.TextValue = strconv.FormatBool(boolean)
+	case constants.CUSTOM_FIELD_TYPE_SELECT:
+		text, _ := raw.(string)
+		if !slices.Contains(field.Options, text) {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must be one of " + strings.Join(field.Options, ", ")}
+		}
+		value.TextValue = text
+	default:
+		text, ok := raw.(string)
+		if !ok || utf8.RuneCountInString(text) > constants.CUSTOM_FIELD_MAX_TEXT_VALUE {
+			return nil, &CustomFieldError{Key: field.Key, Reason: fmt.Sprintf("must be text of at most %d characters", constants.CUSTOM_FIELD_MAX_TEXT_VALUE)}
+		}
+		// pattern is checked on saving the field already
+		if field.Pattern != "" && !regexp.MustCompile(field.Pattern).MatchString(text) {
+			return nil, &CustomFieldError{Key: field.Key, Reason: "must match " + field.Pattern}
+		}
+		value.TextValue = text
+	}
+	return value, nil
+}
+
+func findFields(db *gorm.DB, entity string) ([]models.CustomField, error) {
+	fields := []models.CustomField{}
+	query := db.Model(&models.CustomField{})
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	err := query.Order("entity, position, id").Find(&fields).Error
+	return fields, err
+}
+
+// applyCustomField validates definition, settings which do not belong to type are dropped
+func applyCustomField(tx *gorm.DB, field *models.CustomField, payload dtos.UpdateCustomFieldRequest) error {
+	label := strings.TrimSpace(payload.Label)
+	if label == "" || utf8.RuneCountInString(label) > constants.CUSTOM_FIELD_MAX_LABEL {
+		return ErrCustomFieldLabel
+	}
+
+	field.Options, field.Pattern, field.Min, field.Max = nil, "", nil, nil
+	switch field.Type {
+	case constants.CUSTOM_FIELD_TYPE_SELECT:
+		options := []string{}
+		for _, option := range payload.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || utf8.RuneCountInString(option) > constants.CUSTOM_FIELD_MAX_TEXT_VALUE || slices.Contains(options, option) {
+				return ErrCustomFieldOptions
+			}
+			options = append(options, option)
+		}
+		if len(options) == 0 || len(options) > constants.CUSTOM_FIELD_MAX_OPTIONS {
+			return ErrCustomFieldOptions
+		}
+		field.Options = options
+	case constants.CUSTOM_FIELD_TYPE_TEXT:
+		if _, err := regexp.Compile(payload.Pattern); err != nil {
+			return ErrCustomFieldPattern
+		}
+		field.Pattern = payload.Pattern
+	case constants.CUSTOM_FIELD_TYPE_NUMBER:
+		if payload.Min != nil && payload.Max != nil && *payload.Min > *payload.Max {
+			return ErrCustomFieldRange
+		}
+		field.Min, field.Max = payload.Min, payload.Max
+	}
+
+	ability := strings.TrimSpace(payload.Ability)
+	if ability != "" {
+		var known int64
+		if err := tx.Model(&auth_models.Ability{}).Where("name = ? AND status != ?", ability, "removed").Count(&known).Error; err != nil {
+			return err
+		}
+		if known == 0 {
+			return ErrCustomFieldAbility
+		}
+	}
+
+	field.Label = label
+	field.Required = payload.Required
+	field.Ability = ability
+	field.Position = payload.Position
+	return nil
+}
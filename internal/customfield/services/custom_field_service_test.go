@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	auth_models "hr-system-go/internal/auth/models"
+	"hr-system-go/internal/customfield/constants"
+	"hr-system-go/internal/customfield/dtos"
+	"hr-system-go/internal/customfield/models"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCustomFieldService(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CustomFieldService Suite")
+}
+
+var (
+	customFieldService CustomFieldServiceInterface
+	mockEnv            *env.Env
+	mockLogger         *logger.Logger
+	mockDB             *mysql.MySqlStore
+)
+
+var _ = BeforeSuite(func() {
+	mockEnv = env.NewEnv()
+	mockLogger = logger.NewLogger(mockEnv)
+	mockDB = mysql.NewMySqlStore(mockEnv, mockLogger)
+	customFieldService = NewCustomFieldService(mockLogger, mockDB)
+
+	mockDB.Connect(
+		mockEnv.GetEnv("DB_USER"),
+		mockEnv.GetEnv("DB_PASSWORD"),
+		mockEnv.GetEnv("DB_DATABASE"),
+		mockEnv.GetEnv("DB_HOST"),
+		mockEnv.GetEnv("DB_PORT"),
+		mockEnv.GetEnv("DB_PARAMS"),
+	)
+
+	mockDB.DB().AutoMigrate(&models.CustomField{}, &models.CustomFieldValue{}, &auth_models.Ability{})
+})
+
+var _ = AfterSuite(func() {
+	mockDB.DB().Migrator().DropTable(&models.CustomField{}, &models.CustomFieldValue{}, &auth_models.Ability{})
+	mockDB.Close()
+})
+
+var _ = Describe("CustomFieldService", func() {
+	BeforeEach(func() {
+		_ = mockDB.DB().Exec("truncate table custom_field").Error
+		_ = mockDB.DB().Exec("truncate table custom_field_value").Error
+	})
+
+	newField := func(key string, fieldType string) dtos.CreateCustomFieldRequest {
+		return dtos.CreateCustomFieldRequest{
+			Entity:                   constants.CUSTOM_FIELD_ENTITY_USER,
+			Key:                      key,
+			Type:                     fieldType,
+			UpdateCustomFieldRequest: dtos.UpdateCustomFieldRequest{Label: "Field"},
+		}
+	}
+
+	Describe("CreateField", func() {
+		It("should keep only settings of the type", func() {
+			payload := newField("tShirtSize", constants.CUSTOM_FIELD_TYPE_SELECT)
+			payload.Options = []string{" S ", "M", "L"}
+			payload.Pattern = "^[A-Z]+$"
+
+			field, err := customFieldService.CreateField(context.Background(), payload)
+
+			Expect(err).To(BeNil())
+			Expect(field.Options).To(Equal([]string{"S", "M", "L"}))
+			Expect(field.Pattern).To(BeEmpty())
+		})
+
+		It("should refuse taken key of the same entity only", func() {
+			_, err := customFieldService.CreateField(context.Background(), newField("badgeNumber", constants.CUSTOM_FIELD_TYPE_TEXT))
+			Expect(err).To(BeNil())
+			department := newField("badgeNumber", constants.CUSTOM_FIELD_TYPE_TEXT)
+			department.Entity = constants.CUSTOM_FIELD_ENTITY_DEPARTMENT
+			_, err = customFieldService.CreateField(context.Background(), department)
+			Expect(err).To(BeNil())
+
+			_, err = customFieldService.CreateField(context.Background(), newField("badgeNumber", constants.CUSTOM_FIELD_TYPE_NUMBER))
+
+			Expect(err).To(MatchError(ErrCustomFieldKeyTaken))
+		})
+
+		It("should refuse invalid definitions", func() {
+			invalidPattern := newField("badgeNumber", constants.CUSTOM_FIELD_TYPE_TEXT)
+			invalidPattern.Pattern = "[0-9"
+			invertedRange := newField("plannedHeadcount", constants.CUSTOM_FIELD_TYPE_NUMBER)
+			minimum, maximum := 10.0, 1.0
+			invertedRange.Min, invertedRange.Max = &minimum, &maximum
+			unknownAbility := newField("costCenter", constants.CUSTOM_FIELD_TYPE_TEXT)
+			unknownAbility.Ability = "read_cost_centers"
+
+			for payload, expected := range map[*dtos.CreateCustomFieldRequest]error{
+				&invalidPattern: ErrCustomFieldPattern,
+				&invertedRange:  ErrCustomFieldRange,
+				&unknownAbility: ErrCustomFieldAbility,
+			} {
+				_, err := customFieldService.CreateField(context.Background(), *payload)
+				Expect(err).To(MatchError(expected))
+			}
+			_, err := customFieldService.CreateField(context.Background(), newField("cost-center", constants.CUSTOM_FIELD_TYPE_TEXT))
+			Expect(err).To(MatchError(ErrCustomFieldKey))
+			_, err = customFieldService.CreateField(context.Background(), newField("costCenter", "json"))
+			Expect(err).To(MatchError(ErrCustomFieldType))
+		})
+	})
+
+	Describe("SaveCustomFieldValues", func() {
+		It("should check values against their fields", func() {
+			payload := newField("badgeNumber", constants.CUSTOM_FIELD_TYPE_TEXT)
+			payload.Pattern = `^B[0-9]{4}$`
+			_, err := customFieldService.CreateField(context.Background(), payload)
+			Expect(err).To(BeNil())
+			_, err = customFieldService.CreateField(context.Background(), newField("startedOn", constants.CUSTOM_FIELD_TYPE_DATE))
+			Expect(err).To(BeNil())
+
+			for values, reason := range map[string]string{
+				`{"badgeNumber": "A1234"}`:    "badgeNumber must match ^B[0-9]{4}$",
+				`{"startedOn": "31.01.2026"}`: "startedOn must be a date like 2026-01-31",
+				`{"shoeSize": 44}`:            "shoeSize is not a custom field",
+			} {
+				err := SaveCustomFieldValues(mockDB.DB(), constants.CUSTOM_FIELD_ENTITY_USER, 5, decodeValues(values), false)
+				Expect(err).To(MatchError(ErrCustomFieldValue))
+				Expect(err.Error()).To(Equal(reason))
+			}
+		})
+
+		It("should replace value and clear it with null", func() {
+			field, err := customFieldService.CreateField(context.Background(), newField("remote", constants.CUSTOM_FIELD_TYPE_BOOLEAN))
+			Expect(err).To(BeNil())
+
+			Expect(SaveCustomFieldValues(mockDB.DB(), constants.CUSTOM_FIELD_ENTITY_USER, 5, decodeValues(`{"remote": false}`), false)).To(Succeed())
+			Expect(SaveCustomFieldValues(mockDB.DB(), constants.CUSTOM_FIELD_ENTITY_USER, 5, decodeValues(`{"remote": true}`), false)).To(Succeed())
+			var values []models.CustomFieldValue
+			Expect(mockDB.DB().Where("field_id = ?", field.ID).Find(&values).Error).To(Succeed())
+			Expect(values).To(HaveLen(1))
+			Expect(values[0].Value(field.Type)).To(Equal(true))
+
+			Expect(SaveCustomFieldValues(mockDB.DB(), constants.CUSTOM_FIELD_ENTITY_USER, 5, decodeValues(`{"remote": null}`), false)).To(Succeed())
+			Expect(mockDB.DB().Where("field_id = ?", field.ID).Find(&values).Error).To(Succeed())
+			Expect(values).To(BeEmpty())
+		})
+	})
+
+	Describe("DeleteField", func() {
+		It("should remove values of field", func() {
+			field, err := customFieldService.CreateField(context.Background(), newField("badgeNumber", constants.CUSTOM_FIELD_TYPE_TEXT))
+			Expect(err).To(BeNil())
+			Expect(SaveCustomFieldValues(mockDB.DB(), constants.CUSTOM_FIELD_ENTITY_USER, 5, decodeValues(`{"badgeNumber": "B1234"}`), false)).To(Succeed())
+
+			Expect(customFieldService.DeleteField(context.Background(), int(field.ID))).To(Succeed())
+
+			var count int64
+			Expect(mockDB.DB().Model(&models.CustomFieldValue{}).Where("field_id = ?", field.ID).Count(&count).Error).To(Succeed())
+			Expect(count).To(BeZero())
+		})
+	})
+})
+
+// decodeValues reads values like payload of a request
+func decodeValues(values string) map[string]interface{} {
+	decoded := map[string]interface{}{}
+	Expect(json.Unmarshal([]byte(values), &decoded)).To(Succeed())
+	return decoded
+}
@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/internal/auth/constants"
 	auth_service "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_dtos "hr-system-go/internal/customfield/dtos"
+	customfield_services "hr-system-go/internal/customfield/services"
 	"hr-system-go/internal/department/dtos"
 	"hr-system-go/internal/department/services"
 	"hr-system-go/utils"
@@ -15,16 +19,18 @@ import (
 )
 
 type DepartmentController struct {
-	logger      *logger.Logger
-	service     services.DepartmentServiceInterface
-	authService auth_service.AuthServiceInterface
+	logger             *logger.Logger
+	service            services.DepartmentServiceInterface
+	authService        auth_service.AuthServiceInterface
+	customFieldService customfield_services.CustomFieldServiceInterface
 }
 
-func NewDepartmentController(logger *logger.Logger, service services.DepartmentServiceInterface, authService auth_service.AuthServiceInterface) *DepartmentController {
+func NewDepartmentController(logger *logger.Logger, service services.DepartmentServiceInterface, authService auth_service.AuthServiceInterface, customFieldService customfield_services.CustomFieldServiceInterface) *DepartmentController {
 	return &DepartmentController{
-		logger:      logger,
-		service:     service,
-		authService: authService,
+		logger:             logger,
+		service:            service,
+		authService:        authService,
+		customFieldService: customFieldService,
 	}
 }
 
@@ -41,14 +47,32 @@ func (c *DepartmentController) RegisterRoutes(r *gin.Engine) {
 
 func (c *DepartmentController) listDepartments(ctx *gin.Context) {
 	pagination := utils.NewPagination(ctx)
+	access := c.customFieldAccess(ctx)
+	if customfield_dtos.HasFilters(pagination) {
+		fields, err := c.customFieldService.FindFields(customfield_constants.CUSTOM_FIELD_ENTITY_DEPARTMENT)
+		if err != nil {
+			c.logger.Error("Cannot not find custom fields", zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find Departments Error"})
+			return
+		}
+		if forbidden := access.ForbiddenFilters(fields, pagination); len(forbidden) > 0 {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Find Departments Error", "fields": forbidden})
+			return
+		}
+	}
+
 	departments, totalRows, err := c.service.FindDepartments(&pagination)
+	if utils.IsQueryError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.logger.Error("Failed to Find User", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find Departments Error"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dtos.NewDepartmentListResponse(departments, totalRows, pagination))
+	ctx.JSON(http.StatusOK, dtos.NewDepartmentListResponse(departments, totalRows, pagination, access))
 }
 
 func (c *DepartmentController) GetDepartment(ctx *gin.Context) {
@@ -68,7 +92,7 @@ func (c *DepartmentController) GetDepartment(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dtos.NewDepartmentResponse(department))
+	ctx.JSON(http.StatusOK, dtos.NewDepartmentResponse(department, c.customFieldAccess(ctx)))
 }
 
 func (c *DepartmentController) CreateDepartment(ctx *gin.Context) {
@@ -80,14 +104,21 @@ func (c *DepartmentController) CreateDepartment(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
-	department, err := c.service.CreateDepartment(payload)
+	if !c.authorizeCustomFields(ctx, payload.CustomFields, errorMsg) {
+		return
+	}
+	department, err := c.service.CreateDepartment(ctx, payload)
+	if errors.Is(err, customfield_services.ErrCustomFieldValue) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.logger.Error("Cannot not create user", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dtos.NewDepartmentResponse(department))
+	ctx.JSON(http.StatusOK, dtos.NewDepartmentResponse(department, c.customFieldAccess(ctx)))
 }
 
 func (c *DepartmentController) UpdateDepartment(ctx *gin.Context) {
@@ -106,14 +137,22 @@ func (c *DepartmentController) UpdateDepartment(ctx *gin.Context) {
 		return
 	}
 
-	department, err := c.service.UpdateDepartmentByID(departmentID, payload)
+	if !c.authorizeCustomFields(ctx, payload.CustomFields, errorMsg) {
+		return
+	}
+
+	department, err := c.service.UpdateDepartmentByID(ctx, departmentID, payload)
+	if errors.Is(err, customfield_services.ErrCustomFieldValue) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.logger.Error("Cannot not update user", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dtos.NewDepartmentResponse(department))
+	ctx.JSON(http.StatusOK, dtos.NewDepartmentResponse(department, c.customFieldAccess(ctx)))
 }
 
 func (c *DepartmentController) DeleteDepartment(ctx *gin.Context) {
@@ -126,7 +165,7 @@ func (c *DepartmentController) DeleteDepartment(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.service.DeleteDepartmentByID(departmentID); err != nil {
+	if err := c.service.DeleteDepartmentByID(ctx, departmentID); err != nil {
 		c.logger.Error("Cannot not delete Department", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
@@ -134,3 +173,25 @@ func (c *DepartmentController) DeleteDepartment(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+// authorizeCustomFields responds with error itself unless viewer can change every custom field in values
+func (c *DepartmentController) authorizeCustomFields(ctx *gin.Context, values map[string]interface{}, errorMsg string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	fields, err := c.customFieldService.FindFields(customfield_constants.CUSTOM_FIELD_ENTITY_DEPARTMENT)
+	if err != nil {
+		c.logger.Error("Cannot not find custom fields", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return false
+	}
+	if forbidden := c.customFieldAccess(ctx).ForbiddenValues(fields, values); len(forbidden) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": forbidden})
+		return false
+	}
+	return true
+}
+
+func (c *DepartmentController) customFieldAccess(ctx *gin.Context) customfield_dtos.ValueAccess {
+	return customfield_dtos.ValueAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
+	auth_constants "hr-system-go/internal/auth/constants"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
 	"hr-system-go/internal/department/dtos"
 	"hr-system-go/internal/department/models"
 	mock_services "hr-system-go/mocks/services"
@@ -28,6 +31,7 @@ var (
 	departmentController  *DepartmentController
 	mockDepartmentService *mock_services.MockDepartmentService
 	mockAuthService       *mock_services.MockAuthService
+	mockCustomField       *mock_services.MockCustomFieldService
 	router                *gin.Engine
 	mockEnv               *env.Env
 	mockLogger            *logger.Logger
@@ -40,7 +44,9 @@ var _ = Describe("DepartmentController", func() {
 		mockLogger = logger.NewLogger(mockEnv)
 		mockDepartmentService = &mock_services.MockDepartmentService{}
 		mockAuthService = &mock_services.MockAuthService{}
-		departmentController = NewDepartmentController(mockLogger, mockDepartmentService, mockAuthService)
+		mockCustomField = &mock_services.MockCustomFieldService{}
+		mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+		departmentController = NewDepartmentController(mockLogger, mockDepartmentService, mockAuthService, mockCustomField)
 		router = gin.Default()
 		departmentController.RegisterRoutes(router)
 	})
@@ -88,6 +94,27 @@ var _ = Describe("DepartmentController", func() {
 
 			Expect(response["Name"]).To(Equal(department.Name))
 		})
+		It("should leave out custom fields viewer has no ability for", func() {
+			department := &models.Department{Name: "HR", CustomFieldValues: []customfield_models.CustomFieldValue{
+				{Field: &customfield_models.CustomField{Key: "costCenter", Type: customfield_constants.CUSTOM_FIELD_TYPE_SELECT}, TextValue: "A1"},
+				{Field: &customfield_models.CustomField{Key: "budget", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: auth_constants.ABILITY_READ_SALARY}},
+			}}
+			department.ID = 1
+
+			mockDepartmentService.On("FindDepartmentByID", 1).Return(department, nil)
+
+			req, _ := http.NewRequest("GET", "/api/department/1", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response dtos.DepartmentResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			Expect(response.CustomFields).To(Equal(map[string]interface{}{"costCenter": "A1"}))
+		})
 	})
 
 	Describe("CreateDepartment", func() {
@@ -1,6 +1,7 @@
 package dtos
 
 import (
+	customfield_dtos "hr-system-go/internal/customfield/dtos"
 	"hr-system-go/internal/department/models"
 	"hr-system-go/utils"
 )
@@ -16,43 +17,45 @@ type DepartmentResponse struct {
 	Description string
 	Status      string
 	EmployCount int
+	// CustomFields maps key of each custom field viewer can see to its value
+	CustomFields map[string]interface{}
 }
 
 type CreateDepartmentRequest struct {
 	Name         string  `json:"name"`
 	Descriptions *string `json:"descriptions,omitempty"`
+	// CustomFields are values by key, required custom fields have to be given
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
 }
 
 type UpdateDepartmentRequest struct {
 	Name         *string `json:"name"`
 	Descriptions *string `json:"descriptions,omitempty"`
 	Status       *string `json:"status,omitempty"`
+	// CustomFields are values by key, null clears value. Saved apart from the department columns
+	CustomFields map[string]interface{} `json:"customFields,omitempty" gorm:"-"`
 }
 
-func NewDepartmentListResponse(departments []models.Department, totalRows int64, pagination utils.Pagination) *DepartmentListResponse {
+func NewDepartmentListResponse(departments []models.Department, totalRows int64, pagination utils.Pagination, access customfield_dtos.ValueAccess) *DepartmentListResponse {
 	items := []*DepartmentResponse{}
 	for _, department := range departments {
-		items = append(items, NewDepartmentResponse(&department))
+		items = append(items, NewDepartmentResponse(&department, access))
 	}
 
 	return &DepartmentListResponse{
-		Items: items,
-		Pagination: utils.PaginationResult{
-			Limit: pagination.Limit,
-			Page:  pagination.Page,
-			Total: totalRows,
-			Sort:  pagination.Sort,
-		},
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
 	}
 }
 
-func NewDepartmentResponse(department *models.Department) *DepartmentResponse {
+func NewDepartmentResponse(department *models.Department, access customfield_dtos.ValueAccess) *DepartmentResponse {
 	res := &DepartmentResponse{
-		Id:          department.ID,
-		Name:        department.Name,
-		Description: department.Descriptions,
-		Status:      department.Status,
-		EmployCount: department.EmployCount,
+		Id:           department.ID,
+		Name:         department.Name,
+		Description:  department.Descriptions,
+		Status:       department.Status,
+		EmployCount:  department.EmployCount,
+		CustomFields: customfield_dtos.NewCustomFieldValuesResponse(department.CustomFieldValues, access),
 	}
 
 	return res
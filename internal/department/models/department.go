@@ -2,6 +2,7 @@ package models
 
 import (
 	base_model "hr-system-go/internal/base/models"
+	customfield_model "hr-system-go/internal/customfield/models"
 
 	"gorm.io/gorm"
 )
@@ -12,6 +13,8 @@ type Department struct {
 	Descriptions string `gorm:"type:text"`
 	Status       string `gorm:"default:'active'"`
 	EmployCount  int
+	// CustomFieldValues are values of fields admins added to departments
+	CustomFieldValues []customfield_model.CustomFieldValue `gorm:"polymorphic:Entity;polymorphicValue:department"`
 }
 
 func ValidScope(db *gorm.DB) *gorm.DB {
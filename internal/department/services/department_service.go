@@ -1,22 +1,26 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_services "hr-system-go/internal/customfield/services"
 
 	"hr-system-go/internal/department/dtos"
 	"hr-system-go/internal/department/models"
 	"hr-system-go/utils"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type DepartmentServiceInterface interface {
 	FindDepartments(pagination *utils.Pagination) ([]models.Department, int64, error)
 	FindDepartmentByID(id int) (*models.Department, error)
-	CreateDepartment(payload dtos.CreateDepartmentRequest) (*models.Department, error)
-	UpdateDepartmentByID(id int, payload dtos.UpdateDepartmentRequest) (*models.Department, error)
-	DeleteDepartmentByID(id int) error
+	CreateDepartment(ctx context.Context, payload dtos.CreateDepartmentRequest) (*models.Department, error)
+	UpdateDepartmentByID(ctx context.Context, id int, payload dtos.UpdateDepartmentRequest) (*models.Department, error)
+	DeleteDepartmentByID(ctx context.Context, id int) error
 }
 
 type DepartmentService struct {
@@ -31,15 +35,31 @@ func NewDepartmentService(logger *logger.Logger, db *mysql.MySqlStore) Departmen
 	}
 }
 
+var departmentSortFields = utils.SortFields{
+	"name":        "name",
+	"employCount": "employ_count",
+	"createdAt":   "created_at",
+}
+
+// FindDepartments takes search over name and description and filters on custom fields
 func (s *DepartmentService) FindDepartments(pagination *utils.Pagination) ([]models.Department, int64, error) {
 	var departments []models.Department
 	var totalCount int64 = 0
 
-	if err := models.ValidScope(s.db.DB()).Count(&totalCount).Error; err != nil {
+	customFilters, err := customfield_services.CustomFieldFilters(s.db.DB(), customfield_constants.CUSTOM_FIELD_ENTITY_DEPARTMENT, *pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter, err := pagination.FilterScope(customFilters, "name", "descriptions")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := models.ValidScope(s.db.DB()).Scopes(filter).Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := models.ValidScope(s.db.DB()).Limit(pagination.Limit).Offset(pagination.Offset()).Order(pagination.Sort).Find(&departments).Error
+	err = pagination.FindPage(models.ValidScope(s.db.DB()).Scopes(filter).Preload("CustomFieldValues.Field"), departmentSortFields, &departments)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -49,7 +69,7 @@ func (s *DepartmentService) FindDepartments(pagination *utils.Pagination) ([]mod
 
 func (s *DepartmentService) FindDepartmentByID(departmentID int) (*models.Department, error) {
 	var department *models.Department
-	if err := models.ValidScope(s.db.DB()).First(&department, departmentID).Error; err != nil {
+	if err := models.ValidScope(s.db.DB()).Preload("CustomFieldValues.Field").First(&department, departmentID).Error; err != nil {
 		s.logger.Error("Cannot Not Find User by ID", zap.Error(err))
 		return nil, err
 	}
@@ -57,22 +77,34 @@ func (s *DepartmentService) FindDepartmentByID(departmentID int) (*models.Depart
 	return department, nil
 }
 
-func (s *DepartmentService) CreateDepartment(payload dtos.CreateDepartmentRequest) (*models.Department, error) {
+func (s *DepartmentService) CreateDepartment(ctx context.Context, payload dtos.CreateDepartmentRequest) (*models.Department, error) {
 	department := &models.Department{Name: payload.Name}
 	if payload.Descriptions != nil {
 		department.Descriptions = *payload.Descriptions
 	}
-	if err := models.ValidScope(s.db.DB()).Create(&department).Error; err != nil {
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := models.ValidScope(tx).Create(&department).Error; err != nil {
+			return err
+		}
+		return customfield_services.SaveCustomFieldValues(tx, customfield_constants.CUSTOM_FIELD_ENTITY_DEPARTMENT, department.ID, payload.CustomFields, true)
+	})
+	if err != nil {
 		s.logger.Error("Cannot Update Deployment Data", zap.Error(err))
 		return nil, err
 	}
 
-	return department, nil
+	return s.FindDepartmentByID(int(department.ID))
 }
 
-func (s *DepartmentService) UpdateDepartmentByID(departmentID int, payload dtos.UpdateDepartmentRequest) (*models.Department, error) {
+func (s *DepartmentService) UpdateDepartmentByID(ctx context.Context, departmentID int, payload dtos.UpdateDepartmentRequest) (*models.Department, error) {
 	var department *models.Department
-	if err := models.ValidScope(s.db.DB()).First(&department, departmentID).Updates(payload).Error; err != nil {
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := models.ValidScope(tx).First(&department, departmentID).Updates(payload).Error; err != nil {
+			return err
+		}
+		return customfield_services.SaveCustomFieldValues(tx, customfield_constants.CUSTOM_FIELD_ENTITY_DEPARTMENT, department.ID, payload.CustomFields, false)
+	})
+	if err != nil {
 		s.logger.Error("Cannot Update Deployment Data", zap.Error(err))
 		return nil, err
 	}
@@ -80,7 +112,7 @@ func (s *DepartmentService) UpdateDepartmentByID(departmentID int, payload dtos.
 	return s.FindDepartmentByID(departmentID)
 }
 
-func (s *DepartmentService) DeleteDepartmentByID(departmentID int) error {
+func (s *DepartmentService) DeleteDepartmentByID(ctx context.Context, departmentID int) error {
 	var department *models.Department
-	return models.ValidScope(s.db.DB()).First(&department, departmentID).Update("status", "removed").Error
+	return models.ValidScope(s.db.DB().WithContext(ctx)).First(&department, departmentID).Update("status", "removed").Error
 }
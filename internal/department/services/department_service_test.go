@@ -1,12 +1,17 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	customfield_services "hr-system-go/internal/customfield/services"
 	"hr-system-go/internal/department/dtos"
 	"hr-system-go/internal/department/models"
 	"hr-system-go/utils"
+	"net/url"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -40,11 +45,11 @@ var _ = BeforeSuite(func() {
 		mockEnv.GetEnv("DB_PARAMS"),
 	)
 
-	mockDB.DB().AutoMigrate(&models.Department{})
+	mockDB.DB().AutoMigrate(&models.Department{}, &customfield_models.CustomField{}, &customfield_models.CustomFieldValue{})
 })
 
 var _ = AfterSuite(func() {
-	mockDB.DB().Migrator().DropTable(&models.Department{})
+	mockDB.DB().Migrator().DropTable(&models.Department{}, &customfield_models.CustomField{}, &customfield_models.CustomFieldValue{})
 	mockDB.Close()
 })
 
@@ -96,7 +101,7 @@ var _ = Describe("DepartmentService", func() {
 			payload := dtos.CreateDepartmentRequest{Name: "New Dept", Descriptions: new(string)}
 			*payload.Descriptions = "New department description"
 
-			result, err := departmentService.CreateDepartment(payload)
+			result, err := departmentService.CreateDepartment(context.Background(), payload)
 
 			Expect(err).To(BeNil())
 			Expect(result.Name).To(Equal(payload.Name))
@@ -113,7 +118,7 @@ var _ = Describe("DepartmentService", func() {
 				Name: requestName,
 			}
 			mockDB.DB().Create(&department)
-			result, err := departmentService.UpdateDepartmentByID(int(department.ID), payload)
+			result, err := departmentService.UpdateDepartmentByID(context.Background(), int(department.ID), payload)
 
 			Expect(err).To(BeNil())
 			Expect(result.ID).To(Equal(department.ID))
@@ -130,9 +135,63 @@ var _ = Describe("DepartmentService", func() {
 
 			mockDB.DB().Create(&department)
 
-			err := departmentService.DeleteDepartmentByID(int(department.ID))
+			err := departmentService.DeleteDepartmentByID(context.Background(), int(department.ID))
 
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Describe("CustomFields", func() {
+		var costCenter, headcount *customfield_models.CustomField
+
+		BeforeEach(func() {
+			_ = mockDB.DB().Exec("truncate table department").Error
+			costCenter = &customfield_models.CustomField{Entity: customfield_constants.CUSTOM_FIELD_ENTITY_DEPARTMENT, Key: "costCenter", Label: "Cost center", Type: customfield_constants.CUSTOM_FIELD_TYPE_SELECT, Options: []string{"A1", "B2"}, Required: true}
+			headcount = &customfield_models.CustomField{Entity: customfield_constants.CUSTOM_FIELD_ENTITY_DEPARTMENT, Key: "plannedHeadcount", Label: "Planned headcount", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER}
+			Expect(mockDB.DB().Create(costCenter).Error).To(Succeed())
+			Expect(mockDB.DB().Create(headcount).Error).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = mockDB.DB().Exec("truncate table custom_field").Error
+			_ = mockDB.DB().Exec("truncate table custom_field_value").Error
+		})
+
+		It("should save values and filter on them", func() {
+			for name, values := range map[string]map[string]interface{}{
+				"HR": {"costCenter": "A1", "plannedHeadcount": 4.0},
+				"IT": {"costCenter": "B2", "plannedHeadcount": 12.0},
+			} {
+				_, err := departmentService.CreateDepartment(context.Background(), dtos.CreateDepartmentRequest{Name: name, CustomFields: values})
+				Expect(err).To(BeNil())
+			}
+
+			pagination := utils.Pagination{Page: 1, Limit: 10, Filters: url.Values{"custom.costCenter": {"B2"}, "custom.plannedHeadcountFrom": {"10"}}}
+			result, totalCount, err := departmentService.FindDepartments(&pagination)
+
+			Expect(err).To(BeNil())
+			Expect(totalCount).To(Equal(int64(1)))
+			Expect(result[0].Name).To(Equal("IT"))
+			Expect(result[0].CustomFieldValues).To(HaveLen(2))
+		})
+
+		It("should refuse department without required field", func() {
+			_, err := departmentService.CreateDepartment(context.Background(), dtos.CreateDepartmentRequest{Name: "MKT"})
+
+			Expect(err).To(MatchError(customfield_services.ErrCustomFieldValue))
+			Expect(err.Error()).To(Equal("costCenter is required"))
+		})
+
+		It("should refuse value which is not an option", func() {
+			department, err := departmentService.CreateDepartment(context.Background(), dtos.CreateDepartmentRequest{Name: "BD", CustomFields: map[string]interface{}{"costCenter": "A1"}})
+			Expect(err).To(BeNil())
+
+			_, err = departmentService.UpdateDepartmentByID(context.Background(), int(department.ID), dtos.UpdateDepartmentRequest{CustomFields: map[string]interface{}{"costCenter": "Z9"}})
+
+			Expect(err).To(MatchError(customfield_services.ErrCustomFieldValue))
+			found, err := departmentService.FindDepartmentByID(int(department.ID))
+			Expect(err).To(BeNil())
+			Expect(found.CustomFieldValues[0].TextValue).To(Equal("A1"))
+		})
+	})
 })
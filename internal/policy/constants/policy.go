@@ -0,0 +1,31 @@
+package constants
+
+const (
+	POLICY_RESOURCE_USER             = "user"
+	POLICY_RESOURCE_LEAVE            = "leave"
+	POLICY_RESOURCE_CLOCK_RECORD     = "clock_record"
+	POLICY_RESOURCE_USER_PERMISSIONS = "user_permissions"
+	POLICY_RESOURCE_API_KEY          = "api_key"
+	POLICY_RESOURCE_SESSION          = "session"
+	POLICY_RESOURCE_PROFILE          = "profile"
+	POLICY_RESOURCE_CHECKLIST        = "checklist"
+	POLICY_RESOURCE_CONTRACT         = "contract"
+	POLICY_RESOURCE_DIRECTORY        = "directory"
+)
+
+const (
+	POLICY_ACTION_READ   = "read"
+	POLICY_ACTION_CREATE = "create"
+	POLICY_ACTION_UPDATE = "update"
+	POLICY_ACTION_DELETE = "delete"
+	// POLICY_ACTION_APPROVE decides status of leave or outcome of probation
+	POLICY_ACTION_APPROVE = "approve"
+	// POLICY_ACTION_CHANGE_SIGN_IN enables or disables password login of user
+	POLICY_ACTION_CHANGE_SIGN_IN = "change_sign_in"
+)
+
+// deny rules win over allow rules, request without matching allow rule is denied
+const (
+	POLICY_EFFECT_ALLOW = "allow"
+	POLICY_EFFECT_DENY  = "deny"
+)
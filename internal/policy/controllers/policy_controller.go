@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	"hr-system-go/internal/policy/dtos"
+	"hr-system-go/internal/policy/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PolicyController struct {
+	logger      *logger.Logger
+	service     services.PolicyServiceInterface
+	authService auth_service.AuthServiceInterface
+}
+
+func NewPolicyController(logger *logger.Logger, service services.PolicyServiceInterface, authService auth_service.AuthServiceInterface) *PolicyController {
+	return &PolicyController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+func (c *PolicyController) RegisterRoutes(r *gin.Engine) {
+	policyRoutes := r.Group("/api/policy")
+	{
+		policyRoutes.GET("/rules", c.authService.AuthUserAbilityWrapper(c.listRules, constants.ABILITY_ADMIN))
+		policyRoutes.POST("/explain", c.authService.AuthUserAbilityWrapper(c.Explain, constants.ABILITY_ADMIN))
+	}
+}
+
+func (c *PolicyController) listRules(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, dtos.NewRuleListResponse(c.service.Rules()))
+}
+
+// Explain shows why subject would be granted or denied access, nothing is changed
+func (c *PolicyController) Explain(ctx *gin.Context) {
+	var payload dtos.ExplainRequest
+	errorMsg := "Failed to Explain Policy"
+	if err := ctx.ShouldBindJSON(&payload); err != nil || payload.SubjectID == 0 {
+		c.logger.Error("Cannot not parse explain payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	explanation, err := c.service.Explain(payload)
+	if errors.Is(err, services.ErrUnknownPolicy) || errors.Is(err, services.ErrMissingResource) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not explain policy", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewExplainResponse(explanation))
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/policy/constants"
+	"hr-system-go/internal/policy/dtos"
+	"hr-system-go/internal/policy/models"
+	"hr-system-go/internal/policy/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestPolicyController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Policy Controller Suite")
+}
+
+var (
+	mockPolicyService *mock_services.MockPolicyService
+	mockAuthService   *mock_services.MockAuthService
+	router            *gin.Engine
+	mockLogger        *logger.Logger
+)
+
+var _ = Describe("PolicyController", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockPolicyService = &mock_services.MockPolicyService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		router = gin.Default()
+		NewPolicyController(mockLogger, mockPolicyService, mockAuthService).RegisterRoutes(router)
+	})
+
+	Describe("Explain", func() {
+		ownerID := uint(5)
+		payload := dtos.ExplainRequest{SubjectID: 1, Resource: constants.POLICY_RESOURCE_USER, Action: constants.POLICY_ACTION_READ, OwnerID: &ownerID}
+		explain := func(payload dtos.ExplainRequest) *httptest.ResponseRecorder {
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/policy/explain", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("should return decision with evaluated rules", func() {
+			explanation := &models.Explanation{
+				Decision: models.Decision{Allowed: false, Reason: "No rule allows read of user"},
+				Rules: []models.RuleResult{{
+					Name:       "user.read.owner",
+					Effect:     constants.POLICY_EFFECT_ALLOW,
+					Conditions: []models.ConditionResult{{Name: "subject owns resource", Passed: false}},
+				}},
+			}
+			mockPolicyService.On("Explain", payload).Return(explanation, nil)
+
+			w := explain(payload)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.ExplainResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Rules[0].Conditions[0].Passed).To(BeFalse())
+		})
+
+		It("should reject resource action without rules", func() {
+			unknown := dtos.ExplainRequest{SubjectID: 1, Resource: "payroll", Action: "export"}
+			mockPolicyService.On("Explain", unknown).Return(nil, services.ErrUnknownPolicy)
+
+			Expect(explain(unknown).Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return not found for unknown subject", func() {
+			mockPolicyService.On("Explain", payload).Return(nil, gorm.ErrRecordNotFound)
+
+			Expect(explain(payload).Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should require subject", func() {
+			Expect(explain(dtos.ExplainRequest{Resource: constants.POLICY_RESOURCE_USER}).Code).To(Equal(http.StatusBadRequest))
+			mockPolicyService.AssertNotCalled(GinkgoT(), "Explain")
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package dtos
+
+import "hr-system-go/internal/policy/models"
+
+// ExplainRequest is a dry run of authorization for any user, nothing is changed
+type ExplainRequest struct {
+	SubjectID uint   `json:"subjectId"`
+	Resource  string `json:"resource"`
+	Action    string `json:"action"`
+	// ResourceID identifies leave, other resources are identified by their owner
+	ResourceID    *uint `json:"resourceId,omitempty"`
+	OwnerID       *uint `json:"ownerId,omitempty"`
+	Impersonating bool  `json:"impersonating,omitempty"`
+	ApiKey        bool  `json:"apiKey,omitempty"`
+}
+
+type RuleListResponse struct {
+	Items []*RuleResponse
+}
+
+type RuleResponse struct {
+	Name        string
+	Resource    string
+	Action      string
+	Effect      string
+	Description string
+	Conditions  []string
+}
+
+type ExplainResponse struct {
+	Allowed  bool
+	Rule     string
+	Reason   string
+	Subject  models.Subject
+	Resource models.Resource
+	Rules    []models.RuleResult
+}
+
+func NewRuleListResponse(rules []models.Rule) *RuleListResponse {
+	items := []*RuleResponse{}
+	for _, rule := range rules {
+		conditions := []string{}
+		for _, condition := range rule.Conditions {
+			conditions = append(conditions, condition.Name)
+		}
+		items = append(items, &RuleResponse{
+			Name:        rule.Name,
+			Resource:    rule.Resource,
+			Action:      rule.Action,
+			Effect:      rule.Effect,
+			Description: rule.Description,
+			Conditions:  conditions,
+		})
+	}
+	return &RuleListResponse{Items: items}
+}
+
+func NewExplainResponse(explanation *models.Explanation) *ExplainResponse {
+	return &ExplainResponse{
+		Allowed:  explanation.Decision.Allowed,
+		Rule:     explanation.Decision.Rule,
+		Reason:   explanation.Decision.Reason,
+		Subject:  explanation.Request.Subject,
+		Resource: explanation.Request.Resource,
+		Rules:    explanation.Rules,
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+// Subject is user asking for access, it is not stored
+type Subject struct {
+	ID           uint
+	DepartmentID *uint
+	Abilities    []string
+}
+
+// Resource is a record or collection of records belonging to owner
+type Resource struct {
+	Type    string
+	ID      uint
+	OwnerID uint
+	// DepartmentID is department of owner
+	DepartmentID *uint
+	// ManagerID is manager of owner in employment record in effect
+	ManagerID *uint
+}
+
+// Environment describes how request was made
+type Environment struct {
+	Impersonating bool
+	ApiKey        bool
+}
+
+type Request struct {
+	Subject     Subject
+	Action      string
+	Resource    Resource
+	Environment Environment
+}
+
+type Condition struct {
+	Name  string
+	Check func(request Request) bool
+}
+
+// Rule matches request when all of its conditions hold
+type Rule struct {
+	Name        string
+	Resource    string
+	Action      string
+	Effect      string
+	Description string
+	Conditions  []Condition
+}
+
+type Decision struct {
+	Allowed bool
+	// Rule is name of rule which decided, empty when no rule matched
+	Rule   string
+	Reason string
+}
+
+type ConditionResult struct {
+	Name   string
+	Passed bool
+}
+
+type RuleResult struct {
+	Name        string
+	Effect      string
+	Description string
+	Matched     bool
+	Conditions  []ConditionResult
+}
+
+// Explanation lists every rule of resource action with result of each condition
+type Explanation struct {
+	Decision Decision
+	Request  Request
+	Rules    []RuleResult
+}
@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"hr-system-go/app"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/policy/controllers"
+	"hr-system-go/internal/policy/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PolicyModule struct {
+	app.AppModuleInterface
+}
+
+func (m *PolicyModule) Controllers() []interface{} {
+	return []interface{}{
+		controllers.NewPolicyController,
+		func(
+			r *gin.Engine,
+			c *controllers.PolicyController,
+			logger *logger.Logger,
+		) *PolicyModule {
+			c.RegisterRoutes(r)
+			logger.Info("= Policy module init")
+			return m
+		},
+	}
+}
+
+func (m *PolicyModule) Provide() []interface{} {
+	return []interface{}{
+		services.NewPolicyService,
+	}
+}
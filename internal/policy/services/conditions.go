@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	auth_constants "hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/policy/models"
+)
+
+// HasAbility passes for admins as well, same as ability check of routes
+func HasAbility(ability string) models.Condition {
+	return models.Condition{
+		Name: fmt.Sprintf("subject has %s ability", ability),
+		Check: func(request models.Request) bool {
+			for _, name := range request.Subject.Abilities {
+				if name == ability || name == auth_constants.ABILITY_ADMIN {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+func IsOwner() models.Condition {
+	return models.Condition{
+		Name: "subject owns resource",
+		Check: func(request models.Request) bool {
+			return request.Subject.ID != 0 && request.Subject.ID == request.Resource.OwnerID
+		},
+	}
+}
+
+// IsManager fails when owner has no manager in employment record in effect
+func IsManager() models.Condition {
+	return models.Condition{
+		Name: "subject is owner's manager",
+		Check: func(request models.Request) bool {
+			manager := request.Resource.ManagerID
+			return request.Subject.ID != 0 && manager != nil && *manager == request.Subject.ID
+		},
+	}
+}
+
+func Impersonating() models.Condition {
+	return models.Condition{
+		Name: "request is made while impersonating",
+		Check: func(request models.Request) bool {
+			return request.Environment.Impersonating
+		},
+	}
+}
+
+func UsingApiKey() models.Condition {
+	return models.Condition{
+		Name: "request is authenticated with api key",
+		Check: func(request models.Request) bool {
+			return request.Environment.ApiKey
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"hr-system-go/internal/policy/constants"
+	"hr-system-go/internal/policy/models"
+)
+
+// Engine evaluates declared rules, it has no dependencies so rules can be tested in isolation
+type Engine struct {
+	rules []models.Rule
+	index map[string][]models.Rule
+}
+
+func NewEngine(rules []models.Rule) *Engine {
+	index := map[string][]models.Rule{}
+	for _, rule := range rules {
+		key := ruleKey(rule.Resource, rule.Action)
+		index[key] = append(index[key], rule)
+	}
+	return &Engine{rules: rules, index: index}
+}
+
+func ruleKey(resource string, action string) string {
+	return resource + ":" + action
+}
+
+// Rules are returned in declaration order
+func (e *Engine) Rules() []models.Rule {
+	return e.rules
+}
+
+func (e *Engine) HasRules(resource string, action string) bool {
+	return len(e.index[ruleKey(resource, action)]) > 0
+}
+
+func (e *Engine) Evaluate(request models.Request) models.Decision {
+	return e.Explain(request).Decision
+}
+
+// Explain checks every condition of every rule, so denied request shows what is missing
+func (e *Engine) Explain(request models.Request) models.Explanation {
+	explanation := models.Explanation{Request: request, Rules: []models.RuleResult{}}
+	var allowedBy, deniedBy *models.RuleResult
+
+	for _, rule := range e.index[ruleKey(request.Resource.Type, request.Action)] {
+		result := models.RuleResult{
+			Name:        rule.Name,
+			Effect:      rule.Effect,
+			Description: rule.Description,
+			Matched:     true,
+			Conditions:  []models.ConditionResult{},
+		}
+		for _, condition := range rule.Conditions {
+			passed := condition.Check(request)
+			result.Conditions = append(result.Conditions, models.ConditionResult{Name: condition.Name, Passed: passed})
+			result.Matched = result.Matched && passed
+		}
+		explanation.Rules = append(explanation.Rules, result)
+
+		if !result.Matched {
+			continue
+		}
+		if rule.Effect == constants.POLICY_EFFECT_DENY && deniedBy == nil {
+			deniedBy = &result
+		}
+		if rule.Effect == constants.POLICY_EFFECT_ALLOW && allowedBy == nil {
+			allowedBy = &result
+		}
+	}
+
+	switch {
+	case deniedBy != nil:
+		explanation.Decision = models.Decision{Allowed: false, Rule: deniedBy.Name, Reason: deniedBy.Description}
+	case allowedBy != nil:
+		explanation.Decision = models.Decision{Allowed: true, Rule: allowedBy.Name, Reason: allowedBy.Description}
+	default:
+		explanation.Decision = models.Decision{
+			Allowed: false,
+			Reason:  fmt.Sprintf("No rule allows %s of %s", request.Action, request.Resource.Type),
+		}
+	}
+	return explanation
+}
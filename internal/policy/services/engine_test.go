@@ -0,0 +1,188 @@
+package services
+
+import (
+	auth_constants "hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/policy/constants"
+	"hr-system-go/internal/policy/models"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPolicyService(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Policy Suite")
+}
+
+var _ = Describe("Engine", func() {
+	always := models.Condition{Name: "always", Check: func(models.Request) bool { return true }}
+	never := models.Condition{Name: "never", Check: func(models.Request) bool { return false }}
+	request := models.Request{Action: "read", Resource: models.Resource{Type: "report"}}
+
+	It("should let matching deny rule win over allow rule", func() {
+		engine := NewEngine([]models.Rule{
+			allow("report", "read", "anyone", "Anyone can read", always),
+			deny("report", "read", "frozen", "Reports are frozen", always),
+		})
+
+		decision := engine.Evaluate(request)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Rule).To(Equal("report.read.frozen"))
+		Expect(decision.Reason).To(Equal("Reports are frozen"))
+	})
+
+	It("should deny when no allow rule matches", func() {
+		engine := NewEngine([]models.Rule{allow("report", "read", "nobody", "Nobody can read", never)})
+
+		decision := engine.Evaluate(request)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Rule).To(BeEmpty())
+	})
+
+	It("should explain every condition of every rule", func() {
+		engine := NewEngine([]models.Rule{
+			allow("report", "read", "partly", "Needs both", never, always),
+			allow("report", "read", "anyone", "Anyone can read", always),
+		})
+
+		explanation := engine.Explain(request)
+
+		Expect(explanation.Decision.Rule).To(Equal("report.read.anyone"))
+		Expect(explanation.Rules).To(HaveLen(2))
+		Expect(explanation.Rules[0].Matched).To(BeFalse())
+		Expect(explanation.Rules[0].Conditions).To(Equal([]models.ConditionResult{
+			{Name: "never", Passed: false},
+			{Name: "always", Passed: true},
+		}))
+	})
+})
+
+var _ = Describe("DefaultRules", func() {
+	engine := NewEngine(DefaultRules())
+	departmentA, departmentB := uint(1), uint(2)
+	evaluate := func(subject models.Subject, resource models.Resource, action string) models.Decision {
+		return engine.Evaluate(models.Request{Subject: subject, Action: action, Resource: resource})
+	}
+
+	Describe("user", func() {
+		user := models.Resource{Type: constants.POLICY_RESOURCE_USER, OwnerID: 5}
+
+		It("should allow admin to read other user", func() {
+			subject := models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_ADMIN}}
+			Expect(evaluate(subject, user, constants.POLICY_ACTION_READ).Allowed).To(BeTrue())
+		})
+
+		It("should allow all grants ability to read other user", func() {
+			subject := models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_ALL_GRANTS_USER}}
+			Expect(evaluate(subject, user, constants.POLICY_ACTION_READ).Allowed).To(BeTrue())
+		})
+
+		It("should allow user to read their own data", func() {
+			subject := models.Subject{ID: 5}
+			Expect(evaluate(subject, user, constants.POLICY_ACTION_READ).Allowed).To(BeTrue())
+		})
+
+		It("should deny other user's data without ability", func() {
+			subject := models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_READ_USER}}
+			Expect(evaluate(subject, user, constants.POLICY_ACTION_UPDATE).Allowed).To(BeFalse())
+		})
+
+		It("should deny changing own sign in method even to admin", func() {
+			subject := models.Subject{ID: 5, Abilities: []string{auth_constants.ABILITY_ADMIN}}
+			decision := evaluate(subject, user, constants.POLICY_ACTION_CHANGE_SIGN_IN)
+			Expect(decision.Allowed).To(BeFalse())
+			Expect(decision.Rule).To(Equal("user.change_sign_in.self"))
+		})
+	})
+
+	Describe("leave", func() {
+		managerID := uint(1)
+		leave := models.Resource{Type: constants.POLICY_RESOURCE_LEAVE, ID: 9, OwnerID: 5, DepartmentID: &departmentA, ManagerID: &managerID}
+
+		It("should let only owner update leave", func() {
+			manager := models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_ALL_GRANTS_LEAVE}}
+			Expect(evaluate(manager, leave, constants.POLICY_ACTION_UPDATE).Allowed).To(BeFalse())
+			Expect(evaluate(models.Subject{ID: 5}, leave, constants.POLICY_ACTION_UPDATE).Allowed).To(BeTrue())
+		})
+
+		It("should allow manager of owner to approve", func() {
+			manager := models.Subject{ID: 1, DepartmentID: &departmentB}
+			Expect(evaluate(manager, leave, constants.POLICY_ACTION_APPROVE).Allowed).To(BeTrue())
+		})
+
+		It("should deny leave managers of owner's department who do not manage owner", func() {
+			colleague := models.Subject{ID: 2, DepartmentID: &departmentA, Abilities: []string{auth_constants.ABILITY_ALL_GRANTS_LEAVE}}
+			decision := evaluate(colleague, leave, constants.POLICY_ACTION_APPROVE)
+			Expect(decision.Allowed).To(BeFalse())
+		})
+
+		It("should deny approving leave of owner without manager", func() {
+			unmanaged := models.Resource{Type: constants.POLICY_RESOURCE_LEAVE, ID: 9, OwnerID: 5, DepartmentID: &departmentA}
+			Expect(evaluate(models.Subject{ID: 1, DepartmentID: &departmentA}, unmanaged, constants.POLICY_ACTION_APPROVE).Allowed).To(BeFalse())
+		})
+
+		It("should deny approving own leave", func() {
+			manager := models.Subject{ID: 5, DepartmentID: &departmentA, Abilities: []string{auth_constants.ABILITY_ADMIN}}
+			decision := evaluate(manager, leave, constants.POLICY_ACTION_APPROVE)
+			Expect(decision.Allowed).To(BeFalse())
+			Expect(decision.Rule).To(Equal("leave.approve.self"))
+		})
+	})
+
+	Describe("contract", func() {
+		contract := models.Resource{Type: constants.POLICY_RESOURCE_CONTRACT, OwnerID: 5}
+
+		It("should let employment manager decide probation of others but not their own", func() {
+			hr := models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_WRITE_EMPLOYMENT}}
+			self := models.Subject{ID: 5, Abilities: []string{auth_constants.ABILITY_WRITE_EMPLOYMENT}}
+			decision := evaluate(self, contract, constants.POLICY_ACTION_APPROVE)
+
+			Expect(evaluate(hr, contract, constants.POLICY_ACTION_APPROVE).Allowed).To(BeTrue())
+			Expect(decision.Allowed).To(BeFalse())
+			Expect(decision.Rule).To(Equal("contract.approve.self"))
+			Expect(evaluate(models.Subject{ID: 5}, contract, constants.POLICY_ACTION_READ).Allowed).To(BeTrue())
+		})
+	})
+
+	Describe("directory", func() {
+		entry := models.Resource{Type: constants.POLICY_RESOURCE_DIRECTORY, OwnerID: 5}
+
+		It("should let owner and employment manager update directory entry", func() {
+			Expect(evaluate(models.Subject{ID: 5}, entry, constants.POLICY_ACTION_UPDATE).Allowed).To(BeTrue())
+			Expect(evaluate(models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_WRITE_EMPLOYMENT}}, entry, constants.POLICY_ACTION_UPDATE).Allowed).To(BeTrue())
+			Expect(evaluate(models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_READ_PERSONAL_DATA}}, entry, constants.POLICY_ACTION_UPDATE).Allowed).To(BeFalse())
+		})
+	})
+
+	Describe("api_key", func() {
+		apiKey := models.Resource{Type: constants.POLICY_RESOURCE_API_KEY, OwnerID: 5}
+
+		It("should deny creating key with api key or while impersonating", func() {
+			owner := models.Subject{ID: 5}
+			byApiKey := engine.Evaluate(models.Request{Subject: owner, Action: constants.POLICY_ACTION_CREATE, Resource: apiKey, Environment: models.Environment{ApiKey: true}})
+			impersonating := engine.Evaluate(models.Request{Subject: owner, Action: constants.POLICY_ACTION_CREATE, Resource: apiKey, Environment: models.Environment{Impersonating: true}})
+
+			Expect(byApiKey.Allowed).To(BeFalse())
+			Expect(byApiKey.Reason).To(Equal("Api keys cannot create api keys"))
+			Expect(impersonating.Allowed).To(BeFalse())
+			Expect(evaluate(owner, apiKey, constants.POLICY_ACTION_CREATE).Allowed).To(BeTrue())
+		})
+	})
+	Describe("session", func() {
+		session := models.Resource{Type: constants.POLICY_RESOURCE_SESSION, OwnerID: 5}
+
+		It("should let owner and admin terminate session but not while impersonating", func() {
+			owner := models.Subject{ID: 5}
+			admin := models.Subject{ID: 1, Abilities: []string{auth_constants.ABILITY_ADMIN}}
+			impersonating := engine.Evaluate(models.Request{Subject: owner, Action: constants.POLICY_ACTION_DELETE, Resource: session, Environment: models.Environment{Impersonating: true}})
+
+			Expect(evaluate(owner, session, constants.POLICY_ACTION_DELETE).Allowed).To(BeTrue())
+			Expect(evaluate(admin, session, constants.POLICY_ACTION_DELETE).Allowed).To(BeTrue())
+			Expect(evaluate(models.Subject{ID: 2}, session, constants.POLICY_ACTION_READ).Allowed).To(BeFalse())
+			Expect(impersonating.Allowed).To(BeFalse())
+		})
+	})
+})
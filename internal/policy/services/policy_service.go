@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	attendance_models "hr-system-go/internal/attendance/models"
+	auth_services "hr-system-go/internal/auth/services"
+	"hr-system-go/internal/policy/constants"
+	"hr-system-go/internal/policy/dtos"
+	"hr-system-go/internal/policy/models"
+	user_models "hr-system-go/internal/user/models"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownPolicy   = errors.New("no rules are declared for resource action")
+	ErrMissingResource = errors.New("ownerId or resourceId of leave is required")
+)
+
+type PolicyServiceInterface interface {
+	Authorize(ctx *gin.Context, resource models.Resource, action string) models.Decision
+	Rules() []models.Rule
+	Explain(payload dtos.ExplainRequest) (*models.Explanation, error)
+}
+
+type PolicyService struct {
+	logger      *logger.Logger
+	db          *mysql.MySqlStore
+	authService auth_services.AuthServiceInterface
+	cache       *auth_services.PermissionCache
+	engine      *Engine
+}
+
+func NewPolicyService(logger *logger.Logger, db *mysql.MySqlStore, authService auth_services.AuthServiceInterface, cache *auth_services.PermissionCache) PolicyServiceInterface {
+	return &PolicyService{
+		logger:      logger,
+		db:          db,
+		authService: authService,
+		cache:       cache,
+		engine:      NewEngine(DefaultRules()),
+	}
+}
+
+// Authorize evaluates rules for current user of request
+func (s *PolicyService) Authorize(ctx *gin.Context, resource models.Resource, action string) models.Decision {
+	subject := models.Subject{Abilities: s.authService.GetCurrentUserAbilities(ctx)}
+	if currentUser := s.authService.GetCurrentUser(ctx); currentUser != nil {
+		subject.ID = currentUser.ID
+		subject.DepartmentID = currentUser.DepartmentID
+	}
+
+	decision := s.engine.Evaluate(models.Request{
+		Subject:  subject,
+		Action:   action,
+		Resource: resource,
+		Environment: models.Environment{
+			Impersonating: s.authService.GetImpersonation(ctx) != nil,
+			ApiKey:        s.authService.GetCurrentApiKey(ctx) != nil,
+		},
+	})
+	if !decision.Allowed {
+		s.logger.Info("Policy denied request",
+			zap.Uint("subjectId", subject.ID),
+			zap.String("resource", resource.Type),
+			zap.String("action", action),
+			zap.String("reason", decision.Reason),
+		)
+	}
+	return decision
+}
+
+func (s *PolicyService) Rules() []models.Rule {
+	return s.engine.Rules()
+}
+
+// Explain loads subject and resource like a real request would have them
+func (s *PolicyService) Explain(payload dtos.ExplainRequest) (*models.Explanation, error) {
+	if !s.engine.HasRules(payload.Resource, payload.Action) {
+		return nil, ErrUnknownPolicy
+	}
+
+	var subjectUser *user_models.User
+	if err := user_models.ValidScope(s.db.DB()).First(&subjectUser, payload.SubjectID).Error; err != nil {
+		return nil, err
+	}
+	abilities, err := s.cache.UserAbilities(subjectUser)
+	if err != nil {
+		s.logger.Error("Cannot get abilities of subject", zap.Error(err))
+		return nil, err
+	}
+
+	resource, err := s.loadResource(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := s.engine.Explain(models.Request{
+		Subject: models.Subject{
+			ID:           subjectUser.ID,
+			DepartmentID: subjectUser.DepartmentID,
+			Abilities:    abilities,
+		},
+		Action:   payload.Action,
+		Resource: *resource,
+		Environment: models.Environment{
+			Impersonating: payload.Impersonating,
+			ApiKey:        payload.ApiKey,
+		},
+	})
+	return &explanation, nil
+}
+
+func (s *PolicyService) loadResource(payload dtos.ExplainRequest) (*models.Resource, error) {
+	if payload.Resource == constants.POLICY_RESOURCE_LEAVE && payload.ResourceID != nil {
+		var leave *attendance_models.Leave
+		if err := attendance_models.ValidLeaveScope(s.db.DB()).Preload("User").First(&leave, *payload.ResourceID).Error; err != nil {
+			return nil, err
+		}
+		managerID, err := user_models.AppliedManagerID(s.db.DB(), leave.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.Resource{
+			Type:         payload.Resource,
+			ID:           leave.ID,
+			OwnerID:      leave.UserID,
+			DepartmentID: leave.User.DepartmentID,
+			ManagerID:    managerID,
+		}, nil
+	}
+
+	if payload.OwnerID == nil {
+		return nil, ErrMissingResource
+	}
+	var owner *user_models.User
+	if err := user_models.ValidScope(s.db.DB()).First(&owner, *payload.OwnerID).Error; err != nil {
+		return nil, err
+	}
+	managerID, err := user_models.AppliedManagerID(s.db.DB(), owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Resource{
+		Type:         payload.Resource,
+		OwnerID:      owner.ID,
+		DepartmentID: owner.DepartmentID,
+		ManagerID:    managerID,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	auth_constants "hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/policy/constants"
+	"hr-system-go/internal/policy/models"
+)
+
+// DefaultRules are authorization rules of the system, routes still require their ability first
+func DefaultRules() []models.Rule {
+	rules := []models.Rule{}
+
+	for _, action := range []string{constants.POLICY_ACTION_READ, constants.POLICY_ACTION_UPDATE, constants.POLICY_ACTION_DELETE} {
+		rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_USER, action, auth_constants.ABILITY_ALL_GRANTS_USER)...)
+	}
+	rules = append(rules,
+		deny(constants.POLICY_RESOURCE_USER, constants.POLICY_ACTION_CHANGE_SIGN_IN, "self",
+			"Sign in method is decided by administrators, users cannot change their own", IsOwner()),
+		allow(constants.POLICY_RESOURCE_USER, constants.POLICY_ACTION_CHANGE_SIGN_IN, "all_grants",
+			"User managers can change sign in method of others", HasAbility(auth_constants.ABILITY_ALL_GRANTS_USER)),
+	)
+
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_LEAVE, constants.POLICY_ACTION_READ, auth_constants.ABILITY_ALL_GRANTS_LEAVE)...)
+	for _, action := range []string{constants.POLICY_ACTION_CREATE, constants.POLICY_ACTION_UPDATE, constants.POLICY_ACTION_DELETE} {
+		rules = append(rules, ownerOnly(constants.POLICY_RESOURCE_LEAVE, action))
+	}
+	rules = append(rules,
+		deny(constants.POLICY_RESOURCE_LEAVE, constants.POLICY_ACTION_APPROVE, "self",
+			"Users cannot approve their own leave", IsOwner()),
+		allow(constants.POLICY_RESOURCE_LEAVE, constants.POLICY_ACTION_APPROVE, "admin",
+			"Admins can approve leave of anyone", HasAbility(auth_constants.ABILITY_ADMIN)),
+		allow(constants.POLICY_RESOURCE_LEAVE, constants.POLICY_ACTION_APPROVE, "manager",
+			"Managers can approve leave of their direct reports", IsManager()),
+	)
+
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_CLOCK_RECORD, constants.POLICY_ACTION_READ, auth_constants.ABILITY_ALL_GRANTS_CLOCK_RECORD)...)
+	rules = append(rules, ownerOnly(constants.POLICY_RESOURCE_CLOCK_RECORD, constants.POLICY_ACTION_CREATE))
+
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_PROFILE, constants.POLICY_ACTION_READ, auth_constants.ABILITY_READ_PERSONAL_DATA)...)
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_PROFILE, constants.POLICY_ACTION_UPDATE, auth_constants.ABILITY_WRITE_PERSONAL_DATA)...)
+
+	// directory is read by every authenticated user, only entries are guarded
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_DIRECTORY, constants.POLICY_ACTION_UPDATE, auth_constants.ABILITY_WRITE_EMPLOYMENT)...)
+
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_CHECKLIST, constants.POLICY_ACTION_READ, auth_constants.ABILITY_WRITE_EMPLOYMENT)...)
+
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_CONTRACT, constants.POLICY_ACTION_READ, auth_constants.ABILITY_WRITE_EMPLOYMENT)...)
+	rules = append(rules,
+		deny(constants.POLICY_RESOURCE_CONTRACT, constants.POLICY_ACTION_APPROVE, "self",
+			"Users cannot decide their own probation", IsOwner()),
+		allow(constants.POLICY_RESOURCE_CONTRACT, constants.POLICY_ACTION_APPROVE, "all_grants",
+			"Employment managers can decide probation of others", HasAbility(auth_constants.ABILITY_WRITE_EMPLOYMENT)),
+	)
+
+	rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_USER_PERMISSIONS, constants.POLICY_ACTION_READ, auth_constants.ABILITY_READ_ROLE)...)
+
+	for _, action := range []string{constants.POLICY_ACTION_READ, constants.POLICY_ACTION_CREATE, constants.POLICY_ACTION_DELETE} {
+		rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_API_KEY, action, auth_constants.ABILITY_ADMIN)...)
+	}
+	rules = append(rules,
+		// a leaked key must not be able to mint new keys
+		deny(constants.POLICY_RESOURCE_API_KEY, constants.POLICY_ACTION_CREATE, "api_key",
+			"Api keys cannot create api keys", UsingApiKey()),
+		// key would outlive impersonation session
+		deny(constants.POLICY_RESOURCE_API_KEY, constants.POLICY_ACTION_CREATE, "impersonation",
+			"Api keys cannot be created while impersonating", Impersonating()),
+	)
+
+	for _, action := range []string{constants.POLICY_ACTION_READ, constants.POLICY_ACTION_DELETE} {
+		rules = append(rules, ownerOrAbility(constants.POLICY_RESOURCE_SESSION, action, auth_constants.ABILITY_ADMIN)...)
+	}
+	rules = append(rules,
+		// impersonator would sign out the user being helped
+		deny(constants.POLICY_RESOURCE_SESSION, constants.POLICY_ACTION_DELETE, "impersonation",
+			"Sessions cannot be terminated while impersonating", Impersonating()),
+	)
+
+	return rules
+}
+
+func allow(resource string, action string, name string, description string, conditions ...models.Condition) models.Rule {
+	return newRule(constants.POLICY_EFFECT_ALLOW, resource, action, name, description, conditions)
+}
+
+func deny(resource string, action string, name string, description string, conditions ...models.Condition) models.Rule {
+	return newRule(constants.POLICY_EFFECT_DENY, resource, action, name, description, conditions)
+}
+
+func newRule(effect string, resource string, action string, name string, description string, conditions []models.Condition) models.Rule {
+	return models.Rule{
+		Name:        resource + "." + action + "." + name,
+		Resource:    resource,
+		Action:      action,
+		Effect:      effect,
+		Description: description,
+		Conditions:  conditions,
+	}
+}
+
+func ownerOnly(resource string, action string) models.Rule {
+	return allow(resource, action, "owner", "Users can manage their own records", IsOwner())
+}
+
+func ownerOrAbility(resource string, action string, ability string) []models.Rule {
+	return []models.Rule{
+		allow(resource, action, "owner", "Users can manage their own records", IsOwner()),
+		allow(resource, action, "all_grants", "Ability grants access to records of all users", HasAbility(ability)),
+	}
+}
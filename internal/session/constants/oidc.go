@@ -0,0 +1,21 @@
+package constants
+
+const (
+	OIDC_SCOPE_OPENID  = "openid"
+	OIDC_SCOPE_PROFILE = "profile"
+	OIDC_SCOPE_EMAIL   = "email"
+)
+
+const (
+	OAUTH_ERROR_INVALID_REQUEST         = "invalid_request"
+	OAUTH_ERROR_INVALID_CLIENT          = "invalid_client"
+	OAUTH_ERROR_INVALID_GRANT           = "invalid_grant"
+	OAUTH_ERROR_INVALID_SCOPE           = "invalid_scope"
+	OAUTH_ERROR_INVALID_TOKEN           = "invalid_token"
+	OAUTH_ERROR_ACCESS_DENIED           = "access_denied"
+	OAUTH_ERROR_UNSUPPORTED_GRANT_TYPE  = "unsupported_grant_type"
+	OAUTH_ERROR_UNSUPPORTED_RESPONSE    = "unsupported_response_type"
+	OAUTH_CODE_CHALLENGE_METHOD_S256    = "S256"
+	OAUTH_GRANT_TYPE_AUTHORIZATION_CODE = "authorization_code"
+	OAUTH_RESPONSE_TYPE_CODE            = "code"
+)
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_services "hr-system-go/internal/auth/services"
+	session_constants "hr-system-go/internal/session/constants"
+	"hr-system-go/internal/session/dtos"
+	"hr-system-go/internal/session/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type OIDCController struct {
+	logger      *logger.Logger
+	service     services.OIDCServiceInterface
+	authService auth_services.AuthServiceInterface
+}
+
+func NewOIDCController(logger *logger.Logger, service services.OIDCServiceInterface, authService auth_services.AuthServiceInterface) *OIDCController {
+	return &OIDCController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+func (c *OIDCController) RegisterRoutes(r *gin.Engine) {
+	r.GET("/.well-known/openid-configuration", c.Discovery)
+	oauthRoutes := r.Group("/oauth")
+	{
+		oauthRoutes.GET("/jwks", c.JWKS)
+		// called by HR System frontend with user's token after user agreed to sign in to the client
+		oauthRoutes.POST("/authorize", c.authService.AuthTokenWrapper(c.Authorize))
+		oauthRoutes.POST("/token", c.Token)
+		oauthRoutes.GET("/userinfo", c.UserInfo)
+	}
+	clientRoutes := r.Group("/api/oauth/clients")
+	{
+		clientRoutes.GET("", c.authService.AuthUserAbilityWrapper(c.listClients, constants.ABILITY_ADMIN))
+		clientRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.RegisterClient, constants.ABILITY_ADMIN))
+		clientRoutes.DELETE("/:id", c.authService.AuthUserAbilityWrapper(c.DeleteClient, constants.ABILITY_ADMIN))
+	}
+}
+
+func (c *OIDCController) Discovery(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.service.Discovery())
+}
+
+func (c *OIDCController) JWKS(ctx *gin.Context) {
+	keySet, err := c.service.JWKS()
+	if err != nil {
+		c.logger.Error("Cannot load JWKS", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Load Keys"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keySet)
+}
+
+func (c *OIDCController) Authorize(ctx *gin.Context) {
+	// API keys belong to integrations, not to a person signing in, and support must not sign in to apps as someone else
+	if c.authService.GetCurrentApiKey(ctx) != nil || c.authService.GetImpersonation(ctx) != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": session_constants.OAUTH_ERROR_ACCESS_DENIED})
+		return
+	}
+	var payload dtos.AuthorizeRequest
+	if err := ctx.ShouldBind(&payload); err != nil {
+		c.logger.Error("Cannot Parse Authorize Request", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": session_constants.OAUTH_ERROR_INVALID_REQUEST})
+		return
+	}
+
+	redirectTo, err := c.service.Authorize(c.authService.GetCurrentUser(ctx), payload)
+	if err != nil {
+		c.logger.Error("Cannot Authorize OAuth Client", zap.Error(err))
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.AuthorizeResponse{RedirectTo: redirectTo})
+}
+
+func (c *OIDCController) Token(ctx *gin.Context) {
+	var payload dtos.TokenRequest
+	if err := ctx.ShouldBind(&payload); err != nil {
+		c.logger.Error("Cannot Parse Token Request", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": session_constants.OAUTH_ERROR_INVALID_REQUEST})
+		return
+	}
+	if clientID, clientSecret, ok := ctx.Request.BasicAuth(); ok {
+		payload.ClientID = clientID
+		payload.ClientSecret = clientSecret
+	}
+
+	token, err := c.service.ExchangeCode(payload)
+	if err != nil {
+		c.logger.Error("Cannot Exchange Authorization Code", zap.Error(err))
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, token)
+}
+
+func (c *OIDCController) UserInfo(ctx *gin.Context) {
+	token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !found || token == "" {
+		ctx.Header("WWW-Authenticate", "Bearer")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": session_constants.OAUTH_ERROR_INVALID_TOKEN})
+		return
+	}
+
+	info, err := c.service.UserInfo(token)
+	if err != nil {
+		c.logger.Error("Cannot Get User Info", zap.Error(err))
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+func (c *OIDCController) listClients(ctx *gin.Context) {
+	clients, err := c.service.FindClients()
+	if err != nil {
+		c.logger.Error("Failed to Find OAuth Clients", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find OAuth Clients Error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewOAuthClientListResponse(clients))
+}
+
+func (c *OIDCController) RegisterClient(ctx *gin.Context) {
+	var payload dtos.RegisterOAuthClientRequest
+	errorMsg := "Failed to Register OAuth Client"
+	if err := ctx.ShouldBindJSON(&payload); err != nil || payload.Name == "" || len(payload.RedirectURIs) == 0 {
+		c.logger.Error("Cannot not parse register payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	client, secret, err := c.service.RegisterClient(payload)
+	if err != nil {
+		c.logger.Error("Cannot not register OAuth Client", zap.Error(err))
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewRegisteredOAuthClientResponse(client, secret))
+}
+
+func (c *OIDCController) DeleteClient(ctx *gin.Context) {
+	clientID, err := strconv.Atoi(ctx.Param("id"))
+	errorMsg := "Failed to Delete OAuth Client"
+	if err != nil {
+		c.logger.Error("Cannot not parse OAuth Client ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	err = c.service.DeleteClientByID(clientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": errorMsg})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not delete OAuth Client", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// OAuth errors use "error" and "error_description" fields from RFC 6749
+func respondOAuthError(ctx *gin.Context, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	switch oauthErr.Code {
+	case session_constants.OAUTH_ERROR_INVALID_CLIENT, session_constants.OAUTH_ERROR_INVALID_TOKEN:
+		status = http.StatusUnauthorized
+	}
+	ctx.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+}
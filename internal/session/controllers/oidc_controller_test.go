@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	auth_models "hr-system-go/internal/auth/models"
+	"hr-system-go/internal/session/constants"
+	"hr-system-go/internal/session/dtos"
+	"hr-system-go/internal/session/services"
+	user_models "hr-system-go/internal/user/models"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("OIDCController", func() {
+	var mockOIDCService *mock_services.MockOIDCService
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockOIDCService = &mock_services.MockOIDCService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		router = gin.Default()
+		NewOIDCController(mockLogger, mockOIDCService, mockAuthService).RegisterRoutes(router)
+	})
+
+	Describe("Authorize", func() {
+		It("should return client redirect with code", func() {
+			user := &user_models.User{Name: "Jane"}
+			mockAuthService.On("GetCurrentApiKey", mock.Anything).Return(nil)
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockOIDCService.On("Authorize", user, mock.MatchedBy(func(request dtos.AuthorizeRequest) bool {
+				return request.ClientID == "wiki" && request.CodeChallengeMethod == constants.OAUTH_CODE_CHALLENGE_METHOD_S256
+			})).Return("https://wiki.internal/callback?code=abc&state=xyz", nil)
+
+			form := url.Values{
+				"response_type":         {"code"},
+				"client_id":             {"wiki"},
+				"redirect_uri":          {"https://wiki.internal/callback"},
+				"scope":                 {"openid"},
+				"state":                 {"xyz"},
+				"code_challenge":        {"challenge"},
+				"code_challenge_method": {"S256"},
+			}
+			req, _ := http.NewRequest("POST", "/oauth/authorize", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.AuthorizeResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.RedirectTo).To(ContainSubstring("code=abc"))
+		})
+
+		It("should not authorize with api key", func() {
+			mockAuthService.On("GetCurrentApiKey", mock.Anything).Return(&auth_models.ApiKey{})
+
+			req, _ := http.NewRequest("POST", "/oauth/authorize?client_id=wiki", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Describe("Token", func() {
+		It("should use basic auth client credentials", func() {
+			token := &dtos.TokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: "id"}
+			mockOIDCService.On("ExchangeCode", mock.MatchedBy(func(request dtos.TokenRequest) bool {
+				return request.ClientID == "wiki" && request.ClientSecret == "secret" && request.Code == "abc"
+			})).Return(token, nil)
+
+			form := url.Values{"grant_type": {"authorization_code"}, "code": {"abc"}, "code_verifier": {"verifier"}}
+			req, _ := http.NewRequest("POST", "/oauth/token", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("wiki", "secret")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Cache-Control")).To(Equal("no-store"))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["id_token"]).To(Equal("id"))
+		})
+
+		It("should return OAuth error for invalid grant", func() {
+			mockOIDCService.On("ExchangeCode", mock.Anything).Return(nil, &services.OAuthError{Code: constants.OAUTH_ERROR_INVALID_GRANT, Description: "invalid or expired code"})
+
+			req, _ := http.NewRequest("POST", "/oauth/token", strings.NewReader("grant_type=authorization_code&code=used"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			var response map[string]string
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["error"]).To(Equal(constants.OAUTH_ERROR_INVALID_GRANT))
+		})
+	})
+
+	Describe("UserInfo", func() {
+		It("should require bearer token", func() {
+			req, _ := http.NewRequest("GET", "/oauth/userinfo", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should return claims of token owner", func() {
+			mockOIDCService.On("UserInfo", "access").Return(map[string]interface{}{"sub": "7"}, nil)
+
+			req, _ := http.NewRequest("GET", "/oauth/userinfo", nil)
+			req.Header.Set("Authorization", "Bearer access")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"sub":"7"`))
+		})
+	})
+})
@@ -1,8 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"hr-system-go/app/plugins/logger"
 	auth_service "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_dtos "hr-system-go/internal/customfield/dtos"
+	customfield_services "hr-system-go/internal/customfield/services"
+	session_services "hr-system-go/internal/session/services"
 	user_models "hr-system-go/internal/user/models"
 	"hr-system-go/internal/user/services"
 	"net/http"
@@ -13,30 +18,54 @@ import (
 )
 
 type SessionsController struct {
-	logger      *logger.Logger
-	service     services.UserServiceInterface
-	authService auth_service.AuthServiceInterface
+	logger              *logger.Logger
+	service             services.UserServiceInterface
+	authService         auth_service.AuthServiceInterface
+	externalOIDCService session_services.ExternalOIDCServiceInterface
+	invitationService   services.InvitationServiceInterface
+	emailService        services.EmailVerificationServiceInterface
+	passwordService     services.PasswordResetServiceInterface
+	customFieldService  customfield_services.CustomFieldServiceInterface
 }
 
-func NewSessionsController(logger *logger.Logger, service services.UserServiceInterface, authService auth_service.AuthServiceInterface) *SessionsController {
+func NewSessionsController(
+	logger *logger.Logger,
+	service services.UserServiceInterface,
+	authService auth_service.AuthServiceInterface,
+	externalOIDCService session_services.ExternalOIDCServiceInterface,
+	invitationService services.InvitationServiceInterface,
+	emailService services.EmailVerificationServiceInterface,
+	passwordService services.PasswordResetServiceInterface,
+	customFieldService customfield_services.CustomFieldServiceInterface,
+) *SessionsController {
 	return &SessionsController{
-		logger:      logger,
-		service:     service,
-		authService: authService,
+		logger:              logger,
+		service:             service,
+		authService:         authService,
+		externalOIDCService: externalOIDCService,
+		invitationService:   invitationService,
+		emailService:        emailService,
+		passwordService:     passwordService,
+		customFieldService:  customFieldService,
 	}
 }
 
 func (c *SessionsController) RegisterRoutes(r *gin.Engine) {
 	r.POST("api/register", c.SignUp)
 	r.POST("api/login", c.SignIn)
+	r.GET("api/login/oidc", c.ExternalSignIn)
+	r.GET("api/login/oidc/callback", c.ExternalSignInCallback)
 	r.POST("api/passwordResetRequest", c.PasswordResetRequest)
-	r.POST("api/resetPassword", c.authService.AuthTokenWrapper(c.ResetPassword))
+	// link is opened from the mailbox, the mailed token is the only credential
+	r.POST("api/resetPassword", c.ResetPassword)
+	r.POST("api/changePassword", c.authService.AuthTokenWrapper(c.ChangePassword))
 }
 
 type sessionBody struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Name         string                 `json:"name"`
+	Email        string                 `json:"email"`
+	Password     string                 `json:"password"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
 }
 
 type passwordResetRequestBody struct {
@@ -44,34 +73,74 @@ type passwordResetRequestBody struct {
 }
 
 type resetPasswordBody struct {
-	Email       string `json:"email"`
+	Token       string `json:"token" binding:"required"`
 	NewPassword string `json:"newPassword"`
 }
 
+type changePasswordBody struct {
+	NewPassword string `json:"newPassword"`
+}
+
+// SignUp is closed unless REGISTRATION_OPEN is set, employees join through HR invitations
 func (c *SessionsController) SignUp(ctx *gin.Context) {
+	if !c.invitationService.OpenRegistrationEnabled() {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Registration requires an invitation"})
+		return
+	}
+
 	var payload sessionBody
+	errorMsg := "Failed to register user"
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		c.logger.Error("Cannot Parse Body", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// new user has no abilities, fields needing one are left to HR
+	if len(payload.CustomFields) > 0 {
+		fields, err := c.customFieldService.FindFields(customfield_constants.CUSTOM_FIELD_ENTITY_USER)
+		if err != nil {
+			c.logger.Error("Cannot not find custom fields", zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+			return
+		}
+		if forbidden := (customfield_dtos.ValueAccess{}).ForbiddenValues(fields, payload.CustomFields); len(forbidden) > 0 {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": forbidden})
+			return
+		}
+	}
+
 	user := user_models.User{
 		Name:  payload.Name,
 		Email: payload.Email,
 	}
-	if err := c.service.RegisterUser(&user, payload.Password); err != nil {
+	if err := c.service.RegisterUser(ctx, &user, payload.Password, payload.CustomFields); err != nil {
 		c.logger.Error("Cannot Register User", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to register user"})
+		respondPasswordError(ctx, err, errorMsg)
+		return
+	}
+	// account exists already, user can ask for another link when this one is lost
+	if err := c.emailService.SendVerification(ctx, &user); err != nil {
+		c.logger.Error("Cannot Send Email Verification", zap.Error(err))
+	}
+	// same gate as SignIn, user signs in after confirming the mailed link
+	if c.emailService.VerificationRequired() && !user.EmailVerified() {
+		ctx.JSON(http.StatusOK, gin.H{"emailNotVerified": true})
 		return
 	}
 
-	token, _ := c.authService.GenerateToken(user.ID, user.Name)
+	token, err := c.authService.GenerateToken(ctx, user.ID, user.Name)
+	if err != nil {
+		c.logger.Error("Cannot not generate token", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{"token": token})
 }
 
 func (c *SessionsController) SignIn(ctx *gin.Context) {
 	var payload sessionBody
+	errorMsg := "Failed to sign in"
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		c.logger.Error("Cannot Parse Body", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -86,15 +155,83 @@ func (c *SessionsController) SignIn(ctx *gin.Context) {
 		return
 	}
 
+	if user.IsServiceAccount() {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordEncrypt), []byte(payload.Password)); err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	token, _ := c.authService.GenerateToken(user.ID, user.Name)
+	// told only after password matched, otherwise anyone could learn who signs in with identity provider
+	if user.PasswordLoginDisabled {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Password login is disabled, sign in with identity provider", "passwordLoginDisabled": true})
+		return
+	}
+
+	if user.Terminated() {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is no longer active", "terminated": true})
+		return
+	}
+
+	if c.emailService.VerificationRequired() && !user.EmailVerified() {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Email is not verified", "emailNotVerified": true})
+		return
+	}
+
+	// expired password must be changed through the mailed passwordResetRequest link before login
+	if c.service.IsPasswordExpired(user) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Password expired", "passwordExpired": true})
+		return
+	}
+
+	token, err := c.authService.GenerateToken(ctx, user.ID, user.Name)
+	if err != nil {
+		c.logger.Error("Cannot not generate token", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{"token": token})
 }
 
+// ExternalSignIn returns identity provider URL the client should open to sign in
+func (c *SessionsController) ExternalSignIn(ctx *gin.Context) {
+	authorizationURL, err := c.externalOIDCService.AuthorizationURL()
+	if err != nil {
+		c.logger.Error("Cannot Start External Sign In", zap.Error(err))
+		respondExternalSignInError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"authorizationUrl": authorizationURL})
+}
+
+// ExternalSignInCallback is the redirect URI registered at identity provider
+func (c *SessionsController) ExternalSignInCallback(ctx *gin.Context) {
+	errorMsg := "Failed to sign in"
+	if errorCode := ctx.Query("error"); errorCode != "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider rejected sign in", "reason": errorCode})
+		return
+	}
+
+	user, err := c.externalOIDCService.Login(ctx.Query("code"), ctx.Query("state"))
+	if err != nil {
+		c.logger.Error("Cannot Finish External Sign In", zap.Error(err))
+		respondExternalSignInError(ctx, err)
+		return
+	}
+
+	token, err := c.authService.GenerateToken(ctx, user.ID, user.Name)
+	if err != nil {
+		c.logger.Error("Cannot not generate token", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// PasswordResetRequest answers the same whether or not email belongs to an account
 func (c *SessionsController) PasswordResetRequest(ctx *gin.Context) {
 	var payload passwordResetRequestBody
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
@@ -103,18 +240,13 @@ func (c *SessionsController) PasswordResetRequest(ctx *gin.Context) {
 		return
 	}
 
-	user, err := c.service.FindUserByEmail(payload.Email)
-	if err != nil {
-		c.logger.Error("Cannot Find User by Email")
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to Send Reset Request"})
+	if err := c.passwordService.RequestPasswordReset(ctx, payload.Email); err != nil {
+		c.logger.Error("Cannot Send Reset Request", zap.Error(err))
 	}
-
-	// token is for post resetPassword action
-	// todo: send token by mailer
-	token, _ := c.authService.GenerateToken(user.ID, user.Name)
-	ctx.JSON(http.StatusOK, gin.H{"token": token})
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "If the email belongs to an account, a password reset link was sent to it"})
 }
 
+// ResetPassword sets new password with the token mailed by PasswordResetRequest
 func (c *SessionsController) ResetPassword(ctx *gin.Context) {
 	var payload resetPasswordBody
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
@@ -123,17 +255,73 @@ func (c *SessionsController) ResetPassword(ctx *gin.Context) {
 		return
 	}
 
+	if _, err := c.passwordService.ResetPassword(ctx, payload.Token, payload.NewPassword); err != nil {
+		c.logger.Error("Cannot Reset Password", zap.Error(err))
+		if errors.Is(err, services.ErrInvalidPasswordResetToken) {
+			ctx.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		respondPasswordError(ctx, err, "Bad Request")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *SessionsController) ChangePassword(ctx *gin.Context) {
+	var payload changePasswordBody
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot Parse Body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	user := c.authService.GetCurrentUser(ctx)
 	if user == nil {
 		c.logger.Error("Cannot Get Current User")
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Bad Request"})
+		return
+	}
+	if c.authService.GetImpersonation(ctx) != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Password cannot be changed while impersonating"})
+		return
 	}
 
-	if err := c.service.UpdatePassword(user, payload.NewPassword); err != nil {
-		c.logger.Error("Cannot Find User", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Bad Request"})
+	if err := c.service.UpdatePassword(ctx, user, payload.NewPassword); err != nil {
+		c.logger.Error("Cannot Update Password", zap.Error(err))
+		respondPasswordError(ctx, err, "Bad Request")
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
+
+func respondPasswordError(ctx *gin.Context, err error, errorMsg string) {
+	var policyErr *services.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Password does not satisfy policy", "violations": policyErr.Violations})
+		return
+	}
+	if errors.Is(err, customfield_services.ErrCustomFieldValue) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+}
+
+func respondExternalSignInError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, session_services.ErrExternalLoginDisabled):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "External sign in is not configured"})
+	case errors.Is(err, session_services.ErrExternalLoginState):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Sign in expired, please try again"})
+	case errors.Is(err, session_services.ErrExternalIdentity):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	case errors.Is(err, session_services.ErrExternalUserNotProvisioned):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "No account is linked to this identity"})
+	case errors.Is(err, session_services.ErrExternalProviderUnavailable):
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+	}
+}
@@ -3,9 +3,17 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
+	auth_constants "hr-system-go/internal/auth/constants"
+	auth_models "hr-system-go/internal/auth/models"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	session_services "hr-system-go/internal/session/services"
+	"hr-system-go/internal/user/constants"
 	user_models "hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
 	mock_services "hr-system-go/mocks/services"
 	"net/http"
 	"net/http/httptest"
@@ -27,6 +35,11 @@ var (
 	sessionController *SessionsController
 	mockUserService   *mock_services.MockUserService
 	mockAuthService   *mock_services.MockAuthService
+	mockExternalOIDC  *mock_services.MockExternalOIDCService
+	mockInvitation    *mock_services.MockInvitationService
+	mockEmail         *mock_services.MockEmailVerificationService
+	mockPasswordReset *mock_services.MockPasswordResetService
+	mockCustomField   *mock_services.MockCustomFieldService
 	router            *gin.Engine
 	mockEnv           *env.Env
 	mockLogger        *logger.Logger
@@ -39,12 +52,18 @@ var _ = Describe("UserController", func() {
 		mockLogger = logger.NewLogger(mockEnv)
 		mockUserService = &mock_services.MockUserService{}
 		mockAuthService = &mock_services.MockAuthService{}
-		sessionController = NewSessionsController(mockLogger, mockUserService, mockAuthService)
+		mockExternalOIDC = &mock_services.MockExternalOIDCService{}
+		mockInvitation = &mock_services.MockInvitationService{}
+		mockEmail = &mock_services.MockEmailVerificationService{}
+		mockPasswordReset = &mock_services.MockPasswordResetService{}
+		mockCustomField = &mock_services.MockCustomFieldService{}
+		sessionController = NewSessionsController(mockLogger, mockUserService, mockAuthService, mockExternalOIDC, mockInvitation, mockEmail, mockPasswordReset, mockCustomField)
 		router = gin.Default()
 		sessionController.RegisterRoutes(router)
 	})
 	Describe("SignUp", func() {
 		It("should register a new user and return a token", func() {
+			mockInvitation.On("OpenRegistrationEnabled").Return(true)
 			payload := sessionBody{
 				Name:     "John Doe",
 				Email:    "john@example.com",
@@ -55,7 +74,9 @@ var _ = Describe("UserController", func() {
 				Email: payload.Email,
 			}
 
-			mockUserService.On("RegisterUser", user, payload.Password).Return(nil)
+			mockUserService.On("RegisterUser", user, payload.Password, map[string]interface{}(nil)).Return(nil)
+			mockEmail.On("SendVerification", user).Return(nil)
+			mockEmail.On("VerificationRequired").Return(false)
 			mockAuthService.On("GenerateToken", mock.AnythingOfType("uint"), payload.Name).Return("token123", nil)
 
 			jsonPayload, _ := json.Marshal(payload)
@@ -71,6 +92,82 @@ var _ = Describe("UserController", func() {
 			json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(response["token"]).To(Equal("token123"))
 		})
+
+		It("should not return a token before email is verified when verification is required", func() {
+			mockInvitation.On("OpenRegistrationEnabled").Return(true)
+			payload := sessionBody{
+				Name:     "John Doe",
+				Email:    "john@example.com",
+				Password: "password123",
+			}
+			user := &user_models.User{
+				Name:  payload.Name,
+				Email: payload.Email,
+			}
+
+			mockUserService.On("RegisterUser", user, payload.Password, map[string]interface{}(nil)).Return(nil)
+			mockEmail.On("SendVerification", user).Return(nil)
+			mockEmail.On("VerificationRequired").Return(true)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response).NotTo(HaveKey("token"))
+			Expect(response["emailNotVerified"]).To(BeTrue())
+			mockAuthService.AssertNotCalled(GinkgoT(), "GenerateToken", mock.Anything, mock.Anything)
+		})
+
+		It("should reject registration without invitation when open registration is disabled", func() {
+			mockInvitation.On("OpenRegistrationEnabled").Return(false)
+			payload := sessionBody{
+				Name:     "John Doe",
+				Email:    "john@example.com",
+				Password: "password123",
+			}
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockUserService.AssertNotCalled(GinkgoT(), "RegisterUser", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should refuse custom fields needing an ability on registration", func() {
+			mockInvitation.On("OpenRegistrationEnabled").Return(true)
+			mockCustomField.On("FindFields", customfield_constants.CUSTOM_FIELD_ENTITY_USER).Return([]customfield_models.CustomField{
+				{Key: "tShirtSize", Type: customfield_constants.CUSTOM_FIELD_TYPE_TEXT},
+				{Key: "badgeNumber", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: auth_constants.ABILITY_READ_PERSONAL_DATA},
+			}, nil)
+			payload := sessionBody{
+				Name:         "John Doe",
+				Email:        "john@example.com",
+				Password:     "password123",
+				CustomFields: map[string]interface{}{"tShirtSize": "M", "badgeNumber": 1234},
+			}
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring("badgeNumber"))
+			mockUserService.AssertNotCalled(GinkgoT(), "RegisterUser", mock.Anything, mock.Anything, mock.Anything)
+		})
 	})
 
 	Describe("SignIn", func() {
@@ -84,6 +181,8 @@ var _ = Describe("UserController", func() {
 			user := &user_models.User{Name: "John Doe", Email: "john@example.com", PasswordEncrypt: string(hashedPassword)}
 			user.ID = uint(1)
 			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
+			mockUserService.On("IsPasswordExpired", user).Return(false)
+			mockEmail.On("VerificationRequired").Return(false)
 			mockAuthService.On("GenerateToken", user.ID, user.Name).Return("token123", nil)
 
 			jsonPayload, _ := json.Marshal(payload)
@@ -99,23 +198,215 @@ var _ = Describe("UserController", func() {
 			json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(response["token"]).To(Equal("token123"))
 		})
-	})
 
-	Describe("PasswordResetRequest", func() {
-		It("should send a password reset request and return a token", func() {
-			payload := passwordResetRequestBody{
-				Email: "john@example.com",
+		It("should not return empty token when session cannot be saved", func() {
+			payload := sessionBody{
+				Email:    "john@example.com",
+				Password: "password123",
 			}
 
-			user := &user_models.User{Name: "John Doe", Email: "john@example.com"}
+			hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+			user := &user_models.User{Name: "John Doe", Email: "john@example.com", PasswordEncrypt: string(hashedPassword)}
+			user.ID = uint(1)
+			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
+			mockUserService.On("IsPasswordExpired", user).Return(false)
+			mockEmail.On("VerificationRequired").Return(false)
+			mockAuthService.On("GenerateToken", user.ID, user.Name).Return("", errors.New("connection refused"))
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+			Expect(w.Body.String()).NotTo(ContainSubstring("token"))
+		})
+
+		It("should reject login of service account", func() {
+			payload := sessionBody{
+				Email:    "payroll@service-account.invalid",
+				Password: "password123",
+			}
+
+			hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+			user := &user_models.User{Name: "Payroll", Email: payload.Email, PasswordEncrypt: string(hashedPassword), Type: constants.USER_TYPE_SERVICE_ACCOUNT}
+			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject login when password is expired", func() {
+			payload := sessionBody{
+				Email:    "john@example.com",
+				Password: "password123",
+			}
+
+			hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+			user := &user_models.User{Name: "John Doe", Email: "john@example.com", PasswordEncrypt: string(hashedPassword)}
 			user.ID = uint(1)
+			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
+			mockUserService.On("IsPasswordExpired", user).Return(true)
+			mockEmail.On("VerificationRequired").Return(false)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
 
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["passwordExpired"]).To(BeTrue())
+			mockAuthService.AssertNotCalled(GinkgoT(), "GenerateToken", mock.Anything, mock.Anything)
+		})
+
+		It("should reject login of unverified email when verification is required", func() {
+			payload := sessionBody{
+				Email:    "john@example.com",
+				Password: "password123",
+			}
+
+			hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+			user := &user_models.User{Name: "John Doe", Email: "john@example.com", PasswordEncrypt: string(hashedPassword)}
+			user.ID = uint(1)
 			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
-			mockAuthService.On("GenerateToken", user.ID, user.Name).Return("token123", nil)
+			mockEmail.On("VerificationRequired").Return(true)
 
 			jsonPayload, _ := json.Marshal(payload)
-			req, _ := http.NewRequest("POST", "/api/passwordResetRequest", bytes.NewBuffer(jsonPayload))
-			// req.Header.Set("Content-Type", "application/json")
+			req, _ := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["emailNotVerified"]).To(BeTrue())
+			mockAuthService.AssertNotCalled(GinkgoT(), "GenerateToken", mock.Anything, mock.Anything)
+		})
+
+		It("should reject login of terminated user", func() {
+			payload := sessionBody{
+				Email:    "john@example.com",
+				Password: "password123",
+			}
+
+			hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+			user := &user_models.User{Name: "John Doe", Email: payload.Email, PasswordEncrypt: string(hashedPassword), LifecycleState: constants.LIFECYCLE_STATE_TERMINATED}
+			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["terminated"]).To(BeTrue())
+			mockAuthService.AssertNotCalled(GinkgoT(), "GenerateToken", mock.Anything, mock.Anything)
+		})
+
+		It("should reject password login of user signing in with identity provider", func() {
+			payload := sessionBody{
+				Email:    "john@example.com",
+				Password: "password123",
+			}
+
+			hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+			user := &user_models.User{Name: "John Doe", Email: payload.Email, PasswordEncrypt: string(hashedPassword), PasswordLoginDisabled: true}
+			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["passwordLoginDisabled"]).To(BeTrue())
+			mockAuthService.AssertNotCalled(GinkgoT(), "GenerateToken", mock.Anything, mock.Anything)
+		})
+
+		It("should not tell password login is disabled to wrong password", func() {
+			payload := sessionBody{
+				Email:    "john@example.com",
+				Password: "wrong-password",
+			}
+
+			hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+			user := &user_models.User{Name: "John Doe", Email: payload.Email, PasswordEncrypt: string(hashedPassword), PasswordLoginDisabled: true}
+			mockUserService.On("FindUserByEmail", payload.Email).Return(user, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			Expect(w.Body.String()).NotTo(ContainSubstring("passwordLoginDisabled"))
+		})
+	})
+
+	Describe("ExternalSignIn", func() {
+		It("should return authorization url of identity provider", func() {
+			mockExternalOIDC.On("AuthorizationURL").Return("https://idp.example.com/authorize?state=abc", nil)
+
+			req, _ := http.NewRequest("GET", "/api/login/oidc", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response map[string]string
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["authorizationUrl"]).To(Equal("https://idp.example.com/authorize?state=abc"))
+		})
+
+		It("should return not found when external sign in is not configured", func() {
+			mockExternalOIDC.On("AuthorizationURL").Return("", session_services.ErrExternalLoginDisabled)
+
+			req, _ := http.NewRequest("GET", "/api/login/oidc", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("ExternalSignInCallback", func() {
+		It("should return token of linked user", func() {
+			user := &user_models.User{Name: "John Doe"}
+			user.ID = uint(1)
+			mockExternalOIDC.On("Login", "code123", "state123").Return(user, nil)
+			mockAuthService.On("GenerateToken", user.ID, user.Name).Return("token123", nil)
+
+			req, _ := http.NewRequest("GET", "/api/login/oidc/callback?code=code123&state=state123", nil)
 			w := httptest.NewRecorder()
 
 			router.ServeHTTP(w, req)
@@ -126,22 +417,173 @@ var _ = Describe("UserController", func() {
 			json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(response["token"]).To(Equal("token123"))
 		})
+
+		It("should reject sign in cancelled at identity provider", func() {
+			req, _ := http.NewRequest("GET", "/api/login/oidc/callback?error=access_denied&state=state123", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			mockExternalOIDC.AssertNotCalled(GinkgoT(), "Login", mock.Anything, mock.Anything)
+		})
+
+		It("should map login errors to status", func() {
+			cases := map[error]int{
+				session_services.ErrExternalLoginState:          http.StatusBadRequest,
+				session_services.ErrExternalIdentity:            http.StatusUnauthorized,
+				session_services.ErrExternalUserNotProvisioned:  http.StatusForbidden,
+				session_services.ErrExternalProviderUnavailable: http.StatusBadGateway,
+			}
+			for loginErr, status := range cases {
+				mockExternalOIDC = &mock_services.MockExternalOIDCService{}
+				mockExternalOIDC.On("Login", "code123", "state123").Return(nil, loginErr)
+				router = gin.Default()
+				NewSessionsController(mockLogger, mockUserService, mockAuthService, mockExternalOIDC, mockInvitation, mockEmail, mockPasswordReset, mockCustomField).RegisterRoutes(router)
+
+				req, _ := http.NewRequest("GET", "/api/login/oidc/callback?code=code123&state=state123", nil)
+				w := httptest.NewRecorder()
+
+				router.ServeHTTP(w, req)
+
+				Expect(w.Code).To(Equal(status))
+			}
+		})
+	})
+
+	Describe("PasswordResetRequest", func() {
+		It("should mail a reset link without returning a token", func() {
+			payload := passwordResetRequestBody{
+				Email: "john@example.com",
+			}
+			mockPasswordReset.On("RequestPasswordReset", payload.Email).Return(nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/passwordResetRequest", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(w.Body.String()).NotTo(ContainSubstring("token"))
+			mockAuthService.AssertNotCalled(GinkgoT(), "GenerateToken", mock.Anything, mock.Anything)
+		})
+
+		It("should answer the same when sending fails", func() {
+			mockPasswordReset.On("RequestPasswordReset", "john@example.com").Return(errors.New("smtp down"))
+			mockPasswordReset.On("RequestPasswordReset", "nobody@example.com").Return(nil)
+
+			send := func(email string) *httptest.ResponseRecorder {
+				jsonPayload, _ := json.Marshal(passwordResetRequestBody{Email: email})
+				req, _ := http.NewRequest("POST", "/api/passwordResetRequest", bytes.NewBuffer(jsonPayload))
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w
+			}
+			failed := send("john@example.com")
+			unknown := send("nobody@example.com")
+
+			Expect(failed.Code).To(Equal(unknown.Code))
+			Expect(failed.Body.String()).To(Equal(unknown.Body.String()))
+		})
 	})
 
 	Describe("ResetPassword", func() {
-		It("should reset the user's password", func() {
+		It("should reset the password with mailed token without signing in", func() {
 			payload := resetPasswordBody{
-				Email:       "john@example.com",
+				Token:       "reset123",
 				NewPassword: "newpassword123",
 			}
 
+			user := &user_models.User{Name: "John Doe", Email: "john@example.com"}
+			user.ID = uint(1)
+			mockPasswordReset.On("ResetPassword", payload.Token, payload.NewPassword).Return(user, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/resetPassword", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(w.Body.String()).To(BeEmpty())
+			mockAuthService.AssertNotCalled(GinkgoT(), "GenerateToken", mock.Anything, mock.Anything)
+		})
+
+		It("should refuse invalid or used token", func() {
+			payload := resetPasswordBody{
+				Token:       "reset123",
+				NewPassword: "newpassword123",
+			}
+			mockPasswordReset.On("ResetPassword", payload.Token, payload.NewPassword).Return(nil, services.ErrInvalidPasswordResetToken)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/resetPassword", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusGone))
+		})
+
+		It("should not accept a session instead of mailed token", func() {
+			payload := changePasswordBody{NewPassword: "newpassword123"}
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/resetPassword", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer token123")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			mockPasswordReset.AssertNotCalled(GinkgoT(), "ResetPassword", mock.Anything, mock.Anything)
+		})
+
+		It("should return violated password rules", func() {
+			payload := resetPasswordBody{
+				Token:       "reset123",
+				NewPassword: "short",
+			}
+
+			policyErr := &services.PasswordPolicyError{Violations: []services.PasswordViolation{
+				{Rule: constants.PASSWORD_RULE_MIN_LENGTH, Message: "password must be at least 8 characters long"},
+			}}
+			mockPasswordReset.On("ResetPassword", payload.Token, payload.NewPassword).Return(nil, policyErr)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/resetPassword", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			var response struct {
+				Violations []services.PasswordViolation `json:"violations"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Violations).To(HaveLen(1))
+			Expect(response.Violations[0].Rule).To(Equal(constants.PASSWORD_RULE_MIN_LENGTH))
+		})
+	})
+
+	Describe("ChangePassword", func() {
+		It("should change the password of current user", func() {
+			payload := changePasswordBody{NewPassword: "newpassword123"}
+
 			user := &user_models.User{Name: "John Doe", Email: "john@example.com"}
 			user.ID = uint(1)
 			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
 			mockUserService.On("UpdatePassword", user, payload.NewPassword).Return(nil)
 
 			jsonPayload, _ := json.Marshal(payload)
-			req, _ := http.NewRequest("POST", "/api/resetPassword", bytes.NewBuffer(jsonPayload))
+			req, _ := http.NewRequest("POST", "/api/changePassword", bytes.NewBuffer(jsonPayload))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
@@ -149,5 +591,22 @@ var _ = Describe("UserController", func() {
 
 			Expect(w.Code).To(Equal(http.StatusNoContent))
 		})
+
+		It("should not change password while impersonating", func() {
+			payload := changePasswordBody{NewPassword: "newpassword123"}
+			user := &user_models.User{Name: "John Doe"}
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(&auth_models.Impersonation{})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/changePassword", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockUserService.AssertNotCalled(GinkgoT(), "UpdatePassword", mock.Anything, mock.Anything)
+		})
 	})
 })
@@ -0,0 +1,90 @@
+package dtos
+
+import (
+	"hr-system-go/internal/session/models"
+)
+
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+type AuthorizeResponse struct {
+	// RedirectTo is where the browser should go next, with code or error in query
+	RedirectTo string `json:"redirectTo"`
+}
+
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	CodeVerifier string `form:"code_verifier"`
+}
+
+// TokenResponse follows OAuth2 field names
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+type OAuthClientListResponse struct {
+	Items []*OAuthClientResponse
+}
+
+type OAuthClientResponse struct {
+	Id           uint
+	ClientId     string
+	Name         string
+	RedirectUris []string
+	Confidential bool
+}
+
+// RegisteredOAuthClientResponse is the only response containing client secret
+type RegisteredOAuthClientResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:",omitempty"`
+}
+
+type RegisterOAuthClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirectUris"`
+	// public clients (SPA, mobile) cannot keep secret
+	Confidential bool `json:"confidential"`
+}
+
+func NewOAuthClientListResponse(clients []models.OAuthClient) *OAuthClientListResponse {
+	items := []*OAuthClientResponse{}
+	for _, client := range clients {
+		items = append(items, NewOAuthClientResponse(&client))
+	}
+
+	return &OAuthClientListResponse{Items: items}
+}
+
+func NewOAuthClientResponse(client *models.OAuthClient) *OAuthClientResponse {
+	return &OAuthClientResponse{
+		Id:           client.ID,
+		ClientId:     client.ClientID,
+		Name:         client.Name,
+		RedirectUris: client.RedirectURIs,
+		Confidential: client.IsConfidential(),
+	}
+}
+
+func NewRegisteredOAuthClientResponse(client *models.OAuthClient, secret string) *RegisteredOAuthClientResponse {
+	return &RegisteredOAuthClientResponse{
+		OAuthClientResponse: *NewOAuthClientResponse(client),
+		ClientSecret:        secret,
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	user_models "hr-system-go/internal/user/models"
+	"time"
+)
+
+// ExternalIdentity links a subject of an external identity provider to a user,
+// subject is stable while email at the provider may change
+type ExternalIdentity struct {
+	base_model.BaseModel
+	UserID      uint              `gorm:"not null;index"`
+	User        *user_models.User `gorm:"foreignKey:UserID"`
+	Issuer      string            `gorm:"not null;size:255;uniqueIndex:idx_issuer_subject"`
+	Subject     string            `gorm:"not null;size:255;uniqueIndex:idx_issuer_subject"`
+	Email       string
+	LastLoginAt *time.Time `gorm:"type:timestamp;default:null"`
+}
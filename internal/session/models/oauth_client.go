@@ -0,0 +1,36 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+
+	"gorm.io/gorm"
+)
+
+// OAuthClient is an internal app using "Sign in with HR System",
+// clients without SecretHash are public and rely on PKCE only
+type OAuthClient struct {
+	base_model.BaseModel
+	ClientID     string   `gorm:"not null;uniqueIndex;size:64"`
+	Name         string   `gorm:"not null"`
+	SecretHash   string   `gorm:"size:64"`
+	RedirectURIs []string `gorm:"serializer:json;type:text"`
+	Status       string   `gorm:"default:'active'"`
+}
+
+func ValidScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&OAuthClient{}).Where("status != ?", "removed")
+}
+
+func (c *OAuthClient) IsConfidential() bool {
+	return c.SecretHash != ""
+}
+
+// redirect URI must match a registered one exactly
+func (c *OAuthClient) AllowsRedirectURI(redirectURI string) bool {
+	for _, uri := range c.RedirectURIs {
+		if uri == redirectURI {
+			return true
+		}
+	}
+	return false
+}
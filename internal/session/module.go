@@ -4,6 +4,7 @@ import (
 	"hr-system-go/app"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/internal/session/controllers"
+	"hr-system-go/internal/session/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,12 +16,15 @@ type SessionModule struct {
 func (m *SessionModule) Controllers() []interface{} {
 	return []interface{}{
 		controllers.NewSessionsController,
+		controllers.NewOIDCController,
 		func(
 			r *gin.Engine,
 			c *controllers.SessionsController,
+			oc *controllers.OIDCController,
 			logger *logger.Logger,
 		) *SessionModule {
 			c.RegisterRoutes(r)
+			oc.RegisterRoutes(r)
 			logger.Info("= Session module init")
 			return m
 		},
@@ -28,5 +32,8 @@ func (m *SessionModule) Controllers() []interface{} {
 }
 
 func (m *SessionModule) Provide() []interface{} {
-	return []interface{}{}
+	return []interface{}{
+		services.NewOIDCService,
+		services.NewExternalOIDCService,
+	}
 }
@@ -0,0 +1,553 @@
+package services
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/app/plugins/redis"
+	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_services "hr-system-go/internal/customfield/services"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/session/constants"
+	"hr-system-go/internal/session/models"
+	user_constants "hr-system-go/internal/user/constants"
+	user_models "hr-system-go/internal/user/models"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ExternalOIDCStateTTL = 10 * time.Minute
+var ExternalOIDCHTTPTimeout = 10 * time.Second
+
+var (
+	ErrExternalLoginDisabled       = errors.New("external login is not configured")
+	ErrExternalLoginState          = errors.New("invalid or expired login state")
+	ErrExternalIdentity            = errors.New("invalid external identity")
+	ErrExternalUserNotProvisioned  = errors.New("no user is linked to external identity")
+	ErrExternalProviderUnavailable = errors.New("external identity provider is unavailable")
+)
+
+// ExternalOIDCConfig describes the identity provider users sign in with and how
+// its claims are mapped when users are created on first login
+type ExternalOIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+	// AutoCreateUsers creates unknown users on first login, otherwise they must exist with same email
+	AutoCreateUsers bool
+	// DisablePasswordLogin is applied to created users only
+	DisablePasswordLogin bool
+	DefaultRole          string
+	DefaultDepartment    string
+	RoleClaim            string
+	RoleMapping          map[string]string
+	DepartmentClaim      string
+	DepartmentMapping    map[string]string
+}
+
+type externalLoginState struct {
+	Nonce        string
+	CodeVerifier string
+}
+
+type externalProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type externalTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type ExternalOIDCServiceInterface interface {
+	Enabled() bool
+	AuthorizationURL() (string, error)
+	Login(code string, state string) (*user_models.User, error)
+}
+
+type ExternalOIDCService struct {
+	logger     *logger.Logger
+	db         *mysql.MySqlStore
+	rdb        *redis.RedisStore
+	config     ExternalOIDCConfig
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	metadata *externalProviderMetadata
+	keys     map[string]*rsa.PublicKey
+}
+
+func NewExternalOIDCService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, rdb *redis.RedisStore) ExternalOIDCServiceInterface {
+	scopes := strings.Fields(env.GetEnv("EXTERNAL_OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{constants.OIDC_SCOPE_OPENID, constants.OIDC_SCOPE_PROFILE, constants.OIDC_SCOPE_EMAIL}
+	}
+	return newExternalOIDCService(logger, db, rdb, ExternalOIDCConfig{
+		Issuer:               strings.TrimSuffix(env.GetEnv("EXTERNAL_OIDC_ISSUER"), "/"),
+		ClientID:             env.GetEnv("EXTERNAL_OIDC_CLIENT_ID"),
+		ClientSecret:         env.GetEnv("EXTERNAL_OIDC_CLIENT_SECRET"),
+		RedirectURI:          env.GetEnv("EXTERNAL_OIDC_REDIRECT_URI"),
+		Scopes:               scopes,
+		AutoCreateUsers:      env.GetEnvBool("EXTERNAL_OIDC_AUTO_CREATE_USERS", false),
+		DisablePasswordLogin: env.GetEnvBool("EXTERNAL_OIDC_DISABLE_PASSWORD_LOGIN", true),
+		DefaultRole:          env.GetEnv("EXTERNAL_OIDC_DEFAULT_ROLE"),
+		DefaultDepartment:    env.GetEnv("EXTERNAL_OIDC_DEFAULT_DEPARTMENT"),
+		RoleClaim:            env.GetEnv("EXTERNAL_OIDC_ROLE_CLAIM"),
+		RoleMapping:          parseClaimMapping(env.GetEnv("EXTERNAL_OIDC_ROLE_MAPPING")),
+		DepartmentClaim:      env.GetEnv("EXTERNAL_OIDC_DEPARTMENT_CLAIM"),
+		DepartmentMapping:    parseClaimMapping(env.GetEnv("EXTERNAL_OIDC_DEPARTMENT_MAPPING")),
+	})
+}
+
+func newExternalOIDCService(logger *logger.Logger, db *mysql.MySqlStore, rdb *redis.RedisStore, config ExternalOIDCConfig) *ExternalOIDCService {
+	return &ExternalOIDCService{
+		logger:     logger,
+		db:         db,
+		rdb:        rdb,
+		config:     config,
+		httpClient: &http.Client{Timeout: ExternalOIDCHTTPTimeout},
+		keys:       map[string]*rsa.PublicKey{},
+	}
+}
+
+func (s *ExternalOIDCService) Enabled() bool {
+	return s.config.Issuer != "" && s.config.ClientID != "" && s.config.RedirectURI != ""
+}
+
+// AuthorizationURL starts login, state, nonce and PKCE verifier are kept in Redis until callback
+func (s *ExternalOIDCService) AuthorizationURL() (string, error) {
+	if !s.Enabled() {
+		return "", ErrExternalLoginDisabled
+	}
+	metadata, err := s.providerMetadata()
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	loginState := externalLoginState{}
+	if loginState.Nonce, err = randomToken(32); err != nil {
+		return "", err
+	}
+	if loginState.CodeVerifier, err = randomToken(32); err != nil {
+		return "", err
+	}
+	if err := s.rdb.Set(loginStateKey(state), loginState, ExternalOIDCStateTTL); err != nil {
+		s.logger.Error("Cannot store external login state", zap.Error(err))
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(loginState.CodeVerifier))
+	return buildRedirectURI(metadata.AuthorizationEndpoint, map[string]string{
+		"response_type":         constants.OAUTH_RESPONSE_TYPE_CODE,
+		"client_id":             s.config.ClientID,
+		"redirect_uri":          s.config.RedirectURI,
+		"scope":                 strings.Join(s.config.Scopes, " "),
+		"state":                 state,
+		"nonce":                 loginState.Nonce,
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(challenge[:]),
+		"code_challenge_method": constants.OAUTH_CODE_CHALLENGE_METHOD_S256,
+	}), nil
+}
+
+// Login finishes callback and returns linked user. Identity is matched by issuer and subject first,
+// then by verified email, and created with mapped role and department when auto creation is on.
+func (s *ExternalOIDCService) Login(code string, state string) (*user_models.User, error) {
+	if !s.Enabled() {
+		return nil, ErrExternalLoginDisabled
+	}
+
+	var loginState externalLoginState
+	if state == "" || s.rdb.Take(loginStateKey(state), &loginState) != nil {
+		return nil, ErrExternalLoginState
+	}
+
+	idToken, err := s.exchangeCode(code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.verifyIDToken(idToken, loginState.Nonce)
+	if err != nil {
+		s.logger.Error("Cannot verify external ID token", zap.Error(err))
+		return nil, err
+	}
+
+	return s.findOrProvisionUser(claims)
+}
+
+func (s *ExternalOIDCService) findOrProvisionUser(claims jwt.MapClaims) (*user_models.User, error) {
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	now := time.Now()
+
+	var identity *models.ExternalIdentity
+	err := s.db.DB().Where("issuer = ? AND subject = ?", s.config.Issuer, subject).First(&identity).Error
+	if err == nil {
+		var user *user_models.User
+		if err := user_models.ValidScope(s.db.DB()).First(&user, identity.UserID).Error; err != nil {
+			return nil, ErrExternalUserNotProvisioned
+		}
+		if err := s.db.DB().Model(&identity).Updates(map[string]interface{}{"email": email, "last_login_at": now}).Error; err != nil {
+			s.logger.Error("Cannot update external identity", zap.Error(err))
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error("Cannot find external identity", zap.Error(err))
+		return nil, err
+	}
+
+	identity = &models.ExternalIdentity{Issuer: s.config.Issuer, Subject: subject, Email: email, LastLoginAt: &now}
+
+	// existing users are linked only by email the provider has verified, missing claim counts as unverified
+	if verified, _ := claims["email_verified"].(bool); email != "" && verified {
+		var user *user_models.User
+		err := user_models.ValidScope(s.db.DB()).Where("email = ?", email).First(&user).Error
+		if err == nil {
+			if user.IsServiceAccount() {
+				return nil, ErrExternalUserNotProvisioned
+			}
+			identity.UserID = user.ID
+			if err := s.db.DB().Create(&identity).Error; err != nil {
+				s.logger.Error("Cannot link external identity", zap.Error(err))
+				return nil, err
+			}
+			return user, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("Cannot find user by email", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if !s.config.AutoCreateUsers || email == "" {
+		return nil, ErrExternalUserNotProvisioned
+	}
+	return s.provisionUser(identity, claims)
+}
+
+func (s *ExternalOIDCService) provisionUser(identity *models.ExternalIdentity, claims jwt.MapClaims) (*user_models.User, error) {
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name = identity.Email
+	}
+	user := &user_models.User{
+		Name:                  name,
+		Email:                 identity.Email,
+		JoinDate:              time.Now(),
+		Type:                  user_constants.USER_TYPE_HUMAN,
+		PasswordLoginDisabled: s.config.DisablePasswordLogin,
+	}
+	// password is never shown, user can set one with password reset when password login is allowed
+	user.GenerateRandomPassword()
+	if verified, _ := claims["email_verified"].(bool); verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	role, err := s.mappedRole(claims)
+	if err != nil {
+		return nil, err
+	}
+	if role != nil {
+		user.RoleID = &role.ID
+	}
+	department, err := s.mappedDepartment(claims)
+	if err != nil {
+		return nil, err
+	}
+	if department != nil {
+		user.DepartmentID = &department.ID
+	}
+
+	err = s.db.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		// claims carry no custom fields, while any user field is required such users are invited by HR instead
+		if err := customfield_services.SaveCustomFieldValues(tx, customfield_constants.CUSTOM_FIELD_ENTITY_USER, user.ID, nil, true); err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(&identity).Error
+	})
+	if errors.Is(err, customfield_services.ErrCustomFieldValue) {
+		s.logger.Warn("Cannot provision external user without required custom fields", zap.Error(err))
+		return nil, ErrExternalUserNotProvisioned
+	}
+	if err != nil {
+		s.logger.Error("Cannot provision external user", zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("Provisioned user from external identity", zap.Uint("userId", user.ID), zap.String("subject", identity.Subject))
+	return user, nil
+}
+
+// first claim value with a mapping wins, otherwise default role is used
+func (s *ExternalOIDCService) mappedRole(claims jwt.MapClaims) (*auth_models.Role, error) {
+	roleName := s.config.DefaultRole
+	for _, value := range claimValues(claims, s.config.RoleClaim) {
+		if mapped, ok := s.config.RoleMapping[value]; ok {
+			roleName = mapped
+			break
+		}
+	}
+	if roleName == "" {
+		return nil, nil
+	}
+
+	var role *auth_models.Role
+	if err := auth_models.ValidScope(s.db.DB()).Where("name = ?", roleName).First(&role).Error; err != nil {
+		s.logger.Error("Cannot find role for external user", zap.String("role", roleName), zap.Error(err))
+		return nil, err
+	}
+	return role, nil
+}
+
+// unmapped claim value is matched with department name, unknown departments fall back to default one
+func (s *ExternalOIDCService) mappedDepartment(claims jwt.MapClaims) (*department_models.Department, error) {
+	names := []string{}
+	for _, value := range claimValues(claims, s.config.DepartmentClaim) {
+		if mapped, ok := s.config.DepartmentMapping[value]; ok {
+			value = mapped
+		}
+		names = append(names, value)
+	}
+	if s.config.DefaultDepartment != "" {
+		names = append(names, s.config.DefaultDepartment)
+	}
+
+	for _, name := range names {
+		var department *department_models.Department
+		err := department_models.ValidScope(s.db.DB()).Where("name = ?", name).First(&department).Error
+		if err == nil {
+			return department, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("Cannot find department for external user", zap.Error(err))
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (s *ExternalOIDCService) exchangeCode(code string, codeVerifier string) (string, error) {
+	metadata, err := s.providerMetadata()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {constants.OAUTH_GRANT_TYPE_AUTHORIZATION_CODE},
+		"code":          {code},
+		"redirect_uri":  {s.config.RedirectURI},
+		"client_id":     {s.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	request, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if s.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		s.logger.Error("Cannot exchange external authorization code", zap.Error(err))
+		return "", ErrExternalProviderUnavailable
+	}
+	defer response.Body.Close()
+
+	var token externalTokenResponse
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		s.logger.Error("Cannot parse external token response", zap.Error(err))
+		return "", ErrExternalProviderUnavailable
+	}
+	if response.StatusCode != http.StatusOK || token.IDToken == "" {
+		return "", fmt.Errorf("%w: code exchange failed %s %s", ErrExternalIdentity, token.Error, token.ErrorDescription)
+	}
+	return token.IDToken, nil
+}
+
+func (s *ExternalOIDCService) verifyIDToken(idToken string, nonce string) (jwt.MapClaims, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.verificationKey(kid)
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(s.config.Issuer),
+		jwt.WithAudience(s.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(auth_services.TokenClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExternalIdentity, err.Error())
+	}
+
+	if claims["nonce"] != nonce {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrExternalIdentity)
+	}
+	if subject, _ := claims["sub"].(string); subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrExternalIdentity)
+	}
+	return claims, nil
+}
+
+// provider keys are refetched once when token is signed by unknown kid, so rotation at provider is picked up
+func (s *ExternalOIDCService) verificationKey(kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	key, ok := s.keys[kid]
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := s.fetchKeys(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %s", kid)
+}
+
+func (s *ExternalOIDCService) fetchKeys() error {
+	metadata, err := s.providerMetadata()
+	if err != nil {
+		return err
+	}
+
+	var keySet auth_services.JSONWebKeySet
+	if err := s.getJSON(metadata.JwksURI, &keySet); err != nil {
+		return err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range keySet.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// discovery document is fetched once, provider must report the configured issuer
+func (s *ExternalOIDCService) providerMetadata() (*externalProviderMetadata, error) {
+	s.mu.Lock()
+	metadata := s.metadata
+	s.mu.Unlock()
+	if metadata != nil {
+		return metadata, nil
+	}
+
+	metadata = &externalProviderMetadata{}
+	if err := s.getJSON(s.config.Issuer+"/.well-known/openid-configuration", metadata); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != s.config.Issuer {
+		s.logger.Error("External provider reports another issuer", zap.String("issuer", metadata.Issuer))
+		return nil, ErrExternalProviderUnavailable
+	}
+
+	s.mu.Lock()
+	s.metadata = metadata
+	s.mu.Unlock()
+	return metadata, nil
+}
+
+func (s *ExternalOIDCService) getJSON(endpoint string, data interface{}) error {
+	response, err := s.httpClient.Get(endpoint)
+	if err != nil {
+		s.logger.Error("Cannot reach external provider", zap.String("url", endpoint), zap.Error(err))
+		return ErrExternalProviderUnavailable
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		s.logger.Error("External provider responded with error", zap.String("url", endpoint), zap.Int("status", response.StatusCode))
+		return ErrExternalProviderUnavailable
+	}
+	if err := json.NewDecoder(response.Body).Decode(data); err != nil {
+		s.logger.Error("Cannot parse external provider response", zap.String("url", endpoint), zap.Error(err))
+		return ErrExternalProviderUnavailable
+	}
+	return nil
+}
+
+// claim may be a single string or a list of strings, e.g. groups
+func claimValues(claims jwt.MapClaims, name string) []string {
+	if name == "" {
+		return nil
+	}
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := []string{}
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// parseClaimMapping reads "claimValue:Name,otherValue:Other"
+func parseClaimMapping(value string) map[string]string {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		claimValue, name, ok := strings.Cut(pair, ":")
+		if ok && strings.TrimSpace(claimValue) != "" {
+			mapping[strings.TrimSpace(claimValue)] = strings.TrimSpace(name)
+		}
+	}
+	return mapping
+}
+
+func loginStateKey(state string) string {
+	return "oidc:login_states/" + hashToken(state)
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/session/models"
+	user_models "hr-system-go/internal/user/models"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// stubIdentityProvider signs ID tokens with its own key, claims of next token are set by test
+type stubIdentityProvider struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	claims        jwt.MapClaims
+	nonce         string
+	codeChallenge string
+}
+
+func newStubIdentityProvider() *stubIdentityProvider {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	idp := &stubIdentityProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(auth_services.JSONWebKeySet{Keys: []auth_services.JSONWebKey{{
+			Kty: "RSA",
+			Kid: "stub",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		hash := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		clientID, _, _ := r.BasicAuth()
+		if r.PostFormValue("code") != "valid-code" || clientID != "hr-system" || base64.RawURLEncoding.EncodeToString(hash[:]) != idp.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idp.sign(idp.claims), "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	return idp
+}
+
+func (idp *stubIdentityProvider) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "stub"
+	signed, _ := token.SignedString(idp.key)
+	return signed
+}
+
+func (idp *stubIdentityProvider) identityClaims(subject string, email string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            subject,
+		"aud":            "hr-system",
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          idp.nonce,
+		"email":          email,
+		"email_verified": true,
+		"name":           "Jane External",
+	}
+}
+
+var _ = Describe("ExternalOIDCService", func() {
+	var (
+		idp     *stubIdentityProvider
+		service *ExternalOIDCService
+		config  ExternalOIDCConfig
+	)
+
+	BeforeEach(func() {
+		idp = newStubIdentityProvider()
+		config = ExternalOIDCConfig{
+			Issuer:               idp.server.URL,
+			ClientID:             "hr-system",
+			ClientSecret:         "secret",
+			RedirectURI:          "http://localhost:3000/api/login/oidc/callback",
+			Scopes:               []string{"openid", "email"},
+			DisablePasswordLogin: true,
+		}
+		service = newExternalOIDCService(mockLogger, mockDB, mockRDS, config)
+	})
+
+	AfterEach(func() {
+		idp.server.Close()
+	})
+
+	// start returns state of a new login and lets stub provider know nonce and code challenge
+	start := func() string {
+		authorizationURL, err := service.AuthorizationURL()
+		Expect(err).To(BeNil())
+		parsed, _ := url.Parse(authorizationURL)
+		idp.nonce = parsed.Query().Get("nonce")
+		idp.codeChallenge = parsed.Query().Get("code_challenge")
+		return parsed.Query().Get("state")
+	}
+
+	It("should link existing user by verified email", func() {
+		user := &user_models.User{Name: "Jane", Email: faker.Email()}
+		mockDB.DB().Create(&user)
+		subject := faker.UUIDDigit()
+
+		state := start()
+		idp.claims = idp.identityClaims(subject, user.Email)
+		loggedIn, err := service.Login("valid-code", state)
+
+		Expect(err).To(BeNil())
+		Expect(loggedIn.ID).To(Equal(user.ID))
+
+		var identity models.ExternalIdentity
+		mockDB.DB().Where("subject = ?", subject).First(&identity)
+		Expect(identity.UserID).To(Equal(user.ID))
+
+		// subject keeps identifying user after email changes at provider
+		state = start()
+		idp.claims = idp.identityClaims(subject, faker.Email())
+		loggedIn, err = service.Login("valid-code", state)
+		Expect(err).To(BeNil())
+		Expect(loggedIn.ID).To(Equal(user.ID))
+	})
+
+	It("should create user with mapped role and department on first login", func() {
+		role := &auth_models.Role{Name: "Engineer-" + faker.Word()}
+		department := &department_models.Department{Name: "Platform-" + faker.Word()}
+		mockDB.DB().Create(&role)
+		mockDB.DB().Create(&department)
+		config.AutoCreateUsers = true
+		config.RoleClaim = "groups"
+		config.RoleMapping = map[string]string{"eng": role.Name}
+		config.DepartmentClaim = "department"
+		service = newExternalOIDCService(mockLogger, mockDB, mockRDS, config)
+
+		state := start()
+		idp.claims = idp.identityClaims(faker.UUIDDigit(), faker.Email())
+		idp.claims["groups"] = []string{"everyone", "eng"}
+		idp.claims["department"] = department.Name
+		user, err := service.Login("valid-code", state)
+
+		Expect(err).To(BeNil())
+		Expect(user.Name).To(Equal("Jane External"))
+		Expect(*user.RoleID).To(Equal(role.ID))
+		Expect(*user.DepartmentID).To(Equal(department.ID))
+		Expect(user.PasswordLoginDisabled).To(BeTrue())
+	})
+
+	It("should not create unknown user when auto creation is off", func() {
+		state := start()
+		idp.claims = idp.identityClaims(faker.UUIDDigit(), faker.Email())
+
+		_, err := service.Login("valid-code", state)
+
+		Expect(err).To(MatchError(ErrExternalUserNotProvisioned))
+	})
+
+	It("should not link user by unverified email", func() {
+		user := &user_models.User{Name: "Jane", Email: faker.Email()}
+		mockDB.DB().Create(&user)
+
+		state := start()
+		idp.claims = idp.identityClaims(faker.UUIDDigit(), user.Email)
+		idp.claims["email_verified"] = false
+		_, err := service.Login("valid-code", state)
+
+		Expect(err).To(MatchError(ErrExternalUserNotProvisioned))
+	})
+
+	It("should not link user when provider leaves out email_verified", func() {
+		user := &user_models.User{Name: "Jane", Email: faker.Email()}
+		mockDB.DB().Create(&user)
+
+		state := start()
+		idp.claims = idp.identityClaims(faker.UUIDDigit(), user.Email)
+		delete(idp.claims, "email_verified")
+		_, err := service.Login("valid-code", state)
+
+		Expect(err).To(MatchError(ErrExternalUserNotProvisioned))
+	})
+
+	It("should reject token with wrong nonce or audience", func() {
+		state := start()
+		idp.claims = idp.identityClaims(faker.UUIDDigit(), faker.Email())
+		idp.claims["nonce"] = "replayed"
+		_, err := service.Login("valid-code", state)
+		Expect(err).To(MatchError(ErrExternalIdentity))
+
+		state = start()
+		idp.claims = idp.identityClaims(faker.UUIDDigit(), faker.Email())
+		idp.claims["aud"] = "another-app"
+		_, err = service.Login("valid-code", state)
+		Expect(err).To(MatchError(ErrExternalIdentity))
+	})
+
+	It("should accept state only once", func() {
+		state := start()
+		idp.claims = idp.identityClaims(faker.UUIDDigit(), faker.Email())
+		service.Login("invalid-code", state)
+
+		_, err := service.Login("valid-code", state)
+
+		Expect(err).To(MatchError(ErrExternalLoginState))
+	})
+})
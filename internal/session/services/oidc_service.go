@@ -0,0 +1,414 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/app/plugins/redis"
+	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	"hr-system-go/internal/session/constants"
+	"hr-system-go/internal/session/dtos"
+	"hr-system-go/internal/session/models"
+	user_models "hr-system-go/internal/user/models"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var OIDCCodeTTL = 10 * time.Minute
+var OIDCAccessTokenTTL = 1 * time.Hour
+var OIDCIDTokenTTL = 1 * time.Hour
+
+var supportedScopes = []string{constants.OIDC_SCOPE_OPENID, constants.OIDC_SCOPE_PROFILE, constants.OIDC_SCOPE_EMAIL}
+
+// OAuthError is reported to clients with OAuth2 error code
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+func newOAuthError(code string, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+type authorizationCode struct {
+	ClientID      string
+	UserID        uint
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string
+	AuthTime      int64
+}
+
+type accessToken struct {
+	ClientID string
+	UserID   uint
+	Scope    string
+}
+
+type OIDCServiceInterface interface {
+	Discovery() map[string]interface{}
+	JWKS() (auth_services.JSONWebKeySet, error)
+	RegisterClient(payload dtos.RegisterOAuthClientRequest) (*models.OAuthClient, string, error)
+	FindClients() ([]models.OAuthClient, error)
+	DeleteClientByID(clientID int) error
+	Authorize(user *user_models.User, request dtos.AuthorizeRequest) (string, error)
+	ExchangeCode(request dtos.TokenRequest) (*dtos.TokenResponse, error)
+	UserInfo(accessToken string) (map[string]interface{}, error)
+}
+
+type OIDCService struct {
+	logger  *logger.Logger
+	db      *mysql.MySqlStore
+	rdb     *redis.RedisStore
+	keyRing *auth_services.KeyRing
+	issuer  string
+}
+
+func NewOIDCService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, rdb *redis.RedisStore, keyRing *auth_services.KeyRing) OIDCServiceInterface {
+	return &OIDCService{
+		logger:  logger,
+		db:      db,
+		rdb:     rdb,
+		keyRing: keyRing,
+		issuer:  auth_services.TokenIssuer(env),
+	}
+}
+
+func (s *OIDCService) Discovery() map[string]interface{} {
+	return map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/oauth/authorize",
+		"token_endpoint":                        s.issuer + "/oauth/token",
+		"userinfo_endpoint":                     s.issuer + "/oauth/userinfo",
+		"jwks_uri":                              s.issuer + "/oauth/jwks",
+		"response_types_supported":              []string{constants.OAUTH_RESPONSE_TYPE_CODE},
+		"grant_types_supported":                 []string{constants.OAUTH_GRANT_TYPE_AUTHORIZATION_CODE},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": auth_services.SigningAlgorithms,
+		"scopes_supported":                      supportedScopes,
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{constants.OAUTH_CODE_CHALLENGE_METHOD_S256},
+		"claims_supported": []string{
+			"sub", "name", "email", "email_verified", "department_id", "department", "roles",
+		},
+	}
+}
+
+func (s *OIDCService) JWKS() (auth_services.JSONWebKeySet, error) {
+	return s.keyRing.JWKS()
+}
+
+// RegisterClient returns client secret only once, public clients get none
+func (s *OIDCService) RegisterClient(payload dtos.RegisterOAuthClientRequest) (*models.OAuthClient, string, error) {
+	for _, redirectURI := range payload.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			return nil, "", newOAuthError(constants.OAUTH_ERROR_INVALID_REQUEST, "redirect uri must be absolute without fragment")
+		}
+	}
+
+	clientID, err := randomToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+	client := &models.OAuthClient{
+		ClientID:     clientID,
+		Name:         payload.Name,
+		RedirectURIs: payload.RedirectURIs,
+	}
+
+	secret := ""
+	if payload.Confidential {
+		if secret, err = randomToken(32); err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = hashToken(secret)
+	}
+
+	if err := s.db.DB().Create(&client).Error; err != nil {
+		s.logger.Error("Cannot Register OAuth Client", zap.Error(err))
+		return nil, "", err
+	}
+	return client, secret, nil
+}
+
+func (s *OIDCService) FindClients() ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	if err := models.ValidScope(s.db.DB()).Order("id desc").Find(&clients).Error; err != nil {
+		s.logger.Error("Cannot Find OAuth Clients", zap.Error(err))
+		return nil, err
+	}
+	return clients, nil
+}
+
+func (s *OIDCService) DeleteClientByID(clientID int) error {
+	var client *models.OAuthClient
+	if err := models.ValidScope(s.db.DB()).First(&client, clientID).Update("status", "removed").Error; err != nil {
+		s.logger.Error("Cannot Delete OAuth Client", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Authorize issues authorization code for signed in user and returns client redirect URI carrying it.
+// Errors found before redirect URI is verified are returned as OAuthError and must not redirect.
+func (s *OIDCService) Authorize(user *user_models.User, request dtos.AuthorizeRequest) (string, error) {
+	client, err := s.findClient(request.ClientID)
+	if err != nil {
+		return "", newOAuthError(constants.OAUTH_ERROR_INVALID_CLIENT, "unknown client")
+	}
+	if !client.AllowsRedirectURI(request.RedirectURI) {
+		return "", newOAuthError(constants.OAUTH_ERROR_INVALID_REQUEST, "redirect uri is not registered")
+	}
+
+	redirectWithError := func(code string, description string) (string, error) {
+		return buildRedirectURI(request.RedirectURI, map[string]string{
+			"error":             code,
+			"error_description": description,
+			"state":             request.State,
+		}), nil
+	}
+
+	if request.ResponseType != constants.OAUTH_RESPONSE_TYPE_CODE {
+		return redirectWithError(constants.OAUTH_ERROR_UNSUPPORTED_RESPONSE, "only code response type is supported")
+	}
+	scopes := strings.Fields(request.Scope)
+	if !slices.Contains(scopes, constants.OIDC_SCOPE_OPENID) {
+		return redirectWithError(constants.OAUTH_ERROR_INVALID_SCOPE, "openid scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(supportedScopes, scope) {
+			return redirectWithError(constants.OAUTH_ERROR_INVALID_SCOPE, "unsupported scope "+scope)
+		}
+	}
+	// PKCE is required for every client, plain method is not accepted
+	if request.CodeChallenge == "" || request.CodeChallengeMethod != constants.OAUTH_CODE_CHALLENGE_METHOD_S256 {
+		return redirectWithError(constants.OAUTH_ERROR_INVALID_REQUEST, "S256 code challenge is required")
+	}
+	if user.IsServiceAccount() {
+		return redirectWithError(constants.OAUTH_ERROR_ACCESS_DENIED, "service accounts cannot sign in to apps")
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = s.rdb.Set(codeKey(code), authorizationCode{
+		ClientID:      client.ClientID,
+		UserID:        user.ID,
+		RedirectURI:   request.RedirectURI,
+		Scope:         strings.Join(scopes, " "),
+		Nonce:         request.Nonce,
+		CodeChallenge: request.CodeChallenge,
+		AuthTime:      time.Now().Unix(),
+	}, OIDCCodeTTL)
+	if err != nil {
+		s.logger.Error("Cannot store authorization code", zap.Error(err))
+		return "", err
+	}
+
+	return buildRedirectURI(request.RedirectURI, map[string]string{"code": code, "state": request.State}), nil
+}
+
+func (s *OIDCService) ExchangeCode(request dtos.TokenRequest) (*dtos.TokenResponse, error) {
+	if request.GrantType != constants.OAUTH_GRANT_TYPE_AUTHORIZATION_CODE {
+		return nil, newOAuthError(constants.OAUTH_ERROR_UNSUPPORTED_GRANT_TYPE, "only authorization_code grant is supported")
+	}
+
+	client, err := s.findClient(request.ClientID)
+	if err != nil {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_CLIENT, "unknown client")
+	}
+	if client.IsConfidential() && subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashToken(request.ClientSecret))) != 1 {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_CLIENT, "invalid client secret")
+	}
+
+	// code is removed on first read, so it cannot be replayed
+	var code authorizationCode
+	if err := s.rdb.Take(codeKey(request.Code), &code); err != nil {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_GRANT, "invalid or expired code")
+	}
+	if code.ClientID != client.ClientID || code.RedirectURI != request.RedirectURI {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_GRANT, "code was issued to another client or redirect uri")
+	}
+	if !verifyCodeChallenge(code.CodeChallenge, request.CodeVerifier) {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_GRANT, "code verifier does not match")
+	}
+
+	user, roles, err := s.findUserWithRoles(code.UserID)
+	if err != nil {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_GRANT, "user is not available")
+	}
+
+	claims := jwt.MapClaims{
+		"iss":       s.issuer,
+		"sub":       strconv.Itoa(int(user.ID)),
+		"aud":       client.ClientID,
+		"iat":       time.Now().Unix(),
+		"nbf":       time.Now().Unix(),
+		"exp":       time.Now().Add(OIDCIDTokenTTL).Unix(),
+		"auth_time": code.AuthTime,
+	}
+	if code.Nonce != "" {
+		claims["nonce"] = code.Nonce
+	}
+	for key, value := range userClaims(user, roles, code.Scope) {
+		claims[key] = value
+	}
+	idToken, err := s.keyRing.Sign(claims)
+	if err != nil {
+		s.logger.Error("Cannot sign ID token", zap.Error(err))
+		return nil, err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	err = s.rdb.Set(accessTokenKey(token), accessToken{ClientID: client.ClientID, UserID: user.ID, Scope: code.Scope}, OIDCAccessTokenTTL)
+	if err != nil {
+		s.logger.Error("Cannot store access token", zap.Error(err))
+		return nil, err
+	}
+
+	return &dtos.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(OIDCAccessTokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+// UserInfo accepts only access tokens issued by token endpoint, not API session tokens
+func (s *OIDCService) UserInfo(token string) (map[string]interface{}, error) {
+	var stored accessToken
+	if err := s.rdb.Get(accessTokenKey(token), &stored); err != nil {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_TOKEN, "invalid or expired access token")
+	}
+
+	user, roles, err := s.findUserWithRoles(stored.UserID)
+	if err != nil {
+		return nil, newOAuthError(constants.OAUTH_ERROR_INVALID_TOKEN, "user is not available")
+	}
+
+	info := map[string]interface{}{"sub": strconv.Itoa(int(user.ID))}
+	for key, value := range userClaims(user, roles, stored.Scope) {
+		info[key] = value
+	}
+	return info, nil
+}
+
+func (s *OIDCService) findClient(clientID string) (*models.OAuthClient, error) {
+	var client *models.OAuthClient
+	if err := models.ValidScope(s.db.DB()).Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// roles are primary role and additional roles active now
+func (s *OIDCService) findUserWithRoles(userID uint) (*user_models.User, []string, error) {
+	var user *user_models.User
+	if err := user_models.ValidScope(s.db.DB()).Preload("Role").Preload("Department").First(&user, userID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	roles := []string{}
+	if user.Role != nil && user.Role.Status != "removed" {
+		roles = append(roles, user.Role.Name)
+	}
+	var userRoles []auth_models.UserRole
+	err := auth_models.ActiveUserRoleScope(s.db.DB(), time.Now()).
+		Where("user_id = ?", user.ID).
+		Preload("Role", func(db *gorm.DB) *gorm.DB { return auth_models.ValidScope(db) }).
+		Find(&userRoles).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, userRole := range userRoles {
+		if userRole.Role != nil && !slices.Contains(roles, userRole.Role.Name) {
+			roles = append(roles, userRole.Role.Name)
+		}
+	}
+	return user, roles, nil
+}
+
+// department and roles are always included, internal apps decide access by them
+func userClaims(user *user_models.User, roles []string, scope string) map[string]interface{} {
+	scopes := strings.Fields(scope)
+	claims := map[string]interface{}{"roles": roles}
+	if user.Department != nil {
+		claims["department_id"] = user.Department.ID
+		claims["department"] = user.Department.Name
+	}
+	if slices.Contains(scopes, constants.OIDC_SCOPE_PROFILE) {
+		claims["name"] = user.Name
+	}
+	if slices.Contains(scopes, constants.OIDC_SCOPE_EMAIL) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerified()
+	}
+	return claims
+}
+
+func verifyCodeChallenge(challenge string, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+	hash := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func buildRedirectURI(redirectURI string, params map[string]string) string {
+	parsed, _ := url.Parse(redirectURI)
+	query := parsed.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func codeKey(code string) string {
+	return "oidc:codes/" + hashToken(code)
+}
+
+func accessTokenKey(token string) string {
+	return "oidc:access_tokens/" + hashToken(token)
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/app/plugins/redis"
+	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/session/constants"
+	"hr-system-go/internal/session/dtos"
+	"hr-system-go/internal/session/models"
+	user_models "hr-system-go/internal/user/models"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOIDCService(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OIDCService Suite")
+}
+
+var (
+	oidcService OIDCServiceInterface
+	mockEnv     *env.Env
+	mockLogger  *logger.Logger
+	mockDB      *mysql.MySqlStore
+	mockRDS     *redis.RedisStore
+	mockKeyRing *auth_services.KeyRing
+)
+
+var _ = BeforeSuite(func() {
+	mockEnv = env.NewEnv()
+	mockLogger = logger.NewLogger(mockEnv)
+	mockDB = mysql.NewMySqlStore(mockEnv, mockLogger)
+	mockRDS = redis.NewRedisStore(mockEnv, mockLogger)
+	mockKeyRing = auth_services.NewKeyRing(mockLogger, mockEnv, mockDB)
+	oidcService = NewOIDCService(mockLogger, mockEnv, mockDB, mockRDS, mockKeyRing)
+
+	mockDB.Connect(
+		mockEnv.GetEnv("DB_USER"),
+		mockEnv.GetEnv("DB_PASSWORD"),
+		mockEnv.GetEnv("DB_DATABASE"),
+		mockEnv.GetEnv("DB_HOST"),
+		mockEnv.GetEnv("DB_PORT"),
+		mockEnv.GetEnv("DB_PARAMS"),
+	)
+	redisDB, _ := strconv.Atoi(mockEnv.GetEnv("REDIS_DB"))
+	mockRDS.Connect(
+		mockEnv.GetEnv("REDIS_HOST"),
+		mockEnv.GetEnv("REDIS_PORT"),
+		redisDB,
+	)
+
+	mockDB.DB().AutoMigrate(&user_models.User{}, &auth_models.Role{}, &auth_models.UserRole{}, &department_models.Department{}, &auth_models.SigningKey{}, &models.OAuthClient{}, &models.ExternalIdentity{})
+})
+
+var _ = AfterSuite(func() {
+	mockRDS.ClearAll()
+	mockDB.DB().Migrator().DropTable(&user_models.User{}, &auth_models.Role{}, &auth_models.UserRole{}, &department_models.Department{}, &auth_models.SigningKey{}, &models.OAuthClient{}, &models.ExternalIdentity{})
+	mockDB.Close()
+})
+
+var _ = Describe("OIDCService", func() {
+	const redirectURI = "https://wiki.internal/callback"
+	const verifier = "a-very-long-code-verifier-with-enough-entropy-0123456789"
+	hash := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	var (
+		client *models.OAuthClient
+		secret string
+		user   *user_models.User
+	)
+
+	BeforeEach(func() {
+		client, secret, _ = oidcService.RegisterClient(dtos.RegisterOAuthClientRequest{Name: "Wiki", RedirectURIs: []string{redirectURI}, Confidential: true})
+		user = &user_models.User{
+			Name:       "Jane",
+			Email:      faker.Email(),
+			Role:       &auth_models.Role{Name: "RD"},
+			Department: &department_models.Department{Name: "Platform"},
+		}
+		mockDB.DB().Create(&user)
+	})
+
+	authorize := func(request dtos.AuthorizeRequest) string {
+		redirectTo, err := oidcService.Authorize(user, request)
+		Expect(err).To(BeNil())
+		parsed, _ := url.Parse(redirectTo)
+		return parsed.Query().Get("code")
+	}
+
+	validRequest := func() dtos.AuthorizeRequest {
+		return dtos.AuthorizeRequest{
+			ResponseType:        constants.OAUTH_RESPONSE_TYPE_CODE,
+			ClientID:            client.ClientID,
+			RedirectURI:         redirectURI,
+			Scope:               "openid profile email",
+			State:               "xyz",
+			Nonce:               "n-0S6",
+			CodeChallenge:       challenge,
+			CodeChallengeMethod: constants.OAUTH_CODE_CHALLENGE_METHOD_S256,
+		}
+	}
+
+	It("should issue ID token with user, department and role claims", func() {
+		code := authorize(validRequest())
+
+		token, err := oidcService.ExchangeCode(dtos.TokenRequest{
+			GrantType:    constants.OAUTH_GRANT_TYPE_AUTHORIZATION_CODE,
+			Code:         code,
+			RedirectURI:  redirectURI,
+			ClientID:     client.ClientID,
+			ClientSecret: secret,
+			CodeVerifier: verifier,
+		})
+
+		Expect(err).To(BeNil())
+		claims, err := auth_services.ValidateToken(token.IDToken, mockKeyRing, auth_services.TokenIssuer(mockEnv), client.ClientID)
+		Expect(err).To(BeNil())
+		Expect(claims["aud"]).To(Equal(client.ClientID))
+		Expect(claims["nonce"]).To(Equal("n-0S6"))
+		Expect(claims["email"]).To(Equal(user.Email))
+		Expect(claims["department"]).To(Equal("Platform"))
+		Expect(claims["roles"]).To(ConsistOf("RD"))
+
+		info, err := oidcService.UserInfo(token.AccessToken)
+		Expect(err).To(BeNil())
+		Expect(info["sub"]).To(Equal(strconv.Itoa(int(user.ID))))
+	})
+
+	It("should not redirect to unregistered uri", func() {
+		request := validRequest()
+		request.RedirectURI = "https://evil.example/callback"
+
+		_, err := oidcService.Authorize(user, request)
+
+		var oauthErr *OAuthError
+		Expect(err).To(BeAssignableToTypeOf(oauthErr))
+	})
+
+	It("should require S256 code challenge", func() {
+		request := validRequest()
+		request.CodeChallengeMethod = "plain"
+
+		redirectTo, err := oidcService.Authorize(user, request)
+
+		Expect(err).To(BeNil())
+		Expect(redirectTo).To(ContainSubstring("error=" + constants.OAUTH_ERROR_INVALID_REQUEST))
+	})
+
+	It("should reject wrong code verifier and code reuse", func() {
+		code := authorize(validRequest())
+		request := dtos.TokenRequest{
+			GrantType:    constants.OAUTH_GRANT_TYPE_AUTHORIZATION_CODE,
+			Code:         code,
+			RedirectURI:  redirectURI,
+			ClientID:     client.ClientID,
+			ClientSecret: secret,
+			CodeVerifier: "wrong-verifier",
+		}
+
+		_, err := oidcService.ExchangeCode(request)
+		Expect(err).To(MatchError(ContainSubstring(constants.OAUTH_ERROR_INVALID_GRANT)))
+
+		request.CodeVerifier = verifier
+		_, err = oidcService.ExchangeCode(request)
+		Expect(err).To(MatchError(ContainSubstring(constants.OAUTH_ERROR_INVALID_GRANT)))
+	})
+
+	It("should reject confidential client without secret", func() {
+		code := authorize(validRequest())
+
+		_, err := oidcService.ExchangeCode(dtos.TokenRequest{
+			GrantType:    constants.OAUTH_GRANT_TYPE_AUTHORIZATION_CODE,
+			Code:         code,
+			RedirectURI:  redirectURI,
+			ClientID:     client.ClientID,
+			CodeVerifier: verifier,
+		})
+
+		Expect(err).To(MatchError(ContainSubstring(constants.OAUTH_ERROR_INVALID_CLIENT)))
+	})
+
+	It("should publish signing keys", func() {
+		keySet, err := oidcService.JWKS()
+
+		Expect(err).To(BeNil())
+		Expect(keySet.Keys).NotTo(BeEmpty())
+		Expect(keySet.Keys[0].Alg).To(Equal(jwt.SigningMethodRS256.Alg()))
+	})
+})
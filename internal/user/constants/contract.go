@@ -0,0 +1,27 @@
+package constants
+
+const (
+	CONTRACT_TYPE_PERMANENT  = "permanent"
+	CONTRACT_TYPE_FIXED_TERM = "fixed_term"
+)
+
+var CONTRACT_TYPES = []string{CONTRACT_TYPE_PERMANENT, CONTRACT_TYPE_FIXED_TERM}
+
+// extending keeps probation open with a later end date, confirm and end close it
+const (
+	PROBATION_DECISION_CONFIRM = "confirm"
+	PROBATION_DECISION_EXTEND  = "extend"
+	PROBATION_DECISION_END     = "end"
+)
+
+var PROBATION_DECISIONS = []string{PROBATION_DECISION_CONFIRM, PROBATION_DECISION_EXTEND, PROBATION_DECISION_END}
+
+const (
+	CONTRACT_REMINDER_KIND_PROBATION = "probation_end"
+	CONTRACT_REMINDER_KIND_END       = "contract_end"
+)
+
+const (
+	CONTRACT_DEFAULT_REMINDER_DAYS             = 14
+	CONTRACT_DEFAULT_REMINDER_INTERVAL_MINUTES = 1440
+)
@@ -0,0 +1,29 @@
+package constants
+
+// DIRECTORY_LIFECYCLE_STATES are employees colleagues can find, future and former employees are left out
+var DIRECTORY_LIFECYCLE_STATES = []string{LIFECYCLE_STATE_PROBATION, LIFECYCLE_STATE_ACTIVE, LIFECYCLE_STATE_ON_NOTICE}
+
+const (
+	DIRECTORY_MAX_SKILLS       = 30
+	DIRECTORY_MAX_SKILL_LENGTH = 50
+	DIRECTORY_MAX_OFFICE       = 100
+)
+
+// photos are stored in every size as JPEG, large keeps the shape and thumbnails are square
+const (
+	DIRECTORY_PHOTO_SIZE_LARGE  = "large"
+	DIRECTORY_PHOTO_SIZE_MEDIUM = "medium"
+	DIRECTORY_PHOTO_SIZE_SMALL  = "small"
+)
+
+var DIRECTORY_PHOTO_PIXELS = map[string]int{
+	DIRECTORY_PHOTO_SIZE_LARGE:  1024,
+	DIRECTORY_PHOTO_SIZE_MEDIUM: 256,
+	DIRECTORY_PHOTO_SIZE_SMALL:  64,
+}
+
+const (
+	DIRECTORY_PHOTO_MAX_BYTES = 5 << 20
+	// DIRECTORY_PHOTO_MAX_SOURCE_PIXELS refuses images which would take too much memory to decode
+	DIRECTORY_PHOTO_MAX_SOURCE_PIXELS = 40_000_000
+)
@@ -0,0 +1,9 @@
+package constants
+
+const (
+	DOCUMENT_DEFAULT_MAX_MB                           = 20
+	DOCUMENT_DEFAULT_EXPIRY_REMINDER_DAYS             = 30
+	DOCUMENT_DEFAULT_EXPIRY_REMINDER_INTERVAL_MINUTES = 1440
+	DOCUMENT_MAX_FILE_NAME                            = 255
+	DOCUMENT_MAX_CATEGORY_NAME                        = 100
+)
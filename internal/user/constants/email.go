@@ -0,0 +1,17 @@
+package constants
+
+const (
+	// EMAIL_PURPOSE_VERIFY confirms current email, EMAIL_PURPOSE_CHANGE confirms pending one
+	EMAIL_PURPOSE_VERIFY = "verify"
+	EMAIL_PURPOSE_CHANGE = "change"
+)
+
+const (
+	EMAIL_VERIFICATION_DEFAULT_TTL_HOURS   = 24
+	EMAIL_VERIFICATION_DEFAULT_CONFIRM_URL = "http://localhost:3000/email/confirm"
+)
+
+const (
+	PASSWORD_RESET_DEFAULT_TTL_MINUTES = 30
+	PASSWORD_RESET_DEFAULT_URL         = "http://localhost:3000/password/reset"
+)
@@ -0,0 +1,19 @@
+package constants
+
+const (
+	EMPLOYMENT_TYPE_FULL_TIME  = "full_time"
+	EMPLOYMENT_TYPE_PART_TIME  = "part_time"
+	EMPLOYMENT_TYPE_CONTRACTOR = "contractor"
+	EMPLOYMENT_TYPE_INTERN     = "intern"
+)
+
+var EMPLOYMENT_TYPES = []string{EMPLOYMENT_TYPE_FULL_TIME, EMPLOYMENT_TYPE_PART_TIME, EMPLOYMENT_TYPE_CONTRACTOR, EMPLOYMENT_TYPE_INTERN}
+
+// employment record status is not stored, it follows from effective dates
+const (
+	EMPLOYMENT_STATUS_PAST      = "past"
+	EMPLOYMENT_STATUS_CURRENT   = "current"
+	EMPLOYMENT_STATUS_SCHEDULED = "scheduled"
+)
+
+const EMPLOYMENT_DEFAULT_APPLY_INTERVAL_MINUTES = 60
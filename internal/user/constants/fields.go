@@ -0,0 +1,47 @@
+package constants
+
+import auth_constants "hr-system-go/internal/auth/constants"
+
+// sensitive user fields, named after their json keys
+const (
+	USER_FIELD_AGE           = "age"
+	USER_FIELD_SALARY        = "salary"
+	USER_FIELD_STATUS        = "status"
+	USER_FIELD_ROLE          = "roleId"
+	USER_FIELD_DEPARTMENT    = "departmentId"
+	USER_FIELD_PENDING_EMAIL = "pendingEmail"
+	// profile fields
+	USER_FIELD_DATE_OF_BIRTH      = "dateOfBirth"
+	USER_FIELD_NATIONAL_ID        = "nationalId"
+	USER_FIELD_BANK_ACCOUNT       = "bankAccount"
+	USER_FIELD_ADDRESSES          = "addresses"
+	USER_FIELD_PHONE_NUMBERS      = "phoneNumbers"
+	USER_FIELD_EMERGENCY_CONTACTS = "emergencyContacts"
+	// USER_FIELD_TERMINATION is reason and note of termination
+	USER_FIELD_TERMINATION = "termination"
+)
+
+// USER_FIELD_READ_ABILITIES are needed to see field of other users
+var USER_FIELD_READ_ABILITIES = map[string]string{
+	USER_FIELD_AGE:                auth_constants.ABILITY_READ_PERSONAL_DATA,
+	USER_FIELD_SALARY:             auth_constants.ABILITY_READ_SALARY,
+	USER_FIELD_PENDING_EMAIL:      auth_constants.ABILITY_READ_PERSONAL_DATA,
+	USER_FIELD_DATE_OF_BIRTH:      auth_constants.ABILITY_READ_PERSONAL_DATA,
+	USER_FIELD_NATIONAL_ID:        auth_constants.ABILITY_READ_PERSONAL_DATA,
+	USER_FIELD_BANK_ACCOUNT:       auth_constants.ABILITY_READ_SALARY,
+	USER_FIELD_ADDRESSES:          auth_constants.ABILITY_READ_PERSONAL_DATA,
+	USER_FIELD_PHONE_NUMBERS:      auth_constants.ABILITY_READ_PERSONAL_DATA,
+	USER_FIELD_EMERGENCY_CONTACTS: auth_constants.ABILITY_READ_PERSONAL_DATA,
+	USER_FIELD_TERMINATION:        auth_constants.ABILITY_WRITE_EMPLOYMENT,
+}
+
+// USER_FIELD_WRITE_ABILITIES are needed to change field of anyone, own record included
+var USER_FIELD_WRITE_ABILITIES = map[string]string{
+	USER_FIELD_SALARY:     auth_constants.ABILITY_WRITE_SALARY,
+	USER_FIELD_STATUS:     auth_constants.ABILITY_WRITE_EMPLOYMENT,
+	USER_FIELD_ROLE:       auth_constants.ABILITY_READ_WRITE_ROLE,
+	USER_FIELD_DEPARTMENT: auth_constants.ABILITY_WRITE_EMPLOYMENT,
+	// identity fields are verified by HR, contact and bank data is self-service
+	USER_FIELD_DATE_OF_BIRTH: auth_constants.ABILITY_WRITE_PERSONAL_DATA,
+	USER_FIELD_NATIONAL_ID:   auth_constants.ABILITY_WRITE_PERSONAL_DATA,
+}
@@ -0,0 +1,21 @@
+package constants
+
+// query parameters of user list and export
+const (
+	USER_FILTER_DEPARTMENT = "departmentId"
+	USER_FILTER_ROLE       = "roleId"
+	// USER_FILTER_STATUS matches lifecycle states
+	USER_FILTER_STATUS         = "status"
+	USER_FILTER_JOIN_DATE_FROM = "joinDateFrom"
+	USER_FILTER_JOIN_DATE_TO   = "joinDateTo"
+	// USER_FILTER_MANAGER matches manager of the employment record in effect
+	USER_FILTER_MANAGER    = "managerId"
+	USER_FILTER_SALARY_MIN = "salaryMin"
+	USER_FILTER_SALARY_MAX = "salaryMax"
+)
+
+// USER_PRIVILEGED_FILTERS tell field of each filter which reveals it, viewer needs read ability of the field
+var USER_PRIVILEGED_FILTERS = map[string]string{
+	USER_FILTER_SALARY_MIN: USER_FIELD_SALARY,
+	USER_FILTER_SALARY_MAX: USER_FIELD_SALARY,
+}
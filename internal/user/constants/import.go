@@ -0,0 +1,37 @@
+package constants
+
+// columns of user import and export, a mapping ties them to headers of the uploaded file
+const (
+	USER_IMPORT_FIELD_NAME          = "name"
+	USER_IMPORT_FIELD_EMAIL         = "email"
+	USER_IMPORT_FIELD_JOIN_DATE     = "joinDate"
+	USER_IMPORT_FIELD_DATE_OF_BIRTH = "dateOfBirth"
+	USER_IMPORT_FIELD_DEPARTMENT    = "department"
+	USER_IMPORT_FIELD_ROLE          = "role"
+	USER_IMPORT_FIELD_SALARY        = "salary"
+)
+
+var USER_IMPORT_FIELDS = []string{
+	USER_IMPORT_FIELD_NAME,
+	USER_IMPORT_FIELD_EMAIL,
+	USER_IMPORT_FIELD_JOIN_DATE,
+	USER_IMPORT_FIELD_DATE_OF_BIRTH,
+	USER_IMPORT_FIELD_DEPARTMENT,
+	USER_IMPORT_FIELD_ROLE,
+	USER_IMPORT_FIELD_SALARY,
+}
+
+var USER_IMPORT_REQUIRED_FIELDS = []string{USER_IMPORT_FIELD_NAME, USER_IMPORT_FIELD_EMAIL}
+
+// USER_IMPORT_PRIVILEGED_FIELDS need the write ability of matching user field
+var USER_IMPORT_PRIVILEGED_FIELDS = map[string]string{
+	USER_IMPORT_FIELD_DATE_OF_BIRTH: USER_FIELD_DATE_OF_BIRTH,
+	USER_IMPORT_FIELD_DEPARTMENT:    USER_FIELD_DEPARTMENT,
+	USER_IMPORT_FIELD_ROLE:          USER_FIELD_ROLE,
+	USER_IMPORT_FIELD_SALARY:        USER_FIELD_SALARY,
+}
+
+const (
+	USER_IMPORT_MAX_ROWS       = 5000
+	USER_IMPORT_MAX_FILE_BYTES = 10 << 20
+)
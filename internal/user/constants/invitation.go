@@ -0,0 +1,14 @@
+package constants
+
+const (
+	INVITATION_STATUS_PENDING  = "pending"
+	INVITATION_STATUS_ACCEPTED = "accepted"
+	INVITATION_STATUS_REVOKED  = "revoked"
+	// expired is not stored, pending invitations past their expiry are reported as expired
+	INVITATION_STATUS_EXPIRED = "expired"
+)
+
+const (
+	INVITATION_DEFAULT_TTL_HOURS  = 72
+	INVITATION_DEFAULT_ACCEPT_URL = "http://localhost:3000/invitations/accept"
+)
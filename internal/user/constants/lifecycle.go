@@ -0,0 +1,82 @@
+package constants
+
+import auth_constants "hr-system-go/internal/auth/constants"
+
+const (
+	// LIFECYCLE_STATE_PRE_HIRE is user who accepted offer and has not started yet
+	LIFECYCLE_STATE_PRE_HIRE   = "pre_hire"
+	LIFECYCLE_STATE_PROBATION  = "probation"
+	LIFECYCLE_STATE_ACTIVE     = "active"
+	LIFECYCLE_STATE_ON_NOTICE  = "on_notice"
+	LIFECYCLE_STATE_TERMINATED = "terminated"
+	// LIFECYCLE_STATE_REHIRED is former employee waiting for their new hire date
+	LIFECYCLE_STATE_REHIRED = "rehired"
+)
+
+var LIFECYCLE_STATES = []string{
+	LIFECYCLE_STATE_PRE_HIRE,
+	LIFECYCLE_STATE_PROBATION,
+	LIFECYCLE_STATE_ACTIVE,
+	LIFECYCLE_STATE_ON_NOTICE,
+	LIFECYCLE_STATE_TERMINATED,
+	LIFECYCLE_STATE_REHIRED,
+}
+
+// LIFECYCLE_TRANSITIONS are states user can be moved to by hand, notice and rehire have endpoints of their own
+var LIFECYCLE_TRANSITIONS = map[string][]string{
+	LIFECYCLE_STATE_PRE_HIRE:  {LIFECYCLE_STATE_PROBATION, LIFECYCLE_STATE_ACTIVE},
+	LIFECYCLE_STATE_PROBATION: {LIFECYCLE_STATE_ACTIVE},
+	LIFECYCLE_STATE_REHIRED:   {LIFECYCLE_STATE_PROBATION, LIFECYCLE_STATE_ACTIVE},
+}
+
+const (
+	TERMINATION_REASON_RESIGNATION      = "resignation"
+	TERMINATION_REASON_DISMISSAL        = "dismissal"
+	TERMINATION_REASON_END_OF_CONTRACT  = "end_of_contract"
+	TERMINATION_REASON_RETIREMENT       = "retirement"
+	TERMINATION_REASON_MUTUAL_AGREEMENT = "mutual_agreement"
+	// TERMINATION_REASON_FAILED_PROBATION is recorded when employment is ended by probation decision
+	TERMINATION_REASON_FAILED_PROBATION = "failed_probation"
+	TERMINATION_REASON_OTHER            = "other"
+)
+
+var TERMINATION_REASONS = []string{
+	TERMINATION_REASON_RESIGNATION,
+	TERMINATION_REASON_DISMISSAL,
+	TERMINATION_REASON_END_OF_CONTRACT,
+	TERMINATION_REASON_RETIREMENT,
+	TERMINATION_REASON_MUTUAL_AGREEMENT,
+	TERMINATION_REASON_FAILED_PROBATION,
+	TERMINATION_REASON_OTHER,
+}
+
+const (
+	CHECKLIST_KIND_ONBOARDING  = "onboarding"
+	CHECKLIST_KIND_OFFBOARDING = "offboarding"
+)
+
+var CHECKLIST_KINDS = []string{CHECKLIST_KIND_ONBOARDING, CHECKLIST_KIND_OFFBOARDING}
+
+const (
+	CHECKLIST_ASSIGNEE_HR = "hr"
+	CHECKLIST_ASSIGNEE_IT = "it"
+	// CHECKLIST_ASSIGNEE_MANAGER is resolved to manager of employee when checklist starts
+	CHECKLIST_ASSIGNEE_MANAGER = "manager"
+)
+
+var CHECKLIST_ASSIGNEES = []string{CHECKLIST_ASSIGNEE_HR, CHECKLIST_ASSIGNEE_IT, CHECKLIST_ASSIGNEE_MANAGER}
+
+// CHECKLIST_ASSIGNEE_ABILITIES let team members work on tasks of their team
+var CHECKLIST_ASSIGNEE_ABILITIES = map[string]string{
+	CHECKLIST_ASSIGNEE_HR: auth_constants.ABILITY_WRITE_EMPLOYMENT,
+	CHECKLIST_ASSIGNEE_IT: auth_constants.ABILITY_COMPLETE_IT_TASKS,
+}
+
+// overdue is not stored, open tasks past their due date are reported as overdue
+const (
+	CHECKLIST_TASK_STATUS_OPEN    = "open"
+	CHECKLIST_TASK_STATUS_DONE    = "done"
+	CHECKLIST_TASK_STATUS_OVERDUE = "overdue"
+)
+
+const LIFECYCLE_DEFAULT_APPLY_INTERVAL_MINUTES = 60
@@ -0,0 +1,12 @@
+package constants
+
+const (
+	PASSWORD_RULE_MIN_LENGTH    = "min_length"
+	PASSWORD_RULE_UPPERCASE     = "uppercase"
+	PASSWORD_RULE_LOWERCASE     = "lowercase"
+	PASSWORD_RULE_DIGIT         = "digit"
+	PASSWORD_RULE_SYMBOL        = "symbol"
+	PASSWORD_RULE_COMMON        = "common_password"
+	PASSWORD_RULE_PERSONAL_INFO = "personal_info"
+	PASSWORD_RULE_REUSED        = "reused"
+)
@@ -0,0 +1,18 @@
+package constants
+
+const (
+	ADDRESS_TYPE_HOME   = "home"
+	ADDRESS_TYPE_POSTAL = "postal"
+)
+
+var ADDRESS_TYPES = []string{ADDRESS_TYPE_HOME, ADDRESS_TYPE_POSTAL}
+
+const (
+	PHONE_TYPE_MOBILE = "mobile"
+	PHONE_TYPE_HOME   = "home"
+	PHONE_TYPE_WORK   = "work"
+)
+
+var PHONE_TYPES = []string{PHONE_TYPE_MOBILE, PHONE_TYPE_HOME, PHONE_TYPE_WORK}
+
+const PROFILE_MAX_EMERGENCY_CONTACTS = 5
@@ -0,0 +1,6 @@
+package constants
+
+const (
+	USER_TYPE_HUMAN           = "human"
+	USER_TYPE_SERVICE_ACCOUNT = "service_account"
+)
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/services"
+	"hr-system-go/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ChecklistsController serves onboarding and offboarding templates and the tasks started from them
+type ChecklistsController struct {
+	logger        *logger.Logger
+	service       services.ChecklistServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewChecklistsController(logger *logger.Logger, service services.ChecklistServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *ChecklistsController {
+	return &ChecklistsController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *ChecklistsController) RegisterRoutes(r *gin.Engine) {
+	templateRoutes := r.Group("/api/checklist-templates")
+	{
+		templateRoutes.GET("", c.authService.AuthUserAbilityWrapper(c.ListTemplates, constants.ABILITY_WRITE_EMPLOYMENT))
+		templateRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.CreateTemplate, constants.ABILITY_WRITE_EMPLOYMENT))
+		templateRoutes.PUT("/:templateId", c.authService.AuthUserAbilityWrapper(c.UpdateTemplate, constants.ABILITY_WRITE_EMPLOYMENT))
+		templateRoutes.DELETE("/:templateId", c.authService.AuthUserAbilityWrapper(c.DeleteTemplate, constants.ABILITY_WRITE_EMPLOYMENT))
+	}
+
+	// tasks are listed for whoever they are assigned to, access is checked per task
+	taskRoutes := r.Group("/api/checklist-tasks")
+	{
+		taskRoutes.GET("", c.authService.AuthTokenWrapper(c.ListTasks))
+		taskRoutes.POST("/:taskId/complete", c.authService.AuthTokenWrapper(c.CompleteTask))
+		taskRoutes.DELETE("/:taskId/complete", c.authService.AuthTokenWrapper(c.ReopenTask))
+	}
+
+	r.GET("/api/users/:userId/checklists", c.authService.AuthTokenWrapper(c.ListUserChecklists))
+}
+
+func (c *ChecklistsController) ListTemplates(ctx *gin.Context) {
+	templates, err := c.service.FindTemplates(ctx.Query("kind"))
+	if errors.Is(err, services.ErrChecklistKind) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find checklist templates", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Find Checklist Templates"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewChecklistTemplateListResponse(templates))
+}
+
+func (c *ChecklistsController) CreateTemplate(ctx *gin.Context) {
+	errorMsg := "Failed to Create Checklist Template"
+	var payload dtos.ChecklistTemplateRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse checklist template payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	template, err := c.service.CreateTemplate(ctx, payload)
+	if err != nil {
+		c.logger.Error("Cannot not create checklist template", zap.Error(err))
+		c.respondTemplateError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewChecklistTemplateResponse(template))
+}
+
+// UpdateTemplate replaces template tasks, checklists already started keep their tasks
+func (c *ChecklistsController) UpdateTemplate(ctx *gin.Context) {
+	errorMsg := "Failed to Update Checklist Template"
+	templateID, err := strconv.Atoi(ctx.Param("templateId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Checklist Template ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.ChecklistTemplateRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse checklist template payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	template, err := c.service.UpdateTemplate(ctx, templateID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not update checklist template", zap.Error(err))
+		c.respondTemplateError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewChecklistTemplateResponse(template))
+}
+
+func (c *ChecklistsController) DeleteTemplate(ctx *gin.Context) {
+	errorMsg := "Failed to Delete Checklist Template"
+	templateID, err := strconv.Atoi(ctx.Param("templateId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Checklist Template ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	if err := c.service.DeleteTemplate(ctx, templateID); err != nil {
+		c.logger.Error("Cannot not delete checklist template", zap.Error(err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Checklist template not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (c *ChecklistsController) ListUserChecklists(ctx *gin.Context) {
+	errorMsg := "Failed to Find Checklists"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_CHECKLIST, OwnerID: uint(userID)}
+	if !c.policyService.Authorize(ctx, resource, policy_constants.POLICY_ACTION_READ).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	checklists, err := c.service.FindChecklists(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find checklists", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewChecklistListResponse(checklists))
+}
+
+// ListTasks filters by ?userId=, ?assignee=hr|it|manager and ?status=open|done|overdue,
+// only tasks current user can work on are listed
+func (c *ChecklistsController) ListTasks(ctx *gin.Context) {
+	errorMsg := "Failed to Find Checklist Tasks"
+	access, ok := c.taskAccess(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	filter := dtos.ChecklistTaskFilter{Assignee: ctx.Query("assignee"), Status: ctx.Query("status"), Access: &access}
+	if value := ctx.Query("userId"); value != "" {
+		userID, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+			return
+		}
+		filter.UserID = userID
+	}
+
+	pagination := utils.NewPagination(ctx)
+	tasks, totalRows, err := c.service.FindTasks(filter, &pagination)
+	if errors.Is(err, services.ErrChecklistAssignee) || errors.Is(err, services.ErrChecklistTaskStatus) || utils.IsQueryError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find checklist tasks", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewChecklistTaskListResponse(tasks, totalRows, pagination))
+}
+
+func (c *ChecklistsController) CompleteTask(ctx *gin.Context) {
+	c.setTaskCompletion(ctx, true)
+}
+
+func (c *ChecklistsController) ReopenTask(ctx *gin.Context) {
+	c.setTaskCompletion(ctx, false)
+}
+
+func (c *ChecklistsController) setTaskCompletion(ctx *gin.Context, complete bool) {
+	errorMsg := "Failed to Update Checklist Task"
+	taskID, err := strconv.Atoi(ctx.Param("taskId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Checklist Task ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	access, ok := c.taskAccess(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	task, err := c.service.FindTask(taskID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Checklist task not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find checklist task", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	if !access.CanComplete(task) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	if complete {
+		task, err = c.service.CompleteTask(ctx, taskID, access.ViewerID)
+	} else {
+		task, err = c.service.ReopenTask(ctx, taskID)
+	}
+	if err != nil {
+		c.logger.Error("Cannot not update checklist task", zap.Error(err))
+		if errors.Is(err, services.ErrChecklistCancelled) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewChecklistTaskResponse(task))
+}
+
+func (c *ChecklistsController) respondTemplateError(ctx *gin.Context, err error, errorMsg string) {
+	switch {
+	case errors.Is(err, services.ErrChecklistTemplateName), errors.Is(err, services.ErrChecklistKind),
+		errors.Is(err, services.ErrChecklistTask):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Checklist template or department not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+	}
+}
+
+func (c *ChecklistsController) taskAccess(ctx *gin.Context) (dtos.TaskAccess, bool) {
+	currentUser := c.authService.GetCurrentUser(ctx)
+	if currentUser == nil {
+		return dtos.TaskAccess{}, false
+	}
+	return dtos.TaskAccess{ViewerID: currentUser.ID, Abilities: c.authService.GetCurrentUserAbilities(ctx)}, true
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ChecklistsController", func() {
+	var mockChecklist *mock_services.MockChecklistService
+	manager := &models.User{}
+	manager.ID = 7
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockChecklist = &mock_services.MockChecklistService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(manager)
+		router = gin.Default()
+		NewChecklistsController(mockLogger, mockChecklist, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	Describe("CreateTemplate", func() {
+		It("should reject task with unknown assignee", func() {
+			payload := dtos.ChecklistTemplateRequest{Name: "Onboarding", Kind: user_constants.CHECKLIST_KIND_ONBOARDING, Tasks: []dtos.ChecklistTemplateTaskRequest{{Title: "Order desk", Assignee: "facilities"}}}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_EMPLOYMENT})
+			mockChecklist.On("CreateTemplate", payload).Return(nil, services.ErrChecklistTask)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/checklist-templates", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("ListUserChecklists", func() {
+		It("should return checklists with progress", func() {
+			now := time.Now()
+			checklists := []models.Checklist{{UserID: 5, Kind: user_constants.CHECKLIST_KIND_ONBOARDING, Tasks: []models.ChecklistTask{
+				{Title: "Prepare laptop", Assignee: user_constants.CHECKLIST_ASSIGNEE_IT, CompletedAt: &now},
+				{Title: "Plan first week", Assignee: user_constants.CHECKLIST_ASSIGNEE_MANAGER, DueDate: now.AddDate(0, 0, -1)},
+			}}}
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, mock.Anything).Return(policy_models.Decision{Allowed: true})
+			mockChecklist.On("FindChecklists", 5).Return(checklists, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/checklists", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response []dtos.ChecklistResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response[0].Done).To(Equal(1))
+			Expect(response[0].Total).To(Equal(2))
+			Expect(response[0].Tasks[1].Status).To(Equal(user_constants.CHECKLIST_TASK_STATUS_OVERDUE))
+		})
+
+		It("should refuse checklists of other users", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, mock.Anything).Return(policy_models.Decision{Allowed: false})
+
+			req, _ := http.NewRequest("GET", "/api/users/5/checklists", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockChecklist.AssertNotCalled(GinkgoT(), "FindChecklists", mock.Anything)
+		})
+	})
+
+	Describe("ListTasks", func() {
+		It("should limit tasks to the ones viewer can work on", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_COMPLETE_IT_TASKS})
+			mockChecklist.On("FindTasks", mock.AnythingOfType("dtos.ChecklistTaskFilter"), mock.Anything).Return([]models.ChecklistTask{}, int64(0), nil)
+
+			req, _ := http.NewRequest("GET", "/api/checklist-tasks?status=overdue", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			filter := mockChecklist.Calls[0].Arguments.Get(0).(dtos.ChecklistTaskFilter)
+			Expect(filter.Status).To(Equal(user_constants.CHECKLIST_TASK_STATUS_OVERDUE))
+			Expect(filter.Access.ViewerID).To(Equal(uint(7)))
+			Expect(filter.Access.Teams()).To(ConsistOf(user_constants.CHECKLIST_ASSIGNEE_IT))
+		})
+	})
+
+	Describe("CompleteTask", func() {
+		complete := func() *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("POST", "/api/checklist-tasks/3/complete", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("should let manager complete own task", func() {
+			task := &models.ChecklistTask{Assignee: user_constants.CHECKLIST_ASSIGNEE_MANAGER, AssigneeID: &manager.ID}
+			now := time.Now()
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockChecklist.On("FindTask", 3).Return(task, nil)
+			mockChecklist.On("CompleteTask", 3, uint(7)).Return(&models.ChecklistTask{Assignee: task.Assignee, CompletedAt: &now}, nil)
+
+			w := complete()
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.ChecklistTaskResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Status).To(Equal(user_constants.CHECKLIST_TASK_STATUS_DONE))
+		})
+
+		It("should refuse task of another team", func() {
+			task := &models.ChecklistTask{Assignee: user_constants.CHECKLIST_ASSIGNEE_IT}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockChecklist.On("FindTask", 3).Return(task, nil)
+
+			w := complete()
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockChecklist.AssertNotCalled(GinkgoT(), "CompleteTask", mock.Anything, mock.Anything)
+		})
+
+		It("should refuse task of cancelled checklist", func() {
+			task := &models.ChecklistTask{Assignee: user_constants.CHECKLIST_ASSIGNEE_IT}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_COMPLETE_IT_TASKS})
+			mockChecklist.On("FindTask", 3).Return(task, nil)
+			mockChecklist.On("CompleteTask", 3, uint(7)).Return(nil, services.ErrChecklistCancelled)
+
+			w := complete()
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+})
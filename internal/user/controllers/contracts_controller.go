@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// upcomingContractsMaxDays keeps the upcoming list within a year
+const upcomingContractsMaxDays = 366
+
+// ContractsController keeps employment contracts and decides probation at its end
+type ContractsController struct {
+	logger        *logger.Logger
+	service       services.ContractServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewContractsController(logger *logger.Logger, service services.ContractServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *ContractsController {
+	return &ContractsController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *ContractsController) RegisterRoutes(r *gin.Engine) {
+	r.GET("/api/contracts/upcoming", c.authService.AuthUserAbilityWrapper(c.ListUpcoming, constants.ABILITY_WRITE_EMPLOYMENT))
+	userRoutes := r.Group("/api/users/:userId")
+	{
+		userRoutes.GET("/contracts", c.authService.AuthUserAbilityWrapper(c.ListContracts, constants.ABILITY_READ_USER))
+		userRoutes.POST("/contracts", c.authService.AuthUserAbilityWrapper(c.CreateContract, constants.ABILITY_WRITE_EMPLOYMENT))
+		userRoutes.POST("/probation-decision", c.authService.AuthUserAbilityWrapper(c.DecideProbation, constants.ABILITY_WRITE_EMPLOYMENT))
+	}
+}
+
+// ListContracts shows contracts to the user and HR only
+func (c *ContractsController) ListContracts(ctx *gin.Context) {
+	errorMsg := "Failed to Find Contracts"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_CONTRACT, OwnerID: uint(userID)}
+	if !c.policyService.Authorize(ctx, resource, policy_constants.POLICY_ACTION_READ).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	contracts, err := c.service.FindContracts(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find contracts", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewContractListResponse(uint(userID), contracts))
+}
+
+// ListUpcoming lists open probation and contract ends within ?days, reminder days by default
+func (c *ContractsController) ListUpcoming(ctx *gin.Context) {
+	errorMsg := "Failed to Find Upcoming Contracts"
+	days := user_constants.CONTRACT_DEFAULT_REMINDER_DAYS
+	if value := ctx.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > upcomingContractsMaxDays {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number from 0 to 366"})
+			return
+		}
+		days = parsed
+	}
+	until := models.EmploymentDate(time.Now()).AddDate(0, 0, days)
+
+	contracts, err := c.service.FindUpcomingContracts(until)
+	if err != nil {
+		c.logger.Error("Cannot not find upcoming contracts", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewUpcomingContractDatesResponse(contracts, until))
+}
+
+func (c *ContractsController) CreateContract(ctx *gin.Context) {
+	errorMsg := "Failed to Create Contract"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.CreateContractRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse contract payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	contract, err := c.service.CreateContract(ctx, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not create contract", zap.Error(err))
+		c.respondContractError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewContractResponse(contract))
+}
+
+// DecideProbation confirms, extends or ends employment of user on probation, nobody decides their own
+func (c *ContractsController) DecideProbation(ctx *gin.Context) {
+	errorMsg := "Failed to Decide Probation"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.ProbationDecisionRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse probation decision payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_CONTRACT, OwnerID: uint(userID)}
+	if !c.policyService.Authorize(ctx, resource, policy_constants.POLICY_ACTION_APPROVE).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+	currentUser := c.authService.GetCurrentUser(ctx)
+	if currentUser == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	contract, err := c.service.DecideProbation(ctx, currentUser.ID, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not decide probation", zap.Error(err))
+		c.respondContractError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewContractResponse(contract))
+}
+
+func (c *ContractsController) respondContractError(ctx *gin.Context, err error, errorMsg string) {
+	switch {
+	case errors.Is(err, services.ErrContractType), errors.Is(err, services.ErrContractDates),
+		errors.Is(err, services.ErrContractProbationDate), errors.Is(err, services.ErrProbationDecision),
+		errors.Is(err, services.ErrProbationExtension), errors.Is(err, services.ErrTerminationDate):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrContractOverlap), errors.Is(err, services.ErrNoOpenProbation),
+		errors.Is(err, services.ErrLifecycleTransition), errors.Is(err, services.ErrTerminationExists),
+		errors.Is(err, auth_service.ErrLastAdmin):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+	}
+}
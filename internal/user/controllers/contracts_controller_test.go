@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("ContractsController", func() {
+	var mockContract *mock_services.MockContractService
+	hr := &models.User{}
+	hr.ID = 1
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockContract = &mock_services.MockContractService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(hr)
+		router = gin.Default()
+		NewContractsController(mockLogger, mockContract, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	sendJSON := func(method string, path string, payload interface{}) *httptest.ResponseRecorder {
+		jsonPayload, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonPayload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Describe("ListContracts", func() {
+		It("should list contracts of user", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			contracts := []models.Contract{{UserID: 5, Type: user_constants.CONTRACT_TYPE_PERMANENT, StartDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)}}
+			mockContract.On("FindContracts", 5).Return(contracts, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/contracts", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.ContractListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(1))
+			Expect(response.Items[0].Type).To(Equal(user_constants.CONTRACT_TYPE_PERMANENT))
+		})
+
+		It("should forbid contracts of others without ability", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
+
+			req, _ := http.NewRequest("GET", "/api/users/5/contracts", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockContract.AssertNotCalled(GinkgoT(), "FindContracts", mock.Anything)
+		})
+	})
+
+	Describe("ListUpcoming", func() {
+		It("should list probation and contract ends by date", func() {
+			today := models.EmploymentDate(time.Now())
+			probationEnd, contractEnd := today.AddDate(0, 0, 10), today.AddDate(0, 0, 3)
+			contracts := []models.Contract{
+				{UserID: 5, Type: user_constants.CONTRACT_TYPE_PERMANENT, ProbationEndDate: &probationEnd, User: &models.User{Name: "Jane"}},
+				{UserID: 6, Type: user_constants.CONTRACT_TYPE_FIXED_TERM, EndDate: &contractEnd, User: &models.User{Name: "John"}},
+			}
+			mockContract.On("FindUpcomingContracts", today.AddDate(0, 0, 30)).Return(contracts, nil)
+
+			req, _ := http.NewRequest("GET", "/api/contracts/upcoming?days=30", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.UpcomingContractDatesResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(2))
+			Expect(response.Items[0].UserName).To(Equal("John"))
+			Expect(response.Items[0].Kind).To(Equal(user_constants.CONTRACT_REMINDER_KIND_END))
+			Expect(response.Items[1].Kind).To(Equal(user_constants.CONTRACT_REMINDER_KIND_PROBATION))
+		})
+
+		It("should reject days out of range", func() {
+			req, _ := http.NewRequest("GET", "/api/contracts/upcoming?days=1000", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("CreateContract", func() {
+		startDate := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+		It("should create contract", func() {
+			payload := dtos.CreateContractRequest{Type: user_constants.CONTRACT_TYPE_PERMANENT, StartDate: &startDate}
+			contract := &models.Contract{UserID: 5, Type: payload.Type, StartDate: startDate}
+			mockContract.On("CreateContract", 5, mock.AnythingOfType("dtos.CreateContractRequest")).Return(contract, nil)
+
+			w := sendJSON("POST", "/api/users/5/contracts", payload)
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("should map create errors to status", func() {
+			cases := map[error]int{
+				services.ErrContractDates:   http.StatusBadRequest,
+				services.ErrContractOverlap: http.StatusConflict,
+				gorm.ErrRecordNotFound:      http.StatusNotFound,
+			}
+			for createErr, status := range cases {
+				mockContract = &mock_services.MockContractService{}
+				mockContract.On("CreateContract", 5, mock.Anything).Return(nil, createErr)
+				router = gin.Default()
+				NewContractsController(mockLogger, mockContract, mockAuthService, mockPolicy).RegisterRoutes(router)
+
+				w := sendJSON("POST", "/api/users/5/contracts", dtos.CreateContractRequest{Type: user_constants.CONTRACT_TYPE_FIXED_TERM, StartDate: &startDate})
+
+				Expect(w.Code).To(Equal(status))
+			}
+		})
+	})
+
+	Describe("DecideProbation", func() {
+		payload := dtos.ProbationDecisionRequest{Decision: user_constants.PROBATION_DECISION_CONFIRM}
+
+		It("should record decision of current user", func() {
+			decision := user_constants.PROBATION_DECISION_CONFIRM
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_APPROVE).Return(policy_models.Decision{Allowed: true})
+			mockContract.On("DecideProbation", uint(1), 5, payload).Return(&models.Contract{UserID: 5, ProbationDecision: &decision}, nil)
+
+			w := sendJSON("POST", "/api/users/5/probation-decision", payload)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.ContractResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(*response.ProbationDecision).To(Equal(user_constants.PROBATION_DECISION_CONFIRM))
+		})
+
+		It("should forbid deciding own probation", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_APPROVE).Return(policy_models.Decision{Allowed: false})
+
+			w := sendJSON("POST", "/api/users/1/probation-decision", payload)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockContract.AssertNotCalled(GinkgoT(), "DecideProbation", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should return conflict without open probation", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_APPROVE).Return(policy_models.Decision{Allowed: true})
+			mockContract.On("DecideProbation", uint(1), 5, payload).Return(nil, services.ErrNoOpenProbation)
+
+			w := sendJSON("POST", "/api/users/5/probation-decision", payload)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+})
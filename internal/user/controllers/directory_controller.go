@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/logger"
+	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	"hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/services"
+	"hr-system-go/utils"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DirectoryController serves the employee directory to every signed in user, users edit their own entry and photo
+type DirectoryController struct {
+	logger        *logger.Logger
+	service       services.DirectoryServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewDirectoryController(logger *logger.Logger, service services.DirectoryServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *DirectoryController {
+	return &DirectoryController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *DirectoryController) RegisterRoutes(r *gin.Engine) {
+	directoryRoutes := r.Group("/api/directory")
+	{
+		directoryRoutes.GET("", c.authService.AuthTokenWrapper(c.ListDirectory))
+		directoryRoutes.GET("/:userId", c.authService.AuthTokenWrapper(c.GetDirectoryEntry))
+		directoryRoutes.GET("/:userId/photo", c.authService.AuthTokenWrapper(c.GetPhoto))
+	}
+	userRoutes := r.Group("/api/users/:userId")
+	{
+		userRoutes.PUT("/directory", c.authService.AuthTokenWrapper(c.UpdateDirectoryEntry))
+		userRoutes.PUT("/photo", c.authService.AuthTokenWrapper(c.UploadPhoto))
+		userRoutes.DELETE("/photo", c.authService.AuthTokenWrapper(c.DeletePhoto))
+	}
+}
+
+// ListDirectory pages employees by name, ?search= ranks them by fuzzy match of name and skills
+func (c *DirectoryController) ListDirectory(ctx *gin.Context) {
+	pagination := utils.NewPagination(ctx)
+	items, totalRows, err := c.service.FindDirectory(&pagination)
+	switch {
+	case utils.IsQueryError(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.logger.Error("Cannot not find directory", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Find Directory"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDirectoryListResponse(items, totalRows, pagination))
+}
+
+func (c *DirectoryController) GetDirectoryEntry(ctx *gin.Context) {
+	errorMsg := "Failed to Get Directory Entry"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	item, err := c.service.FindDirectoryEntry(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find directory entry", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDirectoryEntryResponse(*item))
+}
+
+// GetPhoto streams ?size= large, medium or small of the photo as JPEG
+func (c *DirectoryController) GetPhoto(ctx *gin.Context) {
+	errorMsg := "Failed to Get Photo"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	photo, err := c.service.OpenPhoto(ctx, userID, ctx.DefaultQuery("size", constants.DIRECTORY_PHOTO_SIZE_MEDIUM))
+	switch {
+	case errors.Is(err, services.ErrDirectoryPhotoSize):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case errors.Is(err, services.ErrNoDirectoryPhoto):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.logger.Error("Cannot not open photo", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	defer photo.Close()
+
+	// photo urls carry version of the photo, so a cached one is never stale for long
+	ctx.Header("Cache-Control", "private, max-age=86400")
+	ctx.Header("Content-Type", "image/jpeg")
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, photo); err != nil {
+		c.logger.Error("Cannot not write photo", zap.Error(err))
+	}
+}
+
+func (c *DirectoryController) UpdateDirectoryEntry(ctx *gin.Context) {
+	errorMsg := "Failed to Update Directory Entry"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	var payload dtos.UpdateDirectoryEntryRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse directory entry payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	item, err := c.service.UpdateDirectoryEntry(ctx, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not update directory entry", zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrDirectoryOffice), errors.Is(err, services.ErrDirectoryPhoneExtension),
+			errors.Is(err, services.ErrDirectorySkills):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDirectoryEntryResponse(*item))
+}
+
+// UploadPhoto takes multipart image as photo, it replaces the previous photo in every size
+func (c *DirectoryController) UploadPhoto(ctx *gin.Context) {
+	errorMsg := "Failed to Upload Photo"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	// body is cut off once it is larger than the file may be, gin would otherwise buffer all of it first
+	utils.LimitUploadBody(ctx, constants.DIRECTORY_PHOTO_MAX_BYTES)
+	fileHeader, err := ctx.FormFile("photo")
+	if utils.IsUploadTooLarge(err) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("photo must not be larger than %d bytes", constants.DIRECTORY_PHOTO_MAX_BYTES)})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not read photo", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "photo is required"})
+		return
+	}
+	if fileHeader.Size > constants.DIRECTORY_PHOTO_MAX_BYTES {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("photo must not be larger than %d bytes", constants.DIRECTORY_PHOTO_MAX_BYTES)})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.logger.Error("Cannot not open photo", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.logger.Error("Cannot not read photo", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	item, err := c.service.UploadPhoto(ctx, userID, content)
+	if err != nil {
+		c.logger.Error("Cannot not upload photo", zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrDirectoryPhoto):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrDirectoryPhotoTooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDirectoryEntryResponse(*item))
+}
+
+func (c *DirectoryController) DeletePhoto(ctx *gin.Context) {
+	errorMsg := "Failed to Delete Photo"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	item, err := c.service.DeletePhoto(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not delete photo", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDirectoryEntryResponse(*item))
+}
+
+func (c *DirectoryController) authorize(ctx *gin.Context, userID int) bool {
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_DIRECTORY, OwnerID: uint(userID)}
+	return c.policyService.Authorize(ctx, resource, policy_constants.POLICY_ACTION_UPDATE).Allowed
+}
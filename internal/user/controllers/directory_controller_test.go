@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"hr-system-go/utils"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("DirectoryController", func() {
+	var mockDirectory *mock_services.MockDirectoryService
+
+	newItem := func(userID uint) *dtos.DirectoryItem {
+		salary := 5000.0
+		user := &models.User{Name: "John", Email: "john@example.com", Salary: &salary}
+		user.ID = userID
+		manager := &models.User{Name: "Jane"}
+		manager.ID = 2
+		photoKey := "directory/5/abc"
+		return &dtos.DirectoryItem{
+			User:       user,
+			Entry:      &models.DirectoryEntry{UserID: userID, Office: "Berlin", PhoneExtension: "123", Skills: []string{"Go"}, PhotoKey: &photoKey},
+			Employment: &models.EmploymentRecord{JobTitle: "Engineer", Manager: manager},
+		}
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockDirectory = &mock_services.MockDirectoryService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		router = gin.Default()
+		NewDirectoryController(mockLogger, mockDirectory, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	authorize := func(allowed bool) {
+		mockPolicy.On("Authorize", mock.Anything, policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_DIRECTORY, OwnerID: 5}, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: allowed})
+	}
+
+	uploadPhoto := func(content string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("photo", "me.png")
+		part.Write([]byte(content))
+		writer.Close()
+		req, _ := http.NewRequest("PUT", "/api/users/5/photo", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Describe("ListDirectory", func() {
+		It("should return only directory fields", func() {
+			mockDirectory.On("FindDirectory", mock.MatchedBy(func(pagination *utils.Pagination) bool {
+				return pagination.Search == "jon"
+			})).Return([]dtos.DirectoryItem{*newItem(5)}, int64(1), nil)
+
+			req, _ := http.NewRequest("GET", "/api/directory?search=jon", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).NotTo(ContainSubstring("john@example.com"))
+			Expect(w.Body.String()).NotTo(ContainSubstring("Salary"))
+			var response dtos.DirectoryListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(1))
+			Expect(response.Items[0].JobTitle).To(Equal("Engineer"))
+			Expect(*response.Items[0].ManagerName).To(Equal("Jane"))
+			Expect(response.Items[0].PhotoUrls).To(HaveKeyWithValue("small", HavePrefix("/api/directory/5/photo?size=small")))
+		})
+
+		It("should return bad request for unknown sort field", func() {
+			mockDirectory.On("FindDirectory", mock.Anything).Return([]dtos.DirectoryItem{}, int64(0), &utils.SortError{Term: "salary"})
+
+			req, _ := http.NewRequest("GET", "/api/directory?sort=salary", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GetPhoto", func() {
+		It("should stream photo as JPEG", func() {
+			mockDirectory.On("OpenPhoto", 5, "small").Return(io.NopCloser(strings.NewReader("jpeg")), nil)
+
+			req, _ := http.NewRequest("GET", "/api/directory/5/photo?size=small", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("image/jpeg"))
+			Expect(w.Body.String()).To(Equal("jpeg"))
+		})
+
+		It("should return not found for user without photo", func() {
+			mockDirectory.On("OpenPhoto", 5, "medium").Return(nil, services.ErrNoDirectoryPhoto)
+
+			req, _ := http.NewRequest("GET", "/api/directory/5/photo", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("UpdateDirectoryEntry", func() {
+		It("should forbid updating entry of another user", func() {
+			authorize(false)
+
+			req, _ := http.NewRequest("PUT", "/api/users/5/directory", bytes.NewBufferString(`{"office":"Berlin"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockDirectory.AssertNotCalled(GinkgoT(), "UpdateDirectoryEntry", mock.Anything, mock.Anything)
+		})
+
+		It("should return bad request for invalid phone extension", func() {
+			authorize(true)
+			mockDirectory.On("UpdateDirectoryEntry", 5, mock.Anything).Return(nil, services.ErrDirectoryPhoneExtension)
+
+			req, _ := http.NewRequest("PUT", "/api/users/5/directory", bytes.NewBufferString(`{"phoneExtension":"ext 12"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("UploadPhoto", func() {
+		It("should upload photo of own entry", func() {
+			authorize(true)
+			mockDirectory.On("UploadPhoto", 5, []byte("image")).Return(newItem(5), nil)
+
+			w := uploadPhoto("image")
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.DirectoryEntryResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.PhotoUrls).To(HaveLen(3))
+		})
+
+		It("should return bad request for file which is not an image", func() {
+			authorize(true)
+			mockDirectory.On("UploadPhoto", 5, mock.Anything).Return(nil, services.ErrDirectoryPhoto)
+
+			w := uploadPhoto("not an image")
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring(services.ErrDirectoryPhoto.Error()))
+		})
+
+		It("should cut off photo larger than the limit", func() {
+			authorize(true)
+
+			w := uploadPhoto(strings.Repeat("x", user_constants.DIRECTORY_PHOTO_MAX_BYTES+utils.MULTIPART_FORM_OVERHEAD_BYTES))
+
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			mockDirectory.AssertNotCalled(GinkgoT(), "UploadPhoto", mock.Anything, mock.Anything)
+		})
+	})
+})
@@ -0,0 +1,423 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/storage"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	"hr-system-go/utils"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// expiringDocumentsMaxDays keeps the expiring list within a year
+const expiringDocumentsMaxDays = 366
+
+// DocumentsController keeps employee files like contracts, IDs and certificates, access is decided by category
+type DocumentsController struct {
+	logger      *logger.Logger
+	service     services.DocumentServiceInterface
+	authService auth_service.AuthServiceInterface
+}
+
+func NewDocumentsController(logger *logger.Logger, service services.DocumentServiceInterface, authService auth_service.AuthServiceInterface) *DocumentsController {
+	return &DocumentsController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+func (c *DocumentsController) RegisterRoutes(r *gin.Engine) {
+	categoryRoutes := r.Group("/api/document-categories")
+	{
+		categoryRoutes.GET("", c.authService.AuthTokenWrapper(c.ListCategories))
+		categoryRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.CreateCategory, constants.ABILITY_ADMIN))
+		categoryRoutes.PUT("/:categoryId", c.authService.AuthUserAbilityWrapper(c.UpdateCategory, constants.ABILITY_ADMIN))
+		categoryRoutes.DELETE("/:categoryId", c.authService.AuthUserAbilityWrapper(c.DeleteCategory, constants.ABILITY_ADMIN))
+	}
+
+	// expiring documents are listed from categories viewer can read
+	r.GET("/api/documents/expiring", c.authService.AuthTokenWrapper(c.ListExpiring))
+
+	documentRoutes := r.Group("/api/users/:userId/documents")
+	{
+		documentRoutes.GET("", c.authService.AuthTokenWrapper(c.ListDocuments))
+		documentRoutes.POST("", c.authService.AuthTokenWrapper(c.UploadDocument))
+		documentRoutes.GET("/:documentId", c.authService.AuthTokenWrapper(c.GetDocument))
+		documentRoutes.GET("/:documentId/download", c.authService.AuthTokenWrapper(c.DownloadDocument))
+		documentRoutes.PUT("/:documentId", c.authService.AuthTokenWrapper(c.UpdateDocument))
+		documentRoutes.DELETE("/:documentId", c.authService.AuthTokenWrapper(c.DeleteDocument))
+	}
+}
+
+func (c *DocumentsController) ListCategories(ctx *gin.Context) {
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		c.logger.Error("Cannot not find document categories", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Find Document Categories"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDocumentCategoryListResponse(categories))
+}
+
+func (c *DocumentsController) CreateCategory(ctx *gin.Context) {
+	errorMsg := "Failed to Create Document Category"
+	var payload dtos.DocumentCategoryRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse document category payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	category, err := c.service.CreateCategory(ctx, payload)
+	if err != nil {
+		c.categoryError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewDocumentCategoryResponse(category))
+}
+
+func (c *DocumentsController) UpdateCategory(ctx *gin.Context) {
+	errorMsg := "Failed to Update Document Category"
+	categoryID, err := strconv.Atoi(ctx.Param("categoryId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Category ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.DocumentCategoryRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse document category payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	category, err := c.service.UpdateCategory(ctx, categoryID, payload)
+	if err != nil {
+		c.categoryError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDocumentCategoryResponse(category))
+}
+
+func (c *DocumentsController) DeleteCategory(ctx *gin.Context) {
+	errorMsg := "Failed to Delete Document Category"
+	categoryID, err := strconv.Atoi(ctx.Param("categoryId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Category ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	if err := c.service.DeleteCategory(ctx, categoryID); err != nil {
+		c.categoryError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Document category deleted"})
+}
+
+func (c *DocumentsController) categoryError(ctx *gin.Context, err error, errorMsg string) {
+	c.logger.Error("Cannot not save document category", zap.Error(err))
+	switch {
+	case errors.Is(err, services.ErrDocumentCategoryName), errors.Is(err, services.ErrDocumentCategoryAbility):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDocumentCategoryTaken), errors.Is(err, services.ErrDocumentCategoryInUse):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Document category not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+	}
+}
+
+// ListExpiring lists documents expiring within ?days, reminder days by default
+func (c *DocumentsController) ListExpiring(ctx *gin.Context) {
+	days := user_constants.DOCUMENT_DEFAULT_EXPIRY_REMINDER_DAYS
+	if value := ctx.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > expiringDocumentsMaxDays {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number from 0 to 366"})
+			return
+		}
+		days = parsed
+	}
+
+	documents, err := c.service.FindExpiringDocuments(models.EmploymentDate(time.Now()).AddDate(0, 0, days))
+	if err != nil {
+		c.logger.Error("Cannot not find expiring documents", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Find Expiring Documents"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewExpiringDocumentsResponse(documents, c.fieldAccess(ctx)))
+}
+
+func (c *DocumentsController) ListDocuments(ctx *gin.Context) {
+	errorMsg := "Failed to Find Documents"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	documents, err := c.service.FindDocuments(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find documents", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDocumentListResponse(uint(userID), documents, c.fieldAccess(ctx)))
+}
+
+// UploadDocument takes multipart file with categoryId, optional expiresOn date, note and hex SHA-256 checksum.
+// File is streamed to storage, so the fields have to come before it
+func (c *DocumentsController) UploadDocument(ctx *gin.Context) {
+	errorMsg := "Failed to Upload Document"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	utils.LimitUploadBody(ctx, c.service.MaxBytes())
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		c.logger.Error("Cannot not read document form", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	fields, file, err := utils.ReadMultipartFields(reader, "file")
+	if err != nil {
+		c.logger.Error("Cannot not read document form", zap.Error(err))
+		switch {
+		case utils.IsUploadTooLarge(err):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errorMsg})
+		case errors.Is(err, utils.ErrMultipartFileMissing):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		case errors.Is(err, utils.ErrMultipartField):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		}
+		return
+	}
+	defer file.Close()
+
+	categoryID, err := strconv.Atoi(fields["categoryId"])
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrDocumentCategory.Error()})
+		return
+	}
+	category, err := c.service.FindCategory(categoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrDocumentCategory.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find document category", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	access := c.fieldAccess(ctx)
+	if !access.CanWriteDocuments(category, uint(userID)) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	payload := dtos.UploadDocumentRequest{
+		CategoryID:   category.ID,
+		Checksum:     fields["checksum"],
+		Note:         fields["note"],
+		UploadedByID: access.ViewerID,
+		FileName:     file.FileName(),
+		ContentType:  file.Header.Get("Content-Type"),
+		Content:      file,
+	}
+	if value := fields["expiresOn"]; value != "" {
+		expiresOn, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "expiresOn must be a date like 2030-12-31"})
+			return
+		}
+		payload.ExpiresOn = &expiresOn
+	}
+	if payload.ContentType == "" {
+		payload.ContentType = mime.TypeByExtension(filepath.Ext(payload.FileName))
+	}
+	if payload.ContentType == "" {
+		payload.ContentType = "application/octet-stream"
+	}
+
+	document, err := c.service.UploadDocument(ctx, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not upload document", zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrDocumentTooLarge), utils.IsUploadTooLarge(err):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrDocumentFileName), errors.Is(err, services.ErrDocumentEmpty),
+			errors.Is(err, services.ErrDocumentChecksum), errors.Is(err, services.ErrDocumentExpiry),
+			errors.Is(err, services.ErrDocumentCategory):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewDocumentResponse(document))
+}
+
+func (c *DocumentsController) GetDocument(ctx *gin.Context) {
+	document, ok := c.findDocument(ctx, "Failed to Get Document", false)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDocumentResponse(document))
+}
+
+// DownloadDocument streams content from storage as attachment, ETag is the checksum
+func (c *DocumentsController) DownloadDocument(ctx *gin.Context) {
+	errorMsg := "Failed to Download Document"
+	document, ok := c.findDocument(ctx, errorMsg, false)
+	if !ok {
+		return
+	}
+
+	content, err := c.service.OpenDocument(ctx, document)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		c.logger.Error("Document content is missing from storage", zap.Uint("DocumentID", document.ID))
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Document content not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not open document", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	defer content.Close()
+
+	// attachment and nosniff keep uploaded HTML or scripts from running in the browser
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}))
+	ctx.Header("Content-Type", document.ContentType)
+	ctx.Header("Content-Length", strconv.FormatInt(document.Size, 10))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Header("ETag", `"`+document.Checksum+`"`)
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, content); err != nil {
+		c.logger.Error("Cannot not write document", zap.Error(err))
+	}
+}
+
+func (c *DocumentsController) UpdateDocument(ctx *gin.Context) {
+	errorMsg := "Failed to Update Document"
+	document, ok := c.findDocument(ctx, errorMsg, true)
+	if !ok {
+		return
+	}
+	var payload dtos.UpdateDocumentRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse document payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	document, err := c.service.UpdateDocument(ctx, int(document.UserID), int(document.ID), payload)
+	if err != nil {
+		c.logger.Error("Cannot not update document", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewDocumentResponse(document))
+}
+
+func (c *DocumentsController) DeleteDocument(ctx *gin.Context) {
+	errorMsg := "Failed to Delete Document"
+	document, ok := c.findDocument(ctx, errorMsg, true)
+	if !ok {
+		return
+	}
+
+	if err := c.service.DeleteDocument(ctx, int(document.UserID), int(document.ID)); err != nil {
+		c.logger.Error("Cannot not delete document", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Document deleted"})
+}
+
+// findDocument writes error response unless document of path exists and viewer may read it, or change it with write
+func (c *DocumentsController) findDocument(ctx *gin.Context, errorMsg string, write bool) (*models.Document, bool) {
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return nil, false
+	}
+	documentID, err := strconv.Atoi(ctx.Param("documentId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Document ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return nil, false
+	}
+
+	document, err := c.service.FindDocument(userID, documentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find document", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return nil, false
+	}
+
+	access := c.fieldAccess(ctx)
+	allowed := access.CanReadDocuments(document.Category, document.UserID)
+	if write {
+		allowed = access.CanWriteDocuments(document.Category, document.UserID)
+	}
+	if !allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return nil, false
+	}
+	return document, true
+}
+
+func (c *DocumentsController) fieldAccess(ctx *gin.Context) dtos.FieldAccess {
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	if currentUser := c.authService.GetCurrentUser(ctx); currentUser != nil {
+		access.ViewerID = currentUser.ID
+	}
+	return access
+}
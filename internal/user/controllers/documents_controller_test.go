@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/storage"
+	"hr-system-go/internal/auth/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"hr-system-go/utils"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("DocumentsController", func() {
+	var mockDocument *mock_services.MockDocumentService
+	viewer := &models.User{}
+	viewer.ID = 5
+	identity := &models.DocumentCategory{Name: "Identity documents", ReadAbility: constants.ABILITY_READ_PERSONAL_DATA, WriteAbility: constants.ABILITY_WRITE_PERSONAL_DATA, ExpiryRequired: true}
+	identity.ID = 2
+	certificates := &models.DocumentCategory{Name: "Certificates", ReadAbility: constants.ABILITY_READ_PERSONAL_DATA, WriteAbility: constants.ABILITY_WRITE_PERSONAL_DATA, SelfService: true}
+	certificates.ID = 3
+
+	newDocument := func(userID uint, category *models.DocumentCategory) *models.Document {
+		document := &models.Document{UserID: userID, CategoryID: category.ID, Category: category, FileName: "passport scan.pdf", ContentType: "application/pdf", Size: 8, Checksum: strings.Repeat("ab", 32)}
+		document.ID = 11
+		return document
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockDocument = &mock_services.MockDocumentService{}
+		mockDocument.On("MaxBytes").Return(int64(1 << 20))
+		mockAuthService = &mock_services.MockAuthService{}
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(viewer)
+		router = gin.Default()
+		NewDocumentsController(mockLogger, mockDocument, mockAuthService).RegisterRoutes(router)
+	})
+
+	upload := func(userID string, fields map[string]string, content string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for name, value := range fields {
+			writer.WriteField(name, value)
+		}
+		part, _ := writer.CreateFormFile("file", "certificate.pdf")
+		part.Write([]byte(content))
+		writer.Close()
+		req, _ := http.NewRequest("POST", "/api/users/"+userID+"/documents", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Describe("ListDocuments", func() {
+		It("should leave out categories viewer cannot read", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			hidden := &models.DocumentCategory{Name: "Contracts", ReadAbility: constants.ABILITY_READ_PERSONAL_DATA, WriteAbility: constants.ABILITY_WRITE_EMPLOYMENT}
+			mockDocument.On("FindDocuments", 8).Return([]models.Document{*newDocument(8, hidden)}, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/8/documents", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.DocumentListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(BeEmpty())
+		})
+
+		It("should return own documents", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockDocument.On("FindDocuments", 5).Return([]models.Document{*newDocument(5, identity)}, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/documents", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.DocumentListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(1))
+			Expect(response.Items[0].DownloadUrl).To(Equal("/api/users/5/documents/11/download"))
+		})
+	})
+
+	Describe("UploadDocument", func() {
+		It("should let employee upload to self service category", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockDocument.On("FindCategory", 3).Return(certificates, nil)
+			mockDocument.On("UploadDocument", 5, mock.MatchedBy(func(payload dtos.UploadDocumentRequest) bool {
+				content, _ := io.ReadAll(payload.Content)
+				return payload.FileName == "certificate.pdf" && payload.ContentType == "application/octet-stream" &&
+					payload.UploadedByID == 5 && payload.ExpiresOn.Format("2006-01-02") == "2030-12-31" && string(content) == "%PDF-1.4"
+			})).Return(newDocument(5, certificates), nil)
+
+			w := upload("5", map[string]string{"categoryId": "3", "expiresOn": "2030-12-31"}, "%PDF-1.4")
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("should forbid employee to upload to category of HR", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockDocument.On("FindCategory", 2).Return(identity, nil)
+
+			w := upload("5", map[string]string{"categoryId": "2"}, "%PDF-1.4")
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockDocument.AssertNotCalled(GinkgoT(), "UploadDocument", mock.Anything, mock.Anything)
+		})
+
+		It("should return payload too large over the limit", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_PERSONAL_DATA})
+			mockDocument.On("FindCategory", 2).Return(identity, nil)
+			mockDocument.On("UploadDocument", 8, mock.Anything).Return(nil, services.ErrDocumentTooLarge)
+
+			w := upload("8", map[string]string{"categoryId": "2", "expiresOn": "2030-12-31"}, "%PDF-1.4")
+
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+
+		It("should cut off upload over the limit while it is received", func() {
+			var readErr error
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_PERSONAL_DATA})
+			mockDocument.On("FindCategory", 2).Return(identity, nil)
+			mockDocument.On("UploadDocument", 8, mock.Anything).Run(func(args mock.Arguments) {
+				_, readErr = io.Copy(io.Discard, args.Get(1).(dtos.UploadDocumentRequest).Content)
+			}).Return(nil, services.ErrDocumentTooLarge)
+
+			w := upload("8", map[string]string{"categoryId": "2"}, strings.Repeat("x", 3<<20))
+
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(utils.IsUploadTooLarge(readErr)).To(BeTrue())
+		})
+
+		It("should require file after the fields", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			writer.WriteField("categoryId", "3")
+			writer.Close()
+			req, _ := http.NewRequest("POST", "/api/users/5/documents", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("file is required"))
+		})
+
+		It("should reject checksum which does not match", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_PERSONAL_DATA})
+			mockDocument.On("FindCategory", 2).Return(identity, nil)
+			mockDocument.On("UploadDocument", 8, mock.Anything).Return(nil, services.ErrDocumentChecksum)
+
+			w := upload("8", map[string]string{"categoryId": "2", "expiresOn": "2030-12-31", "checksum": strings.Repeat("0", 64)}, "%PDF-1.4")
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("DownloadDocument", func() {
+		It("should stream document as attachment", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_PERSONAL_DATA})
+			document := newDocument(8, identity)
+			mockDocument.On("FindDocument", 8, 11).Return(document, nil)
+			mockDocument.On("OpenDocument", document).Return(io.NopCloser(strings.NewReader("%PDF-1.4")), nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/8/documents/11/download", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/pdf"))
+			Expect(w.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="passport scan.pdf"`))
+			Expect(w.Header().Get("X-Content-Type-Options")).To(Equal("nosniff"))
+			Expect(w.Body.String()).To(Equal("%PDF-1.4"))
+		})
+
+		It("should forbid document of other employee", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockDocument.On("FindDocument", 8, 11).Return(newDocument(8, identity), nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/8/documents/11/download", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should return not found when content is missing from storage", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			document := newDocument(5, identity)
+			mockDocument.On("FindDocument", 5, 11).Return(document, nil)
+			mockDocument.On("OpenDocument", document).Return(nil, storage.ErrObjectNotFound)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/documents/11/download", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("DeleteDocument", func() {
+		It("should forbid employee to delete document of HR category", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockDocument.On("FindDocument", 5, 11).Return(newDocument(5, identity), nil)
+
+			req, _ := http.NewRequest("DELETE", "/api/users/5/documents/11", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Describe("CreateCategory", func() {
+		It("should return conflict for taken name", func() {
+			payload := dtos.DocumentCategoryRequest{Name: "Certificates", ReadAbility: constants.ABILITY_READ_PERSONAL_DATA, WriteAbility: constants.ABILITY_WRITE_PERSONAL_DATA}
+			mockDocument.On("CreateCategory", payload).Return(nil, services.ErrDocumentCategoryTaken)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("POST", "/api/document-categories", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	auth_service "hr-system-go/internal/auth/services"
+	"hr-system-go/internal/user/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type EmailController struct {
+	logger      *logger.Logger
+	service     services.EmailVerificationServiceInterface
+	authService auth_service.AuthServiceInterface
+}
+
+func NewEmailController(logger *logger.Logger, service services.EmailVerificationServiceInterface, authService auth_service.AuthServiceInterface) *EmailController {
+	return &EmailController{
+		logger:      logger,
+		service:     service,
+		authService: authService,
+	}
+}
+
+func (c *EmailController) RegisterRoutes(r *gin.Engine) {
+	emailRoutes := r.Group("/api/email")
+	{
+		// link is opened from the mailbox, possibly before user can sign in
+		emailRoutes.POST("/confirm", c.ConfirmEmail)
+		emailRoutes.POST("/verification", c.authService.AuthTokenWrapper(c.ResendVerification))
+	}
+}
+
+type confirmEmailBody struct {
+	Token string `json:"token"`
+}
+
+func (c *EmailController) ConfirmEmail(ctx *gin.Context) {
+	var payload confirmEmailBody
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot Parse Body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := c.service.ConfirmEmail(ctx, payload.Token)
+	if err != nil {
+		c.logger.Error("Cannot Confirm Email", zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrInvalidEmailToken):
+			ctx.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailTaken):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Confirm Email"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"email": user.Email, "emailVerified": user.EmailVerified()})
+}
+
+func (c *EmailController) ResendVerification(ctx *gin.Context) {
+	user := c.authService.GetCurrentUser(ctx)
+	if user == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := c.service.SendVerification(ctx, user); err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.logger.Error("Cannot Send Email Verification", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Send Email Verification"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var emailController *EmailController
+
+var _ = Describe("EmailController", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockEmail = &mock_services.MockEmailVerificationService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		emailController = NewEmailController(mockLogger, mockEmail, mockAuthService)
+		router = gin.Default()
+		emailController.RegisterRoutes(router)
+	})
+
+	Describe("ConfirmEmail", func() {
+		confirm := func(token string) *httptest.ResponseRecorder {
+			jsonPayload, _ := json.Marshal(map[string]string{"token": token})
+			req, _ := http.NewRequest("POST", "/api/email/confirm", bytes.NewBuffer(jsonPayload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("should confirm email of token owner", func() {
+			now := time.Now()
+			user := &models.User{Email: "new@example.com", EmailVerifiedAt: &now}
+			mockEmail.On("ConfirmEmail", "token123").Return(user, nil)
+
+			w := confirm("token123")
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["email"]).To(Equal("new@example.com"))
+			Expect(response["emailVerified"]).To(BeTrue())
+		})
+
+		It("should reject used or expired token", func() {
+			mockEmail.On("ConfirmEmail", "token123").Return(nil, services.ErrInvalidEmailToken)
+
+			w := confirm("token123")
+
+			Expect(w.Code).To(Equal(http.StatusGone))
+		})
+	})
+
+	Describe("ResendVerification", func() {
+		It("should send verification to current user", func() {
+			user := &models.User{Email: "john@example.com"}
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockEmail.On("SendVerification", user).Return(nil)
+
+			req, _ := http.NewRequest("POST", "/api/email/verification", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should return conflict when email is already verified", func() {
+			user := &models.User{Email: "john@example.com"}
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockEmail.On("SendVerification", user).Return(services.ErrEmailAlreadyVerified)
+
+			req, _ := http.NewRequest("POST", "/api/email/verification", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+})
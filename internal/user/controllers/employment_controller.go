@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// EmploymentController serves effective-dated job positions of users
+type EmploymentController struct {
+	logger        *logger.Logger
+	service       services.EmploymentServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewEmploymentController(logger *logger.Logger, service services.EmploymentServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *EmploymentController {
+	return &EmploymentController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *EmploymentController) RegisterRoutes(r *gin.Engine) {
+	employmentRoutes := r.Group("/api/users/:userId/employment")
+	{
+		employmentRoutes.GET("", c.authService.AuthUserAbilityWrapper(c.ListEmploymentRecords, constants.ABILITY_READ_USER))
+		employmentRoutes.GET("/effective", c.authService.AuthUserAbilityWrapper(c.GetEffectiveRecord, constants.ABILITY_READ_USER))
+		employmentRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.CreateEmploymentRecord, constants.ABILITY_WRITE_EMPLOYMENT))
+		employmentRoutes.DELETE("/:recordId", c.authService.AuthUserAbilityWrapper(c.CancelEmploymentRecord, constants.ABILITY_WRITE_EMPLOYMENT))
+	}
+}
+
+func (c *EmploymentController) ListEmploymentRecords(ctx *gin.Context) {
+	errorMsg := "Failed to Find Employment Records"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	records, err := c.service.FindEmploymentRecords(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find employment records", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewEmploymentTimelineResponse(uint(userID), records, c.fieldAccess(ctx)))
+}
+
+// GetEffectiveRecord answers what position user had on ?date=2006-01-02, today by default
+func (c *EmploymentController) GetEffectiveRecord(ctx *gin.Context) {
+	errorMsg := "Failed to Find Employment Record"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	date := time.Now()
+	if value := ctx.Query("date"); value != "" {
+		if date, err = time.Parse(time.DateOnly, value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+			return
+		}
+	}
+	if !c.authorize(ctx, userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	record, err := c.service.FindEffectiveRecord(userID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No employment record in effect on this date"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find employment record", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewEmploymentRecordResponse(record, c.owner(userID), c.fieldAccess(ctx)))
+}
+
+func (c *EmploymentController) CreateEmploymentRecord(ctx *gin.Context) {
+	errorMsg := "Failed to Create Employment Record"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	var payload dtos.CreateEmploymentRecordRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse employment payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	access := c.fieldAccess(ctx)
+	if payload.Salary != nil {
+		if c.authService.GetImpersonation(ctx) != nil {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Salary cannot be changed while impersonating"})
+			return
+		}
+		if !access.CanWrite(user_constants.USER_FIELD_SALARY) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": []string{user_constants.USER_FIELD_SALARY}})
+			return
+		}
+	}
+
+	record, err := c.service.CreateEmploymentRecord(ctx, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not create employment record", zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrEmploymentEffectiveFrom), errors.Is(err, services.ErrEmploymentType),
+			errors.Is(err, services.ErrEmploymentFTE), errors.Is(err, services.ErrEmploymentSalary),
+			errors.Is(err, services.ErrEmploymentManager):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmploymentRecordExists):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User or department not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewEmploymentRecordResponse(record, c.owner(userID), access))
+}
+
+// CancelEmploymentRecord removes change which has not taken effect yet
+func (c *EmploymentController) CancelEmploymentRecord(ctx *gin.Context) {
+	errorMsg := "Failed to Cancel Employment Record"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	recordID, err := strconv.Atoi(ctx.Param("recordId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Employment Record ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	err = c.service.CancelEmploymentRecord(ctx, userID, recordID)
+	if err != nil {
+		c.logger.Error("Cannot not cancel employment record", zap.Error(err))
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Employment record not found"})
+		case errors.Is(err, services.ErrEmploymentRecordApplied):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (c *EmploymentController) authorize(ctx *gin.Context, userID int) bool {
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_USER, ID: uint(userID), OwnerID: uint(userID)}
+	return c.policyService.Authorize(ctx, resource, policy_constants.POLICY_ACTION_READ).Allowed
+}
+
+// owner is enough for field access, which compares ids only
+func (c *EmploymentController) owner(userID int) *models.User {
+	owner := &models.User{}
+	owner.ID = uint(userID)
+	return owner
+}
+
+func (c *EmploymentController) fieldAccess(ctx *gin.Context) dtos.FieldAccess {
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	if currentUser := c.authService.GetCurrentUser(ctx); currentUser != nil {
+		access.ViewerID = currentUser.ID
+	}
+	return access
+}
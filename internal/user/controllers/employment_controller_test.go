@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("EmploymentController", func() {
+	var mockEmployment *mock_services.MockEmploymentService
+	viewer := &models.User{}
+	viewer.ID = 1
+	salary := 5200.0
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockEmployment = &mock_services.MockEmploymentService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(viewer)
+		router = gin.Default()
+		NewEmploymentController(mockLogger, mockEmployment, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	Describe("ListEmploymentRecords", func() {
+		It("should return timeline with end dates and hidden salary", func() {
+			today := models.EmploymentDate(time.Now())
+			records := []models.EmploymentRecord{
+				{EffectiveFrom: today.AddDate(-2, 0, 0), JobTitle: "Engineer", Salary: &salary},
+				{EffectiveFrom: today.AddDate(0, -1, 0), JobTitle: "Senior Engineer", Salary: &salary},
+				{EffectiveFrom: today.AddDate(0, 1, 0), JobTitle: "Lead Engineer", Salary: &salary},
+			}
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_USER})
+			mockEmployment.On("FindEmploymentRecords", 5).Return(records, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/employment", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.EmploymentTimelineResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(3))
+			Expect(response.Items[0].Status).To(Equal(user_constants.EMPLOYMENT_STATUS_PAST))
+			Expect(response.Items[0].EffectiveTo.Equal(records[1].EffectiveFrom.AddDate(0, 0, -1))).To(BeTrue())
+			Expect(response.Items[1].Status).To(Equal(user_constants.EMPLOYMENT_STATUS_CURRENT))
+			Expect(response.Items[2].Status).To(Equal(user_constants.EMPLOYMENT_STATUS_SCHEDULED))
+			Expect(response.Items[2].EffectiveTo).To(BeNil())
+			Expect(response.Items[1].Salary).To(BeNil())
+		})
+	})
+
+	Describe("GetEffectiveRecord", func() {
+		It("should find record in effect on given date", func() {
+			date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+			record := &models.EmploymentRecord{EffectiveFrom: date.AddDate(0, -6, 0), JobTitle: "Engineer"}
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockEmployment.On("FindEffectiveRecord", 5, date).Return(record, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/employment/effective?date=2024-03-01", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.EmploymentRecordResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.JobTitle).To(Equal("Engineer"))
+		})
+
+		It("should reject malformed date", func() {
+			req, _ := http.NewRequest("GET", "/api/users/5/employment/effective?date=01.03.2024", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return not found before first record", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockEmployment.On("FindEffectiveRecord", 5, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/employment/effective?date=1999-01-01", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("CreateEmploymentRecord", func() {
+		postRecord := func(payload string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("POST", "/api/users/5/employment", bytes.NewBufferString(payload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("should schedule promotion", func() {
+			effectiveFrom := models.EmploymentDate(time.Now()).AddDate(0, 1, 0)
+			record := &models.EmploymentRecord{EffectiveFrom: effectiveFrom, JobTitle: "Lead Engineer"}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_EMPLOYMENT})
+			mockEmployment.On("CreateEmploymentRecord", 5, mock.AnythingOfType("dtos.CreateEmploymentRecordRequest")).Return(record, nil)
+
+			w := postRecord(`{"effectiveFrom": "` + effectiveFrom.Format(time.RFC3339) + `", "jobTitle": "Lead Engineer", "reason": "promotion"}`)
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			payload := mockEmployment.Calls[0].Arguments.Get(1).(dtos.CreateEmploymentRecordRequest)
+			Expect(*payload.JobTitle).To(Equal("Lead Engineer"))
+			Expect(payload.Salary).To(BeNil())
+		})
+
+		It("should refuse salary change without salary ability", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_EMPLOYMENT})
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+
+			w := postRecord(`{"effectiveFrom": "2026-11-01T00:00:00Z", "salary": 6000}`)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockEmployment.AssertNotCalled(GinkgoT(), "CreateEmploymentRecord", mock.Anything, mock.Anything)
+		})
+
+		It("should map create errors to status", func() {
+			cases := map[error]int{
+				services.ErrEmploymentFTE:          http.StatusBadRequest,
+				services.ErrEmploymentRecordExists: http.StatusConflict,
+				gorm.ErrRecordNotFound:             http.StatusNotFound,
+			}
+			for createErr, status := range cases {
+				mockEmployment = &mock_services.MockEmploymentService{}
+				mockAuthService = &mock_services.MockAuthService{}
+				mockAuthService.On("GetCurrentUser", mock.Anything).Return(viewer)
+				mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ADMIN})
+				mockEmployment.On("CreateEmploymentRecord", 5, mock.Anything).Return(nil, createErr)
+				router = gin.Default()
+				NewEmploymentController(mockLogger, mockEmployment, mockAuthService, mockPolicy).RegisterRoutes(router)
+
+				w := postRecord(`{"effectiveFrom": "2026-11-01T00:00:00Z", "fte": 1.5}`)
+
+				Expect(w.Code).To(Equal(status))
+			}
+		})
+	})
+
+	Describe("CancelEmploymentRecord", func() {
+		It("should refuse record which took effect", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_EMPLOYMENT})
+			mockEmployment.On("CancelEmploymentRecord", 5, 9).Return(services.ErrEmploymentRecordApplied)
+
+			req, _ := http.NewRequest("DELETE", "/api/users/5/employment/9", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+})
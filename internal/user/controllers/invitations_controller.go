@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_dtos "hr-system-go/internal/customfield/dtos"
+	customfield_services "hr-system-go/internal/customfield/services"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/services"
+	"hr-system-go/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type InvitationsController struct {
+	logger             *logger.Logger
+	service            services.InvitationServiceInterface
+	authService        auth_service.AuthServiceInterface
+	customFieldService customfield_services.CustomFieldServiceInterface
+}
+
+func NewInvitationsController(logger *logger.Logger, service services.InvitationServiceInterface, authService auth_service.AuthServiceInterface, customFieldService customfield_services.CustomFieldServiceInterface) *InvitationsController {
+	return &InvitationsController{
+		logger:             logger,
+		service:            service,
+		authService:        authService,
+		customFieldService: customFieldService,
+	}
+}
+
+func (c *InvitationsController) RegisterRoutes(r *gin.Engine) {
+	invitationRoutes := r.Group("/api/invitations")
+	{
+		invitationRoutes.GET("", c.authService.AuthUserAbilityWrapper(c.ListInvitations, constants.ABILITY_INVITE_USER))
+		invitationRoutes.POST("", c.authService.AuthUserAbilityWrapper(c.CreateInvitation, constants.ABILITY_INVITE_USER))
+		invitationRoutes.DELETE("/:invitationId", c.authService.AuthUserAbilityWrapper(c.RevokeInvitation, constants.ABILITY_INVITE_USER))
+		// invited employee has no account yet, the mailed token is the credential
+		invitationRoutes.POST("/accept", c.AcceptInvitation)
+	}
+}
+
+func (c *InvitationsController) ListInvitations(ctx *gin.Context) {
+	pagination := utils.NewPagination(ctx)
+	invitations, totalRows, err := c.service.FindInvitations(ctx.Query("status"), &pagination)
+	if errors.Is(err, services.ErrInvitationStatus) || utils.IsQueryError(err) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find invitations", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Find Invitations"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewInvitationListResponse(invitations, totalRows, pagination))
+}
+
+func (c *InvitationsController) CreateInvitation(ctx *gin.Context) {
+	errorMsg := "Failed to Create Invitation"
+	var payload dtos.CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse invitation payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	// inviting someone into a role or department needs the same abilities as assigning it
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	forbidden := []string{}
+	if payload.RoleID != nil && !access.CanWrite(user_constants.USER_FIELD_ROLE) {
+		forbidden = append(forbidden, user_constants.USER_FIELD_ROLE)
+	}
+	if payload.DepartmentID != nil && !access.CanWrite(user_constants.USER_FIELD_DEPARTMENT) {
+		forbidden = append(forbidden, user_constants.USER_FIELD_DEPARTMENT)
+	}
+	forbiddenCustomFields, ok := c.forbiddenCustomFields(ctx, payload.CustomFields, access.CustomFieldAccess(nil), errorMsg)
+	if !ok {
+		return
+	}
+	forbidden = append(forbidden, forbiddenCustomFields...)
+	if len(forbidden) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": forbidden})
+		return
+	}
+
+	currentUser := c.authService.GetCurrentUser(ctx)
+	if currentUser == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	invitation, err := c.service.CreateInvitation(ctx, currentUser.ID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not create invitation", zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrInvalidEmail), errors.Is(err, services.ErrInvitationStartDate), errors.Is(err, customfield_services.ErrCustomFieldValue):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Role or department not found"})
+		case errors.Is(err, services.ErrEmailTaken):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvitationNotSent):
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewInvitationResponse(invitation))
+}
+
+func (c *InvitationsController) RevokeInvitation(ctx *gin.Context) {
+	errorMsg := "Failed to Revoke Invitation"
+	invitationID, err := strconv.Atoi(ctx.Param("invitationId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse Invitation ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	if err := c.service.RevokeInvitation(ctx, invitationID); err != nil {
+		c.logger.Error("Cannot not revoke invitation", zap.Error(err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Pending invitation not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// AcceptInvitation sets password and profile of invited employee and signs them in
+func (c *InvitationsController) AcceptInvitation(ctx *gin.Context) {
+	errorMsg := "Failed to Accept Invitation"
+	var payload dtos.AcceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse accept payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	// invited employee has no abilities yet, fields needing one are set by HR on the invitation
+	forbidden, ok := c.forbiddenCustomFields(ctx, payload.CustomFields, customfield_dtos.ValueAccess{}, errorMsg)
+	if !ok {
+		return
+	}
+	if len(forbidden) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": forbidden})
+		return
+	}
+
+	user, err := c.service.AcceptInvitation(ctx, payload)
+	if err != nil {
+		c.logger.Error("Cannot not accept invitation", zap.Error(err))
+		var policyErr *services.PasswordPolicyError
+		switch {
+		case errors.As(err, &policyErr):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Password does not satisfy policy", "violations": policyErr.Violations})
+		case errors.Is(err, services.ErrInvitationName), errors.Is(err, customfield_services.ErrCustomFieldValue):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidInvitation):
+			ctx.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailTaken):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	token, err := c.authService.GenerateToken(ctx, user.ID, user.Name)
+	if err != nil {
+		c.logger.Error("Cannot not generate token", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// forbiddenCustomFields lists user custom fields in values access cannot write, false means error was responded
+func (c *InvitationsController) forbiddenCustomFields(ctx *gin.Context, values map[string]interface{}, access customfield_dtos.ValueAccess, errorMsg string) ([]string, bool) {
+	if len(values) == 0 {
+		return nil, true
+	}
+	fields, err := c.customFieldService.FindFields(customfield_constants.CUSTOM_FIELD_ENTITY_USER)
+	if err != nil {
+		c.logger.Error("Cannot not find custom fields", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return nil, false
+	}
+	return access.ForbiddenValues(fields, values), true
+}
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	customfield_services "hr-system-go/internal/customfield/services"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var (
+	invitationsController *InvitationsController
+	mockInvitation        *mock_services.MockInvitationService
+)
+
+var _ = Describe("InvitationsController", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockInvitation = &mock_services.MockInvitationService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockCustomField = &mock_services.MockCustomFieldService{}
+		invitationsController = NewInvitationsController(mockLogger, mockInvitation, mockAuthService, mockCustomField)
+		router = gin.Default()
+		invitationsController.RegisterRoutes(router)
+	})
+
+	postJSON := func(path string, payload interface{}) *httptest.ResponseRecorder {
+		jsonPayload, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonPayload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Describe("CreateInvitation", func() {
+		startDate := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+		departmentID := uint(3)
+
+		It("should create invitation for current user", func() {
+			inviter := &models.User{}
+			inviter.ID = 1
+			payload := dtos.CreateInvitationRequest{Email: "new.hire@example.com", DepartmentID: &departmentID, StartDate: &startDate}
+			invitation := &models.Invitation{Email: payload.Email, StartDate: startDate, ExpiresAt: time.Now().Add(time.Hour), Status: user_constants.INVITATION_STATUS_PENDING, InvitedByID: 1}
+
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER, constants.ABILITY_WRITE_EMPLOYMENT})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(inviter)
+			mockInvitation.On("CreateInvitation", uint(1), mock.AnythingOfType("dtos.CreateInvitationRequest")).Return(invitation, nil)
+
+			w := postJSON("/api/invitations", payload)
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			var response dtos.InvitationResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Email).To(Equal(payload.Email))
+			Expect(response.Status).To(Equal(user_constants.INVITATION_STATUS_PENDING))
+		})
+
+		It("should refuse role assignment without role ability", func() {
+			roleID := uint(2)
+			payload := dtos.CreateInvitationRequest{Email: "new.hire@example.com", RoleID: &roleID, StartDate: &startDate}
+
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER})
+
+			w := postJSON("/api/invitations", payload)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf(user_constants.USER_FIELD_ROLE))
+			mockInvitation.AssertNotCalled(GinkgoT(), "CreateInvitation", mock.Anything, mock.Anything)
+		})
+
+		It("should refuse custom fields inviter cannot write", func() {
+			payload := dtos.CreateInvitationRequest{Email: "new.hire@example.com", StartDate: &startDate, CustomFields: map[string]interface{}{"badgeNumber": 1234}}
+
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER})
+			mockCustomField.On("FindFields", customfield_constants.CUSTOM_FIELD_ENTITY_USER).Return([]customfield_models.CustomField{
+				{Key: "badgeNumber", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: constants.ABILITY_READ_PERSONAL_DATA},
+			}, nil)
+
+			w := postJSON("/api/invitations", payload)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf("badgeNumber"))
+			mockInvitation.AssertNotCalled(GinkgoT(), "CreateInvitation", mock.Anything, mock.Anything)
+		})
+
+		It("should map create errors to status", func() {
+			inviter := &models.User{}
+			inviter.ID = 1
+			cases := map[error]int{
+				services.ErrInvalidEmail:      http.StatusBadRequest,
+				services.ErrEmailTaken:        http.StatusConflict,
+				gorm.ErrRecordNotFound:        http.StatusBadRequest,
+				services.ErrInvitationNotSent: http.StatusBadGateway,
+				&customfield_services.CustomFieldError{Key: "tShirtSize", Reason: "is not an option"}: http.StatusBadRequest,
+			}
+			for createErr, status := range cases {
+				mockInvitation = &mock_services.MockInvitationService{}
+				mockAuthService = &mock_services.MockAuthService{}
+				mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ADMIN})
+				mockAuthService.On("GetCurrentUser", mock.Anything).Return(inviter)
+				mockInvitation.On("CreateInvitation", uint(1), mock.Anything).Return(nil, createErr)
+				router = gin.Default()
+				NewInvitationsController(mockLogger, mockInvitation, mockAuthService, mockCustomField).RegisterRoutes(router)
+
+				w := postJSON("/api/invitations", dtos.CreateInvitationRequest{Email: "taken@example.com", StartDate: &startDate})
+
+				Expect(w.Code).To(Equal(status))
+			}
+		})
+	})
+
+	Describe("ListInvitations", func() {
+		It("should list invitations with expired status", func() {
+			invitations := []models.Invitation{
+				{Email: "late@example.com", Status: user_constants.INVITATION_STATUS_PENDING, ExpiresAt: time.Now().Add(-time.Hour)},
+			}
+			mockInvitation.On("FindInvitations", "", mock.AnythingOfType("*utils.Pagination")).Return(invitations, int64(1), nil)
+
+			req, _ := http.NewRequest("GET", "/api/invitations", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.InvitationListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items).To(HaveLen(1))
+			Expect(response.Items[0].Status).To(Equal(user_constants.INVITATION_STATUS_EXPIRED))
+		})
+
+		It("should reject unknown status filter", func() {
+			mockInvitation.On("FindInvitations", "lost", mock.Anything).Return(nil, int64(0), services.ErrInvitationStatus)
+
+			req, _ := http.NewRequest("GET", "/api/invitations?status=lost", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("RevokeInvitation", func() {
+		It("should revoke pending invitation", func() {
+			mockInvitation.On("RevokeInvitation", 5).Return(nil)
+
+			req, _ := http.NewRequest("DELETE", "/api/invitations/5", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should return not found when invitation is not pending", func() {
+			mockInvitation.On("RevokeInvitation", 5).Return(gorm.ErrRecordNotFound)
+
+			req, _ := http.NewRequest("DELETE", "/api/invitations/5", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("AcceptInvitation", func() {
+		dateOfBirth := time.Date(1998, 4, 12, 0, 0, 0, 0, time.UTC)
+		payload := dtos.AcceptInvitationRequest{Token: "token123", Name: "New Hire", DateOfBirth: &dateOfBirth, Password: "Sunflower2024"}
+
+		It("should create user and return a token", func() {
+			user := &models.User{Name: payload.Name}
+			user.ID = 9
+			mockInvitation.On("AcceptInvitation", payload).Return(user, nil)
+			mockAuthService.On("GenerateToken", uint(9), payload.Name).Return("jwt123", nil)
+
+			w := postJSON("/api/invitations/accept", payload)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response map[string]string
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["token"]).To(Equal("jwt123"))
+		})
+
+		It("should reject used or expired token", func() {
+			mockInvitation.On("AcceptInvitation", payload).Return(nil, services.ErrInvalidInvitation)
+
+			w := postJSON("/api/invitations/accept", payload)
+
+			Expect(w.Code).To(Equal(http.StatusGone))
+		})
+
+		It("should return password policy violations", func() {
+			policyErr := &services.PasswordPolicyError{Violations: []services.PasswordViolation{{Rule: user_constants.PASSWORD_RULE_MIN_LENGTH, Message: "too short"}}}
+			mockInvitation.On("AcceptInvitation", payload).Return(nil, policyErr)
+
+			w := postJSON("/api/invitations/accept", payload)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["violations"]).To(HaveLen(1))
+		})
+
+		It("should let employee set open custom fields only", func() {
+			mockCustomField.On("FindFields", customfield_constants.CUSTOM_FIELD_ENTITY_USER).Return([]customfield_models.CustomField{
+				{Key: "tShirtSize", Type: customfield_constants.CUSTOM_FIELD_TYPE_TEXT},
+				{Key: "badgeNumber", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: constants.ABILITY_READ_PERSONAL_DATA},
+			}, nil)
+			withFields := payload
+			withFields.CustomFields = map[string]interface{}{"tShirtSize": "M", "badgeNumber": 1234}
+
+			w := postJSON("/api/invitations/accept", withFields)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf("badgeNumber"))
+			mockInvitation.AssertNotCalled(GinkgoT(), "AcceptInvitation", mock.Anything)
+		})
+
+		It("should refuse missing required custom field", func() {
+			mockInvitation.On("AcceptInvitation", payload).Return(nil, &customfield_services.CustomFieldError{Key: "tShirtSize", Reason: "is required"})
+
+			w := postJSON("/api/invitations/accept", payload)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("tShirtSize"))
+		})
+	})
+})
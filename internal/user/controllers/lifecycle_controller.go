@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LifecycleController moves users through hiring, probation, notice and rehire
+type LifecycleController struct {
+	logger        *logger.Logger
+	service       services.LifecycleServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewLifecycleController(logger *logger.Logger, service services.LifecycleServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *LifecycleController {
+	return &LifecycleController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *LifecycleController) RegisterRoutes(r *gin.Engine) {
+	userRoutes := r.Group("/api/users/:userId")
+	{
+		userRoutes.GET("/lifecycle", c.authService.AuthUserAbilityWrapper(c.GetLifecycle, constants.ABILITY_READ_USER))
+		userRoutes.PUT("/lifecycle", c.authService.AuthUserAbilityWrapper(c.ChangeLifecycleState, constants.ABILITY_WRITE_EMPLOYMENT))
+		userRoutes.POST("/termination", c.authService.AuthUserAbilityWrapper(c.TerminateUser, constants.ABILITY_WRITE_EMPLOYMENT))
+		userRoutes.DELETE("/termination", c.authService.AuthUserAbilityWrapper(c.WithdrawTermination, constants.ABILITY_WRITE_EMPLOYMENT))
+		userRoutes.POST("/rehire", c.authService.AuthUserAbilityWrapper(c.RehireUser, constants.ABILITY_WRITE_EMPLOYMENT))
+	}
+}
+
+// GetLifecycle shows termination details to the user and HR only
+func (c *LifecycleController) GetLifecycle(ctx *gin.Context) {
+	errorMsg := "Failed to Find Lifecycle"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_USER, ID: uint(userID), OwnerID: uint(userID)}
+	if !c.policyService.Authorize(ctx, resource, policy_constants.POLICY_ACTION_READ).Allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	user, termination, err := c.service.FindLifecycle(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find lifecycle", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	if currentUser := c.authService.GetCurrentUser(ctx); currentUser != nil {
+		access.ViewerID = currentUser.ID
+	}
+	if !access.CanRead(user, user_constants.USER_FIELD_TERMINATION) {
+		termination = nil
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewLifecycleResponse(user, termination))
+}
+
+func (c *LifecycleController) ChangeLifecycleState(ctx *gin.Context) {
+	errorMsg := "Failed to Change Lifecycle State"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.ChangeLifecycleStateRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse lifecycle payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	user, err := c.service.ChangeLifecycleState(ctx, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not change lifecycle state", zap.Error(err))
+		c.respondLifecycleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewLifecycleResponse(user, nil))
+}
+
+// TerminateUser records notice, user is terminated after the termination date
+func (c *LifecycleController) TerminateUser(ctx *gin.Context) {
+	errorMsg := "Failed to Terminate User"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.TerminateUserRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse termination payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	currentUser := c.authService.GetCurrentUser(ctx)
+	if currentUser == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	termination, err := c.service.TerminateUser(ctx, currentUser.ID, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not terminate user", zap.Error(err))
+		c.respondLifecycleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewTerminationResponse(termination))
+}
+
+func (c *LifecycleController) WithdrawTermination(ctx *gin.Context) {
+	errorMsg := "Failed to Withdraw Termination"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	if err := c.service.WithdrawTermination(ctx, userID); err != nil {
+		c.logger.Error("Cannot not withdraw termination", zap.Error(err))
+		c.respondLifecycleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (c *LifecycleController) RehireUser(ctx *gin.Context) {
+	errorMsg := "Failed to Rehire User"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	var payload dtos.RehireUserRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse rehire payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+
+	user, err := c.service.RehireUser(ctx, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not rehire user", zap.Error(err))
+		c.respondLifecycleError(ctx, err, errorMsg)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewLifecycleResponse(user, nil))
+}
+
+func (c *LifecycleController) respondLifecycleError(ctx *gin.Context, err error, errorMsg string) {
+	switch {
+	case errors.Is(err, services.ErrProbationEndDate), errors.Is(err, services.ErrTerminationDate),
+		errors.Is(err, services.ErrTerminationReason), errors.Is(err, services.ErrNoticePeriod),
+		errors.Is(err, services.ErrRehireDate):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLifecycleTransition), errors.Is(err, services.ErrTerminationExists),
+		errors.Is(err, auth_service.ErrLastAdmin):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+	}
+}
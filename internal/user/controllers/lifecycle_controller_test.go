@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_services "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("LifecycleController", func() {
+	var mockLifecycle *mock_services.MockLifecycleService
+	hr := &models.User{}
+	hr.ID = 1
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockLifecycle = &mock_services.MockLifecycleService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		mockAuthService.On("GetCurrentUser", mock.Anything).Return(hr)
+		router = gin.Default()
+		NewLifecycleController(mockLogger, mockLifecycle, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	sendJSON := func(method string, path string, payload interface{}) *httptest.ResponseRecorder {
+		jsonPayload, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonPayload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Describe("GetLifecycle", func() {
+		user := &models.User{LifecycleState: user_constants.LIFECYCLE_STATE_ON_NOTICE}
+		user.ID = 5
+		termination := &models.Termination{UserID: 5, Reason: user_constants.TERMINATION_REASON_DISMISSAL, Note: "repeated misconduct"}
+
+		It("should hide termination details from viewers outside HR", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_USER})
+			mockLifecycle.On("FindLifecycle", 5).Return(user, termination, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/lifecycle", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.LifecycleResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.State).To(Equal(user_constants.LIFECYCLE_STATE_ON_NOTICE))
+			Expect(response.Termination).To(BeNil())
+		})
+
+		It("should show termination details to HR", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_USER, constants.ABILITY_WRITE_EMPLOYMENT})
+			mockLifecycle.On("FindLifecycle", 5).Return(user, termination, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/5/lifecycle", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var response dtos.LifecycleResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Termination.Reason).To(Equal(user_constants.TERMINATION_REASON_DISMISSAL))
+		})
+	})
+
+	Describe("TerminateUser", func() {
+		terminationDate := time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)
+
+		It("should record termination by current user", func() {
+			payload := dtos.TerminateUserRequest{TerminationDate: &terminationDate, NoticePeriodDays: 30, Reason: user_constants.TERMINATION_REASON_RESIGNATION}
+			termination := &models.Termination{UserID: 5, TerminationDate: terminationDate, NoticePeriodDays: 30, Reason: payload.Reason, RecordedByID: 1}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_EMPLOYMENT})
+			mockLifecycle.On("TerminateUser", uint(1), 5, mock.AnythingOfType("dtos.TerminateUserRequest")).Return(termination, nil)
+
+			w := sendJSON("POST", "/api/users/5/termination", payload)
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			var response dtos.TerminationResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.NoticePeriodDays).To(Equal(30))
+			Expect(response.RecordedById).To(Equal(uint(1)))
+		})
+
+		It("should map termination errors to status", func() {
+			cases := map[error]int{
+				services.ErrTerminationReason:   http.StatusBadRequest,
+				services.ErrTerminationDate:     http.StatusBadRequest,
+				services.ErrTerminationExists:   http.StatusConflict,
+				services.ErrLifecycleTransition: http.StatusConflict,
+				auth_services.ErrLastAdmin:      http.StatusConflict,
+			}
+			for terminateErr, status := range cases {
+				mockLifecycle = &mock_services.MockLifecycleService{}
+				mockAuthService = &mock_services.MockAuthService{}
+				mockAuthService.On("GetCurrentUser", mock.Anything).Return(hr)
+				mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ADMIN})
+				mockLifecycle.On("TerminateUser", uint(1), 5, mock.Anything).Return(nil, terminateErr)
+				router = gin.Default()
+				NewLifecycleController(mockLogger, mockLifecycle, mockAuthService, mockPolicy).RegisterRoutes(router)
+
+				w := sendJSON("POST", "/api/users/5/termination", dtos.TerminateUserRequest{TerminationDate: &terminationDate, Reason: "layoff"})
+
+				Expect(w.Code).To(Equal(status))
+			}
+		})
+	})
+
+	Describe("WithdrawTermination", func() {
+		It("should refuse user who is not on notice", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_EMPLOYMENT})
+			mockLifecycle.On("WithdrawTermination", 5).Return(services.ErrLifecycleTransition)
+
+			req, _ := http.NewRequest("DELETE", "/api/users/5/termination", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("RehireUser", func() {
+		It("should rehire terminated user", func() {
+			joinDate := time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC)
+			user := &models.User{LifecycleState: user_constants.LIFECYCLE_STATE_REHIRED, JoinDate: joinDate}
+			user.ID = 5
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_EMPLOYMENT})
+			mockLifecycle.On("RehireUser", 5, mock.AnythingOfType("dtos.RehireUserRequest")).Return(user, nil)
+
+			w := sendJSON("POST", "/api/users/5/rehire", dtos.RehireUserRequest{JoinDate: &joinDate})
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.LifecycleResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.State).To(Equal(user_constants.LIFECYCLE_STATE_REHIRED))
+			Expect(response.JoinDate.Equal(joinDate)).To(BeTrue())
+		})
+	})
+})
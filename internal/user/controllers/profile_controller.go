@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	auth_service "hr-system-go/internal/auth/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProfileController serves personal data of employees, users edit their own contact and bank data
+type ProfileController struct {
+	logger        *logger.Logger
+	service       services.ProfileServiceInterface
+	authService   auth_service.AuthServiceInterface
+	policyService policy_services.PolicyServiceInterface
+}
+
+func NewProfileController(logger *logger.Logger, service services.ProfileServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface) *ProfileController {
+	return &ProfileController{
+		logger:        logger,
+		service:       service,
+		authService:   authService,
+		policyService: policyService,
+	}
+}
+
+func (c *ProfileController) RegisterRoutes(r *gin.Engine) {
+	profileRoutes := r.Group("/api/users/:userId/profile")
+	{
+		profileRoutes.GET("", c.authService.AuthTokenWrapper(c.GetProfile))
+		profileRoutes.PUT("", c.authService.AuthTokenWrapper(c.UpdateProfile))
+	}
+}
+
+func (c *ProfileController) GetProfile(ctx *gin.Context) {
+	errorMsg := "Failed to Get Profile"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_READ) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	profile, err := c.service.FindProfile(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not find profile", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewProfileResponse(profile, c.fieldAccess(ctx)))
+}
+
+func (c *ProfileController) UpdateProfile(ctx *gin.Context) {
+	errorMsg := "Failed to Update Profile"
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		c.logger.Error("Cannot not parse User ID", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_UPDATE) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	var payload dtos.UpdateProfileRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		c.logger.Error("Cannot not parse profile payload", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
+		return
+	}
+	// salary would be paid to account chosen by impersonator
+	if payload.BankAccount != nil && c.authService.GetImpersonation(ctx) != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Bank account cannot be changed while impersonating"})
+		return
+	}
+
+	access := c.fieldAccess(ctx)
+	if fields := access.ForbiddenProfileFields(payload); len(fields) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": fields})
+		return
+	}
+
+	profile, err := c.service.UpdateProfile(ctx, userID, payload)
+	if err != nil {
+		c.logger.Error("Cannot not update profile", zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrProfileDateOfBirth), errors.Is(err, services.ErrProfileAddress),
+			errors.Is(err, services.ErrProfilePhoneNumber), errors.Is(err, services.ErrProfileBankAccount),
+			errors.Is(err, services.ErrProfileEmergencyContact), errors.Is(err, services.ErrInvalidEmail):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewProfileResponse(profile, access))
+}
+
+func (c *ProfileController) authorize(ctx *gin.Context, userID int, action string) bool {
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_PROFILE, OwnerID: uint(userID)}
+	return c.policyService.Authorize(ctx, resource, action).Allowed
+}
+
+func (c *ProfileController) fieldAccess(ctx *gin.Context) dtos.FieldAccess {
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	if currentUser := c.authService.GetCurrentUser(ctx); currentUser != nil {
+		access.ViewerID = currentUser.ID
+	}
+	return access
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_models "hr-system-go/internal/auth/models"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ProfileController", func() {
+	var mockProfile *mock_services.MockProfileService
+	nationalID, bankAccount := "850412-1234", "DE89370400440532013000"
+	dateOfBirth := time.Date(1985, 4, 12, 0, 0, 0, 0, time.UTC)
+
+	newProfile := func(userID uint) *models.Profile {
+		user := &models.User{Name: "John", DateOfBirth: &dateOfBirth}
+		user.ID = userID
+		return &models.Profile{
+			UserID:            userID,
+			NationalID:        &nationalID,
+			BankAccountNumber: &bankAccount,
+			PhoneNumbers:      []models.PhoneNumber{{Type: user_constants.PHONE_TYPE_MOBILE, Number: "+49 151 2345678"}},
+			User:              user,
+		}
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockProfile = &mock_services.MockProfileService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		mockPolicy = &mock_services.MockPolicyService{}
+		router = gin.Default()
+		NewProfileController(mockLogger, mockProfile, mockAuthService, mockPolicy).RegisterRoutes(router)
+	})
+
+	putProfile := func(payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/users/5/profile", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Describe("GetProfile", func() {
+		It("should hide bank account from viewer without salary ability", func() {
+			viewer := &models.User{}
+			viewer.ID = 1
+			mockPolicy.On("Authorize", mock.Anything, policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_PROFILE, OwnerID: 5}, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockProfile.On("FindProfile", 5).Return(newProfile(5), nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(viewer)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_READ_PERSONAL_DATA})
+
+			req, _ := http.NewRequest("GET", "/api/users/5/profile", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.ProfileResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(*response.NationalID).To(Equal(nationalID))
+			Expect(response.BankAccount).To(BeNil())
+			Expect(response.PhoneNumbers).To(HaveLen(1))
+			Expect(response.Addresses).To(BeEmpty())
+		})
+
+		It("should deny profile of other user without permission", func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
+
+			req, _ := http.NewRequest("GET", "/api/users/5/profile", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockProfile.AssertNotCalled(GinkgoT(), "FindProfile", mock.Anything)
+		})
+	})
+
+	Describe("UpdateProfile", func() {
+		owner := &models.User{}
+		owner.ID = 5
+
+		BeforeEach(func() {
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(owner)
+		})
+
+		It("should let user update their own contact data", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockProfile.On("UpdateProfile", 5, mock.AnythingOfType("dtos.UpdateProfileRequest")).Return(newProfile(5), nil)
+
+			w := putProfile(`{"phoneNumbers": [{"type": "mobile", "number": "+49 151 2345678"}]}`)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			payload := mockProfile.Calls[0].Arguments.Get(1).(dtos.UpdateProfileRequest)
+			Expect(*payload.PhoneNumbers).To(HaveLen(1))
+			Expect(payload.Addresses).To(BeNil())
+		})
+
+		It("should refuse identity fields without personal data ability, own record included", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+
+			w := putProfile(`{"dateOfBirth": "1985-04-12T00:00:00Z", "nationalId": "850412-1234"}`)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf(user_constants.USER_FIELD_DATE_OF_BIRTH, user_constants.USER_FIELD_NATIONAL_ID))
+			mockProfile.AssertNotCalled(GinkgoT(), "UpdateProfile", mock.Anything, mock.Anything)
+		})
+
+		It("should not change bank account while impersonating", func() {
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(&auth_models.Impersonation{})
+
+			w := putProfile(`{"bankAccount": {"holder": "John", "number": "DE89370400440532013000"}}`)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockProfile.AssertNotCalled(GinkgoT(), "UpdateProfile", mock.Anything, mock.Anything)
+		})
+
+		It("should reject invalid emergency contact", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockProfile.On("UpdateProfile", 5, mock.Anything).Return(nil, services.ErrProfileEmergencyContact)
+
+			w := putProfile(`{"emergencyContacts": [{"name": "Jane"}]}`)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	auth_service "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_services "hr-system-go/internal/customfield/services"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/services"
+	"hr-system-go/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UserImportController creates users in bulk from CSV and XLSX files and exports the user list
+type UserImportController struct {
+	logger             *logger.Logger
+	service            services.UserImportServiceInterface
+	authService        auth_service.AuthServiceInterface
+	customFieldService customfield_services.CustomFieldServiceInterface
+}
+
+func NewUserImportController(logger *logger.Logger, service services.UserImportServiceInterface, authService auth_service.AuthServiceInterface, customFieldService customfield_services.CustomFieldServiceInterface) *UserImportController {
+	return &UserImportController{
+		logger:             logger,
+		service:            service,
+		authService:        authService,
+		customFieldService: customFieldService,
+	}
+}
+
+func (c *UserImportController) RegisterRoutes(r *gin.Engine) {
+	userRoutes := r.Group("/api/users")
+	{
+		userRoutes.POST("/import", c.authService.AuthUserAbilityWrapper(c.ImportUsers, constants.ABILITY_INVITE_USER))
+		userRoutes.GET("/export", c.authService.AuthUserAbilityWrapper(c.ExportUsers, constants.ABILITY_ALL_GRANTS_USER))
+	}
+}
+
+// ImportUsers takes multipart file with optional mapping JSON, dryRun previews users and errors without saving
+func (c *UserImportController) ImportUsers(ctx *gin.Context) {
+	errorMsg := "Failed to Import Users"
+	// body is cut off once it is larger than the file may be, gin would otherwise buffer all of it first
+	utils.LimitUploadBody(ctx, user_constants.USER_IMPORT_MAX_FILE_BYTES)
+	fileHeader, err := ctx.FormFile("file")
+	if utils.IsUploadTooLarge(err) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must not be larger than %d bytes", user_constants.USER_IMPORT_MAX_FILE_BYTES)})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Cannot not read import file", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size > user_constants.USER_IMPORT_MAX_FILE_BYTES {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must not be larger than %d bytes", user_constants.USER_IMPORT_MAX_FILE_BYTES)})
+		return
+	}
+	format := ctx.DefaultPostForm("format", utils.SpreadsheetFormat(fileHeader.Filename))
+
+	var payload dtos.ImportUsersRequest
+	if mapping := ctx.PostForm("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &payload.Mapping); err != nil {
+			c.logger.Error("Cannot not parse import mapping", zap.Error(err))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrUserImportMapping.Error()})
+			return
+		}
+	}
+	payload.DryRun, _ = strconv.ParseBool(ctx.DefaultPostForm("dryRun", ctx.Query("dryRun")))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.logger.Error("Cannot not open import file", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.logger.Error("Cannot not read import file", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	payload.Rows, err = utils.ReadSpreadsheet(format, content)
+	if err != nil {
+		c.logger.Error("Cannot not parse import file", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// importing role, department or salary needs the same abilities as setting them
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	forbidden := []string{}
+	for _, field := range payload.MappedFields() {
+		userField, privileged := user_constants.USER_IMPORT_PRIVILEGED_FIELDS[field]
+		if privileged && !access.CanWrite(userField) {
+			forbidden = append(forbidden, field)
+		}
+	}
+	if customColumns := payload.CustomFieldColumns(); len(customColumns) > 0 {
+		fields, err := c.customFieldService.FindFields(customfield_constants.CUSTOM_FIELD_ENTITY_USER)
+		if err != nil {
+			c.logger.Error("Cannot not find custom fields", zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+			return
+		}
+		values := map[string]interface{}{}
+		for key := range customColumns {
+			values[key] = nil
+		}
+		for _, key := range access.CustomFieldAccess(nil).ForbiddenValues(fields, values) {
+			forbidden = append(forbidden, customfield_constants.CUSTOM_FIELD_FILTER_PREFIX+key)
+		}
+	}
+	if len(forbidden) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": forbidden})
+		return
+	}
+
+	rows, err := c.service.ImportUsers(ctx, payload)
+	var importErr *services.UserImportError
+	switch {
+	case errors.As(err, &importErr) && payload.DryRun:
+		ctx.JSON(http.StatusOK, dtos.NewUserImportResponse(rows, true, importErr.Errors))
+	case errors.As(err, &importErr):
+		ctx.JSON(http.StatusUnprocessableEntity, dtos.NewUserImportResponse(rows, false, importErr.Errors))
+	case errors.Is(err, services.ErrUserImportMapping), errors.Is(err, services.ErrUserImportEmpty), errors.Is(err, services.ErrUserImportTooLarge), errors.Is(err, customfield_services.ErrCustomFieldValue):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.logger.Error("Cannot not import users", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+	case payload.DryRun:
+		ctx.JSON(http.StatusOK, dtos.NewUserImportResponse(rows, true, nil))
+	default:
+		ctx.JSON(http.StatusCreated, dtos.NewUserImportResponse(rows, false, nil))
+	}
+}
+
+// ExportUsers writes user list with the filters and order of the list endpoint as ?format=csv or xlsx
+func (c *UserImportController) ExportUsers(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", utils.SPREADSHEET_FORMAT_CSV)
+	contentType, ok := utils.SPREADSHEET_CONTENT_TYPES[format]
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrSpreadsheetFormat.Error()})
+		return
+	}
+
+	errorMsg := "Failed to Export Users"
+	pagination := utils.NewPagination(ctx)
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	if currentUser := c.authService.GetCurrentUser(ctx); currentUser != nil {
+		access.ViewerID = currentUser.ID
+	}
+	forbidden, err := forbiddenUserFilters(c.customFieldService, pagination, access)
+	if err != nil {
+		c.logger.Error("Cannot not find custom fields", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+	if len(forbidden) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": forbidden})
+		return
+	}
+
+	users, err := c.service.ExportUsers(&pagination)
+	switch {
+	case utils.IsQueryError(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.logger.Error("Cannot not export users", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().Format(time.DateOnly), format)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Header("Content-Type", contentType)
+	ctx.Status(http.StatusOK)
+	if err := utils.WriteSpreadsheet(ctx.Writer, format, dtos.UserExportRows(users, access)); err != nil {
+		c.logger.Error("Cannot not write user export", zap.Error(err))
+	}
+}
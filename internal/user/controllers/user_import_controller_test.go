@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/auth/constants"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	department_models "hr-system-go/internal/department/models"
+	user_constants "hr-system-go/internal/user/constants"
+	dtos "hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
+	mock_services "hr-system-go/mocks/services"
+	"hr-system-go/utils"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("UserImportController", func() {
+	var mockImport *mock_services.MockUserImportService
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEnv := env.NewEnv()
+		mockLogger = logger.NewLogger(mockEnv)
+		mockImport = &mock_services.MockUserImportService{}
+		mockAuthService = &mock_services.MockAuthService{}
+		router = gin.Default()
+		mockCustomField = &mock_services.MockCustomFieldService{}
+		NewUserImportController(mockLogger, mockImport, mockAuthService, mockCustomField).RegisterRoutes(router)
+	})
+
+	upload := func(filename string, content string, fields map[string]string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write([]byte(content))
+		for key, value := range fields {
+			writer.WriteField(key, value)
+		}
+		writer.Close()
+		req, _ := http.NewRequest("POST", "/api/users/import", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Describe("ImportUsers", func() {
+		csv := "Full Name,Work Email\nJane Doe,jane@example.com\nJohn Doe,not-an-email\n"
+		mapping := `{"name":"Full Name","email":"Work Email"}`
+
+		It("should preview valid rows and errors on dry run", func() {
+			user := &models.User{Name: "Jane Doe", Email: "jane@example.com"}
+			rows := []dtos.UserImportRow{{Row: 2, User: user}}
+			importErr := &services.UserImportError{Errors: []dtos.UserImportRowError{{Row: 3, Field: "email", Message: "email is invalid"}}}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER})
+			mockImport.On("ImportUsers", mock.MatchedBy(func(payload dtos.ImportUsersRequest) bool {
+				return payload.DryRun && len(payload.Rows) == 3 && payload.Mapping["email"] == "Work Email"
+			})).Return(rows, importErr)
+
+			w := upload("team.csv", csv, map[string]string{"mapping": mapping, "dryRun": "true"})
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.UserImportResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.TotalRows).To(Equal(2))
+			Expect(response.Imported).To(Equal(0))
+			Expect(response.Items).To(HaveLen(1))
+			Expect(response.Errors).To(ConsistOf(dtos.UserImportRowError{Row: 3, Field: "email", Message: "email is invalid"}))
+		})
+
+		It("should refuse to import file with invalid rows", func() {
+			importErr := &services.UserImportError{Errors: []dtos.UserImportRowError{{Row: 3, Field: "email", Message: "email is invalid"}}}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER})
+			mockImport.On("ImportUsers", mock.Anything).Return([]dtos.UserImportRow{}, importErr)
+
+			w := upload("team.csv", csv, map[string]string{"mapping": mapping})
+
+			Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should import valid file", func() {
+			user := &models.User{Name: "Jane Doe", Email: "jane@example.com"}
+			user.ID = 7
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER})
+			mockImport.On("ImportUsers", mock.Anything).Return([]dtos.UserImportRow{{Row: 2, User: user}}, nil)
+
+			w := upload("team.csv", "name,email\nJane Doe,jane@example.com\n", nil)
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			var response dtos.UserImportResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Imported).To(Equal(1))
+			Expect(response.Items[0].Id).To(Equal(uint(7)))
+		})
+
+		It("should refuse salary column without salary ability", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER})
+
+			w := upload("team.csv", "name,email,salary\nJane Doe,jane@example.com,5000\n", nil)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf("salary"))
+			mockImport.AssertNotCalled(GinkgoT(), "ImportUsers", mock.Anything)
+		})
+
+		It("should refuse custom field column viewer cannot write", func() {
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_INVITE_USER})
+			mockCustomField.On("FindFields", customfield_constants.CUSTOM_FIELD_ENTITY_USER).Return([]customfield_models.CustomField{
+				{Key: "tShirtSize", Type: customfield_constants.CUSTOM_FIELD_TYPE_TEXT},
+				{Key: "badgeNumber", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: constants.ABILITY_READ_PERSONAL_DATA},
+			}, nil)
+
+			w := upload("team.csv", "name,email,custom.tShirtSize,custom.badgeNumber\nJane Doe,jane@example.com,M,1234\n", nil)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf("custom.badgeNumber"))
+			mockImport.AssertNotCalled(GinkgoT(), "ImportUsers", mock.Anything)
+		})
+
+		It("should refuse xlsx unzipping beyond the part limit", func() {
+			bomb := &bytes.Buffer{}
+			archive := zip.NewWriter(bomb)
+			part, _ := archive.Create("xl/worksheets/sheet1.xml")
+			part.Write([]byte("<worksheet><sheetData>"))
+			padding := bytes.Repeat([]byte(" "), 1<<20)
+			for written := 0; written <= utils.XLSX_MAX_PART_BYTES; written += len(padding) {
+				part.Write(padding)
+			}
+			archive.Close()
+
+			w := upload("team.xlsx", bomb.String(), nil)
+
+			Expect(bomb.Len()).To(BeNumerically("<", user_constants.USER_IMPORT_MAX_FILE_BYTES))
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			mockImport.AssertNotCalled(GinkgoT(), "ImportUsers", mock.Anything)
+		})
+
+		It("should reject unknown file format", func() {
+			w := upload("team.txt", csv, nil)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("ExportUsers", func() {
+		It("should export users as xlsx without salary viewer cannot read", func() {
+			salary := 5000.0
+			users := []models.User{{Name: "Jane Doe", Email: "jane@example.com", JoinDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Salary: &salary}}
+			users[0].ID = 5
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&models.User{})
+			mockImport.On("ExportUsers", mock.AnythingOfType("*utils.Pagination")).Return(users, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/export?format=xlsx", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal(utils.SPREADSHEET_CONTENT_TYPES[utils.SPREADSHEET_FORMAT_XLSX]))
+			rows, err := utils.ReadSpreadsheet(utils.SPREADSHEET_FORMAT_XLSX, w.Body.Bytes())
+			Expect(err).To(BeNil())
+			Expect(rows).To(HaveLen(2))
+			Expect(rows[1][:5]).To(Equal([]string{"5", "Jane Doe", "jane@example.com", "", "2026-01-05"}))
+			Expect(rows[1][8]).To(BeEmpty())
+		})
+
+		It("should escape text which would run as formula", func() {
+			users := []models.User{
+				{Name: "=HYPERLINK(\"http://evil.example\")", Email: "@jane@example.com", Department: &department_models.Department{Name: "+IT"}},
+				{Name: "\tTab", Email: "-1+2@example.com"},
+			}
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&models.User{})
+			mockImport.On("ExportUsers", mock.AnythingOfType("*utils.Pagination")).Return(users, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users/export?format=csv", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			rows, err := utils.ReadSpreadsheet(utils.SPREADSHEET_FORMAT_CSV, w.Body.Bytes())
+			Expect(err).To(BeNil())
+			Expect(rows[1][1]).To(Equal("'=HYPERLINK(\"http://evil.example\")"))
+			Expect(rows[1][2]).To(Equal("'@jane@example.com"))
+			Expect(rows[1][6]).To(Equal("'+IT"))
+			Expect(rows[2][1]).To(Equal("'\tTab"))
+			Expect(rows[2][2]).To(Equal("'-1+2@example.com"))
+		})
+
+		It("should reject unknown format", func() {
+			req, _ := http.NewRequest("GET", "/api/users/export?format=pdf", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -1,9 +1,16 @@
 package controllers
 
 import (
+	"errors"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/internal/auth/constants"
 	auth_service "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_dtos "hr-system-go/internal/customfield/dtos"
+	customfield_services "hr-system-go/internal/customfield/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	policy_services "hr-system-go/internal/policy/services"
 	dtos "hr-system-go/internal/user/dtos"
 	"hr-system-go/internal/user/services"
 	"hr-system-go/utils"
@@ -15,16 +22,22 @@ import (
 )
 
 type UsersController struct {
-	logger      *logger.Logger
-	service     services.UserServiceInterface
-	authService auth_service.AuthServiceInterface
+	logger             *logger.Logger
+	service            services.UserServiceInterface
+	authService        auth_service.AuthServiceInterface
+	policyService      policy_services.PolicyServiceInterface
+	emailService       services.EmailVerificationServiceInterface
+	customFieldService customfield_services.CustomFieldServiceInterface
 }
 
-func NewUsersController(logger *logger.Logger, service services.UserServiceInterface, authService auth_service.AuthServiceInterface) *UsersController {
+func NewUsersController(logger *logger.Logger, service services.UserServiceInterface, authService auth_service.AuthServiceInterface, policyService policy_services.PolicyServiceInterface, emailService services.EmailVerificationServiceInterface, customFieldService customfield_services.CustomFieldServiceInterface) *UsersController {
 	return &UsersController{
-		logger:      logger,
-		service:     service,
-		authService: authService,
+		logger:             logger,
+		service:            service,
+		authService:        authService,
+		policyService:      policyService,
+		emailService:       emailService,
+		customFieldService: customFieldService,
 	}
 }
 
@@ -40,14 +53,30 @@ func (c *UsersController) RegisterRoutes(r *gin.Engine) {
 
 func (c *UsersController) listUsers(ctx *gin.Context) {
 	pagination := utils.NewPagination(ctx)
-	users, totalRows, err := c.service.FindUsers(&pagination)
+	access := c.fieldAccess(ctx)
+	forbidden, err := forbiddenUserFilters(c.customFieldService, pagination, access)
 	if err != nil {
+		c.logger.Error("Cannot not find custom fields", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find users Error"})
+		return
+	}
+	if len(forbidden) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Find users Error", "fields": forbidden})
+		return
+	}
+
+	users, totalRows, err := c.service.FindUsers(&pagination)
+	switch {
+	case utils.IsQueryError(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
 		c.logger.Error("Failed to Find User", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Find users Error"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dtos.NewUserListResponse(users, totalRows, pagination))
+	ctx.JSON(http.StatusOK, dtos.NewUserListResponse(users, totalRows, pagination, access))
 }
 
 func (c *UsersController) GetUser(ctx *gin.Context) {
@@ -60,7 +89,7 @@ func (c *UsersController) GetUser(ctx *gin.Context) {
 		return
 	}
 
-	if !c.authService.AbleToAccessOtherUserData(ctx, userID, constants.ABILITY_ALL_GRANTS_USER) {
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_READ) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
@@ -72,7 +101,7 @@ func (c *UsersController) GetUser(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dtos.NewUserResponse(user))
+	ctx.JSON(http.StatusOK, dtos.NewUserResponse(user, c.fieldAccess(ctx)))
 }
 
 func (c *UsersController) UpdateUser(ctx *gin.Context) {
@@ -85,7 +114,7 @@ func (c *UsersController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	if !c.authService.AbleToAccessOtherUserData(ctx, userID, constants.ABILITY_ALL_GRANTS_USER) {
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_UPDATE) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
@@ -96,15 +125,59 @@ func (c *UsersController) UpdateUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": errorMsg})
 		return
 	}
+	if payload.Salary != nil && c.authService.GetImpersonation(ctx) != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Salary cannot be changed while impersonating"})
+		return
+	}
+	if payload.PasswordLoginDisabled != nil && !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_CHANGE_SIGN_IN) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
+		return
+	}
+
+	access := c.fieldAccess(ctx)
+	fields := access.ForbiddenFields(payload)
+	if len(payload.CustomFields) > 0 {
+		customFields, err := c.customFieldService.FindFields(customfield_constants.CUSTOM_FIELD_ENTITY_USER)
+		if err != nil {
+			c.logger.Error("Cannot not find custom fields", zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
+			return
+		}
+		fields = append(fields, access.CustomFieldAccess(nil).ForbiddenValues(customFields, payload.CustomFields)...)
+	}
+	if len(fields) > 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg, "fields": fields})
+		return
+	}
 
-	user, err := c.service.UpdateUserByID(userID, payload)
+	// email is the login identifier, it changes only after confirmation from the new address
+	if payload.Email != nil {
+		if c.authService.GetImpersonation(ctx) != nil {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Email cannot be changed while impersonating"})
+			return
+		}
+		if !c.requestEmailChange(ctx, userID, *payload.Email) {
+			return
+		}
+		payload.Email = nil
+	}
+
+	user, err := c.service.UpdateUserByID(ctx, userID, payload)
+	if errors.Is(err, customfield_services.ErrCustomFieldValue) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, auth_service.ErrLastAdmin) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.logger.Error("Cannot not update user", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dtos.NewUserResponse(user))
+	ctx.JSON(http.StatusOK, dtos.NewUserResponse(user, access))
 }
 
 func (c *UsersController) DeleteUser(ctx *gin.Context) {
@@ -117,16 +190,70 @@ func (c *UsersController) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
-	if !c.authService.AbleToAccessOtherUserData(ctx, userID, constants.ABILITY_ALL_GRANTS_USER) {
+	if !c.authorize(ctx, userID, policy_constants.POLICY_ACTION_DELETE) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": errorMsg})
 		return
 	}
 
-	if err := c.service.DeleteUserByID(userID); err != nil {
+	if err := c.service.DeleteUserByID(ctx, userID); err != nil {
 		c.logger.Error("Cannot not delete user", zap.Error(err))
+		if errors.Is(err, auth_service.ErrLastAdmin) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errorMsg})
 		return
 	}
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+// requestEmailChange responds with error itself, unchanged email is not an error
+func (c *UsersController) requestEmailChange(ctx *gin.Context, userID int, email string) bool {
+	user, err := c.service.FindUserByID(userID)
+	if err != nil {
+		c.logger.Error("Cannot not find user", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Update User"})
+		return false
+	}
+
+	err = c.emailService.RequestEmailChange(ctx, user, email)
+	switch {
+	case err == nil, errors.Is(err, services.ErrEmailUnchanged):
+		return true
+	case errors.Is(err, services.ErrInvalidEmail):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmailTaken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.logger.Error("Cannot not request email change", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to Update User"})
+	}
+	return false
+}
+
+func (c *UsersController) authorize(ctx *gin.Context, userID int, action string) bool {
+	resource := policy_models.Resource{Type: policy_constants.POLICY_RESOURCE_USER, ID: uint(userID), OwnerID: uint(userID)}
+	return c.policyService.Authorize(ctx, resource, action).Allowed
+}
+
+func (c *UsersController) fieldAccess(ctx *gin.Context) dtos.FieldAccess {
+	access := dtos.FieldAccess{Abilities: c.authService.GetCurrentUserAbilities(ctx)}
+	if currentUser := c.authService.GetCurrentUser(ctx); currentUser != nil {
+		access.ViewerID = currentUser.ID
+	}
+	return access
+}
+
+// forbiddenUserFilters lists privileged filters of user list and export, custom fields are looked up only when filtered on
+func forbiddenUserFilters(customFieldService customfield_services.CustomFieldServiceInterface, pagination utils.Pagination, access dtos.FieldAccess) ([]string, error) {
+	forbidden := access.ForbiddenFilters(pagination)
+	if !customfield_dtos.HasFilters(pagination) {
+		return forbidden, nil
+	}
+	fields, err := customFieldService.FindFields(customfield_constants.CUSTOM_FIELD_ENTITY_USER)
+	if err != nil {
+		return nil, err
+	}
+	return append(forbidden, access.CustomFieldAccess(nil).ForbiddenFilters(fields, pagination)...), nil
+}
@@ -7,13 +7,24 @@ import (
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/internal/auth/constants"
+	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	customfield_services "hr-system-go/internal/customfield/services"
+	policy_constants "hr-system-go/internal/policy/constants"
+	policy_models "hr-system-go/internal/policy/models"
+	user_constants "hr-system-go/internal/user/constants"
 	dtos "hr-system-go/internal/user/dtos"
 	"hr-system-go/internal/user/models"
+	"hr-system-go/internal/user/services"
 	mock_services "hr-system-go/mocks/services"
+	"hr-system-go/utils"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
@@ -30,6 +41,9 @@ var (
 	userController  *UsersController
 	mockUserService *mock_services.MockUserService
 	mockAuthService *mock_services.MockAuthService
+	mockPolicy      *mock_services.MockPolicyService
+	mockEmail       *mock_services.MockEmailVerificationService
+	mockCustomField *mock_services.MockCustomFieldService
 	router          *gin.Engine
 	mockEnv         *env.Env
 	mockLogger      *logger.Logger
@@ -42,7 +56,10 @@ var _ = Describe("UserController", func() {
 		mockLogger = logger.NewLogger(mockEnv)
 		mockUserService = &mock_services.MockUserService{}
 		mockAuthService = &mock_services.MockAuthService{}
-		userController = NewUsersController(mockLogger, mockUserService, mockAuthService)
+		mockPolicy = &mock_services.MockPolicyService{}
+		mockEmail = &mock_services.MockEmailVerificationService{}
+		mockCustomField = &mock_services.MockCustomFieldService{}
+		userController = NewUsersController(mockLogger, mockUserService, mockAuthService, mockPolicy, mockEmail, mockCustomField)
 		router = gin.Default()
 		userController.RegisterRoutes(router)
 	})
@@ -56,6 +73,8 @@ var _ = Describe("UserController", func() {
 			totalRows := int64(2)
 
 			mockUserService.On("FindUsers", mock.AnythingOfType("*utils.Pagination")).Return(users, totalRows, nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&models.User{})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
 
 			req, _ := http.NewRequest("GET", "/api/users", nil)
 			w := httptest.NewRecorder()
@@ -69,6 +88,136 @@ var _ = Describe("UserController", func() {
 
 			Expect(response["Items"]).To(HaveLen(2))
 		})
+
+		It("should hide salary of other users without read salary ability", func() {
+			salary := 5000.0
+			dateOfBirth := time.Now().AddDate(-30, 0, -1)
+			users := []models.User{{Name: "User1", DateOfBirth: &dateOfBirth, Salary: &salary}}
+			users[0].ID = 2
+			viewer := &models.User{}
+			viewer.ID = 1
+
+			mockUserService.On("FindUsers", mock.AnythingOfType("*utils.Pagination")).Return(users, int64(1), nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(viewer)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER, constants.ABILITY_READ_PERSONAL_DATA})
+
+			req, _ := http.NewRequest("GET", "/api/users", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response dtos.UserListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			Expect(response.Items[0].Salary).To(BeNil())
+			Expect(*response.Items[0].Age).To(Equal(30))
+		})
+
+		It("should refuse salary filter without read salary ability", func() {
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&models.User{})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
+
+			req, _ := http.NewRequest("GET", "/api/users?salaryMin=5000&departmentId=2", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf("salaryMin"))
+			mockUserService.AssertNotCalled(GinkgoT(), "FindUsers", mock.Anything)
+		})
+
+		It("should show own custom fields and hide those of others without ability", func() {
+			badgeNumber := &customfield_models.CustomField{Key: "badgeNumber", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: constants.ABILITY_READ_PERSONAL_DATA}
+			tShirtSize := &customfield_models.CustomField{Key: "tShirtSize", Type: customfield_constants.CUSTOM_FIELD_TYPE_SELECT}
+			badge := 1234.0
+			values := func() []customfield_models.CustomFieldValue {
+				return []customfield_models.CustomFieldValue{{Field: badgeNumber, NumberValue: &badge}, {Field: tShirtSize, TextValue: "M"}}
+			}
+			users := []models.User{{Name: "Viewer", CustomFieldValues: values()}, {Name: "Colleague", CustomFieldValues: values()}}
+			users[0].ID, users[1].ID = 1, 2
+
+			mockUserService.On("FindUsers", mock.AnythingOfType("*utils.Pagination")).Return(users, int64(2), nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&users[0])
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
+
+			req, _ := http.NewRequest("GET", "/api/users", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.UserListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Items[0].CustomFields).To(Equal(map[string]interface{}{"badgeNumber": 1234.0, "tShirtSize": "M"}))
+			Expect(response.Items[1].CustomFields).To(Equal(map[string]interface{}{"tShirtSize": "M"}))
+		})
+
+		It("should refuse custom field filter without ability of the field", func() {
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&models.User{})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
+			mockCustomField.On("FindFields", customfield_constants.CUSTOM_FIELD_ENTITY_USER).Return([]customfield_models.CustomField{
+				{Key: "badgeNumber", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Ability: constants.ABILITY_READ_PERSONAL_DATA},
+				{Key: "tShirtSize", Type: customfield_constants.CUSTOM_FIELD_TYPE_SELECT},
+			}, nil)
+
+			req, _ := http.NewRequest("GET", "/api/users?custom.badgeNumberFrom=1000&custom.tShirtSize=M", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["fields"]).To(ConsistOf("custom.badgeNumberFrom"))
+			mockUserService.AssertNotCalled(GinkgoT(), "FindUsers", mock.Anything)
+		})
+
+		It("should pass filters and search to service and reject invalid values", func() {
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&models.User{})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
+			mockUserService.On("FindUsers", mock.MatchedBy(func(pagination *utils.Pagination) bool {
+				return pagination.Search == "jane" && pagination.HasFilter(user_constants.USER_FILTER_JOIN_DATE_FROM)
+			})).Return([]models.User{}, int64(0), &utils.FilterError{Param: user_constants.USER_FILTER_JOIN_DATE_FROM, Reason: "must be a date like 2026-01-31"})
+
+			req, _ := http.NewRequest("GET", "/api/users?search=+jane+&joinDateFrom=yesterday", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response["error"]).To(Equal("joinDateFrom must be a date like 2026-01-31"))
+		})
+
+		It("should return cursors and reject sort outside of sortable fields", func() {
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(&models.User{})
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ALL_GRANTS_USER})
+			mockUserService.On("FindUsers", mock.MatchedBy(func(pagination *utils.Pagination) bool {
+				return pagination.CursorMode && pagination.Sort == "name asc"
+			})).Run(func(args mock.Arguments) {
+				args.Get(0).(*utils.Pagination).NextCursor = "next"
+			}).Return([]models.User{{Name: "User1"}}, int64(3), nil)
+			mockUserService.On("FindUsers", mock.Anything).Return([]models.User{}, int64(0), &utils.SortError{Term: "salary", Fields: utils.SortFields{"name": "name"}})
+
+			req, _ := http.NewRequest("GET", "/api/users?sort=name+asc&cursor=", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response dtos.UserListResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(response.Pagination.NextCursor).To(Equal("next"))
+
+			req, _ = http.NewRequest("GET", "/api/users?sort=salary", nil)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
 	})
 
 	Describe("GetUser", func() {
@@ -77,8 +226,10 @@ var _ = Describe("UserController", func() {
 			user := &models.User{Name: "User1"}
 			user.ID = uint(userID)
 
-			mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_USER).Return(true)
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
 			mockUserService.On("FindUserByID", userID).Return(user, nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
 
 			req, _ := http.NewRequest("GET", "/api/users/"+strconv.Itoa(userID), nil)
 			w := httptest.NewRecorder()
@@ -93,10 +244,32 @@ var _ = Describe("UserController", func() {
 			Expect(response["Name"]).To(Equal(user.Name))
 		})
 
+		It("should show own salary without read salary ability", func() {
+			userID := 1
+			salary := 5000.0
+			user := &models.User{Name: "User1", Salary: &salary}
+			user.ID = uint(userID)
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: true})
+			mockUserService.On("FindUserByID", userID).Return(user, nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+
+			req, _ := http.NewRequest("GET", "/api/users/"+strconv.Itoa(userID), nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			var response dtos.UserResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			Expect(*response.Salary).To(Equal(salary))
+		})
+
 		It("should return forbidden when not able to access user data", func() {
 			userID := 1
 
-			mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_USER).Return(false)
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_READ).Return(policy_models.Decision{Allowed: false})
 
 			req, _ := http.NewRequest("GET", "/api/users/"+strconv.Itoa(userID), nil)
 			w := httptest.NewRecorder()
@@ -115,8 +288,10 @@ var _ = Describe("UserController", func() {
 			updatedUser := &models.User{Name: updatedName}
 			updatedUser.ID = uint(userID)
 
-			mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_USER).Return(true)
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
 			mockUserService.On("UpdateUserByID", userID, payload).Return(updatedUser, nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(updatedUser)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
 
 			jsonPayload, _ := json.Marshal(payload)
 			req, _ := http.NewRequest("PUT", "/api/users/"+strconv.Itoa(userID), bytes.NewBuffer(jsonPayload))
@@ -132,13 +307,185 @@ var _ = Describe("UserController", func() {
 
 			Expect(response["Name"]).To(Equal(updatedUser.Name))
 		})
+
+		It("should keep email until change is confirmed from new address", func() {
+			userID := 1
+			newEmail := "new@example.com"
+			payload := dtos.UpdateUserRequest{Email: &newEmail}
+			user := &models.User{Name: "User1", Email: "old@example.com"}
+			user.ID = uint(userID)
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockUserService.On("FindUserByID", userID).Return(user, nil)
+			mockEmail.On("RequestEmailChange", user, newEmail).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(0).(*models.User).PendingEmail = &newEmail
+			})
+			mockUserService.On("UpdateUserByID", userID, dtos.UpdateUserRequest{}).Return(user, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("PUT", "/api/users/"+strconv.Itoa(userID), bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response dtos.UserResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			Expect(*response.Email).To(Equal("old@example.com"))
+			Expect(*response.PendingEmail).To(Equal(newEmail))
+		})
+
+		It("should reject email used by another user", func() {
+			userID := 1
+			newEmail := "taken@example.com"
+			payload := dtos.UpdateUserRequest{Email: &newEmail}
+			user := &models.User{Name: "User1", Email: "old@example.com"}
+			user.ID = uint(userID)
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(user)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockUserService.On("FindUserByID", userID).Return(user, nil)
+			mockEmail.On("RequestEmailChange", user, newEmail).Return(services.ErrEmailTaken)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("PUT", "/api/users/"+strconv.Itoa(userID), bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			mockUserService.AssertNotCalled(GinkgoT(), "UpdateUserByID", mock.Anything, mock.Anything)
+		})
+
+		It("should reject privileged fields without matching abilities", func() {
+			userID := 1
+			salary := 99999.0
+			status := "inactive"
+			departmentID := 2
+			payload := dtos.UpdateUserRequest{Salary: &salary, Status: &status, DepartmentID: &departmentID}
+			currentUser := &models.User{}
+			currentUser.ID = uint(userID)
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_WRITE_SALARY})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("PUT", "/api/users/"+strconv.Itoa(userID), bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			Expect(response["fields"]).To(Equal([]interface{}{"status", "departmentId"}))
+			mockUserService.AssertNotCalled(GinkgoT(), "UpdateUserByID", mock.Anything, mock.Anything)
+		})
+
+		It("should reject custom field value with message of service", func() {
+			payload := dtos.UpdateUserRequest{CustomFields: map[string]interface{}{"tShirtSize": "XXXL"}}
+			currentUser := &models.User{}
+			currentUser.ID = 1
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{})
+			mockCustomField.On("FindFields", customfield_constants.CUSTOM_FIELD_ENTITY_USER).Return([]customfield_models.CustomField{
+				{Key: "tShirtSize", Type: customfield_constants.CUSTOM_FIELD_TYPE_SELECT, Options: []string{"S", "M", "L"}},
+			}, nil)
+			mockUserService.On("UpdateUserByID", 1, payload).Return(nil, &customfield_services.CustomFieldError{Key: "tShirtSize", Reason: "must be one of S, M, L"})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("PUT", "/api/users/1", bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("tShirtSize must be one of S, M, L"))
+		})
+
+		It("should let admin change privileged fields", func() {
+			userID := 2
+			salary := 99999.0
+			payload := dtos.UpdateUserRequest{Salary: &salary}
+			updatedUser := &models.User{Salary: &salary}
+			updatedUser.ID = uint(userID)
+			currentUser := &models.User{}
+			currentUser.ID = 1
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(nil)
+			mockAuthService.On("GetCurrentUser", mock.Anything).Return(currentUser)
+			mockAuthService.On("GetCurrentUserAbilities", mock.Anything).Return([]string{constants.ABILITY_ADMIN})
+			mockUserService.On("UpdateUserByID", userID, payload).Return(updatedUser, nil)
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("PUT", "/api/users/"+strconv.Itoa(userID), bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response dtos.UserResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			Expect(*response.Salary).To(Equal(salary))
+		})
+
+		It("should not change salary while impersonating", func() {
+			userID := 1
+			salary := 99999.0
+			payload := dtos.UpdateUserRequest{Salary: &salary}
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockAuthService.On("GetImpersonation", mock.Anything).Return(&auth_models.Impersonation{})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("PUT", "/api/users/"+strconv.Itoa(userID), bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockUserService.AssertNotCalled(GinkgoT(), "UpdateUserByID", mock.Anything, mock.Anything)
+		})
+
+		It("should not let user re-enable own password login", func() {
+			userID := 1
+			disabled := false
+			payload := dtos.UpdateUserRequest{PasswordLoginDisabled: &disabled}
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_UPDATE).Return(policy_models.Decision{Allowed: true})
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_CHANGE_SIGN_IN).Return(policy_models.Decision{Allowed: false})
+
+			jsonPayload, _ := json.Marshal(payload)
+			req, _ := http.NewRequest("PUT", "/api/users/"+strconv.Itoa(userID), bytes.NewBuffer(jsonPayload))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			mockUserService.AssertNotCalled(GinkgoT(), "UpdateUserByID", mock.Anything, mock.Anything)
+		})
 	})
 
 	Describe("DeleteUser", func() {
 		It("should delete a user", func() {
 			userID := 1
 
-			mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_USER).Return(true)
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
 			mockUserService.On("DeleteUserByID", userID).Return(nil)
 
 			req, _ := http.NewRequest("DELETE", "/api/users/"+strconv.Itoa(userID), nil)
@@ -152,7 +499,7 @@ var _ = Describe("UserController", func() {
 		It("should return an error when delete fails", func() {
 			userID := 1
 
-			mockAuthService.On("AbleToAccessOtherUserData", mock.Anything, userID, constants.ABILITY_ALL_GRANTS_USER).Return(true)
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
 			mockUserService.On("DeleteUserByID", userID).Return(errors.New("delete failed"))
 
 			req, _ := http.NewRequest("DELETE", "/api/users/"+strconv.Itoa(userID), nil)
@@ -162,5 +509,19 @@ var _ = Describe("UserController", func() {
 
 			Expect(w.Code).To(Equal(http.StatusInternalServerError))
 		})
+
+		It("should refuse to delete the last admin", func() {
+			userID := 1
+
+			mockPolicy.On("Authorize", mock.Anything, mock.Anything, policy_constants.POLICY_ACTION_DELETE).Return(policy_models.Decision{Allowed: true})
+			mockUserService.On("DeleteUserByID", userID).Return(auth_services.ErrLastAdmin)
+
+			req, _ := http.NewRequest("DELETE", "/api/users/"+strconv.Itoa(userID), nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
 	})
 })
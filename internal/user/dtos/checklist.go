@@ -0,0 +1,207 @@
+package dtos
+
+import (
+	auth_constants "hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"time"
+)
+
+type ChecklistTemplateResponse struct {
+	Id           uint
+	Name         string
+	Kind         string
+	DepartmentId *uint
+	Tasks        []*ChecklistTemplateTaskResponse
+}
+
+type ChecklistTemplateTaskResponse struct {
+	Id            uint
+	Title         string
+	Description   string
+	Assignee      string
+	DueOffsetDays int
+}
+
+type ChecklistResponse struct {
+	Id          uint
+	UserId      uint
+	Kind        string
+	Name        string
+	AnchorDate  time.Time
+	CancelledAt *time.Time
+	Done        int
+	Total       int
+	Tasks       []*ChecklistTaskResponse
+}
+
+type ChecklistTaskListResponse struct {
+	Items      []*ChecklistTaskResponse
+	Pagination utils.PaginationResult
+}
+
+type ChecklistTaskResponse struct {
+	Id            uint
+	ChecklistId   uint
+	UserId        uint
+	UserName      *string
+	Title         string
+	Description   string
+	Assignee      string
+	AssigneeId    *uint
+	DueDate       time.Time
+	Status        string
+	CompletedAt   *time.Time
+	CompletedById *uint
+}
+
+// ChecklistTemplateRequest replaces all tasks of template, tasks keep order of the list
+type ChecklistTemplateRequest struct {
+	Name         string                         `json:"name"`
+	Kind         string                         `json:"kind"`
+	DepartmentID *uint                          `json:"departmentId,omitempty"`
+	Tasks        []ChecklistTemplateTaskRequest `json:"tasks"`
+}
+
+type ChecklistTemplateTaskRequest struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	Assignee      string `json:"assignee"`
+	DueOffsetDays int    `json:"dueOffsetDays"`
+}
+
+// ChecklistTaskFilter empty fields match all tasks
+type ChecklistTaskFilter struct {
+	UserID   int
+	Assignee string
+	Status   string
+	// Access limits tasks to the ones viewer can work on, nil lists all of them
+	Access *TaskAccess
+}
+
+// TaskAccess decides which checklist tasks viewer can work on. HR runs the process and can
+// complete any task, team members complete tasks of their team and managers their own
+type TaskAccess struct {
+	ViewerID  uint
+	Abilities []string
+}
+
+func (a TaskAccess) CanCompleteAll() bool {
+	return a.hasAbility(auth_constants.ABILITY_WRITE_EMPLOYMENT)
+}
+
+func (a TaskAccess) CanComplete(task *models.ChecklistTask) bool {
+	if a.CanCompleteAll() {
+		return true
+	}
+	if task.AssigneeID != nil && *task.AssigneeID == a.ViewerID {
+		return true
+	}
+	ability, ok := constants.CHECKLIST_ASSIGNEE_ABILITIES[task.Assignee]
+	return ok && a.hasAbility(ability)
+}
+
+// Teams lists assignee teams viewer belongs to
+func (a TaskAccess) Teams() []string {
+	teams := []string{}
+	for _, assignee := range constants.CHECKLIST_ASSIGNEES {
+		if ability, ok := constants.CHECKLIST_ASSIGNEE_ABILITIES[assignee]; ok && a.hasAbility(ability) {
+			teams = append(teams, assignee)
+		}
+	}
+	return teams
+}
+
+func (a TaskAccess) hasAbility(requiredAbility string) bool {
+	return FieldAccess{ViewerID: a.ViewerID, Abilities: a.Abilities}.hasAbility(requiredAbility)
+}
+
+func NewChecklistTemplateResponse(template *models.ChecklistTemplate) *ChecklistTemplateResponse {
+	tasks := []*ChecklistTemplateTaskResponse{}
+	for _, task := range template.Tasks {
+		tasks = append(tasks, &ChecklistTemplateTaskResponse{
+			Id:            task.ID,
+			Title:         task.Title,
+			Description:   task.Description,
+			Assignee:      task.Assignee,
+			DueOffsetDays: task.DueOffsetDays,
+		})
+	}
+
+	return &ChecklistTemplateResponse{
+		Id:           template.ID,
+		Name:         template.Name,
+		Kind:         template.Kind,
+		DepartmentId: template.DepartmentID,
+		Tasks:        tasks,
+	}
+}
+
+func NewChecklistTemplateListResponse(templates []models.ChecklistTemplate) []*ChecklistTemplateResponse {
+	items := []*ChecklistTemplateResponse{}
+	for i := range templates {
+		items = append(items, NewChecklistTemplateResponse(&templates[i]))
+	}
+	return items
+}
+
+func NewChecklistResponse(checklist *models.Checklist) *ChecklistResponse {
+	res := &ChecklistResponse{
+		Id:          checklist.ID,
+		UserId:      checklist.UserID,
+		Kind:        checklist.Kind,
+		Name:        checklist.Name,
+		AnchorDate:  checklist.AnchorDate,
+		CancelledAt: checklist.CancelledAt,
+		Total:       len(checklist.Tasks),
+		Tasks:       []*ChecklistTaskResponse{},
+	}
+	for i := range checklist.Tasks {
+		if checklist.Tasks[i].CompletedAt != nil {
+			res.Done++
+		}
+		res.Tasks = append(res.Tasks, NewChecklistTaskResponse(&checklist.Tasks[i]))
+	}
+	return res
+}
+
+func NewChecklistListResponse(checklists []models.Checklist) []*ChecklistResponse {
+	items := []*ChecklistResponse{}
+	for i := range checklists {
+		items = append(items, NewChecklistResponse(&checklists[i]))
+	}
+	return items
+}
+
+func NewChecklistTaskListResponse(tasks []models.ChecklistTask, totalRows int64, pagination utils.Pagination) *ChecklistTaskListResponse {
+	items := []*ChecklistTaskResponse{}
+	for i := range tasks {
+		items = append(items, NewChecklistTaskResponse(&tasks[i]))
+	}
+
+	return &ChecklistTaskListResponse{
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
+	}
+}
+
+func NewChecklistTaskResponse(task *models.ChecklistTask) *ChecklistTaskResponse {
+	res := &ChecklistTaskResponse{
+		Id:            task.ID,
+		ChecklistId:   task.ChecklistID,
+		UserId:        task.UserID,
+		Title:         task.Title,
+		Description:   task.Description,
+		Assignee:      task.Assignee,
+		AssigneeId:    task.AssigneeID,
+		DueDate:       task.DueDate,
+		Status:        task.CurrentStatus(),
+		CompletedAt:   task.CompletedAt,
+		CompletedById: task.CompletedByID,
+	}
+	if task.User != nil {
+		res.UserName = &task.User.Name
+	}
+	return res
+}
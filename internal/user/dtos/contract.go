@@ -0,0 +1,110 @@
+package dtos
+
+import (
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"sort"
+	"time"
+)
+
+type ContractListResponse struct {
+	UserId uint
+	Items  []*ContractResponse
+}
+
+type ContractResponse struct {
+	Id                    uint
+	Type                  string
+	StartDate             time.Time
+	EndDate               *time.Time
+	ProbationEndDate      *time.Time
+	ProbationDecision     *string
+	ProbationDecidedAt    *time.Time
+	ProbationDecidedById  *uint
+	ProbationDecisionNote string
+	ProbationExtensions   int
+	Note                  string
+}
+
+// UpcomingContractDateResponse is probation or contract end falling within the requested days
+type UpcomingContractDateResponse struct {
+	ContractId uint
+	UserId     uint
+	UserName   string
+	Kind       string
+	Date       time.Time
+}
+
+type UpcomingContractDatesResponse struct {
+	Items []*UpcomingContractDateResponse
+}
+
+// CreateContractRequest endDate is required for fixed term contracts only
+type CreateContractRequest struct {
+	Type             string     `json:"type"`
+	StartDate        *time.Time `json:"startDate,omitempty"`
+	EndDate          *time.Time `json:"endDate,omitempty"`
+	ProbationEndDate *time.Time `json:"probationEndDate,omitempty"`
+	Note             string     `json:"note"`
+}
+
+// ProbationDecisionRequest probationEndDate is the new end when extending,
+// terminationDate is the last working day when ending and defaults to probation end
+type ProbationDecisionRequest struct {
+	Decision         string     `json:"decision"`
+	ProbationEndDate *time.Time `json:"probationEndDate,omitempty"`
+	TerminationDate  *time.Time `json:"terminationDate,omitempty"`
+	Note             string     `json:"note"`
+}
+
+func NewContractListResponse(userID uint, contracts []models.Contract) *ContractListResponse {
+	items := []*ContractResponse{}
+	for i := range contracts {
+		items = append(items, NewContractResponse(&contracts[i]))
+	}
+	return &ContractListResponse{UserId: userID, Items: items}
+}
+
+func NewContractResponse(contract *models.Contract) *ContractResponse {
+	return &ContractResponse{
+		Id:                    contract.ID,
+		Type:                  contract.Type,
+		StartDate:             contract.StartDate,
+		EndDate:               contract.EndDate,
+		ProbationEndDate:      contract.ProbationEndDate,
+		ProbationDecision:     contract.ProbationDecision,
+		ProbationDecidedAt:    contract.ProbationDecidedAt,
+		ProbationDecidedById:  contract.ProbationDecidedByID,
+		ProbationDecisionNote: contract.ProbationDecisionNote,
+		ProbationExtensions:   contract.ProbationExtensions,
+		Note:                  contract.Note,
+	}
+}
+
+// NewUpcomingContractDatesResponse lists open probation ends and contract ends from first to last day
+func NewUpcomingContractDatesResponse(contracts []models.Contract, until time.Time) *UpcomingContractDatesResponse {
+	today := models.EmploymentDate(time.Now())
+	within := func(date *time.Time) bool {
+		return date != nil && !date.Before(today) && !date.After(until)
+	}
+
+	items := []*UpcomingContractDateResponse{}
+	for _, contract := range contracts {
+		item := func(kind string, date time.Time) *UpcomingContractDateResponse {
+			res := &UpcomingContractDateResponse{ContractId: contract.ID, UserId: contract.UserID, Kind: kind, Date: date}
+			if contract.User != nil {
+				res.UserName = contract.User.Name
+			}
+			return res
+		}
+		if contract.ProbationOpen() && within(contract.ProbationEndDate) {
+			items = append(items, item(constants.CONTRACT_REMINDER_KIND_PROBATION, *contract.ProbationEndDate))
+		}
+		if within(contract.EndDate) {
+			items = append(items, item(constants.CONTRACT_REMINDER_KIND_END, *contract.EndDate))
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Date.Before(items[j].Date) })
+
+	return &UpcomingContractDatesResponse{Items: items}
+}
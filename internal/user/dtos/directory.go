@@ -0,0 +1,86 @@
+package dtos
+
+import (
+	"fmt"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+)
+
+// DirectoryItem is user with directory entry and employment record in effect, both may be nil
+type DirectoryItem struct {
+	User       *models.User
+	Entry      *models.DirectoryEntry
+	Employment *models.EmploymentRecord
+}
+
+type DirectoryListResponse struct {
+	Items      []*DirectoryEntryResponse
+	Pagination utils.PaginationResult
+}
+
+// DirectoryEntryResponse has only what every employee may see of a colleague
+type DirectoryEntryResponse struct {
+	UserId         uint
+	Name           string
+	JobTitle       string
+	DepartmentId   *uint
+	DepartmentName *string
+	ManagerId      *uint
+	ManagerName    *string
+	Office         string
+	PhoneExtension string
+	Skills         []string
+	// PhotoUrls by size, empty until photo is uploaded
+	PhotoUrls map[string]string
+}
+
+// UpdateDirectoryEntryRequest changes fields which are set, an empty skills list clears them
+type UpdateDirectoryEntryRequest struct {
+	Office         *string   `json:"office,omitempty"`
+	PhoneExtension *string   `json:"phoneExtension,omitempty"`
+	Skills         *[]string `json:"skills,omitempty"`
+}
+
+func NewDirectoryListResponse(items []DirectoryItem, totalRows int64, pagination utils.Pagination) *DirectoryListResponse {
+	res := &DirectoryListResponse{
+		Items:      []*DirectoryEntryResponse{},
+		Pagination: pagination.Result(totalRows),
+	}
+	for _, item := range items {
+		res.Items = append(res.Items, NewDirectoryEntryResponse(item))
+	}
+	return res
+}
+
+func NewDirectoryEntryResponse(item DirectoryItem) *DirectoryEntryResponse {
+	res := &DirectoryEntryResponse{
+		UserId:    item.User.ID,
+		Name:      item.User.Name,
+		Skills:    []string{},
+		PhotoUrls: map[string]string{},
+	}
+	if item.User.Department != nil {
+		res.DepartmentId, res.DepartmentName = &item.User.Department.ID, &item.User.Department.Name
+	}
+	if item.Employment != nil {
+		res.JobTitle = item.Employment.JobTitle
+		if item.Employment.Manager != nil {
+			res.ManagerId, res.ManagerName = &item.Employment.Manager.ID, &item.Employment.Manager.Name
+		}
+	}
+	if item.Entry != nil {
+		res.Office = item.Entry.Office
+		res.PhoneExtension = item.Entry.PhoneExtension
+		if item.Entry.Skills != nil {
+			res.Skills = item.Entry.Skills
+		}
+		if item.Entry.PhotoKey != nil {
+			for size := range constants.DIRECTORY_PHOTO_PIXELS {
+				// version changes with the photo, so browsers may cache each one
+				res.PhotoUrls[size] = fmt.Sprintf("/api/directory/%d/photo?size=%s&v=%d", item.User.ID, size, item.Entry.UpdatedAt.Unix())
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,159 @@
+package dtos
+
+import (
+	"fmt"
+	"hr-system-go/internal/user/models"
+	"io"
+	"time"
+)
+
+type DocumentCategoryResponse struct {
+	Id             uint
+	Name           string
+	Description    string
+	ReadAbility    string
+	WriteAbility   string
+	SelfService    bool
+	ExpiryRequired bool
+}
+
+type DocumentCategoryListResponse struct {
+	Items []*DocumentCategoryResponse
+}
+
+type DocumentCategoryRequest struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	ReadAbility    string `json:"readAbility"`
+	WriteAbility   string `json:"writeAbility"`
+	SelfService    bool   `json:"selfService"`
+	ExpiryRequired bool   `json:"expiryRequired"`
+}
+
+type DocumentResponse struct {
+	Id           uint
+	UserId       uint
+	CategoryId   uint
+	CategoryName string
+	FileName     string
+	ContentType  string
+	Size         int64
+	Checksum     string
+	Note         string
+	ExpiresOn    *time.Time
+	UploadedById *uint
+	CreatedAt    time.Time
+	DownloadUrl  string
+}
+
+type DocumentListResponse struct {
+	UserId uint
+	Items  []*DocumentResponse
+}
+
+// ExpiringDocumentResponse is document expiring within the requested days
+type ExpiringDocumentResponse struct {
+	DocumentId   uint
+	UserId       uint
+	UserName     string
+	CategoryName string
+	FileName     string
+	ExpiresOn    time.Time
+}
+
+type ExpiringDocumentsResponse struct {
+	Items []*ExpiringDocumentResponse
+}
+
+// UploadDocumentRequest is read from multipart form, Content is streamed to storage
+type UploadDocumentRequest struct {
+	CategoryID  uint
+	FileName    string
+	ContentType string
+	// Checksum is optional hex SHA-256 computed by client, upload fails when content does not match it
+	Checksum     string
+	Note         string
+	ExpiresOn    *time.Time
+	UploadedByID uint
+	Content      io.Reader
+}
+
+// UpdateDocumentRequest changes fields which are set, new expiry date is reminded again
+type UpdateDocumentRequest struct {
+	ExpiresOn *time.Time `json:"expiresOn,omitempty"`
+	Note      *string    `json:"note,omitempty"`
+}
+
+func NewDocumentCategoryListResponse(categories []models.DocumentCategory) *DocumentCategoryListResponse {
+	items := []*DocumentCategoryResponse{}
+	for i := range categories {
+		items = append(items, NewDocumentCategoryResponse(&categories[i]))
+	}
+	return &DocumentCategoryListResponse{Items: items}
+}
+
+func NewDocumentCategoryResponse(category *models.DocumentCategory) *DocumentCategoryResponse {
+	return &DocumentCategoryResponse{
+		Id:             category.ID,
+		Name:           category.Name,
+		Description:    category.Description,
+		ReadAbility:    category.ReadAbility,
+		WriteAbility:   category.WriteAbility,
+		SelfService:    category.SelfService,
+		ExpiryRequired: category.ExpiryRequired,
+	}
+}
+
+// NewDocumentListResponse leaves out documents in categories viewer cannot read
+func NewDocumentListResponse(userID uint, documents []models.Document, access FieldAccess) *DocumentListResponse {
+	items := []*DocumentResponse{}
+	for i := range documents {
+		if access.CanReadDocuments(documents[i].Category, documents[i].UserID) {
+			items = append(items, NewDocumentResponse(&documents[i]))
+		}
+	}
+	return &DocumentListResponse{UserId: userID, Items: items}
+}
+
+func NewDocumentResponse(document *models.Document) *DocumentResponse {
+	res := &DocumentResponse{
+		Id:           document.ID,
+		UserId:       document.UserID,
+		CategoryId:   document.CategoryID,
+		FileName:     document.FileName,
+		ContentType:  document.ContentType,
+		Size:         document.Size,
+		Checksum:     document.Checksum,
+		Note:         document.Note,
+		ExpiresOn:    document.ExpiresOn,
+		UploadedById: document.UploadedByID,
+		CreatedAt:    document.CreatedAt,
+		DownloadUrl:  fmt.Sprintf("/api/users/%d/documents/%d/download", document.UserID, document.ID),
+	}
+	if document.Category != nil {
+		res.CategoryName = document.Category.Name
+	}
+	return res
+}
+
+// NewExpiringDocumentsResponse leaves out documents in categories viewer cannot read
+func NewExpiringDocumentsResponse(documents []models.Document, access FieldAccess) *ExpiringDocumentsResponse {
+	items := []*ExpiringDocumentResponse{}
+	for _, document := range documents {
+		if !access.CanReadDocuments(document.Category, document.UserID) {
+			continue
+		}
+		item := &ExpiringDocumentResponse{
+			DocumentId:   document.ID,
+			UserId:       document.UserID,
+			CategoryName: document.Category.Name,
+			FileName:     document.FileName,
+			ExpiresOn:    *document.ExpiresOn,
+		}
+		if document.User != nil {
+			item.UserName = document.User.Name
+		}
+		items = append(items, item)
+	}
+	return &ExpiringDocumentsResponse{Items: items}
+}
@@ -0,0 +1,97 @@
+package dtos
+
+import (
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"time"
+)
+
+type EmploymentTimelineResponse struct {
+	UserId uint
+	Items  []*EmploymentRecordResponse
+}
+
+type EmploymentRecordResponse struct {
+	Id            uint
+	EffectiveFrom time.Time
+	// EffectiveTo is the last day before next record, nil for the latest one
+	EffectiveTo    *time.Time
+	Status         string
+	JobTitle       string
+	Grade          string
+	EmploymentType string
+	FTE            float64
+	Salary         *float64
+	DepartmentId   *uint
+	DepartmentName *string
+	ManagerId      *uint
+	ManagerName    *string
+	Reason         string
+}
+
+// CreateEmploymentRecordRequest fields which are not set are taken over from the record in effect before
+type CreateEmploymentRecordRequest struct {
+	EffectiveFrom  *time.Time `json:"effectiveFrom,omitempty"`
+	JobTitle       *string    `json:"jobTitle,omitempty"`
+	Grade          *string    `json:"grade,omitempty"`
+	EmploymentType *string    `json:"employmentType,omitempty"`
+	FTE            *float64   `json:"fte,omitempty"`
+	Salary         *float64   `json:"salary,omitempty"`
+	DepartmentID   *uint      `json:"departmentId,omitempty"`
+	ManagerID      *uint      `json:"managerId,omitempty"`
+	Reason         string     `json:"reason"`
+}
+
+// NewEmploymentTimelineResponse expects records ordered by effective date
+func NewEmploymentTimelineResponse(userID uint, records []models.EmploymentRecord, access FieldAccess) *EmploymentTimelineResponse {
+	owner := &models.User{}
+	owner.ID = userID
+	today := models.EmploymentDate(time.Now())
+
+	items := []*EmploymentRecordResponse{}
+	for i := range records {
+		item := NewEmploymentRecordResponse(&records[i], owner, access)
+		if i+1 < len(records) {
+			effectiveTo := records[i+1].EffectiveFrom.AddDate(0, 0, -1)
+			item.EffectiveTo = &effectiveTo
+		}
+		switch {
+		case records[i].EffectiveFrom.After(today):
+			item.Status = constants.EMPLOYMENT_STATUS_SCHEDULED
+		case item.EffectiveTo != nil && item.EffectiveTo.Before(today):
+			item.Status = constants.EMPLOYMENT_STATUS_PAST
+		}
+		items = append(items, item)
+	}
+
+	return &EmploymentTimelineResponse{UserId: userID, Items: items}
+}
+
+// NewEmploymentRecordResponse hides salary from viewers who cannot read salary of owner
+func NewEmploymentRecordResponse(record *models.EmploymentRecord, owner *models.User, access FieldAccess) *EmploymentRecordResponse {
+	res := &EmploymentRecordResponse{
+		Id:             record.ID,
+		EffectiveFrom:  record.EffectiveFrom,
+		Status:         constants.EMPLOYMENT_STATUS_CURRENT,
+		JobTitle:       record.JobTitle,
+		Grade:          record.Grade,
+		EmploymentType: record.EmploymentType,
+		FTE:            record.FTE,
+		Salary:         record.Salary,
+		DepartmentId:   record.DepartmentID,
+		ManagerId:      record.ManagerID,
+		Reason:         record.Reason,
+	}
+
+	if !access.CanRead(owner, constants.USER_FIELD_SALARY) {
+		res.Salary = nil
+	}
+	if record.Department != nil {
+		res.DepartmentName = &record.Department.Name
+	}
+	if record.Manager != nil {
+		res.ManagerName = &record.Manager.Name
+	}
+
+	return res
+}
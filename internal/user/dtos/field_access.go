@@ -0,0 +1,105 @@
+package dtos
+
+import (
+	auth_constants "hr-system-go/internal/auth/constants"
+	customfield_dtos "hr-system-go/internal/customfield/dtos"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"slices"
+)
+
+// FieldAccess decides which sensitive user fields viewer can read and change
+type FieldAccess struct {
+	ViewerID  uint
+	Abilities []string
+}
+
+// CanRead is true for own record and for fields viewer has read ability of
+func (a FieldAccess) CanRead(user *models.User, field string) bool {
+	if user.ID == a.ViewerID {
+		return true
+	}
+	return a.CanReadOthers(field)
+}
+
+// CanReadOthers is true when viewer sees field of every user, like filtering lists on it
+func (a FieldAccess) CanReadOthers(field string) bool {
+	return a.hasAbility(constants.USER_FIELD_READ_ABILITIES[field])
+}
+
+// ForbiddenFilters lists privileged filters in query which viewer cannot use
+func (a FieldAccess) ForbiddenFilters(pagination utils.Pagination) []string {
+	forbidden := []string{}
+	for param, field := range constants.USER_PRIVILEGED_FILTERS {
+		if pagination.HasFilter(param) && !a.CanReadOthers(field) {
+			forbidden = append(forbidden, param)
+		}
+	}
+	slices.Sort(forbidden)
+	return forbidden
+}
+
+// CustomFieldAccess lets viewer see every custom field of own record, like other fields
+func (a FieldAccess) CustomFieldAccess(user *models.User) customfield_dtos.ValueAccess {
+	return customfield_dtos.ValueAccess{Abilities: a.Abilities, Owner: user != nil && user.ID == a.ViewerID}
+}
+
+func (a FieldAccess) CanWrite(field string) bool {
+	return a.hasAbility(constants.USER_FIELD_WRITE_ABILITIES[field])
+}
+
+// ForbiddenFields lists privileged fields set in payload which viewer cannot change
+func (a FieldAccess) ForbiddenFields(payload UpdateUserRequest) []string {
+	return a.forbidden([]payloadField{
+		{constants.USER_FIELD_SALARY, payload.Salary != nil},
+		{constants.USER_FIELD_STATUS, payload.Status != nil},
+		{constants.USER_FIELD_ROLE, payload.RoleID != nil},
+		{constants.USER_FIELD_DEPARTMENT, payload.DepartmentID != nil},
+	})
+}
+
+// ForbiddenProfileFields lists identity fields set in payload which viewer cannot change
+func (a FieldAccess) ForbiddenProfileFields(payload UpdateProfileRequest) []string {
+	return a.forbidden([]payloadField{
+		{constants.USER_FIELD_DATE_OF_BIRTH, payload.DateOfBirth != nil},
+		{constants.USER_FIELD_NATIONAL_ID, payload.NationalID != nil},
+	})
+}
+
+type payloadField struct {
+	name string
+	set  bool
+}
+
+func (a FieldAccess) forbidden(fields []payloadField) []string {
+	forbidden := []string{}
+	for _, field := range fields {
+		if field.set && !a.CanWrite(field.name) {
+			forbidden = append(forbidden, field.name)
+		}
+	}
+	return forbidden
+}
+
+func (a FieldAccess) hasAbility(requiredAbility string) bool {
+	if requiredAbility == "" {
+		return true
+	}
+	for _, ability := range a.Abilities {
+		if ability == requiredAbility || ability == auth_constants.ABILITY_ADMIN {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadDocuments is true for own documents and for either ability of category
+func (a FieldAccess) CanReadDocuments(category *models.DocumentCategory, ownerID uint) bool {
+	return ownerID == a.ViewerID || a.hasAbility(category.ReadAbility) || a.hasAbility(category.WriteAbility)
+}
+
+// CanWriteDocuments needs write ability of category, employees manage their own documents in self service categories
+func (a FieldAccess) CanWriteDocuments(category *models.DocumentCategory, ownerID uint) bool {
+	return (ownerID == a.ViewerID && category.SelfService) || a.hasAbility(category.WriteAbility)
+}
@@ -0,0 +1,76 @@
+package dtos
+
+import (
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"time"
+)
+
+type InvitationListResponse struct {
+	Items      []*InvitationResponse
+	Pagination utils.PaginationResult
+}
+
+type InvitationResponse struct {
+	Id             uint
+	Email          string
+	RoleName       *string
+	DepartmentName *string
+	StartDate      time.Time
+	ExpiresAt      time.Time
+	Status         string
+	InvitedByID    uint
+	AcceptedAt     *time.Time
+	UserID         *uint
+}
+
+type CreateInvitationRequest struct {
+	Email        string     `json:"email"`
+	RoleID       *uint      `json:"roleId,omitempty"`
+	DepartmentID *uint      `json:"departmentId,omitempty"`
+	StartDate    *time.Time `json:"startDate,omitempty"`
+	// CustomFields are checked now and saved once the invitation is accepted
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+}
+
+type AcceptInvitationRequest struct {
+	Token       string     `json:"token"`
+	Name        string     `json:"name"`
+	DateOfBirth *time.Time `json:"dateOfBirth,omitempty"`
+	Password    string     `json:"password"`
+	// CustomFields add to and override fields HR set on the invitation
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+}
+
+func NewInvitationListResponse(invitations []models.Invitation, totalRows int64, pagination utils.Pagination) *InvitationListResponse {
+	items := []*InvitationResponse{}
+	for _, invitation := range invitations {
+		items = append(items, NewInvitationResponse(&invitation))
+	}
+
+	return &InvitationListResponse{
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
+	}
+}
+
+func NewInvitationResponse(invitation *models.Invitation) *InvitationResponse {
+	res := &InvitationResponse{
+		Id:          invitation.ID,
+		Email:       invitation.Email,
+		StartDate:   invitation.StartDate,
+		ExpiresAt:   invitation.ExpiresAt,
+		Status:      invitation.CurrentStatus(),
+		InvitedByID: invitation.InvitedByID,
+		AcceptedAt:  invitation.AcceptedAt,
+		UserID:      invitation.UserID,
+	}
+	if invitation.Role != nil {
+		res.RoleName = &invitation.Role.Name
+	}
+	if invitation.Department != nil {
+		res.DepartmentName = &invitation.Department.Name
+	}
+
+	return res
+}
@@ -0,0 +1,71 @@
+package dtos
+
+import (
+	"hr-system-go/internal/user/models"
+	"time"
+)
+
+type LifecycleResponse struct {
+	UserId           uint
+	State            string
+	JoinDate         time.Time
+	ProbationEndDate *time.Time
+	// Termination is pending notice or termination in effect, nil for employed users
+	Termination *TerminationResponse
+}
+
+type TerminationResponse struct {
+	Id               uint
+	NoticeDate       time.Time
+	TerminationDate  time.Time
+	NoticePeriodDays int
+	Reason           string
+	Note             string
+	RecordedById     uint
+	AppliedAt        *time.Time
+}
+
+type ChangeLifecycleStateRequest struct {
+	State            string     `json:"state"`
+	ProbationEndDate *time.Time `json:"probationEndDate,omitempty"`
+}
+
+// TerminateUserRequest noticeDate defaults to today, terminationDate is the last working day
+type TerminateUserRequest struct {
+	NoticeDate       *time.Time `json:"noticeDate,omitempty"`
+	TerminationDate  *time.Time `json:"terminationDate,omitempty"`
+	NoticePeriodDays int        `json:"noticePeriodDays"`
+	Reason           string     `json:"reason"`
+	Note             string     `json:"note"`
+}
+
+type RehireUserRequest struct {
+	JoinDate         *time.Time `json:"joinDate,omitempty"`
+	ProbationEndDate *time.Time `json:"probationEndDate,omitempty"`
+}
+
+func NewLifecycleResponse(user *models.User, termination *models.Termination) *LifecycleResponse {
+	res := &LifecycleResponse{
+		UserId:           user.ID,
+		State:            user.LifecycleState,
+		JoinDate:         user.JoinDate,
+		ProbationEndDate: user.ProbationEndDate,
+	}
+	if termination != nil {
+		res.Termination = NewTerminationResponse(termination)
+	}
+	return res
+}
+
+func NewTerminationResponse(termination *models.Termination) *TerminationResponse {
+	return &TerminationResponse{
+		Id:               termination.ID,
+		NoticeDate:       termination.NoticeDate,
+		TerminationDate:  termination.TerminationDate,
+		NoticePeriodDays: termination.NoticePeriodDays,
+		Reason:           termination.Reason,
+		Note:             termination.Note,
+		RecordedById:     termination.RecordedByID,
+		AppliedAt:        termination.AppliedAt,
+	}
+}
@@ -0,0 +1,83 @@
+package dtos
+
+import (
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"time"
+)
+
+type ProfileResponse struct {
+	UserId            uint
+	DateOfBirth       *time.Time
+	Age               *int
+	NationalID        *string
+	BankAccount       *BankAccountResponse
+	Addresses         []models.Address
+	PhoneNumbers      []models.PhoneNumber
+	EmergencyContacts []models.EmergencyContact
+}
+
+type BankAccountResponse struct {
+	Holder *string
+	Number *string
+	Code   *string
+}
+
+// UpdateProfileRequest replaces lists which are set, an empty list clears them
+type UpdateProfileRequest struct {
+	DateOfBirth       *time.Time                 `json:"dateOfBirth,omitempty"`
+	NationalID        *string                    `json:"nationalId,omitempty"`
+	BankAccount       *BankAccountRequest        `json:"bankAccount,omitempty"`
+	Addresses         *[]models.Address          `json:"addresses,omitempty"`
+	PhoneNumbers      *[]models.PhoneNumber      `json:"phoneNumbers,omitempty"`
+	EmergencyContacts *[]models.EmergencyContact `json:"emergencyContacts,omitempty"`
+}
+
+// BankAccountRequest with empty number removes bank account
+type BankAccountRequest struct {
+	Holder string `json:"holder"`
+	Number string `json:"number"`
+	Code   string `json:"code"`
+}
+
+// NewProfileResponse leaves out fields viewer is not allowed to read, profile.User must be loaded
+func NewProfileResponse(profile *models.Profile, access FieldAccess) *ProfileResponse {
+	user := profile.User
+	res := &ProfileResponse{UserId: user.ID}
+
+	if access.CanRead(user, constants.USER_FIELD_DATE_OF_BIRTH) {
+		res.DateOfBirth = user.DateOfBirth
+	}
+	if access.CanRead(user, constants.USER_FIELD_AGE) {
+		res.Age = user.Age()
+	}
+	if access.CanRead(user, constants.USER_FIELD_NATIONAL_ID) {
+		res.NationalID = profile.NationalID
+	}
+	if access.CanRead(user, constants.USER_FIELD_BANK_ACCOUNT) && profile.BankAccountNumber != nil {
+		res.BankAccount = &BankAccountResponse{
+			Holder: profile.BankAccountHolder,
+			Number: profile.BankAccountNumber,
+			Code:   profile.BankCode,
+		}
+	}
+	if access.CanRead(user, constants.USER_FIELD_ADDRESSES) {
+		res.Addresses = emptyIfNil(profile.Addresses)
+	}
+	if access.CanRead(user, constants.USER_FIELD_PHONE_NUMBERS) {
+		res.PhoneNumbers = emptyIfNil(profile.PhoneNumbers)
+	}
+	if access.CanRead(user, constants.USER_FIELD_EMERGENCY_CONTACTS) {
+		res.EmergencyContacts = emptyIfNil(profile.EmergencyContacts)
+	}
+
+	return res
+}
+
+// emptyIfNil tells readable but empty list apart from hidden one
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
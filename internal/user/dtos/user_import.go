@@ -0,0 +1,165 @@
+package dtos
+
+import (
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportUsersRequest rows start with the header row, mapping ties import fields to headers.
+// Fields named like a header need no mapping
+type ImportUsersRequest struct {
+	Rows    [][]string
+	Mapping map[string]string
+	DryRun  bool
+}
+
+type UserImportResponse struct {
+	DryRun bool
+	// TotalRows counts non-empty rows, valid or not
+	TotalRows int
+	Imported  int
+	Items     []*UserImportItemResponse
+	Errors    []UserImportRowError
+}
+
+// UserImportRowError is problem with a cell, Row counts the header as row 1
+type UserImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// UserImportRow is user read from row of the file, saved only when the whole file is valid
+type UserImportRow struct {
+	Row          int
+	User         *models.User
+	CustomFields map[string]interface{}
+	// PasswordLinkSent tells the link to set first password was mailed after import
+	PasswordLinkSent bool
+}
+
+// UserImportItemResponse previews user created from row of the file
+type UserImportItemResponse struct {
+	Row            int
+	Id             uint
+	Name           string
+	Email          string
+	JoinDate       time.Time
+	DepartmentName *string
+	RoleName       *string
+	Salary         *float64
+	// PasswordLinkSent is false for dry run and when mail failed, user can still request a reset
+	PasswordLinkSent bool
+}
+
+// MappedFields lists import fields the file has a column for
+func (r ImportUsersRequest) MappedFields() []string {
+	if len(r.Rows) == 0 {
+		return []string{}
+	}
+	headers := []string{}
+	for _, header := range r.Rows[0] {
+		headers = append(headers, strings.ToLower(strings.TrimSpace(header)))
+	}
+
+	fields := []string{}
+	for _, field := range constants.USER_IMPORT_FIELDS {
+		header, mapped := r.Mapping[field]
+		if !mapped {
+			header = field
+		}
+		if slices.Contains(headers, strings.ToLower(strings.TrimSpace(header))) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// CustomFieldColumns finds columns of custom fields by key, their headers are custom.<key> and need no mapping
+func (r ImportUsersRequest) CustomFieldColumns() map[string]int {
+	columns := map[string]int{}
+	if len(r.Rows) == 0 {
+		return columns
+	}
+	for index, header := range r.Rows[0] {
+		key, ok := strings.CutPrefix(strings.TrimSpace(header), customfield_constants.CUSTOM_FIELD_FILTER_PREFIX)
+		if _, found := columns[key]; ok && key != "" && !found {
+			columns[key] = index
+		}
+	}
+	return columns
+}
+
+// NewUserImportResponse lists users in file order, errors keep dry run from being imported
+func NewUserImportResponse(rows []UserImportRow, dryRun bool, errors []UserImportRowError) *UserImportResponse {
+	if errors == nil {
+		errors = []UserImportRowError{}
+	}
+	invalidRows := map[int]bool{}
+	for _, rowError := range errors {
+		invalidRows[rowError.Row] = true
+	}
+	res := &UserImportResponse{DryRun: dryRun, TotalRows: len(rows) + len(invalidRows), Items: []*UserImportItemResponse{}, Errors: errors}
+	for _, row := range rows {
+		item := &UserImportItemResponse{
+			Row:              row.Row,
+			Id:               row.User.ID,
+			Name:             row.User.Name,
+			Email:            row.User.Email,
+			JoinDate:         row.User.JoinDate,
+			Salary:           row.User.Salary,
+			PasswordLinkSent: row.PasswordLinkSent,
+		}
+		if row.User.Department != nil {
+			item.DepartmentName = &row.User.Department.Name
+		}
+		if row.User.Role != nil {
+			item.RoleName = &row.User.Role.Name
+		}
+		res.Items = append(res.Items, item)
+	}
+	if !dryRun && len(errors) == 0 {
+		res.Imported = len(rows)
+	}
+	return res
+}
+
+// UserExportRows has header of import fields first, so exported file can be imported elsewhere.
+// Text users entered is escaped, it must not run as formula when the file is opened
+func UserExportRows(users []models.User, access FieldAccess) [][]string {
+	rows := [][]string{{
+		"id",
+		constants.USER_IMPORT_FIELD_NAME,
+		constants.USER_IMPORT_FIELD_EMAIL,
+		"lifecycleState",
+		constants.USER_IMPORT_FIELD_JOIN_DATE,
+		constants.USER_IMPORT_FIELD_DATE_OF_BIRTH,
+		constants.USER_IMPORT_FIELD_DEPARTMENT,
+		constants.USER_IMPORT_FIELD_ROLE,
+		constants.USER_IMPORT_FIELD_SALARY,
+	}}
+	for i := range users {
+		user := &users[i]
+		row := []string{strconv.FormatUint(uint64(user.ID), 10), utils.EscapeSpreadsheetFormula(user.Name), utils.EscapeSpreadsheetFormula(user.Email), user.LifecycleState, user.JoinDate.Format(time.DateOnly), "", "", "", ""}
+		if user.DateOfBirth != nil && access.CanRead(user, constants.USER_FIELD_DATE_OF_BIRTH) {
+			row[5] = user.DateOfBirth.Format(time.DateOnly)
+		}
+		if user.Department != nil {
+			row[6] = utils.EscapeSpreadsheetFormula(user.Department.Name)
+		}
+		if user.Role != nil {
+			row[7] = utils.EscapeSpreadsheetFormula(user.Role.Name)
+		}
+		if user.Salary != nil && access.CanRead(user, constants.USER_FIELD_SALARY) {
+			row[8] = strconv.FormatFloat(*user.Salary, 'f', -1, 64)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
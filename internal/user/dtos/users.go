@@ -1,6 +1,8 @@
 package dtos
 
 import (
+	customfield_dtos "hr-system-go/internal/customfield/dtos"
+	"hr-system-go/internal/user/constants"
 	"hr-system-go/internal/user/models"
 	"hr-system-go/utils"
 )
@@ -16,48 +18,69 @@ type UserResponse struct {
 	Email          *string
 	Age            *int
 	Status         *string
+	LifecycleState string
 	Salary         *float64
 	RoleName       *string
 	DepartmentName *string
+	// PasswordLoginDisabled users sign in through identity provider only
+	PasswordLoginDisabled bool
+	EmailVerified         bool
+	// PendingEmail waits for confirmation from the new address
+	PendingEmail *string
+	// CustomFields maps key of each custom field viewer can see to its value
+	CustomFields map[string]interface{}
 }
 
 type UpdateUserRequest struct {
 	Name         *string  `json:"name,omitempty"`
 	Email        *string  `json:"email,omitempty"`
-	Age          *int     `json:"age,omitempty"`
 	Status       *string  `json:"status,omitempty"`
 	Salary       *float64 `json:"salary,omitempty"`
 	RoleID       *int     `json:"roleId,omitempty"`
 	DepartmentID *int     `json:"departmentId,omitempty"`
+	// false lets user sign in with password again
+	PasswordLoginDisabled *bool `json:"passwordLoginDisabled,omitempty"`
+	// CustomFields are values by key, null clears value. Saved apart from the user columns
+	CustomFields map[string]interface{} `json:"customFields,omitempty" gorm:"-"`
 }
 
-func NewUserListResponse(users []models.User, totalRows int64, pagination utils.Pagination) *UserListResponse {
+func NewUserListResponse(users []models.User, totalRows int64, pagination utils.Pagination, access FieldAccess) *UserListResponse {
 	items := []*UserResponse{}
 	for _, user := range users {
-		items = append(items, NewUserResponse(&user))
+		items = append(items, NewUserResponse(&user, access))
 	}
 
 	return &UserListResponse{
-		Items: items,
-		Pagination: utils.PaginationResult{
-			Limit: pagination.Limit,
-			Page:  pagination.Page,
-			Total: totalRows,
-			Sort:  pagination.Sort,
-		},
+		Items:      items,
+		Pagination: pagination.Result(totalRows),
 	}
 }
 
-func NewUserResponse(user *models.User) *UserResponse {
+// NewUserResponse leaves out sensitive fields viewer is not allowed to read
+func NewUserResponse(user *models.User, access FieldAccess) *UserResponse {
 	res := &UserResponse{
-		Id:     user.ID,
-		Name:   &user.Name,
-		Email:  &user.Email,
-		Age:    &user.Age,
-		Status: &user.Status,
-		Salary: user.Salary,
+		Id:                    user.ID,
+		Name:                  &user.Name,
+		Email:                 &user.Email,
+		Age:                   user.Age(),
+		Status:                &user.Status,
+		LifecycleState:        user.LifecycleState,
+		Salary:                user.Salary,
+		PasswordLoginDisabled: user.PasswordLoginDisabled,
+		EmailVerified:         user.EmailVerified(),
+		PendingEmail:          user.PendingEmail,
+		CustomFields:          customfield_dtos.NewCustomFieldValuesResponse(user.CustomFieldValues, access.CustomFieldAccess(user)),
 	}
 
+	if !access.CanRead(user, constants.USER_FIELD_AGE) {
+		res.Age = nil
+	}
+	if !access.CanRead(user, constants.USER_FIELD_SALARY) {
+		res.Salary = nil
+	}
+	if !access.CanRead(user, constants.USER_FIELD_PENDING_EMAIL) {
+		res.PendingEmail = nil
+	}
 	if user.Role != nil {
 		res.RoleName = &user.Role.Name
 	}
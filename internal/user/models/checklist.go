@@ -0,0 +1,86 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	department_model "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChecklistTemplate lists tasks started for every employee who is hired or leaves,
+// templates without department apply to all departments
+type ChecklistTemplate struct {
+	base_model.BaseModel
+	Name         string `gorm:"not null"`
+	Kind         string `gorm:"not null;index"`
+	Status       string `gorm:"default:'active'"`
+	DepartmentID *uint
+	Department   *department_model.Department `gorm:"foreignKey:DepartmentID"`
+	Tasks        []ChecklistTemplateTask      `gorm:"foreignKey:TemplateID"`
+}
+
+// ChecklistTemplateTask is due DueOffsetDays after hire or termination date, negative offsets are due before it
+type ChecklistTemplateTask struct {
+	base_model.BaseModel
+	TemplateID    uint   `gorm:"not null;index"`
+	Position      int    `gorm:"not null;default:0"`
+	Title         string `gorm:"not null"`
+	Description   string `gorm:"type:text"`
+	Assignee      string `gorm:"not null"`
+	DueOffsetDays int    `gorm:"not null;default:0"`
+}
+
+// Checklist is template started for user, tasks are copied so later template changes do not affect it
+type Checklist struct {
+	base_model.BaseModel
+	UserID     uint   `gorm:"not null;index"`
+	TemplateID uint   `gorm:"not null"`
+	Kind       string `gorm:"not null"`
+	Name       string `gorm:"not null"`
+	// AnchorDate is hire or termination date the due dates are counted from
+	AnchorDate  time.Time       `gorm:"type:date;not null"`
+	CancelledAt *time.Time      `gorm:"type:timestamp;default:null"`
+	Tasks       []ChecklistTask `gorm:"foreignKey:ChecklistID"`
+}
+
+type ChecklistTask struct {
+	base_model.BaseModel
+	ChecklistID uint `gorm:"not null;index"`
+	// UserID is the employee being on- or offboarded
+	UserID      uint   `gorm:"not null;index"`
+	Position    int    `gorm:"not null;default:0"`
+	Title       string `gorm:"not null"`
+	Description string `gorm:"type:text"`
+	Assignee    string `gorm:"not null;index"`
+	// AssigneeID is set for manager tasks, nil when employee has no manager and HR takes over
+	AssigneeID    *uint      `gorm:"index"`
+	DueDate       time.Time  `gorm:"type:date;not null"`
+	CompletedAt   *time.Time `gorm:"type:timestamp;default:null"`
+	CompletedByID *uint
+	Checklist     *Checklist `gorm:"foreignKey:ChecklistID"`
+	User          *User      `gorm:"foreignKey:UserID"`
+}
+
+func ValidChecklistTemplateScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&ChecklistTemplate{}).Where("status != ?", "removed")
+}
+
+// OpenChecklistTaskScope leaves out completed tasks and tasks of cancelled checklists
+func OpenChecklistTaskScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&ChecklistTask{}).
+		Where("checklist_task.completed_at IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM checklist WHERE checklist.id = checklist_task.checklist_id AND checklist.cancelled_at IS NOT NULL)")
+}
+
+// CurrentStatus reports open tasks past their due date as overdue
+func (t *ChecklistTask) CurrentStatus() string {
+	if t.CompletedAt != nil {
+		return constants.CHECKLIST_TASK_STATUS_DONE
+	}
+	if t.DueDate.Before(EmploymentDate(time.Now())) {
+		return constants.CHECKLIST_TASK_STATUS_OVERDUE
+	}
+	return constants.CHECKLIST_TASK_STATUS_OPEN
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contract is employment agreement of user from StartDate, EndDate is nil for permanent ones.
+// ProbationEndDate of User mirrors the contract in effect, reminder times keep the daily job from mailing twice
+type Contract struct {
+	base_model.BaseModel
+	UserID           uint       `gorm:"not null;index"`
+	Type             string     `gorm:"not null"`
+	StartDate        time.Time  `gorm:"type:date;not null"`
+	EndDate          *time.Time `gorm:"type:date;default:null;index"`
+	ProbationEndDate *time.Time `gorm:"type:date;default:null;index"`
+	Note             string     `gorm:"type:text"`
+	// ProbationDecision is confirm or end once probation is decided, extensions move ProbationEndDate
+	ProbationDecision       *string
+	ProbationDecidedAt      *time.Time `gorm:"type:timestamp;default:null"`
+	ProbationDecidedByID    *uint
+	ProbationDecisionNote   string     `gorm:"type:text"`
+	ProbationExtensions     int        `gorm:"not null;default:0"`
+	ProbationReminderSentAt *time.Time `gorm:"type:timestamp;default:null"`
+	EndReminderSentAt       *time.Time `gorm:"type:timestamp;default:null"`
+	// Relations
+	User *User `gorm:"foreignKey:UserID"`
+}
+
+// CurrentContractScope orders contracts of user started at date or earlier, latest first
+func CurrentContractScope(db *gorm.DB, userID uint, date time.Time) *gorm.DB {
+	return db.Model(&Contract{}).
+		Where("user_id = ? AND start_date <= ?", userID, date).
+		Order("start_date DESC")
+}
+
+// ProbationOpen is true while probation was neither confirmed nor ended
+func (c *Contract) ProbationOpen() bool {
+	return c.ProbationEndDate != nil && c.ProbationDecision == nil
+}
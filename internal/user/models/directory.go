@@ -0,0 +1,25 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+)
+
+// DirectoryEntry is what every employee may see of a colleague besides name, department and position,
+// one row per user created on first update
+type DirectoryEntry struct {
+	base_model.BaseModel
+	UserID         uint   `gorm:"not null;uniqueIndex"`
+	Office         string `gorm:"size:100"`
+	PhoneExtension string `gorm:"size:16"`
+	// Skills are searchable along with name
+	Skills []string `gorm:"serializer:json;type:text"`
+	// PhotoKey is storage key of photo sizes without size suffix, nil until photo is uploaded
+	PhotoKey *string
+	// Relations
+	User *User `gorm:"foreignKey:UserID"`
+}
+
+// PhotoSizeKey is storage key of one photo size
+func (e *DirectoryEntry) PhotoSizeKey(size string) string {
+	return *e.PhotoKey + "-" + size + ".jpg"
+}
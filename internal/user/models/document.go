@@ -0,0 +1,41 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+)
+
+// DocumentCategory groups employee files like contracts, IDs and certificates. Employees always read their own
+// documents, documents of others need ReadAbility or WriteAbility and uploading needs WriteAbility
+type DocumentCategory struct {
+	base_model.BaseModel
+	Name         string `gorm:"not null;size:100;uniqueIndex"`
+	Description  string `gorm:"type:text"`
+	ReadAbility  string `gorm:"not null"`
+	WriteAbility string `gorm:"not null"`
+	// SelfService lets employees upload and delete their own documents, like certificates
+	SelfService bool `gorm:"not null;default:false"`
+	// ExpiryRequired asks for expiry date on upload, like visas and work permits
+	ExpiryRequired bool `gorm:"not null;default:false"`
+}
+
+// Document is metadata of file kept in storage under StorageKey, content never changes once uploaded
+type Document struct {
+	base_model.BaseModel
+	UserID      uint   `gorm:"not null;index"`
+	CategoryID  uint   `gorm:"not null;index"`
+	FileName    string `gorm:"not null;size:255"`
+	ContentType string `gorm:"not null"`
+	Size        int64  `gorm:"not null"`
+	// Checksum is hex SHA-256 of content
+	Checksum   string `gorm:"not null;size:64"`
+	StorageKey string `gorm:"not null;size:255"`
+	Note       string `gorm:"type:text"`
+	// ExpiresOn is last valid day, reminder time keeps the daily job from mailing twice
+	ExpiresOn            *time.Time `gorm:"type:date;default:null;index"`
+	ExpiryReminderSentAt *time.Time `gorm:"type:timestamp;default:null"`
+	UploadedByID         *uint
+	// Relations
+	User     *User             `gorm:"foreignKey:UserID"`
+	Category *DocumentCategory `gorm:"foreignKey:CategoryID"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmailVerification proves user owns Email, only sha256 of the mailed token is stored
+type EmailVerification struct {
+	base_model.BaseModel
+	UserID    uint       `gorm:"not null;index"`
+	Email     string     `gorm:"not null"`
+	Purpose   string     `gorm:"not null"`
+	TokenHash string     `gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp;default:null"`
+}
+
+func UsableEmailVerificationScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&EmailVerification{}).
+		Where("used_at IS NULL").
+		Where("expires_at > ?", time.Now())
+}
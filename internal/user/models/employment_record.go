@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	base_model "hr-system-go/internal/base/models"
+	department_model "hr-system-go/internal/department/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmploymentRecord is job position of user from EffectiveFrom until the next record starts.
+// Department and salary of User mirror the record in effect, AppliedAt is set once they do
+type EmploymentRecord struct {
+	base_model.BaseModel
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_user_effective_from"`
+	EffectiveFrom  time.Time `gorm:"type:date;not null;uniqueIndex:idx_user_effective_from"`
+	JobTitle       string
+	Grade          string
+	EmploymentType string  `gorm:"not null;default:'full_time'"`
+	FTE            float64 `gorm:"not null;default:1"`
+	Salary         *float64
+	// Reason tells why position changed, like promotion or transfer
+	Reason    string
+	AppliedAt *time.Time `gorm:"type:timestamp;default:null;index"`
+	// Relations
+	DepartmentID *uint
+	Department   *department_model.Department `gorm:"foreignKey:DepartmentID"`
+	ManagerID    *uint
+	Manager      *User `gorm:"foreignKey:ManagerID"`
+}
+
+// EffectiveEmploymentScope orders records of user in effect at date or earlier, latest first
+func EffectiveEmploymentScope(db *gorm.DB, userID uint, date time.Time) *gorm.DB {
+	return db.Model(&EmploymentRecord{}).
+		Where("user_id = ? AND effective_from <= ?", userID, date).
+		Order("effective_from DESC")
+}
+
+// EmploymentDate drops time of day, records take effect for whole days
+func EmploymentDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ManagedUserIDsQuery selects users whose employment record in effect has one of managerIDs as manager
+func ManagedUserIDsQuery(db *gorm.DB, managerIDs []interface{}) *gorm.DB {
+	return db.Model(&EmploymentRecord{}).
+		Select("user_id").
+		Where("manager_id IN ? AND (user_id, effective_from) IN (?)", managerIDs, latestAppliedEmploymentQuery(db))
+}
+
+// AppliedEmploymentScope selects employment records in effect of userIDs, one per user
+func AppliedEmploymentScope(db *gorm.DB, userIDs []uint) *gorm.DB {
+	return db.Model(&EmploymentRecord{}).
+		Where("user_id IN ? AND (user_id, effective_from) IN (?)", userIDs, latestAppliedEmploymentQuery(db.Session(&gorm.Session{NewDB: true})))
+}
+
+// AppliedManagerID is manager in employment record in effect of user, nil when there is none
+func AppliedManagerID(db *gorm.DB, userID uint) (*uint, error) {
+	var record *EmploymentRecord
+	err := AppliedEmploymentScope(db, []uint{userID}).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record.ManagerID, nil
+}
+
+func latestAppliedEmploymentQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&EmploymentRecord{}).
+		Select("user_id, MAX(effective_from)").
+		Where("applied_at IS NOT NULL").
+		Group("user_id")
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	auth_model "hr-system-go/internal/auth/models"
+	base_model "hr-system-go/internal/base/models"
+	department_model "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Invitation lets HR onboard an employee, only sha256 of the mailed token is stored
+type Invitation struct {
+	base_model.BaseModel
+	Email       string     `gorm:"not null;index"`
+	TokenHash   string     `gorm:"not null;uniqueIndex;size:64"`
+	StartDate   time.Time  `gorm:"type:timestamp;not null"`
+	ExpiresAt   time.Time  `gorm:"type:timestamp;not null"`
+	Status      string     `gorm:"not null;index;default:'pending'"`
+	InvitedByID uint       `gorm:"not null"`
+	AcceptedAt  *time.Time `gorm:"type:timestamp;default:null"`
+	// UserID is the account created when invitation is accepted
+	UserID *uint
+	// CustomFields set by HR are saved on the user when invitation is accepted
+	CustomFields map[string]interface{} `gorm:"serializer:json;type:text"`
+	// Relations
+	RoleID       *uint
+	Role         *auth_model.Role `gorm:"foreignKey:RoleID"`
+	DepartmentID *uint
+	Department   *department_model.Department `gorm:"foreignKey:DepartmentID"`
+}
+
+func PendingInvitationScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&Invitation{}).
+		Where("status = ?", constants.INVITATION_STATUS_PENDING).
+		Where("expires_at > ?", time.Now())
+}
+
+func (i *Invitation) Expired() bool {
+	return !i.ExpiresAt.After(time.Now())
+}
+
+// CurrentStatus reports pending invitations past their expiry as expired
+func (i *Invitation) CurrentStatus() string {
+	if i.Status == constants.INVITATION_STATUS_PENDING && i.Expired() {
+		return constants.INVITATION_STATUS_EXPIRED
+	}
+	return i.Status
+}
@@ -0,0 +1,11 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+)
+
+type PasswordHistory struct {
+	base_model.BaseModel
+	UserID          uint   `gorm:"index;not null"`
+	PasswordEncrypt string `gorm:"not null"`
+}
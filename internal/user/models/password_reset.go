@@ -0,0 +1,23 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasswordReset lets user set a new password once, only sha256 of the mailed token is stored
+type PasswordReset struct {
+	base_model.BaseModel
+	UserID    uint       `gorm:"not null;index"`
+	TokenHash string     `gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp;default:null"`
+}
+
+func UsablePasswordResetScope(db *gorm.DB) *gorm.DB {
+	return db.Model(&PasswordReset{}).
+		Where("used_at IS NULL").
+		Where("expires_at > ?", time.Now())
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+)
+
+// Profile holds personal and payroll data of employee, one row per user created on first update.
+// Date of birth is kept on User with the other identity fields of the account
+type Profile struct {
+	base_model.BaseModel
+	UserID     uint    `gorm:"not null;uniqueIndex"`
+	NationalID *string `gorm:"size:64"`
+	// bank account salary is paid to
+	BankAccountHolder *string
+	BankAccountNumber *string            `gorm:"size:64"`
+	BankCode          *string            `gorm:"size:32"`
+	Addresses         []Address          `gorm:"serializer:json;type:text"`
+	PhoneNumbers      []PhoneNumber      `gorm:"serializer:json;type:text"`
+	EmergencyContacts []EmergencyContact `gorm:"serializer:json;type:text"`
+	// Relations
+	User *User `gorm:"foreignKey:UserID"`
+}
+
+type Address struct {
+	Type       string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+type PhoneNumber struct {
+	Type   string
+	Number string
+}
+
+// EmergencyContact list is ordered, first one is called first
+type EmergencyContact struct {
+	Name         string
+	Relationship string
+	Phone        string
+	Email        string
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	base_model "hr-system-go/internal/base/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Termination ends employment of user after TerminationDate, their last working day.
+// Withdrawn notices are kept, AppliedAt is set once user is terminated
+type Termination struct {
+	base_model.BaseModel
+	UserID           uint      `gorm:"not null;index"`
+	NoticeDate       time.Time `gorm:"type:date;not null"`
+	TerminationDate  time.Time `gorm:"type:date;not null;index"`
+	NoticePeriodDays int       `gorm:"not null;default:0"`
+	Reason           string    `gorm:"not null"`
+	Note             string    `gorm:"type:text"`
+	RecordedByID     uint      `gorm:"not null"`
+	// PreviousState is restored when notice is withdrawn
+	PreviousState string     `gorm:"not null"`
+	WithdrawnAt   *time.Time `gorm:"type:timestamp;default:null"`
+	AppliedAt     *time.Time `gorm:"type:timestamp;default:null"`
+}
+
+// CurrentTerminationScope is termination of user which was not withdrawn, latest first
+func CurrentTerminationScope(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&Termination{}).
+		Where("user_id = ? AND withdrawn_at IS NULL", userID).
+		Order("termination_date DESC")
+}
@@ -3,8 +3,10 @@ package models
 import (
 	auth_model "hr-system-go/internal/auth/models"
 	base_model "hr-system-go/internal/base/models"
+	customfield_model "hr-system-go/internal/customfield/models"
 
 	department_model "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -16,16 +18,31 @@ type User struct {
 	base_model.BaseModel
 	Name            string    `gorm:"not null"`
 	Email           string    `gorm:"index:idx_email_status,unique"`
-	Age             int       `gorm:"not null,check:age > 0"`
 	Status          string    `gorm:"index:idx_email_status;default:'active'"`
 	PasswordEncrypt string    `gorm:"not null"`
 	JoinDate        time.Time `gorm:"type:timestamp;default:current_timestamp()"`
 	Salary          *float64
+	DateOfBirth     *time.Time `gorm:"type:date;default:null"`
+	// PasswordChangedAt is nil for passwords set before password policy, those never expire until changed
+	PasswordChangedAt *time.Time `gorm:"type:timestamp;default:null"`
+	// Type tells human employees from service accounts used by integrations
+	Type string `gorm:"not null;default:'human'"`
+	// PasswordLoginDisabled users sign in through external identity provider only
+	PasswordLoginDisabled bool `gorm:"not null;default:false"`
+	// EmailVerifiedAt is set once user has proven the mailbox is theirs
+	EmailVerifiedAt *time.Time `gorm:"type:timestamp;default:null"`
+	// PendingEmail replaces Email once the change is confirmed from the new address
+	PendingEmail *string
+	// LifecycleState is stage of employment, Status only tells removed records apart
+	LifecycleState   string     `gorm:"not null;index;default:'active'"`
+	ProbationEndDate *time.Time `gorm:"type:date;default:null"`
 	// Relations
 	RoleID       *uint
 	Role         *auth_model.Role `gorm:"foreignKey:RoleID"`
 	DepartmentID *uint
 	Department   *department_model.Department `gorm:"foreignKey:DepartmentID"`
+	// CustomFieldValues are values of fields admins added to users
+	CustomFieldValues []customfield_model.CustomFieldValue `gorm:"polymorphic:Entity;polymorphicValue:user"`
 }
 
 func ValidScope(db *gorm.DB) *gorm.DB {
@@ -48,3 +65,42 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	}
 	return tx.Model(&department_model.Department{}).Where("id = ?", *u.DepartmentID).Update("employ_count", gorm.Expr("employ_count + ?", 1)).Error
 }
+
+// service accounts authenticate with API keys only
+func (u *User) IsServiceAccount() bool {
+	return u.Type == constants.USER_TYPE_SERVICE_ACCOUNT
+}
+
+// password sign in and reset are refused for service accounts and SSO only users
+func (u *User) CanUsePassword() bool {
+	return !u.IsServiceAccount() && !u.PasswordLoginDisabled
+}
+
+// terminated users keep their records but cannot sign in
+func (u *User) Terminated() bool {
+	return u.LifecycleState == constants.LIFECYCLE_STATE_TERMINATED
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Age is nil until date of birth is known
+func (u *User) Age() *int {
+	if u.DateOfBirth == nil {
+		return nil
+	}
+	now, birth := time.Now(), *u.DateOfBirth
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	return &age
+}
+
+func (u *User) PasswordExpired(maxAge time.Duration) bool {
+	if maxAge <= 0 || u.PasswordChangedAt == nil {
+		return false
+	}
+	return time.Since(*u.PasswordChangedAt) > maxAge
+}
@@ -16,12 +16,42 @@ type UserModule struct {
 func (m *UserModule) Controllers() []interface{} {
 	return []interface{}{
 		controllers.NewUsersController,
+		controllers.NewInvitationsController,
+		controllers.NewEmailController,
+		controllers.NewProfileController,
+		controllers.NewEmploymentController,
+		controllers.NewLifecycleController,
+		controllers.NewChecklistsController,
+		controllers.NewContractsController,
+		controllers.NewUserImportController,
+		controllers.NewDirectoryController,
+		controllers.NewDocumentsController,
 		func(
 			r *gin.Engine,
 			c *controllers.UsersController,
+			invitationsController *controllers.InvitationsController,
+			emailController *controllers.EmailController,
+			profileController *controllers.ProfileController,
+			employmentController *controllers.EmploymentController,
+			lifecycleController *controllers.LifecycleController,
+			checklistsController *controllers.ChecklistsController,
+			contractsController *controllers.ContractsController,
+			userImportController *controllers.UserImportController,
+			directoryController *controllers.DirectoryController,
+			documentsController *controllers.DocumentsController,
 			logger *logger.Logger,
 		) *UserModule {
 			c.RegisterRoutes(r)
+			invitationsController.RegisterRoutes(r)
+			emailController.RegisterRoutes(r)
+			profileController.RegisterRoutes(r)
+			employmentController.RegisterRoutes(r)
+			lifecycleController.RegisterRoutes(r)
+			checklistsController.RegisterRoutes(r)
+			contractsController.RegisterRoutes(r)
+			userImportController.RegisterRoutes(r)
+			directoryController.RegisterRoutes(r)
+			documentsController.RegisterRoutes(r)
 			logger.Info("= User module init")
 			return m
 		},
@@ -31,5 +61,16 @@ func (m *UserModule) Controllers() []interface{} {
 func (m *UserModule) Provide() []interface{} {
 	return []interface{}{
 		services.NewUserService,
+		services.NewInvitationService,
+		services.NewEmailVerificationService,
+		services.NewPasswordResetService,
+		services.NewProfileService,
+		services.NewEmploymentService,
+		services.NewLifecycleService,
+		services.NewChecklistService,
+		services.NewContractService,
+		services.NewUserImportService,
+		services.NewDirectoryService,
+		services.NewDocumentService,
 	}
 }
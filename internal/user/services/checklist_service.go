@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrChecklistTemplateName = errors.New("name is required")
+	ErrChecklistKind         = errors.New("kind must be onboarding or offboarding")
+	ErrChecklistTask         = errors.New("every task needs a title and assignee hr, it or manager")
+	ErrChecklistAssignee     = errors.New("assignee must be hr, it or manager")
+	ErrChecklistTaskStatus   = errors.New("status must be open, done or overdue")
+	ErrChecklistCancelled    = errors.New("checklist was cancelled")
+)
+
+type ChecklistServiceInterface interface {
+	FindTemplates(kind string) ([]models.ChecklistTemplate, error)
+	CreateTemplate(ctx context.Context, payload dtos.ChecklistTemplateRequest) (*models.ChecklistTemplate, error)
+	UpdateTemplate(ctx context.Context, templateID int, payload dtos.ChecklistTemplateRequest) (*models.ChecklistTemplate, error)
+	DeleteTemplate(ctx context.Context, templateID int) error
+	FindChecklists(userID int) ([]models.Checklist, error)
+	FindTasks(filter dtos.ChecklistTaskFilter, pagination *utils.Pagination) ([]models.ChecklistTask, int64, error)
+	FindTask(taskID int) (*models.ChecklistTask, error)
+	CompleteTask(ctx context.Context, taskID int, completedByID uint) (*models.ChecklistTask, error)
+	ReopenTask(ctx context.Context, taskID int) (*models.ChecklistTask, error)
+}
+
+type ChecklistService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+}
+
+func NewChecklistService(logger *logger.Logger, db *mysql.MySqlStore) ChecklistServiceInterface {
+	return &ChecklistService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (s *ChecklistService) FindTemplates(kind string) ([]models.ChecklistTemplate, error) {
+	query := models.ValidChecklistTemplateScope(s.db.DB())
+	if kind != "" {
+		if !slices.Contains(constants.CHECKLIST_KINDS, kind) {
+			return nil, ErrChecklistKind
+		}
+		query = query.Where("kind = ?", kind)
+	}
+
+	var templates []models.ChecklistTemplate
+	err := query.Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Order("id ASC").Find(&templates).Error
+	if err != nil {
+		s.logger.Error("Cannot Find Checklist Templates", zap.Error(err))
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (s *ChecklistService) CreateTemplate(ctx context.Context, payload dtos.ChecklistTemplateRequest) (*models.ChecklistTemplate, error) {
+	template, err := s.parseTemplate(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.DB().WithContext(ctx).Create(&template).Error; err != nil {
+		s.logger.Error("Cannot Create Checklist Template", zap.Error(err))
+		return nil, err
+	}
+	return s.findTemplate(template.ID)
+}
+
+// UpdateTemplate affects checklists started afterwards only
+func (s *ChecklistService) UpdateTemplate(ctx context.Context, templateID int, payload dtos.ChecklistTemplateRequest) (*models.ChecklistTemplate, error) {
+	template, err := s.parseTemplate(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current *models.ChecklistTemplate
+		if err := models.ValidChecklistTemplateScope(tx).First(&current, templateID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&current).Select("name", "kind", "department_id").Updates(map[string]interface{}{
+			"name":          template.Name,
+			"kind":          template.Kind,
+			"department_id": template.DepartmentID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("template_id = ?", current.ID).Delete(&models.ChecklistTemplateTask{}).Error; err != nil {
+			return err
+		}
+		for i := range template.Tasks {
+			template.Tasks[i].TemplateID = current.ID
+		}
+		if len(template.Tasks) == 0 {
+			return nil
+		}
+		return tx.Create(&template.Tasks).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Update Checklist Template", zap.Error(err))
+		return nil, err
+	}
+	return s.findTemplate(uint(templateID))
+}
+
+func (s *ChecklistService) DeleteTemplate(ctx context.Context, templateID int) error {
+	var template *models.ChecklistTemplate
+	if err := models.ValidChecklistTemplateScope(s.db.DB().WithContext(ctx)).First(&template, templateID).Update("status", "removed").Error; err != nil {
+		s.logger.Error("Cannot Delete Checklist Template", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// FindChecklists returns checklists of user with their tasks, latest first
+func (s *ChecklistService) FindChecklists(userID int) ([]models.Checklist, error) {
+	var checklists []models.Checklist
+	err := s.db.DB().Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("due_date ASC, position ASC")
+	}).Where("user_id = ?", userID).Order("anchor_date DESC, id DESC").Find(&checklists).Error
+	if err != nil {
+		s.logger.Error("Cannot Find Checklists", zap.Error(err))
+		return nil, err
+	}
+	return checklists, nil
+}
+
+var checklistTaskSortFields = utils.SortFields{
+	"dueDate":  "due_date",
+	"title":    "title",
+	"assignee": "assignee",
+	"position": "position",
+}
+
+func (s *ChecklistService) FindTasks(filter dtos.ChecklistTaskFilter, pagination *utils.Pagination) ([]models.ChecklistTask, int64, error) {
+	scope, err := s.taskScope(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var tasks []models.ChecklistTask
+	var totalCount int64 = 0
+
+	if err := scope(s.db.DB()).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err = pagination.FindPage(scope(s.db.DB()).Preload("User"), checklistTaskSortFields, &tasks)
+	if err != nil {
+		s.logger.Error("Cannot Find Checklist Tasks", zap.Error(err))
+		return nil, 0, err
+	}
+	return tasks, totalCount, nil
+}
+
+func (s *ChecklistService) FindTask(taskID int) (*models.ChecklistTask, error) {
+	var task *models.ChecklistTask
+	if err := s.db.DB().Preload("User").Preload("Checklist").First(&task, taskID).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *ChecklistService) CompleteTask(ctx context.Context, taskID int, completedByID uint) (*models.ChecklistTask, error) {
+	now := time.Now()
+	return s.setTaskCompletion(ctx, taskID, map[string]interface{}{"completed_at": now, "completed_by_id": completedByID})
+}
+
+func (s *ChecklistService) ReopenTask(ctx context.Context, taskID int) (*models.ChecklistTask, error) {
+	return s.setTaskCompletion(ctx, taskID, map[string]interface{}{"completed_at": nil, "completed_by_id": nil})
+}
+
+func (s *ChecklistService) setTaskCompletion(ctx context.Context, taskID int, updates map[string]interface{}) (*models.ChecklistTask, error) {
+	task, err := s.FindTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Checklist != nil && task.Checklist.CancelledAt != nil {
+		return nil, ErrChecklistCancelled
+	}
+
+	if err := s.db.DB().WithContext(ctx).Model(&models.ChecklistTask{}).Where("id = ?", task.ID).Updates(updates).Error; err != nil {
+		s.logger.Error("Cannot Update Checklist Task", zap.Error(err))
+		return nil, err
+	}
+	return s.FindTask(taskID)
+}
+
+func (s *ChecklistService) findTemplate(templateID uint) (*models.ChecklistTemplate, error) {
+	var template *models.ChecklistTemplate
+	err := models.ValidChecklistTemplateScope(s.db.DB()).Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).First(&template, templateID).Error
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *ChecklistService) parseTemplate(payload dtos.ChecklistTemplateRequest) (*models.ChecklistTemplate, error) {
+	template := &models.ChecklistTemplate{
+		Name:         strings.TrimSpace(payload.Name),
+		Kind:         payload.Kind,
+		DepartmentID: payload.DepartmentID,
+		Tasks:        []models.ChecklistTemplateTask{},
+	}
+	if template.Name == "" {
+		return nil, ErrChecklistTemplateName
+	}
+	if !slices.Contains(constants.CHECKLIST_KINDS, template.Kind) {
+		return nil, ErrChecklistKind
+	}
+	if template.DepartmentID != nil {
+		if err := department_models.ValidScope(s.db.DB()).First(&department_models.Department{}, *template.DepartmentID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for i, task := range payload.Tasks {
+		title := strings.TrimSpace(task.Title)
+		if title == "" || !slices.Contains(constants.CHECKLIST_ASSIGNEES, task.Assignee) {
+			return nil, ErrChecklistTask
+		}
+		template.Tasks = append(template.Tasks, models.ChecklistTemplateTask{
+			Position:      i,
+			Title:         title,
+			Description:   strings.TrimSpace(task.Description),
+			Assignee:      task.Assignee,
+			DueOffsetDays: task.DueOffsetDays,
+		})
+	}
+	return template, nil
+}
+
+func (s *ChecklistService) taskScope(filter dtos.ChecklistTaskFilter) (func(db *gorm.DB) *gorm.DB, error) {
+	if filter.Assignee != "" && !slices.Contains(constants.CHECKLIST_ASSIGNEES, filter.Assignee) {
+		return nil, ErrChecklistAssignee
+	}
+	switch filter.Status {
+	case "", constants.CHECKLIST_TASK_STATUS_OPEN, constants.CHECKLIST_TASK_STATUS_OVERDUE, constants.CHECKLIST_TASK_STATUS_DONE:
+	default:
+		return nil, ErrChecklistTaskStatus
+	}
+	today := models.EmploymentDate(time.Now())
+
+	return func(db *gorm.DB) *gorm.DB {
+		query := db.Model(&models.ChecklistTask{})
+		switch filter.Status {
+		case "":
+			query = query.Where("NOT EXISTS (SELECT 1 FROM checklist WHERE checklist.id = checklist_task.checklist_id AND checklist.cancelled_at IS NOT NULL)")
+		case constants.CHECKLIST_TASK_STATUS_OPEN:
+			query = models.OpenChecklistTaskScope(db).Where("due_date >= ?", today)
+		case constants.CHECKLIST_TASK_STATUS_OVERDUE:
+			query = models.OpenChecklistTaskScope(db).Where("due_date < ?", today)
+		case constants.CHECKLIST_TASK_STATUS_DONE:
+			query = query.Where("completed_at IS NOT NULL")
+		}
+
+		if filter.UserID != 0 {
+			query = query.Where("user_id = ?", filter.UserID)
+		}
+		if filter.Assignee != "" {
+			query = query.Where("assignee = ?", filter.Assignee)
+		}
+		if filter.Access != nil && !filter.Access.CanCompleteAll() {
+			visible := db.Where("assignee_id = ?", filter.Access.ViewerID)
+			if teams := filter.Access.Teams(); len(teams) > 0 {
+				visible = visible.Or("assignee IN ?", teams)
+			}
+			query = query.Where(visible)
+		}
+		return query
+	}, nil
+}
+
+// startChecklists copies every active template of kind which applies to department of user,
+// due dates are counted from anchor date. Manager tasks go to manager in effect at anchor date
+func startChecklists(tx *gorm.DB, user *models.User, kind string, anchor time.Time) error {
+	anchor = models.EmploymentDate(anchor)
+	var templates []models.ChecklistTemplate
+	query := models.ValidChecklistTemplateScope(tx).Preload("Tasks").Where("kind = ?", kind)
+	if user.DepartmentID != nil {
+		query = query.Where("department_id IS NULL OR department_id = ?", *user.DepartmentID)
+	} else {
+		query = query.Where("department_id IS NULL")
+	}
+	if err := query.Find(&templates).Error; err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		return nil
+	}
+
+	var managerID *uint
+	var record *models.EmploymentRecord
+	err := models.EffectiveEmploymentScope(tx, user.ID, anchor).First(&record).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil {
+		managerID = record.ManagerID
+	}
+
+	for _, template := range templates {
+		checklist := &models.Checklist{
+			UserID:     user.ID,
+			TemplateID: template.ID,
+			Kind:       template.Kind,
+			Name:       template.Name,
+			AnchorDate: anchor,
+		}
+		for _, task := range template.Tasks {
+			item := models.ChecklistTask{
+				UserID:      user.ID,
+				Position:    task.Position,
+				Title:       task.Title,
+				Description: task.Description,
+				Assignee:    task.Assignee,
+				DueDate:     anchor.AddDate(0, 0, task.DueOffsetDays),
+			}
+			if task.Assignee == constants.CHECKLIST_ASSIGNEE_MANAGER {
+				item.AssigneeID = managerID
+			}
+			checklist.Tasks = append(checklist.Tasks, item)
+		}
+		if err := tx.Create(&checklist).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelChecklists stops checklists of kind which still have open tasks, finished ones are kept as they are
+func cancelChecklists(tx *gorm.DB, userID uint, kind string) error {
+	return tx.Model(&models.Checklist{}).
+		Where("user_id = ? AND kind = ? AND cancelled_at IS NULL", userID, kind).
+		Where("EXISTS (SELECT 1 FROM checklist_task WHERE checklist_task.checklist_id = checklist.id AND checklist_task.completed_at IS NULL)").
+		Update("cancelled_at", time.Now()).Error
+}
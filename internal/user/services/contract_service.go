@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
+	"hr-system-go/app/plugins/mysql"
+	auth_constants "hr-system-go/internal/auth/constants"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrContractType          = errors.New("type must be permanent or fixed_term")
+	ErrContractDates         = errors.New("startDate is required, endDate is required for fixed term contracts only and must not be before startDate")
+	ErrContractProbationDate = errors.New("probationEndDate must be within the contract")
+	ErrContractOverlap       = errors.New("contract overlaps another contract of user")
+	ErrProbationDecision     = errors.New("decision must be confirm, extend or end")
+	ErrNoOpenProbation       = errors.New("user has no open probation")
+	ErrProbationExtension    = errors.New("probationEndDate must be after current probation end and within the contract, probation can only be extended before it ends")
+)
+
+type ContractServiceInterface interface {
+	FindContracts(userID int) ([]models.Contract, error)
+	FindUpcomingContracts(until time.Time) ([]models.Contract, error)
+	CreateContract(ctx context.Context, userID int, payload dtos.CreateContractRequest) (*models.Contract, error)
+	DecideProbation(ctx context.Context, decidedByID uint, userID int, payload dtos.ProbationDecisionRequest) (*models.Contract, error)
+	SendDueReminders(ctx context.Context) (int, error)
+}
+
+type ContractService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	mailer *mailer.Mailer
+	// reminderDays is how long before probation or contract end manager and HR are notified
+	reminderDays int
+	// hrEmails receive every reminder, empty falls back to users whose role can write employment
+	hrEmails string
+	// reminderInterval is how often due reminders are sent, zero leaves it to contracts:remind command
+	reminderInterval time.Duration
+	stop             chan struct{}
+}
+
+func NewContractService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, mailer *mailer.Mailer, lc fx.Lifecycle) ContractServiceInterface {
+	service := &ContractService{
+		logger:           logger,
+		db:               db,
+		mailer:           mailer,
+		reminderDays:     env.GetEnvInt("CONTRACT_REMINDER_DAYS", constants.CONTRACT_DEFAULT_REMINDER_DAYS),
+		hrEmails:         env.GetEnv("CONTRACT_REMINDER_HR_EMAILS"),
+		reminderInterval: time.Duration(env.GetEnvInt("CONTRACT_REMINDER_INTERVAL_MINUTES", constants.CONTRACT_DEFAULT_REMINDER_INTERVAL_MINUTES)) * time.Minute,
+		stop:             make(chan struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if service.reminderInterval > 0 {
+				go service.remindPeriodically()
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(service.stop)
+			return nil
+		},
+	})
+	return service
+}
+
+func (s *ContractService) FindContracts(userID int) ([]models.Contract, error) {
+	var contracts []models.Contract
+	err := s.db.DB().Where("user_id = ?", userID).Order("start_date").Find(&contracts).Error
+	if err != nil {
+		s.logger.Error("Cannot Find Contracts", zap.Error(err))
+		return nil, err
+	}
+	return contracts, nil
+}
+
+// FindUpcomingContracts returns contracts with open probation or contract ending from today until the date
+func (s *ContractService) FindUpcomingContracts(until time.Time) ([]models.Contract, error) {
+	var contracts []models.Contract
+	err := upcomingContractScope(s.db.DB(), models.EmploymentDate(until)).Preload("User").Find(&contracts).Error
+	if err != nil {
+		s.logger.Error("Cannot Find Upcoming Contracts", zap.Error(err))
+		return nil, err
+	}
+	return contracts, nil
+}
+
+// CreateContract adds contract which must not overlap others, the latest contract sets probation end of user
+func (s *ContractService) CreateContract(ctx context.Context, userID int, payload dtos.CreateContractRequest) (*models.Contract, error) {
+	if !slices.Contains(constants.CONTRACT_TYPES, payload.Type) {
+		return nil, ErrContractType
+	}
+	if payload.StartDate == nil {
+		return nil, ErrContractDates
+	}
+	contract := &models.Contract{
+		Type:      payload.Type,
+		StartDate: models.EmploymentDate(*payload.StartDate),
+		Note:      strings.TrimSpace(payload.Note),
+	}
+	if (payload.EndDate != nil) != (payload.Type == constants.CONTRACT_TYPE_FIXED_TERM) {
+		return nil, ErrContractDates
+	}
+	if payload.EndDate != nil {
+		endDate := models.EmploymentDate(*payload.EndDate)
+		if endDate.Before(contract.StartDate) {
+			return nil, ErrContractDates
+		}
+		contract.EndDate = &endDate
+	}
+	if payload.ProbationEndDate != nil {
+		probationEndDate := models.EmploymentDate(*payload.ProbationEndDate)
+		if probationEndDate.Before(contract.StartDate) || (contract.EndDate != nil && probationEndDate.After(*contract.EndDate)) {
+			return nil, ErrContractProbationDate
+		}
+		contract.ProbationEndDate = &probationEndDate
+	}
+
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user *models.User
+		if err := models.ValidScope(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+		contract.UserID = user.ID
+
+		overlapping := tx.Model(&models.Contract{}).Where("user_id = ? AND (end_date IS NULL OR end_date >= ?)", user.ID, contract.StartDate)
+		if contract.EndDate != nil {
+			overlapping = overlapping.Where("start_date <= ?", *contract.EndDate)
+		}
+		var count int64
+		if err := overlapping.Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrContractOverlap
+		}
+
+		if err := tx.Create(&contract).Error; err != nil {
+			return err
+		}
+		return syncProbation(tx, user, contract)
+	})
+	if err != nil {
+		s.logger.Error("Cannot Create Contract", zap.Error(err))
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// DecideProbation confirms, extends or ends employment at the end of probation of current contract
+func (s *ContractService) DecideProbation(ctx context.Context, decidedByID uint, userID int, payload dtos.ProbationDecisionRequest) (*models.Contract, error) {
+	if !slices.Contains(constants.PROBATION_DECISIONS, payload.Decision) {
+		return nil, ErrProbationDecision
+	}
+	today := models.EmploymentDate(time.Now())
+
+	var contract *models.Contract
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user *models.User
+		if err := models.ValidScope(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+		err := models.CurrentContractScope(tx, user.ID, today).First(&contract).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoOpenProbation
+		}
+		if err != nil {
+			return err
+		}
+		if !contract.ProbationOpen() {
+			return ErrNoOpenProbation
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{}
+		switch payload.Decision {
+		case constants.PROBATION_DECISION_EXTEND:
+			if payload.ProbationEndDate == nil || contract.ProbationEndDate.Before(today) {
+				return ErrProbationExtension
+			}
+			probationEndDate := models.EmploymentDate(*payload.ProbationEndDate)
+			if !probationEndDate.After(*contract.ProbationEndDate) || (contract.EndDate != nil && probationEndDate.After(*contract.EndDate)) {
+				return ErrProbationExtension
+			}
+			updates["probation_end_date"] = probationEndDate
+			updates["probation_extensions"] = gorm.Expr("probation_extensions + ?", 1)
+			// reminder is sent again before the new end
+			updates["probation_reminder_sent_at"] = nil
+		case constants.PROBATION_DECISION_CONFIRM:
+			err := models.ValidScope(tx).
+				Where("id = ? AND lifecycle_state = ?", user.ID, constants.LIFECYCLE_STATE_PROBATION).
+				Update("lifecycle_state", constants.LIFECYCLE_STATE_ACTIVE).Error
+			if err != nil {
+				return err
+			}
+		case constants.PROBATION_DECISION_END:
+			terminationDate := *contract.ProbationEndDate
+			if payload.TerminationDate != nil {
+				terminationDate = *payload.TerminationDate
+			}
+			_, err := terminateUser(tx, decidedByID, userID, dtos.TerminateUserRequest{
+				TerminationDate: &terminationDate,
+				Reason:          constants.TERMINATION_REASON_FAILED_PROBATION,
+				Note:            payload.Note,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if payload.Decision != constants.PROBATION_DECISION_EXTEND {
+			updates["probation_decision"] = payload.Decision
+		}
+		updates["probation_decided_at"] = now
+		updates["probation_decided_by_id"] = decidedByID
+		updates["probation_decision_note"] = strings.TrimSpace(payload.Note)
+
+		result := tx.Model(&models.Contract{}).Where("id = ? AND probation_decision IS NULL", contract.ID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNoOpenProbation
+		}
+		if err := tx.First(&contract, contract.ID).Error; err != nil {
+			return err
+		}
+		if payload.Decision != constants.PROBATION_DECISION_EXTEND {
+			return nil
+		}
+		return syncProbation(tx, user, contract)
+	})
+	if err != nil {
+		s.logger.Error("Cannot Decide Probation", zap.Error(err))
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+func (s *ContractService) SendDueReminders(ctx context.Context) (int, error) {
+	return SendContractReminders(ctx, s.logger, s.db, s.mailer, s.reminderDays, s.hrEmails)
+}
+
+// SendContractReminders mails manager and HR about probation and contract ends within the next days,
+// each end is reminded once. It is run periodically and by contracts:remind command
+func SendContractReminders(ctx context.Context, logger *logger.Logger, db *mysql.MySqlStore, mail *mailer.Mailer, days int, hrEmails string) (int, error) {
+	today := models.EmploymentDate(time.Now())
+	until := today.AddDate(0, 0, days)
+
+	var contracts []models.Contract
+	err := upcomingContractScope(db.DB().WithContext(ctx), until).
+		Where("(probation_end_date IS NOT NULL AND probation_reminder_sent_at IS NULL) OR (end_date IS NOT NULL AND end_reminder_sent_at IS NULL)").
+		Preload("User").
+		Find(&contracts).Error
+	if err != nil {
+		logger.Error("Cannot Find Due Contract Reminders", zap.Error(err))
+		return 0, err
+	}
+	if len(contracts) == 0 {
+		return 0, nil
+	}
+
+	hr, err := contractReminderHREmails(db.DB().WithContext(ctx), hrEmails)
+	if err != nil {
+		logger.Error("Cannot Find HR Recipients", zap.Error(err))
+		return 0, err
+	}
+
+	within := func(date *time.Time) bool {
+		return date != nil && !date.Before(today) && !date.After(until)
+	}
+	sent := 0
+	for i := range contracts {
+		contract := &contracts[i]
+		recipients, err := contractReminderRecipients(db.DB().WithContext(ctx), contract.UserID, hr)
+		if err != nil {
+			logger.Error("Cannot Find Contract Reminder Recipients", zap.Uint("UserID", contract.UserID), zap.Error(err))
+			continue
+		}
+		if len(recipients) == 0 {
+			logger.Warn("Contract reminder has no recipient", zap.Uint("UserID", contract.UserID))
+			continue
+		}
+
+		reminders := []struct {
+			due     bool
+			column  string
+			message mailer.Message
+		}{
+			{
+				due:    contract.ProbationOpen() && contract.ProbationReminderSentAt == nil && within(contract.ProbationEndDate),
+				column: "probation_reminder_sent_at",
+				message: mailer.Message{
+					Subject: fmt.Sprintf("Probation of %s ends on %s", contract.User.Name, contract.ProbationEndDate.Format(time.DateOnly)),
+					Body:    fmt.Sprintf("Probation of %s ends on %s. Confirm, extend or end employment before that date, otherwise probation passes.\n", contract.User.Name, contract.ProbationEndDate.Format(time.DateOnly)),
+				},
+			},
+			{
+				due:    contract.EndReminderSentAt == nil && within(contract.EndDate),
+				column: "end_reminder_sent_at",
+				message: mailer.Message{
+					Subject: fmt.Sprintf("Contract of %s ends on %s", contract.User.Name, contract.EndDate.Format(time.DateOnly)),
+					Body:    fmt.Sprintf("Fixed term contract of %s ends on %s. Add a new contract or record termination before that date.\n", contract.User.Name, contract.EndDate.Format(time.DateOnly)),
+				},
+			},
+		}
+		for _, reminder := range reminders {
+			if !reminder.due {
+				continue
+			}
+			// reminder is marked sent within the transaction, so it is retried when mail cannot be delivered
+			err := db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(&models.Contract{}).
+					Where("id = ? AND "+reminder.column+" IS NULL", contract.ID).
+					Update(reminder.column, time.Now())
+				if result.Error != nil || result.RowsAffected == 0 {
+					return result.Error
+				}
+				reminder.message.To = recipients
+				return mail.Send(reminder.message)
+			})
+			if err != nil {
+				logger.Error("Cannot Send Contract Reminder", zap.Uint("ContractID", contract.ID), zap.Error(err))
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *ContractService) remindPeriodically() {
+	ticker := time.NewTicker(s.reminderInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if sent, err := s.SendDueReminders(context.Background()); err == nil && sent > 0 {
+				s.logger.Info("Sent contract reminders", zap.Int("Reminders", sent))
+			}
+		}
+	}
+}
+
+// upcomingContractScope is contracts of employed users with open probation or contract ending from today until the date
+func upcomingContractScope(db *gorm.DB, until time.Time) *gorm.DB {
+	today := models.EmploymentDate(time.Now())
+	employed := models.ValidScope(db.Session(&gorm.Session{NewDB: true})).
+		Where("lifecycle_state NOT IN ?", []string{constants.LIFECYCLE_STATE_ON_NOTICE, constants.LIFECYCLE_STATE_TERMINATED}).
+		Select("id")
+	return db.Model(&models.Contract{}).
+		Where("user_id IN (?)", employed).
+		Where(
+			db.Session(&gorm.Session{NewDB: true}).
+				Where("probation_decision IS NULL AND probation_end_date BETWEEN ? AND ?", today, until).
+				Or("end_date BETWEEN ? AND ?", today, until),
+		)
+}
+
+// syncProbation mirrors probation of the latest contract on user, started contracts move user in or out of probation
+func syncProbation(tx *gorm.DB, user *models.User, contract *models.Contract) error {
+	var later int64
+	err := tx.Model(&models.Contract{}).Where("user_id = ? AND start_date > ?", user.ID, contract.StartDate).Count(&later).Error
+	if err != nil || later > 0 {
+		return err
+	}
+
+	updates := map[string]interface{}{"probation_end_date": contract.ProbationEndDate}
+	today := models.EmploymentDate(time.Now())
+	if !contract.StartDate.After(today) {
+		onProbation := contract.ProbationEndDate != nil && !contract.ProbationEndDate.Before(today)
+		switch {
+		case onProbation && user.LifecycleState == constants.LIFECYCLE_STATE_ACTIVE:
+			updates["lifecycle_state"] = constants.LIFECYCLE_STATE_PROBATION
+		case !onProbation && user.LifecycleState == constants.LIFECYCLE_STATE_PROBATION:
+			updates["lifecycle_state"] = constants.LIFECYCLE_STATE_ACTIVE
+		}
+	}
+	return models.ValidScope(tx).Where("id = ?", user.ID).Updates(updates).Error
+}
+
+// contractReminderHREmails are configured addresses, or users whose primary role can write employment
+func contractReminderHREmails(db *gorm.DB, configured string) ([]string, error) {
+	emails := []string{}
+	for _, email := range strings.Split(configured, ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	if len(emails) > 0 {
+		return emails, nil
+	}
+	return abilityHolderEmails(db, auth_constants.ABILITY_WRITE_EMPLOYMENT)
+}
+
+// contractReminderRecipients adds manager from employment record in effect to HR
+func contractReminderRecipients(db *gorm.DB, userID uint, hr []string) ([]string, error) {
+	recipients := slices.Clone(hr)
+
+	var record *models.EmploymentRecord
+	err := models.EffectiveEmploymentScope(db, userID, models.EmploymentDate(time.Now())).Preload("Manager").First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return recipients, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Manager != nil && !slices.Contains(recipients, record.Manager.Email) {
+		recipients = append(recipients, record.Manager.Email)
+	}
+	return recipients, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/app/plugins/mailer"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContractService", func() {
+	today := models.EmploymentDate(time.Now())
+
+	registerUser := func() *models.User {
+		user := &models.User{Name: "John Doe", Email: faker.Email(), JoinDate: today.AddDate(0, -1, 0)}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+		return user
+	}
+	addContract := func(user *models.User, probationEndDate time.Time) *models.Contract {
+		startDate := user.JoinDate
+		contract, err := contractService.CreateContract(context.Background(), int(user.ID), dtos.CreateContractRequest{
+			Type:             constants.CONTRACT_TYPE_PERMANENT,
+			StartDate:        &startDate,
+			ProbationEndDate: &probationEndDate,
+		})
+		Expect(err).To(BeNil())
+		return contract
+	}
+	reload := func(user *models.User) *models.User {
+		var found *models.User
+		Expect(models.ValidScope(mockDB.DB()).First(&found, user.ID).Error).To(Succeed())
+		return found
+	}
+
+	It("should put user on probation of current contract", func() {
+		user := registerUser()
+		probationEndDate := today.AddDate(0, 1, 0)
+
+		addContract(user, probationEndDate)
+
+		found := reload(user)
+		Expect(found.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_PROBATION))
+		Expect(found.ProbationEndDate.Equal(probationEndDate)).To(BeTrue())
+	})
+
+	It("should reject overlapping contracts and fixed term without end", func() {
+		user := registerUser()
+		addContract(user, today.AddDate(0, 1, 0))
+		startDate := today.AddDate(0, 2, 0)
+
+		_, err := contractService.CreateContract(context.Background(), int(user.ID), dtos.CreateContractRequest{Type: constants.CONTRACT_TYPE_PERMANENT, StartDate: &startDate})
+		Expect(err).To(MatchError(ErrContractOverlap))
+		_, err = contractService.CreateContract(context.Background(), int(user.ID), dtos.CreateContractRequest{Type: constants.CONTRACT_TYPE_FIXED_TERM, StartDate: &startDate})
+		Expect(err).To(MatchError(ErrContractDates))
+	})
+
+	It("should extend probation and confirm it", func() {
+		user := registerUser()
+		addContract(user, today.AddDate(0, 0, 5))
+		extended := today.AddDate(0, 2, 0)
+
+		contract, err := contractService.DecideProbation(context.Background(), 1, int(user.ID), dtos.ProbationDecisionRequest{Decision: constants.PROBATION_DECISION_EXTEND, ProbationEndDate: &extended})
+		Expect(err).To(BeNil())
+		Expect(contract.ProbationEndDate.Equal(extended)).To(BeTrue())
+		Expect(contract.ProbationExtensions).To(Equal(1))
+		Expect(contract.ProbationDecision).To(BeNil())
+		Expect(reload(user).ProbationEndDate.Equal(extended)).To(BeTrue())
+
+		contract, err = contractService.DecideProbation(context.Background(), 1, int(user.ID), dtos.ProbationDecisionRequest{Decision: constants.PROBATION_DECISION_CONFIRM})
+		Expect(err).To(BeNil())
+		Expect(*contract.ProbationDecision).To(Equal(constants.PROBATION_DECISION_CONFIRM))
+		Expect(reload(user).LifecycleState).To(Equal(constants.LIFECYCLE_STATE_ACTIVE))
+
+		_, err = contractService.DecideProbation(context.Background(), 1, int(user.ID), dtos.ProbationDecisionRequest{Decision: constants.PROBATION_DECISION_END})
+		Expect(err).To(MatchError(ErrNoOpenProbation))
+	})
+
+	It("should put user on notice when probation is ended", func() {
+		user := registerUser()
+		probationEndDate := today.AddDate(0, 0, 5)
+		addContract(user, probationEndDate)
+
+		_, err := contractService.DecideProbation(context.Background(), 1, int(user.ID), dtos.ProbationDecisionRequest{Decision: constants.PROBATION_DECISION_END})
+		Expect(err).To(BeNil())
+
+		Expect(reload(user).LifecycleState).To(Equal(constants.LIFECYCLE_STATE_ON_NOTICE))
+		var termination *models.Termination
+		Expect(models.CurrentTerminationScope(mockDB.DB(), user.ID).First(&termination).Error).To(Succeed())
+		Expect(termination.Reason).To(Equal(constants.TERMINATION_REASON_FAILED_PROBATION))
+		Expect(termination.TerminationDate.Equal(probationEndDate)).To(BeTrue())
+	})
+
+	It("should remind of probation end once", func() {
+		user := registerUser()
+		contract := addContract(user, today.AddDate(0, 0, 3))
+		mail := mailer.NewMailer(mockEnv, mockLogger)
+
+		_, err := SendContractReminders(context.Background(), mockLogger, mockDB, mail, 7, "hr@example.com")
+		Expect(err).To(BeNil())
+		sent, err := SendContractReminders(context.Background(), mockLogger, mockDB, mail, 7, "hr@example.com")
+		Expect(err).To(BeNil())
+		Expect(sent).To(Equal(0))
+
+		var found *models.Contract
+		Expect(mockDB.DB().First(&found, contract.ID).Error).To(Succeed())
+		Expect(found.ProbationReminderSentAt).NotTo(BeNil())
+		Expect(found.EndReminderSentAt).To(BeNil())
+	})
+})
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/app/plugins/storage"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"image"
+	"image/jpeg"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	// photos may be uploaded as GIF and PNG too
+	_ "image/gif"
+	_ "image/png"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDirectoryOffice         = fmt.Errorf("office must not be longer than %d characters", constants.DIRECTORY_MAX_OFFICE)
+	ErrDirectoryPhoneExtension = errors.New("phone extension must be at most 10 digits")
+	ErrDirectorySkills         = fmt.Errorf("at most %d skills of at most %d characters", constants.DIRECTORY_MAX_SKILLS, constants.DIRECTORY_MAX_SKILL_LENGTH)
+	ErrDirectoryPhoto          = errors.New("photo must be a JPEG, PNG or GIF image")
+	ErrDirectoryPhotoTooLarge  = fmt.Errorf("photo must not be larger than %d bytes and %d pixels", constants.DIRECTORY_PHOTO_MAX_BYTES, constants.DIRECTORY_PHOTO_MAX_SOURCE_PIXELS)
+	ErrDirectoryPhotoSize      = errors.New("photo size must be large, medium or small")
+	ErrNoDirectoryPhoto        = errors.New("user has no photo")
+)
+
+type DirectoryServiceInterface interface {
+	FindDirectory(pagination *utils.Pagination) ([]dtos.DirectoryItem, int64, error)
+	FindDirectoryEntry(userID int) (*dtos.DirectoryItem, error)
+	UpdateDirectoryEntry(ctx context.Context, userID int, payload dtos.UpdateDirectoryEntryRequest) (*dtos.DirectoryItem, error)
+	UploadPhoto(ctx context.Context, userID int, content []byte) (*dtos.DirectoryItem, error)
+	DeletePhoto(ctx context.Context, userID int) (*dtos.DirectoryItem, error)
+	OpenPhoto(ctx context.Context, userID int, size string) (io.ReadCloser, error)
+}
+
+type DirectoryService struct {
+	logger  *logger.Logger
+	db      *mysql.MySqlStore
+	storage *storage.Storage
+}
+
+func NewDirectoryService(logger *logger.Logger, db *mysql.MySqlStore, storage *storage.Storage) DirectoryServiceInterface {
+	return &DirectoryService{
+		logger:  logger,
+		db:      db,
+		storage: storage,
+	}
+}
+
+var directoryFilters = []utils.Filter{
+	{Param: constants.USER_FILTER_DEPARTMENT, Column: "department_id", Type: utils.FILTER_TYPE_INT},
+}
+
+var directorySortFields = utils.SortFields{
+	"name": "name",
+}
+
+// directoryScope is current human employees, service accounts and former employees are not listed
+func directoryScope(db *gorm.DB) *gorm.DB {
+	return models.ValidScope(db).
+		Where("type = ? AND lifecycle_state IN ?", constants.USER_TYPE_HUMAN, constants.DIRECTORY_LIFECYCLE_STATES)
+}
+
+// FindDirectory lists employees by name, search ranks them by fuzzy match of name and skills instead
+// and pages by page only
+func (s *DirectoryService) FindDirectory(pagination *utils.Pagination) ([]dtos.DirectoryItem, int64, error) {
+	filter, err := pagination.FilterScope(directoryFilters)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pagination.Search != "" {
+		return s.searchDirectory(pagination, filter)
+	}
+
+	var totalCount int64
+	if err := directoryScope(s.db.DB()).Scopes(filter).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination.Sort == utils.SORT_DEFAULT {
+		pagination.Sort = "name"
+	}
+	var users []models.User
+	if err := pagination.FindPage(directoryScope(s.db.DB()).Scopes(filter).Preload("Department"), directorySortFields, &users); err != nil {
+		return nil, 0, err
+	}
+
+	items, err := s.directoryItems(s.db.DB(), users)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, totalCount, nil
+}
+
+// searchDirectory scores every candidate in memory, directory is small enough and fuzzy match has no index
+func (s *DirectoryService) searchDirectory(pagination *utils.Pagination, filter func(*gorm.DB) *gorm.DB) ([]dtos.DirectoryItem, int64, error) {
+	var candidates []models.User
+	if err := directoryScope(s.db.DB()).Scopes(filter).Select("id", "name").Find(&candidates).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []models.DirectoryEntry
+	err := s.db.DB().Select("user_id", "skills").
+		Where("user_id IN (?)", directoryScope(s.db.DB()).Scopes(filter).Select("id")).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	skills := map[uint][]string{}
+	for _, entry := range entries {
+		skills[entry.UserID] = entry.Skills
+	}
+
+	type match struct {
+		user  models.User
+		score float64
+	}
+	matches := []match{}
+	for _, user := range candidates {
+		if score := utils.FuzzyScore(pagination.Search, append([]string{user.Name}, skills[user.ID]...)...); score > 0 {
+			matches = append(matches, match{user: user, score: score})
+		}
+	}
+	slices.SortFunc(matches, func(a, b match) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		if byName := strings.Compare(strings.ToLower(a.user.Name), strings.ToLower(b.user.Name)); byName != 0 {
+			return byName
+		}
+		return int(a.user.ID) - int(b.user.ID)
+	})
+
+	start := min(pagination.Offset(), len(matches))
+	end := min(start+pagination.GetLimit(), len(matches))
+	ids := []uint{}
+	for _, match := range matches[start:end] {
+		ids = append(ids, match.user.ID)
+	}
+	var users []models.User
+	if err := models.ValidScope(s.db.DB()).Preload("Department").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	slices.SortFunc(users, func(a, b models.User) int {
+		return slices.Index(ids, a.ID) - slices.Index(ids, b.ID)
+	})
+
+	items, err := s.directoryItems(s.db.DB(), users)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, int64(len(matches)), nil
+}
+
+func (s *DirectoryService) FindDirectoryEntry(userID int) (*dtos.DirectoryItem, error) {
+	var user models.User
+	if err := directoryScope(s.db.DB()).Preload("Department").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	items, err := s.directoryItems(s.db.DB(), []models.User{user})
+	if err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// UpdateDirectoryEntry changes fields set in payload, skills are trimmed and deduplicated
+func (s *DirectoryService) UpdateDirectoryEntry(ctx context.Context, userID int, payload dtos.UpdateDirectoryEntryRequest) (*dtos.DirectoryItem, error) {
+	if err := normalizeDirectoryEntry(&payload); err != nil {
+		return nil, err
+	}
+
+	var item *dtos.DirectoryItem
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entry, err := s.findEntry(tx, userID)
+		if err != nil {
+			return err
+		}
+		if payload.Office != nil {
+			entry.Office = *payload.Office
+		}
+		if payload.PhoneExtension != nil {
+			entry.PhoneExtension = *payload.PhoneExtension
+		}
+		if payload.Skills != nil {
+			entry.Skills = *payload.Skills
+		}
+		if err := tx.Omit("User").Save(&entry).Error; err != nil {
+			return err
+		}
+		item, err = s.entryItem(tx, entry)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Cannot Update Directory Entry", zap.Error(err))
+		return nil, err
+	}
+	return item, nil
+}
+
+// UploadPhoto stores every photo size under a new key so cached old photos never show up for the new one,
+// files of the replaced photo are removed once the entry points to the new ones
+func (s *DirectoryService) UploadPhoto(ctx context.Context, userID int, content []byte) (*dtos.DirectoryItem, error) {
+	photos, err := directoryPhotos(content)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := s.findEntry(s.db.DB(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldEntry := *entry
+	key, err := newPhotoKey(entry.UserID)
+	if err != nil {
+		return nil, err
+	}
+	entry.PhotoKey = &key
+	stored := []string{}
+	for size, photo := range photos {
+		if err := s.storage.Put(ctx, entry.PhotoSizeKey(size), bytes.NewReader(photo)); err != nil {
+			s.logger.Error("Cannot Store Photo", zap.Error(err))
+			s.storage.DeleteQuietly(ctx, stored...)
+			return nil, err
+		}
+		stored = append(stored, entry.PhotoSizeKey(size))
+	}
+
+	if err := s.db.DB().WithContext(ctx).Omit("User").Save(&entry).Error; err != nil {
+		s.logger.Error("Cannot Update Directory Entry", zap.Error(err))
+		s.storage.DeleteQuietly(ctx, stored...)
+		return nil, err
+	}
+	s.deletePhotoFiles(ctx, &oldEntry)
+	return s.entryItem(s.db.DB(), entry)
+}
+
+// DeletePhoto of user without photo does nothing
+func (s *DirectoryService) DeletePhoto(ctx context.Context, userID int) (*dtos.DirectoryItem, error) {
+	entry, err := s.findEntry(s.db.DB(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.PhotoKey == nil {
+		return s.entryItem(s.db.DB(), entry)
+	}
+
+	oldEntry := *entry
+	entry.PhotoKey = nil
+	if err := s.db.DB().WithContext(ctx).Omit("User").Save(&entry).Error; err != nil {
+		s.logger.Error("Cannot Update Directory Entry", zap.Error(err))
+		return nil, err
+	}
+	s.deletePhotoFiles(ctx, &oldEntry)
+	return s.entryItem(s.db.DB(), entry)
+}
+
+// OpenPhoto streams one size of the photo of a user listed in directory
+func (s *DirectoryService) OpenPhoto(ctx context.Context, userID int, size string) (io.ReadCloser, error) {
+	if _, ok := constants.DIRECTORY_PHOTO_PIXELS[size]; !ok {
+		return nil, ErrDirectoryPhotoSize
+	}
+	var user models.User
+	if err := directoryScope(s.db.DB()).Select("id").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	var entry models.DirectoryEntry
+	err := s.db.DB().Where("user_id = ?", user.ID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && entry.PhotoKey == nil) {
+		return nil, ErrNoDirectoryPhoto
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	photo, err := s.storage.Open(ctx, entry.PhotoSizeKey(size))
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		s.logger.Warn("Photo missing from storage", zap.String("key", entry.PhotoSizeKey(size)))
+		return nil, ErrNoDirectoryPhoto
+	}
+	return photo, err
+}
+
+// findEntry returns empty entry for users who have not filled it in yet
+func (s *DirectoryService) findEntry(db *gorm.DB, userID int) (*models.DirectoryEntry, error) {
+	var user *models.User
+	if err := models.ValidScope(db).Preload("Department").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var entry *models.DirectoryEntry
+	err := db.Where("user_id = ?", user.ID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		entry = &models.DirectoryEntry{UserID: user.ID}
+	} else if err != nil {
+		s.logger.Error("Cannot Find Directory Entry", zap.Error(err))
+		return nil, err
+	}
+
+	entry.User = user
+	return entry, nil
+}
+
+func (s *DirectoryService) entryItem(db *gorm.DB, entry *models.DirectoryEntry) (*dtos.DirectoryItem, error) {
+	item := dtos.DirectoryItem{User: entry.User, Entry: entry}
+	var employment models.EmploymentRecord
+	err := models.AppliedEmploymentScope(db, []uint{entry.UserID}).Preload("Manager").First(&employment).Error
+	if err == nil {
+		item.Employment = &employment
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// directoryItems loads entries and employment records in effect of users, in the order of users
+func (s *DirectoryService) directoryItems(db *gorm.DB, users []models.User) ([]dtos.DirectoryItem, error) {
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+
+	var entries []*models.DirectoryEntry
+	if err := db.Where("user_id IN ?", ids).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	var employments []*models.EmploymentRecord
+	if err := models.AppliedEmploymentScope(db, ids).Preload("Manager").Find(&employments).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]dtos.DirectoryItem, len(users))
+	for i := range users {
+		items[i].User = &users[i]
+		for _, entry := range entries {
+			if entry.UserID == users[i].ID {
+				items[i].Entry = entry
+			}
+		}
+		for _, employment := range employments {
+			if employment.UserID == users[i].ID {
+				items[i].Employment = employment
+			}
+		}
+	}
+	return items, nil
+}
+
+func (s *DirectoryService) deletePhotoFiles(ctx context.Context, entry *models.DirectoryEntry) {
+	if entry.PhotoKey == nil {
+		return
+	}
+	for size := range constants.DIRECTORY_PHOTO_PIXELS {
+		s.storage.DeleteQuietly(ctx, entry.PhotoSizeKey(size))
+	}
+}
+
+// normalizeDirectoryEntry trims payload in place and validates it
+func normalizeDirectoryEntry(payload *dtos.UpdateDirectoryEntryRequest) error {
+	if payload.Office != nil {
+		*payload.Office = strings.TrimSpace(*payload.Office)
+		if utf8.RuneCountInString(*payload.Office) > constants.DIRECTORY_MAX_OFFICE {
+			return ErrDirectoryOffice
+		}
+	}
+
+	if payload.PhoneExtension != nil {
+		*payload.PhoneExtension = strings.TrimSpace(*payload.PhoneExtension)
+		// empty extension removes it
+		if len(*payload.PhoneExtension) > 10 || strings.Trim(*payload.PhoneExtension, "0123456789") != "" {
+			return ErrDirectoryPhoneExtension
+		}
+	}
+
+	if payload.Skills != nil {
+		skills := []string{}
+		for _, skill := range *payload.Skills {
+			skill = strings.Join(strings.Fields(skill), " ")
+			if utf8.RuneCountInString(skill) > constants.DIRECTORY_MAX_SKILL_LENGTH {
+				return ErrDirectorySkills
+			}
+			duplicate := slices.ContainsFunc(skills, func(other string) bool { return strings.EqualFold(other, skill) })
+			if skill != "" && !duplicate {
+				skills = append(skills, skill)
+			}
+		}
+		if len(skills) > constants.DIRECTORY_MAX_SKILLS {
+			return ErrDirectorySkills
+		}
+		*payload.Skills = skills
+	}
+
+	return nil
+}
+
+// directoryPhotos decodes uploaded image and encodes every photo size as JPEG, thumbnails are scaled
+// from the next larger size which is much faster than from the original
+func directoryPhotos(content []byte) (map[string][]byte, error) {
+	if len(content) > constants.DIRECTORY_PHOTO_MAX_BYTES {
+		return nil, ErrDirectoryPhotoTooLarge
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrDirectoryPhoto
+	}
+	if config.Width*config.Height > constants.DIRECTORY_PHOTO_MAX_SOURCE_PIXELS {
+		return nil, ErrDirectoryPhotoTooLarge
+	}
+	source, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrDirectoryPhoto
+	}
+
+	photos := map[string][]byte{}
+	large := utils.Thumbnail(source, constants.DIRECTORY_PHOTO_PIXELS[constants.DIRECTORY_PHOTO_SIZE_LARGE], false)
+	medium := utils.Thumbnail(large, constants.DIRECTORY_PHOTO_PIXELS[constants.DIRECTORY_PHOTO_SIZE_MEDIUM], true)
+	small := utils.Thumbnail(medium, constants.DIRECTORY_PHOTO_PIXELS[constants.DIRECTORY_PHOTO_SIZE_SMALL], true)
+	for size, photo := range map[string]*image.RGBA{
+		constants.DIRECTORY_PHOTO_SIZE_LARGE:  large,
+		constants.DIRECTORY_PHOTO_SIZE_MEDIUM: medium,
+		constants.DIRECTORY_PHOTO_SIZE_SMALL:  small,
+	} {
+		flattenAlpha(photo)
+		var buffer bytes.Buffer
+		if err := jpeg.Encode(&buffer, photo, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		photos[size] = buffer.Bytes()
+	}
+	return photos, nil
+}
+
+// flattenAlpha puts transparent pixels on white background, JPEG has no transparency
+func flattenAlpha(photo *image.RGBA) {
+	for i := 0; i < len(photo.Pix); i += 4 {
+		// RGBA is premultiplied, so the background adds what alpha leaves uncovered
+		uncovered := 255 - photo.Pix[i+3]
+		photo.Pix[i] += uncovered
+		photo.Pix[i+1] += uncovered
+		photo.Pix[i+2] += uncovered
+		photo.Pix[i+3] = 255
+	}
+}
+
+func newPhotoKey(userID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("directory/%d/%s", userID, hex.EncodeToString(random)), nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DirectoryService", func() {
+	registerUser := func(name string) *models.User {
+		user := &models.User{Name: name, Email: faker.Email()}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+		return user
+	}
+
+	// pngPhoto is red with transparent left half
+	pngPhoto := func(width int, height int) []byte {
+		photo := image.NewNRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := width / 2; x < width; x++ {
+				photo.Set(x, y, color.NRGBA{R: 200, G: 40, B: 40, A: 255})
+			}
+		}
+		var buffer bytes.Buffer
+		Expect(png.Encode(&buffer, photo)).To(Succeed())
+		return buffer.Bytes()
+	}
+
+	openPhoto := func(userID uint, size string) image.Image {
+		file, err := directoryService.OpenPhoto(context.Background(), int(userID), size)
+		Expect(err).To(BeNil())
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		photo, err := jpeg.Decode(bytes.NewReader(content))
+		Expect(err).To(BeNil())
+		return photo
+	}
+
+	It("should find employees by name and skill with typos", func() {
+		quentin := registerUser("Quentin Marlowe")
+		skills := []string{"Kubernetes", " kubernetes ", "Terraform"}
+		_, err := directoryService.UpdateDirectoryEntry(context.Background(), int(quentin.ID), dtos.UpdateDirectoryEntryRequest{Skills: &skills})
+		Expect(err).To(BeNil())
+
+		byName := &utils.Pagination{Search: "quentn marl"}
+		items, total, err := directoryService.FindDirectory(byName)
+		Expect(err).To(BeNil())
+		Expect(total).To(BeNumerically(">=", 1))
+		Expect(items[0].User.ID).To(Equal(quentin.ID))
+
+		bySkill := &utils.Pagination{Search: "kubernets marlowe"}
+		items, _, err = directoryService.FindDirectory(bySkill)
+		Expect(err).To(BeNil())
+		Expect(items).To(HaveLen(1))
+		Expect(items[0].Entry.Skills).To(Equal([]string{"Kubernetes", "Terraform"}))
+	})
+
+	It("should leave service accounts and former employees out", func() {
+		employee := registerUser("Ottoline Vasquez")
+		former := registerUser("Ottoline Vasquez")
+		Expect(mockDB.DB().Model(&models.User{}).Where("id = ?", former.ID).Update("lifecycle_state", constants.LIFECYCLE_STATE_TERMINATED).Error).To(Succeed())
+		service := registerUser("Ottoline Vasquez")
+		Expect(mockDB.DB().Model(&models.User{}).Where("id = ?", service.ID).Update("type", constants.USER_TYPE_SERVICE_ACCOUNT).Error).To(Succeed())
+
+		items, total, err := directoryService.FindDirectory(&utils.Pagination{Search: "Ottoline Vasquez"})
+
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(int64(1)))
+		Expect(items[0].User.ID).To(Equal(employee.ID))
+		_, err = directoryService.FindDirectoryEntry(int(former.ID))
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should reject phone extension which is not digits", func() {
+		user := registerUser("John Doe")
+		extension := "ext 12"
+
+		_, err := directoryService.UpdateDirectoryEntry(context.Background(), int(user.ID), dtos.UpdateDirectoryEntryRequest{PhoneExtension: &extension})
+
+		Expect(err).To(MatchError(ErrDirectoryPhoneExtension))
+	})
+
+	It("should store photo in every size and remove it", func() {
+		user := registerUser("John Doe")
+
+		item, err := directoryService.UploadPhoto(context.Background(), int(user.ID), pngPhoto(300, 200))
+		Expect(err).To(BeNil())
+		Expect(item.Entry.PhotoKey).NotTo(BeNil())
+
+		Expect(openPhoto(user.ID, constants.DIRECTORY_PHOTO_SIZE_LARGE).Bounds().Size()).To(Equal(image.Pt(300, 200)))
+		Expect(openPhoto(user.ID, constants.DIRECTORY_PHOTO_SIZE_MEDIUM).Bounds().Size()).To(Equal(image.Pt(200, 200)))
+		small := openPhoto(user.ID, constants.DIRECTORY_PHOTO_SIZE_SMALL)
+		Expect(small.Bounds().Size()).To(Equal(image.Pt(64, 64)))
+		// transparent left edge turns white instead of black
+		r, g, b, _ := small.At(0, 32).RGBA()
+		Expect(min(r, g, b) >> 8).To(BeNumerically(">", 240))
+
+		_, err = directoryService.DeletePhoto(context.Background(), int(user.ID))
+		Expect(err).To(BeNil())
+		_, err = directoryService.OpenPhoto(context.Background(), int(user.ID), constants.DIRECTORY_PHOTO_SIZE_SMALL)
+		Expect(err).To(MatchError(ErrNoDirectoryPhoto))
+	})
+
+	It("should reject file which is not an image", func() {
+		user := registerUser("John Doe")
+
+		_, err := directoryService.UploadPhoto(context.Background(), int(user.ID), []byte("%PDF-1.4"))
+
+		Expect(err).To(MatchError(ErrDirectoryPhoto))
+	})
+})
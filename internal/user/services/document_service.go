@@ -0,0 +1,477 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/app/plugins/storage"
+	auth_models "hr-system-go/internal/auth/models"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDocumentCategoryName    = fmt.Errorf("name is required and must not be longer than %d characters", constants.DOCUMENT_MAX_CATEGORY_NAME)
+	ErrDocumentCategoryTaken   = errors.New("document category name is already used")
+	ErrDocumentCategoryAbility = errors.New("readAbility and writeAbility must be existing abilities")
+	ErrDocumentCategoryInUse   = errors.New("document category still has documents")
+	ErrDocumentCategory        = errors.New("categoryId must be an existing document category")
+	ErrDocumentFileName        = fmt.Errorf("file name is required and must not be longer than %d characters", constants.DOCUMENT_MAX_FILE_NAME)
+	ErrDocumentEmpty           = errors.New("document is empty")
+	// ErrDocumentTooLarge is wrapped with the configured limit
+	ErrDocumentTooLarge = errors.New("document is too large")
+	ErrDocumentChecksum = errors.New("checksum must be hex SHA-256 of the content")
+	ErrDocumentExpiry   = errors.New("expiresOn is required for documents of this category")
+)
+
+type DocumentServiceInterface interface {
+	FindCategories() ([]models.DocumentCategory, error)
+	FindCategory(categoryID int) (*models.DocumentCategory, error)
+	CreateCategory(ctx context.Context, payload dtos.DocumentCategoryRequest) (*models.DocumentCategory, error)
+	UpdateCategory(ctx context.Context, categoryID int, payload dtos.DocumentCategoryRequest) (*models.DocumentCategory, error)
+	DeleteCategory(ctx context.Context, categoryID int) error
+	FindDocuments(userID int) ([]models.Document, error)
+	FindDocument(userID int, documentID int) (*models.Document, error)
+	FindExpiringDocuments(until time.Time) ([]models.Document, error)
+	MaxBytes() int64
+	UploadDocument(ctx context.Context, userID int, payload dtos.UploadDocumentRequest) (*models.Document, error)
+	UpdateDocument(ctx context.Context, userID int, documentID int, payload dtos.UpdateDocumentRequest) (*models.Document, error)
+	DeleteDocument(ctx context.Context, userID int, documentID int) error
+	OpenDocument(ctx context.Context, document *models.Document) (io.ReadCloser, error)
+	SendDueReminders(ctx context.Context) (int, error)
+}
+
+type DocumentService struct {
+	logger  *logger.Logger
+	db      *mysql.MySqlStore
+	storage *storage.Storage
+	mailer  *mailer.Mailer
+	// maxBytes is the largest document accepted, larger uploads are aborted while streaming
+	maxBytes int64
+	// reminderDays is how long before expiry employee and HR are notified
+	reminderDays int
+	// reminderInterval is how often due reminders are sent, zero leaves it to documents:remind command
+	reminderInterval time.Duration
+	stop             chan struct{}
+}
+
+func NewDocumentService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, storage *storage.Storage, mailer *mailer.Mailer, lc fx.Lifecycle) DocumentServiceInterface {
+	service := &DocumentService{
+		logger:           logger,
+		db:               db,
+		storage:          storage,
+		mailer:           mailer,
+		maxBytes:         int64(env.GetEnvInt("DOCUMENT_MAX_MB", constants.DOCUMENT_DEFAULT_MAX_MB)) << 20,
+		reminderDays:     env.GetEnvInt("DOCUMENT_EXPIRY_REMINDER_DAYS", constants.DOCUMENT_DEFAULT_EXPIRY_REMINDER_DAYS),
+		reminderInterval: time.Duration(env.GetEnvInt("DOCUMENT_EXPIRY_REMINDER_INTERVAL_MINUTES", constants.DOCUMENT_DEFAULT_EXPIRY_REMINDER_INTERVAL_MINUTES)) * time.Minute,
+		stop:             make(chan struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if service.reminderInterval > 0 {
+				go service.remindPeriodically()
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(service.stop)
+			return nil
+		},
+	})
+	return service
+}
+
+func (s *DocumentService) FindCategories() ([]models.DocumentCategory, error) {
+	var categories []models.DocumentCategory
+	if err := s.db.DB().Order("name").Find(&categories).Error; err != nil {
+		s.logger.Error("Cannot Find Document Categories", zap.Error(err))
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (s *DocumentService) FindCategory(categoryID int) (*models.DocumentCategory, error) {
+	var category *models.DocumentCategory
+	if err := s.db.DB().First(&category, categoryID).Error; err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *DocumentService) CreateCategory(ctx context.Context, payload dtos.DocumentCategoryRequest) (*models.DocumentCategory, error) {
+	category := &models.DocumentCategory{}
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := applyDocumentCategory(tx, category, payload); err != nil {
+			return err
+		}
+		return tx.Create(&category).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Create Document Category", zap.Error(err))
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *DocumentService) UpdateCategory(ctx context.Context, categoryID int, payload dtos.DocumentCategoryRequest) (*models.DocumentCategory, error) {
+	var category *models.DocumentCategory
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&category, categoryID).Error; err != nil {
+			return err
+		}
+		if err := applyDocumentCategory(tx, category, payload); err != nil {
+			return err
+		}
+		return tx.Save(&category).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Update Document Category", zap.Error(err))
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory refuses categories with documents, their access would be lost with it
+func (s *DocumentService) DeleteCategory(ctx context.Context, categoryID int) error {
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var category *models.DocumentCategory
+		if err := tx.First(&category, categoryID).Error; err != nil {
+			return err
+		}
+		var documents int64
+		if err := tx.Model(&models.Document{}).Where("category_id = ?", category.ID).Count(&documents).Error; err != nil {
+			return err
+		}
+		if documents > 0 {
+			return ErrDocumentCategoryInUse
+		}
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Delete Document Category", zap.Error(err))
+	}
+	return err
+}
+
+// FindDocuments lists documents of user in every category, latest first
+func (s *DocumentService) FindDocuments(userID int) ([]models.Document, error) {
+	var user *models.User
+	if err := models.ValidScope(s.db.DB()).Select("id").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	var documents []models.Document
+	if err := s.db.DB().Preload("Category").Where("user_id = ?", user.ID).Order("id DESC").Find(&documents).Error; err != nil {
+		s.logger.Error("Cannot Find Documents", zap.Error(err))
+		return nil, err
+	}
+	return documents, nil
+}
+
+func (s *DocumentService) FindDocument(userID int, documentID int) (*models.Document, error) {
+	var document *models.Document
+	err := s.db.DB().Preload("Category").
+		Where("user_id IN (?)", models.ValidScope(s.db.DB()).Where("id = ?", userID).Select("id")).
+		First(&document, documentID).Error
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// FindExpiringDocuments lists documents of employed users expiring from today until the date, soonest first
+func (s *DocumentService) FindExpiringDocuments(until time.Time) ([]models.Document, error) {
+	var documents []models.Document
+	if err := expiringDocumentScope(s.db.DB(), until).Preload("Category").Preload("User").Order("expires_on, id").Find(&documents).Error; err != nil {
+		s.logger.Error("Cannot Find Expiring Documents", zap.Error(err))
+		return nil, err
+	}
+	return documents, nil
+}
+
+// MaxBytes is the largest document accepted, controller limits request body to it
+func (s *DocumentService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// UploadDocument streams content to storage while counting and hashing it, the stored object is removed again
+// when it turns out too large, empty or different from the checksum of the client
+func (s *DocumentService) UploadDocument(ctx context.Context, userID int, payload dtos.UploadDocumentRequest) (*models.Document, error) {
+	fileName := strings.TrimSpace(path.Base(strings.ReplaceAll(payload.FileName, `\`, "/")))
+	if fileName == "" || fileName == "." || fileName == "/" || utf8.RuneCountInString(fileName) > constants.DOCUMENT_MAX_FILE_NAME {
+		return nil, ErrDocumentFileName
+	}
+	payload.Checksum = strings.ToLower(strings.TrimSpace(payload.Checksum))
+	if payload.Checksum != "" {
+		if decoded, err := hex.DecodeString(payload.Checksum); err != nil || len(decoded) != sha256.Size {
+			return nil, ErrDocumentChecksum
+		}
+	}
+
+	var user *models.User
+	if err := models.ValidScope(s.db.DB()).Select("id").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	var category *models.DocumentCategory
+	err := s.db.DB().First(&category, payload.CategoryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDocumentCategory
+	}
+	if err != nil {
+		return nil, err
+	}
+	if category.ExpiryRequired && payload.ExpiresOn == nil {
+		return nil, ErrDocumentExpiry
+	}
+
+	key, err := newDocumentKey(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	content := &documentReader{reader: payload.Content, hash: sha256.New(), maxBytes: s.maxBytes}
+	if err := s.storage.Put(ctx, key, content); err != nil {
+		if errors.Is(err, ErrDocumentTooLarge) {
+			return nil, fmt.Errorf("%w, at most %d bytes", ErrDocumentTooLarge, s.maxBytes)
+		}
+		s.logger.Error("Cannot Store Document", zap.Error(err))
+		return nil, err
+	}
+
+	checksum := hex.EncodeToString(content.hash.Sum(nil))
+	switch {
+	case content.size == 0:
+		s.storage.DeleteQuietly(ctx, key)
+		return nil, ErrDocumentEmpty
+	case payload.Checksum != "" && payload.Checksum != checksum:
+		s.storage.DeleteQuietly(ctx, key)
+		return nil, ErrDocumentChecksum
+	}
+
+	document := &models.Document{
+		UserID:      user.ID,
+		CategoryID:  category.ID,
+		FileName:    fileName,
+		ContentType: payload.ContentType,
+		Size:        content.size,
+		Checksum:    checksum,
+		StorageKey:  key,
+		Note:        strings.TrimSpace(payload.Note),
+		ExpiresOn:   payload.ExpiresOn,
+		Category:    category,
+	}
+	if payload.UploadedByID != 0 {
+		document.UploadedByID = &payload.UploadedByID
+	}
+	if err := s.db.DB().WithContext(ctx).Omit("User", "Category").Create(&document).Error; err != nil {
+		s.logger.Error("Cannot Create Document", zap.Error(err))
+		s.storage.DeleteQuietly(ctx, key)
+		return nil, err
+	}
+	return document, nil
+}
+
+func (s *DocumentService) UpdateDocument(ctx context.Context, userID int, documentID int, payload dtos.UpdateDocumentRequest) (*models.Document, error) {
+	document, err := s.FindDocument(userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if payload.ExpiresOn != nil && (document.ExpiresOn == nil || !payload.ExpiresOn.Equal(*document.ExpiresOn)) {
+		document.ExpiresOn = payload.ExpiresOn
+		document.ExpiryReminderSentAt = nil
+	}
+	if payload.Note != nil {
+		document.Note = strings.TrimSpace(*payload.Note)
+	}
+	if err := s.db.DB().WithContext(ctx).Omit("User", "Category").Save(&document).Error; err != nil {
+		s.logger.Error("Cannot Update Document", zap.Error(err))
+		return nil, err
+	}
+	return document, nil
+}
+
+// DeleteDocument removes the file right after the record, a failure leaves an orphan file only
+func (s *DocumentService) DeleteDocument(ctx context.Context, userID int, documentID int) error {
+	document, err := s.FindDocument(userID, documentID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.DB().WithContext(ctx).Delete(&document).Error; err != nil {
+		s.logger.Error("Cannot Delete Document", zap.Error(err))
+		return err
+	}
+	s.storage.DeleteQuietly(ctx, document.StorageKey)
+	return nil
+}
+
+func (s *DocumentService) OpenDocument(ctx context.Context, document *models.Document) (io.ReadCloser, error) {
+	return s.storage.Open(ctx, document.StorageKey)
+}
+
+func (s *DocumentService) SendDueReminders(ctx context.Context) (int, error) {
+	return SendDocumentExpiryReminders(ctx, s.logger, s.db, s.mailer, s.reminderDays)
+}
+
+// SendDocumentExpiryReminders mails employee and holders of write ability of the category about documents
+// expiring within the next days, each document is reminded once. It is run periodically and by documents:remind command
+func SendDocumentExpiryReminders(ctx context.Context, logger *logger.Logger, db *mysql.MySqlStore, mail *mailer.Mailer, days int) (int, error) {
+	until := models.EmploymentDate(time.Now()).AddDate(0, 0, days)
+
+	var documents []models.Document
+	err := expiringDocumentScope(db.DB().WithContext(ctx), until).
+		Where("expiry_reminder_sent_at IS NULL").
+		Preload("Category").
+		Preload("User").
+		Find(&documents).Error
+	if err != nil {
+		logger.Error("Cannot Find Due Document Reminders", zap.Error(err))
+		return 0, err
+	}
+
+	// categories share their write ability often, look every ability up once
+	holders := map[string][]string{}
+	sent := 0
+	for i := range documents {
+		document := &documents[i]
+		ability := document.Category.WriteAbility
+		if _, ok := holders[ability]; !ok {
+			emails, err := abilityHolderEmails(db.DB().WithContext(ctx), ability)
+			if err != nil {
+				logger.Error("Cannot Find Document Reminder Recipients", zap.String("Ability", ability), zap.Error(err))
+				continue
+			}
+			holders[ability] = emails
+		}
+		recipients := slices.Clone(holders[ability])
+		if !slices.Contains(recipients, document.User.Email) {
+			recipients = append(recipients, document.User.Email)
+		}
+
+		expiresOn := document.ExpiresOn.Format(time.DateOnly)
+		message := mailer.Message{
+			To:      recipients,
+			Subject: fmt.Sprintf("%s of %s expires on %s", document.Category.Name, document.User.Name, expiresOn),
+			Body:    fmt.Sprintf("Document %s (%s) of %s expires on %s. Upload a renewed document before that date.\n", document.FileName, document.Category.Name, document.User.Name, expiresOn),
+		}
+		// reminder is marked sent within the transaction, so it is retried when mail cannot be delivered
+		err := db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Document{}).
+				Where("id = ? AND expiry_reminder_sent_at IS NULL", document.ID).
+				Update("expiry_reminder_sent_at", time.Now())
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			return mail.Send(message)
+		})
+		if err != nil {
+			logger.Error("Cannot Send Document Reminder", zap.Uint("DocumentID", document.ID), zap.Error(err))
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *DocumentService) remindPeriodically() {
+	ticker := time.NewTicker(s.reminderInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if sent, err := s.SendDueReminders(context.Background()); err == nil && sent > 0 {
+				s.logger.Info("Sent document reminders", zap.Int("Reminders", sent))
+			}
+		}
+	}
+}
+
+// expiringDocumentScope is documents of employed users expiring from today until the date
+func expiringDocumentScope(db *gorm.DB, until time.Time) *gorm.DB {
+	employed := models.ValidScope(db.Session(&gorm.Session{NewDB: true})).
+		Where("lifecycle_state != ?", constants.LIFECYCLE_STATE_TERMINATED).
+		Select("id")
+	return db.Model(&models.Document{}).
+		Where("user_id IN (?)", employed).
+		Where("expires_on BETWEEN ? AND ?", models.EmploymentDate(time.Now()), until)
+}
+
+// applyDocumentCategory validates payload against other categories and known abilities
+func applyDocumentCategory(tx *gorm.DB, category *models.DocumentCategory, payload dtos.DocumentCategoryRequest) error {
+	name := strings.TrimSpace(payload.Name)
+	if name == "" || utf8.RuneCountInString(name) > constants.DOCUMENT_MAX_CATEGORY_NAME {
+		return ErrDocumentCategoryName
+	}
+	var taken int64
+	if err := tx.Model(&models.DocumentCategory{}).Where("name = ? AND id != ?", name, category.ID).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrDocumentCategoryTaken
+	}
+
+	abilities := []string{strings.TrimSpace(payload.ReadAbility), strings.TrimSpace(payload.WriteAbility)}
+	var known int64
+	if err := tx.Model(&auth_models.Ability{}).Where("name IN ? AND status != ?", abilities, "removed").Distinct("name").Count(&known).Error; err != nil {
+		return err
+	}
+	distinct := int64(2)
+	if abilities[0] == abilities[1] {
+		distinct = 1
+	}
+	if abilities[0] == "" || abilities[1] == "" || known != distinct {
+		return ErrDocumentCategoryAbility
+	}
+
+	category.Name = name
+	category.Description = strings.TrimSpace(payload.Description)
+	category.ReadAbility, category.WriteAbility = abilities[0], abilities[1]
+	category.SelfService = payload.SelfService
+	category.ExpiryRequired = payload.ExpiryRequired
+	return nil
+}
+
+// documentReader counts and hashes content on its way to storage and fails once it exceeds maxBytes
+type documentReader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	size     int64
+	maxBytes int64
+}
+
+func (r *documentReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+	r.hash.Write(p[:n])
+	if r.size > r.maxBytes {
+		return n, ErrDocumentTooLarge
+	}
+	return n, err
+}
+
+func newDocumentKey(userID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("documents/%d/%s", userID, hex.EncodeToString(random)), nil
+}
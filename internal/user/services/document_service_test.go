@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hr-system-go/app/plugins/mailer"
+	auth_constants "hr-system-go/internal/auth/constants"
+	auth_models "hr-system-go/internal/auth/models"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DocumentService", func() {
+	today := models.EmploymentDate(time.Now())
+
+	registerUser := func() *models.User {
+		user := &models.User{Name: "John Doe", Email: faker.Email()}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+		return user
+	}
+	addCategory := func(expiryRequired bool) *models.DocumentCategory {
+		category := &models.DocumentCategory{Name: faker.Word() + faker.UUIDDigit(), ReadAbility: auth_constants.ABILITY_READ_PERSONAL_DATA, WriteAbility: auth_constants.ABILITY_WRITE_PERSONAL_DATA, ExpiryRequired: expiryRequired}
+		Expect(mockDB.DB().Create(category).Error).To(Succeed())
+		return category
+	}
+	upload := func(user *models.User, category *models.DocumentCategory, content string, expiresOn *time.Time) (*models.Document, error) {
+		return documentService.UploadDocument(context.Background(), int(user.ID), dtos.UploadDocumentRequest{
+			CategoryID:  category.ID,
+			FileName:    `C:\scans\passport.pdf`,
+			ContentType: "application/pdf",
+			ExpiresOn:   expiresOn,
+			Content:     strings.NewReader(content),
+		})
+	}
+
+	It("should store document with checksum and open it again", func() {
+		user := registerUser()
+		category := addCategory(false)
+
+		document, err := upload(user, category, "%PDF-1.4", nil)
+
+		Expect(err).To(BeNil())
+		Expect(document.FileName).To(Equal("passport.pdf"))
+		Expect(document.Size).To(Equal(int64(8)))
+		hash := sha256.Sum256([]byte("%PDF-1.4"))
+		Expect(document.Checksum).To(Equal(hex.EncodeToString(hash[:])))
+		content, err := documentService.OpenDocument(context.Background(), document)
+		Expect(err).To(BeNil())
+		defer content.Close()
+		Expect(io.ReadAll(content)).To(Equal([]byte("%PDF-1.4")))
+	})
+
+	It("should refuse content which does not match checksum of client", func() {
+		user := registerUser()
+		category := addCategory(false)
+
+		_, err := documentService.UploadDocument(context.Background(), int(user.ID), dtos.UploadDocumentRequest{
+			CategoryID: category.ID,
+			FileName:   "passport.pdf",
+			Checksum:   strings.Repeat("0", 64),
+			Content:    strings.NewReader("%PDF-1.4"),
+		})
+
+		Expect(err).To(MatchError(ErrDocumentChecksum))
+		documents, err := documentService.FindDocuments(int(user.ID))
+		Expect(err).To(BeNil())
+		Expect(documents).To(BeEmpty())
+	})
+
+	It("should ask for expiry date when category requires it", func() {
+		user := registerUser()
+		category := addCategory(true)
+
+		_, err := upload(user, category, "%PDF-1.4", nil)
+
+		Expect(err).To(MatchError(ErrDocumentExpiry))
+	})
+
+	It("should refuse category with unknown ability", func() {
+		Expect(mockDB.DB().Create(&auth_models.Ability{Name: auth_constants.ABILITY_READ_PERSONAL_DATA}).Error).To(Succeed())
+
+		_, err := documentService.CreateCategory(context.Background(), dtos.DocumentCategoryRequest{Name: "Visas", ReadAbility: auth_constants.ABILITY_READ_PERSONAL_DATA, WriteAbility: "write_visas"})
+
+		Expect(err).To(MatchError(ErrDocumentCategoryAbility))
+	})
+
+	It("should remind expiring document once", func() {
+		user := registerUser()
+		category := addCategory(true)
+		soon, later := today.AddDate(0, 0, 5), today.AddDate(0, 0, 60)
+		expiring, err := upload(user, category, "%PDF-1.4", &soon)
+		Expect(err).To(BeNil())
+		_, err = upload(user, category, "%PDF-1.4", &later)
+		Expect(err).To(BeNil())
+		mail := mailer.NewMailer(mockEnv, mockLogger)
+
+		sent, err := SendDocumentExpiryReminders(context.Background(), mockLogger, mockDB, mail, 30)
+		Expect(err).To(BeNil())
+		Expect(sent).To(Equal(1))
+		sent, err = SendDocumentExpiryReminders(context.Background(), mockLogger, mockDB, mail, 30)
+		Expect(err).To(BeNil())
+		Expect(sent).To(Equal(0))
+
+		found, err := documentService.FindDocument(int(user.ID), int(expiring.ID))
+		Expect(err).To(BeNil())
+		Expect(found.ExpiryReminderSentAt).NotTo(BeNil())
+	})
+})
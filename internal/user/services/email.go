@@ -0,0 +1,68 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"net/mail"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidEmail = errors.New("email is invalid")
+	ErrEmailTaken   = errors.New("email is already used by another user")
+)
+
+// parseEmail accepts bare address only, display names like "John <john@example.com>" are stripped
+func parseEmail(email string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return "", ErrInvalidEmail
+	}
+	return address.Address, nil
+}
+
+func checkEmailAvailable(db *gorm.DB, email string) error {
+	var count int64
+	if err := models.ValidScope(db).Where("email = ?", email).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// mailed tokens are random secrets, only their sha256 is stored
+func newMailToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func hashMailToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// abilityHolderEmails are addresses of employed users whose primary role has ability, like HR for reminders
+func abilityHolderEmails(db *gorm.DB, ability string) ([]string, error) {
+	emails := []string{}
+	// not using ValidScope since status column is ambiguous after joining role
+	err := db.Model(&models.User{}).
+		Where("user.status != ? AND user.lifecycle_state != ?", "removed", constants.LIFECYCLE_STATE_TERMINATED).
+		Joins("JOIN role ON role.id = user.role_id AND role.status != ?", "removed").
+		Joins("JOIN role_abilities ON role_abilities.role_id = role.id").
+		Joins("JOIN ability ON ability.id = role_abilities.ability_id").
+		Where("ability.name = ?", ability).
+		Distinct().
+		Pluck("user.email", &emails).Error
+	return emails, err
+}
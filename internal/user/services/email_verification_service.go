@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrEmailUnchanged       = errors.New("new email is the same as current one")
+	// ErrInvalidEmailToken does not tell unknown, used, expired and superseded tokens apart
+	ErrInvalidEmailToken = errors.New("email confirmation link is invalid or expired")
+)
+
+type EmailVerificationServiceInterface interface {
+	SendVerification(ctx context.Context, user *models.User) error
+	RequestEmailChange(ctx context.Context, user *models.User, newEmail string) error
+	ConfirmEmail(ctx context.Context, token string) (*models.User, error)
+	VerificationRequired() bool
+}
+
+type EmailVerificationService struct {
+	logger     *logger.Logger
+	db         *mysql.MySqlStore
+	mailer     *mailer.Mailer
+	ttl        time.Duration
+	confirmURL string
+	// required blocks password login until email is verified
+	required bool
+}
+
+func NewEmailVerificationService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, mailer *mailer.Mailer) EmailVerificationServiceInterface {
+	confirmURL := env.GetEnv("EMAIL_CONFIRM_URL")
+	if confirmURL == "" {
+		confirmURL = constants.EMAIL_VERIFICATION_DEFAULT_CONFIRM_URL
+	}
+
+	return &EmailVerificationService{
+		logger:     logger,
+		db:         db,
+		mailer:     mailer,
+		ttl:        time.Duration(env.GetEnvInt("EMAIL_VERIFICATION_TTL_HOURS", constants.EMAIL_VERIFICATION_DEFAULT_TTL_HOURS)) * time.Hour,
+		confirmURL: confirmURL,
+		required:   env.GetEnvBool("EMAIL_VERIFICATION_REQUIRED", false),
+	}
+}
+
+// SendVerification mails a link confirming current email, earlier links stop working
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := s.issueToken(tx, user.ID, user.Email, constants.EMAIL_PURPOSE_VERIFY)
+		if err != nil {
+			return err
+		}
+		return s.mailer.Send(mailer.Message{
+			To:      []string{user.Email},
+			Subject: "Verify your email address",
+			Body:    fmt.Sprintf("Confirm this is your email address before %s:\n%s\n", time.Now().Add(s.ttl).Format(time.RFC1123), s.confirmLink(token)),
+		})
+	})
+	if err != nil {
+		s.logger.Error("Cannot Send Email Verification", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RequestEmailChange keeps current email until the new one is confirmed, old address is notified
+func (s *EmailVerificationService) RequestEmailChange(ctx context.Context, user *models.User, newEmail string) error {
+	email, err := parseEmail(newEmail)
+	if err != nil {
+		return err
+	}
+	if email == user.Email {
+		return ErrEmailUnchanged
+	}
+	if err := checkEmailAvailable(s.db.DB(), email); err != nil {
+		return err
+	}
+
+	err = s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := models.ValidScope(tx).Where("id = ?", user.ID).Update("pending_email", email).Error; err != nil {
+			return err
+		}
+		token, err := s.issueToken(tx, user.ID, email, constants.EMAIL_PURPOSE_CHANGE)
+		if err != nil {
+			return err
+		}
+
+		err = s.mailer.Send(mailer.Message{
+			To:      []string{email},
+			Subject: "Confirm your new email address",
+			Body:    fmt.Sprintf("Confirm %s as the email address of your account before %s:\n%s\n", email, time.Now().Add(s.ttl).Format(time.RFC1123), s.confirmLink(token)),
+		})
+		if err != nil {
+			return err
+		}
+		return s.mailer.Send(mailer.Message{
+			To:      []string{user.Email},
+			Subject: "Your email address is being changed",
+			Body:    fmt.Sprintf("A change of your account email to %s was requested. It applies only after it is confirmed from the new address, contact HR if it was not you.\n", email),
+		})
+	})
+	if err != nil {
+		s.logger.Error("Cannot Request Email Change", zap.Error(err))
+		return err
+	}
+
+	user.PendingEmail = &email
+	return nil
+}
+
+// ConfirmEmail consumes token, verifying current email or applying the pending one
+func (s *EmailVerificationService) ConfirmEmail(ctx context.Context, token string) (*models.User, error) {
+	var verification *models.EmailVerification
+	err := models.UsableEmailVerificationScope(s.db.DB()).Where("token_hash = ?", hashMailToken(token)).First(&verification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidEmailToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	err = s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := models.UsableEmailVerificationScope(tx).Where("id = ?", verification.ID).Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidEmailToken
+		}
+
+		if err := models.ValidScope(tx).First(&user, verification.UserID).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"email_verified_at": now}
+		switch verification.Purpose {
+		case constants.EMAIL_PURPOSE_VERIFY:
+			// email changed since the link was sent
+			if user.Email != verification.Email {
+				return ErrInvalidEmailToken
+			}
+		case constants.EMAIL_PURPOSE_CHANGE:
+			// a later change request replaced this one
+			if user.PendingEmail == nil || *user.PendingEmail != verification.Email {
+				return ErrInvalidEmailToken
+			}
+			if err := checkEmailAvailable(tx, verification.Email); err != nil {
+				return err
+			}
+			updates["email"] = verification.Email
+			updates["pending_email"] = nil
+		default:
+			return ErrInvalidEmailToken
+		}
+
+		return models.ValidScope(tx).Where("id = ?", user.ID).Updates(updates).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Confirm Email", zap.Error(err))
+		return nil, err
+	}
+
+	if err := models.ValidScope(s.db.DB()).First(&user, verification.UserID).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *EmailVerificationService) VerificationRequired() bool {
+	return s.required
+}
+
+// issueToken replaces unused tokens of the same purpose, so only the latest link works
+func (s *EmailVerificationService) issueToken(tx *gorm.DB, userID uint, email string, purpose string) (string, error) {
+	err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).Delete(&models.EmailVerification{}).Error
+	if err != nil {
+		return "", err
+	}
+
+	token, err := newMailToken()
+	if err != nil {
+		return "", err
+	}
+	verification := &models.EmailVerification{
+		UserID:    userID,
+		Email:     email,
+		Purpose:   purpose,
+		TokenHash: hashMailToken(token),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := tx.Create(&verification).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *EmailVerificationService) confirmLink(token string) string {
+	return s.confirmURL + "?token=" + url.QueryEscape(token)
+}
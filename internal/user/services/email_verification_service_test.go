@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EmailVerificationService", func() {
+	registerUser := func() *models.User {
+		user := &models.User{Name: "John Doe", Email: faker.Email()}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+		return user
+	}
+	issueToken := func(user *models.User, email string, purpose string) string {
+		token := "token-" + faker.UUIDDigit()
+		verification := &models.EmailVerification{
+			UserID:    user.ID,
+			Email:     email,
+			Purpose:   purpose,
+			TokenHash: hashMailToken(token),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		Expect(mockDB.DB().Create(&verification).Error).To(Succeed())
+		return token
+	}
+
+	Describe("SendVerification", func() {
+		It("should keep only the latest verification link", func() {
+			user := registerUser()
+
+			Expect(emailService.SendVerification(context.Background(), user)).To(Succeed())
+			Expect(emailService.SendVerification(context.Background(), user)).To(Succeed())
+
+			var count int64
+			models.UsableEmailVerificationScope(mockDB.DB()).Where("user_id = ?", user.ID).Count(&count)
+			Expect(count).To(Equal(int64(1)))
+		})
+
+		It("should refuse verified email", func() {
+			user := registerUser()
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+
+			Expect(emailService.SendVerification(context.Background(), user)).To(MatchError(ErrEmailAlreadyVerified))
+		})
+	})
+
+	Describe("ConfirmEmail", func() {
+		It("should verify current email once", func() {
+			user := registerUser()
+			token := issueToken(user, user.Email, constants.EMAIL_PURPOSE_VERIFY)
+
+			confirmed, err := emailService.ConfirmEmail(context.Background(), token)
+
+			Expect(err).To(BeNil())
+			Expect(confirmed.EmailVerified()).To(BeTrue())
+			_, err = emailService.ConfirmEmail(context.Background(), token)
+			Expect(err).To(MatchError(ErrInvalidEmailToken))
+		})
+
+		It("should apply pending email only after confirmation", func() {
+			user := registerUser()
+			oldEmail := user.Email
+			newEmail := faker.Email()
+
+			Expect(emailService.RequestEmailChange(context.Background(), user, newEmail)).To(Succeed())
+			var reloaded models.User
+			mockDB.DB().First(&reloaded, user.ID)
+			Expect(reloaded.Email).To(Equal(oldEmail))
+			Expect(*reloaded.PendingEmail).To(Equal(newEmail))
+
+			token := issueToken(user, newEmail, constants.EMAIL_PURPOSE_CHANGE)
+			confirmed, err := emailService.ConfirmEmail(context.Background(), token)
+
+			Expect(err).To(BeNil())
+			Expect(confirmed.Email).To(Equal(newEmail))
+			Expect(confirmed.PendingEmail).To(BeNil())
+			Expect(confirmed.EmailVerified()).To(BeTrue())
+		})
+
+		It("should reject change link replaced by later request", func() {
+			user := registerUser()
+			firstEmail := faker.Email()
+			Expect(emailService.RequestEmailChange(context.Background(), user, firstEmail)).To(Succeed())
+			token := issueToken(user, firstEmail, constants.EMAIL_PURPOSE_CHANGE)
+			Expect(emailService.RequestEmailChange(context.Background(), user, faker.Email())).To(Succeed())
+
+			_, err := emailService.ConfirmEmail(context.Background(), token)
+
+			Expect(err).To(MatchError(ErrInvalidEmailToken))
+		})
+	})
+
+	Describe("RequestEmailChange", func() {
+		It("should reject email of another user", func() {
+			user := registerUser()
+			other := registerUser()
+
+			Expect(emailService.RequestEmailChange(context.Background(), user, other.Email)).To(MatchError(ErrEmailTaken))
+		})
+	})
+})
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"slices"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmploymentEffectiveFrom = errors.New("effectiveFrom is required")
+	ErrEmploymentRecordExists  = errors.New("user already has employment record effective from this date")
+	ErrEmploymentType          = errors.New("employmentType must be full_time, part_time, contractor or intern")
+	ErrEmploymentFTE           = errors.New("fte must be greater than 0 and at most 1")
+	ErrEmploymentSalary        = errors.New("salary must not be negative")
+	ErrEmploymentManager       = errors.New("manager must be another active user")
+	// ErrEmploymentRecordApplied is returned for records which are already in effect
+	ErrEmploymentRecordApplied = errors.New("only scheduled employment records can be cancelled")
+)
+
+type EmploymentServiceInterface interface {
+	FindEmploymentRecords(userID int) ([]models.EmploymentRecord, error)
+	FindEffectiveRecord(userID int, date time.Time) (*models.EmploymentRecord, error)
+	CreateEmploymentRecord(ctx context.Context, userID int, payload dtos.CreateEmploymentRecordRequest) (*models.EmploymentRecord, error)
+	CancelEmploymentRecord(ctx context.Context, userID int, recordID int) error
+	ApplyDueRecords(ctx context.Context) (int, error)
+}
+
+type EmploymentService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	// applyInterval is how often scheduled records are checked, zero leaves it to employment:apply command
+	applyInterval time.Duration
+	stop          chan struct{}
+}
+
+func NewEmploymentService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, lc fx.Lifecycle) EmploymentServiceInterface {
+	service := &EmploymentService{
+		logger:        logger,
+		db:            db,
+		applyInterval: time.Duration(env.GetEnvInt("EMPLOYMENT_APPLY_INTERVAL_MINUTES", constants.EMPLOYMENT_DEFAULT_APPLY_INTERVAL_MINUTES)) * time.Minute,
+		stop:          make(chan struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if service.applyInterval > 0 {
+				go service.applyPeriodically()
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(service.stop)
+			return nil
+		},
+	})
+	return service
+}
+
+// FindEmploymentRecords is timeline of user, scheduled records included
+func (s *EmploymentService) FindEmploymentRecords(userID int) ([]models.EmploymentRecord, error) {
+	var records []models.EmploymentRecord
+	err := s.db.DB().Preload("Department").Preload("Manager").
+		Where("user_id = ?", userID).
+		Order("effective_from ASC").
+		Find(&records).Error
+	if err != nil {
+		s.logger.Error("Cannot Find Employment Records", zap.Error(err))
+		return nil, err
+	}
+	return records, nil
+}
+
+// FindEffectiveRecord returns gorm.ErrRecordNotFound before the first record of user
+func (s *EmploymentService) FindEffectiveRecord(userID int, date time.Time) (*models.EmploymentRecord, error) {
+	var record *models.EmploymentRecord
+	err := models.EffectiveEmploymentScope(s.db.DB(), uint(userID), models.EmploymentDate(date)).
+		Preload("Department").Preload("Manager").
+		First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// CreateEmploymentRecord applies record to user right away unless it starts in the future
+func (s *EmploymentService) CreateEmploymentRecord(ctx context.Context, userID int, payload dtos.CreateEmploymentRecordRequest) (*models.EmploymentRecord, error) {
+	if payload.EffectiveFrom == nil {
+		return nil, ErrEmploymentEffectiveFrom
+	}
+	effectiveFrom := models.EmploymentDate(*payload.EffectiveFrom)
+
+	var record *models.EmploymentRecord
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user *models.User
+		if err := models.ValidScope(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.EmploymentRecord{}).Where("user_id = ? AND effective_from = ?", user.ID, effectiveFrom).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmploymentRecordExists
+		}
+
+		base, err := previousEmployment(tx, user, effectiveFrom)
+		if err != nil {
+			return err
+		}
+		record = base
+		record.EffectiveFrom = effectiveFrom
+		record.Reason = payload.Reason
+		applyEmploymentPayload(record, payload)
+		if err := validateEmployment(tx, record); err != nil {
+			return err
+		}
+
+		if err := tx.Omit("Department", "Manager").Create(&record).Error; err != nil {
+			return err
+		}
+		if effectiveFrom.After(models.EmploymentDate(time.Now())) {
+			return nil
+		}
+		return applyDueEmployment(tx, user.ID)
+	})
+	if err != nil {
+		s.logger.Error("Cannot Create Employment Record", zap.Error(err))
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (s *EmploymentService) CancelEmploymentRecord(ctx context.Context, userID int, recordID int) error {
+	var record *models.EmploymentRecord
+	if err := s.db.DB().Where("user_id = ?", userID).First(&record, recordID).Error; err != nil {
+		return err
+	}
+	if record.AppliedAt != nil || !record.EffectiveFrom.After(models.EmploymentDate(time.Now())) {
+		return ErrEmploymentRecordApplied
+	}
+
+	result := s.db.DB().WithContext(ctx).Where("id = ? AND applied_at IS NULL", record.ID).Delete(&models.EmploymentRecord{})
+	if result.Error != nil {
+		s.logger.Error("Cannot Cancel Employment Record", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEmploymentRecordApplied
+	}
+	return nil
+}
+
+func (s *EmploymentService) ApplyDueRecords(ctx context.Context) (int, error) {
+	return ApplyDueEmploymentRecords(ctx, s.logger, s.db)
+}
+
+// ApplyDueEmploymentRecords brings department and salary of users in line with records which took effect,
+// returns number of users changed. It is run periodically and by employment:apply command
+func ApplyDueEmploymentRecords(ctx context.Context, logger *logger.Logger, db *mysql.MySqlStore) (int, error) {
+	var userIDs []uint
+	err := db.DB().WithContext(ctx).Model(&models.EmploymentRecord{}).
+		Where("applied_at IS NULL AND effective_from <= ?", models.EmploymentDate(time.Now())).
+		Distinct().Pluck("user_id", &userIDs).Error
+	if err != nil {
+		logger.Error("Cannot Find Due Employment Records", zap.Error(err))
+		return 0, err
+	}
+
+	applied := 0
+	for _, userID := range userIDs {
+		err := db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return applyDueEmployment(tx, userID)
+		})
+		if err != nil {
+			logger.Error("Cannot Apply Employment Record", zap.Uint("UserID", userID), zap.Error(err))
+			continue
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+func (s *EmploymentService) applyPeriodically() {
+	ticker := time.NewTicker(s.applyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if applied, err := s.ApplyDueRecords(context.Background()); err == nil && applied > 0 {
+				s.logger.Info("Applied scheduled employment records", zap.Int("Users", applied))
+			}
+		}
+	}
+}
+
+// applyDueEmployment claims unapplied due records first, so concurrent runs change user only once
+func applyDueEmployment(tx *gorm.DB, userID uint) error {
+	today := models.EmploymentDate(time.Now())
+	result := tx.Model(&models.EmploymentRecord{}).
+		Where("user_id = ? AND applied_at IS NULL AND effective_from <= ?", userID, today).
+		Update("applied_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	var record *models.EmploymentRecord
+	if err := models.EffectiveEmploymentScope(tx, userID, today).First(&record).Error; err != nil {
+		return err
+	}
+	var user *models.User
+	err := models.ValidScope(tx).First(&user, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// removed users keep their history but are not counted in departments
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !sameUintPointer(user.DepartmentID, record.DepartmentID) {
+		if err := moveDepartmentEmployee(tx, user.DepartmentID, record.DepartmentID); err != nil {
+			return err
+		}
+	}
+	return models.ValidScope(tx).Where("id = ?", userID).Updates(map[string]interface{}{
+		"department_id": record.DepartmentID,
+		"salary":        record.Salary,
+	}).Error
+}
+
+// recordCurrentEmployment keeps history of department and salary changed on user directly, a record of today is amended.
+// History of users without records starts at their join date
+func recordCurrentEmployment(tx *gorm.DB, userID uint) error {
+	var user *models.User
+	if err := models.ValidScope(tx).First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	today := models.EmploymentDate(time.Now())
+	record, err := previousEmployment(tx, user, today.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	if record.ID != 0 && sameUintPointer(record.DepartmentID, user.DepartmentID) && sameFloatPointer(record.Salary, user.Salary) {
+		return nil
+	}
+
+	record.DepartmentID = user.DepartmentID
+	record.Salary = user.Salary
+	now := time.Now()
+	record.AppliedAt = &now
+	if record.ID != 0 && record.EffectiveFrom.Equal(today) {
+		return tx.Omit("Department", "Manager").Save(&record).Error
+	}
+	if record.ID != 0 {
+		record.ID = 0
+		record.EffectiveFrom = today
+		record.Reason = ""
+	}
+	return tx.Omit("Department", "Manager").Create(&record).Error
+}
+
+// previousEmployment copies record in effect the day before date, users without history start from their current data
+func previousEmployment(tx *gorm.DB, user *models.User, date time.Time) (*models.EmploymentRecord, error) {
+	var previous *models.EmploymentRecord
+	err := models.EffectiveEmploymentScope(tx, user.ID, date.AddDate(0, 0, -1)).First(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.EmploymentRecord{
+			UserID:         user.ID,
+			EffectiveFrom:  models.EmploymentDate(user.JoinDate),
+			EmploymentType: constants.EMPLOYMENT_TYPE_FULL_TIME,
+			FTE:            1,
+			Salary:         user.Salary,
+			DepartmentID:   user.DepartmentID,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := *previous
+	return &record, nil
+}
+
+func applyEmploymentPayload(record *models.EmploymentRecord, payload dtos.CreateEmploymentRecordRequest) {
+	record.ID = 0
+	record.AppliedAt = nil
+	if payload.JobTitle != nil {
+		record.JobTitle = *payload.JobTitle
+	}
+	if payload.Grade != nil {
+		record.Grade = *payload.Grade
+	}
+	if payload.EmploymentType != nil {
+		record.EmploymentType = *payload.EmploymentType
+	}
+	if payload.FTE != nil {
+		record.FTE = *payload.FTE
+	}
+	if payload.Salary != nil {
+		record.Salary = payload.Salary
+	}
+	if payload.DepartmentID != nil {
+		record.DepartmentID = payload.DepartmentID
+	}
+	if payload.ManagerID != nil {
+		record.ManagerID = payload.ManagerID
+	}
+}
+
+func validateEmployment(tx *gorm.DB, record *models.EmploymentRecord) error {
+	if !slices.Contains(constants.EMPLOYMENT_TYPES, record.EmploymentType) {
+		return ErrEmploymentType
+	}
+	if record.FTE <= 0 || record.FTE > 1 {
+		return ErrEmploymentFTE
+	}
+	if record.Salary != nil && *record.Salary < 0 {
+		return ErrEmploymentSalary
+	}
+	if record.DepartmentID != nil {
+		if err := department_models.ValidScope(tx).First(&department_models.Department{}, *record.DepartmentID).Error; err != nil {
+			return err
+		}
+	}
+	if record.ManagerID != nil {
+		if *record.ManagerID == record.UserID {
+			return ErrEmploymentManager
+		}
+		err := models.ValidScope(tx).First(&models.User{}, *record.ManagerID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEmploymentManager
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func moveDepartmentEmployee(tx *gorm.DB, from *uint, to *uint) error {
+	if from != nil {
+		err := tx.Model(&department_models.Department{}).Where("id = ?", *from).Update("employ_count", gorm.Expr("employ_count - ?", 1)).Error
+		if err != nil {
+			return err
+		}
+	}
+	if to != nil {
+		return tx.Model(&department_models.Department{}).Where("id = ?", *to).Update("employ_count", gorm.Expr("employ_count + ?", 1)).Error
+	}
+	return nil
+}
+
+func sameUintPointer(a *uint, b *uint) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func sameFloatPointer(a *float64, b *float64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
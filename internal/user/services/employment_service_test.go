@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EmploymentService", func() {
+	var user *models.User
+	today := models.EmploymentDate(time.Now())
+
+	BeforeEach(func() {
+		salary := 4000.0
+		user = &models.User{Name: "John Doe", Email: faker.Email(), Salary: &salary}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+	})
+
+	It("should start history at join date of new user", func() {
+		records, err := employmentService.FindEmploymentRecords(int(user.ID))
+
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(1))
+		Expect(*records[0].Salary).To(Equal(4000.0))
+		Expect(records[0].AppliedAt).NotTo(BeNil())
+	})
+
+	It("should keep scheduled change until it takes effect", func() {
+		department := &department_models.Department{Name: faker.Word()}
+		Expect(mockDB.DB().Create(department).Error).To(BeNil())
+		effectiveFrom := today.AddDate(0, 0, 7)
+		salary := 4500.0
+		title := "Senior Engineer"
+
+		record, err := employmentService.CreateEmploymentRecord(context.Background(), int(user.ID), dtos.CreateEmploymentRecordRequest{
+			EffectiveFrom: &effectiveFrom,
+			JobTitle:      &title,
+			Salary:        &salary,
+			DepartmentID:  &department.ID,
+			Reason:        "promotion",
+		})
+		Expect(err).To(BeNil())
+		Expect(record.AppliedAt).To(BeNil())
+
+		applied, err := employmentService.ApplyDueRecords(context.Background())
+		Expect(err).To(BeNil())
+		Expect(applied).To(BeZero())
+
+		found, _ := userService.FindUserByID(int(user.ID))
+		Expect(*found.Salary).To(Equal(4000.0))
+		Expect(found.DepartmentID).To(BeNil())
+
+		effective, err := employmentService.FindEffectiveRecord(int(user.ID), effectiveFrom)
+		Expect(err).To(BeNil())
+		Expect(effective.JobTitle).To(Equal(title))
+
+		Expect(employmentService.CancelEmploymentRecord(context.Background(), int(user.ID), int(record.ID))).To(Succeed())
+		_, err = employmentService.FindEffectiveRecord(int(user.ID), effectiveFrom)
+		Expect(err).To(BeNil())
+	})
+
+	It("should apply change effective today right away", func() {
+		salary := 4800.0
+
+		record, err := employmentService.CreateEmploymentRecord(context.Background(), int(user.ID), dtos.CreateEmploymentRecordRequest{EffectiveFrom: &today, Salary: &salary})
+		Expect(err).To(BeNil())
+
+		found, _ := userService.FindUserByID(int(user.ID))
+		Expect(*found.Salary).To(Equal(4800.0))
+		err = employmentService.CancelEmploymentRecord(context.Background(), int(user.ID), int(record.ID))
+		Expect(err).To(MatchError(ErrEmploymentRecordApplied))
+	})
+
+	It("should reject second record on the same date", func() {
+		effectiveFrom := today.AddDate(0, 1, 0)
+		payload := dtos.CreateEmploymentRecordRequest{EffectiveFrom: &effectiveFrom}
+		_, err := employmentService.CreateEmploymentRecord(context.Background(), int(user.ID), payload)
+		Expect(err).To(BeNil())
+
+		_, err = employmentService.CreateEmploymentRecord(context.Background(), int(user.ID), payload)
+
+		Expect(err).To(MatchError(ErrEmploymentRecordExists))
+	})
+
+	It("should reject user as own manager", func() {
+		effectiveFrom := today.AddDate(0, 1, 0)
+
+		_, err := employmentService.CreateEmploymentRecord(context.Background(), int(user.ID), dtos.CreateEmploymentRecordRequest{EffectiveFrom: &effectiveFrom, ManagerID: &user.ID})
+
+		Expect(err).To(MatchError(ErrEmploymentManager))
+	})
+})
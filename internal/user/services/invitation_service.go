@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
+	"hr-system-go/app/plugins/mysql"
+	auth_models "hr-system-go/internal/auth/models"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_services "hr-system-go/internal/customfield/services"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvitationStartDate = errors.New("startDate is required")
+	ErrInvitationStatus    = errors.New("status must be pending, accepted, revoked or expired")
+	ErrInvitationName      = errors.New("name is required")
+	// ErrInvalidInvitation does not tell unknown, used and expired tokens apart
+	ErrInvalidInvitation = errors.New("invitation is invalid or expired")
+	// ErrInvitationNotSent leaves invitation revoked, nobody has its token
+	ErrInvitationNotSent = errors.New("invitation email could not be sent, invite again")
+)
+
+type InvitationServiceInterface interface {
+	CreateInvitation(ctx context.Context, invitedByID uint, payload dtos.CreateInvitationRequest) (*models.Invitation, error)
+	FindInvitations(status string, pagination *utils.Pagination) ([]models.Invitation, int64, error)
+	RevokeInvitation(ctx context.Context, invitationID int) error
+	AcceptInvitation(ctx context.Context, payload dtos.AcceptInvitationRequest) (*models.User, error)
+	OpenRegistrationEnabled() bool
+}
+
+type InvitationService struct {
+	logger    *logger.Logger
+	db        *mysql.MySqlStore
+	mailer    *mailer.Mailer
+	users     *UserService
+	ttl       time.Duration
+	acceptURL string
+	// openRegistration keeps POST /api/register available to anyone
+	openRegistration bool
+}
+
+func NewInvitationService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, mailer *mailer.Mailer) InvitationServiceInterface {
+	acceptURL := env.GetEnv("INVITATION_ACCEPT_URL")
+	if acceptURL == "" {
+		acceptURL = constants.INVITATION_DEFAULT_ACCEPT_URL
+	}
+
+	return &InvitationService{
+		logger:           logger,
+		db:               db,
+		mailer:           mailer,
+		users:            newUserService(logger, env, db),
+		ttl:              time.Duration(env.GetEnvInt("INVITATION_TTL_HOURS", constants.INVITATION_DEFAULT_TTL_HOURS)) * time.Hour,
+		acceptURL:        acceptURL,
+		openRegistration: env.GetEnvBool("REGISTRATION_OPEN", false),
+	}
+}
+
+// CreateInvitation replaces pending invitations of the same email, token is only sent by mail
+func (s *InvitationService) CreateInvitation(ctx context.Context, invitedByID uint, payload dtos.CreateInvitationRequest) (*models.Invitation, error) {
+	email, err := parseEmail(payload.Email)
+	if err != nil {
+		return nil, err
+	}
+	if payload.StartDate == nil {
+		return nil, ErrInvitationStartDate
+	}
+	if err := s.validateAssignment(payload.RoleID, payload.DepartmentID); err != nil {
+		return nil, err
+	}
+	if err := checkEmailAvailable(s.db.DB(), email); err != nil {
+		return nil, err
+	}
+	// required fields may still be given by the employee on accepting
+	if len(payload.CustomFields) > 0 {
+		fields, err := customfield_services.FindCustomFields(s.db.DB(), customfield_constants.CUSTOM_FIELD_ENTITY_USER)
+		if err != nil {
+			return nil, err
+		}
+		if err := customfield_services.CheckCustomFieldValues(fields, payload.CustomFields, false); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := newMailToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.Invitation{
+		Email:        email,
+		TokenHash:    hashMailToken(token),
+		StartDate:    *payload.StartDate,
+		ExpiresAt:    time.Now().Add(s.ttl),
+		Status:       constants.INVITATION_STATUS_PENDING,
+		InvitedByID:  invitedByID,
+		RoleID:       payload.RoleID,
+		DepartmentID: payload.DepartmentID,
+		CustomFields: payload.CustomFields,
+	}
+	err = s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Invitation{}).
+			Where("email = ? AND status = ?", invitation.Email, constants.INVITATION_STATUS_PENDING).
+			Update("status", constants.INVITATION_STATUS_REVOKED).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&invitation).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Create Invitation", zap.Error(err))
+		return nil, err
+	}
+
+	// mail is sent after commit, so rows are not locked while mail server answers and no mail goes out for rolled back invitation
+	if err := s.mailer.Send(s.invitationMessage(invitation, token)); err != nil {
+		s.logger.Error("Cannot Send Invitation", zap.Error(err))
+		if err := s.db.DB().WithContext(ctx).Model(invitation).Update("status", constants.INVITATION_STATUS_REVOKED).Error; err != nil {
+			s.logger.Error("Cannot Revoke Unsent Invitation", zap.Error(err))
+		}
+		return nil, ErrInvitationNotSent
+	}
+
+	return s.findInvitationByID(invitation.ID)
+}
+
+var invitationSortFields = utils.SortFields{
+	"email":     "email",
+	"startDate": "start_date",
+	"expiresAt": "expires_at",
+	"createdAt": "created_at",
+}
+
+func (s *InvitationService) FindInvitations(status string, pagination *utils.Pagination) ([]models.Invitation, int64, error) {
+	scope, err := s.statusScope(status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var invitations []models.Invitation
+	var totalCount int64 = 0
+
+	if err := scope(s.db.DB()).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err = pagination.FindPage(scope(s.db.DB()).Preload("Role").Preload("Department"), invitationSortFields, &invitations)
+	if err != nil {
+		s.logger.Error("Cannot Find Invitations", zap.Error(err))
+		return nil, 0, err
+	}
+	return invitations, totalCount, nil
+}
+
+func (s *InvitationService) RevokeInvitation(ctx context.Context, invitationID int) error {
+	result := s.db.DB().WithContext(ctx).Model(&models.Invitation{}).
+		Where("id = ? AND status = ?", invitationID, constants.INVITATION_STATUS_PENDING).
+		Update("status", constants.INVITATION_STATUS_REVOKED)
+	if result.Error != nil {
+		s.logger.Error("Cannot Revoke Invitation", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation creates the invited user, token is consumed in the same transaction so it works only once
+func (s *InvitationService) AcceptInvitation(ctx context.Context, payload dtos.AcceptInvitationRequest) (*models.User, error) {
+	if strings.TrimSpace(payload.Name) == "" {
+		return nil, ErrInvitationName
+	}
+
+	var invitation *models.Invitation
+	err := models.PendingInvitationScope(s.db.DB()).Where("token_hash = ?", hashMailToken(payload.Token)).First(&invitation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// token was mailed to the invited address, so accepting it proves the mailbox
+	now := time.Now()
+	user := &models.User{
+		Name:            strings.TrimSpace(payload.Name),
+		Email:           invitation.Email,
+		DateOfBirth:     payload.DateOfBirth,
+		RoleID:          invitation.RoleID,
+		DepartmentID:    invitation.DepartmentID,
+		JoinDate:        invitation.StartDate,
+		EmailVerifiedAt: &now,
+	}
+	customFields := map[string]interface{}{}
+	for key, value := range invitation.CustomFields {
+		customFields[key] = value
+	}
+	for key, value := range payload.CustomFields {
+		customFields[key] = value
+	}
+	err = s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkEmailAvailable(tx, invitation.Email); err != nil {
+			return err
+		}
+
+		result := models.PendingInvitationScope(tx).Where("id = ?", invitation.ID).Updates(map[string]interface{}{
+			"status":      constants.INVITATION_STATUS_ACCEPTED,
+			"accepted_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidInvitation
+		}
+
+		if err := s.users.createUser(tx, user, payload.Password, customFields); err != nil {
+			return err
+		}
+		return tx.Model(&models.Invitation{}).Where("id = ?", invitation.ID).Update("user_id", user.ID).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Accept Invitation", zap.Error(err))
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *InvitationService) OpenRegistrationEnabled() bool {
+	return s.openRegistration
+}
+
+func (s *InvitationService) findInvitationByID(invitationID uint) (*models.Invitation, error) {
+	var invitation *models.Invitation
+	if err := s.db.DB().Preload("Role").Preload("Department").First(&invitation, invitationID).Error; err != nil {
+		s.logger.Error("Cannot Find Invitation", zap.Error(err))
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *InvitationService) statusScope(status string) (func(db *gorm.DB) *gorm.DB, error) {
+	switch status {
+	case "":
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.Invitation{})
+		}, nil
+	case constants.INVITATION_STATUS_PENDING:
+		return models.PendingInvitationScope, nil
+	case constants.INVITATION_STATUS_EXPIRED:
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.Invitation{}).
+				Where("status = ?", constants.INVITATION_STATUS_PENDING).
+				Where("expires_at <= ?", time.Now())
+		}, nil
+	case constants.INVITATION_STATUS_ACCEPTED, constants.INVITATION_STATUS_REVOKED:
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.Invitation{}).Where("status = ?", status)
+		}, nil
+	default:
+		return nil, ErrInvitationStatus
+	}
+}
+
+func (s *InvitationService) validateAssignment(roleID *uint, departmentID *uint) error {
+	if roleID != nil {
+		var role *auth_models.Role
+		if err := auth_models.ValidScope(s.db.DB()).First(&role, *roleID).Error; err != nil {
+			return err
+		}
+	}
+	if departmentID != nil {
+		var department *department_models.Department
+		if err := department_models.ValidScope(s.db.DB()).First(&department, *departmentID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *InvitationService) invitationMessage(invitation *models.Invitation, token string) mailer.Message {
+	link := s.acceptURL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf(
+		"You are invited to join the company, your first day is %s.\n\nSet your password and profile here before %s:\n%s\n",
+		invitation.StartDate.Format("2006-01-02"),
+		invitation.ExpiresAt.Format(time.RFC1123),
+		link,
+	)
+	return mailer.Message{
+		To:      []string{invitation.Email},
+		Subject: "Your HR System invitation",
+		Body:    body,
+	}
+}
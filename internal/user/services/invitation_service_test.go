@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InvitationService", func() {
+	startDate := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Second)
+
+	createInvitation := func(email string, token string, expiresAt time.Time) *models.Invitation {
+		invitation := &models.Invitation{
+			Email:       email,
+			TokenHash:   hashMailToken(token),
+			StartDate:   startDate,
+			ExpiresAt:   expiresAt,
+			Status:      constants.INVITATION_STATUS_PENDING,
+			InvitedByID: 1,
+		}
+		Expect(mockDB.DB().Create(&invitation).Error).To(Succeed())
+		return invitation
+	}
+
+	Describe("CreateInvitation", func() {
+		It("should create pending invitation and replace earlier one", func() {
+			email := faker.Email()
+			payload := dtos.CreateInvitationRequest{Email: email, StartDate: &startDate}
+
+			first, err := invitationService.CreateInvitation(context.Background(), 1, payload)
+			Expect(err).To(BeNil())
+			second, err := invitationService.CreateInvitation(context.Background(), 1, payload)
+			Expect(err).To(BeNil())
+
+			var reloaded models.Invitation
+			mockDB.DB().First(&reloaded, first.ID)
+			Expect(reloaded.Status).To(Equal(constants.INVITATION_STATUS_REVOKED))
+			Expect(second.Status).To(Equal(constants.INVITATION_STATUS_PENDING))
+			Expect(second.ExpiresAt).To(BeTemporally(">", time.Now()))
+		})
+
+		It("should reject email of existing user", func() {
+			user := &models.User{Name: "John Doe", Email: faker.Email()}
+			Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+
+			_, err := invitationService.CreateInvitation(context.Background(), 1, dtos.CreateInvitationRequest{Email: user.Email, StartDate: &startDate})
+
+			Expect(err).To(MatchError(ErrEmailTaken))
+		})
+
+		It("should require start date", func() {
+			_, err := invitationService.CreateInvitation(context.Background(), 1, dtos.CreateInvitationRequest{Email: faker.Email()})
+
+			Expect(err).To(MatchError(ErrInvitationStartDate))
+		})
+	})
+
+	Describe("AcceptInvitation", func() {
+		It("should create user with start date as join date only once", func() {
+			token := "token-" + faker.UUIDDigit()
+			invitation := createInvitation(faker.Email(), token, time.Now().Add(time.Hour))
+			dateOfBirth := time.Date(1998, 4, 12, 0, 0, 0, 0, time.UTC)
+			payload := dtos.AcceptInvitationRequest{Token: token, Name: "New Hire", DateOfBirth: &dateOfBirth, Password: "Sunflower2024"}
+
+			user, err := invitationService.AcceptInvitation(context.Background(), payload)
+
+			Expect(err).To(BeNil())
+			Expect(user.Email).To(Equal(invitation.Email))
+			Expect(user.JoinDate).To(BeTemporally("~", startDate, time.Second))
+
+			var reloaded models.Invitation
+			mockDB.DB().First(&reloaded, invitation.ID)
+			Expect(reloaded.Status).To(Equal(constants.INVITATION_STATUS_ACCEPTED))
+			Expect(*reloaded.UserID).To(Equal(user.ID))
+
+			_, err = invitationService.AcceptInvitation(context.Background(), payload)
+			Expect(err).To(MatchError(ErrInvalidInvitation))
+		})
+
+		It("should reject expired invitation", func() {
+			token := "token-" + faker.UUIDDigit()
+			createInvitation(faker.Email(), token, time.Now().Add(-time.Hour))
+
+			_, err := invitationService.AcceptInvitation(context.Background(), dtos.AcceptInvitationRequest{Token: token, Name: "Late Hire", Password: "Sunflower2024"})
+
+			Expect(err).To(MatchError(ErrInvalidInvitation))
+		})
+
+		It("should keep invitation pending when password violates policy", func() {
+			token := "token-" + faker.UUIDDigit()
+			invitation := createInvitation(faker.Email(), token, time.Now().Add(time.Hour))
+
+			_, err := invitationService.AcceptInvitation(context.Background(), dtos.AcceptInvitationRequest{Token: token, Name: "New Hire", Password: "short"})
+
+			var policyErr *PasswordPolicyError
+			Expect(err).To(BeAssignableToTypeOf(policyErr))
+			var reloaded models.Invitation
+			mockDB.DB().First(&reloaded, invitation.ID)
+			Expect(reloaded.Status).To(Equal(constants.INVITATION_STATUS_PENDING))
+		})
+	})
+
+	Describe("RevokeInvitation", func() {
+		It("should revoke pending invitation once", func() {
+			invitation := createInvitation(faker.Email(), "token-"+faker.UUIDDigit(), time.Now().Add(time.Hour))
+
+			Expect(invitationService.RevokeInvitation(context.Background(), int(invitation.ID))).To(Succeed())
+			Expect(invitationService.RevokeInvitation(context.Background(), int(invitation.ID))).NotTo(Succeed())
+		})
+	})
+})
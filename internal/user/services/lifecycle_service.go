@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLifecycleTransition = errors.New("user cannot be moved to this lifecycle state")
+	ErrProbationEndDate    = errors.New("probationEndDate must not be before join date")
+	ErrTerminationDate     = errors.New("terminationDate is required and must not be before join date or notice date")
+	ErrTerminationReason   = errors.New("reason must be resignation, dismissal, end_of_contract, retirement, mutual_agreement, failed_probation or other")
+	ErrNoticePeriod        = errors.New("noticePeriodDays must not be negative")
+	ErrTerminationExists   = errors.New("user is already on notice")
+	ErrRehireDate          = errors.New("joinDate is required and must be after last termination date")
+)
+
+type LifecycleServiceInterface interface {
+	FindLifecycle(userID int) (*models.User, *models.Termination, error)
+	ChangeLifecycleState(ctx context.Context, userID int, payload dtos.ChangeLifecycleStateRequest) (*models.User, error)
+	TerminateUser(ctx context.Context, recordedByID uint, userID int, payload dtos.TerminateUserRequest) (*models.Termination, error)
+	WithdrawTermination(ctx context.Context, userID int) error
+	RehireUser(ctx context.Context, userID int, payload dtos.RehireUserRequest) (*models.User, error)
+	ApplyDueLifecycle(ctx context.Context) (int, error)
+}
+
+type LifecycleService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	// applyInterval is how often hire, probation and termination dates are checked, zero leaves it to lifecycle:apply command
+	applyInterval time.Duration
+	stop          chan struct{}
+}
+
+func NewLifecycleService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, lc fx.Lifecycle) LifecycleServiceInterface {
+	service := &LifecycleService{
+		logger:        logger,
+		db:            db,
+		applyInterval: time.Duration(env.GetEnvInt("LIFECYCLE_APPLY_INTERVAL_MINUTES", constants.LIFECYCLE_DEFAULT_APPLY_INTERVAL_MINUTES)) * time.Minute,
+		stop:          make(chan struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if service.applyInterval > 0 {
+				go service.applyPeriodically()
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(service.stop)
+			return nil
+		},
+	})
+	return service
+}
+
+// FindLifecycle returns termination only while user is on notice or terminated
+func (s *LifecycleService) FindLifecycle(userID int) (*models.User, *models.Termination, error) {
+	var user *models.User
+	if err := models.ValidScope(s.db.DB()).First(&user, userID).Error; err != nil {
+		return nil, nil, err
+	}
+	if user.LifecycleState != constants.LIFECYCLE_STATE_ON_NOTICE && !user.Terminated() {
+		return user, nil, nil
+	}
+
+	var termination *models.Termination
+	if err := models.CurrentTerminationScope(s.db.DB(), user.ID).First(&termination).Error; err != nil {
+		s.logger.Error("Cannot Find Termination", zap.Error(err))
+		return nil, nil, err
+	}
+	return user, termination, nil
+}
+
+// ChangeLifecycleState starts employment or ends probation ahead of the dates
+func (s *LifecycleService) ChangeLifecycleState(ctx context.Context, userID int, payload dtos.ChangeLifecycleStateRequest) (*models.User, error) {
+	var user *models.User
+	if err := models.ValidScope(s.db.DB()).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if !slices.Contains(constants.LIFECYCLE_TRANSITIONS[user.LifecycleState], payload.State) {
+		return nil, ErrLifecycleTransition
+	}
+
+	updates := map[string]interface{}{"lifecycle_state": payload.State}
+	if payload.ProbationEndDate != nil {
+		probationEndDate := models.EmploymentDate(*payload.ProbationEndDate)
+		if probationEndDate.Before(models.EmploymentDate(user.JoinDate)) {
+			return nil, ErrProbationEndDate
+		}
+		updates["probation_end_date"] = probationEndDate
+	}
+
+	result := models.ValidScope(s.db.DB().WithContext(ctx)).
+		Where("id = ? AND lifecycle_state = ?", user.ID, user.LifecycleState).
+		Updates(updates)
+	if result.Error != nil {
+		s.logger.Error("Cannot Change Lifecycle State", zap.Error(result.Error))
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrLifecycleTransition
+	}
+
+	if err := models.ValidScope(s.db.DB()).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// TerminateUser puts user on notice and starts offboarding, terminations dated in the past take effect right away
+func (s *LifecycleService) TerminateUser(ctx context.Context, recordedByID uint, userID int, payload dtos.TerminateUserRequest) (*models.Termination, error) {
+	var termination *models.Termination
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		termination, err = terminateUser(tx, recordedByID, userID, payload)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Cannot Terminate User", zap.Error(err))
+		return nil, err
+	}
+
+	return termination, nil
+}
+
+// WithdrawTermination returns user on notice to their previous state and cancels offboarding
+func (s *LifecycleService) WithdrawTermination(ctx context.Context, userID int) error {
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user *models.User
+		if err := models.ValidScope(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.LifecycleState != constants.LIFECYCLE_STATE_ON_NOTICE {
+			return ErrLifecycleTransition
+		}
+
+		var termination *models.Termination
+		if err := models.CurrentTerminationScope(tx, user.ID).Where("applied_at IS NULL").First(&termination).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Termination{}).
+			Where("id = ? AND applied_at IS NULL AND withdrawn_at IS NULL", termination.ID).
+			Update("withdrawn_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLifecycleTransition
+		}
+
+		err := models.ValidScope(tx).Where("id = ?", user.ID).Update("lifecycle_state", termination.PreviousState).Error
+		if err != nil {
+			return err
+		}
+		return cancelChecklists(tx, user.ID, constants.CHECKLIST_KIND_OFFBOARDING)
+	})
+	if err != nil {
+		s.logger.Error("Cannot Withdraw Termination", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RehireUser brings terminated user back from the new join date and starts onboarding again
+func (s *LifecycleService) RehireUser(ctx context.Context, userID int, payload dtos.RehireUserRequest) (*models.User, error) {
+	if payload.JoinDate == nil {
+		return nil, ErrRehireDate
+	}
+	joinDate := models.EmploymentDate(*payload.JoinDate)
+
+	var user *models.User
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := models.ValidScope(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+		if !user.Terminated() {
+			return ErrLifecycleTransition
+		}
+
+		var termination *models.Termination
+		if err := models.CurrentTerminationScope(tx, user.ID).First(&termination).Error; err != nil {
+			return err
+		}
+		if !joinDate.After(termination.TerminationDate) {
+			return ErrRehireDate
+		}
+		var probationEndDate *time.Time
+		if payload.ProbationEndDate != nil {
+			date := models.EmploymentDate(*payload.ProbationEndDate)
+			if date.Before(joinDate) {
+				return ErrProbationEndDate
+			}
+			probationEndDate = &date
+		}
+
+		result := models.ValidScope(tx).
+			Where("id = ? AND lifecycle_state = ?", user.ID, constants.LIFECYCLE_STATE_TERMINATED).
+			Updates(map[string]interface{}{
+				"lifecycle_state":    constants.LIFECYCLE_STATE_REHIRED,
+				"join_date":          joinDate,
+				"probation_end_date": probationEndDate,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLifecycleTransition
+		}
+
+		user.JoinDate = joinDate
+		if err := startChecklists(tx, user, constants.CHECKLIST_KIND_ONBOARDING, joinDate); err != nil {
+			return err
+		}
+		_, err := startDueEmployment(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Cannot Rehire User", zap.Error(err))
+		return nil, err
+	}
+
+	if err := models.ValidScope(s.db.DB()).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *LifecycleService) ApplyDueLifecycle(ctx context.Context) (int, error) {
+	return ApplyDueLifecycle(ctx, s.logger, s.db)
+}
+
+// ApplyDueLifecycle starts employment on hire date, ends probation and terminates users after
+// their last working day, returns number of users changed. It is run periodically and by lifecycle:apply command
+func ApplyDueLifecycle(ctx context.Context, logger *logger.Logger, db *mysql.MySqlStore) (int, error) {
+	changed, err := startDueEmployment(db.DB().WithContext(ctx), 0)
+	if err != nil {
+		logger.Error("Cannot Start Employment", zap.Error(err))
+		return 0, err
+	}
+
+	var terminations []models.Termination
+	err = db.DB().WithContext(ctx).
+		Where("applied_at IS NULL AND withdrawn_at IS NULL AND termination_date < ?", models.EmploymentDate(time.Now())).
+		Find(&terminations).Error
+	if err != nil {
+		logger.Error("Cannot Find Due Terminations", zap.Error(err))
+		return changed, err
+	}
+
+	for i := range terminations {
+		err := db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return applyTermination(tx, &terminations[i])
+		})
+		if err != nil {
+			logger.Error("Cannot Apply Termination", zap.Uint("UserID", terminations[i].UserID), zap.Error(err))
+			continue
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+func (s *LifecycleService) applyPeriodically() {
+	ticker := time.NewTicker(s.applyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if changed, err := s.ApplyDueLifecycle(context.Background()); err == nil && changed > 0 {
+				s.logger.Info("Applied lifecycle changes", zap.Int("Users", changed))
+			}
+		}
+	}
+}
+
+// initialLifecycleState of new user, users hired for a later date wait in pre-hire
+func initialLifecycleState(user *models.User) string {
+	today := models.EmploymentDate(time.Now())
+	switch {
+	case models.EmploymentDate(user.JoinDate).After(today):
+		return constants.LIFECYCLE_STATE_PRE_HIRE
+	case user.ProbationEndDate != nil && !user.ProbationEndDate.Before(today):
+		return constants.LIFECYCLE_STATE_PROBATION
+	default:
+		return constants.LIFECYCLE_STATE_ACTIVE
+	}
+}
+
+// startDueEmployment moves users past their hire date out of pre-hire, and users past probation end to active.
+// Zero userID applies it to all users
+func startDueEmployment(tx *gorm.DB, userID uint) (int, error) {
+	today := models.EmploymentDate(time.Now())
+	scope := func() *gorm.DB {
+		query := models.ValidScope(tx.Session(&gorm.Session{NewDB: true}))
+		if userID != 0 {
+			query = query.Where("id = ?", userID)
+		}
+		return query
+	}
+	waiting := []string{constants.LIFECYCLE_STATE_PRE_HIRE, constants.LIFECYCLE_STATE_REHIRED}
+
+	changed := int64(0)
+	result := scope().
+		Where("lifecycle_state IN ? AND join_date < ? AND probation_end_date >= ?", waiting, today.AddDate(0, 0, 1), today).
+		Update("lifecycle_state", constants.LIFECYCLE_STATE_PROBATION)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	changed += result.RowsAffected
+
+	result = scope().
+		Where("lifecycle_state IN ? AND join_date < ?", waiting, today.AddDate(0, 0, 1)).
+		Update("lifecycle_state", constants.LIFECYCLE_STATE_ACTIVE)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	changed += result.RowsAffected
+
+	// probation end date is the last day of probation
+	result = scope().
+		Where("lifecycle_state = ? AND probation_end_date < ?", constants.LIFECYCLE_STATE_PROBATION, today).
+		Update("lifecycle_state", constants.LIFECYCLE_STATE_ACTIVE)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(changed + result.RowsAffected), nil
+}
+
+// terminateUser records termination within tx, shared by termination and probation decisions.
+// The last admin cannot be put on notice
+func terminateUser(tx *gorm.DB, recordedByID uint, userID int, payload dtos.TerminateUserRequest) (*models.Termination, error) {
+	var termination *models.Termination
+	err := auth_services.GuardLastAdmin(tx, func(tx *gorm.DB) error {
+		var err error
+		termination, err = recordTermination(tx, recordedByID, userID, payload)
+		return err
+	})
+	return termination, err
+}
+
+func recordTermination(tx *gorm.DB, recordedByID uint, userID int, payload dtos.TerminateUserRequest) (*models.Termination, error) {
+	if !slices.Contains(constants.TERMINATION_REASONS, payload.Reason) {
+		return nil, ErrTerminationReason
+	}
+	if payload.NoticePeriodDays < 0 {
+		return nil, ErrNoticePeriod
+	}
+	if payload.TerminationDate == nil {
+		return nil, ErrTerminationDate
+	}
+	today := models.EmploymentDate(time.Now())
+	noticeDate := today
+	if payload.NoticeDate != nil {
+		noticeDate = models.EmploymentDate(*payload.NoticeDate)
+	}
+	terminationDate := models.EmploymentDate(*payload.TerminationDate)
+	if terminationDate.Before(noticeDate) {
+		return nil, ErrTerminationDate
+	}
+
+	var user *models.User
+	if err := models.ValidScope(tx).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	switch user.LifecycleState {
+	case constants.LIFECYCLE_STATE_ON_NOTICE:
+		return nil, ErrTerminationExists
+	case constants.LIFECYCLE_STATE_TERMINATED:
+		return nil, ErrLifecycleTransition
+	}
+	// offers can be withdrawn before the first working day
+	if terminationDate.Before(models.EmploymentDate(user.JoinDate)) && user.LifecycleState != constants.LIFECYCLE_STATE_PRE_HIRE {
+		return nil, ErrTerminationDate
+	}
+
+	termination := &models.Termination{
+		UserID:           user.ID,
+		NoticeDate:       noticeDate,
+		TerminationDate:  terminationDate,
+		NoticePeriodDays: payload.NoticePeriodDays,
+		Reason:           payload.Reason,
+		Note:             strings.TrimSpace(payload.Note),
+		RecordedByID:     recordedByID,
+		PreviousState:    user.LifecycleState,
+	}
+	if err := tx.Create(&termination).Error; err != nil {
+		return nil, err
+	}
+	result := models.ValidScope(tx).
+		Where("id = ? AND lifecycle_state = ?", user.ID, user.LifecycleState).
+		Update("lifecycle_state", constants.LIFECYCLE_STATE_ON_NOTICE)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrLifecycleTransition
+	}
+
+	if err := startChecklists(tx, user, constants.CHECKLIST_KIND_OFFBOARDING, terminationDate); err != nil {
+		return nil, err
+	}
+	if !terminationDate.Before(today) {
+		return termination, nil
+	}
+	return termination, applyTermination(tx, termination)
+}
+
+// applyTermination signs user out everywhere, unfinished onboarding is cancelled
+func applyTermination(tx *gorm.DB, termination *models.Termination) error {
+	now := time.Now()
+	result := tx.Model(&models.Termination{}).
+		Where("id = ? AND applied_at IS NULL AND withdrawn_at IS NULL", termination.ID).
+		Update("applied_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	termination.AppliedAt = &now
+
+	err := models.ValidScope(tx).
+		Where("id = ? AND lifecycle_state = ?", termination.UserID, constants.LIFECYCLE_STATE_ON_NOTICE).
+		Update("lifecycle_state", constants.LIFECYCLE_STATE_TERMINATED).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Model(&auth_models.Session{}).
+		Where("user_id = ? AND terminated_at IS NULL", termination.UserID).
+		Update("terminated_at", now).Error
+	if err != nil {
+		return err
+	}
+	return cancelChecklists(tx, termination.UserID, constants.CHECKLIST_KIND_ONBOARDING)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	auth_constants "hr-system-go/internal/auth/constants"
+	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LifecycleService", func() {
+	today := models.EmploymentDate(time.Now())
+
+	registerUser := func(joinDate time.Time) *models.User {
+		user := &models.User{Name: "John Doe", Email: faker.Email(), JoinDate: joinDate}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+		return user
+	}
+	checklistsOf := func(user *models.User, kind string) []models.Checklist {
+		checklists, err := checklistService.FindChecklists(int(user.ID))
+		Expect(err).To(BeNil())
+		found := []models.Checklist{}
+		for _, checklist := range checklists {
+			if checklist.Kind == kind {
+				found = append(found, checklist)
+			}
+		}
+		return found
+	}
+
+	BeforeEach(func() {
+		templates, _ := checklistService.FindTemplates("")
+		if len(templates) > 0 {
+			return
+		}
+		for _, kind := range constants.CHECKLIST_KINDS {
+			_, err := checklistService.CreateTemplate(context.Background(), dtos.ChecklistTemplateRequest{
+				Name: kind,
+				Kind: kind,
+				Tasks: []dtos.ChecklistTemplateTaskRequest{
+					{Title: "Accounts", Assignee: constants.CHECKLIST_ASSIGNEE_IT, DueOffsetDays: -2},
+					{Title: "Paperwork", Assignee: constants.CHECKLIST_ASSIGNEE_HR, DueOffsetDays: 5},
+				},
+			})
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("should keep future hire in pre-hire with onboarding due around hire date", func() {
+		joinDate := today.AddDate(0, 0, 14)
+		user := registerUser(joinDate)
+
+		Expect(user.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_PRE_HIRE))
+		onboarding := checklistsOf(user, constants.CHECKLIST_KIND_ONBOARDING)
+		Expect(onboarding).To(HaveLen(1))
+		Expect(onboarding[0].Tasks[0].DueDate.Equal(joinDate.AddDate(0, 0, -2))).To(BeTrue())
+
+		mockDB.DB().Model(&models.User{}).Where("id = ?", user.ID).Update("join_date", today)
+		_, err := lifecycleService.ApplyDueLifecycle(context.Background())
+		Expect(err).To(BeNil())
+
+		found, _, _ := lifecycleService.FindLifecycle(int(user.ID))
+		Expect(found.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_ACTIVE))
+	})
+
+	It("should put user on notice and restore state when notice is withdrawn", func() {
+		user := registerUser(today.AddDate(-1, 0, 0))
+		terminationDate := today.AddDate(0, 1, 0)
+
+		termination, err := lifecycleService.TerminateUser(context.Background(), 1, int(user.ID), dtos.TerminateUserRequest{
+			TerminationDate:  &terminationDate,
+			NoticePeriodDays: 30,
+			Reason:           constants.TERMINATION_REASON_RESIGNATION,
+		})
+		Expect(err).To(BeNil())
+		Expect(termination.AppliedAt).To(BeNil())
+
+		found, current, _ := lifecycleService.FindLifecycle(int(user.ID))
+		Expect(found.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_ON_NOTICE))
+		Expect(current.ID).To(Equal(termination.ID))
+		Expect(checklistsOf(user, constants.CHECKLIST_KIND_OFFBOARDING)).To(HaveLen(1))
+
+		_, err = lifecycleService.TerminateUser(context.Background(), 1, int(user.ID), dtos.TerminateUserRequest{TerminationDate: &terminationDate, Reason: constants.TERMINATION_REASON_OTHER})
+		Expect(err).To(MatchError(ErrTerminationExists))
+
+		Expect(lifecycleService.WithdrawTermination(context.Background(), int(user.ID))).To(Succeed())
+		found, current, _ = lifecycleService.FindLifecycle(int(user.ID))
+		Expect(found.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_ACTIVE))
+		Expect(current).To(BeNil())
+		Expect(checklistsOf(user, constants.CHECKLIST_KIND_OFFBOARDING)[0].CancelledAt).NotTo(BeNil())
+	})
+
+	It("should not put the last admin on notice", func() {
+		mockDB.DB().Exec("UPDATE user SET role_id = NULL")
+		mockDB.DB().Exec("DELETE FROM user_role")
+		mockDB.DB().Exec("DELETE FROM user_ability")
+		var adminAbility auth_models.Ability
+		mockDB.DB().Where("name = ?", auth_constants.ABILITY_ADMIN).FirstOrCreate(&adminAbility, auth_models.Ability{Name: auth_constants.ABILITY_ADMIN})
+		adminRole := &auth_models.Role{Name: "Leaving Admin", Abilities: []auth_models.Ability{adminAbility}}
+		mockDB.DB().Create(adminRole)
+		user := registerUser(today.AddDate(-1, 0, 0))
+		mockDB.DB().Model(&models.User{}).Where("id = ?", user.ID).Update("role_id", adminRole.ID)
+		terminationDate := today.AddDate(0, 1, 0)
+
+		_, err := lifecycleService.TerminateUser(context.Background(), 1, int(user.ID), dtos.TerminateUserRequest{
+			TerminationDate: &terminationDate,
+			Reason:          constants.TERMINATION_REASON_RESIGNATION,
+		})
+
+		Expect(err).To(MatchError(auth_services.ErrLastAdmin))
+		found, current, _ := lifecycleService.FindLifecycle(int(user.ID))
+		Expect(found.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_ACTIVE))
+		Expect(current).To(BeNil())
+	})
+
+	It("should terminate after last working day and rehire later", func() {
+		user := registerUser(today.AddDate(-2, 0, 0))
+		terminationDate := today.AddDate(0, 0, -1)
+
+		termination, err := lifecycleService.TerminateUser(context.Background(), 1, int(user.ID), dtos.TerminateUserRequest{
+			NoticeDate:      &terminationDate,
+			TerminationDate: &terminationDate,
+			Reason:          constants.TERMINATION_REASON_MUTUAL_AGREEMENT,
+		})
+		Expect(err).To(BeNil())
+		Expect(termination.AppliedAt).NotTo(BeNil())
+		found, _, _ := lifecycleService.FindLifecycle(int(user.ID))
+		Expect(found.Terminated()).To(BeTrue())
+
+		_, err = lifecycleService.RehireUser(context.Background(), int(user.ID), dtos.RehireUserRequest{JoinDate: &terminationDate})
+		Expect(err).To(MatchError(ErrRehireDate))
+
+		probationEndDate := today.AddDate(0, 3, 0)
+		rehired, err := lifecycleService.RehireUser(context.Background(), int(user.ID), dtos.RehireUserRequest{JoinDate: &today, ProbationEndDate: &probationEndDate})
+		Expect(err).To(BeNil())
+		Expect(rehired.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_PROBATION))
+		Expect(checklistsOf(user, constants.CHECKLIST_KIND_ONBOARDING)).To(HaveLen(2))
+	})
+
+	It("should only allow moves along lifecycle", func() {
+		user := registerUser(today.AddDate(0, 0, 7))
+
+		_, err := lifecycleService.ChangeLifecycleState(context.Background(), int(user.ID), dtos.ChangeLifecycleStateRequest{State: constants.LIFECYCLE_STATE_TERMINATED})
+		Expect(err).To(MatchError(ErrLifecycleTransition))
+
+		probationEndDate := today.AddDate(0, 6, 0)
+		changed, err := lifecycleService.ChangeLifecycleState(context.Background(), int(user.ID), dtos.ChangeLifecycleStateRequest{State: constants.LIFECYCLE_STATE_PROBATION, ProbationEndDate: &probationEndDate})
+		Expect(err).To(BeNil())
+		Expect(changed.LifecycleState).To(Equal(constants.LIFECYCLE_STATE_PROBATION))
+	})
+})
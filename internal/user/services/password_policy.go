@@ -0,0 +1,191 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+var ErrPasswordPolicy = errors.New("password does not satisfy policy")
+
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrPasswordPolicy.Error(), strings.Join(messages, "; "))
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrPasswordPolicy
+}
+
+type PasswordPolicy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	HistorySize    int
+	MaxAge         time.Duration
+	commonPassword map[string]struct{}
+}
+
+func NewPasswordPolicy(env *env.Env, logger *logger.Logger) *PasswordPolicy {
+	policy := &PasswordPolicy{
+		MinLength:      env.GetEnvInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:   env.GetEnvBool("PASSWORD_REQUIRE_UPPERCASE", true),
+		RequireLower:   env.GetEnvBool("PASSWORD_REQUIRE_LOWERCASE", true),
+		RequireDigit:   env.GetEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol:  env.GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		HistorySize:    env.GetEnvInt("PASSWORD_HISTORY_SIZE", 5),
+		MaxAge:         time.Duration(env.GetEnvInt("PASSWORD_MAX_AGE_DAYS", 0)) * 24 * time.Hour,
+		commonPassword: map[string]struct{}{},
+	}
+
+	path := env.GetEnv("PASSWORD_COMMON_LIST_PATH")
+	if path == "" {
+		path = "config/common_passwords.txt"
+	}
+	if err := policy.LoadCommonPasswords(resolveProjectPath(path)); err != nil {
+		logger.Warn("Cannot load common password list", zap.Error(err))
+	}
+
+	return policy
+}
+
+// LoadCommonPasswords reads one password per line, lines starting with # are ignored
+func (p *PasswordPolicy) LoadCommonPasswords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.commonPassword[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// Validate checks password rules which do not need the password history
+func (p *PasswordPolicy) Validate(password string, user *models.User) error {
+	var violations []PasswordViolation
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Rule:    constants.PASSWORD_RULE_MIN_LENGTH,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, PasswordViolation{
+			Rule:    constants.PASSWORD_RULE_UPPERCASE,
+			Message: "password must contain an uppercase letter",
+		})
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, PasswordViolation{
+			Rule:    constants.PASSWORD_RULE_LOWERCASE,
+			Message: "password must contain a lowercase letter",
+		})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, PasswordViolation{
+			Rule:    constants.PASSWORD_RULE_DIGIT,
+			Message: "password must contain a digit",
+		})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, PasswordViolation{
+			Rule:    constants.PASSWORD_RULE_SYMBOL,
+			Message: "password must contain a symbol",
+		})
+	}
+
+	if _, exist := p.commonPassword[strings.ToLower(password)]; exist {
+		violations = append(violations, PasswordViolation{
+			Rule:    constants.PASSWORD_RULE_COMMON,
+			Message: "password is too common",
+		})
+	}
+
+	if user != nil && containsPersonalInfo(password, user) {
+		violations = append(violations, PasswordViolation{
+			Rule:    constants.PASSWORD_RULE_PERSONAL_INFO,
+			Message: "password must not contain your name or email",
+		})
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func containsPersonalInfo(password string, user *models.User) bool {
+	lowerPassword := strings.ToLower(password)
+	candidates := strings.Fields(strings.ToLower(user.Name))
+	if localPart, _, found := strings.Cut(strings.ToLower(user.Email), "@"); found {
+		candidates = append(candidates, localPart)
+	}
+
+	for _, candidate := range candidates {
+		// short fragments like "li" would reject too many valid passwords
+		if len(candidate) >= 3 && strings.Contains(lowerPassword, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveProjectPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	// for test with different package
+	projectRoot := os.Getenv("PROJECT_ROOT")
+	if len(projectRoot) == 0 {
+		projectRoot, _ = os.Getwd()
+	}
+	return filepath.Join(projectRoot, path)
+}
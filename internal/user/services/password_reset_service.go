@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/models"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidPasswordResetToken does not tell unknown, used, expired and superseded tokens apart
+var ErrInvalidPasswordResetToken = errors.New("password reset link is invalid or expired")
+
+type PasswordResetServiceInterface interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) (*models.User, error)
+}
+
+type PasswordResetService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	mailer *mailer.Mailer
+	users  *UserService
+	ttl    time.Duration
+	// setupTTL is validity of first password link of imported users, same as invitations
+	setupTTL time.Duration
+	resetURL string
+}
+
+func NewPasswordResetService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, mailer *mailer.Mailer) PasswordResetServiceInterface {
+	return newPasswordResetService(logger, env, db, mailer)
+}
+
+func newPasswordResetService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, mailer *mailer.Mailer) *PasswordResetService {
+	resetURL := env.GetEnv("PASSWORD_RESET_URL")
+	if resetURL == "" {
+		resetURL = constants.PASSWORD_RESET_DEFAULT_URL
+	}
+
+	return &PasswordResetService{
+		logger:   logger,
+		db:       db,
+		mailer:   mailer,
+		users:    newUserService(logger, env, db),
+		ttl:      time.Duration(env.GetEnvInt("PASSWORD_RESET_TTL_MINUTES", constants.PASSWORD_RESET_DEFAULT_TTL_MINUTES)) * time.Minute,
+		setupTTL: time.Duration(env.GetEnvInt("INVITATION_TTL_HOURS", constants.INVITATION_DEFAULT_TTL_HOURS)) * time.Hour,
+		resetURL: resetURL,
+	}
+}
+
+// RequestPasswordReset mails a reset link, unknown emails and accounts without password are
+// skipped silently so the caller cannot learn who has an account
+func (s *PasswordResetService) RequestPasswordReset(ctx context.Context, email string) error {
+	var user *models.User
+	err := models.ValidScope(s.db.DB()).Where("email = ?", email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.CanUsePassword() || user.Terminated() {
+		return nil
+	}
+
+	return s.sendReset(ctx, user, s.ttl, "Reset your password", "A password reset was requested for your account, ignore this email if it was not you.")
+}
+
+// ResetPassword consumes token and sets new password in the same transaction, it does not sign user in
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token string, newPassword string) (*models.User, error) {
+	var reset *models.PasswordReset
+	err := models.UsablePasswordResetScope(s.db.DB()).Where("token_hash = ?", hashMailToken(token)).First(&reset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	if err := models.ValidScope(s.db.DB()).First(&user, reset.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidPasswordResetToken
+		}
+		return nil, err
+	}
+	if !user.CanUsePassword() || user.Terminated() {
+		return nil, ErrInvalidPasswordResetToken
+	}
+	if err := s.users.checkNewPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+
+	err = s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := models.UsablePasswordResetScope(tx).Where("id = ?", reset.ID).Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidPasswordResetToken
+		}
+
+		if err := s.users.savePassword(tx, user, newPassword); err != nil {
+			return err
+		}
+		// link was opened from the mailbox, so it is proven to be the user's
+		if user.EmailVerified() {
+			return nil
+		}
+		now := time.Now()
+		if err := models.ValidScope(tx).Where("id = ?", user.ID).Update("email_verified_at", now).Error; err != nil {
+			return err
+		}
+		user.EmailVerifiedAt = &now
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Cannot Reset Password", zap.Error(err))
+		return nil, err
+	}
+	return user, nil
+}
+
+// sendPasswordSetup mails imported user a link to set the first password
+func (s *PasswordResetService) sendPasswordSetup(ctx context.Context, user *models.User) error {
+	return s.sendReset(ctx, user, s.setupTTL, "Set your password", "An account was created for you, set a password to sign in.")
+}
+
+// sendReset mails a link to set a new password, earlier links stop working
+func (s *PasswordResetService) sendReset(ctx context.Context, user *models.User, ttl time.Duration, subject string, intro string) error {
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := s.issueToken(tx, user.ID, ttl)
+		if err != nil {
+			return err
+		}
+		return s.mailer.Send(mailer.Message{
+			To:      []string{user.Email},
+			Subject: subject,
+			Body:    fmt.Sprintf("%s\nSet a new password before %s:\n%s\n", intro, time.Now().Add(ttl).Format(time.RFC1123), s.resetLink(token)),
+		})
+	})
+	if err != nil {
+		s.logger.Error("Cannot Send Password Reset", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// issueToken replaces unused tokens of the user, so only the latest link works
+func (s *PasswordResetService) issueToken(tx *gorm.DB, userID uint, ttl time.Duration) (string, error) {
+	if err := tx.Where("user_id = ? AND used_at IS NULL", userID).Delete(&models.PasswordReset{}).Error; err != nil {
+		return "", err
+	}
+
+	token, err := newMailToken()
+	if err != nil {
+		return "", err
+	}
+	reset := &models.PasswordReset{
+		UserID:    userID,
+		TokenHash: hashMailToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := tx.Create(&reset).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *PasswordResetService) resetLink(token string) string {
+	return s.resetURL + "?token=" + url.QueryEscape(token)
+}
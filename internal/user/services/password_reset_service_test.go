@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("PasswordResetService", func() {
+	registerUser := func() *models.User {
+		user := &models.User{Name: "John Doe", Email: faker.Email()}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+		return user
+	}
+	issueToken := func(user *models.User, expiresAt time.Time) string {
+		token := "token-" + faker.UUIDDigit()
+		reset := &models.PasswordReset{
+			UserID:    user.ID,
+			TokenHash: hashMailToken(token),
+			ExpiresAt: expiresAt,
+		}
+		Expect(mockDB.DB().Create(&reset).Error).To(Succeed())
+		return token
+	}
+
+	Describe("RequestPasswordReset", func() {
+		It("should keep only the latest reset link", func() {
+			user := registerUser()
+
+			Expect(passwordResetService.RequestPasswordReset(context.Background(), user.Email)).To(Succeed())
+			Expect(passwordResetService.RequestPasswordReset(context.Background(), user.Email)).To(Succeed())
+
+			var count int64
+			models.UsablePasswordResetScope(mockDB.DB()).Where("user_id = ?", user.ID).Count(&count)
+			Expect(count).To(Equal(int64(1)))
+		})
+
+		It("should not tell unknown email apart", func() {
+			Expect(passwordResetService.RequestPasswordReset(context.Background(), faker.Email())).To(Succeed())
+		})
+
+		It("should not send link to account without password login", func() {
+			user := registerUser()
+			mockDB.DB().Model(&models.User{}).Where("id = ?", user.ID).Update("password_login_disabled", true)
+
+			Expect(passwordResetService.RequestPasswordReset(context.Background(), user.Email)).To(Succeed())
+
+			var count int64
+			models.UsablePasswordResetScope(mockDB.DB()).Where("user_id = ?", user.ID).Count(&count)
+			Expect(count).To(BeZero())
+		})
+	})
+
+	Describe("ResetPassword", func() {
+		It("should set new password once", func() {
+			user := registerUser()
+			token := issueToken(user, time.Now().Add(time.Hour))
+
+			reset, err := passwordResetService.ResetPassword(context.Background(), token, "Moonflower2025")
+
+			Expect(err).To(BeNil())
+			Expect(bcrypt.CompareHashAndPassword([]byte(reset.PasswordEncrypt), []byte("Moonflower2025"))).To(Succeed())
+			_, err = passwordResetService.ResetPassword(context.Background(), token, "Starflower2026")
+			Expect(err).To(MatchError(ErrInvalidPasswordResetToken))
+		})
+
+		It("should verify email the link was mailed to", func() {
+			user := registerUser()
+			token := issueToken(user, time.Now().Add(time.Hour))
+
+			reset, err := passwordResetService.ResetPassword(context.Background(), token, "Moonflower2025")
+
+			Expect(err).To(BeNil())
+			Expect(reset.EmailVerified()).To(BeTrue())
+		})
+
+		It("should refuse expired token", func() {
+			user := registerUser()
+			token := issueToken(user, time.Now().Add(-time.Minute))
+
+			_, err := passwordResetService.ResetPassword(context.Background(), token, "Moonflower2025")
+
+			Expect(err).To(MatchError(ErrInvalidPasswordResetToken))
+		})
+
+		It("should keep token when password violates policy", func() {
+			user := registerUser()
+			token := issueToken(user, time.Now().Add(time.Hour))
+
+			_, err := passwordResetService.ResetPassword(context.Background(), token, "Sunflower2024")
+
+			var policyErr *PasswordPolicyError
+			Expect(err).To(BeAssignableToTypeOf(policyErr))
+			var count int64
+			models.UsablePasswordResetScope(mockDB.DB()).Where("user_id = ?", user.ID).Count(&count)
+			Expect(count).To(Equal(int64(1)))
+		})
+	})
+})
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProfileDateOfBirth = errors.New("dateOfBirth must be in the past")
+	ErrProfileAddress     = errors.New("address needs type home or postal, line1, city and country")
+	ErrProfilePhoneNumber = errors.New("phone number needs type mobile, home or work and digits")
+	ErrProfileBankAccount = errors.New("bank account needs holder and number")
+	// ErrProfileEmergencyContact is also returned when there are too many contacts
+	ErrProfileEmergencyContact = fmt.Errorf("emergency contact needs name, relationship and phone, at most %d contacts", constants.PROFILE_MAX_EMERGENCY_CONTACTS)
+)
+
+type ProfileServiceInterface interface {
+	FindProfile(userID int) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, userID int, payload dtos.UpdateProfileRequest) (*models.Profile, error)
+}
+
+type ProfileService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+}
+
+func NewProfileService(logger *logger.Logger, db *mysql.MySqlStore) ProfileServiceInterface {
+	return &ProfileService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// FindProfile returns empty profile for users who have not filled it in yet
+func (s *ProfileService) FindProfile(userID int) (*models.Profile, error) {
+	return s.findProfile(s.db.DB(), userID)
+}
+
+// UpdateProfile changes fields set in payload, date of birth is stored on user
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID int, payload dtos.UpdateProfileRequest) (*models.Profile, error) {
+	if err := normalizeProfile(&payload); err != nil {
+		return nil, err
+	}
+
+	var profile *models.Profile
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if profile, err = s.findProfile(tx, userID); err != nil {
+			return err
+		}
+
+		if payload.DateOfBirth != nil {
+			if err := models.ValidScope(tx).Where("id = ?", userID).Update("date_of_birth", payload.DateOfBirth).Error; err != nil {
+				return err
+			}
+			profile.User.DateOfBirth = payload.DateOfBirth
+		}
+
+		if payload.NationalID != nil {
+			profile.NationalID = optionalString(*payload.NationalID)
+		}
+		if payload.BankAccount != nil {
+			profile.BankAccountHolder = optionalString(payload.BankAccount.Holder)
+			profile.BankAccountNumber = optionalString(payload.BankAccount.Number)
+			profile.BankCode = optionalString(payload.BankAccount.Code)
+		}
+		if payload.Addresses != nil {
+			profile.Addresses = *payload.Addresses
+		}
+		if payload.PhoneNumbers != nil {
+			profile.PhoneNumbers = *payload.PhoneNumbers
+		}
+		if payload.EmergencyContacts != nil {
+			profile.EmergencyContacts = *payload.EmergencyContacts
+		}
+
+		return tx.Omit("User").Save(&profile).Error
+	})
+	if err != nil {
+		s.logger.Error("Cannot Update Profile", zap.Error(err))
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *ProfileService) findProfile(db *gorm.DB, userID int) (*models.Profile, error) {
+	var user *models.User
+	if err := models.ValidScope(db).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var profile *models.Profile
+	err := db.Where("user_id = ?", user.ID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = &models.Profile{UserID: user.ID}
+	} else if err != nil {
+		s.logger.Error("Cannot Find Profile", zap.Error(err))
+		return nil, err
+	}
+
+	profile.User = user
+	return profile, nil
+}
+
+// normalizeProfile trims payload in place and validates it
+func normalizeProfile(payload *dtos.UpdateProfileRequest) error {
+	if payload.DateOfBirth != nil && !payload.DateOfBirth.Before(time.Now()) {
+		return ErrProfileDateOfBirth
+	}
+	if payload.NationalID != nil {
+		*payload.NationalID = strings.TrimSpace(*payload.NationalID)
+	}
+
+	if account := payload.BankAccount; account != nil {
+		account.Holder = strings.TrimSpace(account.Holder)
+		account.Number = strings.ReplaceAll(strings.TrimSpace(account.Number), " ", "")
+		account.Code = strings.TrimSpace(account.Code)
+		// empty number removes bank account
+		if account.Number != "" && account.Holder == "" {
+			return ErrProfileBankAccount
+		}
+		if account.Number == "" {
+			*account = dtos.BankAccountRequest{}
+		}
+	}
+
+	if payload.Addresses != nil {
+		for i := range *payload.Addresses {
+			address := &(*payload.Addresses)[i]
+			trimFields(&address.Type, &address.Line1, &address.Line2, &address.City, &address.Region, &address.PostalCode, &address.Country)
+			if !slices.Contains(constants.ADDRESS_TYPES, address.Type) || address.Line1 == "" || address.City == "" || address.Country == "" {
+				return ErrProfileAddress
+			}
+		}
+	}
+
+	if payload.PhoneNumbers != nil {
+		for i := range *payload.PhoneNumbers {
+			phone := &(*payload.PhoneNumbers)[i]
+			trimFields(&phone.Type, &phone.Number)
+			if !slices.Contains(constants.PHONE_TYPES, phone.Type) || !validPhoneNumber(phone.Number) {
+				return ErrProfilePhoneNumber
+			}
+		}
+	}
+
+	if payload.EmergencyContacts != nil {
+		if len(*payload.EmergencyContacts) > constants.PROFILE_MAX_EMERGENCY_CONTACTS {
+			return ErrProfileEmergencyContact
+		}
+		for i := range *payload.EmergencyContacts {
+			contact := &(*payload.EmergencyContacts)[i]
+			trimFields(&contact.Name, &contact.Relationship, &contact.Phone, &contact.Email)
+			if contact.Name == "" || contact.Relationship == "" || !validPhoneNumber(contact.Phone) {
+				return ErrProfileEmergencyContact
+			}
+			if contact.Email != "" {
+				email, err := parseEmail(contact.Email)
+				if err != nil {
+					return err
+				}
+				contact.Email = email
+			}
+		}
+	}
+
+	return nil
+}
+
+// validPhoneNumber accepts digits with optional leading + and common separators
+func validPhoneNumber(number string) bool {
+	digits := 0
+	for i, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= 4
+}
+
+func trimFields(fields ...*string) {
+	for _, field := range fields {
+		*field = strings.TrimSpace(*field)
+	}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
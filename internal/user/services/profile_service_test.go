@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProfileService", func() {
+	registerUser := func() *models.User {
+		user := &models.User{Name: "John Doe", Email: faker.Email()}
+		Expect(userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)).To(Succeed())
+		return user
+	}
+
+	It("should return empty profile of user who has not filled it in", func() {
+		user := registerUser()
+
+		profile, err := profileService.FindProfile(int(user.ID))
+
+		Expect(err).To(BeNil())
+		Expect(profile.ID).To(BeZero())
+		Expect(profile.User.ID).To(Equal(user.ID))
+	})
+
+	It("should update only fields set in payload", func() {
+		user := registerUser()
+		dateOfBirth := time.Date(1985, 4, 12, 0, 0, 0, 0, time.UTC)
+		nationalID := " 850412-1234 "
+		phones := []models.PhoneNumber{{Type: constants.PHONE_TYPE_MOBILE, Number: "+49 151 2345678"}}
+
+		_, err := profileService.UpdateProfile(context.Background(), int(user.ID), dtos.UpdateProfileRequest{
+			DateOfBirth:  &dateOfBirth,
+			NationalID:   &nationalID,
+			PhoneNumbers: &phones,
+			BankAccount:  &dtos.BankAccountRequest{Holder: "John Doe", Number: "DE89 3704 0044 0532 0130 00"},
+		})
+		Expect(err).To(BeNil())
+
+		contacts := []models.EmergencyContact{{Name: "Jane Doe", Relationship: "spouse", Phone: "+49 151 7654321"}}
+		_, err = profileService.UpdateProfile(context.Background(), int(user.ID), dtos.UpdateProfileRequest{EmergencyContacts: &contacts})
+		Expect(err).To(BeNil())
+
+		profile, _ := profileService.FindProfile(int(user.ID))
+		Expect(*profile.NationalID).To(Equal("850412-1234"))
+		Expect(*profile.BankAccountNumber).To(Equal("DE89370400440532013000"))
+		Expect(profile.PhoneNumbers).To(Equal(phones))
+		Expect(profile.EmergencyContacts).To(HaveLen(1))
+		Expect(profile.User.DateOfBirth.Format(time.DateOnly)).To(Equal("1985-04-12"))
+	})
+
+	It("should reject address without city and future date of birth", func() {
+		user := registerUser()
+		addresses := []models.Address{{Type: constants.ADDRESS_TYPE_HOME, Line1: "Main Street 1", Country: "DE"}}
+		tomorrow := time.Now().AddDate(0, 0, 1)
+
+		_, err := profileService.UpdateProfile(context.Background(), int(user.ID), dtos.UpdateProfileRequest{Addresses: &addresses})
+		Expect(err).To(MatchError(ErrProfileAddress))
+		_, err = profileService.UpdateProfile(context.Background(), int(user.ID), dtos.UpdateProfileRequest{DateOfBirth: &tomorrow})
+		Expect(err).To(MatchError(ErrProfileDateOfBirth))
+	})
+})
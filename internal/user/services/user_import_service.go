@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-system-go/app/plugins/env"
+	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
+	"hr-system-go/app/plugins/mysql"
+	auth_models "hr-system-go/internal/auth/models"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	customfield_services "hr-system-go/internal/customfield/services"
+	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserImportMapping  = errors.New("mapping must tie name, email and optional joinDate, dateOfBirth, department, role and salary to columns of the file")
+	ErrUserImportEmpty    = errors.New("file has no rows to import")
+	ErrUserImportTooLarge = fmt.Errorf("file must not have more than %d rows", constants.USER_IMPORT_MAX_ROWS)
+	ErrUserImportRows     = errors.New("file has invalid rows")
+)
+
+// UserImportError lists every invalid cell, nothing is imported while there are any
+type UserImportError struct {
+	Errors []dtos.UserImportRowError
+}
+
+func (e *UserImportError) Error() string {
+	return fmt.Sprintf("%s: %d errors", ErrUserImportRows.Error(), len(e.Errors))
+}
+
+func (e *UserImportError) Unwrap() error {
+	return ErrUserImportRows
+}
+
+type UserImportServiceInterface interface {
+	ImportUsers(ctx context.Context, payload dtos.ImportUsersRequest) ([]dtos.UserImportRow, error)
+	ExportUsers(pagination *utils.Pagination) ([]models.User, error)
+}
+
+type UserImportService struct {
+	logger *logger.Logger
+	db     *mysql.MySqlStore
+	users  *UserService
+	resets *PasswordResetService
+}
+
+func NewUserImportService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore, mailer *mailer.Mailer) UserImportServiceInterface {
+	return &UserImportService{
+		logger: logger,
+		db:     db,
+		users:  newUserService(logger, env, db),
+		resets: newPasswordResetService(logger, env, db, mailer),
+	}
+}
+
+// ImportUsers validates every row before saving any, all users are created in one transaction.
+// Imported users get a random password and a mailed link to set their own
+func (s *UserImportService) ImportUsers(ctx context.Context, payload dtos.ImportUsersRequest) ([]dtos.UserImportRow, error) {
+	if len(payload.Rows) < 2 {
+		return nil, ErrUserImportEmpty
+	}
+	if len(payload.Rows)-1 > constants.USER_IMPORT_MAX_ROWS {
+		return nil, ErrUserImportTooLarge
+	}
+	columns, err := importColumns(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, importErr := s.parseRows(payload.Rows, columns, payload.CustomFieldColumns())
+	if importErr != nil && !errors.Is(importErr, ErrUserImportRows) {
+		s.logger.Error("Cannot Validate User Import", zap.Error(importErr))
+		return nil, importErr
+	}
+	if len(rows) == 0 && importErr == nil {
+		return nil, ErrUserImportEmpty
+	}
+	if payload.DryRun || importErr != nil {
+		return rows, importErr
+	}
+
+	err = s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			// associations are only for preview, saving them would write departments and roles too
+			department, role := row.User.Department, row.User.Role
+			row.User.Department, row.User.Role = nil, nil
+			row.User.GenerateRandomPassword()
+			if err := s.users.insertUser(tx, row.User, row.CustomFields); err != nil {
+				return err
+			}
+			row.User.Department, row.User.Role = department, role
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Cannot Import Users", zap.Error(err))
+		return nil, err
+	}
+
+	// mail is sent after commit like invitations, users whose mail failed can still ask for a reset
+	for i := range rows {
+		if err := s.resets.sendPasswordSetup(ctx, rows[i].User); err != nil {
+			s.logger.Error("Cannot Send Password Setup", zap.Error(err))
+			continue
+		}
+		rows[i].PasswordLinkSent = true
+	}
+
+	return rows, nil
+}
+
+// ExportUsers returns every user matching filters of the list, in list order, with department and role
+func (s *UserImportService) ExportUsers(pagination *utils.Pagination) ([]models.User, error) {
+	filter, err := userFilterScope(s.db.DB(), pagination)
+	if err != nil {
+		return nil, err
+	}
+	order, err := pagination.OrderBy(userSortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	err = models.ValidScope(s.db.DB()).Scopes(filter).Preload("Role").Preload("Department").Order(order).Find(&users).Error
+	if err != nil {
+		s.logger.Error("Cannot Export Users", zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
+// parseRows skips empty rows, errors come back as UserImportError along with rows which are valid.
+// Custom field columns are checked like values of the API, required fields need a column
+func (s *UserImportService) parseRows(fileRows [][]string, columns map[string]int, customColumns map[string]int) ([]dtos.UserImportRow, error) {
+	customFields, err := customfield_services.FindCustomFields(s.db.DB(), customfield_constants.CUSTOM_FIELD_ENTITY_USER)
+	if err != nil {
+		return nil, err
+	}
+	for key := range customColumns {
+		if !slices.ContainsFunc(customFields, func(field customfield_models.CustomField) bool { return field.Key == key }) {
+			return nil, &customfield_services.CustomFieldError{Key: key, Reason: "is not a custom field"}
+		}
+	}
+
+	departments := map[string]*department_models.Department{}
+	var foundDepartments []department_models.Department
+	if err := department_models.ValidScope(s.db.DB()).Find(&foundDepartments).Error; err != nil {
+		return nil, err
+	}
+	for i := range foundDepartments {
+		departments[strings.ToLower(foundDepartments[i].Name)] = &foundDepartments[i]
+	}
+	roles := map[string]*auth_models.Role{}
+	var foundRoles []auth_models.Role
+	if err := auth_models.ValidScope(s.db.DB()).Find(&foundRoles).Error; err != nil {
+		return nil, err
+	}
+	for i := range foundRoles {
+		roles[strings.ToLower(foundRoles[i].Name)] = &foundRoles[i]
+	}
+
+	rowErrors := []dtos.UserImportRowError{}
+	rows := []dtos.UserImportRow{}
+	emailRows := map[string]int{}
+	for i, fileRow := range fileRows[1:] {
+		rowNumber := i + 2
+		cell := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(fileRow) {
+				return ""
+			}
+			return strings.TrimSpace(fileRow[index])
+		}
+		if strings.TrimSpace(strings.Join(fileRow, "")) == "" {
+			continue
+		}
+		valid := true
+		addError := func(field string, message string) {
+			rowErrors = append(rowErrors, dtos.UserImportRowError{Row: rowNumber, Field: field, Message: message})
+			valid = false
+		}
+
+		user := &models.User{Name: cell(constants.USER_IMPORT_FIELD_NAME)}
+		if user.Name == "" {
+			addError(constants.USER_IMPORT_FIELD_NAME, ErrInvitationName.Error())
+		}
+		email, err := parseEmail(cell(constants.USER_IMPORT_FIELD_EMAIL))
+		switch {
+		case err != nil:
+			addError(constants.USER_IMPORT_FIELD_EMAIL, err.Error())
+		case emailRows[email] != 0:
+			addError(constants.USER_IMPORT_FIELD_EMAIL, fmt.Sprintf("email is already used on row %d", emailRows[email]))
+		default:
+			emailRows[email] = rowNumber
+			user.Email = email
+		}
+		if value := cell(constants.USER_IMPORT_FIELD_JOIN_DATE); value != "" {
+			joinDate, err := utils.ParseSpreadsheetDate(value)
+			if err != nil {
+				addError(constants.USER_IMPORT_FIELD_JOIN_DATE, "joinDate must be a date like 2026-01-31")
+			}
+			user.JoinDate = joinDate
+		}
+		if value := cell(constants.USER_IMPORT_FIELD_DATE_OF_BIRTH); value != "" {
+			dateOfBirth, err := utils.ParseSpreadsheetDate(value)
+			if err != nil {
+				addError(constants.USER_IMPORT_FIELD_DATE_OF_BIRTH, "dateOfBirth must be a date like 1990-01-31")
+			}
+			user.DateOfBirth = &dateOfBirth
+		}
+		if value := cell(constants.USER_IMPORT_FIELD_DEPARTMENT); value != "" {
+			if department, ok := departments[strings.ToLower(value)]; ok {
+				user.DepartmentID, user.Department = &department.ID, department
+			} else {
+				addError(constants.USER_IMPORT_FIELD_DEPARTMENT, fmt.Sprintf("department %q does not exist", value))
+			}
+		}
+		if value := cell(constants.USER_IMPORT_FIELD_ROLE); value != "" {
+			if role, ok := roles[strings.ToLower(value)]; ok {
+				user.RoleID, user.Role = &role.ID, role
+			} else {
+				addError(constants.USER_IMPORT_FIELD_ROLE, fmt.Sprintf("role %q does not exist", value))
+			}
+		}
+		if value := cell(constants.USER_IMPORT_FIELD_SALARY); value != "" {
+			salary, err := strconv.ParseFloat(value, 64)
+			// NaN and Inf parse as numbers but are no salary
+			if err != nil || math.IsNaN(salary) || math.IsInf(salary, 0) || salary < 0 {
+				addError(constants.USER_IMPORT_FIELD_SALARY, "salary must be a positive number")
+			}
+			user.Salary = &salary
+		}
+		values, err := parseCustomFieldCells(customFields, customColumns, fileRow)
+		var fieldErr *customfield_services.CustomFieldError
+		if errors.As(err, &fieldErr) {
+			addError(customfield_constants.CUSTOM_FIELD_FILTER_PREFIX+fieldErr.Key, err.Error())
+		} else if err != nil {
+			return nil, err
+		}
+
+		if valid {
+			rows = append(rows, dtos.UserImportRow{Row: rowNumber, User: user, CustomFields: values})
+		}
+	}
+
+	// one query for emails taken by existing users
+	emails := []string{}
+	for email := range emailRows {
+		emails = append(emails, email)
+	}
+	var taken []string
+	if len(emails) > 0 {
+		if err := models.ValidScope(s.db.DB()).Where("email IN ?", emails).Pluck("email", &taken).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(taken) > 0 {
+		rows = slices.DeleteFunc(rows, func(row dtos.UserImportRow) bool {
+			return slices.Contains(taken, row.User.Email)
+		})
+		for _, email := range taken {
+			rowErrors = append(rowErrors, dtos.UserImportRowError{Row: emailRows[email], Field: constants.USER_IMPORT_FIELD_EMAIL, Message: ErrEmailTaken.Error()})
+		}
+		slices.SortStableFunc(rowErrors, func(a, b dtos.UserImportRowError) int { return a.Row - b.Row })
+	}
+
+	if len(rowErrors) > 0 {
+		return rows, &UserImportError{Errors: rowErrors}
+	}
+	return rows, nil
+}
+
+// parseCustomFieldCells turns cells of custom field columns into values, empty cells are left out
+func parseCustomFieldCells(fields []customfield_models.CustomField, customColumns map[string]int, fileRow []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for i := range fields {
+		index, ok := customColumns[fields[i].Key]
+		if !ok || index >= len(fileRow) {
+			continue
+		}
+		value, err := customfield_services.CustomFieldValueFromText(&fields[i], fileRow[index])
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			values[fields[i].Key] = value
+		}
+	}
+	return values, customfield_services.CheckCustomFieldValues(fields, values, true)
+}
+
+// importColumns finds column of every mapped field, fields without mapping match headers by name
+func importColumns(payload dtos.ImportUsersRequest) (map[string]int, error) {
+	for field := range payload.Mapping {
+		if !slices.Contains(constants.USER_IMPORT_FIELDS, field) {
+			return nil, ErrUserImportMapping
+		}
+	}
+
+	headers := map[string]int{}
+	for index, header := range payload.Rows[0] {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if _, ok := headers[header]; !ok && header != "" {
+			headers[header] = index
+		}
+	}
+
+	columns := map[string]int{}
+	for _, field := range constants.USER_IMPORT_FIELDS {
+		header, mapped := payload.Mapping[field]
+		if !mapped {
+			header = field
+		}
+		index, ok := headers[strings.ToLower(strings.TrimSpace(header))]
+		switch {
+		case ok:
+			columns[field] = index
+		case mapped, slices.Contains(constants.USER_IMPORT_REQUIRED_FIELDS, field):
+			return nil, ErrUserImportMapping
+		}
+	}
+	return columns, nil
+}
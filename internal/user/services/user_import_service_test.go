@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	"hr-system-go/internal/user/constants"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+
+	"github.com/bxcodec/faker/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserImportService", func() {
+	It("should list errors per row without importing anything", func() {
+		email := faker.Email()
+		existing := &models.User{Name: "John Doe", Email: faker.Email()}
+		Expect(userService.RegisterUser(context.Background(), existing, "Sunflower2024", nil)).To(Succeed())
+
+		rows, err := userImportService.ImportUsers(context.Background(), dtos.ImportUsersRequest{
+			Rows: [][]string{
+				{"Full Name", "Email", "Department"},
+				{"Jane Doe", email, ""},
+				{"Jane Again", email, ""},
+				{"", existing.Email, "Nowhere"},
+			},
+			Mapping: map[string]string{constants.USER_IMPORT_FIELD_NAME: "Full Name"},
+		})
+
+		var importErr *UserImportError
+		Expect(err).To(BeAssignableToTypeOf(importErr))
+		importErr = err.(*UserImportError)
+		Expect(rows).To(HaveLen(1))
+		Expect(importErr.Errors).To(ConsistOf(
+			dtos.UserImportRowError{Row: 3, Field: constants.USER_IMPORT_FIELD_EMAIL, Message: "email is already used on row 2"},
+			dtos.UserImportRowError{Row: 4, Field: constants.USER_IMPORT_FIELD_NAME, Message: ErrInvitationName.Error()},
+			dtos.UserImportRowError{Row: 4, Field: constants.USER_IMPORT_FIELD_EMAIL, Message: ErrEmailTaken.Error()},
+			dtos.UserImportRowError{Row: 4, Field: constants.USER_IMPORT_FIELD_DEPARTMENT, Message: `department "Nowhere" does not exist`},
+		))
+		_, err = userService.FindUserByEmail(email)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should import valid rows in one go", func() {
+		emails := []string{faker.Email(), faker.Email()}
+
+		rows, err := userImportService.ImportUsers(context.Background(), dtos.ImportUsersRequest{
+			Rows: [][]string{
+				{"name", "email", "joinDate"},
+				{"Jane Doe", emails[0], "2026-01-05"},
+				{},
+				{"John Doe", emails[1], "46027"},
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(rows).To(HaveLen(2))
+		Expect(rows[1].Row).To(Equal(4))
+		for _, email := range emails {
+			user, err := userService.FindUserByEmail(email)
+			Expect(err).To(BeNil())
+			Expect(user.JoinDate.Format("2006-01-02")).To(Equal("2026-01-05"))
+			Expect(user.PasswordChangedAt).To(BeNil())
+			// link to set the first password is mailed once users are saved
+			var links int64
+			models.UsablePasswordResetScope(mockDB.DB()).Where("user_id = ?", user.ID).Count(&links)
+			Expect(links).To(Equal(int64(1)))
+		}
+		Expect(rows[0].PasswordLinkSent).To(BeTrue())
+	})
+
+	It("should refuse salary which is not a finite number", func() {
+		_, err := userImportService.ImportUsers(context.Background(), dtos.ImportUsersRequest{
+			Rows: [][]string{
+				{"name", "email", "salary"},
+				{"Jane Doe", faker.Email(), "NaN"},
+				{"John Doe", faker.Email(), "+Inf"},
+			},
+		})
+
+		var importErr *UserImportError
+		Expect(err).To(BeAssignableToTypeOf(importErr))
+		Expect(err.(*UserImportError).Errors).To(ConsistOf(
+			dtos.UserImportRowError{Row: 2, Field: constants.USER_IMPORT_FIELD_SALARY, Message: "salary must be a positive number"},
+			dtos.UserImportRowError{Row: 3, Field: constants.USER_IMPORT_FIELD_SALARY, Message: "salary must be a positive number"},
+		))
+	})
+
+	Describe("with custom field columns", func() {
+		BeforeEach(func() {
+			tShirtSize := &customfield_models.CustomField{Entity: customfield_constants.CUSTOM_FIELD_ENTITY_USER, Key: "tShirtSize", Label: "T-shirt size", Type: customfield_constants.CUSTOM_FIELD_TYPE_SELECT, Options: []string{"S", "M", "L"}, Required: true}
+			Expect(mockDB.DB().Create(tShirtSize).Error).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = mockDB.DB().Exec("truncate table custom_field").Error
+			_ = mockDB.DB().Exec("truncate table custom_field_value").Error
+		})
+
+		It("should check custom field columns per row", func() {
+			rows, err := userImportService.ImportUsers(context.Background(), dtos.ImportUsersRequest{
+				Rows: [][]string{
+					{"name", "email", "custom.tShirtSize"},
+					{"Jane Doe", faker.Email(), "M"},
+					{"John Doe", faker.Email(), ""},
+					{"Joe Doe", faker.Email(), "XXL"},
+				},
+				DryRun: true,
+			})
+
+			var importErr *UserImportError
+			Expect(err).To(BeAssignableToTypeOf(importErr))
+			Expect(rows).To(HaveLen(1))
+			Expect(rows[0].CustomFields).To(Equal(map[string]interface{}{"tShirtSize": "M"}))
+			Expect(err.(*UserImportError).Errors).To(ConsistOf(
+				dtos.UserImportRowError{Row: 3, Field: "custom.tShirtSize", Message: "tShirtSize is required"},
+				dtos.UserImportRowError{Row: 4, Field: "custom.tShirtSize", Message: "tShirtSize must be one of S, M, L"},
+			))
+		})
+	})
+
+	It("should require mapped name and email columns", func() {
+		_, err := userImportService.ImportUsers(context.Background(), dtos.ImportUsersRequest{
+			Rows: [][]string{{"name"}, {"Jane Doe"}},
+		})
+
+		Expect(err).To(MatchError(ErrUserImportMapping))
+	})
+})
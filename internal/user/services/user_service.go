@@ -1,62 +1,154 @@
 package services
 
 import (
+	"context"
+	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
 	"hr-system-go/app/plugins/mysql"
+	auth_services "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_services "hr-system-go/internal/customfield/services"
 	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
 	"hr-system-go/internal/user/dtos"
 	"hr-system-go/internal/user/models"
 	"hr-system-go/utils"
+	"slices"
 	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type UserServiceInterface interface {
-	RegisterUser(user *models.User, password string) error
+	RegisterUser(ctx context.Context, user *models.User, password string, customFields map[string]interface{}) error
 	FindUsers(pagination *utils.Pagination) ([]models.User, int64, error)
 	FindUserByEmail(email string) (*models.User, error)
 	FindUserByID(userId int) (*models.User, error)
-	UpdateUserByID(userId int, payload dtos.UpdateUserRequest) (*models.User, error)
-	DeleteUserByID(userId int) error
-	UpdatePassword(user *models.User, newPassword string) error
+	UpdateUserByID(ctx context.Context, userId int, payload dtos.UpdateUserRequest) (*models.User, error)
+	DeleteUserByID(ctx context.Context, userId int) error
+	UpdatePassword(ctx context.Context, user *models.User, newPassword string) error
+	IsPasswordExpired(user *models.User) bool
 }
 
 type UserService struct {
-	logger *logger.Logger
-	db     *mysql.MySqlStore
+	logger         *logger.Logger
+	db             *mysql.MySqlStore
+	passwordPolicy *PasswordPolicy
 }
 
-func NewUserService(logger *logger.Logger, db *mysql.MySqlStore) UserServiceInterface {
+func NewUserService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore) UserServiceInterface {
+	return newUserService(logger, env, db)
+}
+
+func newUserService(logger *logger.Logger, env *env.Env, db *mysql.MySqlStore) *UserService {
 	return &UserService{
-		logger: logger,
-		db:     db,
+		logger:         logger,
+		db:             db,
+		passwordPolicy: NewPasswordPolicy(env, logger),
 	}
 }
 
-func (s *UserService) RegisterUser(user *models.User, password string) error {
+func (s *UserService) RegisterUser(ctx context.Context, user *models.User, password string, customFields map[string]interface{}) error {
+	return s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.createUser(tx, user, password, customFields)
+	})
+}
+
+// createUser applies password policy within given transaction, JoinDate defaults to now.
+// Onboarding checklists start right away with due dates counted from JoinDate
+func (s *UserService) createUser(tx *gorm.DB, user *models.User, password string, customFields map[string]interface{}) error {
+	if err := s.passwordPolicy.Validate(password, user); err != nil {
+		return err
+	}
+
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	now := time.Now()
 	user.PasswordEncrypt = string(hashedPassword)
-	user.JoinDate = time.Now()
+	user.PasswordChangedAt = &now
 
-	if err := s.db.DB().Create(&user).Error; err != nil {
-		s.logger.Error("Create User Failed", zap.Error(err))
+	if err := s.insertUser(tx, user, customFields); err != nil {
 		return err
 	}
+	return s.recordPasswordHistory(tx, user)
+}
 
+// insertUser saves user whose password is already set with its custom fields, required ones have to be given.
+// Employment record and onboarding start from JoinDate
+func (s *UserService) insertUser(tx *gorm.DB, user *models.User, customFields map[string]interface{}) error {
+	if user.JoinDate.IsZero() {
+		user.JoinDate = time.Now()
+	}
+	if user.LifecycleState == "" {
+		user.LifecycleState = initialLifecycleState(user)
+	}
+
+	if err := tx.Create(&user).Error; err != nil {
+		s.logger.Error("Create User Failed", zap.Error(err))
+		return err
+	}
+	if err := customfield_services.SaveCustomFieldValues(tx, customfield_constants.CUSTOM_FIELD_ENTITY_USER, user.ID, customFields, true); err != nil {
+		return err
+	}
+	if err := recordCurrentEmployment(tx, user.ID); err != nil {
+		s.logger.Error("Cannot Record Employment", zap.Error(err))
+		return err
+	}
+	if err := startChecklists(tx, user, constants.CHECKLIST_KIND_ONBOARDING, user.JoinDate); err != nil {
+		s.logger.Error("Cannot Start Onboarding", zap.Error(err))
+		return err
+	}
 	return nil
 }
 
+// userFilters are query parameters of user list and export, search matches name and email
+var userFilters = []utils.Filter{
+	{Param: constants.USER_FILTER_DEPARTMENT, Column: "department_id", Type: utils.FILTER_TYPE_INT},
+	{Param: constants.USER_FILTER_ROLE, Column: "role_id", Type: utils.FILTER_TYPE_INT},
+	{Param: constants.USER_FILTER_STATUS, Column: "lifecycle_state", Type: utils.FILTER_TYPE_STRING, Values: constants.LIFECYCLE_STATES},
+	{Param: constants.USER_FILTER_JOIN_DATE_FROM, Column: "join_date", Type: utils.FILTER_TYPE_DATE, Operator: utils.FILTER_OPERATOR_FROM},
+	{Param: constants.USER_FILTER_JOIN_DATE_TO, Column: "join_date", Type: utils.FILTER_TYPE_DATE, Operator: utils.FILTER_OPERATOR_TO},
+	{Param: constants.USER_FILTER_MANAGER, Type: utils.FILTER_TYPE_INT, Scope: func(db *gorm.DB, values []interface{}) *gorm.DB {
+		return db.Where("id IN (?)", models.ManagedUserIDsQuery(db.Session(&gorm.Session{NewDB: true}), values))
+	}},
+	{Param: constants.USER_FILTER_SALARY_MIN, Column: "salary", Type: utils.FILTER_TYPE_NUMBER, Operator: utils.FILTER_OPERATOR_FROM},
+	{Param: constants.USER_FILTER_SALARY_MAX, Column: "salary", Type: utils.FILTER_TYPE_NUMBER, Operator: utils.FILTER_OPERATOR_TO},
+}
+
+var userSearchColumns = []string{"name", "email"}
+
+// userFilterScope parses userFilters along with filters on custom fields of users
+func userFilterScope(db *gorm.DB, pagination *utils.Pagination) (func(db *gorm.DB) *gorm.DB, error) {
+	customFilters, err := customfield_services.CustomFieldFilters(db, customfield_constants.CUSTOM_FIELD_ENTITY_USER, *pagination)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.FilterScope(append(slices.Clip(userFilters), customFilters...), userSearchColumns...)
+}
+
+// userSortFields leave out salary and date of birth, order would reveal them
+var userSortFields = utils.SortFields{
+	"name":     "name",
+	"email":    "email",
+	"joinDate": "join_date",
+	"status":   "lifecycle_state",
+}
+
 func (s *UserService) FindUsers(pagination *utils.Pagination) ([]models.User, int64, error) {
 	var users []models.User
 	var totalCount int64 = 0
 
-	if err := models.ValidScope(s.db.DB()).Count(&totalCount).Error; err != nil {
+	filter, err := userFilterScope(s.db.DB(), pagination)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	err := models.ValidScope(s.db.DB()).Limit(pagination.Limit).Offset(pagination.Offset()).Order(pagination.Sort).Find(&users).Error
+	if err := models.ValidScope(s.db.DB()).Scopes(filter).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err = pagination.FindPage(models.ValidScope(s.db.DB()).Scopes(filter).Preload("CustomFieldValues.Field"), userSortFields, &users)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -75,7 +167,7 @@ func (s *UserService) FindUserByEmail(email string) (*models.User, error) {
 
 func (s *UserService) FindUserByID(userId int) (*models.User, error) {
 	var user *models.User
-	if err := models.ValidScope(s.db.DB()).Preload("Role").Preload("Department").First(&user, userId).Error; err != nil {
+	if err := models.ValidScope(s.db.DB()).Preload("Role").Preload("Department").Preload("CustomFieldValues.Field").First(&user, userId).Error; err != nil {
 		s.logger.Error("Cannot Not Find User by ID", zap.Error(err))
 		return nil, err
 	}
@@ -83,71 +175,184 @@ func (s *UserService) FindUserByID(userId int) (*models.User, error) {
 	return user, nil
 }
 
-func (s *UserService) UpdateUserByID(userId int, payload dtos.UpdateUserRequest) (*models.User, error) {
-	var user *models.User
-	if err := models.ValidScope(s.db.DB()).First(&user, userId).Updates(payload).Error; err != nil {
+func (s *UserService) UpdateUserByID(ctx context.Context, userId int, payload dtos.UpdateUserRequest) (*models.User, error) {
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// role and status decide who is admin
+		if payload.RoleID != nil || payload.Status != nil {
+			return auth_services.GuardLastAdmin(tx, func(tx *gorm.DB) error {
+				return s.updateUser(tx, userId, payload)
+			})
+		}
+		return s.updateUser(tx, userId, payload)
+	})
+	if err != nil {
 		s.logger.Error("Cannot Update User Data", zap.Error(err))
 		return nil, err
 	}
 
-	if err := s.changeUserDepartment(user, payload.DepartmentID); err != nil {
-		return nil, err
-	}
-
 	return s.FindUserByID(userId)
 }
 
-func (s *UserService) DeleteUserByID(userId int) error {
+func (s *UserService) updateUser(tx *gorm.DB, userId int, payload dtos.UpdateUserRequest) error {
 	var user *models.User
-	if err := models.ValidScope(s.db.DB()).First(&user, userId).Update("status", "removed").Error; err != nil {
-		s.logger.Error("Cannot Delete User", zap.Error(err))
+	if err := models.ValidScope(tx).First(&user, userId).Updates(payload).Error; err != nil {
 		return err
 	}
-
-	if user.DepartmentID != nil {
-		var department *department_models.Department
-		if err := department_models.ValidScope(s.db.DB()).First(&department, *user.DepartmentID).Error; err != nil {
-			s.logger.Error("Cannot Find User's Department", zap.Error(err))
+	if err := customfield_services.SaveCustomFieldValues(tx, customfield_constants.CUSTOM_FIELD_ENTITY_USER, user.ID, payload.CustomFields, false); err != nil {
+		return err
+	}
+	if err := s.changeUserDepartment(tx, user, payload.DepartmentID); err != nil {
+		return err
+	}
+	// changes made on user directly are kept in employment history as well
+	if payload.DepartmentID != nil || payload.Salary != nil {
+		if err := recordCurrentEmployment(tx, user.ID); err != nil {
+			s.logger.Error("Cannot Record Employment", zap.Error(err))
 			return err
 		}
+	}
+	return nil
+}
 
-		if err := department.UpdateEmployCount(s.db.DB(), -1); err != nil {
-			s.logger.Error("Cannot Update Old Department Employ Count", zap.Error(err))
-			return err
-		}
+func (s *UserService) DeleteUserByID(ctx context.Context, userId int) error {
+	err := s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return auth_services.GuardLastAdmin(tx, func(tx *gorm.DB) error {
+			var user *models.User
+			if err := models.ValidScope(tx).First(&user, userId).Update("status", "removed").Error; err != nil {
+				return err
+			}
+			if user.DepartmentID == nil {
+				return nil
+			}
+
+			var department *department_models.Department
+			if err := department_models.ValidScope(tx).First(&department, *user.DepartmentID).Error; err != nil {
+				s.logger.Error("Cannot Find User's Department", zap.Error(err))
+				return err
+			}
+			if err := department.UpdateEmployCount(tx, -1); err != nil {
+				s.logger.Error("Cannot Update Old Department Employ Count", zap.Error(err))
+				return err
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Error("Cannot Delete User", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
-func (s *UserService) UpdatePassword(user *models.User, newPassword string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(user.PasswordEncrypt), []byte(newPassword))
-	// err nil means new password is same as old
-	if err == nil {
-		s.logger.Error("User does not change password")
-		return nil
+func (s *UserService) UpdatePassword(ctx context.Context, user *models.User, newPassword string) error {
+	if err := s.checkNewPassword(user, newPassword); err != nil {
+		return err
+	}
+	return s.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.savePassword(tx, user, newPassword)
+	})
+}
+
+// checkNewPassword applies password policy and history to a password replacing the current one
+func (s *UserService) checkNewPassword(user *models.User, newPassword string) error {
+	if err := s.passwordPolicy.Validate(newPassword, user); err != nil {
+		return err
 	}
 
+	reused, err := s.isPasswordReused(user, newPassword)
+	if err != nil {
+		return err
+	}
+	if reused {
+		return &PasswordPolicyError{Violations: []PasswordViolation{{
+			Rule:    constants.PASSWORD_RULE_REUSED,
+			Message: "password must not be one of your recently used passwords",
+		}}}
+	}
+	return nil
+}
+
+// savePassword stores password checked by checkNewPassword within given transaction
+func (s *UserService) savePassword(tx *gorm.DB, user *models.User, newPassword string) error {
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-	err = models.ValidScope(s.db.DB()).First(&user, user.ID).Update("PasswordEncrypt", hashedPassword).Error
+	now := time.Now()
+	err := models.ValidScope(tx).First(&user, user.ID).Updates(map[string]interface{}{
+		"password_encrypt":    string(hashedPassword),
+		"password_changed_at": now,
+	}).Error
 	if err != nil {
 		s.logger.Error("Cannot Update Password", zap.Error(err))
 		return err
 	}
-	return nil
+
+	user.PasswordEncrypt = string(hashedPassword)
+	user.PasswordChangedAt = &now
+	return s.recordPasswordHistory(tx, user)
+}
+
+func (s *UserService) IsPasswordExpired(user *models.User) bool {
+	return user.PasswordExpired(s.passwordPolicy.MaxAge)
+}
+
+// current password always counts as used, even when history is disabled
+func (s *UserService) isPasswordReused(user *models.User, password string) (bool, error) {
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordEncrypt), []byte(password)) == nil {
+		return true, nil
+	}
+	if s.passwordPolicy.HistorySize <= 0 {
+		return false, nil
+	}
+
+	var histories []models.PasswordHistory
+	err := s.db.DB().Where("user_id = ?", user.ID).Order("id desc").Limit(s.passwordPolicy.HistorySize).Find(&histories).Error
+	if err != nil {
+		s.logger.Error("Cannot Find Password History", zap.Error(err))
+		return false, err
+	}
+
+	for _, history := range histories {
+		if bcrypt.CompareHashAndPassword([]byte(history.PasswordEncrypt), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *UserService) recordPasswordHistory(tx *gorm.DB, user *models.User) error {
+	if s.passwordPolicy.HistorySize <= 0 {
+		return nil
+	}
+
+	history := &models.PasswordHistory{UserID: user.ID, PasswordEncrypt: user.PasswordEncrypt}
+	if err := tx.Create(history).Error; err != nil {
+		s.logger.Error("Cannot Record Password History", zap.Error(err))
+		return err
+	}
+
+	// keep only the latest HistorySize hashes
+	var staleIDs []uint
+	err := tx.Model(&models.PasswordHistory{}).Where("user_id = ?", user.ID).Order("id desc").Offset(s.passwordPolicy.HistorySize).Pluck("id", &staleIDs).Error
+	if err != nil {
+		return err
+	}
+	if len(staleIDs) == 0 {
+		return nil
+	}
+	return tx.Delete(&models.PasswordHistory{}, staleIDs).Error
 }
 
-func (s *UserService) changeUserDepartment(user *models.User, newDeploymentId *int) error {
+func (s *UserService) changeUserDepartment(tx *gorm.DB, user *models.User, newDeploymentId *int) error {
 	if newDeploymentId != nil {
 		var newDepartment *department_models.Department
-		if err := department_models.ValidScope(s.db.DB()).First(&newDepartment, &newDeploymentId).Error; err != nil {
+		if err := department_models.ValidScope(tx).First(&newDepartment, &newDeploymentId).Error; err != nil {
 			s.logger.Error("Cannot Find Updating Department", zap.Error(err))
 			return err
 		}
 
 		// user not has department
 		if user.DepartmentID == nil {
-			if err := newDepartment.UpdateEmployCount(s.db.DB(), 1); err != nil {
+			if err := newDepartment.UpdateEmployCount(tx, 1); err != nil {
 				s.logger.Error("Cannot Update New Department Employ Count", zap.Error(err))
 				return err
 			}
@@ -155,11 +360,11 @@ func (s *UserService) changeUserDepartment(user *models.User, newDeploymentId *i
 		// user change department
 		if user.DepartmentID != nil && *user.DepartmentID != uint(*newDeploymentId) {
 			var oldDepartment *department_models.Department
-			if err := department_models.ValidScope(s.db.DB()).First(&oldDepartment, &user.DepartmentID).Error; err != nil {
+			if err := department_models.ValidScope(tx).First(&oldDepartment, &user.DepartmentID).Error; err != nil {
 				s.logger.Error("Cannot Find User's Department", zap.Error(err))
 				return err
 			}
-			if err := oldDepartment.UpdateEmployCount(s.db.DB(), -1); err != nil {
+			if err := oldDepartment.UpdateEmployCount(tx, -1); err != nil {
 				s.logger.Error("Cannot Update Old Department Employ Count", zap.Error(err))
 				return err
 			}
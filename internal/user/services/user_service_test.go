@@ -1,19 +1,33 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"hr-system-go/app/plugins/env"
 	"hr-system-go/app/plugins/logger"
+	"hr-system-go/app/plugins/mailer"
 	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/app/plugins/storage"
+	auth_constants "hr-system-go/internal/auth/constants"
 	auth_models "hr-system-go/internal/auth/models"
+	auth_services "hr-system-go/internal/auth/services"
+	customfield_constants "hr-system-go/internal/customfield/constants"
+	customfield_models "hr-system-go/internal/customfield/models"
+	customfield_services "hr-system-go/internal/customfield/services"
 	department_models "hr-system-go/internal/department/models"
+	"hr-system-go/internal/user/constants"
 	"hr-system-go/internal/user/dtos"
 	"hr-system-go/internal/user/models"
 	"hr-system-go/utils"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker/v3"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/fx/fxtest"
 )
 
 func TestUserService(t *testing.T) {
@@ -22,17 +36,39 @@ func TestUserService(t *testing.T) {
 }
 
 var (
-	userService UserServiceInterface
-	mockEnv     *env.Env
-	mockLogger  *logger.Logger
-	mockDB      *mysql.MySqlStore
+	userService          UserServiceInterface
+	invitationService    InvitationServiceInterface
+	emailService         EmailVerificationServiceInterface
+	passwordResetService PasswordResetServiceInterface
+	profileService       ProfileServiceInterface
+	employmentService    EmploymentServiceInterface
+	lifecycleService     LifecycleServiceInterface
+	checklistService     ChecklistServiceInterface
+	contractService      ContractServiceInterface
+	userImportService    UserImportServiceInterface
+	directoryService     DirectoryServiceInterface
+	documentService      DocumentServiceInterface
+	mockEnv              *env.Env
+	mockLogger           *logger.Logger
+	mockDB               *mysql.MySqlStore
 )
 
 var _ = BeforeSuite(func() {
 	mockEnv = env.NewEnv()
 	mockLogger = logger.NewLogger(mockEnv)
 	mockDB = mysql.NewMySqlStore(mockEnv, mockLogger)
-	userService = NewUserService(mockLogger, mockDB)
+	userService = NewUserService(mockLogger, mockEnv, mockDB)
+	invitationService = NewInvitationService(mockLogger, mockEnv, mockDB, mailer.NewMailer(mockEnv, mockLogger))
+	emailService = NewEmailVerificationService(mockLogger, mockEnv, mockDB, mailer.NewMailer(mockEnv, mockLogger))
+	passwordResetService = NewPasswordResetService(mockLogger, mockEnv, mockDB, mailer.NewMailer(mockEnv, mockLogger))
+	profileService = NewProfileService(mockLogger, mockDB)
+	employmentService = NewEmploymentService(mockLogger, mockEnv, mockDB, fxtest.NewLifecycle(GinkgoT()))
+	lifecycleService = NewLifecycleService(mockLogger, mockEnv, mockDB, fxtest.NewLifecycle(GinkgoT()))
+	checklistService = NewChecklistService(mockLogger, mockDB)
+	userImportService = NewUserImportService(mockLogger, mockEnv, mockDB, mailer.NewMailer(mockEnv, mockLogger))
+	contractService = NewContractService(mockLogger, mockEnv, mockDB, mailer.NewMailer(mockEnv, mockLogger), fxtest.NewLifecycle(GinkgoT()))
+	directoryService = NewDirectoryService(mockLogger, mockDB, storage.NewStorageWithDriver(mockLogger, storage.NewLocalDriver(GinkgoT().TempDir())))
+	documentService = NewDocumentService(mockLogger, mockEnv, mockDB, storage.NewStorageWithDriver(mockLogger, storage.NewLocalDriver(GinkgoT().TempDir())), mailer.NewMailer(mockEnv, mockLogger), fxtest.NewLifecycle(GinkgoT()))
 
 	mockDB.Connect(
 		mockEnv.GetEnv("DB_USER"),
@@ -43,11 +79,11 @@ var _ = BeforeSuite(func() {
 		mockEnv.GetEnv("DB_PARAMS"),
 	)
 
-	mockDB.DB().AutoMigrate(&models.User{}, &auth_models.Role{}, &department_models.Department{})
+	mockDB.DB().AutoMigrate(&models.User{}, &models.PasswordHistory{}, &models.Invitation{}, &models.EmailVerification{}, &models.PasswordReset{}, &models.Profile{}, &models.EmploymentRecord{}, &models.Termination{}, &models.ChecklistTemplate{}, &models.ChecklistTemplateTask{}, &models.Checklist{}, &models.ChecklistTask{}, &models.Contract{}, &models.DirectoryEntry{}, &models.DocumentCategory{}, &models.Document{}, &auth_models.Session{}, &auth_models.Role{}, &auth_models.Ability{}, &auth_models.UserRole{}, &auth_models.UserAbility{}, &department_models.Department{}, &customfield_models.CustomField{}, &customfield_models.CustomFieldValue{})
 })
 
 var _ = AfterSuite(func() {
-	mockDB.DB().Migrator().DropTable(&models.User{}, &auth_models.Role{}, &department_models.Department{})
+	mockDB.DB().Migrator().DropTable(&models.User{}, &models.PasswordHistory{}, &models.Invitation{}, &models.EmailVerification{}, &models.PasswordReset{}, &models.Profile{}, &models.EmploymentRecord{}, &models.Termination{}, &models.ChecklistTemplate{}, &models.ChecklistTemplateTask{}, &models.Checklist{}, &models.ChecklistTask{}, &models.Contract{}, &models.DirectoryEntry{}, &models.DocumentCategory{}, &models.Document{}, &auth_models.Session{}, &auth_models.Role{}, &auth_models.Ability{}, &auth_models.UserRole{}, &auth_models.UserAbility{}, &department_models.Department{}, &customfield_models.CustomField{}, &customfield_models.CustomFieldValue{})
 	mockDB.Close()
 })
 
@@ -58,13 +94,84 @@ var _ = Describe("UserService", func() {
 				Name:  "John Doe",
 				Email: faker.Email(),
 			}
-			password := "password123"
+			password := "Sunflower2024"
 
-			err := userService.RegisterUser(user, password)
+			err := userService.RegisterUser(context.Background(), user, password, nil)
 
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(user.PasswordEncrypt).ShouldNot(BeEmpty())
 			Expect(user.JoinDate).ShouldNot(BeZero())
+			Expect(user.PasswordChangedAt).ShouldNot(BeNil())
+		})
+
+		It("should reject password which violates policy", func() {
+			user := &models.User{
+				Name:  "John Doe",
+				Email: faker.Email(),
+			}
+
+			err := userService.RegisterUser(context.Background(), user, "johndoe", nil)
+
+			var policyErr *PasswordPolicyError
+			Expect(errors.As(err, &policyErr)).To(BeTrue())
+			rules := []string{}
+			for _, violation := range policyErr.Violations {
+				rules = append(rules, violation.Rule)
+			}
+			Expect(rules).To(ContainElements(
+				constants.PASSWORD_RULE_MIN_LENGTH,
+				constants.PASSWORD_RULE_UPPERCASE,
+				constants.PASSWORD_RULE_DIGIT,
+				constants.PASSWORD_RULE_PERSONAL_INFO,
+			))
+			Expect(user.ID).To(BeZero())
+		})
+
+		It("should reject common password", func() {
+			user := &models.User{
+				Name:  "John Doe",
+				Email: faker.Email(),
+			}
+
+			err := userService.RegisterUser(context.Background(), user, "Password123", nil)
+
+			var policyErr *PasswordPolicyError
+			Expect(errors.As(err, &policyErr)).To(BeTrue())
+			Expect(policyErr.Violations[0].Rule).To(Equal(constants.PASSWORD_RULE_COMMON))
+		})
+
+		Describe("with required custom field", func() {
+			BeforeEach(func() {
+				badgeNumber := &customfield_models.CustomField{Entity: customfield_constants.CUSTOM_FIELD_ENTITY_USER, Key: "badgeNumber", Label: "Badge number", Type: customfield_constants.CUSTOM_FIELD_TYPE_NUMBER, Required: true}
+				Expect(mockDB.DB().Create(badgeNumber).Error).To(Succeed())
+			})
+
+			AfterEach(func() {
+				_ = mockDB.DB().Exec("truncate table custom_field").Error
+				_ = mockDB.DB().Exec("truncate table custom_field_value").Error
+			})
+
+			It("should refuse user without required field", func() {
+				user := &models.User{Name: "John Doe", Email: faker.Email()}
+
+				err := userService.RegisterUser(context.Background(), user, "Sunflower2024", nil)
+
+				Expect(err).To(MatchError(customfield_services.ErrCustomFieldValue))
+				Expect(err.Error()).To(Equal("badgeNumber is required"))
+				_, err = userService.FindUserByEmail(user.Email)
+				Expect(err).NotTo(BeNil())
+			})
+
+			It("should save custom fields with the user", func() {
+				user := &models.User{Name: "John Doe", Email: faker.Email()}
+
+				err := userService.RegisterUser(context.Background(), user, "Sunflower2024", map[string]interface{}{"badgeNumber": 1234.0})
+
+				Expect(err).To(BeNil())
+				found, err := userService.FindUserByID(int(user.ID))
+				Expect(err).To(BeNil())
+				Expect(*found.CustomFieldValues[0].NumberValue).To(Equal(1234.0))
+			})
 		})
 	})
 
@@ -97,6 +204,34 @@ var _ = Describe("UserService", func() {
 			Expect(users).To(HaveLen(2))
 			Expect(totalCount).To(Equal(int64(2)))
 		})
+
+		It("should filter users and search name and email", func() {
+			lowSalary, highSalary := 3000.0, 6000.0
+			manager := &models.User{Name: "Manager", Email: faker.Email()}
+			Expect(userService.RegisterUser(context.Background(), manager, "Sunflower2024", nil)).To(Succeed())
+			jane := &models.User{Name: "Jane Smith", Email: "jane.smith@example.com", Salary: &highSalary, JoinDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+			Expect(userService.RegisterUser(context.Background(), jane, "Sunflower2024", nil)).To(Succeed())
+			john := &models.User{Name: "John Doe", Email: faker.Email(), Salary: &lowSalary, JoinDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+			Expect(userService.RegisterUser(context.Background(), john, "Sunflower2024", nil)).To(Succeed())
+			_, err := employmentService.CreateEmploymentRecord(context.Background(), int(jane.ID), dtos.CreateEmploymentRecordRequest{ManagerID: &manager.ID, Reason: "transfer"})
+			Expect(err).To(BeNil())
+
+			find := func(query url.Values) []models.User {
+				users, totalCount, err := userService.FindUsers(&utils.Pagination{Limit: 10, Sort: "id asc", Search: query.Get("search"), Filters: query})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(totalCount).To(Equal(int64(len(users))))
+				return users
+			}
+
+			Expect(find(url.Values{"salaryMin": {"5000"}})).To(ConsistOf(HaveField("Name", "Jane Smith")))
+			Expect(find(url.Values{"joinDateTo": {"2025-03-01"}})).To(ConsistOf(HaveField("Name", "John Doe")))
+			Expect(find(url.Values{"search": {"smith@"}})).To(ConsistOf(HaveField("Name", "Jane Smith")))
+			Expect(find(url.Values{"managerId": {fmt.Sprint(manager.ID)}})).To(ConsistOf(HaveField("Name", "Jane Smith")))
+			Expect(find(url.Values{"search": {"%"}})).To(BeEmpty())
+
+			_, _, err = userService.FindUsers(&utils.Pagination{Filters: url.Values{"status": {"retired"}}})
+			Expect(err).To(MatchError(utils.ErrFilterValue))
+		})
 	})
 
 	Describe("FindUserByEmail", func() {
@@ -148,7 +283,7 @@ var _ = Describe("UserService", func() {
 			mockDB.DB().Create(&mockUser)
 
 			mockDB.DB().Create(&mockDepartment)
-			user, err := userService.UpdateUserByID(int(mockUser.ID), payload)
+			user, err := userService.UpdateUserByID(context.Background(), int(mockUser.ID), payload)
 			var department *department_models.Department
 			mockDB.DB().First(&department, departmentID)
 
@@ -171,7 +306,7 @@ var _ = Describe("UserService", func() {
 			}
 			mockDB.DB().Create(&mockUser)
 
-			err := userService.DeleteUserByID(int(mockUser.ID))
+			err := userService.DeleteUserByID(context.Background(), int(mockUser.ID))
 
 			var department *department_models.Department
 			mockDB.DB().First(&department, mockDepartment.ID)
@@ -179,6 +314,24 @@ var _ = Describe("UserService", func() {
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(department.EmployCount).To(Equal(0))
 		})
+
+		It("should not delete the last admin", func() {
+			mockDB.DB().Exec("UPDATE user SET role_id = NULL")
+			mockDB.DB().Exec("DELETE FROM user_role")
+			mockDB.DB().Exec("DELETE FROM user_ability")
+			var adminAbility auth_models.Ability
+			mockDB.DB().Where("name = ?", auth_constants.ABILITY_ADMIN).FirstOrCreate(&adminAbility, auth_models.Ability{Name: auth_constants.ABILITY_ADMIN})
+			adminRole := &auth_models.Role{Name: "Only Admin", Abilities: []auth_models.Ability{adminAbility}}
+			mockDB.DB().Create(adminRole)
+			adminUser := &models.User{Email: faker.Email(), RoleID: &adminRole.ID}
+			mockDB.DB().Create(adminUser)
+
+			err := userService.DeleteUserByID(context.Background(), int(adminUser.ID))
+
+			Expect(err).To(MatchError(auth_services.ErrLastAdmin))
+			_, err = userService.FindUserByID(int(adminUser.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+		})
 	})
 
 	Describe("UpdatePassword", func() {
@@ -188,10 +341,36 @@ var _ = Describe("UserService", func() {
 				PasswordEncrypt: "$2a$10$abcdefghijklmnopqrstuvwxyz012345",
 			}
 			mockDB.DB().Create(&mockUser)
-			newPassword := "newpassword123"
+			newPassword := "Blueberry2024"
 
-			err := userService.UpdatePassword(mockUser, newPassword)
+			err := userService.UpdatePassword(context.Background(), mockUser, newPassword)
 			Expect(err).ShouldNot(HaveOccurred())
 		})
+
+		It("should reject the current and recently used passwords", func() {
+			mockUser := &models.User{
+				Name:  "Reuse",
+				Email: faker.Email(),
+			}
+			Expect(userService.RegisterUser(context.Background(), mockUser, "Blueberry2024", nil)).ShouldNot(HaveOccurred())
+			Expect(userService.UpdatePassword(context.Background(), mockUser, "Raspberry2024")).ShouldNot(HaveOccurred())
+
+			err := userService.UpdatePassword(context.Background(), mockUser, "Raspberry2024")
+			Expect(errors.Is(err, ErrPasswordPolicy)).To(BeTrue())
+
+			err = userService.UpdatePassword(context.Background(), mockUser, "Blueberry2024")
+			var policyErr *PasswordPolicyError
+			Expect(errors.As(err, &policyErr)).To(BeTrue())
+			Expect(policyErr.Violations[0].Rule).To(Equal(constants.PASSWORD_RULE_REUSED))
+		})
+	})
+
+	Describe("IsPasswordExpired", func() {
+		It("should not expire password when max age is disabled", func() {
+			changedAt := time.Now().AddDate(-5, 0, 0)
+			mockUser := &models.User{PasswordChangedAt: &changedAt}
+
+			Expect(userService.IsPasswordExpired(mockUser)).To(BeFalse())
+		})
 	})
 })
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	user_models "hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApiKeyService struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyService) CreateServiceAccount(ctx context.Context, payload dtos.CreateServiceAccountRequest) (*user_models.User, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user_models.User), args.Error(1)
+}
+
+func (m *MockApiKeyService) FindServiceAccounts(pagination *utils.Pagination) ([]user_models.User, int64, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]user_models.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockApiKeyService) FindApiKeys(userID int) ([]models.ApiKey, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyService) CreateApiKey(userID int, createdByID uint, payload dtos.CreateApiKeyRequest) (*models.ApiKey, string, error) {
+	args := m.Called(userID, createdByID, payload)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).(*models.ApiKey), args.String(1), args.Error(2)
+}
+
+func (m *MockApiKeyService) RevokeApiKey(userID int, keyID int) error {
+	args := m.Called(userID, keyID)
+	return args.Error(0)
+}
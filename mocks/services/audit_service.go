@@ -0,0 +1,33 @@
+package services
+
+import (
+	"hr-system-go/app/plugins/mysql"
+	"hr-system-go/internal/audit/dtos"
+	"hr-system-go/internal/audit/models"
+	"hr-system-go/utils"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) EnableAudit() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockAuditService) Record(db *gorm.DB, entries []mysql.AuditEntry) error {
+	args := m.Called(db, entries)
+	return args.Error(0)
+}
+
+func (m *MockAuditService) FindAuditLogs(filter dtos.FindAuditLogsRequest, pagination *utils.Pagination) ([]models.AuditLog, int64, error) {
+	args := m.Called(filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.AuditLog), args.Get(1).(int64), args.Error(2)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	auth_models "hr-system-go/internal/auth/models"
 	"hr-system-go/internal/user/models"
 
 	"github.com/gin-gonic/gin"
@@ -23,17 +24,52 @@ func (m *MockAuthService) AuthUserAbilityWrapper(handler gin.HandlerFunc, abilit
 	}
 }
 
-func (m *MockAuthService) AbleToAccessOtherUserData(ctx *gin.Context, userID int, ability string) bool {
-	args := m.Called(ctx, userID, ability)
-	return args.Bool(0)
-}
-
 func (m *MockAuthService) GetCurrentUser(ctx *gin.Context) *models.User {
 	args := m.Called(ctx)
 	return args.Get(0).(*models.User)
 }
 
-func (m *MockAuthService) GenerateToken(userID uint, username string) (string, error) {
+func (m *MockAuthService) GetCurrentUserAbilities(ctx *gin.Context) []string {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]string)
+}
+
+func (m *MockAuthService) GenerateToken(ctx *gin.Context, userID uint, username string) (string, error) {
 	args := m.Called(userID, username)
 	return args.String(0), args.Error(1)
 }
+
+func (m *MockAuthService) GetCurrentApiKey(ctx *gin.Context) *auth_models.ApiKey {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*auth_models.ApiKey)
+}
+
+func (m *MockAuthService) GetActor(ctx *gin.Context) *models.User {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*models.User)
+}
+
+func (m *MockAuthService) GetCurrentSession(ctx *gin.Context) *auth_models.Session {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*auth_models.Session)
+}
+
+func (m *MockAuthService) GetImpersonation(ctx *gin.Context) *auth_models.Impersonation {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*auth_models.Impersonation)
+}
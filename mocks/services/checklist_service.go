@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockChecklistService struct {
+	mock.Mock
+}
+
+func (m *MockChecklistService) FindTemplates(kind string) ([]models.ChecklistTemplate, error) {
+	args := m.Called(kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ChecklistTemplate), args.Error(1)
+}
+
+func (m *MockChecklistService) CreateTemplate(ctx context.Context, payload dtos.ChecklistTemplateRequest) (*models.ChecklistTemplate, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChecklistTemplate), args.Error(1)
+}
+
+func (m *MockChecklistService) UpdateTemplate(ctx context.Context, templateID int, payload dtos.ChecklistTemplateRequest) (*models.ChecklistTemplate, error) {
+	args := m.Called(templateID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChecklistTemplate), args.Error(1)
+}
+
+func (m *MockChecklistService) DeleteTemplate(ctx context.Context, templateID int) error {
+	args := m.Called(templateID)
+	return args.Error(0)
+}
+
+func (m *MockChecklistService) FindChecklists(userID int) ([]models.Checklist, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Checklist), args.Error(1)
+}
+
+func (m *MockChecklistService) FindTasks(filter dtos.ChecklistTaskFilter, pagination *utils.Pagination) ([]models.ChecklistTask, int64, error) {
+	args := m.Called(filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.ChecklistTask), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockChecklistService) FindTask(taskID int) (*models.ChecklistTask, error) {
+	args := m.Called(taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChecklistTask), args.Error(1)
+}
+
+func (m *MockChecklistService) CompleteTask(ctx context.Context, taskID int, completedByID uint) (*models.ChecklistTask, error) {
+	args := m.Called(taskID, completedByID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChecklistTask), args.Error(1)
+}
+
+func (m *MockChecklistService) ReopenTask(ctx context.Context, taskID int) (*models.ChecklistTask, error) {
+	args := m.Called(taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChecklistTask), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/internal/attendance/models"
 	user_models "hr-system-go/internal/user/models"
 	"hr-system-go/utils"
@@ -17,7 +18,7 @@ func (m *MockClockRecordService) FindClockRecordsByUserID(userID int, pagination
 	return args.Get(0).([]models.ClockRecord), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockClockRecordService) ClockByUser(user *user_models.User) (*models.ClockRecord, error) {
+func (m *MockClockRecordService) ClockByUser(ctx context.Context, user *user_models.User) (*models.ClockRecord, error) {
 	args := m.Called(user)
 	return args.Get(0).(*models.ClockRecord), args.Error(1)
 }
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockContractService struct {
+	mock.Mock
+}
+
+func (m *MockContractService) FindContracts(userID int) ([]models.Contract, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Contract), args.Error(1)
+}
+
+func (m *MockContractService) FindUpcomingContracts(until time.Time) ([]models.Contract, error) {
+	args := m.Called(until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Contract), args.Error(1)
+}
+
+func (m *MockContractService) CreateContract(ctx context.Context, userID int, payload dtos.CreateContractRequest) (*models.Contract, error) {
+	args := m.Called(userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contract), args.Error(1)
+}
+
+func (m *MockContractService) DecideProbation(ctx context.Context, decidedByID uint, userID int, payload dtos.ProbationDecisionRequest) (*models.Contract, error) {
+	args := m.Called(decidedByID, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contract), args.Error(1)
+}
+
+func (m *MockContractService) SendDueReminders(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
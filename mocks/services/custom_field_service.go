@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/customfield/dtos"
+	"hr-system-go/internal/customfield/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCustomFieldService struct {
+	mock.Mock
+}
+
+func (m *MockCustomFieldService) FindFields(entity string) ([]models.CustomField, error) {
+	args := m.Called(entity)
+	return args.Get(0).([]models.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldService) CreateField(ctx context.Context, payload dtos.CreateCustomFieldRequest) (*models.CustomField, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldService) UpdateField(ctx context.Context, fieldID int, payload dtos.UpdateCustomFieldRequest) (*models.CustomField, error) {
+	args := m.Called(fieldID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CustomField), args.Error(1)
+}
+
+func (m *MockCustomFieldService) DeleteField(ctx context.Context, fieldID int) error {
+	args := m.Called(fieldID)
+	return args.Error(0)
+}
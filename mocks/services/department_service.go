@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/internal/department/dtos"
 	"hr-system-go/internal/department/models"
 	"hr-system-go/utils"
@@ -22,17 +23,17 @@ func (m *MockDepartmentService) FindDepartmentByID(id int) (*models.Department,
 	return args.Get(0).(*models.Department), args.Error(1)
 }
 
-func (m *MockDepartmentService) CreateDepartment(payload dtos.CreateDepartmentRequest) (*models.Department, error) {
+func (m *MockDepartmentService) CreateDepartment(ctx context.Context, payload dtos.CreateDepartmentRequest) (*models.Department, error) {
 	args := m.Called(payload)
 	return args.Get(0).(*models.Department), args.Error(1)
 }
 
-func (m *MockDepartmentService) UpdateDepartmentByID(id int, payload dtos.UpdateDepartmentRequest) (*models.Department, error) {
+func (m *MockDepartmentService) UpdateDepartmentByID(ctx context.Context, id int, payload dtos.UpdateDepartmentRequest) (*models.Department, error) {
 	args := m.Called(id, payload)
 	return args.Get(0).(*models.Department), args.Error(1)
 }
 
-func (m *MockDepartmentService) DeleteDepartmentByID(id int) error {
+func (m *MockDepartmentService) DeleteDepartmentByID(ctx context.Context, id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/utils"
+	"io"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDirectoryService struct {
+	mock.Mock
+}
+
+func (m *MockDirectoryService) FindDirectory(pagination *utils.Pagination) ([]dtos.DirectoryItem, int64, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]dtos.DirectoryItem), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockDirectoryService) FindDirectoryEntry(userID int) (*dtos.DirectoryItem, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DirectoryItem), args.Error(1)
+}
+
+func (m *MockDirectoryService) UpdateDirectoryEntry(ctx context.Context, userID int, payload dtos.UpdateDirectoryEntryRequest) (*dtos.DirectoryItem, error) {
+	args := m.Called(userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DirectoryItem), args.Error(1)
+}
+
+func (m *MockDirectoryService) UploadPhoto(ctx context.Context, userID int, content []byte) (*dtos.DirectoryItem, error) {
+	args := m.Called(userID, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DirectoryItem), args.Error(1)
+}
+
+func (m *MockDirectoryService) DeletePhoto(ctx context.Context, userID int) (*dtos.DirectoryItem, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DirectoryItem), args.Error(1)
+}
+
+func (m *MockDirectoryService) OpenPhoto(ctx context.Context, userID int, size string) (io.ReadCloser, error) {
+	args := m.Called(userID, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
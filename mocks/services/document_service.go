@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"io"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDocumentService struct {
+	mock.Mock
+}
+
+func (m *MockDocumentService) FindCategories() ([]models.DocumentCategory, error) {
+	args := m.Called()
+	return args.Get(0).([]models.DocumentCategory), args.Error(1)
+}
+
+func (m *MockDocumentService) FindCategory(categoryID int) (*models.DocumentCategory, error) {
+	args := m.Called(categoryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentCategory), args.Error(1)
+}
+
+func (m *MockDocumentService) CreateCategory(ctx context.Context, payload dtos.DocumentCategoryRequest) (*models.DocumentCategory, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentCategory), args.Error(1)
+}
+
+func (m *MockDocumentService) UpdateCategory(ctx context.Context, categoryID int, payload dtos.DocumentCategoryRequest) (*models.DocumentCategory, error) {
+	args := m.Called(categoryID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentCategory), args.Error(1)
+}
+
+func (m *MockDocumentService) DeleteCategory(ctx context.Context, categoryID int) error {
+	args := m.Called(categoryID)
+	return args.Error(0)
+}
+
+func (m *MockDocumentService) FindDocuments(userID int) ([]models.Document, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) FindDocument(userID int, documentID int) (*models.Document, error) {
+	args := m.Called(userID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) FindExpiringDocuments(until time.Time) ([]models.Document, error) {
+	args := m.Called(until)
+	return args.Get(0).([]models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) MaxBytes() int64 {
+	args := m.Called()
+	return args.Get(0).(int64)
+}
+
+func (m *MockDocumentService) UploadDocument(ctx context.Context, userID int, payload dtos.UploadDocumentRequest) (*models.Document, error) {
+	args := m.Called(userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) UpdateDocument(ctx context.Context, userID int, documentID int, payload dtos.UpdateDocumentRequest) (*models.Document, error) {
+	args := m.Called(userID, documentID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) DeleteDocument(ctx context.Context, userID int, documentID int) error {
+	args := m.Called(userID, documentID)
+	return args.Error(0)
+}
+
+func (m *MockDocumentService) OpenDocument(ctx context.Context, document *models.Document) (io.ReadCloser, error) {
+	args := m.Called(document)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockDocumentService) SendDueReminders(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Get(0).(int), args.Error(1)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmailVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) RequestEmailChange(ctx context.Context, user *models.User, newEmail string) error {
+	args := m.Called(user, newEmail)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) ConfirmEmail(ctx context.Context, token string) (*models.User, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockEmailVerificationService) VerificationRequired() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
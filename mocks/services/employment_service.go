@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmploymentService struct {
+	mock.Mock
+}
+
+func (m *MockEmploymentService) FindEmploymentRecords(userID int) ([]models.EmploymentRecord, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EmploymentRecord), args.Error(1)
+}
+
+func (m *MockEmploymentService) FindEffectiveRecord(userID int, date time.Time) (*models.EmploymentRecord, error) {
+	args := m.Called(userID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmploymentRecord), args.Error(1)
+}
+
+func (m *MockEmploymentService) CreateEmploymentRecord(ctx context.Context, userID int, payload dtos.CreateEmploymentRecordRequest) (*models.EmploymentRecord, error) {
+	args := m.Called(userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmploymentRecord), args.Error(1)
+}
+
+func (m *MockEmploymentService) CancelEmploymentRecord(ctx context.Context, userID int, recordID int) error {
+	args := m.Called(userID, recordID)
+	return args.Error(0)
+}
+
+func (m *MockEmploymentService) ApplyDueRecords(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
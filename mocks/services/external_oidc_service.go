@@ -0,0 +1,29 @@
+package services
+
+import (
+	user_models "hr-system-go/internal/user/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExternalOIDCService struct {
+	mock.Mock
+}
+
+func (m *MockExternalOIDCService) Enabled() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockExternalOIDCService) AuthorizationURL() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockExternalOIDCService) Login(code string, state string) (*user_models.User, error) {
+	args := m.Called(code, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user_models.User), args.Error(1)
+}
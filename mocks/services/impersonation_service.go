@@ -0,0 +1,32 @@
+package services
+
+import (
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	user_models "hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImpersonationService struct {
+	mock.Mock
+}
+
+func (m *MockImpersonationService) StartImpersonation(actor *user_models.User, userID int, payload dtos.StartImpersonationRequest) (*models.Impersonation, string, error) {
+	args := m.Called(actor, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).(*models.Impersonation), args.String(1), args.Error(2)
+}
+
+func (m *MockImpersonationService) StopImpersonation(impersonation *models.Impersonation) error {
+	args := m.Called(impersonation)
+	return args.Error(0)
+}
+
+func (m *MockImpersonationService) FindImpersonations(pagination *utils.Pagination) ([]models.Impersonation, int64, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]models.Impersonation), args.Get(1).(int64), args.Error(2)
+}
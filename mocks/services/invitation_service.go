@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvitationService struct {
+	mock.Mock
+}
+
+func (m *MockInvitationService) CreateInvitation(ctx context.Context, invitedByID uint, payload dtos.CreateInvitationRequest) (*models.Invitation, error) {
+	args := m.Called(invitedByID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationService) FindInvitations(status string, pagination *utils.Pagination) ([]models.Invitation, int64, error) {
+	args := m.Called(status, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.Invitation), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockInvitationService) RevokeInvitation(ctx context.Context, invitationID int) error {
+	args := m.Called(invitationID)
+	return args.Error(0)
+}
+
+func (m *MockInvitationService) AcceptInvitation(ctx context.Context, payload dtos.AcceptInvitationRequest) (*models.User, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockInvitationService) OpenRegistrationEnabled() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
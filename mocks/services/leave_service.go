@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/internal/attendance/dtos"
 	"hr-system-go/internal/attendance/models"
 	user_models "hr-system-go/internal/user/models"
@@ -23,17 +24,17 @@ func (m *MockLeaveService) FindLeaveByID(leaveID int) (*models.Leave, error) {
 	return args.Get(0).(*models.Leave), args.Error(1)
 }
 
-func (m *MockLeaveService) CreateLeaveByUser(user *user_models.User, payload dtos.CreateLeaveRequest) (*models.Leave, error) {
+func (m *MockLeaveService) CreateLeaveByUser(ctx context.Context, user *user_models.User, payload dtos.CreateLeaveRequest) (*models.Leave, error) {
 	args := m.Called(user, payload)
 	return args.Get(0).(*models.Leave), args.Error(1)
 }
 
-func (m *MockLeaveService) UpdateLeaveByID(leaveID int, payload dtos.UpdateLeaveRequest) (*models.Leave, error) {
+func (m *MockLeaveService) UpdateLeaveByID(ctx context.Context, leaveID int, payload dtos.UpdateLeaveRequest) (*models.Leave, error) {
 	args := m.Called(leaveID, payload)
 	return args.Get(0).(*models.Leave), args.Error(1)
 }
 
-func (m *MockLeaveService) DeleteLeaveByID(leaveID int) error {
+func (m *MockLeaveService) DeleteLeaveByID(ctx context.Context, leaveID int) error {
 	args := m.Called(leaveID)
 	return args.Error(0)
 }
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLifecycleService struct {
+	mock.Mock
+}
+
+func (m *MockLifecycleService) FindLifecycle(userID int) (*models.User, *models.Termination, error) {
+	args := m.Called(userID)
+	var user *models.User
+	if args.Get(0) != nil {
+		user = args.Get(0).(*models.User)
+	}
+	var termination *models.Termination
+	if args.Get(1) != nil {
+		termination = args.Get(1).(*models.Termination)
+	}
+	return user, termination, args.Error(2)
+}
+
+func (m *MockLifecycleService) ChangeLifecycleState(ctx context.Context, userID int, payload dtos.ChangeLifecycleStateRequest) (*models.User, error) {
+	args := m.Called(userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockLifecycleService) TerminateUser(ctx context.Context, recordedByID uint, userID int, payload dtos.TerminateUserRequest) (*models.Termination, error) {
+	args := m.Called(recordedByID, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Termination), args.Error(1)
+}
+
+func (m *MockLifecycleService) WithdrawTermination(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockLifecycleService) RehireUser(ctx context.Context, userID int, payload dtos.RehireUserRequest) (*models.User, error) {
+	args := m.Called(userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockLifecycleService) ApplyDueLifecycle(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	auth_services "hr-system-go/internal/auth/services"
+	"hr-system-go/internal/session/dtos"
+	"hr-system-go/internal/session/models"
+	user_models "hr-system-go/internal/user/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOIDCService struct {
+	mock.Mock
+}
+
+func (m *MockOIDCService) Discovery() map[string]interface{} {
+	args := m.Called()
+	return args.Get(0).(map[string]interface{})
+}
+
+func (m *MockOIDCService) JWKS() (auth_services.JSONWebKeySet, error) {
+	args := m.Called()
+	return args.Get(0).(auth_services.JSONWebKeySet), args.Error(1)
+}
+
+func (m *MockOIDCService) RegisterClient(payload dtos.RegisterOAuthClientRequest) (*models.OAuthClient, string, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).(*models.OAuthClient), args.String(1), args.Error(2)
+}
+
+func (m *MockOIDCService) FindClients() ([]models.OAuthClient, error) {
+	args := m.Called()
+	return args.Get(0).([]models.OAuthClient), args.Error(1)
+}
+
+func (m *MockOIDCService) DeleteClientByID(clientID int) error {
+	args := m.Called(clientID)
+	return args.Error(0)
+}
+
+func (m *MockOIDCService) Authorize(user *user_models.User, request dtos.AuthorizeRequest) (string, error) {
+	args := m.Called(user, request)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOIDCService) ExchangeCode(request dtos.TokenRequest) (*dtos.TokenResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.TokenResponse), args.Error(1)
+}
+
+func (m *MockOIDCService) UserInfo(accessToken string) (map[string]interface{}, error) {
+	args := m.Called(accessToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPasswordResetService struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetService) ResetPassword(ctx context.Context, token string, newPassword string) (*models.User, error) {
+	args := m.Called(token, newPassword)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
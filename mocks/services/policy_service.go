@@ -0,0 +1,31 @@
+package services
+
+import (
+	"hr-system-go/internal/policy/dtos"
+	"hr-system-go/internal/policy/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPolicyService struct {
+	mock.Mock
+}
+
+func (m *MockPolicyService) Authorize(ctx *gin.Context, resource models.Resource, action string) models.Decision {
+	args := m.Called(ctx, resource, action)
+	return args.Get(0).(models.Decision)
+}
+
+func (m *MockPolicyService) Rules() []models.Rule {
+	args := m.Called()
+	return args.Get(0).([]models.Rule)
+}
+
+func (m *MockPolicyService) Explain(payload dtos.ExplainRequest) (*models.Explanation, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Explanation), args.Error(1)
+}
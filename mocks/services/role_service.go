@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/auth/dtos"
+	"hr-system-go/internal/auth/models"
+	"hr-system-go/utils"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleService struct {
+	mock.Mock
+}
+
+func (m *MockRoleService) FindRoles(pagination *utils.Pagination) ([]models.Role, int64, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]models.Role), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRoleService) FindRoleByID(roleID int) (*models.Role, error) {
+	args := m.Called(roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) CreateRole(ctx context.Context, payload dtos.CreateRoleRequest) (*models.Role, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) UpdateRoleByID(ctx context.Context, roleID int, payload dtos.UpdateRoleRequest) (*models.Role, error) {
+	args := m.Called(roleID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) DeleteRoleByID(ctx context.Context, roleID int) error {
+	args := m.Called(roleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) FindAbilities() ([]models.Ability, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Ability), args.Error(1)
+}
+
+func (m *MockRoleService) AddAbilityToRole(ctx context.Context, roleID int, abilityID int) (*models.Role, error) {
+	args := m.Called(roleID, abilityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) RemoveAbilityFromRole(ctx context.Context, roleID int, abilityID int) (*models.Role, error) {
+	args := m.Called(roleID, abilityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) AssignRoleToUser(ctx context.Context, userID int, roleID *int) error {
+	args := m.Called(userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) FindUserRoles(userID int) ([]models.UserRole, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.UserRole), args.Error(1)
+}
+
+func (m *MockRoleService) AddRoleToUser(ctx context.Context, userID int, roleID int, payload dtos.AddUserRoleRequest) (*models.UserRole, error) {
+	args := m.Called(userID, roleID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserRole), args.Error(1)
+}
+
+func (m *MockRoleService) RemoveRoleFromUser(ctx context.Context, userID int, roleID int) error {
+	args := m.Called(userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) FindUserAbilities(userID int) ([]models.UserAbility, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.UserAbility), args.Error(1)
+}
+
+func (m *MockRoleService) SetUserAbility(ctx context.Context, userID int, abilityID int, payload dtos.SetUserAbilityRequest) (*models.UserAbility, error) {
+	args := m.Called(userID, abilityID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserAbility), args.Error(1)
+}
+
+func (m *MockRoleService) RemoveUserAbility(ctx context.Context, userID int, abilityID int) error {
+	args := m.Called(userID, abilityID)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) FindEffectivePermissions(userID int) ([]models.EffectiveAbility, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.EffectiveAbility), args.Error(1)
+}
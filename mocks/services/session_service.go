@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/auth/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) FindSessions(userID int) ([]models.Session, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *MockSessionService) TerminateSession(ctx context.Context, userID int, sessionID int, terminatedByID uint) error {
+	args := m.Called(userID, sessionID, terminatedByID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) TerminateSessions(ctx context.Context, userID int, terminatedByID uint) (int64, error) {
+	args := m.Called(userID, terminatedByID)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"hr-system-go/internal/user/dtos"
+	"hr-system-go/internal/user/models"
+	"hr-system-go/utils"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserImportService struct {
+	mock.Mock
+}
+
+func (m *MockUserImportService) ImportUsers(ctx context.Context, payload dtos.ImportUsersRequest) ([]dtos.UserImportRow, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.UserImportRow), args.Error(1)
+}
+
+func (m *MockUserImportService) ExportUsers(pagination *utils.Pagination) ([]models.User, error) {
+	args := m.Called(pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.User), args.Error(1)
+}
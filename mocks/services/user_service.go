@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"hr-system-go/internal/user/dtos"
 	"hr-system-go/internal/user/models"
 	"hr-system-go/utils"
@@ -12,8 +13,8 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) RegisterUser(user *models.User, password string) error {
-	args := m.Called(user, password)
+func (m *MockUserService) RegisterUser(ctx context.Context, user *models.User, password string, customFields map[string]interface{}) error {
+	args := m.Called(user, password, customFields)
 	return args.Error(0)
 }
 
@@ -38,7 +39,7 @@ func (m *MockUserService) FindUserByID(userId int) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) UpdateUserByID(userId int, payload dtos.UpdateUserRequest) (*models.User, error) {
+func (m *MockUserService) UpdateUserByID(ctx context.Context, userId int, payload dtos.UpdateUserRequest) (*models.User, error) {
 	args := m.Called(userId, payload)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -46,16 +47,21 @@ func (m *MockUserService) UpdateUserByID(userId int, payload dtos.UpdateUserRequ
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) DeleteUserByID(userId int) error {
+func (m *MockUserService) DeleteUserByID(ctx context.Context, userId int) error {
 	args := m.Called(userId)
 	return args.Error(0)
 }
 
-func (m *MockUserService) UpdatePassword(user *models.User, newPassword string) error {
+func (m *MockUserService) UpdatePassword(ctx context.Context, user *models.User, newPassword string) error {
 	args := m.Called(user, newPassword)
 	return args.Error(0)
 }
 
+func (m *MockUserService) IsPasswordExpired(user *models.User) bool {
+	args := m.Called(user)
+	return args.Bool(0)
+}
+
 func (m *MockUserService) changeUserDepartment(user *models.User, newDeploymentId *int) error {
 	args := m.Called(user, newDeploymentId)
 	return args.Error(0)
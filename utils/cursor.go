@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var ErrCursor = errors.New("cursor is invalid, start over without it")
+
+// pageCursor holds sort values of the row at edge of page, Prev walks back from it
+type pageCursor struct {
+	Values []json.RawMessage `json:"v"`
+	Prev   bool              `json:"p,omitempty"`
+}
+
+// FindPage orders query by sort of fields and finds one page into dest, a pointer to slice.
+// In cursor mode rows follow the cursor instead of skipping pages and NextCursor and PrevCursor are set
+func (p *Pagination) FindPage(query *gorm.DB, fields SortFields, dest interface{}) error {
+	orders, err := p.sortOrders(fields)
+	if err != nil {
+		return err
+	}
+	if !p.CursorMode {
+		return query.Order(orderClause(orders, false)).Limit(p.GetLimit()).Offset(p.Offset()).Find(dest).Error
+	}
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(dest); err != nil {
+		return err
+	}
+	sortFields := make([]*schema.Field, len(orders))
+	for i, order := range orders {
+		if sortFields[i] = stmt.Schema.LookUpField(order.column); sortFields[i] == nil {
+			return fmt.Errorf("%s has no column %s", stmt.Schema.Name, order.column)
+		}
+	}
+
+	cursor := pageCursor{}
+	if p.Cursor != "" {
+		if cursor, err = decodeCursor(p.Cursor, len(orders)); err != nil {
+			return err
+		}
+		condition, values, err := keysetCondition(orders, sortFields, cursor)
+		if err != nil {
+			return err
+		}
+		query = query.Where(condition, values...)
+	}
+
+	if err := query.Order(orderClause(orders, cursor.Prev)).Limit(p.GetLimit() + 1).Find(dest).Error; err != nil {
+		return err
+	}
+
+	// the extra row only tells there is more in the direction walked
+	rows := reflect.ValueOf(dest).Elem()
+	more := rows.Len() > p.GetLimit()
+	if more {
+		rows.Set(rows.Slice(0, p.GetLimit()))
+	}
+	if cursor.Prev {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	p.NextCursor, p.PrevCursor = "", ""
+	if rows.Len() == 0 {
+		return nil
+	}
+	hasNext, hasPrev := more, p.Cursor != ""
+	if cursor.Prev {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		if p.NextCursor, err = encodeCursor(query, sortFields, rows.Index(rows.Len()-1), false); err != nil {
+			return err
+		}
+	}
+	if hasPrev {
+		if p.PrevCursor, err = encodeCursor(query, sortFields, rows.Index(0), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keysetCondition matches rows after the cursor in sort order, or before it walking back
+func keysetCondition(orders []sortOrder, fields []*schema.Field, cursor pageCursor) (string, []interface{}, error) {
+	values := make([]interface{}, len(orders))
+	for i, field := range fields {
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(cursor.Values[i], value.Interface()); err != nil {
+			return "", nil, ErrCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+
+	alternatives := make([]string, len(orders))
+	args := []interface{}{}
+	for i, order := range orders {
+		terms := []string{}
+		for j := 0; j < i; j++ {
+			terms = append(terms, orders[j].column+" = ?")
+			args = append(args, values[j])
+		}
+		operator := ">"
+		if order.desc != cursor.Prev {
+			operator = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s ?", order.column, operator))
+		args = append(args, values[i])
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+func encodeCursor(query *gorm.DB, fields []*schema.Field, row reflect.Value, prev bool) (string, error) {
+	cursor := pageCursor{Values: make([]json.RawMessage, len(fields)), Prev: prev}
+	for i, field := range fields {
+		value, _ := field.ValueOf(query.Statement.Context, row)
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values[i] = encoded
+	}
+	encoded, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// decodeCursor refuses cursors of another sort, their values do not line up with columns
+func decodeCursor(value string, columns int) (pageCursor, error) {
+	var cursor pageCursor
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(decoded, &cursor) != nil || len(cursor.Values) != columns {
+		return pageCursor{}, ErrCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	FILTER_TYPE_INT    = "int"
+	FILTER_TYPE_NUMBER = "number"
+	FILTER_TYPE_STRING = "string"
+	FILTER_TYPE_DATE   = "date"
+)
+
+const (
+	// FILTER_OPERATOR_IN matches any of comma separated values, it is the default
+	FILTER_OPERATOR_IN = "in"
+	// FILTER_OPERATOR_FROM and FILTER_OPERATOR_TO bound a range, both ends included
+	FILTER_OPERATOR_FROM = "from"
+	FILTER_OPERATOR_TO   = "to"
+)
+
+var ErrFilterValue = errors.New("filter value is invalid")
+
+// FilterError names query parameter which could not be parsed
+type FilterError struct {
+	Param  string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%s %s", e.Param, e.Reason)
+}
+
+func (e *FilterError) Unwrap() error {
+	return ErrFilterValue
+}
+
+// Filter is query parameter list endpoint accepts, each resource declares its own
+type Filter struct {
+	Param    string
+	Column   string
+	Type     string
+	Operator string
+	// Values limits string filter to known values, like states
+	Values []string
+	// Scope replaces condition on Column for filters needing joins or subqueries, values are parsed already
+	Scope func(db *gorm.DB, values []interface{}) *gorm.DB
+}
+
+// FilterScope parses filters present in query and search, searchColumns match search text anywhere in them.
+// The scope is meant for Count and Find alike
+func (p Pagination) FilterScope(filters []Filter, searchColumns ...string) (func(db *gorm.DB) *gorm.DB, error) {
+	type condition struct {
+		filter Filter
+		values []interface{}
+	}
+	conditions := []condition{}
+	for _, filter := range filters {
+		raw := strings.TrimSpace(p.Filters.Get(filter.Param))
+		if raw == "" {
+			continue
+		}
+		values, err := filter.parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition{filter: filter, values: values})
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, condition := range conditions {
+			filter, values := condition.filter, condition.values
+			switch {
+			case filter.Scope != nil:
+				db = filter.Scope(db, values)
+			case filter.Operator == FILTER_OPERATOR_FROM:
+				db = db.Where(fmt.Sprintf("%s >= ?", filter.Column), values[0])
+			case filter.Operator == FILTER_OPERATOR_TO && filter.Type == FILTER_TYPE_DATE:
+				// dates are whole days, the range ends before the next one starts
+				db = db.Where(fmt.Sprintf("%s < ?", filter.Column), values[0].(time.Time).AddDate(0, 0, 1))
+			case filter.Operator == FILTER_OPERATOR_TO:
+				db = db.Where(fmt.Sprintf("%s <= ?", filter.Column), values[0])
+			default:
+				db = db.Where(fmt.Sprintf("%s IN ?", filter.Column), values)
+			}
+		}
+		if p.Search != "" && len(searchColumns) > 0 {
+			like := "%" + escapeLike(p.Search) + "%"
+			conditions := make([]string, len(searchColumns))
+			args := make([]interface{}, len(searchColumns))
+			for i, column := range searchColumns {
+				conditions[i] = fmt.Sprintf("%s LIKE ?", column)
+				args[i] = like
+			}
+			db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+		}
+		return db
+	}, nil
+}
+
+// HasFilter tells whether any of params was given, like filters needing extra abilities
+func (p Pagination) HasFilter(params ...string) bool {
+	for _, param := range params {
+		if strings.TrimSpace(p.Filters.Get(param)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) parse(raw string) ([]interface{}, error) {
+	parts := []string{raw}
+	if f.Operator == "" || f.Operator == FILTER_OPERATOR_IN {
+		parts = strings.Split(raw, ",")
+	}
+
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		switch f.Type {
+		case FILTER_TYPE_INT:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, &FilterError{Param: f.Param, Reason: "must be whole numbers"}
+			}
+			values = append(values, value)
+		case FILTER_TYPE_NUMBER:
+			value, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, &FilterError{Param: f.Param, Reason: "must be a number"}
+			}
+			values = append(values, value)
+		case FILTER_TYPE_DATE:
+			value, err := time.Parse(time.DateOnly, part)
+			if err != nil {
+				return nil, &FilterError{Param: f.Param, Reason: "must be a date like 2026-01-31"}
+			}
+			values = append(values, value)
+		default:
+			if len(f.Values) > 0 && !slices.Contains(f.Values, part) {
+				return nil, &FilterError{Param: f.Param, Reason: "must be one of " + strings.Join(f.Values, ", ")}
+			}
+			values = append(values, part)
+		}
+	}
+	return values, nil
+}
+
+// escapeLike keeps wildcards typed in search from matching everything
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyScore rates how well query matches texts, 0 is no match. Every word of query has to match some word
+// of texts, exactly, as prefix, inside it or with a typo or two for longer words
+func FuzzyScore(query string, texts ...string) float64 {
+	queryWords := fuzzyWords(query)
+	if len(queryWords) == 0 {
+		return 0
+	}
+	words := []string{}
+	for _, text := range texts {
+		words = append(words, fuzzyWords(text)...)
+	}
+
+	total := 0.0
+	for _, queryWord := range queryWords {
+		best := 0.0
+		for _, word := range words {
+			best = max(best, fuzzyWordScore(queryWord, word))
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+func fuzzyWordScore(query string, word string) float64 {
+	queryRunes, wordRunes := []rune(query), []rune(word)
+	typos := 0
+	switch {
+	case len(queryRunes) >= 8:
+		typos = 2
+	case len(queryRunes) >= 3:
+		typos = 1
+	}
+
+	switch {
+	case query == word:
+		return 3
+	case strings.HasPrefix(word, query):
+		return 2
+	case len(queryRunes) >= 3 && strings.Contains(word, query):
+		return 1.5
+	case typos > 0 && editDistance(queryRunes, wordRunes) <= typos:
+		return 1
+	case typos > 0 && len(wordRunes) > len(queryRunes) && editDistance(queryRunes, wordRunes[:len(queryRunes)]) <= typos:
+		// typo in the beginning of a longer word still being typed
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// fuzzyWords lowercases text and splits it on anything but letters and digits
+func fuzzyWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// editDistance counts insertions, deletions, substitutions and swaps of neighbours turning a into b
+func editDistance(a []rune, b []rune) int {
+	previous, before := make([]int, len(b)+1), make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], before[j-2]+1)
+			}
+		}
+		before, previous, current = previous, current, before
+	}
+	return previous[len(b)]
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"errors"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,13 +13,25 @@ type Pagination struct {
 	Limit int
 	Page  int
 	Sort  string
+	// Search is free text, resource decides which columns it matches
+	Search string
+	// Filters are query parameters, only those the resource declares are applied
+	Filters url.Values
+	// CursorMode pages by ?cursor= instead of page, empty cursor is the first page
+	CursorMode bool
+	Cursor     string
+	// NextCursor and PrevCursor are set by FindPage, empty when there are no more rows that way
+	NextCursor string
+	PrevCursor string
 }
 
 type PaginationResult struct {
-	Limit int
-	Page  int
-	Total int64
-	Sort  string
+	Limit      int
+	Page       int
+	Total      int64
+	Sort       string
+	NextCursor string
+	PrevCursor string
 }
 
 func NewPagination(ctx *gin.Context) Pagination {
@@ -29,10 +44,30 @@ func NewPagination(ctx *gin.Context) Pagination {
 		pagination.Page = page
 	}
 
-	pagination.Sort = ctx.DefaultQuery("sort", "id desc")
+	pagination.Sort = ctx.DefaultQuery("sort", SORT_DEFAULT)
+	pagination.Search = strings.TrimSpace(ctx.Query("search"))
+	pagination.Filters = ctx.Request.URL.Query()
+	pagination.Cursor, pagination.CursorMode = ctx.GetQuery("cursor")
 	return pagination
 }
 
+// Result echoes pagination with total rows for list responses
+func (p Pagination) Result(totalRows int64) PaginationResult {
+	return PaginationResult{
+		Limit:      p.Limit,
+		Page:       p.Page,
+		Total:      totalRows,
+		Sort:       p.Sort,
+		NextCursor: p.NextCursor,
+		PrevCursor: p.PrevCursor,
+	}
+}
+
+// IsQueryError tells errors of filter, sort and cursor parameters, which are for the client to fix
+func IsQueryError(err error) bool {
+	return errors.Is(err, ErrFilterValue) || errors.Is(err, ErrSortField) || errors.Is(err, ErrCursor)
+}
+
 func (p Pagination) Offset() int {
 	return (p.GetPage() - 1) * p.GetLimit()
 }
@@ -50,10 +85,3 @@ func (p Pagination) GetPage() int {
 	}
 	return p.Page
 }
-
-func (p Pagination) GetSort() string {
-	if p.Sort == "" {
-		p.Sort = "Id desc"
-	}
-	return p.Sort
-}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const SORT_DEFAULT = "id desc"
+
+var ErrSortField = errors.New("sort field is not supported")
+
+// SortError names sort term which is not a sortable field with optional asc or desc
+type SortError struct {
+	Term   string
+	Fields SortFields
+}
+
+func (e *SortError) Error() string {
+	names := []string{"id"}
+	for name := range e.Fields {
+		if name != "id" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return fmt.Sprintf("sort %q must be one of %s, followed by asc or desc", e.Term, strings.Join(names, ", "))
+}
+
+func (e *SortError) Unwrap() error {
+	return ErrSortField
+}
+
+// SortFields maps sort names of resource to columns, nothing else reaches ORDER BY.
+// Only NOT NULL columns belong here, cursors cannot step over NULL
+type SortFields map[string]string
+
+type sortOrder struct {
+	column string
+	desc   bool
+}
+
+// OrderBy turns sort like "name asc, joinDate desc" into ORDER BY clause, columns are accepted by name too
+func (p Pagination) OrderBy(fields SortFields) (string, error) {
+	orders, err := p.sortOrders(fields)
+	if err != nil {
+		return "", err
+	}
+	return orderClause(orders, false), nil
+}
+
+// sortOrders ends with id unless sorted by it already, so rows always have the same order
+func (p Pagination) sortOrders(fields SortFields) ([]sortOrder, error) {
+	sort := strings.TrimSpace(p.Sort)
+	if sort == "" {
+		sort = SORT_DEFAULT
+	}
+
+	orders := []sortOrder{}
+	for _, term := range strings.Split(sort, ",") {
+		words := strings.Fields(term)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, &SortError{Term: strings.TrimSpace(term), Fields: fields}
+		}
+		column, ok := fields.column(words[0])
+		if !ok {
+			return nil, &SortError{Term: strings.TrimSpace(term), Fields: fields}
+		}
+		order := sortOrder{column: column}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				order.desc = true
+			default:
+				return nil, &SortError{Term: strings.TrimSpace(term), Fields: fields}
+			}
+		}
+		orders = append(orders, order)
+	}
+
+	if !slices.ContainsFunc(orders, func(order sortOrder) bool { return order.column == "id" }) {
+		orders = append(orders, sortOrder{column: "id", desc: orders[len(orders)-1].desc})
+	}
+	return orders, nil
+}
+
+func (f SortFields) column(name string) (string, bool) {
+	if name == "id" {
+		return "id", true
+	}
+	for field, column := range f {
+		if strings.EqualFold(field, name) || strings.EqualFold(column, name) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// orderClause reverses every direction for walking back from a cursor
+func orderClause(orders []sortOrder, reverse bool) string {
+	terms := make([]string, len(orders))
+	for i, order := range orders {
+		direction := "ASC"
+		if order.desc != reverse {
+			direction = "DESC"
+		}
+		terms[i] = order.column + " " + direction
+	}
+	return strings.Join(terms, ", ")
+}
@@ -0,0 +1,305 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SPREADSHEET_FORMAT_CSV  = "csv"
+	SPREADSHEET_FORMAT_XLSX = "xlsx"
+)
+
+// XLSX_MAX_PART_BYTES caps each decompressed part of XLSX, a small upload can unzip to gigabytes
+const XLSX_MAX_PART_BYTES = 50 << 20
+
+var SPREADSHEET_CONTENT_TYPES = map[string]string{
+	SPREADSHEET_FORMAT_CSV:  "text/csv",
+	SPREADSHEET_FORMAT_XLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+var (
+	ErrSpreadsheetFormat = errors.New("format must be csv or xlsx")
+	ErrSpreadsheetFile   = errors.New("spreadsheet cannot be read")
+)
+
+// SpreadsheetFormat tells format from file name, empty for unknown extensions
+func SpreadsheetFormat(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return SPREADSHEET_FORMAT_CSV
+	case ".xlsx":
+		return SPREADSHEET_FORMAT_XLSX
+	default:
+		return ""
+	}
+}
+
+// ReadSpreadsheet returns rows of CSV or of the first XLSX sheet, rows may differ in length
+func ReadSpreadsheet(format string, content []byte) ([][]string, error) {
+	switch format {
+	case SPREADSHEET_FORMAT_CSV:
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSpreadsheetFile, err)
+		}
+		return rows, nil
+	case SPREADSHEET_FORMAT_XLSX:
+		rows, err := readXLSX(content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSpreadsheetFile, err)
+		}
+		return rows, nil
+	default:
+		return nil, ErrSpreadsheetFormat
+	}
+}
+
+// WriteSpreadsheet writes rows as CSV or as the only sheet of XLSX, all cells are text
+func WriteSpreadsheet(w io.Writer, format string, rows [][]string) error {
+	switch format {
+	case SPREADSHEET_FORMAT_CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case SPREADSHEET_FORMAT_XLSX:
+		return writeXLSX(w, rows)
+	default:
+		return ErrSpreadsheetFormat
+	}
+}
+
+// EscapeSpreadsheetFormula quotes text spreadsheet applications would run as formula, like =HYPERLINK(...)
+func EscapeSpreadsheetFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ParseSpreadsheetDate accepts YYYY-MM-DD and the day numbers XLSX stores dates as
+func ParseSpreadsheetDate(value string) (time.Time, error) {
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		// day zero of spreadsheet calendar, counting in the non-existent 1900-02-29
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxText is plain or rich text of shared string or inline string cell
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	text := t.Text
+	for _, run := range t.Runs {
+		text += run.Text
+	}
+	return text
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(content []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+	decode := func(name string, v interface{}) error {
+		file, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%s is missing", name)
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		// sizes in zip header are not trusted, only what is actually decompressed counts
+		data, err := io.ReadAll(io.LimitReader(reader, XLSX_MAX_PART_BYTES+1))
+		if err != nil {
+			return err
+		}
+		if len(data) > XLSX_MAX_PART_BYTES {
+			return fmt.Errorf("%s is larger than %d bytes unzipped", name, XLSX_MAX_PART_BYTES)
+		}
+		return xml.Unmarshal(data, v)
+	}
+
+	sheetName := "xl/worksheets/sheet1.xml"
+	var workbook xlsxWorkbook
+	var relationships xlsxRelationships
+	if decode("xl/workbook.xml", &workbook) == nil && decode("xl/_rels/workbook.xml.rels", &relationships) == nil && len(workbook.Sheets) > 0 {
+		for _, relationship := range relationships.Relationships {
+			if relationship.ID == workbook.Sheets[0].RelationshipID {
+				sheetName = path.Join("xl", strings.TrimPrefix(relationship.Target, "/xl/"))
+			}
+		}
+	}
+
+	var sharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decode("xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decode(sheetName, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for _, sheetRow := range sheet.Rows {
+		row := []string{}
+		for _, cell := range sheetRow.Cells {
+			column := len(row)
+			if cell.Ref != "" {
+				column = xlsxColumnIndex(cell.Ref)
+			}
+			for len(row) <= column {
+				row = append(row, "")
+			}
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("cell %s refers to unknown shared string", cell.Ref)
+				}
+				row[column] = sharedStrings.Items[index].String()
+			case "inlineStr":
+				row[column] = cell.Inline.String()
+			default:
+				row[column] = cell.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// xlsxColumnIndex turns column letters of cell reference like AB12 into zero based index
+func xlsxColumnIndex(ref string) int {
+	index := 0
+	for _, char := range ref {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		index = index*26 + int(char-'A'+1)
+	}
+	return index - 1
+}
+
+func xlsxColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+var xlsxStaticParts = map[string]string{
+	"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`,
+	"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`,
+}
+
+// writeXLSX writes the smallest workbook spreadsheet applications open, cells are inline strings
+func writeXLSX(w io.Writer, rows [][]string) error {
+	archive := zip.NewWriter(w)
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		part, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(part, xlsxStaticParts[name]); err != nil {
+			return err
+		}
+	}
+
+	var sheet bytes.Buffer
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(j), i+1)
+			if err := xml.EscapeText(&sheet, []byte(value)); err != nil {
+				return err
+			}
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	part, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(sheet.Bytes()); err != nil {
+		return err
+	}
+	return archive.Close()
+}
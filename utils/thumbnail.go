@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Thumbnail scales image down to fit in size x size by averaging the pixels each target pixel covers.
+// Square crops the center first so every thumbnail has the same shape, images are never scaled up
+func Thumbnail(src image.Image, size int, square bool) *image.RGBA {
+	bounds := src.Bounds()
+	if square {
+		side := min(bounds.Dx(), bounds.Dy())
+		x, y := bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2
+		bounds = image.Rect(x, y, x+side, y+side)
+	}
+
+	// copy to RGBA once, reading pixels through image.Image interface is slow for photos
+	source := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(source, source.Bounds(), src, bounds.Min, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > size {
+		width = max(1, width*size/longest)
+		height = max(1, height*size/longest)
+	}
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return source
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*bounds.Dy()/height, max((y+1)*bounds.Dy()/height, y*bounds.Dy()/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*bounds.Dx()/width, max((x+1)*bounds.Dx()/width, x*bounds.Dx()/width+1)
+			var r, g, b, a, count int
+			for sy := y0; sy < y1; sy++ {
+				offset := source.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(source.Pix[offset])
+					g += int(source.Pix[offset+1])
+					b += int(source.Pix[offset+2])
+					a += int(source.Pix[offset+3])
+					offset += 4
+					count++
+				}
+			}
+			offset := thumbnail.PixOffset(x, y)
+			thumbnail.Pix[offset] = uint8(r / count)
+			thumbnail.Pix[offset+1] = uint8(g / count)
+			thumbnail.Pix[offset+2] = uint8(b / count)
+			thumbnail.Pix[offset+3] = uint8(a / count)
+		}
+	}
+	return thumbnail
+}
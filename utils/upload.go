@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// MULTIPART_FORM_OVERHEAD_BYTES allows boundaries, part headers and text fields on top of the file
+	MULTIPART_FORM_OVERHEAD_BYTES = 1 << 20
+	// MULTIPART_FIELD_MAX_BYTES caps each text field read by ReadMultipartFields
+	MULTIPART_FIELD_MAX_BYTES = 64 << 10
+)
+
+var (
+	ErrMultipartFileMissing = errors.New("file is missing")
+	ErrMultipartField       = errors.New("form field is too long")
+)
+
+// LimitUploadBody caps request body to maxFileBytes and form overhead, so larger uploads fail while being received
+// instead of after gin has buffered them. It has to be called before form is read
+func LimitUploadBody(ctx *gin.Context, maxFileBytes int64) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxFileBytes+MULTIPART_FORM_OVERHEAD_BYTES)
+}
+
+// IsUploadTooLarge tells whether err comes from body over the limit of LimitUploadBody
+func IsUploadTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// ReadMultipartFields reads text fields up to the part named fileField, which is returned unread so it can be streamed.
+// Fields sent after the file are not read, clients have to send them first
+func ReadMultipartFields(reader *multipart.Reader, fileField string) (map[string]string, *multipart.Part, error) {
+	fields := map[string]string{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, nil, ErrMultipartFileMissing
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		name := part.FormName()
+		if name == fileField && part.FileName() != "" {
+			return fields, part, nil
+		}
+		value, err := io.ReadAll(io.LimitReader(part, MULTIPART_FIELD_MAX_BYTES+1))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(value) > MULTIPART_FIELD_MAX_BYTES {
+			return nil, nil, fmt.Errorf("%w: %s", ErrMultipartField, name)
+		}
+		fields[name] = string(value)
+	}
+}